@@ -50,6 +50,11 @@ func handleLaunch(profile string, args []string) {
 	// Resume session flag
 	resumeSession := fs.String("resume-session", "", "Claude session ID to resume")
 
+	templateName := fs.String("template", "", "Create from a saved template (see 'agent-deck template list')")
+
+	// Claude/Codex launch options
+	launchFlags := registerToolLaunchFlags(fs)
+
 	fs.Usage = func() {
 		fmt.Println("Usage: agent-deck launch [path] [options]")
 		fmt.Println()
@@ -68,6 +73,8 @@ func handleLaunch(profile string, args []string) {
 		fmt.Println("  agent-deck launch /path/to/project -t \"My Agent\" -c claude -g work")
 		fmt.Println("  agent-deck launch . -c claude --mcp memory -m \"Research topic X\"")
 		fmt.Println("  agent-deck launch . -c claude -m \"Fix bug\" --no-wait")
+		fmt.Println("  agent-deck launch . -c claude --model opus --permission-mode plan -m \"Plan the refactor\"")
+		fmt.Println("  agent-deck launch --template reviewer -w fix/login .")
 	}
 
 	// Reorder args: move path to end so flags are parsed correctly
@@ -80,8 +87,13 @@ func handleLaunch(profile string, args []string) {
 	quietMode := *quiet || *quietShort
 	out := NewCLIOutput(*jsonOutput, quietMode)
 
+	tmpl := loadTemplateFlag(profile, *templateName)
+
 	// Resolve path
 	path := strings.Trim(fs.Arg(0), "'\"")
+	if path == "" && tmpl != nil {
+		path = tmpl.Path
+	}
 	if path == "" || path == "." {
 		var err error
 		path, err = os.Getwd()
@@ -127,15 +139,39 @@ func handleLaunch(profile string, args []string) {
 	}
 	createNewBranch := *newBranch || *newBranchLong
 
+	// Template title and branch patterns fill in whatever wasn't given
+	projectPath := path
+	if tmpl != nil {
+		if sessionGroup == "" {
+			sessionGroup = tmpl.Group
+		}
+		sessionTitle, wtBranch = tmpl.ResolveNames(path, sessionTitle, wtBranch)
+	}
+
 	// Validate --resume-session requires Claude
 	if *resumeSession != "" {
 		tool := detectTool(sessionCommand)
+		if sessionCommand == "" && tmpl != nil {
+			tool = tmpl.Tool
+		}
 		if tool != "claude" {
 			out.Error("--resume-session only works with Claude sessions (-c claude)", ErrCodeInvalidOperation)
 			os.Exit(1)
 		}
 	}
 
+	// Validate Claude/Codex launch options
+	if launchFlags.isSet() {
+		tool := detectTool(sessionCommand)
+		if sessionCommand == "" && tmpl != nil {
+			tool = tmpl.Tool
+		}
+		if err := launchFlags.validate(tool); err != nil {
+			out.Error(err.Error(), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+	}
+
 	// Handle worktree creation
 	var worktreePath, worktreeRepoRoot string
 	if wtBranch != "" {
@@ -189,6 +225,7 @@ func handleLaunch(profile string, args []string) {
 			out.Error(fmt.Sprintf("failed to create worktree: %v", err), ErrCodeInvalidOperation)
 			os.Exit(1)
 		}
+		provisionNewWorktree(repoRoot, worktreePath)
 
 		worktreeRepoRoot = repoRoot
 		path = worktreePath
@@ -230,7 +267,7 @@ func handleLaunch(profile string, args []string) {
 	}
 
 	// Check for duplicate and generate unique title
-	userProvidedTitle := (mergeFlags(*title, *titleShort) != "")
+	userProvidedTitle := mergeFlags(*title, *titleShort) != "" || (tmpl != nil && tmpl.Title != "")
 	if !userProvidedTitle {
 		sessionTitle = generateUniqueTitle(instances, sessionTitle, path)
 	} else {
@@ -255,6 +292,23 @@ func handleLaunch(profile string, args []string) {
 		newInstance.SetParentWithPath(parentInstance.ID, parentInstance.ProjectPath)
 	}
 
+	if tmpl != nil {
+		vars := session.TemplateVars{
+			Project: filepath.Base(projectPath),
+			Branch:  wtBranch,
+			Title:   sessionTitle,
+			Group:   newInstance.GroupPath,
+			Path:    path,
+		}
+		tmpl.ApplyTo(newInstance, vars)
+		if len(mcpFlags) == 0 {
+			mcpFlags = tmpl.MCPs
+		}
+		if initialMessage == "" {
+			initialMessage = vars.Expand(tmpl.InitialMessage)
+		}
+	}
+
 	if sessionCommand != "" {
 		newInstance.Tool = detectTool(sessionCommand)
 		if toolDef := session.GetToolDef(newInstance.Tool); toolDef != nil {
@@ -288,6 +342,8 @@ func handleLaunch(profile string, args []string) {
 		_ = newInstance.SetClaudeOptions(opts)
 	}
 
+	_ = launchFlags.applyToInstance(newInstance)
+
 	// Add to instances and save
 	instances = append(instances, newInstance)
 
@@ -315,6 +371,11 @@ func handleLaunch(profile string, args []string) {
 			os.Exit(1)
 		}
 	}
+	if tmpl != nil && len(tmpl.Skills) > 0 {
+		if err := tmpl.AttachSkills(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
 
 	// Start the session.
 	// - default: StartWithMessage waits for readiness and delivers initial prompt
@@ -375,6 +436,9 @@ func handleLaunch(profile string, args []string) {
 		jsonData["worktree_path"] = worktreePath
 		jsonData["worktree_branch"] = wtBranch
 	}
+	if tmpl != nil {
+		jsonData["template"] = tmpl.Name
+	}
 
 	msg := fmt.Sprintf("Launched session: %s", newInstance.Title)
 	if initialMessage != "" {
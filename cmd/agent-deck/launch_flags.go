@@ -0,0 +1,240 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// toolLaunchFlags holds the Claude and Codex launch option flags shared by add and launch
+type toolLaunchFlags struct {
+	model *string
+
+	// Claude
+	permissionMode     *string
+	allowedTools       *string
+	disallowedTools    *string
+	appendSystemPrompt *string
+	addDirs            []string
+
+	// Codex
+	codexProfile   *string
+	sandbox        *string
+	approvalPolicy *string
+}
+
+// registerToolLaunchFlags adds the Claude and Codex launch option flags to fs
+func registerToolLaunchFlags(fs *flag.FlagSet) *toolLaunchFlags {
+	f := &toolLaunchFlags{
+		model:              fs.String("model", "", "Model for Claude or Codex (e.g., opus, gpt-5-codex)"),
+		permissionMode:     fs.String("permission-mode", "", "Claude permission mode: "+strings.Join(session.ClaudePermissionModes, ", ")),
+		allowedTools:       fs.String("allowed-tools", "", "Comma-separated tools Claude may use without asking (e.g., \"Bash(git:*),Edit\")"),
+		disallowedTools:    fs.String("disallowed-tools", "", "Comma-separated tools Claude may not use"),
+		appendSystemPrompt: fs.String("append-system-prompt", "", "Text appended to Claude's system prompt"),
+		codexProfile:       fs.String("codex-profile", "", "Codex config profile (a [profiles.<name>] table in ~/.codex/config.toml)"),
+		sandbox:            fs.String("sandbox", "", "Codex sandbox: "+strings.Join(session.CodexSandboxModes, ", ")),
+		approvalPolicy:     fs.String("approval-policy", "", "Codex approval policy: "+strings.Join(session.CodexApprovalPolicies, ", ")),
+	}
+	fs.Func("add-dir", "Extra directory Claude may access (can specify multiple times)", func(s string) error {
+		f.addDirs = append(f.addDirs, s)
+		return nil
+	})
+	return f
+}
+
+// validate checks that the given flags fit tool and have values the tool
+// would otherwise reject at startup
+func (f *toolLaunchFlags) validate(tool string) error {
+	claudeOnly := *f.permissionMode != "" || *f.allowedTools != "" || *f.disallowedTools != "" ||
+		*f.appendSystemPrompt != "" || len(f.addDirs) > 0
+	codexOnly := *f.codexProfile != "" || *f.sandbox != "" || *f.approvalPolicy != ""
+
+	switch {
+	case claudeOnly && tool != "claude":
+		return fmt.Errorf("--permission-mode, --allowed-tools, --disallowed-tools, --append-system-prompt and --add-dir only work with Claude sessions (-c claude)")
+	case codexOnly && tool != "codex":
+		return fmt.Errorf("--codex-profile, --sandbox and --approval-policy only work with Codex sessions (-c codex)")
+	case *f.model != "" && tool != "claude" && tool != "codex":
+		return fmt.Errorf("--model only works with Claude and Codex sessions")
+	}
+	if !session.IsValidClaudePermissionMode(*f.permissionMode) {
+		return fmt.Errorf("invalid --permission-mode %q (valid: %s)",
+			*f.permissionMode, strings.Join(session.ClaudePermissionModes, ", "))
+	}
+	if !session.IsValidCodexSandboxMode(*f.sandbox) {
+		return fmt.Errorf("invalid --sandbox %q (valid: %s)",
+			*f.sandbox, strings.Join(session.CodexSandboxModes, ", "))
+	}
+	if !session.IsValidCodexApprovalPolicy(*f.approvalPolicy) {
+		return fmt.Errorf("invalid --approval-policy %q (valid: %s)",
+			*f.approvalPolicy, strings.Join(session.CodexApprovalPolicies, ", "))
+	}
+	return nil
+}
+
+// isSet reports whether any launch option flag was given
+func (f *toolLaunchFlags) isSet() bool {
+	return *f.model != "" || *f.permissionMode != "" || *f.allowedTools != "" ||
+		*f.disallowedTools != "" || *f.appendSystemPrompt != "" || len(f.addDirs) > 0 ||
+		*f.codexProfile != "" || *f.sandbox != "" || *f.approvalPolicy != ""
+}
+
+// applyToInstance stores the given flags in inst's tool options, starting
+// from any options already set (e.g. by a template or --resume-session)
+func (f *toolLaunchFlags) applyToInstance(inst *session.Instance) error {
+	if !f.isSet() {
+		return nil
+	}
+	switch inst.Tool {
+	case "claude":
+		opts := inst.GetClaudeOptions()
+		if opts == nil {
+			userConfig, _ := session.LoadUserConfig()
+			opts = session.NewClaudeOptions(userConfig)
+		}
+		f.applyToClaude(opts)
+		return inst.SetClaudeOptions(opts)
+	case "codex":
+		opts := inst.GetCodexOptions()
+		if opts == nil {
+			opts = &session.CodexOptions{}
+		}
+		f.applyToCodex(opts)
+		return inst.SetCodexOptions(opts)
+	}
+	return nil
+}
+
+// applyToCodex copies the flags that were given onto opts
+func (f *toolLaunchFlags) applyToCodex(opts *session.CodexOptions) {
+	if *f.model != "" {
+		opts.Model = *f.model
+	}
+	if *f.codexProfile != "" {
+		opts.Profile = *f.codexProfile
+	}
+	if *f.sandbox != "" {
+		opts.Sandbox = *f.sandbox
+	}
+	if *f.approvalPolicy != "" {
+		opts.ApprovalPolicy = *f.approvalPolicy
+	}
+}
+
+// applyToClaude copies the flags that were given onto opts
+func (f *toolLaunchFlags) applyToClaude(opts *session.ClaudeOptions) {
+	if *f.model != "" {
+		opts.Model = *f.model
+	}
+	if *f.permissionMode != "" {
+		opts.PermissionMode = *f.permissionMode
+	}
+	if *f.allowedTools != "" {
+		opts.AllowedTools = session.ParseToolList(*f.allowedTools)
+	}
+	if *f.disallowedTools != "" {
+		opts.DisallowedTools = session.ParseToolList(*f.disallowedTools)
+	}
+	if *f.appendSystemPrompt != "" {
+		opts.AppendSystemPrompt = *f.appendSystemPrompt
+	}
+	if len(f.addDirs) > 0 {
+		opts.AddDirs = append([]string(nil), f.addDirs...)
+	}
+}
+
+// toolOptionFields are the tool option fields settable via 'session set'
+var toolOptionFields = []string{
+	"model", "permission-mode", "allowed-tools", "disallowed-tools", "append-system-prompt", "add-dirs",
+	"codex-profile", "sandbox", "approval-policy",
+}
+
+// setToolOptionField updates one Claude or Codex option of inst from its
+// 'session set' string form and returns the previous value. An empty value
+// clears the field.
+func setToolOptionField(inst *session.Instance, field, value string) (string, error) {
+	switch inst.Tool {
+	case "claude":
+		opts := inst.GetClaudeOptions()
+		if opts == nil {
+			userConfig, _ := session.LoadUserConfig()
+			opts = session.NewClaudeOptions(userConfig)
+		}
+		old, err := setClaudeOptionField(opts, field, value)
+		if err != nil {
+			return "", err
+		}
+		return old, inst.SetClaudeOptions(opts)
+	case "codex":
+		opts := inst.GetCodexOptions()
+		if opts == nil {
+			opts = &session.CodexOptions{}
+		}
+		old, err := setCodexOptionField(opts, field, value)
+		if err != nil {
+			return "", err
+		}
+		return old, inst.SetCodexOptions(opts)
+	}
+	return "", fmt.Errorf("%s only applies to Claude and Codex sessions (tool: %s)", field, inst.Tool)
+}
+
+// setCodexOptionField updates one CodexOptions field and returns the previous value
+func setCodexOptionField(opts *session.CodexOptions, field, value string) (string, error) {
+	var old string
+	switch field {
+	case "model":
+		old, opts.Model = opts.Model, value
+	case "codex-profile":
+		old, opts.Profile = opts.Profile, value
+	case "sandbox":
+		if !session.IsValidCodexSandboxMode(value) {
+			return "", fmt.Errorf("invalid sandbox %q (valid: %s)",
+				value, strings.Join(session.CodexSandboxModes, ", "))
+		}
+		old, opts.Sandbox = opts.Sandbox, value
+	case "approval-policy":
+		if !session.IsValidCodexApprovalPolicy(value) {
+			return "", fmt.Errorf("invalid approval policy %q (valid: %s)",
+				value, strings.Join(session.CodexApprovalPolicies, ", "))
+		}
+		old, opts.ApprovalPolicy = opts.ApprovalPolicy, value
+	default:
+		return "", fmt.Errorf("%s is not a Codex option", field)
+	}
+	return old, nil
+}
+
+// setClaudeOptionField updates one ClaudeOptions field and returns the previous value
+func setClaudeOptionField(opts *session.ClaudeOptions, field, value string) (string, error) {
+	var old string
+	switch field {
+	case "model":
+		old, opts.Model = opts.Model, value
+	case "permission-mode":
+		if !session.IsValidClaudePermissionMode(value) {
+			return "", fmt.Errorf("invalid permission mode %q (valid: %s)",
+				value, strings.Join(session.ClaudePermissionModes, ", "))
+		}
+		old, opts.PermissionMode = opts.PermissionMode, value
+	case "allowed-tools":
+		old, opts.AllowedTools = strings.Join(opts.AllowedTools, ","), session.ParseToolList(value)
+	case "disallowed-tools":
+		old, opts.DisallowedTools = strings.Join(opts.DisallowedTools, ","), session.ParseToolList(value)
+	case "append-system-prompt":
+		old, opts.AppendSystemPrompt = opts.AppendSystemPrompt, value
+	case "add-dirs":
+		old = strings.Join(opts.AddDirs, ",")
+		opts.AddDirs = nil
+		for _, dir := range strings.Split(value, ",") {
+			if dir = strings.TrimSpace(dir); dir != "" {
+				opts.AddDirs = append(opts.AddDirs, dir)
+			}
+		}
+	default:
+		return "", fmt.Errorf("%s is not a Claude option", field)
+	}
+	return old, nil
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/termenv"
 
+	"github.com/asheshgoplani/agent-deck/internal/forge"
 	"github.com/asheshgoplani/agent-deck/internal/git"
 	"github.com/asheshgoplani/agent-deck/internal/logging"
 	"github.com/asheshgoplani/agent-deck/internal/session"
@@ -242,6 +243,12 @@ func main() {
 		case "group":
 			handleGroup(profile, args[1:])
 			return
+		case "template", "templates":
+			handleTemplate(profile, args[1:])
+			return
+		case "prompt", "prompts":
+			handlePrompt(profile, args[1:])
+			return
 		case "try":
 			handleTry(profile, args[1:])
 			return
@@ -255,6 +262,14 @@ func main() {
 			handleWorktree(profile, args[1:])
 			return
 		case "web":
+			if len(args) > 1 && args[1] == "token" {
+				handleWebToken(profile, args[2:])
+				return
+			}
+			if len(args) > 1 && args[1] == "share" {
+				handleWebShare(profile, args[2:])
+				return
+			}
 			if hasHeadlessFlag(args[1:]) {
 				handleWebHeadless(profile, args[1:])
 				return
@@ -280,6 +295,9 @@ func main() {
 		case "notify-daemon":
 			handleNotifyDaemon(args[1:])
 			return
+		case "metrics":
+			handleMetrics(profile, args[1:])
+			return
 		}
 	}
 
@@ -444,13 +462,14 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Error: web server setup failed: %v\n", err)
 			os.Exit(1)
 		}
+		homeModel.SetShareViewers(server.ShareViewers())
 		go func() {
 			if err := server.Start(); err != nil {
 				logging.ForComponent(logging.CompWeb).Error("web_server_error",
 					slog.String("error", err.Error()))
 			}
 		}()
-		fmt.Printf("Web server: http://%s\n", server.Addr())
+		fmt.Printf("Web server: %s\n", server.URL())
 		defer func() {
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
@@ -533,6 +552,17 @@ func reorderArgsForFlagParsing(args []string) []string {
 		"-w":        true, "--worktree": true,
 		"--location":       true,
 		"--resume-session": true,
+		"--template":       true,
+		// Claude launch options
+		"--model":                true,
+		"--permission-mode":      true,
+		"--allowed-tools":        true,
+		"--disallowed-tools":     true,
+		"--append-system-prompt": true,
+		"--add-dir":              true,
+		"--codex-profile":        true,
+		"--sandbox":              true,
+		"--approval-policy":      true,
 	}
 
 	var flags []string
@@ -698,6 +728,11 @@ func handleAdd(profile string, args []string) {
 	// Resume session flag
 	resumeSession := fs.String("resume-session", "", "Claude session ID to resume (skips new session creation)")
 
+	templateName := fs.String("template", "", "Create from a saved template (see 'agent-deck template list')")
+
+	// Claude/Codex launch options
+	launchFlags := registerToolLaunchFlags(fs)
+
 	fs.Usage = func() {
 		fmt.Println("Usage: agent-deck add [path] [options]")
 		fmt.Println()
@@ -719,11 +754,18 @@ func handleAdd(profile string, args []string) {
 		fmt.Println("  agent-deck add -t \"Research\" -c claude --mcp memory --mcp sequential-thinking /tmp/x")
 		fmt.Println("  agent-deck add -c opencode --wrapper \"nvim +'terminal {command}' +'startinsert'\" .")
 		fmt.Println("  agent-deck add --quick -c claude .   # Auto-generated name")
+		fmt.Println("  agent-deck add -c claude --model opus --permission-mode plan .")
+		fmt.Println("  agent-deck add -c claude --allowed-tools \"Bash(git:*),Edit\" --add-dir ../shared .")
+		fmt.Println("  agent-deck add -c codex --model gpt-5-codex --sandbox workspace-write --approval-policy on-request .")
 		fmt.Println()
 		fmt.Println("Worktree Examples:")
 		fmt.Println("  agent-deck add -w feature/login .    # Create worktree for existing branch")
 		fmt.Println("  agent-deck add -w feature/new -b .   # Create worktree with new branch")
 		fmt.Println("  agent-deck add --worktree fix/bug-123 --new-branch /path/to/repo")
+		fmt.Println()
+		fmt.Println("Template Examples:")
+		fmt.Println("  agent-deck add --template reviewer .           # Tool, MCPs, skills from template")
+		fmt.Println("  agent-deck add --template feature -t login .   # Worktree branch from template pattern")
 	}
 
 	// Reorder args: move path to end so flags are parsed correctly
@@ -758,15 +800,35 @@ func handleAdd(profile string, args []string) {
 		os.Exit(1)
 	}
 
+	tmpl := loadTemplateFlag(profile, *templateName)
+	if tmpl != nil && sessionGroup == "" {
+		sessionGroup = tmpl.Group
+	}
+
 	// Validate --resume-session requires Claude
 	if *resumeSession != "" {
 		tool := detectTool(sessionCommand)
+		if sessionCommand == "" && tmpl != nil {
+			tool = tmpl.Tool
+		}
 		if tool != "claude" {
 			fmt.Println("Error: --resume-session only works with Claude sessions (-c claude)")
 			os.Exit(1)
 		}
 	}
 
+	// Validate Claude/Codex launch options
+	if launchFlags.isSet() {
+		tool := detectTool(sessionCommand)
+		if sessionCommand == "" && tmpl != nil {
+			tool = tmpl.Tool
+		}
+		if err := launchFlags.validate(tool); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Load existing sessions with profile
 	storage, err := session.NewStorageWithProfile(profile)
 	if err != nil {
@@ -829,8 +891,11 @@ func handleAdd(profile string, args []string) {
 			}
 		}
 	} else {
-		// No explicit path provided: use group default path first, then cwd fallback.
-		if sessionGroup != "" {
+		// No explicit path provided: use template path, then group default path, then cwd fallback.
+		if tmpl != nil {
+			path = tmpl.Path
+		}
+		if path == "" && sessionGroup != "" {
 			path = groupTree.DefaultPathForGroup(sessionGroup)
 		}
 		if path == "" {
@@ -853,6 +918,12 @@ func handleAdd(profile string, args []string) {
 		os.Exit(1)
 	}
 
+	// Template title and branch patterns fill in whatever wasn't given
+	if tmpl != nil {
+		sessionTitle, wtBranch = tmpl.ResolveNames(path, sessionTitle, wtBranch)
+	}
+	projectPath := path
+
 	// Handle worktree creation
 	var worktreePath, worktreeRepoRoot string
 	if wtBranch != "" {
@@ -922,6 +993,7 @@ func handleAdd(profile string, args []string) {
 		}
 
 		fmt.Printf("Created worktree at: %s\n", worktreePath)
+		provisionNewWorktree(repoRoot, worktreePath)
 		worktreeRepoRoot = repoRoot
 		// Update path to point to worktree so session uses worktree as working directory
 		path = worktreePath
@@ -932,8 +1004,8 @@ func handleAdd(profile string, args []string) {
 		sessionTitle = filepath.Base(path)
 	}
 
-	// Track if user provided explicit title or we auto-generated from folder name
-	userProvidedTitle := (mergeFlags(*title, *titleShort) != "")
+	// Track if user (or template) provided explicit title or we auto-generated from folder name
+	userProvidedTitle := mergeFlags(*title, *titleShort) != "" || (tmpl != nil && tmpl.Title != "")
 	isQuick := *quickCreate || *quickCreateShort
 
 	if isQuick && !userProvidedTitle {
@@ -963,6 +1035,20 @@ func handleAdd(profile string, args []string) {
 		newInstance.SetParentWithPath(parentInstance.ID, parentInstance.ProjectPath)
 	}
 
+	tmplVars := session.TemplateVars{
+		Project: filepath.Base(projectPath),
+		Branch:  wtBranch,
+		Title:   sessionTitle,
+		Group:   newInstance.GroupPath,
+		Path:    path,
+	}
+	if tmpl != nil {
+		tmpl.ApplyTo(newInstance, tmplVars)
+		if len(mcpFlags) == 0 {
+			mcpFlags = tmpl.MCPs
+		}
+	}
+
 	// Set command if provided
 	if sessionCommand != "" {
 		newInstance.Tool = detectTool(sessionCommand)
@@ -1003,6 +1089,11 @@ func handleAdd(profile string, args []string) {
 		}
 	}
 
+	// Apply Claude/Codex launch options (persisted so they survive restart and fork)
+	if err := launchFlags.applyToInstance(newInstance); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to set tool options: %v\n", err)
+	}
+
 	// Add to instances
 	instances = append(instances, newInstance)
 
@@ -1040,6 +1131,14 @@ func handleAdd(profile string, args []string) {
 		}
 	}
 
+	// Skills are attached on a best-effort basis; a missing skill source
+	// shouldn't undo the session that was just added
+	if tmpl != nil && len(tmpl.Skills) > 0 {
+		if err := tmpl.AttachSkills(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
 	quietMode := *quiet || *quietShort
 	out := NewCLIOutput(*jsonOutput, quietMode)
 
@@ -1066,9 +1165,17 @@ func handleAdd(profile string, args []string) {
 	if *resumeSession != "" {
 		humanLines = append(humanLines, fmt.Sprintf("  Resume:  %s", *resumeSession))
 	}
+	if tmpl != nil {
+		humanLines = append(humanLines, fmt.Sprintf("  Template: %s", tmpl.Name))
+	}
 	humanLines = append(humanLines, "")
 	humanLines = append(humanLines, "Next steps:")
-	humanLines = append(humanLines, fmt.Sprintf("  agent-deck session start %s   # Start the session", sessionTitle))
+	if tmpl != nil && tmpl.InitialMessage != "" {
+		msg := tmplVars.Expand(tmpl.InitialMessage)
+		humanLines = append(humanLines, fmt.Sprintf("  agent-deck session start %s --message %q   # Start with the template message", sessionTitle, msg))
+	} else {
+		humanLines = append(humanLines, fmt.Sprintf("  agent-deck session start %s   # Start the session", sessionTitle))
+	}
 	humanLines = append(humanLines, "  agent-deck                         # Open TUI and press Enter to attach")
 
 	// Build JSON data
@@ -1154,8 +1261,17 @@ func handleList(profile string, args []string) {
 		return
 	}
 
+	// Cached pull request state from `agent-deck worktree pr`
+	prRecords, _ := forge.LoadRecords(storage.Profile())
+
 	if *jsonOutput {
 		// JSON output for scripting
+		type prJSON struct {
+			Number int    `json:"number"`
+			URL    string `json:"url"`
+			State  string `json:"state"`
+			Checks string `json:"checks,omitempty"`
+		}
 		type sessionJSON struct {
 			ID        string    `json:"id"`
 			Title     string    `json:"title"`
@@ -1166,6 +1282,7 @@ func handleList(profile string, args []string) {
 			Status    string    `json:"status"`
 			Profile   string    `json:"profile"`
 			CreatedAt time.Time `json:"created_at"`
+			PR        *prJSON   `json:"pr,omitempty"`
 		}
 		sessions := make([]sessionJSON, len(instances))
 		for i, inst := range instances {
@@ -1181,6 +1298,14 @@ func handleList(profile string, args []string) {
 				Profile:   storage.Profile(),
 				CreatedAt: inst.CreatedAt,
 			}
+			if rec, ok := prRecords[inst.ID]; ok {
+				sessions[i].PR = &prJSON{
+					Number: rec.PR.Number,
+					URL:    rec.PR.URL,
+					State:  string(rec.PR.State),
+					Checks: string(rec.CheckState),
+				}
+			}
 		}
 		output, err := json.MarshalIndent(sessions, "", "  ")
 		if err != nil {
@@ -1204,6 +1329,9 @@ func handleList(profile string, args []string) {
 		if len(idDisplay) > tableColIDDisplay {
 			idDisplay = idDisplay[:tableColIDDisplay]
 		}
+		if rec, ok := prRecords[inst.ID]; ok {
+			idDisplay += "  PR " + rec.Badge()
+		}
 		fmt.Printf("%-*s %-*s %-*s %s\n", tableColTitle, title, tableColGroup, group, tableColPath, path, idDisplay)
 	}
 	fmt.Printf("\nTotal: %d sessions\n", len(instances))
@@ -2000,8 +2128,11 @@ func printHelp() {
 	fmt.Println("  skill            Manage Claude skills")
 	fmt.Println("  codex-hooks      Manage Codex notify hook integration")
 	fmt.Println("  group            Manage groups")
+	fmt.Println("  template         Manage saved session templates")
+	fmt.Println("  prompt           Send prompts from the prompt library")
 	fmt.Println("  worktree, wt     Manage git worktrees")
 	fmt.Println("  web              Start TUI with web UI server (--headless for server-only)")
+	fmt.Println("  metrics serve    Serve Prometheus metrics without the web UI")
 	fmt.Println("  conductor        Manage conductor meta-agent orchestration")
 	fmt.Println("  profile          Manage profiles")
 	fmt.Println("  update           Check for and install updates")
@@ -2073,6 +2204,8 @@ func printHelp() {
 	fmt.Println("  agent-deck web --listen :9000         # TUI + web on custom port")
 	fmt.Println("  agent-deck web --read-only            # TUI + web in read-only mode")
 	fmt.Println("  agent-deck web --token secret         # TUI + web with auth token")
+	fmt.Println("  agent-deck web token create viewer --scopes menu:read  # Scoped API token")
+	fmt.Println("  agent-deck web share my-session --ttl 2h             # Read-only share link")
 	fmt.Println("  agent-deck web --help                 # Show web command flags")
 	fmt.Println()
 	fmt.Println("Environment Variables:")
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/mcppool"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	"github.com/asheshgoplani/agent-deck/internal/web"
+)
+
+// handleMetrics dispatches metrics subcommands.
+func handleMetrics(profile string, args []string) {
+	if len(args) == 0 || args[0] != "serve" {
+		printMetricsUsage()
+		if len(args) == 0 || args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+			return
+		}
+		os.Exit(1)
+	}
+	handleMetricsServe(profile, args[1:])
+}
+
+func printMetricsUsage() {
+	fmt.Println("Usage: agent-deck metrics serve [options]")
+	fmt.Println()
+	fmt.Println("Serve Prometheus metrics for sessions, analytics, the MCP pool, hub tasks")
+	fmt.Println("and notify-daemon deliveries without starting the web UI.")
+	fmt.Println("The web server exposes the same metrics at /metrics.")
+}
+
+// handleMetricsServe runs a standalone /metrics exporter.
+func handleMetricsServe(profile string, args []string) {
+	fs := flag.NewFlagSet("metrics serve", flag.ExitOnError)
+	listenAddr := fs.String("listen", "127.0.0.1:9420", "Listen address for the metrics endpoint")
+	profiles := fs.String("profiles", "", "Comma-separated profiles to export (\"all\" for every profile; default: current profile)")
+	token := fs.String("token", "", "Require this bearer token on scrapes")
+
+	fs.Usage = func() {
+		printMetricsUsage()
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck metrics serve")
+		fmt.Println("  agent-deck metrics serve --listen 0.0.0.0:9420 --profiles all --token scrape-secret")
+	}
+
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+
+	exported := splitCSV(*profiles)
+	if len(exported) == 1 && exported[0] == "all" {
+		all, err := session.ListProfiles()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to list profiles: %v\n", err)
+			os.Exit(1)
+		}
+		exported = all
+	}
+	if len(exported) == 0 {
+		exported = []string{session.GetEffectiveProfile(profile)}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	// Without a TUI there is no in-process pool; report the sockets owned by
+	// running agent-deck instances instead.
+	pool, _ := mcppool.NewPool(ctx, &mcppool.PoolConfig{Enabled: true})
+	collector := web.NewMetricsCollector(exported, func() *mcppool.Pool {
+		if p := session.GetGlobalPool(); p != nil {
+			return p
+		}
+		pool.DiscoverExistingSockets()
+		return pool
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		if *token != "" {
+			got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(got), []byte(*token)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		collector.ServeHTTP(w, r)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok\n"))
+	})
+
+	server := &http.Server{
+		Addr:              *listenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	fmt.Printf("Agent Deck metrics: http://%s/metrics (profiles: %s)\n", *listenAddr, strings.Join(exported, ", "))
+	fmt.Println("Press Ctrl+C to stop.")
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.ListenAndServe() }()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		_ = server.Shutdown(shutdownCtx)
+	case err := <-errCh:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "Error: metrics server failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handlePrompt dispatches prompt subcommands
+func handlePrompt(profile string, args []string) {
+	if len(args) == 0 {
+		handlePromptList(profile, nil)
+		return
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		handlePromptList(profile, args[1:])
+	case "show":
+		handlePromptShow(profile, args[1:])
+	case "send":
+		handlePromptSend(profile, args[1:])
+	case "help", "--help", "-h":
+		printPromptHelp()
+	default:
+		fmt.Printf("Unknown prompt command: %s\n", args[0])
+		fmt.Println()
+		printPromptHelp()
+		os.Exit(1)
+	}
+}
+
+// printPromptHelp prints usage for prompt commands
+func printPromptHelp() {
+	fmt.Println("Usage: agent-deck prompt <command> [options]")
+	fmt.Println()
+	fmt.Println("Send reusable prompts from this profile's prompt library. Prompts are .md,")
+	fmt.Println(".txt or .toml files in ~/.agent-deck/profiles/<profile>/prompts/, named by")
+	fmt.Println("file name.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  list                           List prompts")
+	fmt.Println("  show <name> [session]          Show a prompt, filled in for a session if given")
+	fmt.Println("  send <name> <session>...       Fill in a prompt and send it to sessions")
+	fmt.Println()
+	fmt.Println("Variables filled from the session:")
+	fmt.Println("  {{title}}  {{project}}  {{path}}  {{group}}  {{tool}}  {{date}}")
+	fmt.Println("  {{branch}}          worktree or current git branch")
+	fmt.Println("  {{files_changed}}   uncommitted files, one per line")
+	fmt.Println("  {{last_response}}   the agent's last response")
+	fmt.Println("Other variables take their default from the prompt's front-matter or --var.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck prompt send write-tests my-project")
+	fmt.Println("  agent-deck prompt send security-review api web --var focus=auth")
+	fmt.Println("  agent-deck prompt show security-review api")
+}
+
+// handlePromptList lists the prompt library
+func handlePromptList(profile string, args []string) {
+	fs := flag.NewFlagSet("prompt list", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+	out := NewCLIOutput(*jsonOutput, false)
+
+	prompts, err := session.ListPrompts(profile)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	var b strings.Builder
+	if len(prompts) == 0 {
+		dir, _ := session.PromptsDir(profile)
+		fmt.Fprintf(&b, "No prompts. Add .md or .toml files to %s\n", FormatPath(dir))
+	}
+	for _, p := range prompts {
+		fmt.Fprintf(&b, "%s %-24s %s\n", bulletSymbol, p.Name, p.Description)
+	}
+	if prompts == nil {
+		prompts = []session.Prompt{}
+	}
+	out.Print(b.String(), map[string]interface{}{"prompts": prompts})
+}
+
+// handlePromptShow prints a prompt, rendered for a session when one is given
+func handlePromptShow(profile string, args []string) {
+	fs := flag.NewFlagSet("prompt show", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	vars := promptVarFlag(fs)
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+	out := NewCLIOutput(*jsonOutput, false)
+	if fs.NArg() < 1 {
+		out.Error("prompt name is required", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	p := loadPromptOrExit(out, profile, fs.Arg(0))
+	if fs.NArg() < 2 {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Prompt:  %s\n", p.Name)
+		if p.Description != "" {
+			fmt.Fprintf(&b, "About:   %s\n", p.Description)
+		}
+		fmt.Fprintf(&b, "File:    %s\n", FormatPath(p.Path))
+		if names := p.Variables(); len(names) > 0 {
+			fmt.Fprintf(&b, "Vars:    %s\n", strings.Join(names, ", "))
+		}
+		fmt.Fprintf(&b, "\n%s\n", p.Body)
+		out.Print(b.String(), p)
+		return
+	}
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+	inst := resolveSessionOrExit(out, fs.Arg(1), instances)
+	text, err := p.Render(inst, *vars)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	out.Print(text+"\n", map[string]interface{}{
+		"prompt":     p.Name,
+		"session_id": inst.ID,
+		"message":    text,
+	})
+}
+
+// handlePromptSend fills in a prompt for each session and sends it
+func handlePromptSend(profile string, args []string) {
+	fs := flag.NewFlagSet("prompt send", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+	noWait := fs.Bool("no-wait", false, "Don't wait for the agent to be ready (send immediately)")
+	vars := promptVarFlag(fs)
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck prompt send <name> <session>... [options]")
+		fmt.Println()
+		fmt.Println("Fill in a prompt from the library for each session and send it, like")
+		fmt.Println("'session send'. Sessions that aren't running are reported and skipped.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+	if fs.NArg() < 2 {
+		fs.Usage()
+		out.Error("prompt name and at least one session are required", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	p := loadPromptOrExit(out, profile, fs.Arg(0))
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+	var targets []*session.Instance
+	for _, ref := range fs.Args()[1:] {
+		targets = append(targets, resolveSessionOrExit(out, ref, instances))
+	}
+
+	type sendResult struct {
+		SessionID string `json:"session_id"`
+		Title     string `json:"session_title"`
+		Message   string `json:"message,omitempty"`
+		Error     string `json:"error,omitempty"`
+	}
+	var results []sendResult
+	var b strings.Builder
+	failed := 0
+	for _, inst := range targets {
+		r := sendResult{SessionID: inst.ID, Title: inst.Title}
+		text, err := sendPrompt(p, inst, *vars, *noWait)
+		if err != nil {
+			r.Error = err.Error()
+			failed++
+			fmt.Fprintf(&b, "%s %s: %v\n", errorSymbol, inst.Title, err)
+		} else {
+			r.Message = text
+			fmt.Fprintf(&b, "%s Sent '%s' to '%s'\n", successSymbol, p.Name, inst.Title)
+		}
+		results = append(results, r)
+	}
+
+	if len(targets) == 1 && failed == 1 {
+		out.Error(results[0].Error, ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	out.Print(b.String(), map[string]interface{}{
+		"success": failed == 0,
+		"prompt":  p.Name,
+		"results": results,
+	})
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// sendPrompt renders p for inst and sends it with the same readiness wait
+// and Enter retry as 'session send'
+func sendPrompt(p *session.Prompt, inst *session.Instance, vars map[string]string, noWait bool) (string, error) {
+	if !inst.Exists() {
+		return "", fmt.Errorf("session is not running")
+	}
+	tmuxSess := inst.GetTmuxSession()
+	if tmuxSess == nil {
+		return "", fmt.Errorf("could not determine tmux session")
+	}
+	text, err := p.Render(inst, vars)
+	if err != nil {
+		return "", err
+	}
+	if noWait {
+		return text, tmuxSess.SendKeysAndEnter(text)
+	}
+	if err := waitForAgentReady(tmuxSess, inst.Tool); err != nil {
+		return "", fmt.Errorf("timeout waiting for agent: %w", err)
+	}
+	return text, sendWithRetry(tmuxSess, text, false)
+}
+
+// promptVarFlag registers the repeatable --var key=value flag
+func promptVarFlag(fs *flag.FlagSet) *map[string]string {
+	vars := map[string]string{}
+	fs.Func("var", "Set a prompt variable, key=value (can specify multiple times)", func(s string) error {
+		key, value, ok := strings.Cut(s, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("expected key=value, got %q", s)
+		}
+		vars[strings.TrimSpace(key)] = value
+		return nil
+	})
+	return &vars
+}
+
+// loadPromptOrExit loads the named prompt, exiting with code 2 when it
+// doesn't exist
+func loadPromptOrExit(out *CLIOutput, profile, name string) *session.Prompt {
+	p, err := session.GetPrompt(profile, name)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(2)
+	}
+	return p
+}
+
+// resolveSessionOrExit resolves ref, exiting with code 2 when no session
+// matches and 1 when it's ambiguous
+func resolveSessionOrExit(out *CLIOutput, ref string, instances []*session.Instance) *session.Instance {
+	inst, errMsg, errCode := ResolveSession(ref, instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+	return inst
+}
@@ -7,6 +7,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/asheshgoplani/agent-deck/internal/git"
 	"github.com/asheshgoplani/agent-deck/internal/profile"
 	"github.com/asheshgoplani/agent-deck/internal/session"
+	"github.com/asheshgoplani/agent-deck/internal/statedb"
 	"github.com/asheshgoplani/agent-deck/internal/tmux"
 )
 
@@ -33,6 +36,8 @@ func handleSession(profile string, args []string) {
 		handleSessionRestart(profile, args[1:])
 	case "fork":
 		handleSessionFork(profile, args[1:])
+	case "handoff":
+		handleSessionHandoff(profile, args[1:])
 	case "attach":
 		handleSessionAttach(profile, args[1:])
 	case "show":
@@ -49,6 +54,12 @@ func handleSession(profile string, args []string) {
 		handleSessionSend(profile, args[1:])
 	case "output":
 		handleSessionOutput(profile, args[1:])
+	case "checkpoints":
+		handleSessionCheckpoints(profile, args[1:])
+	case "context":
+		handleSessionContext(profile, args[1:])
+	case "queue":
+		handleSessionQueue(profile, args[1:])
 	case "help", "--help", "-h":
 		printSessionHelp()
 	default:
@@ -69,12 +80,16 @@ func printSessionHelp() {
 	fmt.Println("  stop <id>               Stop/kill session process")
 	fmt.Println("  restart <id>            Restart session (Claude: reload MCPs)")
 	fmt.Println("  fork <id>               Fork Claude session with context")
+	fmt.Println("  handoff <id> --to <tool>  Continue the work in another agent tool")
 	fmt.Println("  attach <id>             Attach to session interactively")
 	fmt.Println("  show [id]               Show session details (auto-detect current if no id)")
 	fmt.Println("  current                 Show current session and profile (auto-detect)")
 	fmt.Println("  set <id> <field> <value>  Update session property")
 	fmt.Println("  send <id> <message>     Send a message to a running session")
 	fmt.Println("  output <id>             Get the last response from a session")
+	fmt.Println("  checkpoints <id>        List, diff and restore automatic checkpoints")
+	fmt.Println("  context <id>            Show context usage and manage context policies")
+	fmt.Println("  queue <id>              List, reorder and cancel messages queued while busy")
 	fmt.Println("  set-parent <id> <parent>  Link session as sub-session of parent")
 	fmt.Println("  unset-parent <id>       Remove sub-session link")
 	fmt.Println()
@@ -88,6 +103,7 @@ func printSessionHelp() {
 	fmt.Println("  agent-deck session stop abc123")
 	fmt.Println("  agent-deck session restart my-project")
 	fmt.Println("  agent-deck session fork my-project -t \"my-project-fork\"")
+	fmt.Println("  agent-deck session handoff my-project --to codex")
 	fmt.Println("  agent-deck session attach my-project")
 	fmt.Println("  agent-deck session show                  # Auto-detect current session")
 	fmt.Println("  agent-deck session show my-project --json")
@@ -367,7 +383,7 @@ func handleSessionFork(profile string, args []string) {
 	fs.Usage = func() {
 		fmt.Println("Usage: agent-deck session fork <id|title> [options]")
 		fmt.Println()
-		fmt.Println("Fork a Claude session with conversation context.")
+		fmt.Println("Fork a Claude, Gemini, Codex or OpenCode session with conversation context.")
 		fmt.Println()
 		fmt.Println("Options:")
 		fs.PrintDefaults()
@@ -410,24 +426,26 @@ func handleSessionFork(profile string, args []string) {
 		return // unreachable, satisfies staticcheck SA5011
 	}
 
-	// Verify it's a Claude session
-	if inst.Tool != "claude" {
+	// Verify the tool can fork (Claude, Gemini, Codex, OpenCode, adapters
+	// with fork_command)
+	adapter := session.GetToolAdapter(inst.Tool)
+	if adapter == nil {
 		out.Error(
-			fmt.Sprintf("session '%s' is not a Claude session (tool: %s)", inst.Title, inst.Tool),
+			fmt.Sprintf("session '%s' does not support forking (tool: %s)", inst.Title, inst.Tool),
 			ErrCodeInvalidOperation,
 		)
 		os.Exit(1)
 	}
 
-	// Try to capture session ID from tmux if missing (handles pre-fix sessions)
-	if inst.ClaudeSessionID == "" && inst.Exists() {
+	// Try to capture session ID if missing (handles pre-fix sessions)
+	if adapter.SessionID(inst) == "" && inst.Exists() {
 		inst.PostStartSync(2 * time.Second)
 	}
 
 	// Verify it can be forked
 	if !inst.CanFork() {
 		out.Error(
-			fmt.Sprintf("session '%s' cannot be forked: no active Claude session ID", inst.Title),
+			fmt.Sprintf("session '%s' cannot be forked: no active %s session ID", inst.Title, inst.Tool),
 			ErrCodeInvalidOperation,
 		)
 		os.Exit(1)
@@ -451,7 +469,7 @@ func handleSessionFork(profile string, args []string) {
 	createNewBranch := *newBranch || *newBranchLong
 
 	// Handle worktree creation
-	var opts *session.ClaudeOptions
+	var forkOpts *session.ForkOptions
 	if wtBranch != "" {
 		if !git.IsGitRepo(inst.ProjectPath) {
 			out.Error("session path is not a git repository", ErrCodeInvalidOperation)
@@ -491,17 +509,18 @@ func handleSessionFork(profile string, args []string) {
 			out.Error(fmt.Sprintf("worktree creation failed: %v", err), ErrCodeInvalidOperation)
 			os.Exit(1)
 		}
+		provisionNewWorktree(repoRoot, worktreePath)
 
-		userConfig, _ := session.LoadUserConfig()
-		opts = session.NewClaudeOptions(userConfig)
-		opts.WorkDir = worktreePath
-		opts.WorktreePath = worktreePath
-		opts.WorktreeRepoRoot = repoRoot
-		opts.WorktreeBranch = wtBranch
+		forkOpts = &session.ForkOptions{
+			WorkDir:          worktreePath,
+			WorktreePath:     worktreePath,
+			WorktreeRepoRoot: repoRoot,
+			WorktreeBranch:   wtBranch,
+		}
 	}
 
-	// Create the forked instance
-	forkedInst, _, err := inst.CreateForkedInstanceWithOptions(forkTitle, forkGroup, opts)
+	// Create the forked instance through the tool's adapter
+	forkedInst, err := inst.ForkWithAdapter(forkTitle, forkGroup, forkOpts)
 	if err != nil {
 		out.Error(fmt.Sprintf("failed to create fork: %v", err), ErrCodeInvalidOperation)
 		os.Exit(1)
@@ -543,6 +562,140 @@ func handleSessionFork(profile string, args []string) {
 	)
 }
 
+// handleSessionHandoff moves a session's work to another tool: it condenses
+// the transcript into a handoff package and starts a linked child session of
+// the target tool in the same worktree, seeded with that package
+func handleSessionHandoff(profile string, args []string) {
+	fs := flag.NewFlagSet("session handoff", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+	to := fs.String("to", "", "Tool to hand off to (claude, gemini, codex, opencode or a [tools] entry)")
+	title := fs.String("title", "", "Title for the new session")
+	titleShort := fs.String("t", "", "Title for the new session (short)")
+	turns := fs.Int("turns", session.DefaultHandoffTurns, "Number of recent messages to include")
+	dryRun := fs.Bool("dry-run", false, "Print the handoff package without starting a session")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session handoff <id|title> --to <tool> [options]")
+		fmt.Println()
+		fmt.Println("Continue a session's work in another agent tool. The goal, recent turns,")
+		fmt.Println("modified files and open TODOs are condensed from the transcript and sent")
+		fmt.Println("as the first message of a new sub-session in the same worktree.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck session handoff my-project --to codex")
+		fmt.Println("  agent-deck session handoff my-project --to gemini -t \"review\"")
+		fmt.Println("  agent-deck session handoff my-project --to claude --dry-run")
+	}
+
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+
+	identifier := fs.Arg(0)
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	if *to == "" {
+		out.Error("--to is required (e.g. --to codex)", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	if session.GetToolAdapter(*to) == nil {
+		out.Error(fmt.Sprintf("unknown tool '%s' (expected claude, gemini, codex, opencode or a [tools] entry)", *to), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	storage, instances, groupsData, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	inst, errMsg, errCode := ResolveSession(identifier, instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	// The transcript is located through the tool's session ID
+	if adapter := session.GetToolAdapter(inst.Tool); adapter != nil && adapter.SessionID(inst) == "" && inst.Exists() {
+		inst.PostStartSync(2 * time.Second)
+	}
+
+	pkg, err := session.BuildHandoffPackage(inst, *turns)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to build handoff package: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	prompt := pkg.Prompt()
+
+	if *dryRun {
+		out.Print(prompt, map[string]interface{}{
+			"source_id":      inst.ID,
+			"to":             *to,
+			"goal":           pkg.Goal,
+			"modified_files": pkg.ModifiedFiles,
+			"open_todos":     pkg.OpenTodos,
+			"prompt":         prompt,
+		})
+		return
+	}
+
+	newTitle := mergeFlags(*title, *titleShort)
+	if newTitle == "" {
+		newTitle = generateUniqueTitle(instances, inst.Title+"-"+*to, inst.ProjectPath)
+	}
+
+	handoffInst, err := inst.NewHandoffInstance(*to, newTitle)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	instances = append(instances, handoffInst)
+	groupTree := session.NewGroupTreeWithGroups(instances, groupsData)
+	if handoffInst.GroupPath != "" {
+		groupTree.CreateGroup(handoffInst.GroupPath)
+	}
+	if err := storage.SaveWithGroups(instances, groupTree); err != nil {
+		out.Error(fmt.Sprintf("failed to save: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	// Waits for the new agent to be ready, then delivers the package
+	if err := handoffInst.StartWithMessage(prompt); err != nil {
+		out.Error(fmt.Sprintf("failed to start handoff session: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	handoffInst.PostStartSync(3 * time.Second)
+
+	if err := saveSessionData(storage, instances); err != nil {
+		out.Error(fmt.Sprintf("failed to save session state: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	out.Success(
+		fmt.Sprintf("Handed off: %s (%s) -> %s (%s, %s)", inst.Title, inst.Tool, handoffInst.Title, *to, TruncateID(handoffInst.ID)),
+		map[string]interface{}{
+			"success":        true,
+			"source_id":      inst.ID,
+			"parent_id":      handoffInst.ParentSessionID,
+			"new_id":         handoffInst.ID,
+			"new_title":      handoffInst.Title,
+			"tool":           *to,
+			"modified_files": pkg.ModifiedFiles,
+			"open_todos":     pkg.OpenTodos,
+		},
+	)
+}
+
 // handleSessionAttach attaches to a session interactively
 func handleSessionAttach(profile string, args []string) {
 	fs := flag.NewFlagSet("session attach", flag.ExitOnError)
@@ -706,6 +859,12 @@ func handleSessionShow(profile string, args []string) {
 		}
 	}
 
+	// Analytics share one shape across Claude, Gemini, Codex and OpenCode
+	analytics, _ := inst.LoadAnalytics()
+	if analytics != nil {
+		jsonData["analytics"] = analytics
+	}
+
 	// Build human-readable output
 	var sb strings.Builder
 
@@ -755,6 +914,10 @@ func handleSessionShow(profile string, args []string) {
 		}
 	}
 
+	if analytics != nil {
+		sb.WriteString(formatAnalyticsSummary(analytics))
+	}
+
 	sb.WriteString(fmt.Sprintf("Created: %s\n", inst.CreatedAt.Format("2006-01-02 15:04:05")))
 
 	if !inst.LastAccessedAt.IsZero() {
@@ -771,6 +934,32 @@ func handleSessionShow(profile string, args []string) {
 	out.Print(sb.String(), jsonData)
 }
 
+// formatAnalyticsSummary renders the human-readable analytics lines of session show
+func formatAnalyticsSummary(a *session.AgentAnalytics) string {
+	var sb strings.Builder
+	if a.Model != "" {
+		sb.WriteString(fmt.Sprintf("Model:   %s\n", a.Model))
+	}
+	sb.WriteString(fmt.Sprintf("Tokens:  in=%d out=%d cache_read=%d cache_write=%d (context %.1f%%)\n",
+		a.InputTokens, a.OutputTokens, a.CacheReadTokens, a.CacheWriteTokens, a.ContextPercent()))
+	sb.WriteString(fmt.Sprintf("Turns:   %d", a.TotalTurns))
+	if a.EstimatedCost > 0 {
+		sb.WriteString(fmt.Sprintf(" (est. $%.4f)", a.EstimatedCost))
+	}
+	sb.WriteString("\n")
+	if len(a.ToolCalls) > 0 {
+		calls := make([]session.ToolCall, len(a.ToolCalls))
+		copy(calls, a.ToolCalls)
+		sort.SliceStable(calls, func(i, j int) bool { return calls[i].Count > calls[j].Count })
+		parts := make([]string, 0, len(calls))
+		for _, c := range calls {
+			parts = append(parts, fmt.Sprintf("%s(%d)", c.Name, c.Count))
+		}
+		sb.WriteString(fmt.Sprintf("Tools:   %s\n", strings.Join(parts, ", ")))
+	}
+	return sb.String()
+}
+
 func mcpInfoForJSON(mcpInfo *session.MCPInfo) map[string]interface{} {
 	if mcpInfo == nil || !mcpInfo.HasAny() {
 		return nil
@@ -803,6 +992,20 @@ func handleSessionSet(profile string, args []string) {
 		fmt.Println("  claude-session-id  Claude conversation ID")
 		fmt.Println("  gemini-session-id  Gemini conversation ID")
 		fmt.Println()
+		fmt.Println("Claude launch options (applied on next start/restart, empty value clears):")
+		fmt.Println("  model                 Model alias or name (e.g., sonnet, opus)")
+		fmt.Println("  permission-mode       " + strings.Join(session.ClaudePermissionModes, ", "))
+		fmt.Println("  allowed-tools         Comma-separated tools allowed without asking")
+		fmt.Println("  disallowed-tools      Comma-separated tools Claude may not use")
+		fmt.Println("  append-system-prompt  Text appended to the system prompt")
+		fmt.Println("  add-dirs              Comma-separated extra directories")
+		fmt.Println()
+		fmt.Println("Codex launch options (applied on next start/restart, empty value clears):")
+		fmt.Println("  model                 Model name (e.g., gpt-5-codex)")
+		fmt.Println("  codex-profile         Profile from ~/.codex/config.toml")
+		fmt.Println("  sandbox               " + strings.Join(session.CodexSandboxModes, ", "))
+		fmt.Println("  approval-policy       " + strings.Join(session.CodexApprovalPolicies, ", "))
+		fmt.Println()
 		fmt.Println("Options:")
 		fs.PrintDefaults()
 		fmt.Println()
@@ -811,6 +1014,9 @@ func handleSessionSet(profile string, args []string) {
 		fmt.Println("  agent-deck session set my-project claude-session-id \"abc123-def456\"")
 		fmt.Println("  agent-deck session set my-project path /new/path/to/project")
 		fmt.Println("  agent-deck session set my-project wrapper \"nvim +'terminal {command}'\"")
+		fmt.Println("  agent-deck session set my-project permission-mode plan")
+		fmt.Println("  agent-deck session set my-project allowed-tools \"Bash(git:*),Edit\"")
+		fmt.Println("  agent-deck session set my-codex sandbox read-only")
 	}
 
 	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
@@ -838,12 +1044,15 @@ func handleSessionSet(profile string, args []string) {
 		"claude-session-id": true,
 		"gemini-session-id": true,
 	}
+	for _, f := range toolOptionFields {
+		validFields[f] = true
+	}
 
 	if !validFields[field] {
 		out.Error(
 			fmt.Sprintf(
-				"invalid field: %s\nValid fields: title, path, command, tool, wrapper, claude-session-id, gemini-session-id",
-				field,
+				"invalid field: %s\nValid fields: title, path, command, tool, wrapper, claude-session-id, gemini-session-id, %s",
+				field, strings.Join(toolOptionFields, ", "),
 			),
 			ErrCodeInvalidOperation,
 		)
@@ -873,6 +1082,12 @@ func handleSessionSet(profile string, args []string) {
 
 	// Apply the update
 	switch field {
+	case "model", "permission-mode", "allowed-tools", "disallowed-tools", "append-system-prompt", "add-dirs",
+		"codex-profile", "sandbox", "approval-policy":
+		if oldValue, err = setToolOptionField(inst, field, value); err != nil {
+			out.Error(err.Error(), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
 	case "title":
 		oldValue = inst.Title
 		inst.Title = value
@@ -1279,13 +1494,16 @@ func handleSessionSend(profile string, args []string) {
 	jsonOutput := fs.Bool("json", false, "Output as JSON")
 	quiet := fs.Bool("q", false, "Quiet mode")
 	noWait := fs.Bool("no-wait", false, "Don't wait for agent to be ready (send immediately)")
+	noQueue := fs.Bool("no-queue", false, "Don't queue while the agent is busy; wait for it instead")
 	wait := fs.Bool("wait", false, "Block until agent finishes processing, then print output")
 	timeout := fs.Duration("timeout", 10*time.Minute, "Max time to wait for completion (used with --wait)")
 
 	fs.Usage = func() {
 		fmt.Println("Usage: agent-deck session send <id|title> <message> [options]")
 		fmt.Println()
-		fmt.Println("Send a message to a running session.")
+		fmt.Println("Send a message to a running session. While the agent is busy the message is")
+		fmt.Println("queued and delivered when it finishes its turn (see 'session queue'). Queueing")
+		fmt.Println("needs the TUI or notify-daemon running; otherwise send waits for the agent.")
 		fmt.Println()
 		fmt.Println("Options:")
 		fs.PrintDefaults()
@@ -1313,7 +1531,7 @@ func handleSessionSend(profile string, args []string) {
 	message := strings.Join(remaining[1:], " ")
 
 	// Load sessions
-	_, instances, _, err := loadSessionData(profile)
+	storage, instances, _, err := loadSessionData(profile)
 	if err != nil {
 		out.Error(err.Error(), ErrCodeNotFound)
 		os.Exit(1)
@@ -1343,6 +1561,64 @@ func handleSessionSend(profile string, args []string) {
 		os.Exit(1)
 	}
 
+	// Queue instead of typing into a busy agent. --wait needs the reply to
+	// this message, so it keeps waiting for the agent instead, and --no-wait
+	// sends immediately. Without a TUI or notify-daemon nothing would deliver
+	// the queue, so wait as well. Messages already queued go first even when
+	// the agent is idle (a failed delivery or a missed turn end), so this one
+	// never jumps ahead of them.
+	db := storage.GetDB()
+	if db != nil && !*noQueue && !*wait && !*noWait && (queueDepth(db, inst.ID) > 0 || (sessionIsBusy(inst) && session.QueueDelivererAlive(db))) {
+		queued, err := db.EnqueueMessage(inst.ID, message)
+		if err != nil {
+			out.Error(fmt.Sprintf("failed to queue message: %v", err), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		// The turn may have ended while queueing, with nothing left to
+		// deliver the message on the next transition, so deliver the head
+		// now. Without a deliverer, send the queue in order up to this
+		// message; each send waits for the agent to be ready.
+		deliveries := 0
+		if !session.QueueDelivererAlive(db) {
+			deliveries = -1
+		} else if !sessionIsBusy(inst) {
+			deliveries = 1
+		}
+		for ; deliveries != 0; deliveries-- {
+			delivered, err := session.DeliverNextQueuedMessage(profile, db, inst.ID)
+			if err != nil {
+				out.Error(fmt.Sprintf("failed to send queued message: %v", err), ErrCodeInvalidOperation)
+				os.Exit(1)
+			}
+			if delivered == nil {
+				break
+			}
+			if delivered.ID == queued.ID {
+				out.Success(fmt.Sprintf("Sent message to '%s'", inst.Title), map[string]interface{}{
+					"success":       true,
+					"session_id":    inst.ID,
+					"session_title": inst.Title,
+					"message":       message,
+				})
+				return
+			}
+		}
+		depth := 0
+		if msgs, err := db.LoadQueuedMessages(inst.ID); err == nil {
+			depth = len(msgs)
+		}
+		out.Success(fmt.Sprintf("Queued message for '%s' (%d in queue)", inst.Title, depth), map[string]interface{}{
+			"success":       true,
+			"queued":        true,
+			"queue_id":      queued.ID,
+			"queue_depth":   depth,
+			"session_id":    inst.ID,
+			"session_title": inst.Title,
+			"message":       message,
+		})
+		return
+	}
+
 	// Wait for agent to be ready (unless --no-wait is specified)
 	if !*noWait {
 		if err := waitForAgentReady(tmuxSess, inst.Tool); err != nil {
@@ -2052,3 +2328,556 @@ func matchInstanceDataByTmuxName(instances []*session.InstanceData, tmuxSessionN
 	}
 	return nil
 }
+
+// handleSessionCheckpoints lists, diffs and restores a session's automatic
+// checkpoints, and turns auto-checkpointing on or off.
+func handleSessionCheckpoints(profile string, args []string) {
+	fs := flag.NewFlagSet("session checkpoints", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+	clean := fs.Bool("clean", false, "On restore, delete files created after the checkpoint")
+	group := fs.Bool("group", false, "On enable/disable, apply to the session's whole group")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session checkpoints <id|title> [list|diff|restore|enable|disable] [options]")
+		fmt.Println()
+		fmt.Println("Manage automatic checkpoints. When enabled, the session's working tree is")
+		fmt.Println("snapshotted each time the agent goes from running to waiting or idle. Snapshots")
+		fmt.Println("are stored under refs/agent-deck/checkpoints/ and never appear in git log.")
+		fmt.Println()
+		fmt.Println("Actions:")
+		fmt.Println("  list                  List checkpoints (default)")
+		fmt.Println("  diff <n> [m]          Diff checkpoint n against the working tree (or checkpoint m)")
+		fmt.Println("  restore <n>           Restore the working tree to checkpoint n")
+		fmt.Println("  enable                Enable auto-checkpoints")
+		fmt.Println("  disable               Disable auto-checkpoints")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck session checkpoints my-project enable")
+		fmt.Println("  agent-deck session checkpoints my-project enable --group")
+		fmt.Println("  agent-deck session checkpoints my-project")
+		fmt.Println("  agent-deck session checkpoints my-project diff 3")
+		fmt.Println("  agent-deck session checkpoints my-project restore 3 --clean")
+	}
+
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+	inst, errMsg, errCode := ResolveSession(fs.Arg(0), instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	action := "list"
+	if fs.NArg() > 1 {
+		action = fs.Arg(1)
+	}
+	checkpointArg := func(i int) int {
+		n, err := strconv.Atoi(fs.Arg(i))
+		if err != nil || n < 1 {
+			out.Error(fmt.Sprintf("%s requires a checkpoint number", action), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		return n
+	}
+
+	switch action {
+	case "enable", "disable":
+		enabled := action == "enable"
+		target := fmt.Sprintf("session '%s'", inst.Title)
+		if *group {
+			err = session.SetGroupCheckpoints(profile, inst.GroupPath, enabled)
+			target = fmt.Sprintf("group '%s'", inst.GroupPath)
+		} else {
+			err = session.SetSessionCheckpoints(profile, inst.ID, enabled)
+		}
+		if err != nil {
+			out.Error(fmt.Sprintf("failed to save checkpoint settings: %v", err), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		out.Success(fmt.Sprintf("Auto-checkpoints %sd for %s", action, target), map[string]interface{}{
+			"success":    true,
+			"session_id": inst.ID,
+			"group":      *group,
+			"group_path": inst.GroupPath,
+			"enabled":    enabled,
+		})
+		return
+	}
+
+	dir := session.CheckpointDir(inst)
+	if dir == "" || !git.IsGitRepo(dir) {
+		out.Error(fmt.Sprintf("session '%s' is not in a git repository", inst.Title), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	switch action {
+	case "list":
+		checkpoints, err := git.ListCheckpoints(dir, inst.ID)
+		if err != nil {
+			out.Error(err.Error(), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		settings, _ := session.LoadCheckpointSettings(profile)
+		enabled := settings != nil && settings.Enabled(inst)
+		var b strings.Builder
+		state := "disabled"
+		if enabled {
+			state = "enabled"
+		}
+		fmt.Fprintf(&b, "Checkpoints for '%s' (auto-checkpoints %s)\n", inst.Title, state)
+		if len(checkpoints) == 0 {
+			b.WriteString("  No checkpoints yet\n")
+		}
+		for _, cp := range checkpoints {
+			fmt.Fprintf(&b, "  %s %3d  %s  %s  %s\n", bulletSymbol, cp.N, cp.Commit[:min(len(cp.Commit), 8)],
+				cp.Created.Format("2006-01-02 15:04:05"), cp.Message)
+		}
+		if checkpoints == nil {
+			checkpoints = []git.Checkpoint{}
+		}
+		out.Print(b.String(), map[string]interface{}{
+			"session_id":  inst.ID,
+			"enabled":     enabled,
+			"path":        dir,
+			"checkpoints": checkpoints,
+		})
+
+	case "diff":
+		n := checkpointArg(2)
+		other := 0
+		if fs.NArg() > 3 {
+			other = checkpointArg(3)
+		}
+		diff, err := git.CheckpointDiff(dir, inst.ID, n, other)
+		if err != nil {
+			out.Error(err.Error(), ErrCodeNotFound)
+			os.Exit(1)
+		}
+		out.Print(diff, map[string]interface{}{
+			"session_id": inst.ID,
+			"from":       n,
+			"to":         other,
+			"diff":       diff,
+		})
+
+	case "restore":
+		n := checkpointArg(2)
+		safety, removed, err := git.RestoreCheckpoint(dir, inst.ID, n, *clean)
+		if err != nil {
+			out.Error(err.Error(), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s Restored '%s' to checkpoint %d\n", successSymbol, inst.Title, n)
+		if safety != nil {
+			fmt.Fprintf(&b, "  Previous state saved as checkpoint %d\n", safety.N)
+		}
+		for _, path := range removed {
+			fmt.Fprintf(&b, "  Removed %s\n", path)
+		}
+		jsonData := map[string]interface{}{
+			"success":    true,
+			"session_id": inst.ID,
+			"restored":   n,
+			"removed":    removed,
+		}
+		if safety != nil {
+			jsonData["saved_as"] = safety.N
+		}
+		out.Print(b.String(), jsonData)
+
+	default:
+		out.Error(fmt.Sprintf("unknown checkpoints action: %s", action), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+}
+
+// queueDepth returns how many messages are queued for a session
+func queueDepth(db *statedb.StateDB, instanceID string) int {
+	msgs, err := db.LoadQueuedMessages(instanceID)
+	if err != nil {
+		return 0
+	}
+	return len(msgs)
+}
+
+// sessionIsBusy refreshes inst's status and reports whether the agent is
+// mid-turn
+func sessionIsBusy(inst *session.Instance) bool {
+	_ = inst.UpdateStatus()
+	return inst.GetStatusThreadSafe() == session.StatusRunning
+}
+
+// handleSessionQueue lists, reorders and cancels messages queued for a busy
+// session by 'session send'
+func handleSessionQueue(profile string, args []string) {
+	fs := flag.NewFlagSet("session queue", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session queue <id|title> [list|move <msg> <position>|cancel <msg>|clear] [options]")
+		fmt.Println()
+		fmt.Println("Messages sent with 'session send' while the agent is busy wait in a queue and")
+		fmt.Println("are delivered one per turn, each time the agent goes from running to waiting")
+		fmt.Println("or idle. Delivery runs in the TUI, or in the notification daemon when no TUI")
+		fmt.Println("is open.")
+		fmt.Println()
+		fmt.Println("Actions:")
+		fmt.Println("  list                  Show queued messages, next first (default)")
+		fmt.Println("  move <msg> <position> Move a message (1 = next to deliver)")
+		fmt.Println("  cancel <msg>          Remove a message from the queue")
+		fmt.Println("  clear                 Remove all queued messages")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck session queue my-project")
+		fmt.Println("  agent-deck session queue my-project move 12 1")
+		fmt.Println("  agent-deck session queue my-project cancel 12")
+	}
+
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	storage, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+	inst, errMsg, errCode := ResolveSession(fs.Arg(0), instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+	db := storage.GetDB()
+	if db == nil {
+		out.Error("message queue requires the state database", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	action := "list"
+	if fs.NArg() > 1 {
+		action = fs.Arg(1)
+	}
+
+	// parseMessageID reads the queued message ID argument at index i
+	parseMessageID := func(i int) int64 {
+		if fs.NArg() <= i {
+			out.Error(fmt.Sprintf("%s requires a message ID (see 'session queue %s')", action, fs.Arg(0)), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		id, err := strconv.ParseInt(fs.Arg(i), 10, 64)
+		if err != nil {
+			out.Error(fmt.Sprintf("invalid message ID: %s", fs.Arg(i)), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		return id
+	}
+
+	switch action {
+	case "list", "ls":
+		msgs, err := db.LoadQueuedMessages(inst.ID)
+		if err != nil {
+			out.Error(fmt.Sprintf("failed to load queue: %v", err), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		var b strings.Builder
+		if len(msgs) == 0 {
+			fmt.Fprintf(&b, "No queued messages for '%s'\n", inst.Title)
+		} else {
+			fmt.Fprintf(&b, "Queued messages for '%s':\n", inst.Title)
+		}
+		jsonMsgs := make([]map[string]interface{}, 0, len(msgs))
+		for k, m := range msgs {
+			preview := strings.ReplaceAll(m.Message, "\n", " ")
+			if r := []rune(preview); len(r) > 60 {
+				preview = string(r[:57]) + "..."
+			}
+			fmt.Fprintf(&b, "  %d. [%d] %s  %s\n", k+1, m.ID, m.CreatedAt.Format("15:04:05"), preview)
+			jsonMsgs = append(jsonMsgs, map[string]interface{}{
+				"id":         m.ID,
+				"position":   k + 1,
+				"message":    m.Message,
+				"created_at": m.CreatedAt.Format(time.RFC3339),
+			})
+		}
+		out.Print(b.String(), map[string]interface{}{
+			"session_id": inst.ID,
+			"messages":   jsonMsgs,
+		})
+
+	case "move":
+		id := parseMessageID(2)
+		if fs.NArg() < 4 {
+			out.Error("move requires a position (1 = next to deliver)", ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		position, err := strconv.Atoi(fs.Arg(3))
+		if err != nil || position < 1 {
+			out.Error(fmt.Sprintf("invalid position: %s", fs.Arg(3)), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		found, err := db.MoveQueuedMessage(inst.ID, id, position-1)
+		if err != nil {
+			out.Error(fmt.Sprintf("failed to move message: %v", err), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		if !found {
+			out.Error(fmt.Sprintf("no queued message %d for '%s'", id, inst.Title), ErrCodeNotFound)
+			os.Exit(2)
+		}
+		out.Success(fmt.Sprintf("Moved message %d to position %d", id, position), map[string]interface{}{
+			"success":    true,
+			"session_id": inst.ID,
+			"id":         id,
+			"position":   position,
+		})
+
+	case "cancel", "rm":
+		id := parseMessageID(2)
+		found, err := db.DeleteQueuedMessage(inst.ID, id)
+		if err != nil {
+			out.Error(fmt.Sprintf("failed to cancel message: %v", err), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		if !found {
+			out.Error(fmt.Sprintf("no queued message %d for '%s'", id, inst.Title), ErrCodeNotFound)
+			os.Exit(2)
+		}
+		out.Success(fmt.Sprintf("Cancelled message %d", id), map[string]interface{}{
+			"success":    true,
+			"session_id": inst.ID,
+			"id":         id,
+		})
+
+	case "clear":
+		n, err := db.ClearQueuedMessages(inst.ID)
+		if err != nil {
+			out.Error(fmt.Sprintf("failed to clear queue: %v", err), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		out.Success(fmt.Sprintf("Cleared %d queued message(s) for '%s'", n, inst.Title), map[string]interface{}{
+			"success":    true,
+			"session_id": inst.ID,
+			"cleared":    n,
+		})
+
+	default:
+		out.Error(fmt.Sprintf("unknown queue action: %s", action), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+}
+
+// handleSessionContext shows a session's context usage and manages the
+// context policy that acts when usage crosses thresholds
+func handleSessionContext(profile string, args []string) {
+	fs := flag.NewFlagSet("session context", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+	group := fs.Bool("group", false, "On set/clear, apply to the session's whole group")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session context <id|title> [show|set <rule>...|off|clear] [options]")
+		fmt.Println()
+		fmt.Println("Show context-window usage and manage context policies. Policies are checked")
+		fmt.Println("each time the agent goes from running to waiting or idle; each rule acts once")
+		fmt.Println("when usage reaches its threshold and re-arms when usage drops below it.")
+		fmt.Println()
+		fmt.Println("Rules are <percent>:<action>[:<instruction>]. Actions:")
+		fmt.Println("  compact               Send /compact (Gemini: /compress); Claude takes the instruction")
+		fmt.Println("  notify                Message the parent session, or the conductor")
+		fmt.Println("  fork                  Start a fresh session seeded with a handoff summary")
+		fmt.Println()
+		fmt.Println("Actions:")
+		fmt.Println("  show                  Show usage and the policy in effect (default)")
+		fmt.Println("  set <rule>...         Replace the policy")
+		fmt.Println("  off                   Opt the session out of its group's policy")
+		fmt.Println("  clear                 Remove the session's (or group's) policy")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck session context my-project")
+		fmt.Println("  agent-deck session context my-project set 70:notify \"85:compact:keep the API decisions\"")
+		fmt.Println("  agent-deck session context my-project set 90:fork --group")
+		fmt.Println("  agent-deck session context my-project clear")
+	}
+
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+	inst, errMsg, errCode := ResolveSession(fs.Arg(0), instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	action := "show"
+	if fs.NArg() > 1 {
+		action = fs.Arg(1)
+	}
+
+	switch action {
+	case "set", "off", "clear":
+		var rules []session.ContextRule
+		switch action {
+		case "set":
+			if fs.NArg() < 3 {
+				out.Error("set requires at least one rule (e.g. 85:compact)", ErrCodeInvalidOperation)
+				os.Exit(1)
+			}
+			for _, spec := range fs.Args()[2:] {
+				rule, err := session.ParseContextRule(spec)
+				if err != nil {
+					out.Error(err.Error(), ErrCodeInvalidOperation)
+					os.Exit(1)
+				}
+				rules = append(rules, rule)
+			}
+		case "off":
+			if *group {
+				out.Error("off applies to a single session; use clear --group to remove a group policy", ErrCodeInvalidOperation)
+				os.Exit(1)
+			}
+			rules = []session.ContextRule{}
+		}
+
+		target := fmt.Sprintf("session '%s'", inst.Title)
+		if *group {
+			err = session.SetGroupContextPolicy(profile, inst.GroupPath, rules)
+			target = fmt.Sprintf("group '%s'", inst.GroupPath)
+		} else {
+			err = session.SetSessionContextPolicy(profile, inst.ID, rules)
+		}
+		if err != nil {
+			out.Error(fmt.Sprintf("failed to save context policy: %v", err), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+
+		specs := make([]string, 0, len(rules))
+		for _, r := range rules {
+			specs = append(specs, r.String())
+		}
+		msg := fmt.Sprintf("Context policy for %s: %s", target, strings.Join(specs, ", "))
+		switch {
+		case action == "off":
+			msg = fmt.Sprintf("Context policy turned off for %s", target)
+		case rules == nil:
+			msg = fmt.Sprintf("Context policy cleared for %s", target)
+		}
+		out.Success(msg, map[string]interface{}{
+			"success":    true,
+			"session_id": inst.ID,
+			"group":      *group,
+			"group_path": inst.GroupPath,
+			"rules":      specs,
+		})
+
+	case "show":
+		settings, err := session.LoadContextPolicySettings(profile)
+		if err != nil {
+			out.Error(err.Error(), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		rules := settings.Rules(inst)
+		analytics, _ := inst.LoadAnalytics()
+
+		var b strings.Builder
+		jsonData := map[string]interface{}{
+			"session_id": inst.ID,
+			"rules":      rules,
+			"fired":      settings.Fired[inst.ID],
+		}
+		if analytics != nil {
+			fmt.Fprintf(&b, "Context for '%s': %.0f%% (%d/%d tokens", inst.Title,
+				analytics.ContextPercent(), analytics.CurrentContextTokens, analytics.ContextLimit())
+			if analytics.Model != "" {
+				fmt.Fprintf(&b, ", %s", analytics.Model)
+			}
+			b.WriteString(")\n")
+			jsonData["model"] = analytics.Model
+			jsonData["context_tokens"] = analytics.CurrentContextTokens
+			jsonData["context_limit"] = analytics.ContextLimit()
+			jsonData["context_percent"] = analytics.ContextPercent()
+		} else {
+			fmt.Fprintf(&b, "Context for '%s': no usage data\n", inst.Title)
+		}
+		if len(rules) == 0 {
+			b.WriteString("  No context policy\n")
+		}
+		fired := map[float64]bool{}
+		for _, t := range settings.Fired[inst.ID] {
+			fired[t] = true
+		}
+		for _, r := range rules {
+			state := ""
+			if fired[r.Threshold] {
+				state = "  (fired)"
+			}
+			fmt.Fprintf(&b, "  %s %s%s\n", bulletSymbol, r.String(), state)
+		}
+		if rules == nil {
+			rules = []session.ContextRule{}
+		}
+		jsonData["rules"] = rules
+		out.Print(b.String(), jsonData)
+
+	default:
+		out.Error(fmt.Sprintf("unknown context action: %s", action), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+}
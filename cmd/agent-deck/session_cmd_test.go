@@ -44,3 +44,42 @@ func TestMCPInfoForJSON_UsesSlicesAndIsMarshalable(t *testing.T) {
 		t.Fatalf("json.Marshal failed: %v", err)
 	}
 }
+
+func TestSetClaudeOptionField(t *testing.T) {
+	opts := &session.ClaudeOptions{Model: "sonnet", AddDirs: []string{"/a"}}
+
+	old, err := setClaudeOptionField(opts, "model", "opus")
+	if err != nil || old != "sonnet" || opts.Model != "opus" {
+		t.Fatalf("model: old=%q err=%v opts=%+v", old, err, opts)
+	}
+	if _, err := setClaudeOptionField(opts, "allowed-tools", "Bash(git:*), Edit"); err != nil {
+		t.Fatal(err)
+	}
+	if len(opts.AllowedTools) != 2 || opts.AllowedTools[0] != "Bash(git:*)" {
+		t.Fatalf("allowed-tools = %v", opts.AllowedTools)
+	}
+	old, _ = setClaudeOptionField(opts, "add-dirs", "")
+	if old != "/a" || opts.AddDirs != nil {
+		t.Fatalf("add-dirs: old=%q dirs=%v", old, opts.AddDirs)
+	}
+	if _, err := setClaudeOptionField(opts, "permission-mode", "yolo"); err == nil {
+		t.Fatal("invalid permission mode should be rejected")
+	}
+}
+
+func TestSetCodexOptionField(t *testing.T) {
+	opts := &session.CodexOptions{}
+	if _, err := setCodexOptionField(opts, "sandbox", "read-only"); err != nil || opts.Sandbox != "read-only" {
+		t.Fatalf("sandbox: err=%v opts=%+v", err, opts)
+	}
+	if _, err := setCodexOptionField(opts, "approval-policy", "sometimes"); err == nil {
+		t.Fatal("invalid approval policy should be rejected")
+	}
+	if _, err := setCodexOptionField(opts, "permission-mode", "plan"); err == nil {
+		t.Fatal("Claude-only field should be rejected for Codex")
+	}
+	old, _ := setCodexOptionField(opts, "sandbox", "")
+	if old != "read-only" || opts.Sandbox != "" {
+		t.Fatalf("clearing sandbox: old=%q opts=%+v", old, opts)
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleTemplate dispatches template subcommands
+func handleTemplate(profile string, args []string) {
+	if len(args) == 0 {
+		handleTemplateList(profile, nil)
+		return
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		handleTemplateList(profile, args[1:])
+	case "show":
+		handleTemplateShow(profile, args[1:])
+	case "save":
+		handleTemplateSave(profile, args[1:])
+	case "delete", "rm":
+		handleTemplateDelete(profile, args[1:])
+	case "help", "--help", "-h":
+		printTemplateHelp()
+	default:
+		fmt.Printf("Unknown template command: %s\n", args[0])
+		fmt.Println()
+		printTemplateHelp()
+		os.Exit(1)
+	}
+}
+
+// printTemplateHelp prints usage for template commands
+func printTemplateHelp() {
+	fmt.Println("Usage: agent-deck template <command> [options]")
+	fmt.Println()
+	fmt.Println("Save session setups (tool, command, wrapper, worktree, MCPs, skills, tool")
+	fmt.Println("options, initial message) as named templates for this profile.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  list                     List templates")
+	fmt.Println("  show <name>              Show a template")
+	fmt.Println("  save <session> <name>    Save a session's setup as a template")
+	fmt.Println("  delete <name>            Delete a template")
+	fmt.Println()
+	fmt.Println("Placeholders in title, branch, message, command and wrapper:")
+	fmt.Println("  {project}  project directory name    {branch}  worktree branch")
+	fmt.Println("  {title}    session title             {group}   group path")
+	fmt.Println("  {path}     project path              {date}    today (YYYY-MM-DD)")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck template save my-project reviewer -m \"Review {branch} against main\"")
+	fmt.Println("  agent-deck template save api-work feature --branch \"feature/{title}\" --title \"{project}-{branch}\"")
+	fmt.Println("  agent-deck add --template reviewer -w fix/login .")
+	fmt.Println("  agent-deck launch --template feature -t payments ~/src/api")
+}
+
+// handleTemplateList lists templates
+func handleTemplateList(profile string, args []string) {
+	fs := flag.NewFlagSet("template list", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+	out := NewCLIOutput(*jsonOutput, false)
+
+	templates, err := session.ListTemplates(profile)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	var b strings.Builder
+	if len(templates) == 0 {
+		b.WriteString("No templates. Save one with: agent-deck template save <session> <name>\n")
+	}
+	for _, t := range templates {
+		fmt.Fprintf(&b, "%s %-20s %s\n", bulletSymbol, t.Name, templateSummary(t))
+	}
+	out.Print(b.String(), map[string]interface{}{"templates": templates})
+}
+
+// templateSummary is a short description like "claude, worktree, 2 MCPs".
+func templateSummary(t session.SessionTemplate) string {
+	parts := []string{t.Tool}
+	if t.Worktree {
+		parts = append(parts, "worktree")
+	}
+	if len(t.MCPs) > 0 {
+		parts = append(parts, fmt.Sprintf("%d MCPs", len(t.MCPs)))
+	}
+	if len(t.Skills) > 0 {
+		parts = append(parts, fmt.Sprintf("%d skills", len(t.Skills)))
+	}
+	if t.InitialMessage != "" {
+		parts = append(parts, "message")
+	}
+	if t.Description != "" {
+		parts = append(parts, "- "+t.Description)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// handleTemplateShow prints one template
+func handleTemplateShow(profile string, args []string) {
+	fs := flag.NewFlagSet("template show", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+	out := NewCLIOutput(*jsonOutput, false)
+	if fs.NArg() < 1 {
+		out.Error("template name is required", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	t, err := session.GetTemplate(profile, fs.Arg(0))
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(2)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Template:  %s\n", t.Name)
+	if t.Description != "" {
+		fmt.Fprintf(&b, "About:     %s\n", t.Description)
+	}
+	fmt.Fprintf(&b, "Tool:      %s\n", t.Tool)
+	if t.Command != "" && t.Command != t.Tool {
+		fmt.Fprintf(&b, "Command:   %s\n", t.Command)
+	}
+	if t.Wrapper != "" {
+		fmt.Fprintf(&b, "Wrapper:   %s\n", t.Wrapper)
+	}
+	if t.Path != "" {
+		fmt.Fprintf(&b, "Path:      %s\n", FormatPath(t.Path))
+	}
+	if t.Group != "" {
+		fmt.Fprintf(&b, "Group:     %s\n", t.Group)
+	}
+	if t.Title != "" {
+		fmt.Fprintf(&b, "Title:     %s\n", t.Title)
+	}
+	if t.Worktree {
+		branch := t.Branch
+		if branch == "" {
+			branch = session.DefaultTemplateBranch
+		}
+		fmt.Fprintf(&b, "Worktree:  %s\n", branch)
+	}
+	if len(t.MCPs) > 0 {
+		fmt.Fprintf(&b, "MCPs:      %s\n", strings.Join(t.MCPs, ", "))
+	}
+	if len(t.Skills) > 0 {
+		names := make([]string, len(t.Skills))
+		for i, s := range t.Skills {
+			names[i] = s.Name
+		}
+		fmt.Fprintf(&b, "Skills:    %s\n", strings.Join(names, ", "))
+	}
+	if t.InitialMessage != "" {
+		fmt.Fprintf(&b, "Message:   %s\n", t.InitialMessage)
+	}
+	out.Print(b.String(), t)
+}
+
+// handleTemplateSave saves a session's setup as a template
+func handleTemplateSave(profile string, args []string) {
+	fs := flag.NewFlagSet("template save", flag.ExitOnError)
+	message := fs.String("message", "", "Initial message to send when the session starts")
+	messageShort := fs.String("m", "", "Initial message (short)")
+	titlePattern := fs.String("title", "", "Title pattern for new sessions, e.g. \"{project}-{branch}\"")
+	branchPattern := fs.String("branch", "", "Worktree branch pattern (enables worktree), e.g. \"feature/{title}\"")
+	noWorktree := fs.Bool("no-worktree", false, "Don't create worktrees even if the session uses one")
+	noPath := fs.Bool("no-path", false, "Don't store the session's project path")
+	description := fs.String("description", "", "Short description")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck template save <session> <name> [options]")
+		fmt.Println()
+		fmt.Println("Save a session's tool, command, wrapper, group, path, worktree mode, MCPs,")
+		fmt.Println("skills and tool options as a template. Saving over an existing name replaces it.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+	if fs.NArg() < 2 {
+		out.Error("session and template name are required", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+	inst, errMsg, errCode := ResolveSession(fs.Arg(0), instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	t := session.TemplateFromInstance(inst, fs.Arg(1))
+	t.InitialMessage = mergeFlags(*message, *messageShort)
+	t.Title = *titlePattern
+	t.Description = *description
+	if *branchPattern != "" {
+		t.Worktree = true
+		t.Branch = *branchPattern
+	}
+	if *noWorktree {
+		t.Worktree = false
+		t.Branch = ""
+	}
+	if *noPath {
+		t.Path = ""
+	}
+
+	if err := session.SaveTemplate(profile, t); err != nil {
+		out.Error(fmt.Sprintf("failed to save template: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	out.Success(fmt.Sprintf("Saved template '%s' from '%s' (%s)", t.Name, inst.Title, templateSummary(t)), map[string]interface{}{
+		"success":  true,
+		"template": t,
+	})
+}
+
+// handleTemplateDelete deletes a template
+func handleTemplateDelete(profile string, args []string) {
+	fs := flag.NewFlagSet("template delete", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+	if fs.NArg() < 1 {
+		out.Error("template name is required", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	if err := session.DeleteTemplate(profile, fs.Arg(0)); err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(2)
+	}
+	out.Success(fmt.Sprintf("Deleted template '%s'", fs.Arg(0)), map[string]interface{}{
+		"success": true,
+		"name":    fs.Arg(0),
+	})
+}
+
+// loadTemplateFlag loads the template named by --template, exiting with an
+// error when it doesn't exist. It returns nil when name is empty.
+func loadTemplateFlag(profile, name string) *session.SessionTemplate {
+	if name == "" {
+		return nil
+	}
+	t, err := session.GetTemplate(profile, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintln(os.Stderr, "Tip: list templates with 'agent-deck template list'")
+		os.Exit(1)
+	}
+	return t
+}
@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/asheshgoplani/agent-deck/internal/session"
@@ -21,9 +24,16 @@ func buildWebServer(profile string, args []string, menuData web.MenuDataLoader)
 	listenAddr := fs.String("listen", "127.0.0.1:8420", "Listen address for web server")
 	readOnly := fs.Bool("read-only", false, "Run in read-only mode (input disabled)")
 	token := fs.String("token", "", "Bearer token for API/WS access")
+	extraProfiles := fs.String("profiles", "", "Also serve these comma-separated profiles under /p/<name>/ (\"all\" for every profile)")
 	pushEnabled := fs.Bool("push", false, "Enable web push notifications (auto-generates VAPID keys per profile)")
 	pushVAPIDSubject := fs.String("push-vapid-subject", "mailto:agentdeck@localhost", "VAPID subject used for web push notifications")
 	pushTestEvery := fs.Duration("push-test-every", 0, "Send periodic push test notifications at this interval (e.g. 10s, 1m); 0 disables")
+	tlsCert := fs.String("tls-cert", "", "TLS certificate file (PEM); enables HTTPS together with --tls-key")
+	tlsKey := fs.String("tls-key", "", "TLS private key file (PEM)")
+	tlsSelfSigned := fs.Bool("tls-self-signed", false, "Serve HTTPS with an auto-generated self-signed certificate stored per profile")
+	tlsHosts := fs.String("tls-hosts", "", "Extra comma-separated hostnames/IPs for the self-signed certificate")
+	tlsClientCA := fs.String("tls-client-ca", "", "CA bundle (PEM) for verifying client certificates (mTLS)")
+	tlsRequireClientCert := fs.Bool("tls-require-client-cert", false, "Reject connections without a valid client certificate (requires --tls-client-ca)")
 
 	fs.Usage = func() {
 		fmt.Println("Usage: agent-deck web [options]")
@@ -38,9 +48,15 @@ func buildWebServer(profile string, args []string, menuData web.MenuDataLoader)
 		fmt.Println("  agent-deck web")
 		fmt.Println("  agent-deck web --headless")
 		fmt.Println("  agent-deck -p work web --listen 127.0.0.1:9000")
+		fmt.Println("  agent-deck web --profiles work,personal")
 		fmt.Println("  agent-deck web --read-only")
 		fmt.Println("  agent-deck web --push")
 		fmt.Println("  agent-deck web --push --push-test-every 10s")
+		fmt.Println("  agent-deck web --tls-self-signed --listen 0.0.0.0:8443")
+		fmt.Println("  agent-deck web --tls-cert cert.pem --tls-key key.pem --tls-client-ca ca.pem --tls-require-client-cert")
+		fmt.Println()
+		fmt.Println("With --headless, send SIGHUP to reload TLS certificates without restarting.")
+		fmt.Println("Scoped API tokens are managed with 'agent-deck web token'.")
 	}
 
 	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
@@ -58,9 +74,58 @@ func buildWebServer(profile string, args []string, menuData web.MenuDataLoader)
 	if *pushTestEvery > 0 && !*pushEnabled {
 		return nil, fmt.Errorf("--push-test-every requires --push")
 	}
+	if *tlsSelfSigned && (*tlsCert != "" || *tlsKey != "") {
+		return nil, fmt.Errorf("--tls-self-signed cannot be combined with --tls-cert/--tls-key")
+	}
+	if (*tlsCert == "") != (*tlsKey == "") {
+		return nil, fmt.Errorf("--tls-cert and --tls-key must be used together")
+	}
+	tlsEnabled := *tlsSelfSigned || *tlsCert != ""
+	if *tlsClientCA != "" && !tlsEnabled {
+		return nil, fmt.Errorf("--tls-client-ca requires --tls-cert/--tls-key or --tls-self-signed")
+	}
+	if *tlsRequireClientCert && *tlsClientCA == "" {
+		return nil, fmt.Errorf("--tls-require-client-cert requires --tls-client-ca")
+	}
 
 	effectiveProfile := session.GetEffectiveProfile(profile)
 
+	servedProfiles := splitCSV(*extraProfiles)
+	if len(servedProfiles) == 1 && servedProfiles[0] == "all" {
+		all, err := session.ListProfiles()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list profiles: %w", err)
+		}
+		servedProfiles = all
+	}
+
+	apiTokens, err := web.LoadAPITokens(effectiveProfile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load web tokens: %w", err)
+	}
+	// The audit path is always set: tokens created while the server runs
+	// enable auth without a restart, and the server skips auditing while
+	// auth is off.
+	profileDir, err := session.GetProfileDir(effectiveProfile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve profile dir: %w", err)
+	}
+	auditLogPath := filepath.Join(profileDir, web.AuditLogFileName)
+
+	certFile, keyFile := *tlsCert, *tlsKey
+	if *tlsSelfSigned {
+		var generated bool
+		certFile, keyFile, generated, err = web.EnsureSelfSignedCert(effectiveProfile, splitCSV(*tlsHosts))
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare self-signed certificate: %w", err)
+		}
+		if generated {
+			fmt.Printf("TLS: generated self-signed certificate %s\n", certFile)
+		} else {
+			fmt.Printf("TLS: using self-signed certificate %s\n", certFile)
+		}
+	}
+
 	resolvedPushSubject := *pushVAPIDSubject
 	resolvedPushPublic := ""
 	resolvedPushPrivate := ""
@@ -79,16 +144,27 @@ func buildWebServer(profile string, args []string, menuData web.MenuDataLoader)
 	}
 
 	server := web.NewServer(web.Config{
-		ListenAddr:          *listenAddr,
-		Profile:             effectiveProfile,
-		ReadOnly:            *readOnly,
-		Token:               *token,
-		MenuData:            menuData,
-		PushVAPIDPublicKey:  resolvedPushPublic,
-		PushVAPIDPrivateKey: resolvedPushPrivate,
-		PushVAPIDSubject:    resolvedPushSubject,
-		PushTestInterval:    *pushTestEvery,
+		ListenAddr:           *listenAddr,
+		Profile:              effectiveProfile,
+		Profiles:             servedProfiles,
+		ReadOnly:             *readOnly,
+		Token:                *token,
+		Tokens:               apiTokens,
+		TokenStoreProfile:    effectiveProfile,
+		AuditLogPath:         auditLogPath,
+		TLSCertFile:          certFile,
+		TLSKeyFile:           keyFile,
+		TLSClientCAFile:      *tlsClientCA,
+		TLSRequireClientCert: *tlsRequireClientCert,
+		MenuData:             menuData,
+		PushVAPIDPublicKey:   resolvedPushPublic,
+		PushVAPIDPrivateKey:  resolvedPushPrivate,
+		PushVAPIDSubject:     resolvedPushSubject,
+		PushTestInterval:     *pushTestEvery,
 	})
+	if err := server.ReloadTLS(); err != nil {
+		return nil, fmt.Errorf("failed to load TLS material: %w", err)
+	}
 
 	return server, nil
 }
@@ -114,7 +190,11 @@ func handleWebHeadless(profile string, args []string) {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	fmt.Printf("Agent Deck web server (headless): http://%s\n", server.Addr())
+	if server.TLSEnabled() {
+		go server.ReloadTLSOnSIGHUP(ctx)
+	}
+
+	fmt.Printf("Agent Deck web server (headless): %s\n", server.URL())
 	fmt.Println("Press Ctrl+C to stop.")
 
 	errCh := make(chan error, 1)
@@ -133,3 +213,351 @@ func handleWebHeadless(profile string, args []string) {
 		}
 	}
 }
+
+// handleWebToken dispatches "agent-deck web token" subcommands.
+func handleWebToken(profile string, args []string) {
+	if len(args) == 0 {
+		printWebTokenUsage()
+		return
+	}
+
+	switch args[0] {
+	case "create", "add":
+		handleWebTokenCreate(profile, args[1:])
+	case "list", "ls":
+		handleWebTokenList(profile, args[1:])
+	case "revoke", "rm":
+		handleWebTokenRevoke(profile, args[1:])
+	case "help", "-h", "--help":
+		printWebTokenUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown web token command: %s\n", args[0])
+		printWebTokenUsage()
+		os.Exit(1)
+	}
+}
+
+func printWebTokenUsage() {
+	fmt.Println("Usage: agent-deck web token <command> [options]")
+	fmt.Println()
+	fmt.Println("Manage named, scoped API tokens for the web server.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  create <name> --scopes <list>   Mint a token (secret is shown once)")
+	fmt.Println("  list                            List tokens")
+	fmt.Println("  revoke <name>                   Delete a token")
+	fmt.Println()
+	fmt.Println("Scopes:")
+	fmt.Println("  menu:read         View sessions, groups and hub state")
+	fmt.Println("  transcripts:read  Read conversation transcripts")
+	fmt.Println("  terminal:input    Type into terminals and upload files")
+	fmt.Println("  tasks:create      Create and drive hub tasks")
+	fmt.Println("  admin             Everything, including hub administration")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck web token create viewer --scopes menu:read,transcripts:read")
+	fmt.Println("  agent-deck web token create ci --scopes tasks:create --groups work")
+	fmt.Println("  agent-deck web token revoke viewer")
+}
+
+func handleWebTokenCreate(profile string, args []string) {
+	fs := flag.NewFlagSet("web token create", flag.ExitOnError)
+	scopes := fs.String("scopes", string(web.ScopeMenuRead), "Comma-separated scopes")
+	profiles := fs.String("profiles", "", "Comma-separated profiles the token is limited to (default: all)")
+	groups := fs.String("groups", "", "Comma-separated group paths the token is limited to (default: all)")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck web token create <name> [options]")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	parsedScopes, err := web.ParseScopes(*scopes)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	name := fs.Arg(0)
+	secret, err := web.CreateAPIToken(session.GetEffectiveProfile(profile), web.APIToken{
+		Name:     name,
+		Scopes:   parsedScopes,
+		Profiles: splitCSV(*profiles),
+		Groups:   splitCSV(*groups),
+	})
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	out.Success(fmt.Sprintf("Created token %q\n%s\n\nStore it now; it will not be shown again.", name, secret), map[string]interface{}{
+		"success": true,
+		"name":    name,
+		"token":   secret,
+		"scopes":  parsedScopes,
+	})
+}
+
+func handleWebTokenList(profile string, args []string) {
+	fs := flag.NewFlagSet("web token list", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+	tokens, err := web.LoadAPITokens(session.GetEffectiveProfile(profile))
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		type tokenInfo struct {
+			Name      string      `json:"name"`
+			Scopes    []web.Scope `json:"scopes"`
+			Profiles  []string    `json:"profiles,omitempty"`
+			Groups    []string    `json:"groups,omitempty"`
+			CreatedAt time.Time   `json:"created_at"`
+		}
+		infos := make([]tokenInfo, 0, len(tokens))
+		for _, tok := range tokens {
+			infos = append(infos, tokenInfo{
+				Name:      tok.Name,
+				Scopes:    tok.Scopes,
+				Profiles:  tok.Profiles,
+				Groups:    tok.Groups,
+				CreatedAt: tok.CreatedAt,
+			})
+		}
+		out.Print("", infos)
+		return
+	}
+
+	if len(tokens) == 0 {
+		fmt.Println("No web tokens. Create one with: agent-deck web token create <name> --scopes menu:read")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSCOPES\tPROFILES\tGROUPS\tCREATED")
+	for _, tok := range tokens {
+		scopeNames := make([]string, 0, len(tok.Scopes))
+		for _, sc := range tok.Scopes {
+			scopeNames = append(scopeNames, string(sc))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			tok.Name,
+			strings.Join(scopeNames, ","),
+			orAll(tok.Profiles),
+			orAll(tok.Groups),
+			tok.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	_ = w.Flush()
+}
+
+func handleWebTokenRevoke(profile string, args []string) {
+	fs := flag.NewFlagSet("web token revoke", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+	if fs.NArg() != 1 {
+		out.Error("usage: agent-deck web token revoke <name>", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	name := fs.Arg(0)
+	if err := web.RevokeAPIToken(session.GetEffectiveProfile(profile), name); err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(1)
+	}
+	out.Success(fmt.Sprintf("Revoked token %q", name), map[string]interface{}{
+		"success": true,
+		"name":    name,
+	})
+}
+
+func splitCSV(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func orAll(values []string) string {
+	if len(values) == 0 {
+		return "*"
+	}
+	return strings.Join(values, ",")
+}
+
+// handleWebShare dispatches "agent-deck web share" commands. A bare session
+// argument creates a share; list and revoke manage existing ones.
+func handleWebShare(profile string, args []string) {
+	if len(args) == 0 {
+		printWebShareUsage()
+		return
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		handleWebShareList(profile, args[1:])
+	case "revoke", "rm":
+		handleWebShareRevoke(profile, args[1:])
+	case "help", "-h", "--help":
+		printWebShareUsage()
+	default:
+		handleWebShareCreate(profile, args)
+	}
+}
+
+func printWebShareUsage() {
+	fmt.Println("Usage: agent-deck web share <session> [options]")
+	fmt.Println("       agent-deck web share list")
+	fmt.Println("       agent-deck web share revoke <share-id|session-id>")
+	fmt.Println()
+	fmt.Println("Mint a signed, expiring, read-only link to a session's terminal or transcript.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --ttl <duration>     Link lifetime (default 2h)")
+	fmt.Println("  --transcript-only    Share the conversation transcript, not the terminal")
+	fmt.Println("  --base-url <url>     Public base URL of the web server (default http://127.0.0.1:8420)")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck web share \"My Session\" --ttl 2h")
+	fmt.Println("  agent-deck web share \"My Session\" --transcript-only --base-url https://deck.vpn:8443")
+	fmt.Println("  agent-deck web share list")
+	fmt.Println("  agent-deck web share revoke 3fa2c1")
+}
+
+func handleWebShareCreate(profile string, args []string) {
+	fs := flag.NewFlagSet("web share", flag.ExitOnError)
+	ttl := fs.Duration("ttl", 2*time.Hour, "Link lifetime")
+	transcriptOnly := fs.Bool("transcript-only", false, "Share the transcript only")
+	baseURL := fs.String("base-url", "http://127.0.0.1:8420", "Public base URL of the web server")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	fs.Usage = printWebShareUsage
+
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+	if fs.NArg() != 1 {
+		printWebShareUsage()
+		os.Exit(1)
+	}
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	inst, errMsg, errCode := ResolveSession(fs.Arg(0), instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		os.Exit(1)
+	}
+
+	link, token, err := web.CreateShareLink(session.GetEffectiveProfile(profile), inst.ID, inst.Title, *ttl, *transcriptOnly)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	shareURL := strings.TrimRight(*baseURL, "/") + "/share?share=" + token
+	kind := "terminal"
+	if link.TranscriptOnly {
+		kind = "transcript"
+	}
+	out.Success(fmt.Sprintf("Read-only %s link for %q (expires %s):\n%s\n\nRevoke with: agent-deck web share revoke %s",
+		kind, inst.Title, link.ExpiresAt.Local().Format("2006-01-02 15:04"), shareURL, link.ID), map[string]interface{}{
+		"success":         true,
+		"id":              link.ID,
+		"session_id":      link.SessionID,
+		"url":             shareURL,
+		"transcript_only": link.TranscriptOnly,
+		"expires_at":      link.ExpiresAt,
+	})
+}
+
+func handleWebShareList(profile string, args []string) {
+	fs := flag.NewFlagSet("web share list", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+	links, err := web.ListShareLinks(session.GetEffectiveProfile(profile))
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		out.Print("", links)
+		return
+	}
+	if len(links) == 0 {
+		fmt.Println("No active share links.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSESSION\tMODE\tEXPIRES")
+	for _, link := range links {
+		mode := "terminal"
+		if link.TranscriptOnly {
+			mode = "transcript"
+		}
+		title := link.SessionTitle
+		if title == "" {
+			title = TruncateID(link.SessionID)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", link.ID, title, mode, link.ExpiresAt.Local().Format("2006-01-02 15:04"))
+	}
+	_ = w.Flush()
+}
+
+func handleWebShareRevoke(profile string, args []string) {
+	fs := flag.NewFlagSet("web share revoke", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+	if fs.NArg() != 1 {
+		out.Error("usage: agent-deck web share revoke <share-id|session-id>", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	removed, err := web.RevokeShareLink(session.GetEffectiveProfile(profile), fs.Arg(0))
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(1)
+	}
+	out.Success(fmt.Sprintf("Revoked %d share link(s)", removed), map[string]interface{}{
+		"success": true,
+		"removed": removed,
+	})
+}
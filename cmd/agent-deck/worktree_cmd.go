@@ -2,14 +2,20 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/asheshgoplani/agent-deck/internal/forge"
 	"github.com/asheshgoplani/agent-deck/internal/git"
 	"github.com/asheshgoplani/agent-deck/internal/session"
+	"golang.org/x/term"
 )
 
 // handleWorktree dispatches worktree subcommands
@@ -28,6 +34,12 @@ func handleWorktree(profile string, args []string) {
 		handleWorktreeCleanup(profile, args[1:])
 	case "finish":
 		handleWorktreeFinish(profile, args[1:])
+	case "pr":
+		handleWorktreePR(profile, args[1:])
+	case "conflicts":
+		handleWorktreeConflicts(profile, args[1:])
+	case "trust":
+		handleWorktreeTrust(args[1:])
 	case "help", "-h", "--help":
 		printWorktreeUsage()
 	default:
@@ -47,6 +59,9 @@ func printWorktreeUsage() {
 	fmt.Println("  list              List all worktrees in current repository")
 	fmt.Println("  info <session>    Show worktree info for a session")
 	fmt.Println("  finish <session>  Merge branch, remove worktree, and delete session")
+	fmt.Println("  pr <session>      Push branch and open or update its pull request")
+	fmt.Println("  conflicts         Report sibling worktree sessions editing the same files")
+	fmt.Println("  trust [repo]      Review and approve the repo's provisioning commands")
 	fmt.Println("  cleanup [--force] Find and remove orphaned worktrees/sessions")
 	fmt.Println()
 	fmt.Println("Global Options:")
@@ -60,6 +75,9 @@ func printWorktreeUsage() {
 	fmt.Println("  agent-deck worktree finish \"My Session\"")
 	fmt.Println("  agent-deck worktree finish \"My Session\" --no-merge")
 	fmt.Println("  agent-deck worktree finish \"My Session\" --into develop")
+	fmt.Println("  agent-deck worktree pr \"My Session\"")
+	fmt.Println("  agent-deck worktree pr \"My Session\" --status")
+	fmt.Println("  agent-deck worktree conflicts --notify")
 	fmt.Println("  agent-deck worktree cleanup")
 	fmt.Println("  agent-deck worktree cleanup --force")
 }
@@ -250,6 +268,11 @@ func handleWorktreeInfo(profile string, args []string) {
 		worktreeExists = true
 	}
 
+	var provision *session.ProvisionResult
+	if worktreeExists {
+		provision, _ = session.LoadProvisionResult(inst.WorktreePath)
+	}
+
 	if *jsonOutput {
 		out.Print("", map[string]interface{}{
 			"session":         inst.Title,
@@ -258,6 +281,7 @@ func handleWorktreeInfo(profile string, args []string) {
 			"worktree_path":   inst.WorktreePath,
 			"main_repo":       inst.WorktreeRepoRoot,
 			"worktree_exists": worktreeExists,
+			"provision":       provision,
 		})
 		return
 	}
@@ -273,6 +297,88 @@ func handleWorktreeInfo(profile string, args []string) {
 	} else {
 		fmt.Printf("Status:         MISSING (worktree directory not found)\n")
 	}
+	if provision != nil {
+		printProvisionResult(provision)
+	}
+}
+
+// printProvisionResult prints the provisioning section of `worktree info`.
+func printProvisionResult(r *session.ProvisionResult) {
+	state := "ok"
+	if !r.OK() {
+		state = "FAILED"
+	}
+	fmt.Printf("Provisioned:    %s (%s, %s)\n", state, r.Summary(), r.FinishedAt.Format("2006-01-02 15:04"))
+	fmt.Printf("Recipe:         %s\n", FormatPath(r.Recipe))
+	if len(r.Copied) > 0 {
+		fmt.Printf("Copied:         %s\n", strings.Join(r.Copied, ", "))
+	}
+	if len(r.Linked) > 0 {
+		fmt.Printf("Linked:         %s\n", strings.Join(r.Linked, ", "))
+	}
+	if len(r.Ports) > 0 {
+		names := make([]string, 0, len(r.Ports))
+		for name := range r.Ports {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		ports := make([]string, len(names))
+		for i, name := range names {
+			ports[i] = fmt.Sprintf("%s=%d", name, r.Ports[name])
+		}
+		fmt.Printf("Ports:          %s\n", strings.Join(ports, " "))
+	}
+	if r.EnvFile != "" {
+		fmt.Printf("Env File:       %s\n", r.EnvFile)
+	}
+	for _, c := range r.Commands {
+		status := fmt.Sprintf("exit %d", c.ExitCode)
+		if c.TimedOut {
+			status = "timed out"
+		}
+		symbol := successSymbol
+		if c.ExitCode != 0 || c.TimedOut {
+			symbol = errorSymbol
+		}
+		fmt.Printf("  %s %s (%s, %s)\n", symbol, c.Command, status, c.Duration.Round(time.Millisecond))
+		if symbol == errorSymbol && c.Output != "" {
+			lines := strings.Split(strings.TrimRight(c.Output, "\n"), "\n")
+			for _, line := range lines[max(len(lines)-10, 0):] {
+				fmt.Printf("      %s\n", line)
+			}
+		}
+	}
+	for _, c := range r.SkippedCommands {
+		fmt.Printf("  %s %s (skipped: recipe not trusted)\n", errorSymbol, c)
+	}
+	for _, name := range r.SkippedEnv {
+		fmt.Printf("  %s $%s (not expanded: recipe not trusted)\n", errorSymbol, name)
+	}
+	if r.Untrusted() {
+		fmt.Println("  Review and approve with: agent-deck worktree trust <repo>")
+	}
+	for _, e := range r.Errors {
+		fmt.Printf("  %s %s\n", errorSymbol, e)
+	}
+}
+
+// provisionNewWorktree applies the repo's provisioning recipe to a worktree
+// that was just created and reports the outcome on stderr, so it never mixes
+// with --json output. Failures are reported but never fatal.
+func provisionNewWorktree(repoRoot, worktreePath string) {
+	result, err := session.ProvisionWorktree(repoRoot, worktreePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: worktree provisioning failed: %v\n", err)
+	}
+	if result == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Provisioned worktree: %s\n", result.Summary())
+	if result.Untrusted() {
+		fmt.Fprintf(os.Stderr, "  Recipe is not trusted: commands were not run and host variables were not expanded. Review and approve it with: agent-deck worktree trust %s\n", repoRoot)
+	} else if !result.OK() {
+		fmt.Fprintf(os.Stderr, "  See: agent-deck worktree info %s\n", worktreePath)
+	}
 }
 
 // handleWorktreeCleanup finds and removes orphaned worktrees and sessions
@@ -486,6 +592,8 @@ func handleWorktreeFinish(profile string, args []string) {
 	fs := flag.NewFlagSet("worktree finish", flag.ExitOnError)
 	into := fs.String("into", "", "Target branch to merge into (default: auto-detect)")
 	noMerge := fs.Bool("no-merge", false, "Skip merge (e.g. for PR workflows)")
+	strategyName := fs.String("strategy", "merge", "How to integrate the branch: merge, rebase, squash or ff-only")
+	handoff := fs.Bool("handoff", false, "On conflict, send the conflicted files to the session to resolve")
 	keepBranch := fs.Bool("keep-branch", false, "Don't delete local branch after finish")
 	force := fs.Bool("force", false, "Skip safety checks and force branch deletion")
 	jsonOutput := fs.Bool("json", false, "Output as JSON")
@@ -504,8 +612,13 @@ func handleWorktreeFinish(profile string, args []string) {
 		fmt.Println("Examples:")
 		fmt.Println("  agent-deck worktree finish \"My Feature\"")
 		fmt.Println("  agent-deck worktree finish \"My Feature\" --into develop")
+		fmt.Println("  agent-deck worktree finish \"My Feature\" --strategy squash")
+		fmt.Println("  agent-deck worktree finish \"My Feature\" --strategy rebase --handoff")
 		fmt.Println("  agent-deck worktree finish \"My Feature\" --no-merge")
 		fmt.Println("  agent-deck worktree finish \"My Feature\" --no-merge --force")
+		fmt.Println()
+		fmt.Println("A conflict pre-flight (git merge-tree) runs before anything is changed;")
+		fmt.Println("on conflict the main worktree is left untouched.")
 	}
 
 	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
@@ -522,6 +635,12 @@ func handleWorktreeFinish(profile string, args []string) {
 		os.Exit(1)
 	}
 
+	strategy, err := git.ParseMergeStrategy(*strategyName)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
 	// Load sessions
 	storage, instances, _, err := loadSessionData(profile)
 	if err != nil {
@@ -582,6 +701,19 @@ func handleWorktreeFinish(profile string, args []string) {
 		os.Exit(1)
 	}
 
+	// Pre-flight: verify the merge is clean before touching the main worktree
+	if !*noMerge {
+		if err := git.PreflightFinish(repoRoot, targetBranch, worktreeBranch, strategy); err != nil {
+			var conflict *git.MergeConflictError
+			if errors.As(err, &conflict) {
+				reportFinishConflict(out, inst, conflict, *handoff, *force, *jsonOutput)
+				os.Exit(1)
+			}
+			out.Error(fmt.Sprintf("pre-flight check failed: %v", err), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+	}
+
 	// Show summary and confirm
 	if !*force && !*jsonOutput {
 		fmt.Printf("Session:   %s\n", inst.Title)
@@ -590,7 +722,7 @@ func handleWorktreeFinish(profile string, args []string) {
 		if *noMerge {
 			fmt.Printf("Merge:     skipped (--no-merge)\n")
 		} else {
-			fmt.Printf("Merge:     %s → %s\n", worktreeBranch, targetBranch)
+			fmt.Printf("Merge:     %s → %s (%s)\n", worktreeBranch, targetBranch, strategy)
 		}
 		if *keepBranch {
 			fmt.Printf("Branch:    kept (--keep-branch)\n")
@@ -612,22 +744,14 @@ func handleWorktreeFinish(profile string, args []string) {
 
 	// Step 1: Merge (if requested)
 	if !*noMerge {
-		fmt.Printf("Merging %s into %s...\n", worktreeBranch, targetBranch)
-
-		// Checkout target branch in main repo
-		cmd := exec.Command("git", "-C", repoRoot, "checkout", targetBranch)
-		checkoutOutput, err := cmd.CombinedOutput()
-		if err != nil {
-			out.Error(fmt.Sprintf("failed to checkout %s: %s", targetBranch, strings.TrimSpace(string(checkoutOutput))), ErrCodeInvalidOperation)
-			os.Exit(1)
-		}
-
-		// Merge the worktree branch
-		if err := git.MergeBranch(repoRoot, worktreeBranch); err != nil {
-			// Abort the merge to leave things clean
-			abortCmd := exec.Command("git", "-C", repoRoot, "merge", "--abort")
-			_ = abortCmd.Run()
-			out.Error(fmt.Sprintf("merge failed (aborted): %v", err), ErrCodeInvalidOperation)
+		fmt.Printf("Merging %s into %s (%s)...\n", worktreeBranch, targetBranch, strategy)
+		if err := git.FinishBranch(repoRoot, worktreePath, targetBranch, worktreeBranch, strategy); err != nil {
+			var conflict *git.MergeConflictError
+			if errors.As(err, &conflict) {
+				reportFinishConflict(out, inst, conflict, *handoff, *force, *jsonOutput)
+				os.Exit(1)
+			}
+			out.Error(err.Error(), ErrCodeInvalidOperation)
 			os.Exit(1)
 		}
 		fmt.Printf("  %s Merged successfully\n", successSymbol)
@@ -681,6 +805,7 @@ func handleWorktreeFinish(profile string, args []string) {
 			"branch":         worktreeBranch,
 			"merged_into":    targetBranch,
 			"merged":         !*noMerge,
+			"strategy":       string(strategy),
 			"branch_deleted": !*keepBranch,
 		})
 	} else {
@@ -692,6 +817,58 @@ func handleWorktreeFinish(profile string, args []string) {
 	}
 }
 
+// reportFinishConflict prints the conflicted files and, when requested (or
+// confirmed interactively), hands them back to the session's agent. It only
+// asks when stdin is a terminal and --force wasn't given; scripted runs hand
+// off only with --handoff.
+func reportFinishConflict(out *CLIOutput, inst *session.Instance, conflict *git.MergeConflictError, handoff, force, jsonOutput bool) {
+	if !jsonOutput {
+		fmt.Fprintf(os.Stderr, "Error: %s → %s would conflict; nothing was changed.\n", conflict.Source, conflict.Target)
+		fmt.Fprintln(os.Stderr, "Conflicted files:")
+		for _, f := range conflict.Files {
+			fmt.Fprintf(os.Stderr, "  %s %s\n", bulletSymbol, f)
+		}
+		if !handoff && !force && inst.Exists() && term.IsTerminal(int(os.Stdin.Fd())) {
+			fmt.Printf("\nSend the conflict to session '%s' to resolve? [y/N]: ", inst.Title)
+			response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			response = strings.TrimSpace(strings.ToLower(response))
+			handoff = response == "y" || response == "yes"
+		}
+	}
+
+	handedOff := false
+	var handoffErr error
+	if handoff {
+		if tmuxSess := inst.GetTmuxSession(); tmuxSess != nil && inst.Exists() {
+			handoffErr = sendWithRetry(tmuxSess, git.ConflictHandoffPrompt(conflict), false)
+			handedOff = handoffErr == nil
+		} else {
+			handoffErr = fmt.Errorf("session '%s' is not running", inst.Title)
+		}
+	}
+
+	if jsonOutput {
+		result := map[string]interface{}{
+			"success":    false,
+			"code":       "MERGE_CONFLICT",
+			"error":      conflict.Error(),
+			"files":      conflict.Files,
+			"handed_off": handedOff,
+		}
+		if handoffErr != nil {
+			result["handoff_error"] = handoffErr.Error()
+		}
+		out.Print("", result)
+		return
+	}
+	switch {
+	case handedOff:
+		fmt.Printf("%s Sent conflict details to '%s'\n", successSymbol, inst.Title)
+	case handoffErr != nil:
+		fmt.Fprintf(os.Stderr, "Warning: handoff failed: %v\n", handoffErr)
+	}
+}
+
 // truncateString truncates a string to maxLen, adding "..." if truncated
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -702,3 +879,355 @@ func truncateString(s string, maxLen int) string {
 	}
 	return s[:maxLen-3] + "..."
 }
+
+// handleWorktreePR pushes a worktree session's branch and opens (or updates)
+// its pull request on the forge hosting the remote
+func handleWorktreePR(profile string, args []string) {
+	fs := flag.NewFlagSet("worktree pr", flag.ExitOnError)
+	base := fs.String("base", "", "Target branch (default: repo default branch)")
+	remoteName := fs.String("remote", "origin", "Git remote to push to")
+	title := fs.String("title", "", "PR title (default: first line of the session's last response)")
+	draft := fs.Bool("draft", false, "Open the PR as a draft")
+	statusOnly := fs.Bool("status", false, "Only refresh PR state and CI checks (no push)")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck worktree pr <session> [options]")
+		fmt.Println()
+		fmt.Println("Push a worktree session's branch and open or update its pull request.")
+		fmt.Println("The title and body are generated from the session's last response.")
+		fmt.Println("Supports GitHub, GitLab and Gitea; tokens come from the [forge] section")
+		fmt.Println("of config.toml or $GITHUB_TOKEN / $GITLAB_TOKEN / $GITEA_TOKEN.")
+		fmt.Println()
+		fmt.Println("Arguments:")
+		fmt.Println("  session    Session title, ID prefix, or path")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+
+	identifier := fs.Arg(0)
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	if identifier == "" {
+		out.Error("session identifier is required", ErrCodeNotFound)
+		fmt.Println()
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	inst, errMsg, errCode := ResolveSession(identifier, instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+	if !inst.IsWorktree() {
+		out.Error(fmt.Sprintf("session '%s' is not in a worktree", inst.Title), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	effectiveProfile := session.GetEffectiveProfile(profile)
+
+	// --status refreshes the PR through the remote it was opened with
+	// unless --remote says otherwise
+	remoteSet := false
+	fs.Visit(func(fl *flag.Flag) { remoteSet = remoteSet || fl.Name == "remote" })
+	if *statusOnly && !remoteSet {
+		if records, err := forge.LoadRecords(effectiveProfile); err == nil {
+			if existing, ok := records[inst.ID]; ok {
+				*remoteName = existing.GitRemote()
+			}
+		}
+	}
+
+	f, remote, err := forge.ForRepo(inst.WorktreeRepoRoot, *remoteName)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	rec := forge.Record{SessionID: inst.ID, Forge: f.Kind(), Remote: remote.Host + "/" + remote.Path(), RemoteName: *remoteName}
+	created := false
+	if *statusOnly {
+		records, err := forge.LoadRecords(effectiveProfile)
+		if err != nil {
+			out.Error(err.Error(), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		existing, ok := records[inst.ID]
+		if ok && existing.RemoteName == "" {
+			existing.RemoteName = *remoteName
+		}
+		if !ok {
+			pr, err := f.FindPullRequest(ctx, inst.WorktreeBranch)
+			if err != nil || pr == nil {
+				out.Error(fmt.Sprintf("no pull request found for branch %s", inst.WorktreeBranch), ErrCodeNotFound)
+				os.Exit(1)
+				return // unreachable, satisfies staticcheck SA5011
+			}
+			existing = rec
+			existing.PR = *pr
+		}
+		rec = existing
+	} else {
+		targetBranch := *base
+		if targetBranch == "" {
+			targetBranch, err = git.GetDefaultBranch(inst.WorktreeRepoRoot)
+			if err != nil {
+				out.Error(fmt.Sprintf("cannot determine target branch: %v (use --base)", err), ErrCodeInvalidOperation)
+				os.Exit(1)
+			}
+		}
+
+		if !*jsonOutput && !*quiet && !*quietShort {
+			fmt.Printf("Pushing %s to %s...\n", inst.WorktreeBranch, *remoteName)
+		}
+		if err := git.PushBranch(inst.WorktreePath, *remoteName, inst.WorktreeBranch); err != nil {
+			out.Error(err.Error(), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+
+		response := ""
+		if resp, err := inst.GetLastResponse(); err == nil && resp != nil {
+			response = resp.Content
+		}
+		prTitle, prBody := forge.DraftFromResponse(response, inst.Title)
+		if *title != "" {
+			prTitle = *title
+		}
+
+		pr, isNew, err := forge.Publish(ctx, f, forge.NewPullRequest{
+			Head:  inst.WorktreeBranch,
+			Base:  targetBranch,
+			Title: prTitle,
+			Body:  prBody,
+			Draft: *draft,
+		})
+		if err != nil {
+			out.Error(err.Error(), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		created = isNew
+		rec.PR = *pr
+	}
+
+	if refreshed, err := forge.Refresh(ctx, f, rec); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to refresh PR status: %v\n", err)
+		rec.UpdatedAt = time.Now()
+	} else {
+		rec = refreshed
+	}
+	if err := forge.SaveRecord(effectiveProfile, rec); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save PR record: %v\n", err)
+	}
+
+	action := "Updated"
+	if created {
+		action = "Opened"
+	} else if *statusOnly {
+		action = "Refreshed"
+	}
+	var human strings.Builder
+	fmt.Fprintf(&human, "%s %s PR #%d: %s\n", successSymbol, action, rec.PR.Number, rec.PR.URL)
+	fmt.Fprintf(&human, "  State:  %s\n", rec.PR.State)
+	if len(rec.Checks) == 0 {
+		human.WriteString("  Checks: none reported\n")
+	} else {
+		fmt.Fprintf(&human, "  Checks: %s\n", rec.CheckState)
+		for _, c := range rec.Checks {
+			fmt.Fprintf(&human, "    %s %s (%s)\n", bulletSymbol, c.Name, c.State)
+		}
+	}
+	out.Print(human.String(), map[string]interface{}{
+		"success":     true,
+		"session":     inst.Title,
+		"session_id":  inst.ID,
+		"forge":       rec.Forge,
+		"created":     created,
+		"pr":          rec.PR,
+		"checks":      rec.Checks,
+		"check_state": rec.CheckState,
+	})
+}
+
+// handleWorktreeTrust shows the commands in a repo's provisioning recipe and
+// approves them to run on new worktrees
+func handleWorktreeTrust(args []string) {
+	fs := flag.NewFlagSet("worktree trust", flag.ExitOnError)
+	yes := fs.Bool("yes", false, "Approve without prompting")
+	revoke := fs.Bool("revoke", false, "Withdraw approval")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck worktree trust [repo] [options]")
+		fmt.Println()
+		fmt.Printf("Commands in a repo's %s run with your permissions on\n", session.WorktreeRecipeFile)
+		fmt.Println("every new worktree, so they only run once you approve them. Approval covers")
+		fmt.Println("the recipe as it is now; any change to the file needs approving again.")
+		fmt.Println("Recipes in config.toml [worktree.recipes] don't need approval.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	dir, err := filepath.Abs(session.ExpandPath(dir))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	mainWorktree, err := git.GetWorktreeBaseRoot(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s is not in a git repository\n", dir)
+		os.Exit(1)
+	}
+
+	if *revoke {
+		if err := session.UntrustWorktreeRecipe(mainWorktree); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s Recipe commands for %s will no longer run\n", successSymbol, FormatPath(mainWorktree))
+		return
+	}
+
+	recipe, source, err := session.LoadWorktreeRecipe(mainWorktree)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if recipe == nil || source != filepath.Join(mainWorktree, session.WorktreeRecipeFile) {
+		fmt.Fprintf(os.Stderr, "Error: %s has no %s\n", FormatPath(mainWorktree), session.WorktreeRecipeFile)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Recipe: %s\n", FormatPath(source))
+	if len(recipe.Commands) == 0 {
+		fmt.Println("  (no commands)")
+	}
+	for _, c := range recipe.Commands {
+		fmt.Printf("  $ %s\n", c)
+	}
+	if trusted, _ := session.IsWorktreeRecipeTrusted(mainWorktree); trusted {
+		fmt.Println("Already approved.")
+		return
+	}
+
+	if !*yes {
+		fmt.Print("\nRun these commands on every new worktree of this repo? [y/N]: ")
+		response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Aborted.")
+			return
+		}
+	}
+	if err := session.TrustWorktreeRecipe(mainWorktree); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s Approved recipe commands for %s\n", successSymbol, FormatPath(mainWorktree))
+}
+
+// handleWorktreeConflicts reports pairs of worktree sessions in the same repo
+// whose changes touch the same files
+func handleWorktreeConflicts(profile string, args []string) {
+	fs := flag.NewFlagSet("worktree conflicts", flag.ExitOnError)
+	notify := fs.Bool("notify", false, "Message both sessions' parents (or the conductor) about each overlap")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck worktree conflicts [options]")
+		fmt.Println()
+		fmt.Println("Compare the changes of every worktree session against the merge-base with")
+		fmt.Println("its repo's default branch and report sessions touching the same files.")
+		fmt.Println("Overlaps on the same lines are marked as hunk conflicts.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(normalizeArgs(fs, args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	conflicts := session.FindWorktreeConflicts(instances)
+
+	notified := make(map[string][]string)
+	if *notify {
+		effectiveProfile := session.GetEffectiveProfile(profile)
+		for _, c := range conflicts {
+			targets, err := session.NotifyWorktreeConflict(effectiveProfile, c, instances)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			notified[c.Key()] = targets
+		}
+	}
+
+	if *jsonOutput {
+		type conflictJSON struct {
+			session.WorktreeConflict
+			Notified []string `json:"notified,omitempty"`
+		}
+		results := make([]conflictJSON, len(conflicts))
+		for i, c := range conflicts {
+			results[i] = conflictJSON{WorktreeConflict: c, Notified: notified[c.Key()]}
+		}
+		out.Print("", map[string]interface{}{
+			"conflicts": results,
+			"count":     len(results),
+		})
+		return
+	}
+
+	if len(conflicts) == 0 {
+		fmt.Println("No overlapping worktree changes found.")
+		return
+	}
+
+	for _, c := range conflicts {
+		fmt.Printf("%s (%s) <-> %s (%s)  [%s]\n", c.A.Title, c.A.Branch, c.B.Title, c.B.Branch, FormatPath(c.RepoRoot))
+		for _, f := range c.Files {
+			marker := "same file"
+			if f.Hunks {
+				marker = "same lines"
+			}
+			fmt.Printf("  %s %s (%s)\n", bulletSymbol, f.Path, marker)
+		}
+		if targets := notified[c.Key()]; len(targets) > 0 {
+			fmt.Printf("  Notified: %s\n", strings.Join(targets, ", "))
+		}
+	}
+	fmt.Printf("\nTotal: %d overlapping pair(s)\n", len(conflicts))
+}
@@ -56,11 +56,16 @@ func ReadSessionFull(sessionDir string) (*SessionReadResult, error) {
 	if selected == "" {
 		return nil, nil
 	}
+	return ReadSessionFile(selected)
+}
 
+// ReadSessionFile is like ReadSessionFull but reads a specific JSONL file
+// instead of the most recent one in a directory.
+func ReadSessionFile(path string) (*SessionReadResult, error) {
 	// Parse each line as Entry.
-	entries, err := parseJSONL(selected)
+	entries, err := parseJSONL(path)
 	if err != nil {
-		return nil, fmt.Errorf("parse %s: %w", filepath.Base(selected), err)
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
 	}
 
 	if len(entries) == 0 {
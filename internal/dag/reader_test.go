@@ -63,3 +63,21 @@ func TestReadSession_SkipsAgentFiles(t *testing.T) {
 	assert.Equal(t, "m1", msgs[0].UUID)
 	assert.Equal(t, "main msg", msgs[0].Content)
 }
+
+func TestReadSessionFile_IgnoresNewerFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	older := filepath.Join(dir, "older.jsonl")
+	err := os.WriteFile(older, []byte(`{"uuid":"o1","parentUuid":"","type":"human","message":{"role":"user","content":"older msg"},"timestamp":"2025-01-01T00:00:00Z"}
+`), 0644)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "newer.jsonl"), []byte(`{"uuid":"n1","parentUuid":"","type":"human","message":{"role":"user","content":"newer msg"},"timestamp":"2025-01-02T00:00:00Z"}
+`), 0644)
+	require.NoError(t, err)
+
+	result, err := ReadSessionFile(older)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Len(t, result.Messages, 1)
+	assert.Equal(t, "older msg", result.Messages[0].Content)
+}
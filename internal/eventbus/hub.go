@@ -59,6 +59,7 @@ type subscription struct {
 type client struct {
 	conn          WSConn
 	subscriptions map[string]subscription // subscriptionID -> subscription
+	allow         func(Event) bool        // optional per-client event filter
 }
 
 // Hub manages WebSocket clients and routes EventBus events to them
@@ -88,6 +89,12 @@ func NewHub(bus *EventBus) *Hub {
 // RegisterClient adds a WebSocket connection to the hub and returns
 // a unique client ID used for subsequent operations.
 func (h *Hub) RegisterClient(conn WSConn) string {
+	return h.RegisterFilteredClient(conn, nil)
+}
+
+// RegisterFilteredClient is like RegisterClient but only forwards events for
+// which allow returns true. A nil allow forwards every subscribed event.
+func (h *Hub) RegisterFilteredClient(conn WSConn, allow func(Event) bool) string {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -96,6 +103,7 @@ func (h *Hub) RegisterClient(conn WSConn) string {
 	h.clients[id] = &client{
 		conn:          conn,
 		subscriptions: make(map[string]subscription),
+		allow:         allow,
 	}
 	return id
 }
@@ -196,6 +204,9 @@ func (h *Hub) broadcast(event Event) {
 // clientWantsEvent returns true if the client has any subscription matching
 // the given channel and event.
 func (h *Hub) clientWantsEvent(c *client, ch string, event Event) bool {
+	if c.allow != nil && !c.allow(event) {
+		return false
+	}
 	for _, sub := range c.subscriptions {
 		// Per-session subscription: channel must be "session" and sessionID must match
 		if sub.channel == "session" {
@@ -355,3 +355,26 @@ func TestHub_Close(t *testing.T) {
 	hub.Close()
 	assert.Equal(t, 0, hub.ClientCount())
 }
+
+func TestHub_FilteredClient(t *testing.T) {
+	bus := New()
+	hub := NewHub(bus)
+	defer hub.Close()
+
+	conn := &mockConn{}
+	clientID := hub.RegisterFilteredClient(conn, func(e Event) bool {
+		return e.Channel != "hidden-session"
+	})
+
+	raw := json.RawMessage(`{"type":"subscribe","channel":"session","sessionId":"hidden-session"}`)
+	require.NoError(t, hub.HandleMessage(clientID, raw))
+	raw = json.RawMessage(`{"type":"subscribe","channel":"session","sessionId":"visible-session"}`)
+	require.NoError(t, hub.HandleMessage(clientID, raw))
+
+	countBefore := conn.messageCount()
+	bus.Emit(Event{Type: EventSessionStatusChanged, Channel: "hidden-session"})
+	assert.Equal(t, countBefore, conn.messageCount(), "filtered event should not be delivered")
+
+	bus.Emit(Event{Type: EventSessionStatusChanged, Channel: "visible-session"})
+	assert.Equal(t, countBefore+1, conn.messageCount(), "allowed event should be delivered")
+}
@@ -0,0 +1,228 @@
+// Package forge talks to code-hosting forges (GitHub, GitLab, Gitea) over
+// their REST APIs to open and track pull requests for worktree sessions.
+package forge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// Kind identifies a forge implementation.
+type Kind string
+
+const (
+	KindGitHub Kind = "github"
+	KindGitLab Kind = "gitlab"
+	KindGitea  Kind = "gitea"
+)
+
+// PRState is a pull request's state, normalized across forges.
+type PRState string
+
+const (
+	PRStateOpen   PRState = "open"
+	PRStateDraft  PRState = "draft"
+	PRStateMerged PRState = "merged"
+	PRStateClosed PRState = "closed"
+)
+
+// CheckState is a CI check result, normalized across forges.
+type CheckState string
+
+const (
+	CheckNone    CheckState = ""
+	CheckPending CheckState = "pending"
+	CheckSuccess CheckState = "success"
+	CheckFailure CheckState = "failure"
+)
+
+// PullRequest is a pull request (GitLab: merge request).
+type PullRequest struct {
+	Number  int     `json:"number"`
+	URL     string  `json:"url"`
+	Title   string  `json:"title"`
+	State   PRState `json:"state"`
+	Head    string  `json:"head"`
+	Base    string  `json:"base"`
+	HeadSHA string  `json:"head_sha,omitempty"`
+}
+
+// Check is one CI check or commit status on a pull request's head commit.
+type Check struct {
+	Name  string     `json:"name"`
+	State CheckState `json:"state"`
+}
+
+// NewPullRequest describes a pull request to open.
+type NewPullRequest struct {
+	Head  string
+	Base  string
+	Title string
+	Body  string
+	Draft bool
+}
+
+// Forge is the subset of a forge API needed to manage worktree PRs.
+type Forge interface {
+	Kind() Kind
+	// FindPullRequest returns the open pull request for head, or nil.
+	FindPullRequest(ctx context.Context, head string) (*PullRequest, error)
+	GetPullRequest(ctx context.Context, number int) (*PullRequest, error)
+	CreatePullRequest(ctx context.Context, pr NewPullRequest) (*PullRequest, error)
+	UpdatePullRequest(ctx context.Context, number int, title, body string) (*PullRequest, error)
+	// Checks returns the CI checks reported for a commit.
+	Checks(ctx context.Context, sha string) ([]Check, error)
+}
+
+// ErrNoToken is returned when no API token is configured for a forge host.
+var ErrNoToken = errors.New("no forge API token configured")
+
+// Summarize folds individual checks into one state: any failure fails,
+// otherwise any pending check keeps the whole set pending.
+func Summarize(checks []Check) CheckState {
+	if len(checks) == 0 {
+		return CheckNone
+	}
+	state := CheckSuccess
+	for _, c := range checks {
+		switch c.State {
+		case CheckFailure:
+			return CheckFailure
+		case CheckPending:
+			state = CheckPending
+		}
+	}
+	return state
+}
+
+// Remote is a parsed git remote pointing at a forge repository.
+type Remote struct {
+	Host  string
+	Owner string // May contain slashes for GitLab subgroups
+	Repo  string
+}
+
+// Path returns "owner/repo".
+func (r Remote) Path() string {
+	return r.Owner + "/" + r.Repo
+}
+
+// ParseRemote parses https, ssh and scp-style git remote URLs.
+func ParseRemote(raw string) (Remote, error) {
+	raw = strings.TrimSpace(raw)
+	var host, path string
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return Remote{}, fmt.Errorf("parse remote %q: %w", raw, err)
+		}
+		host, path = u.Hostname(), u.Path
+	} else if at := strings.Index(raw, "@"); at >= 0 && strings.Contains(raw[at:], ":") {
+		// scp-style: git@github.com:owner/repo.git
+		host, path, _ = strings.Cut(raw[at+1:], ":")
+	} else {
+		return Remote{}, fmt.Errorf("unsupported remote URL %q", raw)
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	slash := strings.LastIndex(path, "/")
+	if host == "" || slash <= 0 || slash == len(path)-1 {
+		return Remote{}, fmt.Errorf("remote %q does not look like owner/repo", raw)
+	}
+	return Remote{Host: strings.ToLower(host), Owner: path[:slash], Repo: path[slash+1:]}, nil
+}
+
+// New returns the forge for remote, using the [forge] section of config.toml
+// for the forge type, API URL and token.
+func New(remote Remote, settings session.ForgeSettings) (Forge, error) {
+	host := settings.Hosts[remote.Host]
+
+	kind := Kind(strings.ToLower(host.Type))
+	if kind == "" {
+		kind = detectKind(remote.Host)
+	}
+	if kind == "" {
+		return nil, fmt.Errorf("unknown forge for host %s: set [forge.hosts.%q] type = \"github\", \"gitlab\" or \"gitea\"", remote.Host, remote.Host)
+	}
+
+	token := host.Token
+	if token == "" {
+		token = defaultToken(kind, settings)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("%w for %s (set [forge] %s_token or $%s)", ErrNoToken, remote.Host, kind, tokenEnv(kind)[0])
+	}
+
+	apiURL := strings.TrimRight(host.APIURL, "/")
+	switch kind {
+	case KindGitHub:
+		if apiURL == "" {
+			apiURL = "https://api.github.com"
+			if remote.Host != "github.com" {
+				apiURL = "https://" + remote.Host + "/api/v3"
+			}
+		}
+		return &gitHub{client: newClient(apiURL, "Authorization", "Bearer "+token), remote: remote}, nil
+	case KindGitLab:
+		if apiURL == "" {
+			apiURL = "https://" + remote.Host + "/api/v4"
+		}
+		return &gitLab{client: newClient(apiURL, "PRIVATE-TOKEN", token), remote: remote}, nil
+	case KindGitea:
+		if apiURL == "" {
+			apiURL = "https://" + remote.Host + "/api/v1"
+		}
+		return &gitea{client: newClient(apiURL, "Authorization", "token "+token), remote: remote}, nil
+	}
+	return nil, fmt.Errorf("unsupported forge type %q", kind)
+}
+
+func detectKind(host string) Kind {
+	switch {
+	case host == "github.com":
+		return KindGitHub
+	case host == "gitlab.com" || strings.HasPrefix(host, "gitlab."):
+		return KindGitLab
+	case host == "codeberg.org" || strings.HasPrefix(host, "gitea."):
+		return KindGitea
+	}
+	return ""
+}
+
+func tokenEnv(kind Kind) []string {
+	switch kind {
+	case KindGitHub:
+		return []string{"GITHUB_TOKEN", "GH_TOKEN"}
+	case KindGitLab:
+		return []string{"GITLAB_TOKEN"}
+	default:
+		return []string{"GITEA_TOKEN"}
+	}
+}
+
+func defaultToken(kind Kind, settings session.ForgeSettings) string {
+	var token string
+	switch kind {
+	case KindGitHub:
+		token = settings.GitHubToken
+	case KindGitLab:
+		token = settings.GitLabToken
+	case KindGitea:
+		token = settings.GiteaToken
+	}
+	if token != "" {
+		return token
+	}
+	for _, env := range tokenEnv(kind) {
+		if v := os.Getenv(env); v != "" {
+			return v
+		}
+	}
+	return ""
+}
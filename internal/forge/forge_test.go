@@ -0,0 +1,394 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Remote
+	}{
+		{"git@github.com:acme/widgets.git", Remote{Host: "github.com", Owner: "acme", Repo: "widgets"}},
+		{"https://github.com/acme/widgets", Remote{Host: "github.com", Owner: "acme", Repo: "widgets"}},
+		{"ssh://git@gitlab.example.com:2222/group/sub/proj.git", Remote{Host: "gitlab.example.com", Owner: "group/sub", Repo: "proj"}},
+		{"https://codeberg.org/me/thing.git/", Remote{Host: "codeberg.org", Owner: "me", Repo: "thing"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRemote(tt.raw)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRemote(%q) = %+v, %v; want %+v", tt.raw, got, err, tt.want)
+		}
+	}
+	if _, err := ParseRemote("/local/path/repo"); err == nil {
+		t.Error("expected local path to be rejected")
+	}
+}
+
+func TestNewResolvesKindAndToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+
+	if _, err := New(Remote{Host: "github.com", Owner: "a", Repo: "b"}, session.ForgeSettings{}); !errors.Is(err, ErrNoToken) {
+		t.Fatalf("expected ErrNoToken, got %v", err)
+	}
+	if _, err := New(Remote{Host: "git.internal", Owner: "a", Repo: "b"}, session.ForgeSettings{}); err == nil {
+		t.Fatal("expected unknown host to require a configured type")
+	}
+
+	t.Setenv("GH_TOKEN", "env-token")
+	f, err := New(Remote{Host: "github.com", Owner: "a", Repo: "b"}, session.ForgeSettings{})
+	if err != nil || f.Kind() != KindGitHub {
+		t.Fatalf("expected GitHub forge from env token, got %v %v", f, err)
+	}
+
+	settings := session.ForgeSettings{Hosts: map[string]session.ForgeHostSettings{
+		"git.internal": {Type: "gitea", Token: "t"},
+	}}
+	f, err = New(Remote{Host: "git.internal", Owner: "a", Repo: "b"}, settings)
+	if err != nil || f.Kind() != KindGitea {
+		t.Fatalf("expected configured Gitea forge, got %v %v", f, err)
+	}
+	if got := f.(*gitea).client.baseURL; got != "https://git.internal/api/v1" {
+		t.Fatalf("unexpected Gitea API URL %q", got)
+	}
+}
+
+func TestDraftFromResponse(t *testing.T) {
+	title, body := DraftFromResponse("\n## Summary:\nAdded retries to the fetcher.\n", "fallback")
+	if title != "Summary" || !strings.HasPrefix(body, "## Summary") {
+		t.Fatalf("unexpected draft %q / %q", title, body)
+	}
+	if title, _ := DraftFromResponse("", "My Session"); title != "My Session" {
+		t.Fatalf("expected fallback title, got %q", title)
+	}
+	if title, _ := DraftFromResponse(strings.Repeat("x", 200), ""); len([]rune(title)) != maxTitleLen {
+		t.Fatalf("expected title truncated to %d runes, got %d", maxTitleLen, len([]rune(title)))
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	if Summarize(nil) != CheckNone {
+		t.Fatal("expected no checks to summarize as none")
+	}
+	if Summarize([]Check{{State: CheckSuccess}, {State: CheckPending}}) != CheckPending {
+		t.Fatal("expected pending to win over success")
+	}
+	if Summarize([]Check{{State: CheckPending}, {State: CheckFailure}}) != CheckFailure {
+		t.Fatal("expected failure to win")
+	}
+}
+
+// fakeForgeAPI is a minimal in-memory forge REST server. routes maps
+// "METHOD path" (without query) to a handler.
+type fakeForgeAPI struct {
+	mu       sync.Mutex
+	requests []string
+	auth     []string
+	routes   map[string]func(r *http.Request, body map[string]any) any
+}
+
+func newFakeForgeAPI(t *testing.T, routes map[string]func(r *http.Request, body map[string]any) any) (*fakeForgeAPI, *httptest.Server) {
+	t.Helper()
+	api := &fakeForgeAPI{routes: routes}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		api.requests = append(api.requests, r.Method+" "+r.URL.RequestURI())
+		api.auth = append(api.auth, r.Header.Get("Authorization")+r.Header.Get("PRIVATE-TOKEN"))
+		api.mu.Unlock()
+
+		handler, ok := routes[r.Method+" "+r.URL.EscapedPath()]
+		if !ok {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		var body map[string]any
+		if r.Body != nil {
+			_ = json.NewDecoder(r.Body).Decode(&body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(handler(r, body))
+	}))
+	t.Cleanup(srv.Close)
+	return api, srv
+}
+
+func newTestForge(t *testing.T, kind Kind, apiURL string) Forge {
+	t.Helper()
+	settings := session.ForgeSettings{Hosts: map[string]session.ForgeHostSettings{
+		"forge.test": {Type: string(kind), Token: "secret", APIURL: apiURL},
+	}}
+	f, err := New(Remote{Host: "forge.test", Owner: "acme", Repo: "widgets"}, settings)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return f
+}
+
+func TestGitHubPublishAndRefresh(t *testing.T) {
+	open := false
+	pull := func(title string) map[string]any {
+		return map[string]any{
+			"number": 7, "html_url": "https://github.test/acme/widgets/pull/7", "title": title, "state": "open",
+			"head": map[string]any{"ref": "feature", "sha": "abc123"}, "base": map[string]any{"ref": "main"},
+		}
+	}
+	api, srv := newFakeForgeAPI(t, map[string]func(*http.Request, map[string]any) any{
+		"GET /repos/acme/widgets/pulls": func(r *http.Request, _ map[string]any) any {
+			if r.URL.Query().Get("head") != "acme:feature" {
+				t.Errorf("unexpected head filter %q", r.URL.Query().Get("head"))
+			}
+			if !open {
+				return []any{}
+			}
+			return []any{pull("old")}
+		},
+		"POST /repos/acme/widgets/pulls": func(_ *http.Request, body map[string]any) any {
+			if body["base"] != "main" || body["head"] != "feature" {
+				t.Errorf("unexpected create body %v", body)
+			}
+			open = true
+			return pull(body["title"].(string))
+		},
+		"PATCH /repos/acme/widgets/pulls/7": func(_ *http.Request, body map[string]any) any {
+			return pull(body["title"].(string))
+		},
+		"GET /repos/acme/widgets/pulls/7": func(*http.Request, map[string]any) any {
+			return pull("new")
+		},
+		"GET /repos/acme/widgets/commits/abc123/check-runs": func(*http.Request, map[string]any) any {
+			return map[string]any{"check_runs": []any{
+				map[string]any{"name": "build", "status": "completed", "conclusion": "success"},
+				map[string]any{"name": "e2e", "status": "in_progress"},
+			}}
+		},
+		"GET /repos/acme/widgets/commits/abc123/status": func(*http.Request, map[string]any) any {
+			return map[string]any{"statuses": []any{map[string]any{"context": "lint", "state": "success"}}}
+		},
+	})
+	f := newTestForge(t, KindGitHub, srv.URL)
+	ctx := context.Background()
+
+	pr, created, err := Publish(ctx, f, NewPullRequest{Head: "feature", Base: "main", Title: "first"})
+	if err != nil || !created || pr.Number != 7 || pr.Title != "first" {
+		t.Fatalf("expected new PR, got %+v created=%v err=%v", pr, created, err)
+	}
+	pr, created, err = Publish(ctx, f, NewPullRequest{Head: "feature", Base: "main", Title: "second"})
+	if err != nil || created || pr.Title != "second" {
+		t.Fatalf("expected existing PR to be updated, got %+v created=%v err=%v", pr, created, err)
+	}
+
+	rec, err := Refresh(ctx, f, Record{SessionID: "s1", PR: *pr})
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if len(rec.Checks) != 3 || rec.CheckState != CheckPending || rec.PR.State != PRStateOpen {
+		t.Fatalf("unexpected refreshed record %+v", rec)
+	}
+	if rec.Badge() != "#7 open …" {
+		t.Fatalf("unexpected badge %q", rec.Badge())
+	}
+	for _, auth := range api.auth {
+		if auth != "Bearer secret" {
+			t.Fatalf("expected bearer auth, got %q", auth)
+		}
+	}
+}
+
+func TestGitLabPublishAndRefresh(t *testing.T) {
+	mr := map[string]any{
+		"iid": 3, "web_url": "https://gitlab.test/acme/widgets/-/merge_requests/3", "title": "Draft: wip",
+		"state": "opened", "draft": true, "source_branch": "feature", "target_branch": "main", "sha": "def456",
+	}
+	api, srv := newFakeForgeAPI(t, map[string]func(*http.Request, map[string]any) any{
+		"GET /projects/acme%2Fwidgets/merge_requests": func(*http.Request, map[string]any) any {
+			return []any{}
+		},
+		"POST /projects/acme%2Fwidgets/merge_requests": func(_ *http.Request, body map[string]any) any {
+			if body["title"] != "Draft: wip" || body["source_branch"] != "feature" {
+				t.Errorf("unexpected create body %v", body)
+			}
+			return mr
+		},
+		"GET /projects/acme%2Fwidgets/merge_requests/3": func(*http.Request, map[string]any) any {
+			merged := map[string]any{}
+			for k, v := range mr {
+				merged[k] = v
+			}
+			merged["state"] = "merged"
+			return merged
+		},
+		"GET /projects/acme%2Fwidgets/repository/commits/def456/statuses": func(*http.Request, map[string]any) any {
+			return []any{map[string]any{"name": "test", "status": "failed"}}
+		},
+	})
+	f := newTestForge(t, KindGitLab, srv.URL)
+	ctx := context.Background()
+
+	pr, created, err := Publish(ctx, f, NewPullRequest{Head: "feature", Base: "main", Title: "wip", Draft: true})
+	if err != nil || !created || pr.State != PRStateDraft {
+		t.Fatalf("expected draft MR, got %+v created=%v err=%v", pr, created, err)
+	}
+	rec, err := Refresh(ctx, f, Record{PR: *pr})
+	if err != nil || rec.PR.State != PRStateMerged || rec.CheckState != CheckFailure {
+		t.Fatalf("unexpected refreshed record %+v err=%v", rec, err)
+	}
+	if api.auth[0] != "secret" {
+		t.Fatalf("expected PRIVATE-TOKEN auth, got %q", api.auth[0])
+	}
+}
+
+func TestGitLabUpdateKeepsDraft(t *testing.T) {
+	mr := map[string]any{
+		"iid": 3, "web_url": "https://gitlab.test/acme/widgets/-/merge_requests/3", "title": "Draft: wip",
+		"state": "opened", "draft": true, "source_branch": "feature", "target_branch": "main", "sha": "def456",
+	}
+	var updatedTitle any
+	_, srv := newFakeForgeAPI(t, map[string]func(*http.Request, map[string]any) any{
+		"GET /projects/acme%2Fwidgets/merge_requests": func(*http.Request, map[string]any) any {
+			return []any{mr}
+		},
+		"GET /projects/acme%2Fwidgets/merge_requests/3": func(*http.Request, map[string]any) any {
+			return mr
+		},
+		"PUT /projects/acme%2Fwidgets/merge_requests/3": func(_ *http.Request, body map[string]any) any {
+			updatedTitle = body["title"]
+			return mr
+		},
+	})
+	f := newTestForge(t, KindGitLab, srv.URL)
+
+	pr, created, err := Publish(context.Background(), f, NewPullRequest{Head: "feature", Base: "main", Title: "wip v2"})
+	if err != nil || created || pr.State != PRStateDraft {
+		t.Fatalf("expected draft MR to be updated, got %+v created=%v err=%v", pr, created, err)
+	}
+	if updatedTitle != "Draft: wip v2" {
+		t.Fatalf("expected draft prefix to be kept, got %v", updatedTitle)
+	}
+
+	mr["draft"] = false
+	if _, _, err := Publish(context.Background(), f, NewPullRequest{Head: "feature", Base: "main", Title: "ready"}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if updatedTitle != "ready" {
+		t.Fatalf("expected ready MR title unchanged, got %v", updatedTitle)
+	}
+}
+
+func TestGiteaUpdateKeepsDraft(t *testing.T) {
+	pull := map[string]any{
+		"number": 5, "html_url": "https://gitea.test/acme/widgets/pulls/5", "title": "WIP: wip", "state": "open",
+		"head": map[string]any{"ref": "feature", "sha": "abc"}, "base": map[string]any{"ref": "main"},
+	}
+	var updatedTitle any
+	_, srv := newFakeForgeAPI(t, map[string]func(*http.Request, map[string]any) any{
+		"GET /repos/acme/widgets/pulls": func(*http.Request, map[string]any) any {
+			return []any{pull}
+		},
+		"GET /repos/acme/widgets/pulls/5": func(*http.Request, map[string]any) any {
+			return pull
+		},
+		"PATCH /repos/acme/widgets/pulls/5": func(_ *http.Request, body map[string]any) any {
+			updatedTitle = body["title"]
+			updated := map[string]any{}
+			for k, v := range pull {
+				updated[k] = v
+			}
+			updated["title"] = body["title"]
+			return updated
+		},
+	})
+	f := newTestForge(t, KindGitea, srv.URL)
+
+	pr, created, err := Publish(context.Background(), f, NewPullRequest{Head: "feature", Base: "main", Title: "wip v2"})
+	if err != nil || created || pr.State != PRStateDraft {
+		t.Fatalf("expected draft pull to be updated, got %+v created=%v err=%v", pr, created, err)
+	}
+	if updatedTitle != "WIP: wip v2" {
+		t.Fatalf("expected draft prefix to be kept, got %v", updatedTitle)
+	}
+
+	pull["title"] = "ready"
+	if _, _, err := Publish(context.Background(), f, NewPullRequest{Head: "feature", Base: "main", Title: "ready v2"}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if updatedTitle != "ready v2" {
+		t.Fatalf("expected ready pull title unchanged, got %v", updatedTitle)
+	}
+}
+
+func TestGiteaFindsPullByHeadRef(t *testing.T) {
+	pull := func(n int, ref string) map[string]any {
+		return map[string]any{
+			"number": n, "html_url": "https://gitea.test/pulls", "title": "t", "state": "open",
+			"head": map[string]any{"ref": ref, "sha": "fff"}, "base": map[string]any{"ref": "main"},
+		}
+	}
+	api, srv := newFakeForgeAPI(t, map[string]func(*http.Request, map[string]any) any{
+		"GET /repos/acme/widgets/pulls": func(*http.Request, map[string]any) any {
+			return []any{pull(1, "other"), pull(2, "feature")}
+		},
+		"PATCH /repos/acme/widgets/pulls/2": func(*http.Request, map[string]any) any {
+			return pull(2, "feature")
+		},
+		"GET /repos/acme/widgets/pulls/2": func(*http.Request, map[string]any) any {
+			p := pull(2, "feature")
+			p["state"] = "closed"
+			return p
+		},
+		"GET /repos/acme/widgets/commits/fff/status": func(*http.Request, map[string]any) any {
+			return map[string]any{"statuses": []any{map[string]any{"context": "ci", "status": "success"}}}
+		},
+	})
+	f := newTestForge(t, KindGitea, srv.URL)
+	ctx := context.Background()
+
+	pr, created, err := Publish(ctx, f, NewPullRequest{Head: "feature", Base: "main", Title: "t"})
+	if err != nil || created || pr.Number != 2 {
+		t.Fatalf("expected existing PR #2 to be updated, got %+v created=%v err=%v", pr, created, err)
+	}
+	rec, err := Refresh(ctx, f, Record{PR: *pr})
+	if err != nil || rec.PR.State != PRStateClosed || rec.CheckState != CheckSuccess {
+		t.Fatalf("unexpected refreshed record %+v err=%v", rec, err)
+	}
+	if api.auth[0] != "token secret" {
+		t.Fatalf("expected token auth, got %q", api.auth[0])
+	}
+}
+
+func TestAPIErrorsSurface(t *testing.T) {
+	_, srv := newFakeForgeAPI(t, nil)
+	f := newTestForge(t, KindGitHub, srv.URL)
+	_, err := f.GetPullRequest(context.Background(), 1)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound {
+		t.Fatalf("expected 404 APIError, got %v", err)
+	}
+}
+
+func TestRecordsRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	rec := Record{SessionID: "s1", Forge: KindGitHub, RemoteName: "upstream", PR: PullRequest{Number: 4, State: PRStateOpen}, CheckState: CheckSuccess}
+	if err := SaveRecord("default", rec); err != nil {
+		t.Fatalf("SaveRecord: %v", err)
+	}
+	records, err := LoadRecords("default")
+	if err != nil {
+		t.Fatalf("LoadRecords: %v", err)
+	}
+	if got := records["s1"]; got.PR.Number != 4 || got.Badge() != "#4 open ✓" || got.GitRemote() != "upstream" {
+		t.Fatalf("unexpected record %+v", got)
+	}
+	if got := (Record{}).GitRemote(); got != "origin" {
+		t.Fatalf("records without a remote name should use origin, got %q", got)
+	}
+}
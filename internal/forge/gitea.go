@@ -0,0 +1,135 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// gitea implements Forge for Gitea and Forgejo (e.g. Codeberg).
+type gitea struct {
+	client *client
+	remote Remote
+}
+
+type giteaPull struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Title   string `json:"title"`
+	State   string `json:"state"`
+	Merged  bool   `json:"merged"`
+	Head    struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// giteaDraftPrefix marks work-in-progress pulls; Gitea has no draft flag.
+const giteaDraftPrefix = "WIP: "
+
+func (p *giteaPull) toPullRequest() *PullRequest {
+	pr := &PullRequest{
+		Number:  p.Number,
+		URL:     p.HTMLURL,
+		Title:   p.Title,
+		State:   PRStateOpen,
+		Head:    p.Head.Ref,
+		Base:    p.Base.Ref,
+		HeadSHA: p.Head.SHA,
+	}
+	switch {
+	case p.Merged:
+		pr.State = PRStateMerged
+	case p.State == "closed":
+		pr.State = PRStateClosed
+	case strings.HasPrefix(p.Title, giteaDraftPrefix):
+		pr.State = PRStateDraft
+	}
+	return pr
+}
+
+func (g *gitea) Kind() Kind { return KindGitea }
+
+func (g *gitea) repoPath() string {
+	return "/repos/" + g.remote.Owner + "/" + g.remote.Repo
+}
+
+func (g *gitea) FindPullRequest(ctx context.Context, head string) (*PullRequest, error) {
+	// Older Gitea releases cannot filter by head, so page through open pulls.
+	for page := 1; page <= 10; page++ {
+		var pulls []giteaPull
+		path := fmt.Sprintf("%s/pulls?state=open&limit=50&page=%d", g.repoPath(), page)
+		if err := g.client.do(ctx, http.MethodGet, path, nil, &pulls); err != nil {
+			return nil, err
+		}
+		for i := range pulls {
+			if pulls[i].Head.Ref == head {
+				return pulls[i].toPullRequest(), nil
+			}
+		}
+		if len(pulls) < 50 {
+			break
+		}
+	}
+	return nil, nil
+}
+
+func (g *gitea) GetPullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	var pull giteaPull
+	if err := g.client.do(ctx, http.MethodGet, fmt.Sprintf("%s/pulls/%d", g.repoPath(), number), nil, &pull); err != nil {
+		return nil, err
+	}
+	return pull.toPullRequest(), nil
+}
+
+func (g *gitea) CreatePullRequest(ctx context.Context, pr NewPullRequest) (*PullRequest, error) {
+	title := pr.Title
+	if pr.Draft {
+		title = giteaDraftPrefix + title
+	}
+	body := map[string]any{"head": pr.Head, "base": pr.Base, "title": title, "body": pr.Body}
+	var pull giteaPull
+	if err := g.client.do(ctx, http.MethodPost, g.repoPath()+"/pulls", body, &pull); err != nil {
+		return nil, err
+	}
+	return pull.toPullRequest(), nil
+}
+
+// UpdatePullRequest keeps a draft pull a draft: the draft state lives in
+// the title prefix, so a plain title would mark it ready for review.
+func (g *gitea) UpdatePullRequest(ctx context.Context, number int, title, body string) (*PullRequest, error) {
+	current, err := g.GetPullRequest(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if current.State == PRStateDraft && !strings.HasPrefix(title, giteaDraftPrefix) {
+		title = giteaDraftPrefix + title
+	}
+	var pull giteaPull
+	req := map[string]any{"title": title, "body": body}
+	if err := g.client.do(ctx, http.MethodPatch, fmt.Sprintf("%s/pulls/%d", g.repoPath(), number), req, &pull); err != nil {
+		return nil, err
+	}
+	return pull.toPullRequest(), nil
+}
+
+func (g *gitea) Checks(ctx context.Context, sha string) ([]Check, error) {
+	var combined struct {
+		Statuses []struct {
+			Context string `json:"context"`
+			Status  string `json:"status"`
+		} `json:"statuses"`
+	}
+	if err := g.client.do(ctx, http.MethodGet, g.repoPath()+"/commits/"+sha+"/status", nil, &combined); err != nil {
+		return nil, err
+	}
+	checks := make([]Check, 0, len(combined.Statuses))
+	for _, s := range combined.Statuses {
+		checks = append(checks, Check{Name: s.Context, State: commitStatusState(s.Status)})
+	}
+	return checks, nil
+}
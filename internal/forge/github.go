@@ -0,0 +1,149 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// gitHub implements Forge for GitHub and GitHub Enterprise.
+type gitHub struct {
+	client *client
+	remote Remote
+}
+
+type gitHubPull struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Title   string `json:"title"`
+	State   string `json:"state"`
+	Draft   bool   `json:"draft"`
+	Merged  bool   `json:"merged"`
+	// MergedAt is set on list responses, which omit "merged".
+	MergedAt *string `json:"merged_at"`
+	Head     struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+func (p *gitHubPull) toPullRequest() *PullRequest {
+	pr := &PullRequest{
+		Number:  p.Number,
+		URL:     p.HTMLURL,
+		Title:   p.Title,
+		State:   PRStateOpen,
+		Head:    p.Head.Ref,
+		Base:    p.Base.Ref,
+		HeadSHA: p.Head.SHA,
+	}
+	switch {
+	case p.Merged || p.MergedAt != nil:
+		pr.State = PRStateMerged
+	case p.State == "closed":
+		pr.State = PRStateClosed
+	case p.Draft:
+		pr.State = PRStateDraft
+	}
+	return pr
+}
+
+func (g *gitHub) Kind() Kind { return KindGitHub }
+
+func (g *gitHub) repoPath() string {
+	return "/repos/" + g.remote.Owner + "/" + g.remote.Repo
+}
+
+func (g *gitHub) FindPullRequest(ctx context.Context, head string) (*PullRequest, error) {
+	q := url.Values{"state": {"open"}, "head": {g.remote.Owner + ":" + head}}
+	var pulls []gitHubPull
+	if err := g.client.do(ctx, http.MethodGet, g.repoPath()+"/pulls?"+q.Encode(), nil, &pulls); err != nil {
+		return nil, err
+	}
+	if len(pulls) == 0 {
+		return nil, nil
+	}
+	return pulls[0].toPullRequest(), nil
+}
+
+func (g *gitHub) GetPullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	var pull gitHubPull
+	if err := g.client.do(ctx, http.MethodGet, fmt.Sprintf("%s/pulls/%d", g.repoPath(), number), nil, &pull); err != nil {
+		return nil, err
+	}
+	return pull.toPullRequest(), nil
+}
+
+func (g *gitHub) CreatePullRequest(ctx context.Context, pr NewPullRequest) (*PullRequest, error) {
+	body := map[string]any{"title": pr.Title, "head": pr.Head, "base": pr.Base, "body": pr.Body, "draft": pr.Draft}
+	var pull gitHubPull
+	if err := g.client.do(ctx, http.MethodPost, g.repoPath()+"/pulls", body, &pull); err != nil {
+		return nil, err
+	}
+	return pull.toPullRequest(), nil
+}
+
+func (g *gitHub) UpdatePullRequest(ctx context.Context, number int, title, body string) (*PullRequest, error) {
+	var pull gitHubPull
+	req := map[string]any{"title": title, "body": body}
+	if err := g.client.do(ctx, http.MethodPatch, fmt.Sprintf("%s/pulls/%d", g.repoPath(), number), req, &pull); err != nil {
+		return nil, err
+	}
+	return pull.toPullRequest(), nil
+}
+
+func (g *gitHub) Checks(ctx context.Context, sha string) ([]Check, error) {
+	var runs struct {
+		CheckRuns []struct {
+			Name       string `json:"name"`
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+	if err := g.client.do(ctx, http.MethodGet, g.repoPath()+"/commits/"+sha+"/check-runs", nil, &runs); err != nil {
+		return nil, err
+	}
+	var status struct {
+		Statuses []struct {
+			Context string `json:"context"`
+			State   string `json:"state"`
+		} `json:"statuses"`
+	}
+	if err := g.client.do(ctx, http.MethodGet, g.repoPath()+"/commits/"+sha+"/status", nil, &status); err != nil {
+		return nil, err
+	}
+
+	checks := make([]Check, 0, len(runs.CheckRuns)+len(status.Statuses))
+	for _, run := range runs.CheckRuns {
+		state := CheckPending
+		if run.Status == "completed" {
+			switch run.Conclusion {
+			case "success", "neutral", "skipped":
+				state = CheckSuccess
+			default:
+				state = CheckFailure
+			}
+		}
+		checks = append(checks, Check{Name: run.Name, State: state})
+	}
+	for _, s := range status.Statuses {
+		checks = append(checks, Check{Name: s.Context, State: commitStatusState(s.State)})
+	}
+	return checks, nil
+}
+
+// commitStatusState maps GitHub/Gitea commit status values.
+func commitStatusState(state string) CheckState {
+	switch state {
+	case "success":
+		return CheckSuccess
+	case "pending":
+		return CheckPending
+	default: // failure, error, warning
+		return CheckFailure
+	}
+}
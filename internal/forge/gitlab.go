@@ -0,0 +1,138 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// gitLab implements Forge for GitLab merge requests.
+type gitLab struct {
+	client *client
+	remote Remote
+}
+
+type gitLabMergeRequest struct {
+	IID          int    `json:"iid"`
+	WebURL       string `json:"web_url"`
+	Title        string `json:"title"`
+	State        string `json:"state"`
+	Draft        bool   `json:"draft"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	SHA          string `json:"sha"`
+}
+
+func (m *gitLabMergeRequest) toPullRequest() *PullRequest {
+	pr := &PullRequest{
+		Number:  m.IID,
+		URL:     m.WebURL,
+		Title:   m.Title,
+		State:   PRStateOpen,
+		Head:    m.SourceBranch,
+		Base:    m.TargetBranch,
+		HeadSHA: m.SHA,
+	}
+	switch {
+	case m.State == "merged":
+		pr.State = PRStateMerged
+	case m.State == "closed" || m.State == "locked":
+		pr.State = PRStateClosed
+	case m.Draft:
+		pr.State = PRStateDraft
+	}
+	return pr
+}
+
+func (g *gitLab) Kind() Kind { return KindGitLab }
+
+func (g *gitLab) projectPath() string {
+	return "/projects/" + url.PathEscape(g.remote.Path())
+}
+
+func (g *gitLab) FindPullRequest(ctx context.Context, head string) (*PullRequest, error) {
+	q := url.Values{"state": {"opened"}, "source_branch": {head}}
+	var mrs []gitLabMergeRequest
+	if err := g.client.do(ctx, http.MethodGet, g.projectPath()+"/merge_requests?"+q.Encode(), nil, &mrs); err != nil {
+		return nil, err
+	}
+	if len(mrs) == 0 {
+		return nil, nil
+	}
+	return mrs[0].toPullRequest(), nil
+}
+
+func (g *gitLab) GetPullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	var mr gitLabMergeRequest
+	if err := g.client.do(ctx, http.MethodGet, fmt.Sprintf("%s/merge_requests/%d", g.projectPath(), number), nil, &mr); err != nil {
+		return nil, err
+	}
+	return mr.toPullRequest(), nil
+}
+
+func (g *gitLab) CreatePullRequest(ctx context.Context, pr NewPullRequest) (*PullRequest, error) {
+	title := pr.Title
+	if pr.Draft {
+		title = gitLabDraftTitle(title)
+	}
+	body := map[string]any{"source_branch": pr.Head, "target_branch": pr.Base, "title": title, "description": pr.Body}
+	var mr gitLabMergeRequest
+	if err := g.client.do(ctx, http.MethodPost, g.projectPath()+"/merge_requests", body, &mr); err != nil {
+		return nil, err
+	}
+	return mr.toPullRequest(), nil
+}
+
+// UpdatePullRequest keeps a draft MR a draft: GitLab derives the draft state
+// from the title prefix, so a plain title would mark it ready for review.
+func (g *gitLab) UpdatePullRequest(ctx context.Context, number int, title, body string) (*PullRequest, error) {
+	path := fmt.Sprintf("%s/merge_requests/%d", g.projectPath(), number)
+	var current gitLabMergeRequest
+	if err := g.client.do(ctx, http.MethodGet, path, nil, &current); err != nil {
+		return nil, err
+	}
+	if current.Draft {
+		title = gitLabDraftTitle(title)
+	}
+	var mr gitLabMergeRequest
+	req := map[string]any{"title": title, "description": body}
+	if err := g.client.do(ctx, http.MethodPut, path, req, &mr); err != nil {
+		return nil, err
+	}
+	return mr.toPullRequest(), nil
+}
+
+// gitLabDraftTitle adds GitLab's "Draft: " prefix unless title already has one.
+func gitLabDraftTitle(title string) string {
+	lower := strings.ToLower(title)
+	for _, prefix := range []string{"draft:", "[draft]", "(draft)"} {
+		if strings.HasPrefix(lower, prefix) {
+			return title
+		}
+	}
+	return "Draft: " + title
+}
+
+func (g *gitLab) Checks(ctx context.Context, sha string) ([]Check, error) {
+	var statuses []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	}
+	if err := g.client.do(ctx, http.MethodGet, g.projectPath()+"/repository/commits/"+sha+"/statuses", nil, &statuses); err != nil {
+		return nil, err
+	}
+	checks := make([]Check, 0, len(statuses))
+	for _, s := range statuses {
+		state := CheckPending
+		switch s.Status {
+		case "success", "skipped":
+			state = CheckSuccess
+		case "failed", "canceled":
+			state = CheckFailure
+		}
+		checks = append(checks, Check{Name: s.Name, State: state})
+	}
+	return checks, nil
+}
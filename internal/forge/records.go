@@ -0,0 +1,205 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+const pullRequestsFileName = "pull_requests.json"
+
+// Record is the last known pull request state for a session. Records are
+// cached per profile so `agent-deck list` and the TUI can show PR and CI
+// state without calling the forge.
+type Record struct {
+	SessionID  string      `json:"session_id"`
+	Forge      Kind        `json:"forge"`
+	Remote     string      `json:"remote"`
+	RemoteName string      `json:"remote_name,omitempty"` // git remote the PR was opened through
+	PR         PullRequest `json:"pr"`
+	Checks     []Check     `json:"checks,omitempty"`
+	CheckState CheckState  `json:"check_state,omitempty"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// GitRemote returns the git remote to resolve the record's forge from.
+// Records saved before the remote name was stored used origin.
+func (r Record) GitRemote() string {
+	if r.RemoteName != "" {
+		return r.RemoteName
+	}
+	return "origin"
+}
+
+// Badge is a short label like "#12 open ✓" for session lists.
+func (r Record) Badge() string {
+	badge := fmt.Sprintf("#%d %s", r.PR.Number, r.PR.State)
+	switch r.CheckState {
+	case CheckSuccess:
+		badge += " ✓"
+	case CheckFailure:
+		badge += " ✗"
+	case CheckPending:
+		badge += " …"
+	}
+	return badge
+}
+
+// recordsMu serializes read-modify-write cycles on the records file within
+// this process.
+var recordsMu sync.Mutex
+
+func recordsPath(profile string) (string, error) {
+	profileDir, err := session.GetProfileDir(session.GetEffectiveProfile(profile))
+	if err != nil {
+		return "", fmt.Errorf("resolve profile dir: %w", err)
+	}
+	return filepath.Join(profileDir, pullRequestsFileName), nil
+}
+
+// LoadRecords returns the cached records for profile keyed by session ID.
+func LoadRecords(profile string) (map[string]Record, error) {
+	path, err := recordsPath(profile)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]Record{}, nil
+		}
+		return nil, fmt.Errorf("read pull request records: %w", err)
+	}
+	records := map[string]Record{}
+	if err := json.Unmarshal(raw, &records); err != nil {
+		return nil, fmt.Errorf("parse pull request records: %w", err)
+	}
+	return records, nil
+}
+
+// SaveRecord stores rec, replacing any previous record for the session.
+func SaveRecord(profile string, rec Record) error {
+	recordsMu.Lock()
+	defer recordsMu.Unlock()
+
+	records, err := LoadRecords(profile)
+	if err != nil {
+		return err
+	}
+	records[rec.SessionID] = rec
+
+	path, err := recordsPath(profile)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("mkdir pull request records dir: %w", err)
+	}
+	raw, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal pull request records: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("write temp pull request records: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename pull request records: %w", err)
+	}
+	return nil
+}
+
+// ForRepo returns the forge hosting the given remote of repoDir.
+func ForRepo(repoDir, remoteName string) (Forge, Remote, error) {
+	rawURL, err := git.GetRemoteURL(repoDir, remoteName)
+	if err != nil {
+		return nil, Remote{}, err
+	}
+	remote, err := ParseRemote(rawURL)
+	if err != nil {
+		return nil, Remote{}, err
+	}
+	f, err := New(remote, session.GetForgeSettings())
+	if err != nil {
+		return nil, Remote{}, err
+	}
+	return f, remote, nil
+}
+
+// Publish opens a pull request for pr.Head, or updates the title and body of
+// the one already open. created reports whether a new one was opened.
+func Publish(ctx context.Context, f Forge, pr NewPullRequest) (result *PullRequest, created bool, err error) {
+	existing, err := f.FindPullRequest(ctx, pr.Head)
+	if err != nil {
+		return nil, false, fmt.Errorf("look up existing pull request: %w", err)
+	}
+	if existing != nil {
+		updated, err := f.UpdatePullRequest(ctx, existing.Number, pr.Title, pr.Body)
+		if err != nil {
+			return nil, false, fmt.Errorf("update pull request #%d: %w", existing.Number, err)
+		}
+		return updated, false, nil
+	}
+	opened, err := f.CreatePullRequest(ctx, pr)
+	if err != nil {
+		return nil, false, fmt.Errorf("create pull request: %w", err)
+	}
+	return opened, true, nil
+}
+
+// Refresh re-reads a pull request and the checks on its head commit and
+// returns an up-to-date record.
+func Refresh(ctx context.Context, f Forge, rec Record) (Record, error) {
+	pr, err := f.GetPullRequest(ctx, rec.PR.Number)
+	if err != nil {
+		return rec, err
+	}
+	rec.PR = *pr
+	rec.Forge = f.Kind()
+	rec.Checks = nil
+	if pr.HeadSHA != "" {
+		checks, err := f.Checks(ctx, pr.HeadSHA)
+		if err != nil {
+			return rec, fmt.Errorf("load checks: %w", err)
+		}
+		rec.Checks = checks
+	}
+	rec.CheckState = Summarize(rec.Checks)
+	rec.UpdatedAt = time.Now()
+	return rec, nil
+}
+
+// maxTitleLen keeps generated titles within what forges display in lists.
+const maxTitleLen = 72
+
+// DraftFromResponse derives a PR title and body from a session's last
+// response. The first non-empty line (minus markdown heading marks) becomes
+// the title; fallbackTitle is used when the response is empty.
+func DraftFromResponse(response, fallbackTitle string) (title, body string) {
+	body = strings.TrimSpace(response)
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#*- "))
+		line = strings.TrimRight(line, "*:")
+		if line != "" {
+			title = line
+			break
+		}
+	}
+	if title == "" {
+		title = fallbackTitle
+	}
+	if runes := []rune(title); len(runes) > maxTitleLen {
+		title = strings.TrimSpace(string(runes[:maxTitleLen-1])) + "…"
+	}
+	return title, body
+}
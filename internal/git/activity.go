@@ -0,0 +1,202 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxActivityDiffBytes caps the diff collected for the TUI git panel.
+const maxActivityDiffBytes = 256 * 1024
+
+// maxUntrackedDiffs caps how many untracked files are diffed for the panel.
+const maxUntrackedDiffs = 20
+
+// Activity summarizes what has changed in a working tree: branch position
+// relative to the default branch, uncommitted files, recent commits and the
+// uncommitted diff.
+type Activity struct {
+	Branch  string
+	Base    string // Default branch; empty when it cannot be determined
+	Ahead   int
+	Behind  int
+	Files   []FileStat
+	Commits []CommitInfo
+	Diff    string
+	// DiffTruncated is set when Diff was cut at maxActivityDiffBytes.
+	DiffTruncated bool
+}
+
+// FileStat is one uncommitted file with its line counts against HEAD.
+type FileStat struct {
+	Path    string
+	Status  string // Two-letter porcelain status, e.g. " M", "A ", "??"
+	Added   int
+	Deleted int
+	Binary  bool
+}
+
+// CommitInfo is a commit in the activity log.
+type CommitInfo struct {
+	Hash    string
+	Subject string
+	When    time.Time
+}
+
+// CollectActivity gathers the git activity of the repository at dir. Commits
+// are limited to those made after since (zero means the last 10 commits).
+func CollectActivity(dir string, since time.Time) (*Activity, error) {
+	branch, err := GetCurrentBranch(dir)
+	if err != nil {
+		return nil, err
+	}
+	a := &Activity{Branch: branch}
+
+	if base, err := GetDefaultBranch(dir); err == nil {
+		a.Base = base
+		if base != branch {
+			a.Ahead, a.Behind = aheadBehind(dir, base)
+		}
+	}
+
+	if a.Files, err = UncommittedFiles(dir); err != nil {
+		return nil, err
+	}
+	a.Commits = recentCommits(dir, since)
+
+	diffOut, _ := exec.Command("git", "-C", dir, "diff", "HEAD", "--no-color", "--no-ext-diff").Output()
+	// git diff HEAD leaves out untracked files; diff them against /dev/null
+	diffed := 0
+	for i := range a.Files {
+		f := &a.Files[i]
+		if f.Status != "??" || diffed == maxUntrackedDiffs || len(diffOut) >= maxActivityDiffBytes {
+			continue
+		}
+		diffed++
+		out := untrackedDiff(dir, f.Path)
+		f.Binary = isBinaryDiff(out)
+		f.Added = countAddedLines(out)
+		diffOut = append(diffOut, out...)
+	}
+	if len(diffOut) > maxActivityDiffBytes {
+		diffOut = diffOut[:maxActivityDiffBytes]
+		a.DiffTruncated = true
+	}
+	a.Diff = string(diffOut)
+	return a, nil
+}
+
+// untrackedDiff returns the diff that adds the untracked file at path.
+func untrackedDiff(dir, path string) []byte {
+	out, err := exec.Command("git", "-C", dir, "diff", "--no-index", "--no-color", "--no-ext-diff", "--", "/dev/null", path).Output()
+	// --no-index exits 1 when the files differ, which they always do here
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return nil
+	}
+	return out
+}
+
+// isBinaryDiff reports whether diff is git's "Binary files ... differ" stub.
+func isBinaryDiff(diff []byte) bool {
+	for _, line := range strings.Split(string(diff), "\n") {
+		if strings.HasPrefix(line, "Binary files") {
+			return true
+		}
+	}
+	return false
+}
+
+func countAddedLines(diff []byte) int {
+	added := 0
+	for _, line := range strings.Split(string(diff), "\n") {
+		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
+			added++
+		}
+	}
+	return added
+}
+
+func aheadBehind(dir, base string) (ahead, behind int) {
+	out, err := exec.Command("git", "-C", dir, "rev-list", "--left-right", "--count", base+"...HEAD").Output()
+	if err != nil {
+		return 0, 0
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 2 {
+		behind, _ = strconv.Atoi(fields[0])
+		ahead, _ = strconv.Atoi(fields[1])
+	}
+	return ahead, behind
+}
+
+// UncommittedFiles lists the files that differ from HEAD, including untracked
+// ones, with their line counts against HEAD (untracked files have none).
+// Output is NUL-separated so paths with spaces or unusual characters come
+// through unquoted.
+func UncommittedFiles(dir string) ([]FileStat, error) {
+	statusOut, err := exec.Command("git", "-C", dir, "status", "--porcelain", "-z", "--untracked-files=all").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check git status: %w", err)
+	}
+
+	counts := make(map[string]FileStat)
+	if numstat, err := exec.Command("git", "-C", dir, "diff", "HEAD", "--numstat", "-z", "--no-renames").Output(); err == nil {
+		for _, line := range strings.Split(string(numstat), "\x00") {
+			parts := strings.SplitN(line, "\t", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			fs := FileStat{Path: parts[2]}
+			if parts[0] == "-" {
+				fs.Binary = true
+			} else {
+				fs.Added, _ = strconv.Atoi(parts[0])
+				fs.Deleted, _ = strconv.Atoi(parts[1])
+			}
+			counts[fs.Path] = fs
+		}
+	}
+
+	var files []FileStat
+	entries := strings.Split(string(statusOut), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		status := entry[:2]
+		if status[0] == 'R' || status[0] == 'C' {
+			i++ // -z puts the rename source in the next entry
+		}
+		fs := counts[entry[3:]]
+		fs.Path = entry[3:]
+		fs.Status = status
+		files = append(files, fs)
+	}
+	return files, nil
+}
+
+func recentCommits(dir string, since time.Time) []CommitInfo {
+	args := []string{"-C", dir, "log", "--format=%h%x09%ct%x09%s", "-n", "10"}
+	if !since.IsZero() {
+		args = append(args, "--since="+since.Format(time.RFC3339))
+	}
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil
+	}
+	var commits []CommitInfo
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		ts, _ := strconv.ParseInt(parts[1], 10, 64)
+		commits = append(commits, CommitInfo{Hash: parts[0], When: time.Unix(ts, 0), Subject: parts[2]})
+	}
+	return commits
+}
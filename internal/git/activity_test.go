@@ -0,0 +1,62 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollectActivity(t *testing.T) {
+	_, wt, target := setupFinishRepo(t, "main.txt", "feature.txt")
+
+	if err := os.WriteFile(filepath.Join(wt, "README.md"), []byte("# Test Repo\nmore\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wt, "notes.txt"), []byte("draft\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wt, "release notes.txt"), []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := CollectActivity(wt, time.Time{})
+	if err != nil {
+		t.Fatalf("CollectActivity: %v", err)
+	}
+	if a.Branch != "feature" || a.Base != target {
+		t.Fatalf("unexpected branch/base %q/%q", a.Branch, a.Base)
+	}
+	if a.Ahead != 1 || a.Behind != 1 {
+		t.Fatalf("expected ahead 1 / behind 1, got %d/%d", a.Ahead, a.Behind)
+	}
+
+	byPath := make(map[string]FileStat)
+	for _, f := range a.Files {
+		byPath[f.Path] = f
+	}
+	if f := byPath["README.md"]; f.Status != " M" || f.Added != 2 || f.Deleted != 1 {
+		t.Fatalf("unexpected README.md stat %+v", f)
+	}
+	if f := byPath["notes.txt"]; f.Status != "??" || f.Added != 1 {
+		t.Fatalf("expected untracked notes.txt, got %+v", f)
+	}
+	if f := byPath["release notes.txt"]; f.Status != "??" || f.Added != 2 {
+		t.Fatalf("expected unquoted untracked path with a space, got %+v", a.Files)
+	}
+	if len(a.Commits) == 0 || a.Commits[0].Subject != "change feature.txt" {
+		t.Fatalf("expected latest commit first, got %+v", a.Commits)
+	}
+	if !strings.Contains(a.Diff, "+more") {
+		t.Fatalf("expected uncommitted diff, got %q", a.Diff)
+	}
+	if !strings.Contains(a.Diff, "+++ b/notes.txt") || !strings.Contains(a.Diff, "+draft") {
+		t.Fatalf("expected untracked files in the diff, got %q", a.Diff)
+	}
+
+	future, err := CollectActivity(wt, time.Now().Add(time.Hour))
+	if err != nil || len(future.Commits) != 0 {
+		t.Fatalf("expected no commits after since, got %+v %v", future.Commits, err)
+	}
+}
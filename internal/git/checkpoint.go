@@ -0,0 +1,251 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CheckpointRefPrefix is the namespace for session checkpoints. Refs outside
+// refs/heads, refs/tags and refs/remotes are not walked by a plain `git log`,
+// so checkpoints stay out of the user's history.
+const CheckpointRefPrefix = "refs/agent-deck/checkpoints/"
+
+// Checkpoint is a snapshot of a working tree stored as a commit under
+// refs/agent-deck/checkpoints/<session>/<n>.
+type Checkpoint struct {
+	N       int       `json:"n"`
+	Ref     string    `json:"ref"`
+	Commit  string    `json:"commit"`
+	Created time.Time `json:"created"`
+	Message string    `json:"message"`
+}
+
+// CheckpointRef returns the ref name of checkpoint n for sessionID.
+func CheckpointRef(sessionID string, n int) string {
+	return fmt.Sprintf("%s%s/%d", CheckpointRefPrefix, sessionID, n)
+}
+
+// snapshotTree writes the current working tree (tracked changes plus
+// untracked, non-ignored files) as a tree object using a throwaway index, so
+// the user's index and branch are left untouched.
+func snapshotTree(dir string) (string, error) {
+	indexPath, err := runGit(dir, "rev-parse", "--git-path", "index")
+	if err != nil {
+		return "", fmt.Errorf("failed to locate index: %s: %w", indexPath, err)
+	}
+	if !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(dir, indexPath)
+	}
+
+	tmp, err := os.CreateTemp("", "agent-deck-index-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp index: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	// Seeding from the real index keeps `git add -A` fast (stat cache hits)
+	if src, err := os.Open(indexPath); err == nil {
+		_, _ = io.Copy(tmp, src)
+		src.Close()
+	}
+	tmp.Close()
+
+	env := append(os.Environ(), "GIT_INDEX_FILE="+tmpPath)
+	add := exec.Command("git", "-C", dir, "add", "-A")
+	add.Env = env
+	if out, err := add.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to stage snapshot: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	write := exec.Command("git", "-C", dir, "write-tree")
+	write.Env = env
+	out, err := write.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to write snapshot tree: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// CreateCheckpoint snapshots the working tree at dir into the next
+// checkpoint ref for sessionID. It returns the latest checkpoint and whether
+// a new one was created: nothing is written when the tree matches the
+// previous checkpoint or, for the first checkpoint, HEAD.
+func CreateCheckpoint(dir, sessionID, message string) (*Checkpoint, bool, error) {
+	tree, err := snapshotTree(dir)
+	if err != nil {
+		return nil, false, err
+	}
+
+	existing, err := ListCheckpoints(dir, sessionID)
+	if err != nil {
+		return nil, false, err
+	}
+	head, headErr := runGit(dir, "rev-parse", "--verify", "-q", "HEAD")
+	if len(existing) > 0 {
+		latest := existing[len(existing)-1]
+		if latestTree, err := runGit(dir, "rev-parse", latest.Commit+"^{tree}"); err == nil && latestTree == tree {
+			return &latest, false, nil
+		}
+	} else if headErr == nil {
+		if headTree, err := runGit(dir, "rev-parse", "HEAD^{tree}"); err == nil && headTree == tree {
+			return nil, false, nil
+		}
+	}
+
+	args := []string{"-C", dir, "commit-tree", tree, "-m", message}
+	if headErr == nil {
+		args = append(args, "-p", head)
+	}
+	commitCmd := exec.Command("git", args...)
+	// Checkpoints are not user commits; a fixed identity also keeps them
+	// working in repos without user.name/user.email configured.
+	commitCmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=agent-deck", "GIT_AUTHOR_EMAIL=agent-deck@localhost",
+		"GIT_COMMITTER_NAME=agent-deck", "GIT_COMMITTER_EMAIL=agent-deck@localhost",
+	)
+	out, err := commitCmd.Output()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create checkpoint commit: %w", err)
+	}
+	commit := strings.TrimSpace(string(out))
+
+	n := 1
+	if len(existing) > 0 {
+		n = existing[len(existing)-1].N + 1
+	}
+	// An empty old value makes update-ref fail instead of overwriting a ref
+	// created concurrently by another agent-deck process.
+	for attempt := 0; ; attempt++ {
+		ref := CheckpointRef(sessionID, n)
+		msg, err := runGit(dir, "update-ref", "-m", "agent-deck checkpoint", ref, commit, "")
+		if err == nil {
+			return &Checkpoint{N: n, Ref: ref, Commit: commit, Created: time.Now(), Message: message}, true, nil
+		}
+		if attempt == 2 {
+			return nil, false, fmt.Errorf("failed to store checkpoint: %s: %w", msg, err)
+		}
+		n++
+	}
+}
+
+// ListCheckpoints returns the checkpoints of sessionID, oldest first.
+func ListCheckpoints(dir, sessionID string) ([]Checkpoint, error) {
+	prefix := CheckpointRefPrefix + sessionID + "/"
+	out, err := runGit(dir, "for-each-ref", "--format=%(refname)%09%(objectname)%09%(creatordate:unix)%09%(contents:subject)", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %s: %w", out, err)
+	}
+	var checkpoints []Checkpoint
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "\t", 4)
+		if len(parts) < 3 {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimPrefix(parts[0], prefix))
+		if err != nil {
+			continue
+		}
+		ts, _ := strconv.ParseInt(parts[2], 10, 64)
+		cp := Checkpoint{N: n, Ref: parts[0], Commit: parts[1], Created: time.Unix(ts, 0)}
+		if len(parts) == 4 {
+			cp.Message = parts[3]
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+	sort.Slice(checkpoints, func(i, j int) bool { return checkpoints[i].N < checkpoints[j].N })
+	return checkpoints, nil
+}
+
+// GetCheckpoint returns checkpoint n of sessionID.
+func GetCheckpoint(dir, sessionID string, n int) (*Checkpoint, error) {
+	checkpoints, err := ListCheckpoints(dir, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range checkpoints {
+		if checkpoints[i].N == n {
+			return &checkpoints[i], nil
+		}
+	}
+	return nil, fmt.Errorf("checkpoint %d not found", n)
+}
+
+// CheckpointDiff returns the diff from checkpoint n to checkpoint other, or
+// to the current working tree (including untracked files) when other is 0.
+func CheckpointDiff(dir, sessionID string, n, other int) (string, error) {
+	from, err := GetCheckpoint(dir, sessionID, n)
+	if err != nil {
+		return "", err
+	}
+	var to string
+	if other > 0 {
+		cp, err := GetCheckpoint(dir, sessionID, other)
+		if err != nil {
+			return "", err
+		}
+		to = cp.Commit
+	} else if to, err = snapshotTree(dir); err != nil {
+		return "", err
+	}
+	out, err := exec.Command("git", "-C", dir, "diff", "--no-color", "--no-ext-diff", from.Commit, to).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff checkpoint: %w", err)
+	}
+	return string(out), nil
+}
+
+// RestoreCheckpoint rewrites the working tree at dir to checkpoint n. A
+// safety checkpoint of the current state is taken first and returned (nil
+// when the tree already matched the last checkpoint). The index and branch
+// are not modified. With clean, files that did not exist at the checkpoint
+// are deleted; otherwise they are left in place. The removed paths are
+// returned.
+func RestoreCheckpoint(dir, sessionID string, n int, clean bool) (*Checkpoint, []string, error) {
+	cp, err := GetCheckpoint(dir, sessionID, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := snapshotTree(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	safety, created, err := CreateCheckpoint(dir, sessionID, fmt.Sprintf("before restore of checkpoint %d", n))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to save current state: %w", err)
+	}
+	if !created {
+		safety = nil
+	}
+
+	if out, err := runGit(dir, "restore", "--source="+cp.Commit, "--worktree", "--", ":/"); err != nil {
+		return safety, nil, fmt.Errorf("failed to restore checkpoint: %s: %w", out, err)
+	}
+
+	var removed []string
+	if clean {
+		added, err := runGit(dir, "diff", "--name-only", "--no-renames", "--diff-filter=A", cp.Commit, current)
+		if err != nil {
+			return safety, nil, fmt.Errorf("failed to list added files: %s: %w", added, err)
+		}
+		root, err := runGit(dir, "rev-parse", "--show-toplevel")
+		if err != nil {
+			return safety, nil, fmt.Errorf("failed to resolve worktree root: %w", err)
+		}
+		for _, path := range strings.Split(added, "\n") {
+			if path == "" {
+				continue
+			}
+			if err := os.Remove(filepath.Join(root, path)); err == nil || os.IsNotExist(err) {
+				removed = append(removed, path)
+			}
+		}
+	}
+	return safety, removed, nil
+}
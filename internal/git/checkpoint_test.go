@@ -0,0 +1,109 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckpointLifecycle(t *testing.T) {
+	repo := t.TempDir()
+	createTestRepo(t, repo)
+	headBefore := headOf(t, repo, "HEAD")
+	readme := filepath.Join(repo, "README.md")
+
+	// A clean tree matches HEAD, so there is nothing to checkpoint yet
+	if cp, created, err := CreateCheckpoint(repo, "sess-1", "idle"); err != nil || created || cp != nil {
+		t.Fatalf("expected no checkpoint for clean tree, got %+v %v %v", cp, created, err)
+	}
+
+	if err := os.WriteFile(readme, []byte("# Test\nfirst\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "notes.txt"), []byte("draft\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	first, created, err := CreateCheckpoint(repo, "sess-1", "running -> waiting")
+	if err != nil || !created || first.N != 1 {
+		t.Fatalf("CreateCheckpoint: %+v %v %v", first, created, err)
+	}
+	if _, created, _ := CreateCheckpoint(repo, "sess-1", "again"); created {
+		t.Fatal("expected unchanged tree to reuse the latest checkpoint")
+	}
+
+	// Index, branch and log are untouched
+	if status, _ := runGit(repo, "diff", "--cached", "--name-only"); status != "" {
+		t.Fatalf("index was modified: %q", status)
+	}
+	if headOf(t, repo, "HEAD") != headBefore {
+		t.Fatal("HEAD moved")
+	}
+	if log, _ := runGit(repo, "log", "--oneline"); strings.Contains(log, "running -> waiting") {
+		t.Fatalf("checkpoint visible in git log: %s", log)
+	}
+
+	// Trash the tree, take a second checkpoint, then restore the first
+	if err := os.WriteFile(readme, []byte("broken\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "junk.txt"), []byte("junk\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(repo, "notes.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if _, created, err := CreateCheckpoint(repo, "sess-1", "second"); err != nil || !created {
+		t.Fatalf("second checkpoint: %v %v", created, err)
+	}
+
+	diff, err := CheckpointDiff(repo, "sess-1", 1, 0)
+	if err != nil || !strings.Contains(diff, "+broken") || !strings.Contains(diff, "notes.txt") {
+		t.Fatalf("unexpected diff %q (%v)", diff, err)
+	}
+
+	safety, removed, err := RestoreCheckpoint(repo, "sess-1", 1, true)
+	if err != nil {
+		t.Fatalf("RestoreCheckpoint: %v", err)
+	}
+	if safety != nil {
+		t.Fatalf("expected no safety checkpoint when tree matches latest, got %+v", safety)
+	}
+	if len(removed) != 1 || removed[0] != "junk.txt" {
+		t.Fatalf("expected junk.txt removed, got %v", removed)
+	}
+	if got, _ := os.ReadFile(readme); string(got) != "# Test\nfirst\n" {
+		t.Fatalf("README not restored: %q", got)
+	}
+	if got, err := os.ReadFile(filepath.Join(repo, "notes.txt")); err != nil || string(got) != "draft\n" {
+		t.Fatalf("notes.txt not restored: %q %v", got, err)
+	}
+
+	checkpoints, err := ListCheckpoints(repo, "sess-1")
+	if err != nil || len(checkpoints) != 2 || checkpoints[1].Message != "second" {
+		t.Fatalf("ListCheckpoints: %+v %v", checkpoints, err)
+	}
+	if other, _ := ListCheckpoints(repo, "sess-2"); len(other) != 0 {
+		t.Fatalf("expected checkpoints scoped per session, got %+v", other)
+	}
+}
+
+func TestCheckpointRefsHiddenFromLogAll(t *testing.T) {
+	repo := t.TempDir()
+	createTestRepo(t, repo)
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cp, _, err := CreateCheckpoint(repo, "s", "snap")
+	if err != nil || cp == nil {
+		t.Fatalf("CreateCheckpoint: %v", err)
+	}
+	out, err := exec.Command("git", "-C", repo, "log", "--branches", "--tags", "--format=%H").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), cp.Commit) {
+		t.Fatal("checkpoint commit reachable from branches or tags")
+	}
+}
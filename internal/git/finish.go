@@ -0,0 +1,187 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// MergeStrategy selects how a worktree branch is integrated into its target.
+type MergeStrategy string
+
+const (
+	MergeStrategyMerge  MergeStrategy = "merge"
+	MergeStrategyRebase MergeStrategy = "rebase"
+	MergeStrategySquash MergeStrategy = "squash"
+	MergeStrategyFFOnly MergeStrategy = "ff-only"
+)
+
+// MergeStrategies lists the supported strategies in display order.
+var MergeStrategies = []MergeStrategy{MergeStrategyMerge, MergeStrategyRebase, MergeStrategySquash, MergeStrategyFFOnly}
+
+// ParseMergeStrategy validates a strategy name. Empty means merge.
+func ParseMergeStrategy(name string) (MergeStrategy, error) {
+	name = strings.TrimSpace(strings.ToLower(name))
+	if name == "" {
+		return MergeStrategyMerge, nil
+	}
+	for _, s := range MergeStrategies {
+		if string(s) == name {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unknown merge strategy %q (want merge, rebase, squash or ff-only)", name)
+}
+
+// MergeConflictError reports that integrating Source into Target would
+// conflict. Files lists the conflicted paths.
+type MergeConflictError struct {
+	Source string
+	Target string
+	Files  []string
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("merging %s into %s would conflict in %d file(s): %s",
+		e.Source, e.Target, len(e.Files), strings.Join(e.Files, ", "))
+}
+
+// ErrNotFastForward is returned by the ff-only strategy when the target has
+// commits the source branch does not.
+var ErrNotFastForward = errors.New("target branch has diverged; cannot fast-forward")
+
+// CheckMergeConflicts computes the merge of source into target with
+// `git merge-tree` without touching any worktree or index. It returns the
+// conflicted files, or nil when the merge is clean. Requires git 2.38+.
+func CheckMergeConflicts(repoDir, target, source string) ([]string, error) {
+	cmd := exec.Command("git", "-C", repoDir, "merge-tree", "--write-tree", "--name-only", "--no-messages", target, source)
+	output, err := cmd.Output()
+	if err == nil {
+		return nil, nil
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		stderr := ""
+		if exitErr != nil {
+			stderr = strings.TrimSpace(string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("merge-tree pre-flight failed (git 2.38+ required): %s: %w", stderr, err)
+	}
+
+	// Exit 1: first line is the tree OID, then one conflicted path per line.
+	var files []string
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		files = append(files, line)
+	}
+	return files, nil
+}
+
+// IsAncestor reports whether ancestor is reachable from descendant.
+func IsAncestor(repoDir, ancestor, descendant string) bool {
+	cmd := exec.Command("git", "-C", repoDir, "merge-base", "--is-ancestor", ancestor, descendant)
+	return cmd.Run() == nil
+}
+
+// PreflightFinish checks that source can be integrated into target with the
+// given strategy. It only reads repository objects; on a
+// *MergeConflictError or ErrNotFastForward nothing has been modified.
+func PreflightFinish(repoDir, target, source string, strategy MergeStrategy) error {
+	if strategy == MergeStrategyFFOnly {
+		if !IsAncestor(repoDir, target, source) {
+			return ErrNotFastForward
+		}
+		return nil
+	}
+	files, err := CheckMergeConflicts(repoDir, target, source)
+	if err != nil {
+		return err
+	}
+	if len(files) > 0 {
+		return &MergeConflictError{Source: source, Target: target, Files: files}
+	}
+	return nil
+}
+
+// FinishBranch integrates source (checked out in worktreePath) into target
+// in the main worktree at repoDir using strategy. Callers should run
+// PreflightFinish first. Rebase rewrites source in its own worktree and then
+// fast-forwards target; a rebase that still conflicts is aborted.
+func FinishBranch(repoDir, worktreePath, target, source string, strategy MergeStrategy) error {
+	if strategy == MergeStrategyRebase {
+		if out, err := runGit(worktreePath, "rebase", target); err != nil {
+			files, _ := conflictedFiles(worktreePath)
+			_, _ = runGit(worktreePath, "rebase", "--abort")
+			if len(files) > 0 {
+				return &MergeConflictError{Source: source, Target: target, Files: files}
+			}
+			return fmt.Errorf("rebase failed (aborted): %s: %w", out, err)
+		}
+	}
+
+	if out, err := runGit(repoDir, "checkout", target); err != nil {
+		return fmt.Errorf("failed to checkout %s: %s", target, out)
+	}
+
+	switch strategy {
+	case MergeStrategyRebase, MergeStrategyFFOnly:
+		if out, err := runGit(repoDir, "merge", "--ff-only", source); err != nil {
+			return fmt.Errorf("fast-forward failed: %s: %w", out, err)
+		}
+	case MergeStrategySquash:
+		if out, err := runGit(repoDir, "merge", "--squash", source); err != nil {
+			_, _ = runGit(repoDir, "reset", "--merge")
+			return fmt.Errorf("squash merge failed (aborted): %s: %w", out, err)
+		}
+		msg := fmt.Sprintf("Squash merge branch '%s'", source)
+		if out, err := runGit(repoDir, "commit", "-m", msg); err != nil {
+			_, _ = runGit(repoDir, "reset", "--merge")
+			return fmt.Errorf("squash commit failed: %s: %w", out, err)
+		}
+	default:
+		if err := MergeBranch(repoDir, source); err != nil {
+			_, _ = runGit(repoDir, "merge", "--abort")
+			return fmt.Errorf("merge failed (aborted): %w", err)
+		}
+	}
+	return nil
+}
+
+// ConflictHandoffPrompt builds the message sent back to the agent that owns
+// the branch, asking it to resolve the conflicts in its worktree.
+func ConflictHandoffPrompt(conflict *MergeConflictError) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Finishing this worktree failed: merging branch '%s' into '%s' conflicts in these files:\n",
+		conflict.Source, conflict.Target)
+	for _, f := range conflict.Files {
+		fmt.Fprintf(&b, "- %s\n", f)
+	}
+	fmt.Fprintf(&b, "Please run `git rebase %s` (or merge it) in this worktree, resolve the conflicts, "+
+		"make sure the build and tests pass, commit, and tell me when the branch is ready to finish again.", conflict.Target)
+	return b.String()
+}
+
+func conflictedFiles(dir string) ([]string, error) {
+	out, err := runGit(dir, "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
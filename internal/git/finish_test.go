@@ -0,0 +1,139 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupFinishRepo creates a repo whose default branch and a "feature"
+// worktree each have one commit touching the given files.
+func setupFinishRepo(t *testing.T, mainFile, featureFile string) (repo, worktree, target string) {
+	t.Helper()
+	repo = t.TempDir()
+	createTestRepo(t, repo)
+	target, err := GetCurrentBranch(repo)
+	if err != nil {
+		t.Fatalf("GetCurrentBranch: %v", err)
+	}
+
+	worktree = filepath.Join(t.TempDir(), "wt")
+	if err := CreateWorktree(repo, worktree, "feature"); err != nil {
+		t.Fatalf("CreateWorktree: %v", err)
+	}
+	commitFile(t, worktree, featureFile, "feature change\n")
+	if mainFile != "" {
+		commitFile(t, repo, mainFile, "main change\n")
+	}
+	return repo, worktree, target
+}
+
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	for _, args := range [][]string{{"add", name}, {"commit", "-m", "change " + name}} {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+}
+
+func headOf(t *testing.T, dir, ref string) string {
+	t.Helper()
+	out, err := exec.Command("git", "-C", dir, "rev-parse", ref).Output()
+	if err != nil {
+		t.Fatalf("rev-parse %s: %v", ref, err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestParseMergeStrategy(t *testing.T) {
+	if s, err := ParseMergeStrategy(""); err != nil || s != MergeStrategyMerge {
+		t.Fatalf("expected default merge, got %q %v", s, err)
+	}
+	if s, err := ParseMergeStrategy("FF-Only"); err != nil || s != MergeStrategyFFOnly {
+		t.Fatalf("expected ff-only, got %q %v", s, err)
+	}
+	if _, err := ParseMergeStrategy("octopus"); err == nil {
+		t.Fatal("expected unknown strategy to fail")
+	}
+}
+
+func TestPreflightFinishDetectsConflictsWithoutTouchingRepo(t *testing.T) {
+	repo, _, target := setupFinishRepo(t, "README.md", "README.md")
+	before := headOf(t, repo, "HEAD")
+
+	err := PreflightFinish(repo, target, "feature", MergeStrategyMerge)
+	var conflict *MergeConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected MergeConflictError, got %v", err)
+	}
+	if len(conflict.Files) != 1 || conflict.Files[0] != "README.md" {
+		t.Fatalf("unexpected conflicted files: %v", conflict.Files)
+	}
+	if headOf(t, repo, "HEAD") != before {
+		t.Fatal("pre-flight must not move HEAD")
+	}
+	if dirty, _ := HasUncommittedChanges(repo); dirty {
+		t.Fatal("pre-flight must not touch the main worktree")
+	}
+	if !strings.Contains(ConflictHandoffPrompt(conflict), "- README.md") {
+		t.Fatal("expected handoff prompt to list conflicted files")
+	}
+
+	if err := PreflightFinish(repo, target, "feature", MergeStrategyFFOnly); !errors.Is(err, ErrNotFastForward) {
+		t.Fatalf("expected ErrNotFastForward, got %v", err)
+	}
+}
+
+func TestFinishBranchStrategies(t *testing.T) {
+	t.Run("rebase keeps history linear", func(t *testing.T) {
+		repo, wt, target := setupFinishRepo(t, "main.txt", "feature.txt")
+		if err := PreflightFinish(repo, target, "feature", MergeStrategyRebase); err != nil {
+			t.Fatalf("PreflightFinish: %v", err)
+		}
+		if err := FinishBranch(repo, wt, target, "feature", MergeStrategyRebase); err != nil {
+			t.Fatalf("FinishBranch: %v", err)
+		}
+		if headOf(t, repo, target) != headOf(t, repo, "feature") {
+			t.Fatal("expected target to be fast-forwarded to the rebased branch")
+		}
+		parents, _ := exec.Command("git", "-C", repo, "rev-list", "--parents", "-n", "1", target).Output()
+		if len(strings.Fields(string(parents))) != 2 {
+			t.Fatalf("expected a non-merge commit, got parents %q", parents)
+		}
+	})
+
+	t.Run("squash creates a single commit", func(t *testing.T) {
+		repo, wt, target := setupFinishRepo(t, "main.txt", "feature.txt")
+		before := headOf(t, repo, target)
+		if err := FinishBranch(repo, wt, target, "feature", MergeStrategySquash); err != nil {
+			t.Fatalf("FinishBranch: %v", err)
+		}
+		if headOf(t, repo, target+"~1") != before {
+			t.Fatal("expected exactly one new commit on target")
+		}
+		if _, err := os.Stat(filepath.Join(repo, "feature.txt")); err != nil {
+			t.Fatalf("expected squashed change in main worktree: %v", err)
+		}
+	})
+
+	t.Run("ff-only fast-forwards", func(t *testing.T) {
+		repo, wt, target := setupFinishRepo(t, "", "feature.txt")
+		if err := PreflightFinish(repo, target, "feature", MergeStrategyFFOnly); err != nil {
+			t.Fatalf("PreflightFinish: %v", err)
+		}
+		if err := FinishBranch(repo, wt, target, "feature", MergeStrategyFFOnly); err != nil {
+			t.Fatalf("FinishBranch: %v", err)
+		}
+		if headOf(t, repo, target) != headOf(t, repo, "feature") {
+			t.Fatal("expected target to point at feature")
+		}
+	})
+}
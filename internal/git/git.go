@@ -426,3 +426,23 @@ func PruneWorktrees(repoDir string) error {
 	}
 	return nil
 }
+
+// GetRemoteURL returns the fetch URL of the named remote (e.g. "origin")
+func GetRemoteURL(repoDir, remote string) (string, error) {
+	cmd := exec.Command("git", "-C", repoDir, "remote", "get-url", remote)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote %s: %s: %w", remote, strings.TrimSpace(string(output)), err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// PushBranch pushes a branch to the remote and sets it as the upstream
+func PushBranch(repoDir, remote, branchName string) error {
+	cmd := exec.Command("git", "-C", repoDir, "push", "--set-upstream", remote, branchName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("push failed: %s: %w", strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LineRange is an inclusive range of lines in the base version of a file.
+// A pure insertion is recorded as the single line it follows.
+type LineRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Overlaps reports whether two ranges share a line.
+func (r LineRange) Overlaps(o LineRange) bool {
+	return r.Start <= o.End && o.Start <= r.End
+}
+
+// ChangeSet maps each changed path to the base-side line ranges it touches.
+// A nil range slice means the whole file (added, deleted, binary or untracked).
+type ChangeSet map[string][]LineRange
+
+// FileOverlap is a path changed on both sides of a comparison.
+type FileOverlap struct {
+	Path string `json:"path"`
+	// Hunks is true when the edits touch the same base lines, not just the same file.
+	Hunks bool `json:"hunks"`
+}
+
+// ChangedHunks returns what the worktree at dir changed relative to its
+// merge-base with baseRef, including uncommitted and untracked files.
+func ChangedHunks(dir, baseRef string) (ChangeSet, error) {
+	mbOut, err := exec.Command("git", "-C", dir, "merge-base", baseRef, "HEAD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find merge-base with %s: %w", baseRef, err)
+	}
+	mergeBase := strings.TrimSpace(string(mbOut))
+
+	diffOut, err := exec.Command("git", "-C", dir, "diff", "-U0", "--no-color", "--no-ext-diff", "--no-renames", mergeBase).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff against %s: %w", baseRef, err)
+	}
+	changes := parseUnifiedDiffHunks(string(diffOut))
+
+	untracked, err := exec.Command("git", "-C", dir, "ls-files", "--others", "--exclude-standard").Output()
+	if err == nil {
+		for _, path := range strings.Split(string(untracked), "\n") {
+			if path = strings.TrimSpace(path); path != "" {
+				changes[path] = nil
+			}
+		}
+	}
+	return changes, nil
+}
+
+// parseUnifiedDiffHunks extracts base-side hunk ranges from `git diff -U0`.
+func parseUnifiedDiffHunks(diff string) ChangeSet {
+	changes := ChangeSet{}
+	var current string
+	wholeFile := false
+	// Header lines only appear between "diff --git" and the file's first
+	// hunk; after that a removed "-- comment" line reads like "--- comment"
+	inHeader := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			current, wholeFile, inHeader = "", false, true
+		case !inHeader:
+			if strings.HasPrefix(line, "@@ ") && current != "" && !wholeFile {
+				if r, ok := parseHunkHeader(line); ok {
+					changes[current] = append(changes[current], r)
+				}
+			}
+		case strings.HasPrefix(line, "new file mode"), strings.HasPrefix(line, "deleted file mode"), strings.HasPrefix(line, "Binary files"):
+			wholeFile = true
+			if current == "" {
+				// Binary diffs have no ---/+++ lines; take the path from the header
+				if p := binaryDiffPath(line); p != "" {
+					current = p
+				}
+			}
+			if current != "" {
+				changes[current] = nil
+			}
+		case strings.HasPrefix(line, "--- "):
+			if p := strings.TrimPrefix(line, "--- "); p != "/dev/null" {
+				current = strings.TrimPrefix(p, "a/")
+			}
+		case strings.HasPrefix(line, "+++ "):
+			if p := strings.TrimPrefix(line, "+++ "); p != "/dev/null" {
+				current = strings.TrimPrefix(p, "b/")
+			}
+			if wholeFile {
+				changes[current] = nil
+			} else if _, ok := changes[current]; !ok {
+				changes[current] = []LineRange{}
+			}
+		case strings.HasPrefix(line, "@@ "):
+			inHeader = false
+			if current != "" && !wholeFile {
+				if r, ok := parseHunkHeader(line); ok {
+					changes[current] = append(changes[current], r)
+				}
+			}
+		}
+	}
+	return changes
+}
+
+func binaryDiffPath(line string) string {
+	// "Binary files a/x and b/x differ"
+	fields := strings.Fields(line)
+	if len(fields) >= 5 {
+		p := fields[len(fields)-2]
+		if p == "/dev/null" {
+			p = fields[2]
+		}
+		return strings.TrimPrefix(strings.TrimPrefix(p, "a/"), "b/")
+	}
+	return ""
+}
+
+// parseHunkHeader parses the base side of "@@ -start[,count] +start[,count] @@".
+func parseHunkHeader(line string) (LineRange, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") {
+		return LineRange{}, false
+	}
+	startStr, countStr, hasCount := strings.Cut(strings.TrimPrefix(fields[1], "-"), ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return LineRange{}, false
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return LineRange{}, false
+		}
+	}
+	if count == 0 {
+		// Insertion after line start: treat the neighbouring line as touched
+		return LineRange{Start: start, End: start + 1}, true
+	}
+	return LineRange{Start: start, End: start + count - 1}, true
+}
+
+// CompareChanges returns the files changed in both sets, sorted by path.
+func CompareChanges(a, b ChangeSet) []FileOverlap {
+	var overlaps []FileOverlap
+	for path, ra := range a {
+		rb, ok := b[path]
+		if !ok {
+			continue
+		}
+		overlaps = append(overlaps, FileOverlap{Path: path, Hunks: rangesOverlap(ra, rb)})
+	}
+	sort.Slice(overlaps, func(i, j int) bool { return overlaps[i].Path < overlaps[j].Path })
+	return overlaps
+}
+
+func rangesOverlap(a, b []LineRange) bool {
+	if a == nil || b == nil {
+		return true
+	}
+	for _, ra := range a {
+		for _, rb := range b {
+			if ra.Overlaps(rb) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseUnifiedDiffHunks(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a/app.go b/app.go",
+		"index 1111111..2222222 100644",
+		"--- a/app.go",
+		"+++ b/app.go",
+		"@@ -10,3 +10,4 @@ func main() {",
+		"@@ -40 +41 @@ func helper() {",
+		"@@ -50,0 +52,2 @@",
+		"diff --git a/new.txt b/new.txt",
+		"new file mode 100644",
+		"--- /dev/null",
+		"+++ b/new.txt",
+		"@@ -0,0 +1 @@",
+		"diff --git a/logo.png b/logo.png",
+		"index 3333333..4444444 100644",
+		"Binary files a/logo.png and b/logo.png differ",
+	}, "\n")
+
+	changes := parseUnifiedDiffHunks(diff)
+	want := []LineRange{{10, 12}, {40, 40}, {50, 51}}
+	if got := changes["app.go"]; len(got) != len(want) {
+		t.Fatalf("app.go hunks = %v, want %v", got, want)
+	} else {
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("app.go hunk %d = %v, want %v", i, got[i], want[i])
+			}
+		}
+	}
+	for _, path := range []string{"new.txt", "logo.png"} {
+		if r, ok := changes[path]; !ok || r != nil {
+			t.Fatalf("expected %s as whole-file change, got %v (present=%v)", path, r, ok)
+		}
+	}
+}
+
+func TestParseUnifiedDiffHunks_CommentLinesInBody(t *testing.T) {
+	// Removing "-- x" and adding "++ y" produce lines that look like file headers
+	diff := strings.Join([]string{
+		"diff --git a/schema.sql b/schema.sql",
+		"index 1111111..2222222 100644",
+		"--- a/schema.sql",
+		"+++ b/schema.sql",
+		"@@ -3 +3 @@",
+		"--- drop the legacy table",
+		"+-- keep the legacy table",
+		"@@ -9,2 +9,0 @@",
+		"--- a/other.sql",
+		"-DROP TABLE users;",
+		"diff --git a/init.lua b/init.lua",
+		"index 3333333..4444444 100644",
+		"--- a/init.lua",
+		"+++ b/init.lua",
+		"@@ -1 +1 @@",
+		"---- settings",
+		"+++ b/fake.lua",
+	}, "\n")
+
+	changes := parseUnifiedDiffHunks(diff)
+	if len(changes) != 2 {
+		t.Fatalf("expected only schema.sql and init.lua, got %v", changes)
+	}
+	if got := changes["schema.sql"]; len(got) != 2 || got[0] != (LineRange{3, 3}) || got[1] != (LineRange{9, 10}) {
+		t.Fatalf("schema.sql hunks = %v", got)
+	}
+	if got := changes["init.lua"]; len(got) != 1 || got[0] != (LineRange{1, 1}) {
+		t.Fatalf("init.lua hunks = %v", got)
+	}
+}
+
+func TestCompareChanges(t *testing.T) {
+	a := ChangeSet{"shared.go": {{1, 5}}, "same.go": {{10, 20}}, "only-a.go": {{1, 1}}, "whole.txt": nil}
+	b := ChangeSet{"shared.go": {{30, 31}}, "same.go": {{20, 22}}, "whole.txt": {{3, 3}}}
+
+	got := CompareChanges(a, b)
+	want := []FileOverlap{{"same.go", true}, {"shared.go", false}, {"whole.txt", true}}
+	if len(got) != len(want) {
+		t.Fatalf("CompareChanges = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("overlap %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestChangedHunksIncludesUncommittedAndUntracked(t *testing.T) {
+	repo, wt, target := setupFinishRepo(t, "", "feature.txt")
+
+	if err := os.WriteFile(filepath.Join(wt, "README.md"), []byte("# Test\nedited\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wt, "scratch.txt"), []byte("tmp\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := ChangedHunks(wt, target)
+	if err != nil {
+		t.Fatalf("ChangedHunks: %v", err)
+	}
+	for _, path := range []string{"feature.txt", "README.md", "scratch.txt"} {
+		if _, ok := changes[path]; !ok {
+			t.Fatalf("expected %s in changes, got %v", path, changes)
+		}
+	}
+	if r := changes["README.md"]; len(r) != 1 {
+		t.Fatalf("expected one README.md hunk, got %v", r)
+	}
+
+	// The main worktree has no changes relative to its own base
+	mainChanges, err := ChangedHunks(repo, target)
+	if err != nil || len(mainChanges) != 0 {
+		t.Fatalf("expected no changes in main worktree, got %v %v", mainChanges, err)
+	}
+}
@@ -0,0 +1,177 @@
+package session
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// AgentAnalytics is the tool-agnostic analytics shape shared by every agent.
+// Claude, Gemini, Codex and OpenCode parsers all normalize into it so the
+// analytics panel and `session show --json` render the same fields.
+type AgentAnalytics struct {
+	Tool  string `json:"tool"`
+	Model string `json:"model,omitempty"`
+
+	// Token usage (cumulative across all turns). InputTokens excludes cached
+	// input, which is reported separately in CacheReadTokens.
+	InputTokens      int `json:"input_tokens"`
+	OutputTokens     int `json:"output_tokens"`
+	CacheReadTokens  int `json:"cache_read_tokens"`
+	CacheWriteTokens int `json:"cache_write_tokens"`
+
+	// Current context size and the model's window (0 = tool default)
+	CurrentContextTokens int `json:"current_context_tokens"`
+	ContextWindow        int `json:"context_window,omitempty"`
+
+	// Session metrics
+	TotalTurns int           `json:"total_turns"`
+	Duration   time.Duration `json:"duration"`
+	StartTime  time.Time     `json:"start_time"`
+	LastActive time.Time     `json:"last_active"`
+
+	// Tool usage
+	ToolCalls []ToolCall `json:"tool_calls"`
+
+	// Cost estimation
+	EstimatedCost float64 `json:"estimated_cost"`
+}
+
+// defaultContextWindows is used when a session file does not report its
+// model's context window
+var defaultContextWindows = map[string]int{
+	"claude":   200000,
+	"gemini":   1000000,
+	"codex":    272000,
+	"opencode": 200000,
+}
+
+// TotalTokens returns the sum of all token types
+func (a *AgentAnalytics) TotalTokens() int {
+	return a.InputTokens + a.OutputTokens + a.CacheReadTokens + a.CacheWriteTokens
+}
+
+// ContextLimit returns the context window used for ContextPercent
+func (a *AgentAnalytics) ContextLimit() int {
+	return ContextLimitFor(a.Tool, a.Model, a.ContextWindow)
+}
+
+// ContextLimitFor resolves a context window: a [context.model_limits] entry
+// for the model, then the window the tool reported, then the tool's default,
+// then [context].default_limit (200000 when unset)
+func ContextLimitFor(tool, model string, reported int) int {
+	settings := GetContextSettings()
+	if model != "" {
+		best, limit := 0, 0
+		for name, l := range settings.ModelLimits {
+			if l > 0 && strings.HasPrefix(model, name) && len(name) > best {
+				best, limit = len(name), l
+			}
+		}
+		if limit > 0 {
+			return limit
+		}
+	}
+	if reported > 0 {
+		return reported
+	}
+	if limit, ok := defaultContextWindows[tool]; ok {
+		return limit
+	}
+	if settings.DefaultLimit > 0 {
+		return settings.DefaultLimit
+	}
+	return 200000
+}
+
+// ContextPercent returns the percentage of the context window in use
+func (a *AgentAnalytics) ContextPercent() float64 {
+	return float64(a.CurrentContextTokens) / float64(a.ContextLimit()) * 100
+}
+
+// NewAgentAnalyticsFromClaude normalizes analytics parsed from a Claude JSONL file
+func NewAgentAnalyticsFromClaude(a *SessionAnalytics) *AgentAnalytics {
+	if a == nil {
+		return nil
+	}
+	cost := a.EstimatedCost
+	if cost == 0 && a.TotalTokens() > 0 {
+		// Use default Sonnet pricing
+		cost = a.CalculateCost("default")
+	}
+	return &AgentAnalytics{
+		Tool:                 "claude",
+		Model:                a.Model,
+		InputTokens:          a.InputTokens,
+		OutputTokens:         a.OutputTokens,
+		CacheReadTokens:      a.CacheReadTokens,
+		CacheWriteTokens:     a.CacheWriteTokens,
+		CurrentContextTokens: a.CurrentContextTokens,
+		TotalTurns:           a.TotalTurns,
+		Duration:             a.Duration,
+		StartTime:            a.StartTime,
+		LastActive:           a.LastActive,
+		ToolCalls:            a.ToolCalls,
+		EstimatedCost:        cost,
+	}
+}
+
+// NewAgentAnalyticsFromGemini normalizes analytics read from a Gemini session file
+func NewAgentAnalyticsFromGemini(a *GeminiSessionAnalytics) *AgentAnalytics {
+	if a == nil {
+		return nil
+	}
+	model := a.Model
+	if model == "" {
+		model = "default"
+	}
+	return &AgentAnalytics{
+		Tool:                 "gemini",
+		Model:                a.Model,
+		InputTokens:          a.InputTokens,
+		OutputTokens:         a.OutputTokens,
+		CurrentContextTokens: a.CurrentContextTokens,
+		TotalTurns:           a.TotalTurns,
+		Duration:             a.Duration,
+		StartTime:            a.StartTime,
+		LastActive:           a.LastActive,
+		EstimatedCost:        a.CalculateCost(model),
+	}
+}
+
+// ToolHasAnalytics reports whether analytics can be parsed for a tool
+func ToolHasAnalytics(tool string) bool {
+	if _, ok := defaultContextWindows[tool]; ok {
+		return true
+	}
+	switch adapter := GetToolAdapter(tool).(type) {
+	case nil:
+		return false
+	case *declarativeAdapter:
+		// Custom tools only when [tools.<name>.adapter] locates a transcript
+		return adapter.hasTranscript()
+	default:
+		return true
+	}
+}
+
+// toolCallsFromCounts converts per-tool counts to a slice sorted by name
+func toolCallsFromCounts(counts map[string]int) []ToolCall {
+	calls := make([]ToolCall, 0, len(counts))
+	for name, count := range counts {
+		calls = append(calls, ToolCall{Name: name, Count: count})
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].Name < calls[j].Name })
+	return calls
+}
+
+// LoadAnalytics parses the on-disk transcript for this session through the
+// tool's adapter and returns normalized analytics. Returns nil (no error) when
+// the tool has no analytics or the session has not been detected yet.
+func (i *Instance) LoadAnalytics() (*AgentAnalytics, error) {
+	adapter := GetToolAdapter(i.GetToolThreadSafe())
+	if adapter == nil {
+		return nil, nil
+	}
+	return adapter.Analytics(i)
+}
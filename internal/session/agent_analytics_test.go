@@ -0,0 +1,108 @@
+package session
+
+import (
+	"testing"
+)
+
+func TestNewAgentAnalyticsFromClaude(t *testing.T) {
+	if NewAgentAnalyticsFromClaude(nil) != nil {
+		t.Error("nil analytics should normalize to nil")
+	}
+
+	a := NewAgentAnalyticsFromClaude(&SessionAnalytics{
+		InputTokens:          1_000_000,
+		CacheReadTokens:      10,
+		CurrentContextTokens: 100000,
+		TotalTurns:           3,
+		ToolCalls:            []ToolCall{{Name: "Read", Count: 2}},
+	})
+	if a.Tool != "claude" || a.TotalTurns != 3 || len(a.ToolCalls) != 1 {
+		t.Errorf("unexpected normalized analytics %+v", a)
+	}
+	// Cost falls back to default Sonnet pricing when not preset
+	if a.EstimatedCost < 3.0 {
+		t.Errorf("EstimatedCost = %f, want default pricing", a.EstimatedCost)
+	}
+	if got := a.ContextPercent(); got != 50 {
+		t.Errorf("ContextPercent = %f, want 50 (200k window)", got)
+	}
+}
+
+func TestNewAgentAnalyticsFromGemini(t *testing.T) {
+	a := NewAgentAnalyticsFromGemini(&GeminiSessionAnalytics{
+		InputTokens:          1_000_000,
+		OutputTokens:         1_000_000,
+		CurrentContextTokens: 250000,
+		Model:                "gemini-2.5-pro",
+	})
+	if a.Tool != "gemini" || a.Model != "gemini-2.5-pro" {
+		t.Errorf("Tool/Model = %q/%q", a.Tool, a.Model)
+	}
+	if a.EstimatedCost != 11.25 {
+		t.Errorf("EstimatedCost = %f, want gemini-2.5-pro pricing 11.25", a.EstimatedCost)
+	}
+	if got := a.ContextPercent(); got != 25 {
+		t.Errorf("ContextPercent = %f, want 25 (1M window)", got)
+	}
+}
+
+func TestAgentAnalytics_ContextLimit(t *testing.T) {
+	a := &AgentAnalytics{Tool: "codex"}
+	if a.ContextLimit() != 272000 {
+		t.Errorf("codex default = %d", a.ContextLimit())
+	}
+	a.ContextWindow = 400000
+	if a.ContextLimit() != 400000 {
+		t.Errorf("reported window should win, got %d", a.ContextLimit())
+	}
+	if (&AgentAnalytics{Tool: "aider"}).ContextLimit() != 200000 {
+		t.Error("unknown tools should fall back to 200k")
+	}
+}
+
+func TestToolHasAnalytics(t *testing.T) {
+	for _, tool := range []string{"claude", "gemini", "codex", "opencode"} {
+		if !ToolHasAnalytics(tool) {
+			t.Errorf("ToolHasAnalytics(%q) = false", tool)
+		}
+	}
+	if ToolHasAnalytics("shell") {
+		t.Error("shell sessions have no analytics")
+	}
+}
+
+func TestContextLimitFor_ModelLimits(t *testing.T) {
+	userConfigCacheMu.Lock()
+	userConfigCache = &UserConfig{Context: ContextSettings{
+		ModelLimits:  map[string]int{"claude-sonnet-4": 500000, "claude-sonnet-4-5": 1000000},
+		DefaultLimit: 64000,
+	}}
+	userConfigCacheMu.Unlock()
+	t.Cleanup(func() {
+		userConfigCacheMu.Lock()
+		userConfigCache = nil
+		userConfigCacheMu.Unlock()
+	})
+
+	tests := []struct {
+		tool, model string
+		reported    int
+		want        int
+	}{
+		{"claude", "claude-sonnet-4-5-20250929", 0, 1000000}, // longest prefix
+		{"claude", "claude-sonnet-4-20250514", 0, 500000},
+		{"codex", "gpt-5-codex", 400000, 400000}, // no model entry: reported window
+		{"claude", "claude-opus-4-1", 0, 200000}, // tool default
+		{"aider", "", 0, 64000},                  // configured fallback
+	}
+	for _, tt := range tests {
+		if got := ContextLimitFor(tt.tool, tt.model, tt.reported); got != tt.want {
+			t.Errorf("ContextLimitFor(%q, %q, %d) = %d, want %d", tt.tool, tt.model, tt.reported, got, tt.want)
+		}
+	}
+
+	a := NewAgentAnalyticsFromClaude(&SessionAnalytics{Model: "claude-sonnet-4-5", CurrentContextTokens: 250000})
+	if got := a.ContextPercent(); got != 25 {
+		t.Errorf("ContextPercent = %f, want 25 with a 1M model limit", got)
+	}
+}
@@ -17,6 +17,9 @@ type SessionAnalytics struct {
 	CacheReadTokens  int `json:"cache_read_input_tokens"`
 	CacheWriteTokens int `json:"cache_creation_input_tokens"`
 
+	// Model of the latest assistant message
+	Model string `json:"model,omitempty"`
+
 	// Current context size (last turn's input + cache read tokens)
 	// This represents the actual context window usage, not cumulative totals
 	CurrentContextTokens int `json:"current_context_tokens"`
@@ -68,10 +71,11 @@ func (a *SessionAnalytics) TotalTokens() int {
 
 // ContextPercent returns the percentage of context window used
 // Uses CurrentContextTokens (last turn's input + cache) for accurate context usage
-// modelLimit is the model's context window size (defaults to 200000 for Claude)
+// modelLimit is the model's context window size (0 resolves it from the
+// model and [context] settings, 200000 by default for Claude)
 func (a *SessionAnalytics) ContextPercent(modelLimit int) float64 {
 	if modelLimit == 0 {
-		modelLimit = 200000 // Default Claude limit
+		modelLimit = ContextLimitFor("claude", a.Model, 0)
 	}
 	return float64(a.CurrentContextTokens) / float64(modelLimit) * 100
 }
@@ -118,6 +122,7 @@ type jsonlEntry struct {
 	Type      string    `json:"type"`
 	Timestamp time.Time `json:"timestamp"`
 	Message   struct {
+		Model string `json:"model"`
 		Usage struct {
 			InputTokens              int `json:"input_tokens"`
 			OutputTokens             int `json:"output_tokens"`
@@ -183,6 +188,10 @@ func ParseSessionJSONL(path string) (*SessionAnalytics, error) {
 		analytics.CurrentContextTokens = entry.Message.Usage.InputTokens +
 			entry.Message.Usage.CacheReadInputTokens
 
+		if entry.Message.Model != "" && entry.Message.Model != "<synthetic>" {
+			analytics.Model = entry.Message.Model
+		}
+
 		// Count turn
 		analytics.TotalTurns++
 
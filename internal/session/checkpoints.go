@@ -0,0 +1,157 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+const checkpointSettingsFileName = "checkpoints.json"
+
+// CheckpointSettings records which sessions and groups have auto-checkpoints
+// enabled. A session entry overrides its group, so a single session can opt
+// out of a group that has checkpoints turned on.
+type CheckpointSettings struct {
+	Sessions map[string]bool `json:"sessions,omitempty"`
+	Groups   map[string]bool `json:"groups,omitempty"`
+}
+
+// Enabled reports whether auto-checkpoints apply to inst. Group settings are
+// inherited by subgroups; the most specific group wins.
+func (s *CheckpointSettings) Enabled(inst *Instance) bool {
+	if enabled, ok := s.Sessions[inst.ID]; ok {
+		return enabled
+	}
+	best := -1
+	enabled := false
+	for path, on := range s.Groups {
+		if (inst.GroupPath == path || strings.HasPrefix(inst.GroupPath, path+"/")) && len(path) > best {
+			best = len(path)
+			enabled = on
+		}
+	}
+	return enabled
+}
+
+// checkpointSettingsMu serializes read-modify-write cycles on the settings
+// file within this process.
+var checkpointSettingsMu sync.Mutex
+
+func checkpointSettingsPath(profile string) (string, error) {
+	profileDir, err := GetProfileDir(GetEffectiveProfile(profile))
+	if err != nil {
+		return "", fmt.Errorf("resolve profile dir: %w", err)
+	}
+	return filepath.Join(profileDir, checkpointSettingsFileName), nil
+}
+
+// LoadCheckpointSettings returns the auto-checkpoint settings for profile.
+func LoadCheckpointSettings(profile string) (*CheckpointSettings, error) {
+	path, err := checkpointSettingsPath(profile)
+	if err != nil {
+		return nil, err
+	}
+	settings := &CheckpointSettings{}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return settings, nil
+		}
+		return nil, fmt.Errorf("read checkpoint settings: %w", err)
+	}
+	if err := json.Unmarshal(raw, settings); err != nil {
+		return nil, fmt.Errorf("parse checkpoint settings: %w", err)
+	}
+	return settings, nil
+}
+
+// SetSessionCheckpoints turns auto-checkpoints on or off for one session.
+func SetSessionCheckpoints(profile, sessionID string, enabled bool) error {
+	return updateCheckpointSettings(profile, func(s *CheckpointSettings) {
+		if s.Sessions == nil {
+			s.Sessions = map[string]bool{}
+		}
+		s.Sessions[sessionID] = enabled
+	})
+}
+
+// SetGroupCheckpoints turns auto-checkpoints on or off for a group and its
+// subgroups.
+func SetGroupCheckpoints(profile, groupPath string, enabled bool) error {
+	return updateCheckpointSettings(profile, func(s *CheckpointSettings) {
+		if s.Groups == nil {
+			s.Groups = map[string]bool{}
+		}
+		s.Groups[groupPath] = enabled
+	})
+}
+
+func updateCheckpointSettings(profile string, mutate func(*CheckpointSettings)) error {
+	checkpointSettingsMu.Lock()
+	defer checkpointSettingsMu.Unlock()
+
+	settings, err := LoadCheckpointSettings(profile)
+	if err != nil {
+		return err
+	}
+	mutate(settings)
+
+	path, err := checkpointSettingsPath(profile)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("mkdir checkpoint settings dir: %w", err)
+	}
+	raw, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint settings: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("write temp checkpoint settings: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename checkpoint settings: %w", err)
+	}
+	return nil
+}
+
+// CheckpointDir returns the working tree checkpoints are taken from: the
+// session's worktree, or its project path for non-worktree sessions.
+func CheckpointDir(inst *Instance) string {
+	if inst.IsWorktree() {
+		return inst.WorktreePath
+	}
+	return inst.ProjectPath
+}
+
+// AutoCheckpoint snapshots inst's working tree when auto-checkpoints are
+// enabled for it and the transition ends an agent turn. It returns the new
+// checkpoint, or nil when nothing was recorded. Repeated calls for the same
+// tree state (e.g. from both the TUI and the notification daemon) are no-ops.
+func AutoCheckpoint(profile string, inst *Instance, from, to Status) (*git.Checkpoint, error) {
+	if inst == nil || !IsTurnEnd(from, to) {
+		return nil, nil
+	}
+	settings, err := LoadCheckpointSettings(profile)
+	if err != nil || !settings.Enabled(inst) {
+		return nil, err
+	}
+	dir := CheckpointDir(inst)
+	if dir == "" || !git.IsGitRepo(dir) {
+		return nil, nil
+	}
+	cp, created, err := git.CreateCheckpoint(dir, inst.ID, fmt.Sprintf("%s: %s -> %s", inst.Title, from, to))
+	if err != nil || !created {
+		return nil, err
+	}
+	return cp, nil
+}
@@ -0,0 +1,27 @@
+package session
+
+import "testing"
+
+func TestCheckpointSettingsEnabled(t *testing.T) {
+	settings := &CheckpointSettings{
+		Sessions: map[string]bool{"opt-out": false, "opt-in": true},
+		Groups:   map[string]bool{"work": true, "work/scratch": false},
+	}
+	cases := []struct {
+		id, group string
+		want      bool
+	}{
+		{"a", "work", true},
+		{"b", "work/api", true},
+		{"c", "work/scratch", false},
+		{"d", "workshop", false},
+		{"opt-out", "work", false},
+		{"opt-in", "personal", true},
+	}
+	for _, tc := range cases {
+		inst := &Instance{ID: tc.id, GroupPath: tc.group}
+		if got := settings.Enabled(inst); got != tc.want {
+			t.Errorf("Enabled(%s in %q) = %v, want %v", tc.id, tc.group, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// codexPricing contains pricing per million tokens for OpenAI models used by Codex
+// (CacheRead is the cached-input rate; Codex reports no cache writes)
+var codexPricing = map[string]ModelPricing{
+	"gpt-5":       {Input: 1.25, Output: 10.0, CacheRead: 0.125},
+	"gpt-5-codex": {Input: 1.25, Output: 10.0, CacheRead: 0.125},
+	"gpt-5-mini":  {Input: 0.25, Output: 2.0, CacheRead: 0.025},
+	"o3":          {Input: 2.0, Output: 8.0, CacheRead: 0.50},
+	"o4-mini":     {Input: 1.10, Output: 4.40, CacheRead: 0.275},
+	"gpt-4.1":     {Input: 2.0, Output: 8.0, CacheRead: 0.50},
+	"codex-mini":  {Input: 1.50, Output: 6.0, CacheRead: 0.375},
+	"default":     {Input: 1.25, Output: 10.0, CacheRead: 0.125},
+}
+
+// codexTokenUsage mirrors the usage objects in Codex token_count events
+type codexTokenUsage struct {
+	InputTokens       int `json:"input_tokens"`
+	CachedInputTokens int `json:"cached_input_tokens"`
+	OutputTokens      int `json:"output_tokens"`
+	TotalTokens       int `json:"total_tokens"`
+}
+
+// codexJSONLEntry represents a single line in a Codex rollout JSONL file
+type codexJSONLEntry struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Payload   struct {
+		Type  string `json:"type"`
+		Name  string `json:"name"`
+		Model string `json:"model"`
+		Info  *struct {
+			TotalTokenUsage    codexTokenUsage `json:"total_token_usage"`
+			LastTokenUsage     codexTokenUsage `json:"last_token_usage"`
+			ModelContextWindow int             `json:"model_context_window"`
+		} `json:"info"`
+	} `json:"payload"`
+}
+
+// FindCodexSessionFile returns the rollout JSONL path for a Codex session ID
+// under $CODEX_HOME/sessions/YYYY/MM/DD, or "" if not found
+func FindCodexSessionFile(sessionID string) string {
+	if sessionID == "" {
+		return ""
+	}
+	sessionsDir := filepath.Join(getCodexHomeDir(), "sessions")
+
+	var found string
+	var foundTime time.Time
+	_ = filepath.WalkDir(sessionsDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if !strings.HasSuffix(name, ".jsonl") || !strings.Contains(name, sessionID) {
+			return nil
+		}
+		// A resumed session can be split across files; use the newest
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if found == "" || info.ModTime().After(foundTime) {
+			found = path
+			foundTime = info.ModTime()
+		}
+		return nil
+	})
+	return found
+}
+
+// ParseCodexSessionJSONL parses a Codex rollout JSONL file and returns analytics.
+// Token totals come from the latest token_count event (Codex reports them
+// cumulatively), turns from user_message events and tool calls from
+// function_call / custom_tool_call response items.
+func ParseCodexSessionJSONL(path string) (*AgentAnalytics, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	analytics := &AgentAnalytics{Tool: "codex"}
+	toolCounts := make(map[string]int)
+	var firstTime, lastTime time.Time
+	var totalUsage codexTokenUsage
+
+	scanner := bufio.NewScanner(file)
+	// Increase buffer for large lines (tool outputs are inlined)
+	buf := make([]byte, 0, 1024*1024)
+	scanner.Buffer(buf, 10*1024*1024)
+
+	for scanner.Scan() {
+		var entry codexJSONLEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue // Skip malformed lines
+		}
+
+		if !entry.Timestamp.IsZero() {
+			if firstTime.IsZero() || entry.Timestamp.Before(firstTime) {
+				firstTime = entry.Timestamp
+			}
+			if entry.Timestamp.After(lastTime) {
+				lastTime = entry.Timestamp
+			}
+		}
+
+		switch entry.Type {
+		case "turn_context":
+			if entry.Payload.Model != "" {
+				analytics.Model = entry.Payload.Model
+			}
+		case "event_msg":
+			switch entry.Payload.Type {
+			case "user_message":
+				analytics.TotalTurns++
+			case "token_count":
+				if info := entry.Payload.Info; info != nil {
+					totalUsage = info.TotalTokenUsage
+					analytics.CurrentContextTokens = info.LastTokenUsage.TotalTokens
+					if info.ModelContextWindow > 0 {
+						analytics.ContextWindow = info.ModelContextWindow
+					}
+				}
+			}
+		case "response_item":
+			if (entry.Payload.Type == "function_call" || entry.Payload.Type == "custom_tool_call") && entry.Payload.Name != "" {
+				toolCounts[entry.Payload.Name]++
+			}
+		}
+	}
+
+	// OpenAI input totals include cached input; split them out
+	analytics.CacheReadTokens = totalUsage.CachedInputTokens
+	analytics.InputTokens = totalUsage.InputTokens - totalUsage.CachedInputTokens
+	if analytics.InputTokens < 0 {
+		analytics.InputTokens = 0
+	}
+	analytics.OutputTokens = totalUsage.OutputTokens
+	analytics.ToolCalls = toolCallsFromCounts(toolCounts)
+
+	analytics.StartTime = firstTime
+	analytics.LastActive = lastTime
+	if !firstTime.IsZero() && !lastTime.IsZero() {
+		analytics.Duration = lastTime.Sub(firstTime)
+	}
+
+	analytics.EstimatedCost = calculateCodexCost(analytics)
+
+	return analytics, scanner.Err()
+}
+
+// calculateCodexCost estimates cost from codexPricing, matching the model by
+// longest known prefix (e.g. "gpt-5-codex-high" -> "gpt-5-codex")
+func calculateCodexCost(a *AgentAnalytics) float64 {
+	pricing := codexPricing["default"]
+	bestLen := 0
+	for name, p := range codexPricing {
+		if name != "default" && strings.HasPrefix(a.Model, name) && len(name) > bestLen {
+			pricing = p
+			bestLen = len(name)
+		}
+	}
+
+	return float64(a.InputTokens)/1_000_000*pricing.Input +
+		float64(a.OutputTokens)/1_000_000*pricing.Output +
+		float64(a.CacheReadTokens)/1_000_000*pricing.CacheRead
+}
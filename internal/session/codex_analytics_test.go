@@ -0,0 +1,106 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const codexRolloutFixture = `{"timestamp":"2025-10-01T10:00:00.000Z","type":"session_meta","payload":{"id":"0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b","cwd":"/tmp/project"}}
+{"timestamp":"2025-10-01T10:00:01.000Z","type":"turn_context","payload":{"cwd":"/tmp/project","approval_policy":"on-request","model":"gpt-5-codex"}}
+{"timestamp":"2025-10-01T10:00:01.500Z","type":"event_msg","payload":{"type":"user_message","message":"fix the tests","kind":"plain"}}
+{"timestamp":"2025-10-01T10:00:05.000Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{}","call_id":"call_1"}}
+{"timestamp":"2025-10-01T10:00:09.000Z","type":"response_item","payload":{"type":"custom_tool_call","name":"apply_patch","input":"","call_id":"call_2"}}
+{"timestamp":"2025-10-01T10:00:10.000Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":12000,"cached_input_tokens":8000,"output_tokens":900,"total_tokens":12900},"last_token_usage":{"input_tokens":12000,"cached_input_tokens":8000,"output_tokens":900,"total_tokens":12900},"model_context_window":272000}}}
+not json
+{"timestamp":"2025-10-01T10:01:00.000Z","type":"event_msg","payload":{"type":"user_message","message":"now commit","kind":"plain"}}
+{"timestamp":"2025-10-01T10:01:02.000Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{}","call_id":"call_3"}}
+{"timestamp":"2025-10-01T10:01:30.000Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":30000,"cached_input_tokens":20000,"output_tokens":1500,"total_tokens":31500},"last_token_usage":{"input_tokens":18000,"cached_input_tokens":12000,"output_tokens":600,"total_tokens":18600},"model_context_window":272000}}}
+{"timestamp":"2025-10-01T10:01:31.000Z","type":"event_msg","payload":{"type":"token_count","info":null}}
+`
+
+func writeCodexRollout(t *testing.T, codexHome, sessionID, content string) string {
+	t.Helper()
+	dir := filepath.Join(codexHome, "sessions", "2025", "10", "01")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "rollout-2025-10-01T10-00-00-"+sessionID+".jsonl")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseCodexSessionJSONL(t *testing.T) {
+	path := writeCodexRollout(t, t.TempDir(), "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b", codexRolloutFixture)
+
+	a, err := ParseCodexSessionJSONL(path)
+	if err != nil {
+		t.Fatalf("ParseCodexSessionJSONL: %v", err)
+	}
+
+	if a.Tool != "codex" || a.Model != "gpt-5-codex" {
+		t.Errorf("Tool/Model = %q/%q, want codex/gpt-5-codex", a.Tool, a.Model)
+	}
+	// Latest cumulative totals, cached input split out of input
+	if a.InputTokens != 10000 || a.CacheReadTokens != 20000 || a.OutputTokens != 1500 {
+		t.Errorf("tokens = in %d cache %d out %d, want 10000/20000/1500", a.InputTokens, a.CacheReadTokens, a.OutputTokens)
+	}
+	if a.CurrentContextTokens != 18600 || a.ContextWindow != 272000 {
+		t.Errorf("context = %d/%d, want 18600/272000", a.CurrentContextTokens, a.ContextWindow)
+	}
+	if a.TotalTurns != 2 {
+		t.Errorf("TotalTurns = %d, want 2", a.TotalTurns)
+	}
+	want := []ToolCall{{Name: "apply_patch", Count: 1}, {Name: "shell", Count: 2}}
+	if len(a.ToolCalls) != len(want) || a.ToolCalls[0] != want[0] || a.ToolCalls[1] != want[1] {
+		t.Errorf("ToolCalls = %+v, want %+v", a.ToolCalls, want)
+	}
+	if a.Duration != 91*time.Second {
+		t.Errorf("Duration = %v, want 1m31s", a.Duration)
+	}
+	// 10k*1.25 + 1.5k*10 + 20k*0.125 per million
+	if a.EstimatedCost < 0.02999 || a.EstimatedCost > 0.03001 {
+		t.Errorf("EstimatedCost = %f, want 0.03", a.EstimatedCost)
+	}
+}
+
+func TestFindCodexSessionFile(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+	sessionID := "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b"
+	path := writeCodexRollout(t, codexHome, sessionID, codexRolloutFixture)
+
+	if got := FindCodexSessionFile(sessionID); got != path {
+		t.Errorf("FindCodexSessionFile = %q, want %q", got, path)
+	}
+	if got := FindCodexSessionFile("0199ffff-0000-0000-0000-000000000000"); got != "" {
+		t.Errorf("unknown session should not match, got %q", got)
+	}
+	if got := FindCodexSessionFile(""); got != "" {
+		t.Errorf("empty session ID should not match, got %q", got)
+	}
+}
+
+func TestInstanceLoadAnalytics_Codex(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+
+	inst := NewInstanceWithTool("codex-test", "/tmp/project", "codex")
+	if a, err := inst.LoadAnalytics(); a != nil || err != nil {
+		t.Fatalf("no session ID should give no analytics, got %+v, %v", a, err)
+	}
+
+	inst.CodexSessionID = "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b"
+	writeCodexRollout(t, codexHome, inst.CodexSessionID, codexRolloutFixture)
+	a, err := inst.LoadAnalytics()
+	if err != nil || a == nil {
+		t.Fatalf("LoadAnalytics = %+v, %v", a, err)
+	}
+	if a.TotalTurns != 2 || !strings.HasPrefix(a.Model, "gpt-5") {
+		t.Errorf("unexpected analytics %+v", a)
+	}
+}
@@ -0,0 +1,96 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ForkCodexSession clones a Codex rollout JSONL under a new session ID and
+// returns the ID. The session_meta record gets the new ID and, when the fork
+// runs elsewhere (a worktree), the new cwd; every other record is copied
+// verbatim so `codex resume <id>` replays the full conversation.
+func ForkCodexSession(sessionID, targetPath string) (string, error) {
+	src := FindCodexSessionFile(sessionID)
+	if src == "" {
+		return "", fmt.Errorf("rollout not found for Codex session %s", sessionID)
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to read rollout: %w", err)
+	}
+
+	newID, err := newConversationUUID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	rewritten := false
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !rewritten {
+			if meta, ok := rewriteCodexSessionMeta(line, newID, targetPath); ok {
+				line = meta
+				rewritten = true
+			}
+		}
+		out.Write(line)
+		out.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read rollout: %w", err)
+	}
+	if !rewritten {
+		return "", fmt.Errorf("rollout for Codex session %s has no session_meta record", sessionID)
+	}
+
+	// Same layout Codex uses: sessions/YYYY/MM/DD/rollout-<timestamp>-<id>.jsonl
+	now := time.Now()
+	dir := filepath.Join(getCodexHomeDir(), "sessions", now.Format("2006"), now.Format("01"), now.Format("02"))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create sessions dir: %w", err)
+	}
+	name := fmt.Sprintf("rollout-%s-%s.jsonl", now.Format("2006-01-02T15-04-05"), newID)
+	if err := os.WriteFile(filepath.Join(dir, name), out.Bytes(), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write forked rollout: %w", err)
+	}
+	return newID, nil
+}
+
+// rewriteCodexSessionMeta returns line with the session ID (and cwd, if
+// targetPath is set) replaced when it is a session_meta record
+func rewriteCodexSessionMeta(line []byte, newID, targetPath string) ([]byte, bool) {
+	var record map[string]json.RawMessage
+	if err := json.Unmarshal(line, &record); err != nil {
+		return nil, false
+	}
+	var recordType string
+	if err := json.Unmarshal(record["type"], &recordType); err != nil || recordType != "session_meta" {
+		return nil, false
+	}
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(record["payload"], &payload); err != nil {
+		return nil, false
+	}
+
+	payload["id"], _ = json.Marshal(newID)
+	if targetPath != "" {
+		payload["cwd"], _ = json.Marshal(targetPath)
+	}
+	var err error
+	if record["payload"], err = json.Marshal(payload); err != nil {
+		return nil, false
+	}
+	out, err := json.Marshal(record)
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
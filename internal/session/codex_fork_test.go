@@ -0,0 +1,88 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestForkCodexSession(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+	parentID := "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b"
+	writeCodexRollout(t, codexHome, parentID, codexRolloutFixture)
+
+	newID, err := ForkCodexSession(parentID, "/tmp/worktree")
+	if err != nil {
+		t.Fatalf("ForkCodexSession: %v", err)
+	}
+	if newID == parentID || len(newID) != 36 {
+		t.Fatalf("new ID = %q", newID)
+	}
+
+	path := FindCodexSessionFile(newID)
+	if path == "" {
+		t.Fatal("forked rollout not found")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if !strings.Contains(lines[0], `"id":"`+newID+`"`) || !strings.Contains(lines[0], `"cwd":"/tmp/worktree"`) {
+		t.Errorf("session_meta not rewritten: %s", lines[0])
+	}
+	if strings.Contains(string(data), parentID) {
+		t.Error("forked rollout still references the parent ID")
+	}
+
+	// The conversation itself is copied verbatim
+	a, err := ParseCodexSessionJSONL(path)
+	if err != nil || a.TotalTurns != 2 {
+		t.Errorf("forked analytics = %+v, %v", a, err)
+	}
+
+	if _, err := ForkCodexSession("0199ffff-0000-0000-0000-000000000000", ""); err == nil {
+		t.Error("expected error for an unknown session")
+	}
+}
+
+func TestCreateForkedCodexInstance(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+	t.Setenv("HOME", t.TempDir())
+	parentID := "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b"
+	writeCodexRollout(t, codexHome, parentID, codexRolloutFixture)
+
+	parent := NewInstanceWithTool("codex", "/tmp/project", "codex")
+	if err := parent.SetCodexOptions(&CodexOptions{Model: "gpt-5-codex"}); err != nil {
+		t.Fatal(err)
+	}
+	if parent.CanFork() {
+		t.Error("CanFork should need a session ID")
+	}
+	parent.CodexSessionID = parentID
+
+	worktree := filepath.Join(t.TempDir(), "wt")
+	forked, err := parent.ForkWithAdapter("codex (fork)", "exp", &ForkOptions{
+		WorkDir:        worktree,
+		WorktreePath:   worktree,
+		WorktreeBranch: "fork/x",
+	})
+	if err != nil {
+		t.Fatalf("ForkWithAdapter: %v", err)
+	}
+	if forked.Tool != "codex" || forked.GroupPath != "exp" || forked.ProjectPath != worktree || forked.WorktreeBranch != "fork/x" {
+		t.Errorf("forked = %+v", forked)
+	}
+	if forked.CodexSessionID == "" || forked.CodexSessionID == parentID {
+		t.Errorf("forked session ID = %q", forked.CodexSessionID)
+	}
+	if opts := forked.GetCodexOptions(); opts == nil || opts.Model != "gpt-5-codex" {
+		t.Errorf("forked Codex options = %+v, want the parent's", opts)
+	}
+	if cmd := forked.buildCodexCommand("codex"); !strings.Contains(cmd, "resume "+forked.CodexSessionID) {
+		t.Errorf("forked command should resume the copy: %s", cmd)
+	}
+}
@@ -0,0 +1,386 @@
+package session
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Codex reads MCPs from [mcp_servers.<name>] tables in $CODEX_HOME/config.toml
+// (~/.codex/config.toml by default). It has no project-level MCP config, so
+// LOCAL scope is a per-session CODEX_HOME: a directory holding a copy of the
+// global config.toml with the session's MCPs added, and symlinks to
+// everything else in the real Codex home (auth, sessions, history) so login
+// and session detection keep working.
+
+// codexSessionHomesDir is where per-session CODEX_HOME directories live
+const codexSessionHomesDir = "codex-homes"
+
+// codexMCPHeader matches a [mcp_servers.<name>] table header, including
+// sub-tables like [mcp_servers.<name>.env]
+var codexMCPHeader = regexp.MustCompile(`^\s*\[\s*mcp_servers\s*\.\s*("(?:[^"\\]|\\.)*"|'[^']*'|[A-Za-z0-9_-]+)\s*(?:\.[^\]]*)?\]\s*(?:#.*)?$`)
+
+// GetCodexConfigFile returns the path to Codex's global config.toml
+func GetCodexConfigFile() string {
+	return filepath.Join(getCodexHomeDir(), "config.toml")
+}
+
+// GetCodexSessionHome returns the per-session CODEX_HOME used for LOCAL MCPs.
+// The directory only exists while the session has LOCAL MCPs attached.
+func GetCodexSessionHome(instanceID string) string {
+	dir, err := GetAgentDeckDir()
+	if err != nil || instanceID == "" {
+		return ""
+	}
+	return filepath.Join(dir, codexSessionHomesDir, instanceID)
+}
+
+// readCodexMCPNames returns the MCP names defined in a Codex config.toml
+func readCodexMCPNames(configFile string) []string {
+	var config struct {
+		MCPServers map[string]toml.Primitive `toml:"mcp_servers"`
+	}
+	if _, err := toml.DecodeFile(configFile, &config); err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(config.MCPServers))
+	for name := range config.MCPServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetCodexMCPInfo returns a Codex session's MCPs: Global from the global
+// config.toml, Local from the session's own CODEX_HOME.
+func GetCodexMCPInfo(instanceID, projectPath string) *MCPInfo {
+	info := &MCPInfo{Global: readCodexMCPNames(GetCodexConfigFile())}
+	for _, name := range GetCodexSessionMCPNames(instanceID) {
+		info.LocalMCPs = append(info.LocalMCPs, LocalMCP{Name: name, SourcePath: projectPath})
+	}
+	return info
+}
+
+// GetCodexSessionMCPNames returns the LOCAL MCPs of a Codex session: those
+// in its CODEX_HOME config that aren't configured globally.
+func GetCodexSessionMCPNames(instanceID string) []string {
+	home := GetCodexSessionHome(instanceID)
+	if home == "" {
+		return nil
+	}
+	global := make(map[string]bool)
+	for _, name := range readCodexMCPNames(GetCodexConfigFile()) {
+		global[name] = true
+	}
+	var names []string
+	for _, name := range readCodexMCPNames(filepath.Join(home, "config.toml")) {
+		if !global[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// WriteCodexGlobalMCP sets the agent-deck managed MCPs in Codex's global
+// config.toml. MCPs not defined in agent-deck's config.toml, and everything
+// else in the file (comments included), are preserved.
+func WriteCodexGlobalMCP(enabledNames []string) error {
+	configFile := GetCodexConfigFile()
+	existing, err := os.ReadFile(configFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read Codex config: %w", err)
+	}
+
+	availableMCPs := GetAvailableMCPs()
+	content, err := rewriteCodexMCPTables(string(existing), func(name string) bool {
+		_, managed := availableMCPs[name]
+		return managed
+	}, buildCodexMCPServers(enabledNames, "global"))
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", configFile, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configFile), 0700); err != nil {
+		return fmt.Errorf("failed to create Codex config dir: %w", err)
+	}
+	return writeFileAtomic(configFile, []byte(content), 0600)
+}
+
+// WriteCodexSessionMCP sets the LOCAL MCPs of a Codex session. With no MCPs
+// the session's CODEX_HOME is removed and it goes back to the global config.
+func WriteCodexSessionMCP(instanceID string, enabledNames []string) error {
+	home := GetCodexSessionHome(instanceID)
+	if home == "" {
+		return fmt.Errorf("cannot resolve CODEX_HOME for session %q", instanceID)
+	}
+	if len(enabledNames) == 0 {
+		return RemoveCodexSessionHome(instanceID)
+	}
+
+	if err := os.MkdirAll(home, 0700); err != nil {
+		return fmt.Errorf("failed to create session CODEX_HOME: %w", err)
+	}
+	if err := linkCodexHome(getCodexHomeDir(), home); err != nil {
+		return err
+	}
+
+	global, err := os.ReadFile(GetCodexConfigFile())
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read Codex config: %w", err)
+	}
+	local := make(map[string]bool, len(enabledNames))
+	for _, name := range enabledNames {
+		local[name] = true
+	}
+	content, err := rewriteCodexMCPTables(string(global), func(name string) bool {
+		return local[name]
+	}, buildCodexMCPServers(enabledNames, "local"))
+	if err != nil {
+		return fmt.Errorf("failed to build session Codex config from %s: %w", GetCodexConfigFile(), err)
+	}
+
+	return writeFileAtomic(filepath.Join(home, "config.toml"), []byte(content), 0600)
+}
+
+// RemoveCodexSessionHome deletes a session's CODEX_HOME, which holds a copy
+// of the Codex config including MCP credentials. Links into the real Codex
+// home are removed, not followed.
+func RemoveCodexSessionHome(instanceID string) error {
+	home := GetCodexSessionHome(instanceID)
+	if home == "" {
+		return fmt.Errorf("cannot resolve CODEX_HOME for session %q", instanceID)
+	}
+	if err := os.RemoveAll(home); err != nil {
+		return fmt.Errorf("failed to remove session CODEX_HOME: %w", err)
+	}
+	return nil
+}
+
+// RefreshCodexSessionHome regenerates a session's CODEX_HOME config so it
+// follows global config changes and current pool sockets. Returns the
+// directory, or "" when the session has no LOCAL MCPs.
+func RefreshCodexSessionHome(instanceID string) string {
+	home := GetCodexSessionHome(instanceID)
+	if home == "" {
+		return ""
+	}
+	if _, err := os.Stat(home); err != nil {
+		return ""
+	}
+	names := GetCodexSessionMCPNames(instanceID)
+	if err := WriteCodexSessionMCP(instanceID, names); err != nil {
+		mcpCatLog.Warn("codex_home_refresh_failed", slog.String("instance", instanceID), slog.String("error", err.Error()))
+		return home
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return home
+}
+
+// linkCodexHome symlinks everything in the real Codex home except
+// config.toml into a session home. auth.json and sessions/ are always
+// linked so a later login or the first session lands in the real home.
+func linkCodexHome(realHome, sessionHome string) error {
+	if err := os.MkdirAll(filepath.Join(realHome, "sessions"), 0700); err != nil {
+		return fmt.Errorf("failed to create Codex sessions dir: %w", err)
+	}
+	names := map[string]bool{"auth.json": true, "sessions": true}
+	if entries, err := os.ReadDir(realHome); err == nil {
+		for _, e := range entries {
+			names[e.Name()] = true
+		}
+	}
+	delete(names, "config.toml")
+
+	for name := range names {
+		link := filepath.Join(sessionHome, name)
+		if _, err := os.Lstat(link); err == nil {
+			continue
+		}
+		if err := os.Symlink(filepath.Join(realHome, name), link); err != nil {
+			return fmt.Errorf("failed to link %s into session CODEX_HOME: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// buildCodexMCPServers resolves transports for enabled MCPs from config.toml
+func buildCodexMCPServers(enabledNames []string, scope string) map[string]MCPServerConfig {
+	availableMCPs := GetAvailableMCPs()
+	pool := GetGlobalPool()
+	servers := make(map[string]MCPServerConfig)
+	for _, name := range enabledNames {
+		if def, ok := availableMCPs[name]; ok {
+			cfg := resolveMCPServer(pool, name, def, scope)
+			if cfg.Type == "sse" {
+				mcpCatLog.Warn("codex_sse_unsupported", slog.String("mcp", name),
+					slog.String("detail", "Codex only speaks streamable HTTP; writing the URL anyway"))
+			}
+			servers[name] = cfg
+		}
+	}
+	return servers
+}
+
+// rewriteCodexMCPTables drops the [mcp_servers.<name>] tables for which
+// remove returns true and appends tables for servers. Servers the user
+// defined another way (dotted keys or inline tables under [mcp_servers])
+// can't be dropped line by line; their definition is kept and no second
+// one is appended, which would make the file invalid TOML.
+func rewriteCodexMCPTables(content string, remove func(name string) bool, servers map[string]MCPServerConfig) (string, error) {
+	var kept []string
+	skipping := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			skipping = false
+			if m := codexMCPHeader.FindStringSubmatch(line); m != nil {
+				skipping = remove(unquoteTOMLKey(m[1]))
+			}
+		}
+		if !skipping {
+			kept = append(kept, line)
+		}
+	}
+	out := strings.TrimRight(strings.Join(kept, "\n"), "\n")
+
+	var remaining struct {
+		MCPServers map[string]toml.Primitive `toml:"mcp_servers"`
+	}
+	if _, err := toml.Decode(out, &remaining); err != nil {
+		return "", fmt.Errorf("invalid TOML: %w", err)
+	}
+
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		if _, defined := remaining.MCPServers[name]; defined {
+			mcpCatLog.Warn("codex_mcp_already_defined", slog.String("mcp", name),
+				slog.String("detail", "defined outside an [mcp_servers.<name>] table; keeping the existing definition"))
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(out)
+	for _, name := range names {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(formatCodexMCPTable(name, servers[name]))
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// formatCodexMCPTable renders one [mcp_servers.<name>] table
+func formatCodexMCPTable(name string, cfg MCPServerConfig) string {
+	lines := []string{"[mcp_servers." + tomlKey(name) + "]"}
+	if cfg.URL != "" {
+		lines = append(lines, "url = "+tomlString(cfg.URL))
+		if len(cfg.Headers) > 0 {
+			lines = append(lines, "http_headers = "+tomlInlineTable(cfg.Headers))
+		}
+		return strings.Join(lines, "\n")
+	}
+	lines = append(lines, "command = "+tomlString(cfg.Command))
+	args := make([]string, len(cfg.Args))
+	for i, arg := range cfg.Args {
+		args[i] = tomlString(arg)
+	}
+	lines = append(lines, "args = ["+strings.Join(args, ", ")+"]")
+	if len(cfg.Env) > 0 {
+		lines = append(lines, "env = "+tomlInlineTable(cfg.Env))
+	}
+	return strings.Join(lines, "\n")
+}
+
+var bareTOMLKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// tomlKey quotes a key unless it can be written bare
+func tomlKey(key string) string {
+	if bareTOMLKey.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+// unquoteTOMLKey reverses tomlKey for basic and literal strings
+func unquoteTOMLKey(key string) string {
+	switch {
+	case strings.HasPrefix(key, `"`):
+		if s, err := strconv.Unquote(key); err == nil {
+			return s
+		}
+		return strings.Trim(key, `"`)
+	case strings.HasPrefix(key, "'"):
+		return strings.Trim(key, "'")
+	}
+	return key
+}
+
+// tomlString renders s as a TOML basic string
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// tomlInlineTable renders a string map as a TOML inline table, keys sorted
+func tomlInlineTable(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = tomlKey(k) + " = " + tomlString(m[k])
+	}
+	return "{ " + strings.Join(parts, ", ") + " }"
+}
+
+// writeFileAtomic writes data through a temp file and rename
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
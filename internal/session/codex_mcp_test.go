@@ -0,0 +1,318 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+// withTestMCPs makes mcps the config.toml MCP pool for the test
+func withTestMCPs(t *testing.T, mcps map[string]MCPDef) {
+	t.Helper()
+	userConfigCacheMu.Lock()
+	userConfigCache = &UserConfig{MCPs: mcps}
+	userConfigCacheMu.Unlock()
+	t.Cleanup(func() {
+		userConfigCacheMu.Lock()
+		userConfigCache = nil
+		userConfigCacheMu.Unlock()
+	})
+}
+
+func codexTestMCPs() map[string]MCPDef {
+	return map[string]MCPDef{
+		"exa":    {Command: "npx", Args: []string{"-y", "exa-mcp"}, Env: map[string]string{"EXA_API_KEY": "k\"1"}},
+		"remote": {URL: "https://example.com/mcp", Headers: map[string]string{"Authorization": "Bearer t"}},
+		"fs":     {Command: "mcp-fs"},
+	}
+}
+
+func TestWriteCodexGlobalMCP(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+	withTestMCPs(t, codexTestMCPs())
+
+	configFile := filepath.Join(codexHome, "config.toml")
+	initial := `# my settings
+model = "o3"
+
+[mcp_servers.mine]
+command = "my-mcp"
+
+[mcp_servers.exa]
+command = "old"
+
+[mcp_servers.exa.env]
+OLD = "1"
+
+[profiles.fast]
+model = "o4-mini"
+`
+	if err := os.WriteFile(configFile, []byte(initial), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteCodexGlobalMCP([]string{"exa", "remote"}); err != nil {
+		t.Fatalf("WriteCodexGlobalMCP: %v", err)
+	}
+	data, _ := os.ReadFile(configFile)
+	content := string(data)
+
+	var config struct {
+		Model      string `toml:"model"`
+		MCPServers map[string]struct {
+			Command     string            `toml:"command"`
+			Args        []string          `toml:"args"`
+			Env         map[string]string `toml:"env"`
+			URL         string            `toml:"url"`
+			HTTPHeaders map[string]string `toml:"http_headers"`
+		} `toml:"mcp_servers"`
+		Profiles map[string]map[string]string `toml:"profiles"`
+	}
+	if _, err := toml.Decode(content, &config); err != nil {
+		t.Fatalf("written config is not valid TOML: %v\n%s", err, content)
+	}
+	if config.Model != "o3" || config.Profiles["fast"]["model"] != "o4-mini" {
+		t.Error("other settings should be preserved")
+	}
+	if !strings.Contains(content, "# my settings") {
+		t.Error("comments should be preserved")
+	}
+	if config.MCPServers["mine"].Command != "my-mcp" {
+		t.Error("MCPs not defined in config.toml should be preserved")
+	}
+	exa := config.MCPServers["exa"]
+	if exa.Command != "npx" || len(exa.Args) != 2 || exa.Env["EXA_API_KEY"] != "k\"1" || exa.Env["OLD"] != "" {
+		t.Errorf("exa = %+v, want the config.toml definition", exa)
+	}
+	remote := config.MCPServers["remote"]
+	if remote.URL != "https://example.com/mcp" || remote.HTTPHeaders["Authorization"] != "Bearer t" {
+		t.Errorf("remote = %+v, want url and http_headers", remote)
+	}
+
+	// Detaching removes the managed table only
+	if err := WriteCodexGlobalMCP(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := readCodexMCPNames(configFile); len(got) != 1 || got[0] != "mine" {
+		t.Errorf("names after detach = %v, want [mine]", got)
+	}
+}
+
+func TestWriteCodexGlobalMCPKeepsOtherDefinitionForms(t *testing.T) {
+	tests := map[string]string{
+		"dotted keys": `[mcp_servers]
+exa.command = "user-exa"
+exa.args = ["--flag"]
+`,
+		"dotted keys at top level": `mcp_servers.exa.command = "user-exa"
+`,
+		"inline table": `[mcp_servers]
+exa = { command = "user-exa", args = ["--flag"] }
+`,
+	}
+	for name, initial := range tests {
+		t.Run(name, func(t *testing.T) {
+			codexHome := t.TempDir()
+			t.Setenv("CODEX_HOME", codexHome)
+			withTestMCPs(t, codexTestMCPs())
+			configFile := filepath.Join(codexHome, "config.toml")
+			if err := os.WriteFile(configFile, []byte(initial), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := WriteCodexGlobalMCP([]string{"exa", "fs"}); err != nil {
+				t.Fatalf("WriteCodexGlobalMCP: %v", err)
+			}
+			data, _ := os.ReadFile(configFile)
+			var config struct {
+				MCPServers map[string]struct {
+					Command string `toml:"command"`
+				} `toml:"mcp_servers"`
+			}
+			if _, err := toml.Decode(string(data), &config); err != nil {
+				t.Fatalf("written config is not valid TOML: %v\n%s", err, data)
+			}
+			if config.MCPServers["exa"].Command != "user-exa" {
+				t.Errorf("exa = %+v, want the user's definition kept", config.MCPServers["exa"])
+			}
+			if config.MCPServers["fs"].Command != "mcp-fs" {
+				t.Errorf("fs = %+v, want it added", config.MCPServers["fs"])
+			}
+		})
+	}
+}
+
+func TestWriteCodexGlobalMCPRejectsInvalidConfig(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+	withTestMCPs(t, codexTestMCPs())
+	configFile := filepath.Join(codexHome, "config.toml")
+	initial := "model = \"o3\"\nmodel = \"o4\"\n"
+	if err := os.WriteFile(configFile, []byte(initial), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteCodexGlobalMCP([]string{"fs"}); err == nil {
+		t.Fatal("expected an error for an invalid config")
+	}
+	if data, _ := os.ReadFile(configFile); string(data) != initial {
+		t.Errorf("invalid config should be left untouched, got:\n%s", data)
+	}
+}
+
+func TestWriteCodexSessionMCP(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	codexHome := filepath.Join(home, ".codex")
+	t.Setenv("CODEX_HOME", codexHome)
+	withTestMCPs(t, codexTestMCPs())
+
+	if err := os.MkdirAll(codexHome, 0700); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(codexHome, "auth.json"), []byte(`{}`), 0600)
+	if err := WriteCodexGlobalMCP([]string{"remote"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteCodexSessionMCP("inst1", []string{"fs"}); err != nil {
+		t.Fatalf("WriteCodexSessionMCP: %v", err)
+	}
+	sessionHome := GetCodexSessionHome("inst1")
+	if target, err := os.Readlink(filepath.Join(sessionHome, "auth.json")); err != nil || target != filepath.Join(codexHome, "auth.json") {
+		t.Errorf("auth.json should link to the real Codex home, got %q (%v)", target, err)
+	}
+	if _, err := os.Readlink(filepath.Join(sessionHome, "sessions")); err != nil {
+		t.Error("sessions/ should be linked so session detection keeps working")
+	}
+
+	info := GetCodexMCPInfo("inst1", "/proj")
+	if len(info.Global) != 1 || info.Global[0] != "remote" {
+		t.Errorf("global = %v, want [remote]", info.Global)
+	}
+	if local := info.Local(); len(local) != 1 || local[0] != "fs" {
+		t.Errorf("local = %v, want [fs]", local)
+	}
+	if names := readCodexMCPNames(filepath.Join(sessionHome, "config.toml")); len(names) != 2 {
+		t.Errorf("session config should hold global and local MCPs, got %v", names)
+	}
+
+	if got := RefreshCodexSessionHome("inst1"); got != sessionHome {
+		t.Errorf("RefreshCodexSessionHome = %q, want %q", got, sessionHome)
+	}
+
+	// Removing the last local MCP drops the session CODEX_HOME
+	if err := WriteCodexSessionMCP("inst1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(sessionHome); !os.IsNotExist(err) {
+		t.Error("session CODEX_HOME should be removed without local MCPs")
+	}
+	if got := RefreshCodexSessionHome("inst1"); got != "" {
+		t.Errorf("RefreshCodexSessionHome without local MCPs = %q, want empty", got)
+	}
+}
+
+func TestBuildCodexCommandUsesSessionHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CODEX_HOME", filepath.Join(home, ".codex"))
+	withTestMCPs(t, codexTestMCPs())
+
+	inst := NewInstanceWithTool("codex-mcp", "/tmp", "codex")
+	if cmd := inst.buildCodexCommand("codex"); strings.Contains(cmd, "CODEX_HOME=") {
+		t.Errorf("without local MCPs the global CODEX_HOME is used: %s", cmd)
+	}
+	if err := inst.WriteLocalMCPs([]string{"fs"}); err != nil {
+		t.Fatal(err)
+	}
+	agentdeckEnv := fmt.Sprintf("AGENTDECK_INSTANCE_ID=%s AGENTDECK_TITLE=%q AGENTDECK_TOOL=codex ", inst.ID, inst.Title)
+	exportHome := fmt.Sprintf("export CODEX_HOME=%q; ", GetCodexSessionHome(inst.ID))
+	if cmd, want := inst.buildCodexCommand("codex"), exportHome+agentdeckEnv+"codex"; cmd != want {
+		t.Errorf("command should point CODEX_HOME at the session home:\n got: %s\nwant: %s", cmd, want)
+	}
+	// On resume CODEX_HOME must reach codex, not just the tmux command before the ';'
+	inst.CodexSessionID = "sess-1"
+	want := exportHome + agentdeckEnv + "tmux set-environment CODEX_SESSION_ID sess-1; codex resume sess-1"
+	if cmd := inst.buildCodexCommand("codex"); cmd != want {
+		t.Errorf("resume command:\n got: %s\nwant: %s", cmd, want)
+	}
+	if got := inst.LocalMCPNames(); len(got) != 1 || got[0] != "fs" {
+		t.Errorf("LocalMCPNames = %v", got)
+	}
+}
+
+func TestDeleteInstanceRemovesCodexSessionHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	codexHome := filepath.Join(home, ".codex")
+	t.Setenv("CODEX_HOME", codexHome)
+	withTestMCPs(t, codexTestMCPs())
+
+	if err := WriteCodexSessionMCP("inst-del", []string{"exa"}); err != nil {
+		t.Fatal(err)
+	}
+	s := newTestStorage(t)
+	if err := s.DeleteInstance("inst-del"); err != nil {
+		t.Fatalf("DeleteInstance: %v", err)
+	}
+	if _, err := os.Stat(GetCodexSessionHome("inst-del")); !os.IsNotExist(err) {
+		t.Error("deleting a session should remove its CODEX_HOME")
+	}
+	if _, err := os.Stat(filepath.Join(codexHome, "sessions")); err != nil {
+		t.Errorf("the real Codex home must be left alone: %v", err)
+	}
+}
+
+func TestBuildCodexCommandResolvesOptions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	userConfigCacheMu.Lock()
+	userConfigCache = &UserConfig{Codex: CodexSettings{
+		Model:  "gpt-5-codex",
+		Groups: map[string]CodexGroupSettings{"work": {Sandbox: "read-only"}},
+	}}
+	userConfigCacheMu.Unlock()
+	t.Cleanup(func() {
+		userConfigCacheMu.Lock()
+		userConfigCache = nil
+		userConfigCacheMu.Unlock()
+	})
+
+	inst := NewInstanceWithTool("codex-opts", "/tmp", "codex")
+	inst.GroupPath = "work/api"
+	if err := inst.SetCodexOptions(&CodexOptions{ApprovalPolicy: "on-request", Profile: "my profile"}); err != nil {
+		t.Fatal(err)
+	}
+	inst.CodexSessionID = "sess-1"
+
+	cmd := inst.buildCodexCommand("codex")
+	want := "codex --sandbox read-only --ask-for-approval on-request --model gpt-5-codex --profile 'my profile' resume sess-1"
+	if !strings.HasSuffix(cmd, want) {
+		t.Errorf("resume command should keep options before the subcommand:\n got: %s\nwant suffix: %s", cmd, want)
+	}
+}
+
+func TestTOMLString(t *testing.T) {
+	tests := map[string]string{
+		`plain`:     `"plain"`,
+		`a"b\c`:     `"a\"b\\c"`,
+		"tab\tnl\n": `"tab\tnl\n"`,
+		"bell\a":    `"bell\u0007"`,
+	}
+	for in, want := range tests {
+		if got := tomlString(in); got != want {
+			t.Errorf("tomlString(%q) = %s, want %s", in, got, want)
+		}
+	}
+	if got := tomlKey("my.mcp"); got != `"my.mcp"` {
+		t.Errorf("tomlKey should quote dotted names, got %s", got)
+	}
+	if got := unquoteTOMLKey(`"my.mcp"`); got != "my.mcp" {
+		t.Errorf("unquoteTOMLKey = %s", got)
+	}
+}
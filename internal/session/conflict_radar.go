@@ -0,0 +1,193 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+// WorktreeConflict is a pair of sessions in sibling worktrees of the same
+// repo whose changes touch the same files.
+type WorktreeConflict struct {
+	RepoRoot string            `json:"repo_root"`
+	Base     string            `json:"base"`
+	A        ConflictSide      `json:"a"`
+	B        ConflictSide      `json:"b"`
+	Files    []git.FileOverlap `json:"files"`
+}
+
+// ConflictSide identifies one session in a WorktreeConflict.
+type ConflictSide struct {
+	SessionID string `json:"session_id"`
+	Title     string `json:"title"`
+	Branch    string `json:"branch"`
+}
+
+// HunkOverlaps counts files where both sides edit the same lines.
+func (c WorktreeConflict) HunkOverlaps() int {
+	n := 0
+	for _, f := range c.Files {
+		if f.Hunks {
+			n++
+		}
+	}
+	return n
+}
+
+// Key identifies the conflict and its file set, for de-duplicating notifications.
+func (c WorktreeConflict) Key() string {
+	paths := make([]string, len(c.Files))
+	for i, f := range c.Files {
+		paths[i] = f.Path
+	}
+	return c.A.SessionID + "|" + c.B.SessionID + "|" + strings.Join(paths, ",")
+}
+
+// FindWorktreeConflicts computes each worktree session's changes against the
+// merge-base with its repo's default branch and reports every pair of
+// sibling sessions that touch the same files. Sessions whose worktree is
+// missing or cannot be diffed are skipped.
+func FindWorktreeConflicts(instances []*Instance) []WorktreeConflict {
+	byRepo := make(map[string][]*Instance)
+	for _, inst := range instances {
+		if !inst.IsWorktree() || inst.WorktreeRepoRoot == "" {
+			continue
+		}
+		if _, err := os.Stat(inst.WorktreePath); err != nil {
+			continue
+		}
+		byRepo[inst.WorktreeRepoRoot] = append(byRepo[inst.WorktreeRepoRoot], inst)
+	}
+
+	var conflicts []WorktreeConflict
+	for repoRoot, sessions := range byRepo {
+		if len(sessions) < 2 {
+			continue
+		}
+		base, err := git.GetDefaultBranch(repoRoot)
+		if err != nil {
+			continue
+		}
+
+		type sessionChanges struct {
+			inst    *Instance
+			changes git.ChangeSet
+		}
+		var computed []sessionChanges
+		for _, inst := range sessions {
+			changes, err := git.ChangedHunks(inst.WorktreePath, base)
+			if err != nil || len(changes) == 0 {
+				continue
+			}
+			computed = append(computed, sessionChanges{inst: inst, changes: changes})
+		}
+		sort.Slice(computed, func(i, j int) bool { return computed[i].inst.Title < computed[j].inst.Title })
+
+		for i := 0; i < len(computed); i++ {
+			for j := i + 1; j < len(computed); j++ {
+				files := git.CompareChanges(computed[i].changes, computed[j].changes)
+				if len(files) == 0 {
+					continue
+				}
+				a, b := computed[i].inst, computed[j].inst
+				conflicts = append(conflicts, WorktreeConflict{
+					RepoRoot: repoRoot,
+					Base:     base,
+					A:        ConflictSide{SessionID: a.ID, Title: a.Title, Branch: a.WorktreeBranch},
+					B:        ConflictSide{SessionID: b.ID, Title: b.Title, Branch: b.WorktreeBranch},
+					Files:    files,
+				})
+			}
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].RepoRoot != conflicts[j].RepoRoot {
+			return conflicts[i].RepoRoot < conflicts[j].RepoRoot
+		}
+		return conflicts[i].Key() < conflicts[j].Key()
+	})
+	return conflicts
+}
+
+// ConflictCounts returns, per session ID, how many other sessions it overlaps with.
+func ConflictCounts(conflicts []WorktreeConflict) map[string]int {
+	counts := make(map[string]int)
+	for _, c := range conflicts {
+		counts[c.A.SessionID]++
+		counts[c.B.SessionID]++
+	}
+	return counts
+}
+
+// NotifyWorktreeConflict tells the parents of both sessions about the
+// overlap, falling back to the profile's conductor when a session has no
+// live parent. Each target is messaged at most once. It returns the titles
+// of the sessions that were notified.
+func NotifyWorktreeConflict(profile string, conflict WorktreeConflict, instances []*Instance) ([]string, error) {
+	byID := make(map[string]*Instance, len(instances))
+	for _, inst := range instances {
+		byID[inst.ID] = inst
+	}
+
+	involved := map[string]bool{conflict.A.SessionID: true, conflict.B.SessionID: true}
+	var targets []*Instance
+	seen := make(map[string]bool)
+	needConductor := false
+	for _, side := range []ConflictSide{conflict.A, conflict.B} {
+		child := byID[side.SessionID]
+		if child == nil {
+			continue
+		}
+		parent := byID[strings.TrimSpace(child.ParentSessionID)]
+		if parent == nil || involved[parent.ID] {
+			needConductor = true
+			continue
+		}
+		if !seen[parent.ID] {
+			seen[parent.ID] = true
+			targets = append(targets, parent)
+		}
+	}
+	if needConductor {
+		if conductor := selectFallbackConductor(profile, instances); conductor != nil && !involved[conductor.ID] && !seen[conductor.ID] {
+			targets = append(targets, conductor)
+		}
+	}
+
+	message := buildWorktreeConflictMessage(profile, conflict)
+	var notified []string
+	var errs []string
+	for _, target := range targets {
+		if err := SendSessionMessageReliable(profile, target.ID, message); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", target.Title, err))
+			continue
+		}
+		notified = append(notified, target.Title)
+	}
+	if len(errs) > 0 {
+		return notified, fmt.Errorf("notify failed: %s", strings.Join(errs, "; "))
+	}
+	return notified, nil
+}
+
+func buildWorktreeConflictMessage(profile string, conflict WorktreeConflict) string {
+	paths := make([]string, 0, len(conflict.Files))
+	for _, f := range conflict.Files {
+		if f.Hunks {
+			paths = append(paths, f.Path+" (same lines)")
+		} else {
+			paths = append(paths, f.Path)
+		}
+	}
+	return fmt.Sprintf(
+		"[EVENT] Worktree sessions '%s' (%s) and '%s' (%s) are both changing: %s.\nCheck: agent-deck -p %s worktree conflicts",
+		conflict.A.Title, conflict.A.Branch,
+		conflict.B.Title, conflict.B.Branch,
+		strings.Join(paths, ", "),
+		profile,
+	)
+}
@@ -0,0 +1,80 @@
+package session
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+func runGitCmd(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %s", args, out)
+	}
+}
+
+func newRadarWorktree(t *testing.T, repo, branch, file, content string) *Instance {
+	t.Helper()
+	wt := filepath.Join(t.TempDir(), branch)
+	if err := git.CreateWorktree(repo, wt, branch); err != nil {
+		t.Fatalf("CreateWorktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(wt, file), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	inst := NewInstance(branch, wt)
+	inst.WorktreePath = wt
+	inst.WorktreeRepoRoot = repo
+	inst.WorktreeBranch = branch
+	return inst
+}
+
+func TestFindWorktreeConflicts(t *testing.T) {
+	repo := t.TempDir()
+	runGitCmd(t, repo, "init", "-b", "main")
+	runGitCmd(t, repo, "config", "user.email", "test@test.com")
+	runGitCmd(t, repo, "config", "user.name", "Test User")
+	base := strings.Repeat("line\n", 50)
+	if err := os.WriteFile(filepath.Join(repo, "shared.go"), []byte(base), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGitCmd(t, repo, "add", ".")
+	runGitCmd(t, repo, "commit", "-m", "init")
+
+	top := strings.Replace(base, "line\n", "top\n", 1)
+	bottom := strings.Repeat("line\n", 49) + "bottom\n"
+	a := newRadarWorktree(t, repo, "alpha", "shared.go", top)
+	b := newRadarWorktree(t, repo, "beta", "shared.go", top)
+	c := newRadarWorktree(t, repo, "gamma", "shared.go", bottom)
+	d := newRadarWorktree(t, repo, "delta", "other.go", "x\n")
+
+	conflicts := FindWorktreeConflicts([]*Instance{a, b, c, d})
+	if len(conflicts) != 3 {
+		t.Fatalf("expected 3 overlapping pairs (alpha/beta/gamma), got %+v", conflicts)
+	}
+
+	byPair := make(map[string]WorktreeConflict)
+	for _, conflict := range conflicts {
+		byPair[conflict.A.Title+"/"+conflict.B.Title] = conflict
+	}
+	if got := byPair["alpha/beta"]; got.HunkOverlaps() != 1 || got.Base != "main" {
+		t.Fatalf("expected alpha/beta to overlap on the same lines, got %+v", got)
+	}
+	if got := byPair["alpha/gamma"]; len(got.Files) != 1 || got.HunkOverlaps() != 0 {
+		t.Fatalf("expected alpha/gamma to share only the file, got %+v", got)
+	}
+
+	counts := ConflictCounts(conflicts)
+	if counts[a.ID] != 2 || counts[d.ID] != 0 {
+		t.Fatalf("unexpected conflict counts %v", counts)
+	}
+	msg := buildWorktreeConflictMessage("default", byPair["alpha/beta"])
+	if !strings.Contains(msg, "shared.go (same lines)") || !strings.Contains(msg, "worktree conflicts") {
+		t.Fatalf("unexpected notification message %q", msg)
+	}
+}
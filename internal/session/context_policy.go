@@ -0,0 +1,320 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const contextPolicySettingsFileName = "context_policies.json"
+
+// Context policy actions
+const (
+	// ContextActionCompact sends the tool's compaction command (Claude's
+	// /compact takes Instruction as focus instructions)
+	ContextActionCompact = "compact"
+	// ContextActionNotify messages the parent session, or the conductor
+	ContextActionNotify = "notify"
+	// ContextActionFork starts a fresh session of the same tool seeded with
+	// a handoff summary (see BuildHandoffPackage)
+	ContextActionFork = "fork"
+)
+
+// compactCommands is each tool's command for compacting its conversation
+var compactCommands = map[string]string{
+	"claude":   "/compact",
+	"codex":    "/compact",
+	"opencode": "/compact",
+	"gemini":   "/compress",
+}
+
+// ContextRule acts once when a session's context usage reaches Threshold
+// percent. It re-arms when usage drops back below the threshold, e.g. after
+// a compaction.
+type ContextRule struct {
+	Threshold   float64 `json:"threshold"`
+	Action      string  `json:"action"`
+	Instruction string  `json:"instruction,omitempty"`
+}
+
+// String renders the rule in the form ParseContextRule accepts
+func (r ContextRule) String() string {
+	s := strconv.FormatFloat(r.Threshold, 'f', -1, 64) + ":" + r.Action
+	if r.Instruction != "" {
+		s += ":" + r.Instruction
+	}
+	return s
+}
+
+// ParseContextRule parses "<percent>:<action>[:<instruction>]", e.g.
+// "70:notify" or "85:compact:keep the API decisions"
+func ParseContextRule(spec string) (ContextRule, error) {
+	pct, action, ok := strings.Cut(strings.TrimSpace(spec), ":")
+	if !ok {
+		return ContextRule{}, fmt.Errorf("invalid rule %q (expected <percent>:<action>)", spec)
+	}
+	action, instruction, _ := strings.Cut(action, ":")
+	threshold, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(pct), "%"), 64)
+	if err != nil || threshold <= 0 || threshold > 100 {
+		return ContextRule{}, fmt.Errorf("invalid threshold %q (expected 1-100)", pct)
+	}
+	rule := ContextRule{
+		Threshold:   threshold,
+		Action:      strings.ToLower(strings.TrimSpace(action)),
+		Instruction: strings.TrimSpace(instruction),
+	}
+	switch rule.Action {
+	case ContextActionCompact, ContextActionNotify, ContextActionFork:
+	default:
+		return ContextRule{}, fmt.Errorf("unknown action %q (expected compact, notify or fork)", action)
+	}
+	return rule, nil
+}
+
+// ContextPolicySettings records context-window rules per session and group.
+// A session entry overrides its group; an empty rule list opts a session out
+// of its group's policy.
+type ContextPolicySettings struct {
+	Sessions map[string][]ContextRule `json:"sessions,omitempty"`
+	Groups   map[string][]ContextRule `json:"groups,omitempty"`
+
+	// Fired holds the thresholds that have acted per session, so a crossing
+	// acts once even when both the TUI and the notification daemon see it
+	Fired map[string][]float64 `json:"fired,omitempty"`
+}
+
+// Rules returns the rules that apply to inst, sorted by threshold. Group
+// rules are inherited by subgroups; the most specific group wins.
+func (s *ContextPolicySettings) Rules(inst *Instance) []ContextRule {
+	rules, ok := s.Sessions[inst.ID]
+	if !ok {
+		best := -1
+		for path, groupRules := range s.Groups {
+			if (inst.GroupPath == path || strings.HasPrefix(inst.GroupPath, path+"/")) && len(path) > best {
+				best = len(path)
+				rules = groupRules
+			}
+		}
+	}
+	sorted := append([]ContextRule(nil), rules...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Threshold < sorted[j].Threshold })
+	return sorted
+}
+
+// contextPolicySettingsMu serializes read-modify-write cycles on the
+// settings file within this process.
+var contextPolicySettingsMu sync.Mutex
+
+func contextPolicySettingsPath(profile string) (string, error) {
+	profileDir, err := GetProfileDir(GetEffectiveProfile(profile))
+	if err != nil {
+		return "", fmt.Errorf("resolve profile dir: %w", err)
+	}
+	return filepath.Join(profileDir, contextPolicySettingsFileName), nil
+}
+
+// LoadContextPolicySettings returns the context policies for profile.
+func LoadContextPolicySettings(profile string) (*ContextPolicySettings, error) {
+	path, err := contextPolicySettingsPath(profile)
+	if err != nil {
+		return nil, err
+	}
+	settings := &ContextPolicySettings{}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return settings, nil
+		}
+		return nil, fmt.Errorf("read context policies: %w", err)
+	}
+	if err := json.Unmarshal(raw, settings); err != nil {
+		return nil, fmt.Errorf("parse context policies: %w", err)
+	}
+	return settings, nil
+}
+
+// SetSessionContextPolicy sets one session's rules. Nil rules remove the
+// override so the session inherits its group's policy again.
+func SetSessionContextPolicy(profile, sessionID string, rules []ContextRule) error {
+	return updateContextPolicySettings(profile, func(s *ContextPolicySettings) {
+		if rules == nil {
+			delete(s.Sessions, sessionID)
+			return
+		}
+		if s.Sessions == nil {
+			s.Sessions = map[string][]ContextRule{}
+		}
+		s.Sessions[sessionID] = rules
+	})
+}
+
+// SetGroupContextPolicy sets the rules for a group and its subgroups. Nil
+// rules remove the group's policy.
+func SetGroupContextPolicy(profile, groupPath string, rules []ContextRule) error {
+	return updateContextPolicySettings(profile, func(s *ContextPolicySettings) {
+		if rules == nil {
+			delete(s.Groups, groupPath)
+			return
+		}
+		if s.Groups == nil {
+			s.Groups = map[string][]ContextRule{}
+		}
+		s.Groups[groupPath] = rules
+	})
+}
+
+func updateContextPolicySettings(profile string, mutate func(*ContextPolicySettings)) error {
+	contextPolicySettingsMu.Lock()
+	defer contextPolicySettingsMu.Unlock()
+
+	settings, err := LoadContextPolicySettings(profile)
+	if err != nil {
+		return err
+	}
+	mutate(settings)
+
+	path, err := contextPolicySettingsPath(profile)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("mkdir context policies dir: %w", err)
+	}
+	raw, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal context policies: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("write temp context policies: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename context policies: %w", err)
+	}
+	return nil
+}
+
+// selectContextRule returns the rule to act on at percent, or nil, and the
+// thresholds that count as fired afterwards. Only the highest newly crossed
+// rule acts: a jump past both a notify and a compact threshold compacts.
+func selectContextRule(rules []ContextRule, fired []float64, percent float64) (*ContextRule, []float64) {
+	wasFired := make(map[float64]bool, len(fired))
+	for _, t := range fired {
+		wasFired[t] = true
+	}
+	var selected *ContextRule
+	var nowFired []float64
+	for k := range rules {
+		if percent < rules[k].Threshold {
+			continue // Below the threshold: re-armed
+		}
+		nowFired = append(nowFired, rules[k].Threshold)
+		if !wasFired[rules[k].Threshold] {
+			selected = &rules[k]
+		}
+	}
+	return selected, nowFired
+}
+
+// ApplyContextPolicy runs inst's context policy when an agent turn ends
+// (running to waiting or idle). It returns the rule that acted, or nil.
+func ApplyContextPolicy(profile string, inst *Instance, from, to Status) (*ContextRule, error) {
+	if inst == nil || !IsTurnEnd(from, to) {
+		return nil, nil
+	}
+	settings, err := LoadContextPolicySettings(profile)
+	if err != nil {
+		return nil, err
+	}
+	rules := settings.Rules(inst)
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	analytics, err := inst.LoadAnalytics()
+	if err != nil || analytics == nil {
+		return nil, err
+	}
+	percent := analytics.ContextPercent()
+
+	var rule *ContextRule
+	if err := updateContextPolicySettings(profile, func(s *ContextPolicySettings) {
+		var fired []float64
+		rule, fired = selectContextRule(rules, s.Fired[inst.ID], percent)
+		if s.Fired == nil {
+			s.Fired = map[string][]float64{}
+		}
+		if len(fired) == 0 {
+			delete(s.Fired, inst.ID)
+		} else {
+			s.Fired[inst.ID] = fired
+		}
+	}); err != nil || rule == nil {
+		return nil, err
+	}
+	return rule, runContextAction(profile, inst, *rule, analytics)
+}
+
+func runContextAction(profile string, inst *Instance, rule ContextRule, analytics *AgentAnalytics) error {
+	switch rule.Action {
+	case ContextActionCompact:
+		command, ok := compactCommands[inst.Tool]
+		if !ok {
+			return fmt.Errorf("%s sessions have no compact command", inst.Tool)
+		}
+		if inst.Tool == "claude" && rule.Instruction != "" {
+			command += " " + rule.Instruction
+		}
+		return SendSessionMessageReliable(profile, inst.ID, command)
+
+	case ContextActionNotify:
+		storage, err := NewStorageWithProfile(profile)
+		if err != nil {
+			return err
+		}
+		instances, _, err := storage.LoadWithGroups()
+		if err != nil {
+			return err
+		}
+		byID := make(map[string]*Instance, len(instances))
+		for _, other := range instances {
+			byID[other.ID] = other
+		}
+		message := fmt.Sprintf(
+			"[EVENT] Session '%s' (%s) is at %.0f%% of its context window (%d/%d tokens).\nCheck: agent-deck -p %s session show %s",
+			inst.Title, inst.ID, analytics.ContextPercent(), analytics.CurrentContextTokens, analytics.ContextLimit(),
+			GetEffectiveProfile(profile), inst.ID,
+		)
+		if _, _, result := deliverToParentOrConductor(profile, inst, byID, instances, message); result != transitionDeliverySent && result != transitionDeliveryFallbackSent {
+			return fmt.Errorf("context notice not delivered (%s)", result)
+		}
+		return nil
+
+	case ContextActionFork:
+		return startSessionHandoff(profile, inst.ID, inst.Tool)
+	}
+	return fmt.Errorf("unknown context action %q", rule.Action)
+}
+
+// startSessionHandoff runs `agent-deck session handoff` in the background;
+// it waits for the new agent to be ready, which can take a minute
+func startSessionHandoff(profile, sessionRef, tool string) error {
+	args := []string{}
+	if strings.TrimSpace(profile) != "" {
+		args = append(args, "-p", profile)
+	}
+	args = append(args, "session", "handoff", sessionRef, "--to", tool, "-q")
+	cmd := exec.Command(agentDeckBinaryPath(), args...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("handoff failed: %w", err)
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
@@ -0,0 +1,99 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseContextRule(t *testing.T) {
+	rule, err := ParseContextRule("85%:compact:keep the API decisions")
+	if err != nil {
+		t.Fatalf("ParseContextRule: %v", err)
+	}
+	want := ContextRule{Threshold: 85, Action: ContextActionCompact, Instruction: "keep the API decisions"}
+	if rule != want {
+		t.Errorf("rule = %+v, want %+v", rule, want)
+	}
+	if rule.String() != "85:compact:keep the API decisions" {
+		t.Errorf("String() = %q", rule.String())
+	}
+
+	for _, spec := range []string{"85", "0:notify", "120:notify", "x:notify", "80:explode"} {
+		if _, err := ParseContextRule(spec); err == nil {
+			t.Errorf("ParseContextRule(%q) should fail", spec)
+		}
+	}
+}
+
+func TestContextPolicySettingsRules(t *testing.T) {
+	settings := &ContextPolicySettings{
+		Sessions: map[string][]ContextRule{"opt-out": {}, "own": {{Threshold: 50, Action: "notify"}}},
+		Groups: map[string][]ContextRule{
+			"work":     {{Threshold: 90, Action: "fork"}, {Threshold: 70, Action: "notify"}},
+			"work/api": {{Threshold: 80, Action: "compact"}},
+		},
+	}
+	cases := []struct {
+		id, group string
+		want      []float64
+	}{
+		{"a", "work", []float64{70, 90}}, // sorted by threshold
+		{"b", "work/api/v2", []float64{80}},
+		{"c", "workshop", nil},
+		{"opt-out", "work", nil},
+		{"own", "work", []float64{50}},
+	}
+	for _, tc := range cases {
+		var got []float64
+		for _, r := range settings.Rules(&Instance{ID: tc.id, GroupPath: tc.group}) {
+			got = append(got, r.Threshold)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Rules(%s in %q) = %v, want %v", tc.id, tc.group, got, tc.want)
+		}
+	}
+}
+
+func TestSelectContextRule(t *testing.T) {
+	rules := []ContextRule{
+		{Threshold: 70, Action: ContextActionNotify},
+		{Threshold: 85, Action: ContextActionCompact},
+	}
+
+	rule, fired := selectContextRule(rules, nil, 60)
+	if rule != nil || fired != nil {
+		t.Errorf("below all thresholds: rule = %v, fired = %v", rule, fired)
+	}
+
+	rule, fired = selectContextRule(rules, nil, 75)
+	if rule == nil || rule.Action != ContextActionNotify || !reflect.DeepEqual(fired, []float64{70}) {
+		t.Errorf("at 75%%: rule = %v, fired = %v", rule, fired)
+	}
+
+	// Still above 70: already acted
+	if rule, _ = selectContextRule(rules, fired, 80); rule != nil {
+		t.Errorf("70%% rule should act once, got %v", rule)
+	}
+
+	// A jump past both thresholds only runs the highest
+	rule, fired = selectContextRule(rules, nil, 95)
+	if rule == nil || rule.Action != ContextActionCompact || !reflect.DeepEqual(fired, []float64{70, 85}) {
+		t.Errorf("at 95%%: rule = %v, fired = %v", rule, fired)
+	}
+
+	// Compaction drops usage: both rules re-arm
+	if rule, fired = selectContextRule(rules, fired, 30); rule != nil || fired != nil {
+		t.Errorf("after compaction: rule = %v, fired = %v", rule, fired)
+	}
+	if rule, _ = selectContextRule(rules, fired, 72); rule == nil || rule.Threshold != 70 {
+		t.Errorf("re-armed rule should act again, got %v", rule)
+	}
+}
+
+func TestApplyContextPolicy_IgnoresNonTurnTransitions(t *testing.T) {
+	inst := NewInstanceWithTool("ctx", "/tmp/project", "claude")
+	rule, err := ApplyContextPolicy("default", inst, StatusWaiting, StatusIdle)
+	if rule != nil || err != nil {
+		t.Errorf("ApplyContextPolicy = %v, %v; want nil, nil", rule, err)
+	}
+}
@@ -11,7 +11,7 @@ import (
 // buildEnvSourceCommand builds shell commands to source .env files before the main command.
 // Returns empty string if no env files are configured.
 // Order of sourcing (later overrides earlier):
-//  1. Global [shell].env_files (in order)
+//  1. Global [shell].env_files (in order), then the worktree's provisioned env file
 //  2. [shell].init_script (for direnv, nvm, etc.)
 //  3. Tool-specific env_file ([claude].env_file, [gemini].env_file, [tools.X].env_file)
 //  4. Inline env vars from [tools.X].env (highest priority)
@@ -30,6 +30,11 @@ func (i *Instance) buildEnvSourceCommand() string {
 		sources = append(sources, buildSourceCmd(resolved, ignoreMissing))
 	}
 
+	// Ports and variables seeded by worktree provisioning (auto-exported)
+	if envFile := i.provisionedEnvFile(); envFile != "" {
+		sources = append(sources, fmt.Sprintf(`{ set -a; source "%s"; set +a; }`, envFile))
+	}
+
 	// 2. Shell init script (direnv, nvm, pyenv, etc.)
 	if config.Shell.InitScript != "" {
 		script := config.Shell.InitScript
@@ -170,3 +175,20 @@ func (i *Instance) getToolEnvFile() string {
 	}
 	return ""
 }
+
+// provisionedEnvFile returns the env file written by worktree provisioning,
+// or empty string when the session's worktree has none.
+func (i *Instance) provisionedEnvFile() string {
+	if !i.IsWorktree() {
+		return ""
+	}
+	result, err := LoadProvisionResult(i.WorktreePath)
+	if err != nil || result == nil || result.EnvFile == "" {
+		return ""
+	}
+	path := filepath.Join(i.WorktreePath, result.EnvFile)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
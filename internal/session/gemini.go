@@ -175,6 +175,72 @@ func findGeminiSessionInAllProjects(sessionID string) string {
 	return bestPath
 }
 
+// findGeminiSessionFile returns the chat file for a session ID, looking in
+// the project's chats dir first and then across all projects
+func findGeminiSessionFile(projectPath, sessionID string) string {
+	if len(sessionID) < 8 {
+		return ""
+	}
+	// Filename format is session-YYYY-MM-DDTHH-MM-<uuid8>.json
+	pattern := filepath.Join(GetGeminiSessionsDir(projectPath), "session-*-"+sessionID[:8]+".json")
+	if files, _ := filepath.Glob(pattern); len(files) > 0 {
+		return files[0]
+	}
+	return findGeminiSessionInAllProjects(sessionID)
+}
+
+// ForkGeminiSession duplicates a Gemini chat under a new session ID in the
+// chats dir of targetPath (the parent's project or a worktree) and returns
+// the new ID. `gemini --resume <id>` then continues the copy independently.
+func ForkGeminiSession(projectPath, sessionID, targetPath string) (string, error) {
+	src := findGeminiSessionFile(projectPath, sessionID)
+	if src == "" {
+		return "", fmt.Errorf("session file not found for ID: %s", sessionID)
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	// Keep unknown fields intact; only the identity fields change
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	newID, err := newConversationUUID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	now := time.Now().UTC()
+	setString := func(key, value string) {
+		raw, _ := json.Marshal(value)
+		doc[key] = raw
+	}
+	setString("sessionId", newID)
+	setString("lastUpdated", now.Format("2006-01-02T15:04:05.000Z"))
+	if _, ok := doc["projectHash"]; ok {
+		setString("projectHash", HashProjectPath(targetPath))
+	}
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	chatsDir := GetGeminiSessionsDir(targetPath)
+	if chatsDir == "" {
+		return "", fmt.Errorf("cannot determine Gemini sessions dir for %s", targetPath)
+	}
+	if err := os.MkdirAll(chatsDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create sessions dir: %w", err)
+	}
+	name := fmt.Sprintf("session-%s-%s.json", now.Format("2006-01-02T15-04"), newID[:8])
+	if err := os.WriteFile(filepath.Join(chatsDir, name), out, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write forked session: %w", err)
+	}
+	return newID, nil
+}
+
 // UpdateGeminiAnalyticsFromDisk updates the analytics struct from the session file on disk.
 // Uses mtime caching to skip re-parsing unchanged files (important for 40MB+ session files).
 func UpdateGeminiAnalyticsFromDisk(projectPath, sessionID string, analytics *GeminiSessionAnalytics) error {
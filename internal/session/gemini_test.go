@@ -457,3 +457,125 @@ func TestGetAvailableGeminiModels_UpdatedFallback(t *testing.T) {
 		}
 	}
 }
+
+func TestForkGeminiSession(t *testing.T) {
+	tmpDir := t.TempDir()
+	geminiConfigDirOverride = tmpDir
+	defer func() { geminiConfigDirOverride = "" }()
+
+	projectPath := t.TempDir()
+	sessionsDir := GetGeminiSessionsDir(projectPath)
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	parentID := "abc12345-1111-1111-1111-111111111111"
+	parentData := `{
+  "sessionId": "` + parentID + `",
+  "projectHash": "` + HashProjectPath(projectPath) + `",
+  "startTime": "2025-12-23T00:24:00.000Z",
+  "lastUpdated": "2025-12-23T00:30:00.000Z",
+  "messages": [{"id": "1", "type": "user", "content": "hello"}, {"id": "2", "type": "gemini", "content": "hi"}]
+}`
+	if err := os.WriteFile(filepath.Join(sessionsDir, "session-2025-12-23T00-24-abc12345.json"), []byte(parentData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fork into a different directory (a worktree)
+	worktree := t.TempDir()
+	newID, err := ForkGeminiSession(projectPath, parentID, worktree)
+	if err != nil {
+		t.Fatalf("ForkGeminiSession: %v", err)
+	}
+	if newID == parentID || len(newID) != 36 {
+		t.Fatalf("new ID = %q", newID)
+	}
+
+	forkFile := findGeminiSessionFile(worktree, newID)
+	if forkFile == "" || filepath.Dir(forkFile) != GetGeminiSessionsDir(worktree) {
+		t.Fatalf("forked chat not in worktree chats dir: %q", forkFile)
+	}
+	info, err := parseGeminiSessionFile(forkFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.SessionID != newID || info.MessageCount != 2 {
+		t.Errorf("forked chat = %+v", info)
+	}
+	data, _ := os.ReadFile(forkFile)
+	if !strings.Contains(string(data), HashProjectPath(worktree)) {
+		t.Error("forked chat should carry the worktree's project hash")
+	}
+
+	if _, err := ForkGeminiSession(projectPath, "ffffffff-0000-0000-0000-000000000000", projectPath); err == nil {
+		t.Error("expected error for an unknown session")
+	}
+}
+
+func TestCreateForkedGeminiInstance(t *testing.T) {
+	tmpDir := t.TempDir()
+	geminiConfigDirOverride = tmpDir
+	defer func() { geminiConfigDirOverride = "" }()
+
+	projectPath := t.TempDir()
+	sessionsDir := GetGeminiSessionsDir(projectPath)
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	parentID := "abc12345-1111-1111-1111-111111111111"
+	_ = os.WriteFile(filepath.Join(sessionsDir, "session-2025-12-23T00-24-abc12345.json"),
+		[]byte(`{"sessionId":"`+parentID+`","messages":[]}`), 0644)
+
+	parent := NewInstanceWithTool("gem", projectPath, "gemini")
+	parent.GroupPath = "work"
+	parent.GeminiModel = "gemini-2.5-pro"
+	if parent.CanFork() {
+		t.Error("CanFork should need a session ID")
+	}
+
+	parent.GeminiSessionID = parentID
+	if !parent.CanFork() {
+		t.Fatal("CanFork = false with a session ID")
+	}
+	forked, err := parent.ForkWithAdapter("gem (fork)", "", nil)
+	if err != nil {
+		t.Fatalf("ForkWithAdapter: %v", err)
+	}
+	if forked.Tool != "gemini" || forked.GroupPath != "work" || forked.ProjectPath != projectPath {
+		t.Errorf("forked = tool %q group %q path %q", forked.Tool, forked.GroupPath, forked.ProjectPath)
+	}
+	if forked.GeminiSessionID == "" || forked.GeminiSessionID == parentID {
+		t.Errorf("forked session ID = %q", forked.GeminiSessionID)
+	}
+	if forked.GeminiModel != "gemini-2.5-pro" {
+		t.Errorf("GeminiModel = %q, want the parent's", forked.GeminiModel)
+	}
+	if cmd := forked.buildGeminiCommand("gemini"); !strings.Contains(cmd, "--resume "+forked.GeminiSessionID) {
+		t.Errorf("forked command should resume the copy: %s", cmd)
+	}
+}
+
+func TestSyncGeminiSessionFromDisk_SkipsExcluded(t *testing.T) {
+	tmpDir := t.TempDir()
+	geminiConfigDirOverride = tmpDir
+	defer func() { geminiConfigDirOverride = "" }()
+
+	projectPath := t.TempDir()
+	sessionsDir := GetGeminiSessionsDir(projectPath)
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	parentID := "abc12345-1111-1111-1111-111111111111"
+	forkID := "def67890-2222-2222-2222-222222222222"
+	_ = os.WriteFile(filepath.Join(sessionsDir, "session-2025-12-23T00-24-abc12345.json"),
+		[]byte(`{"sessionId":"`+parentID+`","lastUpdated":"2025-12-23T00:30:00.000Z","messages":[]}`), 0644)
+	// A fork of the parent, updated more recently
+	_ = os.WriteFile(filepath.Join(sessionsDir, "session-2025-12-24T10-00-def67890.json"),
+		[]byte(`{"sessionId":"`+forkID+`","lastUpdated":"2025-12-24T10:15:00.000Z","messages":[]}`), 0644)
+
+	inst := NewInstanceWithTool("gem", projectPath, "gemini")
+	inst.GeminiSessionID = parentID
+	inst.syncGeminiSessionFromDisk(map[string]bool{forkID: true})
+	if inst.GeminiSessionID != parentID {
+		t.Errorf("GeminiSessionID = %q, should not adopt the excluded fork", inst.GeminiSessionID)
+	}
+}
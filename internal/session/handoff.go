@@ -0,0 +1,322 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+const (
+	// DefaultHandoffTurns is how many recent messages a handoff carries
+	DefaultHandoffTurns = 6
+
+	handoffGoalLimit  = 2000
+	handoffTurnLimit  = 1500
+	handoffFilesLimit = 50
+)
+
+// HandoffPackage is the condensed context passed from one agent to another
+// when work moves to a different tool: what the user asked for, where the
+// conversation ended, what changed on disk and what is still open.
+type HandoffPackage struct {
+	FromTool      string
+	FromTitle     string
+	ProjectPath   string
+	Branch        string
+	Goal          string
+	RecentTurns   []TranscriptMessage
+	ModifiedFiles []string
+	OpenTodos     []string
+}
+
+// BuildHandoffPackage condenses i's transcript and working tree into a
+// handoff package carrying the last recentTurns messages. Sessions without a
+// readable transcript still hand off their modified files.
+func BuildHandoffPackage(i *Instance, recentTurns int) (*HandoffPackage, error) {
+	var messages []TranscriptMessage
+	if adapter := GetToolAdapter(i.Tool); adapter != nil {
+		var err error
+		if messages, err = adapter.Transcript(i); err != nil {
+			return nil, fmt.Errorf("failed to read %s transcript: %w", i.Tool, err)
+		}
+		if len(messages) == 0 {
+			if resp, err := adapter.LastResponse(i); err == nil && resp.Content != "" {
+				messages = []TranscriptMessage{{Role: "assistant", Text: resp.Content}}
+			}
+		}
+	}
+
+	p := &HandoffPackage{
+		FromTool:    i.Tool,
+		FromTitle:   i.Title,
+		ProjectPath: i.ProjectPath,
+		Branch:      i.WorktreeBranch,
+	}
+	if p.Branch == "" {
+		p.Branch, _ = git.GetCurrentBranch(i.ProjectPath)
+	}
+
+	var turns []TranscriptMessage
+	for _, m := range messages {
+		if m.Text == "" || (m.Role == "user" && isInjectedUserText(m.Text)) {
+			continue
+		}
+		if p.Goal == "" && m.Role == "user" {
+			p.Goal = truncateHandoffText(m.Text, handoffGoalLimit)
+		}
+		turns = append(turns, m)
+	}
+	if recentTurns > 0 && len(turns) > recentTurns {
+		turns = turns[len(turns)-recentTurns:]
+	}
+	for _, m := range turns {
+		m.Text = truncateHandoffText(m.Text, handoffTurnLimit)
+		m.ToolCalls = nil
+		p.RecentTurns = append(p.RecentTurns, m)
+	}
+
+	p.ModifiedFiles = handoffModifiedFiles(i.ProjectPath, messages)
+	p.OpenTodos = handoffOpenTodos(messages)
+	return p, nil
+}
+
+// Prompt renders the package as the first message of the receiving session
+func (p *HandoffPackage) Prompt() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Handoff from %s session %q\n\n", p.FromTool, p.FromTitle)
+	b.WriteString("You are taking over work another agent started in this directory")
+	if p.Branch != "" {
+		fmt.Fprintf(&b, " (branch %s)", p.Branch)
+	}
+	b.WriteString(". Its context is summarized below; the files on disk are the source of truth.\n")
+
+	if p.Goal != "" {
+		fmt.Fprintf(&b, "\n## Goal\n\n%s\n", p.Goal)
+	}
+	if len(p.RecentTurns) > 0 {
+		b.WriteString("\n## Recent turns\n")
+		for _, m := range p.RecentTurns {
+			speaker := "User"
+			if m.Role == "assistant" {
+				speaker = "Assistant"
+			}
+			fmt.Fprintf(&b, "\n**%s:** %s\n", speaker, m.Text)
+		}
+	}
+	if len(p.ModifiedFiles) > 0 {
+		b.WriteString("\n## Modified files\n\n")
+		for _, f := range p.ModifiedFiles {
+			fmt.Fprintf(&b, "- %s\n", f)
+		}
+	}
+	if len(p.OpenTodos) > 0 {
+		b.WriteString("\n## Open TODOs\n\n")
+		for _, t := range p.OpenTodos {
+			fmt.Fprintf(&b, "- [ ] %s\n", t)
+		}
+	}
+	b.WriteString("\nReview the current state of these files, then continue with the next step.\n")
+	return b.String()
+}
+
+// NewHandoffInstance creates a not yet started session of targetTool in i's
+// working directory and worktree, linked to i as its child. Nesting is
+// single-level, so a handoff from a sub-session becomes its sibling.
+func (i *Instance) NewHandoffInstance(targetTool, newTitle string) (*Instance, error) {
+	if GetToolAdapter(targetTool) == nil {
+		return nil, fmt.Errorf("unknown tool %q (expected claude, gemini, codex, opencode or a [tools] entry)", targetTool)
+	}
+
+	h := NewInstanceWithTool(newTitle, i.ProjectPath, targetTool)
+	h.GroupPath = i.GroupPath
+	h.Command = targetTool
+	if def := GetToolDef(targetTool); def != nil {
+		h.Command = def.Command
+	}
+	h.WorktreePath = i.WorktreePath
+	h.WorktreeRepoRoot = i.WorktreeRepoRoot
+	h.WorktreeBranch = i.WorktreeBranch
+
+	if i.IsSubSession() {
+		h.SetParentWithPath(i.ParentSessionID, i.ParentProjectPath)
+	} else {
+		h.SetParentWithPath(i.ID, i.ProjectPath)
+	}
+	return h, nil
+}
+
+// isInjectedUserText reports tool-injected "user" messages (slash command
+// echoes, environment and instruction blocks) that are not real requests
+func isInjectedUserText(text string) bool {
+	return strings.HasPrefix(strings.TrimSpace(text), "<")
+}
+
+func truncateHandoffText(s string, limit int) string {
+	s = strings.TrimSpace(s)
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:limit])) + " …"
+}
+
+// patchFileRe matches file headers in apply_patch payloads
+var patchFileRe = regexp.MustCompile(`(?m)^\*\*\* (?:Add|Update|Delete) File: (.+)$`)
+
+// handoffModifiedFiles merges the uncommitted files in projectPath with the
+// files the transcript's edit tools touched, relative to projectPath
+func handoffModifiedFiles(projectPath string, messages []TranscriptMessage) []string {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(path string) {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			return
+		}
+		if filepath.IsAbs(path) {
+			if rel, err := filepath.Rel(projectPath, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	if git.IsGitRepo(projectPath) {
+		if stats, err := git.UncommittedFiles(projectPath); err == nil {
+			for _, f := range stats {
+				add(f.Path)
+			}
+		}
+	}
+	for _, m := range messages {
+		for _, call := range m.ToolCalls {
+			for _, path := range editedPaths(call) {
+				add(path)
+			}
+		}
+	}
+
+	sort.Strings(files)
+	if len(files) > handoffFilesLimit {
+		files = append(files[:handoffFilesLimit], fmt.Sprintf("… and %d more", len(files)-handoffFilesLimit))
+	}
+	return files
+}
+
+// editedPaths returns the files a write/edit/patch tool call touched
+func editedPaths(call TranscriptToolCall) []string {
+	name := strings.ToLower(call.Name)
+	if !strings.Contains(name, "edit") && !strings.Contains(name, "write") &&
+		!strings.Contains(name, "patch") && !strings.Contains(name, "replace") {
+		return nil
+	}
+	if strings.Contains(name, "todo") {
+		return nil
+	}
+
+	var patch string
+	if json.Unmarshal(call.Input, &patch) == nil {
+		return patchFiles(patch)
+	}
+	var args map[string]any
+	if json.Unmarshal(call.Input, &args) != nil {
+		return nil
+	}
+	for _, key := range []string{"file_path", "filePath", "notebook_path", "absolute_path", "path"} {
+		if path, ok := args[key].(string); ok && path != "" {
+			return []string{path}
+		}
+	}
+	for _, key := range []string{"input", "patch"} {
+		if patch, ok := args[key].(string); ok {
+			return patchFiles(patch)
+		}
+	}
+	return nil
+}
+
+func patchFiles(patch string) []string {
+	var files []string
+	for _, m := range patchFileRe.FindAllStringSubmatch(patch, -1) {
+		files = append(files, m[1])
+	}
+	return files
+}
+
+// handoffOpenTodos returns the unfinished items of the latest todo/plan tool
+// call (Claude TodoWrite, Codex update_plan, OpenCode todowrite, Gemini
+// write_todos), falling back to unchecked markdown boxes in the last reply
+func handoffOpenTodos(messages []TranscriptMessage) []string {
+	for k := len(messages) - 1; k >= 0; k-- {
+		calls := messages[k].ToolCalls
+		for c := len(calls) - 1; c >= 0; c-- {
+			name := strings.ToLower(calls[c].Name)
+			if !strings.Contains(name, "todo") && name != "update_plan" {
+				continue
+			}
+			if todos, ok := parseTodoInput(calls[c].Input); ok {
+				return todos
+			}
+		}
+	}
+
+	for k := len(messages) - 1; k >= 0; k-- {
+		if messages[k].Role != "assistant" || messages[k].Text == "" {
+			continue
+		}
+		var todos []string
+		for _, line := range strings.Split(messages[k].Text, "\n") {
+			line = strings.TrimSpace(line)
+			for _, prefix := range []string{"- [ ] ", "* [ ] "} {
+				if item, ok := strings.CutPrefix(line, prefix); ok {
+					todos = append(todos, strings.TrimSpace(item))
+				}
+			}
+		}
+		return todos
+	}
+	return nil
+}
+
+// parseTodoInput extracts unfinished items from a todo list argument
+// ({"todos": [...]} or {"plan": [...]})
+func parseTodoInput(input json.RawMessage) ([]string, bool) {
+	var args map[string]json.RawMessage
+	if json.Unmarshal(input, &args) != nil {
+		return nil, false
+	}
+	raw, ok := args["todos"]
+	if !ok {
+		if raw, ok = args["plan"]; !ok {
+			return nil, false
+		}
+	}
+	var items []map[string]any
+	if json.Unmarshal(raw, &items) != nil {
+		return nil, false
+	}
+
+	var todos []string
+	for _, item := range items {
+		status, _ := item["status"].(string)
+		switch strings.ToLower(status) {
+		case "completed", "done", "cancelled":
+			continue
+		}
+		for _, key := range []string{"content", "step", "description", "text", "title"} {
+			if text, ok := item[key].(string); ok && text != "" {
+				todos = append(todos, text)
+				break
+			}
+		}
+	}
+	return todos, true
+}
@@ -0,0 +1,135 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const codexHandoffRollout = `{"timestamp":"2025-10-01T10:00:00.000Z","type":"session_meta","payload":{"id":"0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b","cwd":"/tmp/project"}}
+{"timestamp":"2025-10-01T10:00:01.000Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"<environment_context>cwd</environment_context>"}]}}
+{"timestamp":"2025-10-01T10:00:02.000Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"add a --verbose flag"}]}}
+{"timestamp":"2025-10-01T10:00:05.000Z","type":"response_item","payload":{"type":"function_call","name":"update_plan","arguments":"{\"plan\":[{\"step\":\"parse flag\",\"status\":\"completed\"},{\"step\":\"wire logger\",\"status\":\"in_progress\"},{\"step\":\"update docs\",\"status\":\"pending\"}]}","call_id":"call_1"}}
+{"timestamp":"2025-10-01T10:00:09.000Z","type":"response_item","payload":{"type":"custom_tool_call","name":"apply_patch","input":"*** Begin Patch\n*** Update File: cmd/main.go\n@@\n-x\n+y\n*** Add File: internal/log/verbose.go\n+package log\n*** End Patch","call_id":"call_2"}}
+not json
+{"timestamp":"2025-10-01T10:00:10.000Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Flag parsed; the logger is next."}]}}
+`
+
+func TestReadCodexTranscript(t *testing.T) {
+	path := writeCodexRollout(t, t.TempDir(), "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b", codexHandoffRollout)
+
+	messages, err := readCodexTranscript(path)
+	if err != nil {
+		t.Fatalf("readCodexTranscript: %v", err)
+	}
+	if len(messages) != 4 {
+		t.Fatalf("got %d messages, want 4: %+v", len(messages), messages)
+	}
+	// Tool calls are grouped into one assistant message, followed by the reply
+	if messages[2].Role != "assistant" || len(messages[2].ToolCalls) != 2 || messages[2].ToolCalls[1].Name != "apply_patch" {
+		t.Errorf("tool call message = %+v", messages[2])
+	}
+	if messages[3].Text != "Flag parsed; the logger is next." {
+		t.Errorf("reply = %q", messages[3].Text)
+	}
+}
+
+func TestBuildHandoffPackage_Codex(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+	writeCodexRollout(t, codexHome, "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b", codexHandoffRollout)
+
+	inst := NewInstanceWithTool("verbose", t.TempDir(), "codex")
+	inst.CodexSessionID = "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b"
+
+	p, err := BuildHandoffPackage(inst, DefaultHandoffTurns)
+	if err != nil {
+		t.Fatalf("BuildHandoffPackage: %v", err)
+	}
+	if p.Goal != "add a --verbose flag" {
+		t.Errorf("Goal = %q (injected context should be skipped)", p.Goal)
+	}
+	if len(p.RecentTurns) != 2 {
+		t.Errorf("RecentTurns = %+v, want the request and the reply", p.RecentTurns)
+	}
+	if got := strings.Join(p.ModifiedFiles, ","); got != "cmd/main.go,internal/log/verbose.go" {
+		t.Errorf("ModifiedFiles = %q", got)
+	}
+	if got := strings.Join(p.OpenTodos, ","); got != "wire logger,update docs" {
+		t.Errorf("OpenTodos = %q", got)
+	}
+
+	prompt := p.Prompt()
+	for _, want := range []string{`Handoff from codex session "verbose"`, "## Goal", "add a --verbose flag", "- cmd/main.go", "- [ ] wire logger"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestReadClaudeTranscript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	jsonl := `{"uuid":"a","parentUuid":"","type":"user","message":{"role":"user","content":"rename Foo to Bar"},"timestamp":"2025-01-01T00:00:00Z"}
+{"uuid":"b","parentUuid":"a","type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Renaming."},{"type":"tool_use","name":"Edit","input":{"file_path":"/repo/foo.go"}},{"type":"tool_use","name":"TodoWrite","input":{"todos":[{"content":"rename tests","status":"pending"},{"content":"rename type","status":"completed"}]}}]},"timestamp":"2025-01-01T00:00:01Z"}
+{"uuid":"c","parentUuid":"b","type":"user","message":{"role":"user","content":[{"type":"tool_result","content":"ok"}]},"timestamp":"2025-01-01T00:00:02Z"}
+`
+	if err := os.WriteFile(path, []byte(jsonl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	messages, err := readClaudeTranscript(path)
+	if err != nil {
+		t.Fatalf("readClaudeTranscript: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want 2 (tool results are not turns): %+v", len(messages), messages)
+	}
+	if got := handoffModifiedFiles("/repo", messages); strings.Join(got, ",") != "foo.go" {
+		t.Errorf("modified files = %v", got)
+	}
+	if got := handoffOpenTodos(messages); strings.Join(got, ",") != "rename tests" {
+		t.Errorf("open todos = %v", got)
+	}
+}
+
+func TestHandoffOpenTodos_Checkboxes(t *testing.T) {
+	messages := []TranscriptMessage{
+		{Role: "user", Text: "plan it"},
+		{Role: "assistant", Text: "Plan:\n- [x] read code\n- [ ] write tests\n* [ ] ship"},
+	}
+	if got := handoffOpenTodos(messages); strings.Join(got, ",") != "write tests,ship" {
+		t.Errorf("open todos = %v", got)
+	}
+}
+
+func TestNewHandoffInstance(t *testing.T) {
+	src := NewInstanceWithTool("api", "/tmp/project", "claude")
+	src.GroupPath = "work"
+	src.WorktreePath = "/tmp/project"
+	src.WorktreeBranch = "feature/api"
+
+	h, err := src.NewHandoffInstance("codex", "api-codex")
+	if err != nil {
+		t.Fatalf("NewHandoffInstance: %v", err)
+	}
+	if h.Tool != "codex" || h.Command != "codex" || h.GroupPath != "work" || h.WorktreeBranch != "feature/api" {
+		t.Errorf("handoff = tool %q command %q group %q branch %q", h.Tool, h.Command, h.GroupPath, h.WorktreeBranch)
+	}
+	if h.ParentSessionID != src.ID {
+		t.Errorf("ParentSessionID = %q, want %q", h.ParentSessionID, src.ID)
+	}
+
+	// Sub-sessions hand off to a sibling
+	sibling, err := h.NewHandoffInstance("gemini", "api-gemini")
+	if err != nil {
+		t.Fatalf("NewHandoffInstance: %v", err)
+	}
+	if sibling.ParentSessionID != src.ID {
+		t.Errorf("ParentSessionID = %q, want %q", sibling.ParentSessionID, src.ID)
+	}
+
+	if _, err := src.NewHandoffInstance("shell", "x"); err == nil {
+		t.Error("expected error for a tool without an adapter")
+	}
+}
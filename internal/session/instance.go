@@ -36,6 +36,12 @@ const (
 	StatusStarting Status = "starting" // Session is being created (tmux initializing)
 )
 
+// IsTurnEnd reports whether a status change marks the end of an agent turn:
+// running to waiting or idle.
+func IsTurnEnd(from, to Status) bool {
+	return from == StatusRunning && (to == StatusWaiting || to == StatusIdle)
+}
+
 const wrapperPlaceholder = "{command}"
 
 const (
@@ -465,9 +471,23 @@ func (i *Instance) buildClaudeExtraFlags(opts *ClaudeOptions) string {
 	}
 
 	if len(flags) == 0 {
+		return claudeLaunchFlags(opts)
+	}
+	return " " + strings.Join(flags, " ") + claudeLaunchFlags(opts)
+}
+
+// claudeLaunchFlags returns ClaudeOptions.LaunchArgs shell-quoted for
+// embedding in a command string (with a leading space), or "" if none.
+func claudeLaunchFlags(opts *ClaudeOptions) string {
+	if opts == nil {
 		return ""
 	}
-	return " " + strings.Join(flags, " ")
+	var b strings.Builder
+	for _, arg := range opts.LaunchArgs() {
+		b.WriteString(" ")
+		b.WriteString(shellQuote(arg))
+	}
+	return b.String()
 }
 
 // buildGeminiCommand builds the gemini command with session capture
@@ -594,25 +614,25 @@ func (i *Instance) DetectOpenCodeSession() {
 	i.detectOpenCodeSessionAsync()
 }
 
-// buildCodexCommand builds the command for OpenAI Codex CLI
-// resolveCodexYoloFlag returns " --yolo" if yolo mode is enabled (per-session override > global config), or "".
-func (i *Instance) resolveCodexYoloFlag() string {
-	opts := i.GetCodexOptions()
-	if opts != nil && opts.YoloMode != nil {
-		if *opts.YoloMode {
-			return " --yolo"
-		}
-		return ""
-	}
-	// Fallback to global config
-	if config, err := LoadUserConfig(); err == nil && config != nil {
-		if config.Codex.YoloMode {
-			return " --yolo"
-		}
+// ResolveCodexOptions returns the effective Codex options: per-session
+// overrides > [codex.groups] defaults for the session's group > [codex]
+func (i *Instance) ResolveCodexOptions() *CodexOptions {
+	config, _ := LoadUserConfig()
+	return NewCodexOptionsForGroup(config, i.GroupPath).Merge(i.GetCodexOptions())
+}
+
+// resolveCodexFlags returns the resolved Codex options as a shell-quoted
+// flag string with a leading space, or ""
+func (i *Instance) resolveCodexFlags() string {
+	var b strings.Builder
+	for _, arg := range i.ResolveCodexOptions().ToArgs() {
+		b.WriteString(" ")
+		b.WriteString(shellQuote(arg))
 	}
-	return ""
+	return b.String()
 }
 
+// buildCodexCommand builds the command for OpenAI Codex CLI
 // Codex stores sessions in ~/.codex/sessions/YYYY/MM/DD/*.jsonl
 // Resume: codex resume <session-id> or codex resume --last
 // Also sources .env files from [shell].env_files
@@ -622,22 +642,28 @@ func (i *Instance) buildCodexCommand(baseCommand string) string {
 	}
 
 	envPrefix := i.buildEnvSourceCommand()
+	// LOCAL MCPs live in a per-session CODEX_HOME. Exported rather than
+	// prefixed so it survives the `;` in the resume command.
+	if home := RefreshCodexSessionHome(i.ID); home != "" {
+		envPrefix += fmt.Sprintf("export CODEX_HOME=%q; ", home)
+	}
 	agentdeckEnvPrefix := fmt.Sprintf("AGENTDECK_INSTANCE_ID=%s AGENTDECK_TITLE=%q AGENTDECK_TOOL=%s ",
 		i.ID, i.Title, i.Tool)
 	envPrefix += agentdeckEnvPrefix
 
-	yoloFlag := i.resolveCodexYoloFlag()
+	// Options go before the resume subcommand so restarts keep them
+	codexFlags := i.resolveCodexFlags()
 
 	// If baseCommand is just "codex", handle specially
 	if baseCommand == "codex" {
 		// If we already have a session ID, use resume
 		if i.CodexSessionID != "" {
 			return envPrefix + fmt.Sprintf("tmux set-environment CODEX_SESSION_ID %s; codex%s resume %s",
-				i.CodexSessionID, yoloFlag, i.CodexSessionID)
+				i.CodexSessionID, codexFlags, i.CodexSessionID)
 		}
 
 		// Start Codex fresh - session ID will be captured async after startup
-		return envPrefix + "codex" + yoloFlag
+		return envPrefix + "codex" + codexFlags
 	}
 
 	// For custom commands (e.g., resume commands), preserve env propagation.
@@ -1048,6 +1074,13 @@ func decodeJSONStringField(raw map[string]json.RawMessage, key string) string {
 // collectOtherCodexSessionIDs enumerates other managed tmux sessions and returns
 // the CODEX_SESSION_ID values they currently own.
 func (i *Instance) collectOtherCodexSessionIDs() map[string]bool {
+	return i.collectOtherSessionIDs("CODEX_SESSION_ID")
+}
+
+// collectOtherSessionIDs returns the conversation IDs that other agent-deck
+// tmux sessions hold in envVar, so disk scans don't adopt a sibling's (or a
+// fork's) conversation
+func (i *Instance) collectOtherSessionIDs(envVar string) map[string]bool {
 	exclude := make(map[string]bool)
 
 	tmuxSessions, err := tmux.ListAgentDeckSessions()
@@ -1065,7 +1098,7 @@ func (i *Instance) collectOtherCodexSessionIDs() map[string]bool {
 			continue
 		}
 		other := &tmux.Session{Name: sessName}
-		if id, err := other.GetEnvironment("CODEX_SESSION_ID"); err == nil && id != "" {
+		if id, err := other.GetEnvironment(envVar); err == nil && id != "" {
 			exclude[id] = true
 		}
 	}
@@ -1119,6 +1152,11 @@ func (i *Instance) UpdateCodexSession(excludeIDs map[string]bool) {
 
 	if sessionID := i.queryCodexSession(excludeIDs, allowUnscoped); sessionID != "" {
 		changed := sessionID != i.CodexSessionID
+		// A newer rollout in the project may belong to another session (e.g.
+		// a fork of this one); only rotate to unclaimed conversations
+		if changed && i.CodexSessionID != "" && i.collectOtherCodexSessionIDs()[sessionID] {
+			return
+		}
 		if sessionID != i.CodexSessionID {
 			sessionLog.Debug("codex_session_update", slog.String("old_id", i.CodexSessionID), slog.String("new_id", sessionID))
 		}
@@ -1232,15 +1270,10 @@ func (i *Instance) GetGenericSessionID() string {
 
 // CanRestartGeneric returns true if a custom tool can be restarted with session resume
 func (i *Instance) CanRestartGeneric() bool {
-	toolDef := GetToolDef(i.Tool)
-	if toolDef == nil {
-		return false
-	}
-	// Can restart if we have resume support AND an existing session ID
-	if toolDef.ResumeFlag == "" || toolDef.SessionIDEnv == "" {
-		return false
-	}
-	return i.GetGenericSessionID() != ""
+	// Can restart if we have resume support (resume_flag or adapter
+	// resume_command) AND an existing session ID
+	adapter, ok := GetToolAdapter(i.Tool).(*declarativeAdapter)
+	return ok && adapter.CanResume(i)
 }
 
 func (i *Instance) applyWrapper(command string) (string, error) {
@@ -1285,36 +1318,31 @@ func (i *Instance) loadCustomPatternsFromConfig() {
 	}
 }
 
+// buildStartCommand builds the launch command through the tool's adapter.
+// Commands without an adapter (shell, plain custom commands) run as-is.
+func (i *Instance) buildStartCommand() string {
+	if adapter := GetToolAdapter(i.Tool); adapter != nil {
+		return adapter.BuildCommand(i, i.Command)
+	}
+	return i.Command
+}
+
+// discoverToolSession starts the adapter's background session ID discovery
+func (i *Instance) discoverToolSession() {
+	if adapter := GetToolAdapter(i.Tool); adapter != nil {
+		adapter.DiscoverSession(i)
+	}
+}
+
 // Start starts the session in tmux
 func (i *Instance) Start() error {
 	if i.tmuxSession == nil {
 		return fmt.Errorf("tmux session not initialized")
 	}
 
-	// Build command based on tool type
-	// Priority: built-in tools (claude, gemini, opencode, codex) → custom tools from config.toml → raw command
-	var command string
-	switch i.Tool {
-	case "claude":
-		command = i.buildClaudeCommand(i.Command)
-	case "gemini":
-		command = i.buildGeminiCommand(i.Command)
-	case "opencode":
-		command = i.buildOpenCodeCommand(i.Command)
-		// Record start time for session ID detection (Unix millis)
-		i.OpenCodeStartedAt = time.Now().UnixMilli()
-	case "codex":
-		command = i.buildCodexCommand(i.Command)
-		// Record start time for session ID detection (Unix millis)
-		i.CodexStartedAt = time.Now().UnixMilli()
-	default:
-		// Check if this is a custom tool with session resume config
-		if toolDef := GetToolDef(i.Tool); toolDef != nil {
-			command = i.buildGenericCommand(i.Command)
-		} else {
-			command = i.Command
-		}
-	}
+	// Build command through the tool's adapter (built-in or [tools.<name>]);
+	// plain commands run as-is
+	command := i.buildStartCommand()
 
 	var err error
 	command, err = i.applyWrapper(command)
@@ -1353,17 +1381,8 @@ func (i *Instance) Start() error {
 		i.Status = StatusStarting
 	}
 
-	// Start async session ID detection for OpenCode
-	// This runs in background and captures the session ID once OpenCode creates it
-	if i.Tool == "opencode" {
-		go i.detectOpenCodeSessionAsync()
-	}
-
-	// Start async session ID detection for Codex
-	// This runs in background and captures the session ID once Codex creates it
-	if i.Tool == "codex" {
-		go i.detectCodexSessionAsync()
-	}
+	// Start async session ID detection for tools that persist IDs out-of-band
+	i.discoverToolSession()
 
 	return nil
 }
@@ -1378,27 +1397,7 @@ func (i *Instance) StartWithMessage(message string) error {
 	}
 
 	// Start session normally (no embedded message logic)
-	// Priority: built-in tools (claude, gemini, opencode, codex) → custom tools from config.toml → raw command
-	var command string
-	switch i.Tool {
-	case "claude":
-		command = i.buildClaudeCommand(i.Command)
-	case "gemini":
-		command = i.buildGeminiCommand(i.Command)
-	case "opencode":
-		command = i.buildOpenCodeCommand(i.Command)
-		i.OpenCodeStartedAt = time.Now().UnixMilli()
-	case "codex":
-		command = i.buildCodexCommand(i.Command)
-		i.CodexStartedAt = time.Now().UnixMilli()
-	default:
-		// Check if this is a custom tool with session resume config
-		if toolDef := GetToolDef(i.Tool); toolDef != nil {
-			command = i.buildGenericCommand(i.Command)
-		} else {
-			command = i.Command
-		}
-	}
+	command := i.buildStartCommand()
 
 	var err error
 	command, err = i.applyWrapper(command)
@@ -1435,12 +1434,7 @@ func (i *Instance) StartWithMessage(message string) error {
 	i.Status = StatusStarting
 
 	// Start async session ID detection for tools that persist IDs out-of-band.
-	if i.Tool == "opencode" {
-		go i.detectOpenCodeSessionAsync()
-	}
-	if i.Tool == "codex" {
-		go i.detectCodexSessionAsync()
-	}
+	i.discoverToolSession()
 
 	// Send message synchronously (CLI will wait)
 	if message != "" {
@@ -1450,6 +1444,13 @@ func (i *Instance) StartWithMessage(message string) error {
 	return nil
 }
 
+// SendMessageWhenReady waits for an already started session's agent to be
+// ready and sends message. Like StartWithMessage it blocks until the message
+// is delivered, so callers that must stay responsive run it in a goroutine.
+func (i *Instance) SendMessageWhenReady(message string) error {
+	return i.sendMessageWhenReady(message)
+}
+
 // sendMessageWhenReady waits for the agent to be ready and sends the message
 // Uses the existing status detection system which is robust and works for all tools
 //
@@ -2200,7 +2201,7 @@ func (i *Instance) UpdateGeminiSession(excludeIDs map[string]bool) {
 		return
 	}
 	i.syncGeminiSessionFromTmux()
-	i.syncGeminiSessionFromDisk()
+	i.syncGeminiSessionFromDisk(excludeIDs)
 	i.updateGeminiAnalytics()
 	i.updateGeminiLatestPrompt()
 }
@@ -2226,14 +2227,37 @@ func (i *Instance) syncGeminiSessionFromTmux() {
 
 // syncGeminiSessionFromDisk scans the filesystem for the most recent session.
 // Krudony fix: user may have started a NEW session, so always scan rather than using stale cached ID.
-func (i *Instance) syncGeminiSessionFromDisk() {
+func (i *Instance) syncGeminiSessionFromDisk(excludeIDs map[string]bool) {
 	sessions, err := ListGeminiSessions(i.ProjectPath)
 	if err != nil || len(sessions) == 0 {
 		return
 	}
 
-	// Pick the most recent session (list is sorted by LastUpdated desc)
-	mostRecent := sessions[0]
+	// Pick the most recent session (list is sorted by LastUpdated desc) that
+	// is ours or unclaimed: other sessions in the project (e.g. forks of this
+	// one) hold theirs in GEMINI_SESSION_ID. The tmux lookup only runs when
+	// the newest chat is not already ours.
+	var mostRecent *GeminiSessionInfo
+	var claimed map[string]bool
+	for idx := range sessions {
+		candidate := &sessions[idx]
+		if candidate.SessionID != i.GeminiSessionID {
+			if excludeIDs[candidate.SessionID] {
+				continue
+			}
+			if claimed == nil {
+				claimed = i.collectOtherSessionIDs("GEMINI_SESSION_ID")
+			}
+			if claimed[candidate.SessionID] {
+				continue
+			}
+		}
+		mostRecent = candidate
+		break
+	}
+	if mostRecent == nil {
+		return
+	}
 	if mostRecent.SessionID != i.GeminiSessionID {
 		sessionLog.Debug("gemini_session_update", slog.String("old_id", i.GeminiSessionID), slog.String("new_id", mostRecent.SessionID))
 	}
@@ -2442,11 +2466,8 @@ type ResponseOutput struct {
 // For Gemini: Parses the JSON session file for the last assistant message
 // For Codex/Others: Attempts to parse terminal output
 func (i *Instance) GetLastResponse() (*ResponseOutput, error) {
-	if i.Tool == "claude" {
-		return i.getClaudeLastResponse()
-	}
-	if i.Tool == "gemini" {
-		return i.getGeminiLastResponse()
+	if adapter := GetToolAdapter(i.Tool); adapter != nil {
+		return adapter.LastResponse(i)
 	}
 	return i.getTerminalLastResponse()
 }
@@ -2846,24 +2867,11 @@ func (i *Instance) getGeminiLastResponse() (*ResponseOutput, error) {
 		return nil, fmt.Errorf("no Gemini session ID available for this instance")
 	}
 
-	sessionsDir := GetGeminiSessionsDir(i.ProjectPath)
-
-	// Find file by session ID (first 8 chars in filename)
-	// Filename format is session-YYYY-MM-DDTHH-MM-<uuid8>.json
-	pattern := filepath.Join(sessionsDir, "session-*-"+i.GeminiSessionID[:8]+".json")
-	files, _ := filepath.Glob(pattern)
-
-	// Fallback: cross-project search if not found in expected location
-	if len(files) == 0 {
-		if fallbackPath := findGeminiSessionInAllProjects(i.GeminiSessionID); fallbackPath != "" {
-			files = []string{fallbackPath}
-		}
-	}
-
-	if len(files) == 0 {
+	// Find file by session ID, with cross-project fallback
+	sessionFile := findGeminiSessionFile(i.ProjectPath, i.GeminiSessionID)
+	if sessionFile == "" {
 		return nil, fmt.Errorf("session file not found for ID: %s", i.GeminiSessionID)
 	}
-	sessionFile := files[0]
 
 	// Read and parse the JSON file
 	data, err := os.ReadFile(sessionFile)
@@ -3068,9 +3076,9 @@ func (i *Instance) Kill() error {
 	return nil
 }
 
-// Restart restarts the Claude session
-// For Claude sessions with known ID: sends Ctrl+C twice and resume command to existing session
-// For dead sessions or unknown ID: recreates the tmux session
+// Restart restarts the session
+// When the tool's adapter can resume and the tmux session exists: respawns the pane with the resume command
+// For dead sessions or nothing to resume: recreates the tmux session
 func (i *Instance) Restart() error {
 	mcpLog.Debug("restart_called", slog.String("tool", i.Tool), slog.String("claude_session_id", i.ClaudeSessionID), slog.Bool("tmux_session", i.tmuxSession != nil), slog.Bool("tmux_exists", i.tmuxSession != nil && i.tmuxSession.Exists()))
 
@@ -3085,186 +3093,51 @@ func (i *Instance) Restart() error {
 			mcpLog.Warn("mcp_config_regen_failed", slog.String("error", err.Error()))
 			// Continue with restart - Claude will use existing .mcp.json or defaults
 		}
+	} else if i.Tool == "opencode" && !skipRegen {
+		// Codex regenerates its session CODEX_HOME when building the command
+		if local := i.LocalMCPNames(); len(local) > 0 {
+			if err := WriteOpenCodeLocalMCP(i.ProjectPath, local); err != nil {
+				mcpLog.Warn("mcp_config_regen_failed", slog.String("error", err.Error()))
+			}
+		}
 	} else if skipRegen {
 		mcpLog.Debug("mcp_regen_skipped", slog.String("reason", "flag_set_by_apply"))
 	}
 
-	// Sync Claude session from disk before restart to pick up /clear session changes
-	if i.Tool == "claude" {
-		i.syncClaudeSessionFromDisk()
+	// The adapter refreshes the tracked conversation and builds the command
+	// that resumes it; a running pane is respawned in place with it
+	adapter := GetToolAdapter(i.Tool)
+	var resumeCmd string
+	canResume := false
+	if adapter != nil {
+		resumeCmd, canResume = adapter.ResumeCommand(i)
 	}
 
-	// If Claude session with known ID AND tmux session exists, use respawn-pane
-	if i.Tool == "claude" && i.ClaudeSessionID != "" && i.tmuxSession != nil && i.tmuxSession.Exists() {
-		// Build the resume command with proper config
-		resumeCmd := i.buildClaudeResumeCommand()
-		resumeCmd, err := i.applyWrapper(resumeCmd)
+	if canResume && i.tmuxSession != nil && i.tmuxSession.Exists() {
+		respawnCmd, err := i.applyWrapper(resumeCmd)
 		if err != nil {
 			return err
 		}
-		mcpLog.Debug("respawn_pane_claude", slog.String("command", resumeCmd))
+		sessionLog.Info("restart_respawn", slog.String("tool", i.Tool), slog.String("command", respawnCmd))
 
-		// Use respawn-pane for atomic restart
-		// This is more reliable than Ctrl+C + wait for shell + send command
-		// respawn-pane -k kills the current process and starts the new command atomically
-		if err := i.tmuxSession.RespawnPane(resumeCmd); err != nil {
-			mcpLog.Debug("respawn_pane_claude_failed", slog.String("error", err.Error()))
-			return fmt.Errorf("failed to restart Claude session: %w", err)
+		// respawn-pane -k kills the current process and starts the new
+		// command atomically, which is more reliable than Ctrl+C + wait for
+		// the shell + send the command
+		if err := i.tmuxSession.RespawnPane(respawnCmd); err != nil {
+			sessionLog.Info("restart_respawn_failed", slog.String("tool", i.Tool), slog.String("error", err.Error()))
+			return fmt.Errorf("failed to restart %s session: %w", i.Tool, err)
 		}
+		sessionLog.Info("restart_respawn_succeeded", slog.String("tool", i.Tool))
 
-		mcpLog.Debug("respawn_pane_claude_succeeded")
-
+		// Tools that restarted fresh look for their new conversation again
+		if adapter.SessionID(i) == "" {
+			adapter.DiscoverSession(i)
+		}
 		// Re-capture MCPs after restart (they may have changed since session started)
 		i.CaptureLoadedMCPs()
+		i.loadCustomPatternsFromConfig()
 
-		// Start as WAITING - will go GREEN on next tick if Claude shows busy indicator
-		i.Status = StatusWaiting
-		return nil
-	}
-
-	// For Gemini: ALWAYS update session to get the most recent one
-	// Krudony fix: don't skip when we already have an ID - the user may have started a NEW session
-	if i.Tool == "gemini" {
-		i.UpdateGeminiSession(nil)
-	}
-
-	// If Gemini session with known ID AND tmux session exists, use respawn-pane
-	if i.Tool == "gemini" && i.GeminiSessionID != "" && i.tmuxSession != nil && i.tmuxSession.Exists() {
-		resumeCmd := i.buildGeminiCommand("gemini")
-		resumeCmd, err := i.applyWrapper(resumeCmd)
-		if err != nil {
-			return err
-		}
-		sessionLog.Info("restart_gemini_respawn", slog.String("command", resumeCmd))
-
-		if err := i.tmuxSession.RespawnPane(resumeCmd); err != nil {
-			sessionLog.Info("restart_gemini_respawn_failed", slog.String("error", err.Error()))
-			return fmt.Errorf("failed to restart Gemini session: %w", err)
-		}
-
-		sessionLog.Info("restart_gemini_respawn_succeeded")
-		i.Status = StatusWaiting
-		return nil
-	}
-
-	// If OpenCode session AND tmux session exists, use respawn-pane
-	if i.Tool == "opencode" && i.tmuxSession != nil && i.tmuxSession.Exists() {
-		// Try to get session ID from tmux environment if not already set
-		// (async detection stores it there but Instance might not have been saved)
-		if i.OpenCodeSessionID == "" {
-			if envID, err := i.tmuxSession.GetEnvironment("OPENCODE_SESSION_ID"); err == nil && envID != "" {
-				i.OpenCodeSessionID = envID
-				i.OpenCodeDetectedAt = time.Now()
-				sessionLog.Info("restart_opencode_recovered_id", slog.String("session_id", envID))
-			}
-		}
-
-		var resumeCmd string
-		if i.OpenCodeSessionID != "" {
-			// Resume with known session ID
-			resumeCmd = fmt.Sprintf("tmux set-environment OPENCODE_SESSION_ID %s && opencode -s %s",
-				i.OpenCodeSessionID, i.OpenCodeSessionID)
-		} else {
-			// No session ID yet, start fresh (will detect ID async)
-			resumeCmd = "opencode"
-			// Re-record start time for async detection
-			i.OpenCodeStartedAt = time.Now().UnixMilli()
-		}
-		resumeCmd, err := i.applyWrapper(resumeCmd)
-		if err != nil {
-			return err
-		}
-		sessionLog.Info("restart_opencode_respawn", slog.String("command", resumeCmd))
-
-		if err := i.tmuxSession.RespawnPane(resumeCmd); err != nil {
-			sessionLog.Info("restart_opencode_respawn_failed", slog.String("error", err.Error()))
-			return fmt.Errorf("failed to restart OpenCode session: %w", err)
-		}
-
-		// If no session ID, start async detection
-		if i.OpenCodeSessionID == "" {
-			go i.detectOpenCodeSessionAsync()
-		}
-
-		sessionLog.Info("restart_opencode_respawn_succeeded")
-		i.Status = StatusWaiting
-		return nil
-	}
-
-	// For Codex: ALWAYS update session to get the most recent one
-	// Krudony fix: don't skip when we already have an ID - the user may have started a NEW session
-	if i.Tool == "codex" {
-		i.UpdateCodexSession(nil)
-	}
-
-	// If Codex session AND tmux session exists, use respawn-pane
-	if i.Tool == "codex" && i.tmuxSession != nil && i.tmuxSession.Exists() {
-		// Try to get session ID from tmux environment if not already set
-		if i.CodexSessionID == "" {
-			if envID, err := i.tmuxSession.GetEnvironment("CODEX_SESSION_ID"); err == nil && envID != "" {
-				i.CodexSessionID = envID
-				i.CodexDetectedAt = time.Now()
-				sessionLog.Info("restart_codex_recovered_id", slog.String("session_id", envID))
-			}
-		}
-
-		resumeCmd := i.buildCodexCommand("codex")
-		if i.CodexSessionID == "" {
-			// No session ID yet, start fresh (will detect ID async)
-			// Re-record start time for async detection
-			i.CodexStartedAt = time.Now().UnixMilli()
-		}
-		resumeCmd, err := i.applyWrapper(resumeCmd)
-		if err != nil {
-			return err
-		}
-		sessionLog.Info("restart_codex_respawn", slog.String("command", resumeCmd))
-
-		if err := i.tmuxSession.RespawnPane(resumeCmd); err != nil {
-			sessionLog.Info("restart_codex_respawn_failed", slog.String("error", err.Error()))
-			return fmt.Errorf("failed to restart Codex session: %w", err)
-		}
-
-		// If no session ID, start async detection
-		if i.CodexSessionID == "" {
-			go i.detectCodexSessionAsync()
-		}
-
-		sessionLog.Info("restart_codex_respawn_succeeded")
-		i.Status = StatusWaiting
-		return nil
-	}
-
-	// If custom tool with session resume support AND tmux session exists, use respawn-pane
-	if i.CanRestartGeneric() && i.tmuxSession != nil && i.tmuxSession.Exists() {
-		toolDef := GetToolDef(i.Tool)
-		sessionID := i.GetGenericSessionID()
-
-		// Build resume command for custom tool
-		var resumeCmd string
-		if toolDef.DangerousMode && toolDef.DangerousFlag != "" {
-			resumeCmd = fmt.Sprintf("tmux set-environment %s %s && %s %s %s %s",
-				toolDef.SessionIDEnv, sessionID,
-				i.Command, toolDef.ResumeFlag, sessionID, toolDef.DangerousFlag)
-		} else {
-			resumeCmd = fmt.Sprintf("tmux set-environment %s %s && %s %s %s",
-				toolDef.SessionIDEnv, sessionID,
-				i.Command, toolDef.ResumeFlag, sessionID)
-		}
-		resumeCmd, err := i.applyWrapper(resumeCmd)
-		if err != nil {
-			return err
-		}
-
-		sessionLog.Info("restart_generic_respawn", slog.String("tool", i.Tool), slog.String("command", resumeCmd))
-
-		if err := i.tmuxSession.RespawnPane(resumeCmd); err != nil {
-			sessionLog.Info("restart_generic_respawn_failed", slog.String("tool", i.Tool), slog.String("error", err.Error()))
-			return fmt.Errorf("failed to restart %s session: %w", i.Tool, err)
-		}
-
-		sessionLog.Info("restart_generic_respawn_succeeded", slog.String("tool", i.Tool))
-		i.loadCustomPatternsFromConfig() // Reload custom patterns
+		// Start as WAITING - will go GREEN on next tick if the agent shows busy indicator
 		i.Status = StatusWaiting
 		return nil
 	}
@@ -3284,40 +3157,10 @@ func (i *Instance) Restart() error {
 	i.tmuxSession.InstanceID = i.ID // Pass instance ID for activity hooks
 	i.tmuxSession.SetInjectStatusLine(GetTmuxSettings().GetInjectStatusLine())
 
-	var command string
-	if i.Tool == "claude" && i.ClaudeSessionID != "" {
-		command = i.buildClaudeResumeCommand()
-	} else if i.Tool == "gemini" && i.GeminiSessionID != "" {
-		command = i.buildGeminiCommand("gemini")
-	} else if i.Tool == "opencode" && i.OpenCodeSessionID != "" {
-		// Set OPENCODE_SESSION_ID in tmux env so detection works after restart
-		command = fmt.Sprintf("tmux set-environment OPENCODE_SESSION_ID %s && opencode -s %s",
-			i.OpenCodeSessionID, i.OpenCodeSessionID)
-	} else if i.Tool == "codex" && i.CodexSessionID != "" {
-		command = i.buildCodexCommand("codex")
-	} else {
-		// Route to appropriate command builder based on tool
-		switch i.Tool {
-		case "claude":
-			command = i.buildClaudeCommand(i.Command)
-		case "gemini":
-			command = i.buildGeminiCommand(i.Command)
-		case "opencode":
-			command = i.buildOpenCodeCommand(i.Command)
-			// Record start time for async session ID detection
-			i.OpenCodeStartedAt = time.Now().UnixMilli()
-		case "codex":
-			command = i.buildCodexCommand(i.Command)
-			// Record start time for async session ID detection
-			i.CodexStartedAt = time.Now().UnixMilli()
-		default:
-			// Check if this is a custom tool with session resume config
-			if toolDef := GetToolDef(i.Tool); toolDef != nil {
-				command = i.buildGenericCommand(i.Command)
-			} else {
-				command = i.Command
-			}
-		}
+	command := resumeCmd
+	if !canResume {
+		// Route to the tool's adapter (built-in or custom) for a fresh start
+		command = i.buildStartCommand()
 	}
 	command, err := i.applyWrapper(command)
 	if err != nil {
@@ -3351,14 +3194,9 @@ func (i *Instance) Restart() error {
 	// Re-capture MCPs after restart
 	i.CaptureLoadedMCPs()
 
-	// Start async session ID detection for OpenCode (if no ID yet)
-	if i.Tool == "opencode" && i.OpenCodeSessionID == "" {
-		go i.detectOpenCodeSessionAsync()
-	}
-
-	// Start async session ID detection for Codex (if no ID yet)
-	if i.Tool == "codex" && i.CodexSessionID == "" {
-		go i.detectCodexSessionAsync()
+	// Start async session ID detection (if no ID yet)
+	if adapter := GetToolAdapter(i.Tool); adapter != nil && adapter.SessionID(i) == "" {
+		adapter.DiscoverSession(i)
 	}
 
 	// Start as WAITING - will go GREEN on next tick if Claude shows busy indicator
@@ -3409,23 +3247,25 @@ func (i *Instance) buildClaudeResumeCommand() string {
 	sessionLog.Debug("session_data_build_resume", slog.String("session_id", i.ClaudeSessionID), slog.String("path", i.ProjectPath), slog.Bool("use_resume", useResume))
 
 	// Build dangerous mode flag (--dangerously-skip-permissions wins over --allow-...)
-	dangerousFlag := ""
+	extraFlags := ""
 	if dangerousMode {
-		dangerousFlag = " --dangerously-skip-permissions"
+		extraFlags = " --dangerously-skip-permissions"
 	} else if allowDangerousMode {
-		dangerousFlag = " --allow-dangerously-skip-permissions"
+		extraFlags = " --allow-dangerously-skip-permissions"
 	}
+	// Model, permission mode, tool filters etc. survive restart
+	extraFlags += claudeLaunchFlags(opts)
 
 	// Build the command with tmux environment update
 	// This ensures CLAUDE_SESSION_ID is set in tmux env after restart,
 	// so GetSessionIDFromTmux() works correctly and detects the session
 	if useResume {
 		return fmt.Sprintf("tmux set-environment CLAUDE_SESSION_ID %s && %s%s --resume %s%s",
-			i.ClaudeSessionID, configDirPrefix, claudeCmd, i.ClaudeSessionID, dangerousFlag)
+			i.ClaudeSessionID, configDirPrefix, claudeCmd, i.ClaudeSessionID, extraFlags)
 	}
 	// Session was never interacted with - use --session-id to create fresh session
 	return fmt.Sprintf("tmux set-environment CLAUDE_SESSION_ID %s && %s%s --session-id %s%s",
-		i.ClaudeSessionID, configDirPrefix, claudeCmd, i.ClaudeSessionID, dangerousFlag)
+		i.ClaudeSessionID, configDirPrefix, claudeCmd, i.ClaudeSessionID, extraFlags)
 }
 
 // SetGeminiModel sets the Gemini model for this session and triggers a restart if running.
@@ -3440,72 +3280,33 @@ func (i *Instance) SetGeminiModel(model string) error {
 	return nil
 }
 
-// CanRestart returns true if the session can be restarted
-// For Claude sessions with known ID: can always restart (interrupt and resume)
-// For Gemini sessions with known ID: can always restart (interrupt and resume)
-// For OpenCode sessions with known ID: can always restart (interrupt and resume)
-// For Codex sessions with known ID: can always restart (interrupt and resume)
-// For custom tools with session resume config: can restart if session ID available
-// For other sessions: only if dead/error state
+// CanRestart returns true if the session can be restarted: always when the
+// tool's adapter can resume it in place, otherwise only if dead/error
 func (i *Instance) CanRestart() bool {
-	// Gemini sessions with known session ID can always be restarted
-	if i.Tool == "gemini" && i.GeminiSessionID != "" {
-		return true
-	}
-
-	// Claude sessions with known session ID can always be restarted
-	if i.Tool == "claude" && i.ClaudeSessionID != "" {
-		return true
-	}
-
-	// OpenCode sessions with known session ID can always be restarted
-	if i.Tool == "opencode" && i.OpenCodeSessionID != "" {
+	if adapter := GetToolAdapter(i.Tool); adapter != nil && adapter.CanResume(i) {
 		return true
 	}
-
-	// OpenCode sessions without ID can still restart (will start fresh)
-	// This allows restart even before session ID is detected
-	if i.Tool == "opencode" {
-		return true
-	}
-
-	// Codex sessions with known session ID can always be restarted
-	if i.Tool == "codex" && i.CodexSessionID != "" {
-		return true
-	}
-
-	// Codex sessions without ID can still restart (will start fresh)
-	// This allows restart even before session ID is detected
-	if i.Tool == "codex" {
-		return true
-	}
-
-	// Custom tools: check if they have session resume support
-	if i.CanRestartGeneric() {
-		return true
-	}
-
-	// Other sessions: only if dead or error
 	return i.Status == StatusError || i.tmuxSession == nil || !i.tmuxSession.Exists()
 }
 
 // CanFork returns true if this session can be forked
 func (i *Instance) CanFork() bool {
-	// Gemini CLI doesn't support forking
-	if i.Tool == "gemini" {
-		return false
-	}
-
-	// OpenCode sessions can fork if session ID is recent
-	if i.Tool == "opencode" {
-		return i.CanForkOpenCode()
+	if adapter := GetToolAdapter(i.Tool); adapter != nil {
+		return adapter.CanFork(i)
 	}
+	// Sessions without an adapter can fork if Claude runs in them with a recent ID
+	return claudeAdapter{}.CanFork(i)
+}
 
-	// Claude sessions can fork if session ID is recent
-	if i.ClaudeSessionID == "" {
-		return false
+// ForkWithAdapter creates a new, not yet started instance forked through the
+// tool's adapter; opts (may be nil) places it in a worktree. Claude callers
+// that need model or permission options use CreateForkedInstanceWithOptions.
+func (i *Instance) ForkWithAdapter(newTitle, newGroupPath string, opts *ForkOptions) (*Instance, error) {
+	adapter := GetToolAdapter(i.Tool)
+	if adapter == nil {
+		adapter = claudeAdapter{}
 	}
-	return time.Since(i.ClaudeDetectedAt) < 5*time.Minute
+	return adapter.Fork(i, newTitle, newGroupPath, opts)
 }
 
 // CanForkOpenCode returns true if this OpenCode session can be forked
@@ -3590,8 +3391,25 @@ func (i *Instance) CreateForkedInstance(newTitle, newGroupPath string) (*Instanc
 	return i.CreateForkedInstanceWithOptions(newTitle, newGroupPath, nil)
 }
 
+// ForkClaudeOptions returns a copy of this session's Claude options for a
+// fork, with session mode reset, or config defaults if none are stored
+func (i *Instance) ForkClaudeOptions() *ClaudeOptions {
+	opts := i.GetClaudeOptions()
+	if opts == nil {
+		userConfig, _ := LoadUserConfig()
+		return NewClaudeOptions(userConfig)
+	}
+	opts.SessionMode = "new"
+	opts.ResumeSessionID = ""
+	return opts
+}
+
 // CreateForkedInstanceWithOptions creates a new Instance configured for forking with custom options
 func (i *Instance) CreateForkedInstanceWithOptions(newTitle, newGroupPath string, opts *ClaudeOptions) (*Instance, string, error) {
+	// Without explicit options the fork inherits the parent's model, permission mode etc.
+	if opts == nil && i.GetClaudeOptions() != nil {
+		opts = i.ForkClaudeOptions()
+	}
 	cmd, err := i.ForkWithOptions(newTitle, newGroupPath, opts)
 	if err != nil {
 		return nil, "", err
@@ -3751,6 +3569,71 @@ func (i *Instance) CreateForkedOpenCodeInstanceWithOptions(newTitle, newGroupPat
 	return forked, cmd, nil
 }
 
+// CanForkGemini returns true if this Gemini session has a conversation to fork
+func (i *Instance) CanForkGemini() bool {
+	return i.Tool == "gemini" && i.GeminiSessionID != ""
+}
+
+// CreateForkedGeminiInstance copies the Gemini chat under a new session ID
+// and returns a new, not yet started instance that resumes it. The fork
+// keeps the parent's model and YOLO setting.
+func (i *Instance) CreateForkedGeminiInstance(newTitle, newGroupPath string, opts *ForkOptions) (*Instance, error) {
+	if !i.CanForkGemini() {
+		return nil, fmt.Errorf("cannot fork: no active Gemini session")
+	}
+
+	forked := i.newForkedInstance(newTitle, newGroupPath, opts)
+	newID, err := ForkGeminiSession(i.ProjectPath, i.GeminiSessionID, forked.ProjectPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fork: %w", err)
+	}
+
+	forked.Tool = "gemini"
+	forked.Command = "gemini"
+	forked.GeminiSessionID = newID
+	forked.GeminiDetectedAt = time.Now()
+	forked.GeminiModel = i.GeminiModel
+	if i.GeminiYoloMode != nil {
+		yolo := *i.GeminiYoloMode
+		forked.GeminiYoloMode = &yolo
+	}
+	return forked, nil
+}
+
+// CanForkCodex returns true if this Codex session has a conversation to fork
+func (i *Instance) CanForkCodex() bool {
+	return i.Tool == "codex" && i.CodexSessionID != ""
+}
+
+// CreateForkedCodexInstance clones the Codex rollout under a new session ID
+// and returns a new, not yet started instance that resumes it. The fork
+// keeps the parent's Codex options and LOCAL MCPs.
+func (i *Instance) CreateForkedCodexInstance(newTitle, newGroupPath string, opts *ForkOptions) (*Instance, error) {
+	if !i.CanForkCodex() {
+		return nil, fmt.Errorf("cannot fork: no active Codex session")
+	}
+
+	forked := i.newForkedInstance(newTitle, newGroupPath, opts)
+	newID, err := ForkCodexSession(i.CodexSessionID, forked.ProjectPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fork: %w", err)
+	}
+
+	forked.Tool = "codex"
+	forked.Command = "codex"
+	forked.CodexSessionID = newID
+	forked.CodexDetectedAt = time.Now()
+	if len(i.ToolOptionsJSON) > 0 {
+		forked.ToolOptionsJSON = append(json.RawMessage(nil), i.ToolOptionsJSON...)
+	}
+	if names := GetCodexSessionMCPNames(i.ID); len(names) > 0 {
+		if err := WriteCodexSessionMCP(forked.ID, names); err != nil {
+			return nil, fmt.Errorf("cannot fork: %w", err)
+		}
+	}
+	return forked, nil
+}
+
 // Exists checks if the tmux session still exists
 func (i *Instance) Exists() bool {
 	if i.tmuxSession == nil {
@@ -3882,28 +3765,66 @@ func (i *Instance) GetSessionIDFromTmux() string {
 }
 
 // GetMCPInfo returns MCP server information for this session
-// Returns nil if not a Claude or Gemini session
+// Returns nil for tools without MCP support
 func (i *Instance) GetMCPInfo() *MCPInfo {
+	if adapter := GetToolAdapter(i.Tool); adapter != nil {
+		return adapter.MCPInfo(i)
+	}
+	return nil
+}
+
+// SupportsMCP reports whether agent-deck can manage MCPs for the tool
+func (i *Instance) SupportsMCP() bool {
+	return i.Tool == "gemini" || i.HasLocalMCPScope()
+}
+
+// HasLocalMCPScope reports whether the tool supports LOCAL (per-project or
+// per-session) MCPs. Gemini only has a global settings file.
+func (i *Instance) HasLocalMCPScope() bool {
+	return i.Tool == "claude" || i.Tool == "codex" || i.Tool == "opencode"
+}
+
+// LocalMCPNames returns the MCPs attached in this session's LOCAL scope
+func (i *Instance) LocalMCPNames() []string {
 	switch i.Tool {
 	case "claude":
-		return GetMCPInfo(i.ProjectPath)
-	case "gemini":
-		return GetGeminiMCPInfo(i.ProjectPath)
-	default:
+		return GetMCPInfo(i.ProjectPath).Local()
+	case "codex":
+		return GetCodexSessionMCPNames(i.ID)
+	case "opencode":
+		return GetOpenCodeMCPInfo(i.ProjectPath).Local()
+	}
+	return nil
+}
+
+// WriteLocalMCPs sets this session's LOCAL MCPs: .mcp.json for Claude, a
+// per-session CODEX_HOME for Codex, the project's opencode.json for OpenCode
+func (i *Instance) WriteLocalMCPs(names []string) error {
+	switch i.Tool {
+	case "claude":
+		if err := WriteMCPJsonFromConfig(i.ProjectPath, names); err != nil {
+			return err
+		}
+		ClearMCPCache(i.ProjectPath)
 		return nil
+	case "codex":
+		return WriteCodexSessionMCP(i.ID, names)
+	case "opencode":
+		return WriteOpenCodeLocalMCP(i.ProjectPath, names)
 	}
+	return fmt.Errorf("%s sessions have no local MCP scope", i.Tool)
 }
 
 // CaptureLoadedMCPs captures the current MCP names as the "loaded" state
 // This should be called when a session starts or restarts, so we can track
-// which MCPs are actually loaded in the running Claude session vs just configured
+// which MCPs are actually loaded in the running session vs just configured
 func (i *Instance) CaptureLoadedMCPs() {
-	if i.Tool != "claude" {
+	if !i.HasLocalMCPScope() {
 		i.LoadedMCPNames = nil
 		return
 	}
 
-	mcpInfo := GetMCPInfo(i.ProjectPath)
+	mcpInfo := i.GetMCPInfo()
 	if mcpInfo == nil {
 		i.LoadedMCPNames = nil
 		return
@@ -1396,18 +1396,18 @@ func TestInstance_RegenerateMCPConfig_WriteFailure(t *testing.T) {
 
 func TestInstance_CanFork_Gemini(t *testing.T) {
 	inst := NewInstanceWithTool("test", "/tmp/test", "gemini")
-	inst.GeminiSessionID = "abc-123-def"
-	inst.GeminiDetectedAt = time.Now()
-
-	if inst.CanFork() {
-		t.Error("CanFork() should be false for Gemini (not supported by Gemini CLI)")
-	}
 
+	// Gemini forks by copying the chat file, which needs a session ID
 	inst.ClaudeSessionID = "claude-session-xyz"
 	inst.ClaudeDetectedAt = time.Now()
-
 	if inst.CanFork() {
-		t.Error("CanFork() should be false for Gemini tool even with ClaudeSessionID set")
+		t.Error("CanFork() should be false for Gemini without GeminiSessionID, even with ClaudeSessionID set")
+	}
+
+	inst.GeminiSessionID = "abc-123-def"
+	inst.GeminiDetectedAt = time.Now()
+	if !inst.CanFork() {
+		t.Error("CanFork() should be true for Gemini with a session ID")
 	}
 }
 
@@ -2088,6 +2088,21 @@ func TestBuildClaudeExtraFlags_NilOpts(t *testing.T) {
 	}
 }
 
+func TestBuildClaudeExtraFlags_LaunchOptionsQuoted(t *testing.T) {
+	inst := &Instance{Tool: "claude"}
+	opts := &ClaudeOptions{
+		Model:              "opus",
+		AllowedTools:       []string{"Bash(git:*)", "Edit"},
+		AppendSystemPrompt: "Don't push",
+	}
+	flags := inst.buildClaudeExtraFlags(opts)
+
+	want := ` --model opus --allowedTools 'Bash(git:*),Edit' --append-system-prompt 'Don'\''t push'`
+	if flags != want {
+		t.Errorf("flags = %q, want %q", flags, want)
+	}
+}
+
 // TestBuildClaudeCommand_ExportsInstanceID verifies that AGENTDECK_INSTANCE_ID
 // is included in the command string for Claude sessions.
 func TestBuildClaudeCommand_ExportsInstanceID(t *testing.T) {
@@ -2141,6 +2156,75 @@ func TestBuildClaudeResumeCommand_ExportsInstanceID(t *testing.T) {
 	}
 }
 
+// TestBuildClaudeResumeCommand_KeepsLaunchOptions verifies that model and
+// permission mode survive a restart.
+func TestBuildClaudeResumeCommand_KeepsLaunchOptions(t *testing.T) {
+	origConfigDir := os.Getenv("CLAUDE_CONFIG_DIR")
+	origHome := os.Getenv("HOME")
+	os.Unsetenv("CLAUDE_CONFIG_DIR")
+	os.Setenv("HOME", t.TempDir())
+	ClearUserConfigCache()
+	defer func() {
+		if origConfigDir != "" {
+			os.Setenv("CLAUDE_CONFIG_DIR", origConfigDir)
+		}
+		os.Setenv("HOME", origHome)
+		ClearUserConfigCache()
+	}()
+
+	inst := NewInstanceWithTool("test", "/tmp/test", "claude")
+	inst.ClaudeSessionID = "abc-123-def"
+	if err := inst.SetClaudeOptions(&ClaudeOptions{Model: "sonnet", PermissionMode: "plan"}); err != nil {
+		t.Fatalf("SetClaudeOptions: %v", err)
+	}
+
+	cmd := inst.buildClaudeResumeCommand()
+	if !strings.HasSuffix(cmd, "abc-123-def --model sonnet --permission-mode plan") {
+		t.Errorf("Resume command should keep launch options, got: %s", cmd)
+	}
+}
+
+// TestInstance_CreateForkedInstance_InheritsOptions verifies that a fork
+// without explicit options keeps the parent's launch options.
+func TestInstance_CreateForkedInstance_InheritsOptions(t *testing.T) {
+	origConfigDir := os.Getenv("CLAUDE_CONFIG_DIR")
+	origHome := os.Getenv("HOME")
+	os.Unsetenv("CLAUDE_CONFIG_DIR")
+	os.Setenv("HOME", t.TempDir())
+	ClearUserConfigCache()
+	defer func() {
+		if origConfigDir != "" {
+			os.Setenv("CLAUDE_CONFIG_DIR", origConfigDir)
+		}
+		os.Setenv("HOME", origHome)
+		ClearUserConfigCache()
+	}()
+
+	inst := NewInstanceWithTool("original", "/tmp/test", "claude")
+	inst.ClaudeSessionID = "abc-123"
+	inst.ClaudeDetectedAt = time.Now()
+	if err := inst.SetClaudeOptions(&ClaudeOptions{
+		SessionMode:     "resume",
+		ResumeSessionID: "abc-123",
+		Model:           "opus",
+		AddDirs:         []string{"../shared"},
+	}); err != nil {
+		t.Fatalf("SetClaudeOptions: %v", err)
+	}
+
+	forked, cmd, err := inst.CreateForkedInstance("forked", "")
+	if err != nil {
+		t.Fatalf("CreateForkedInstance() failed: %v", err)
+	}
+	if !strings.Contains(cmd, "--fork-session --model opus --add-dir ../shared") {
+		t.Errorf("Fork command should carry launch options, got: %s", cmd)
+	}
+	opts := forked.GetClaudeOptions()
+	if opts == nil || opts.Model != "opus" || opts.SessionMode != "new" || opts.ResumeSessionID != "" {
+		t.Errorf("Forked options = %+v, want model opus in new mode", opts)
+	}
+}
+
 // TestInstance_HookFastPath tests that UpdateStatus uses hook data when fresh.
 func TestInstance_HookFastPath(t *testing.T) {
 	inst := NewInstanceWithTool("hook-test", "/tmp/test", "claude")
@@ -2453,3 +2537,12 @@ func TestKillClearsCachedPrompt(t *testing.T) {
 		t.Errorf("Status should be StatusError after Kill(), got: %s", inst.Status)
 	}
 }
+
+func TestIsTurnEnd(t *testing.T) {
+	if !IsTurnEnd(StatusRunning, StatusWaiting) || !IsTurnEnd(StatusRunning, StatusIdle) {
+		t.Fatal("expected running -> waiting/idle to end a turn")
+	}
+	if IsTurnEnd(StatusRunning, StatusError) || IsTurnEnd(StatusWaiting, StatusIdle) {
+		t.Fatal("unexpected turn end")
+	}
+}
@@ -124,6 +124,53 @@ func tryPoolSocket(pool *mcppool.Pool, name, scope string) (MCPServerConfig, boo
 	return MCPServerConfig{}, false
 }
 
+// resolveMCPServer picks the transport for an MCP from config.toml: its URL
+// for HTTP/SSE MCPs (starting the server if configured), a pool socket when
+// one is available, otherwise the stdio command.
+func resolveMCPServer(pool *mcppool.Pool, name string, def MCPDef, scope string) MCPServerConfig {
+	if def.URL != "" {
+		if def.HasAutoStartServer() {
+			if err := StartHTTPServer(name, &def); err != nil {
+				mcpCatLog.Warn("http_server_start_failed", slog.String("mcp", name), slog.String("scope", scope), slog.Any("error", err))
+				// Continue anyway - server might be external or user will troubleshoot
+			}
+		}
+
+		transport := def.Transport
+		if transport == "" {
+			transport = "http" // default to http if URL is set
+		}
+		mcpCatLog.Info("transport_http", slog.String("mcp", name), slog.String("scope", scope), slog.String("transport", transport), slog.String("url", def.URL))
+		return MCPServerConfig{
+			Type:    transport,
+			URL:     def.URL,
+			Headers: def.Headers,
+		}
+	}
+
+	// Try to use pool socket for this MCP (stdio only)
+	if socketCfg, used := tryPoolSocket(pool, name, scope); used {
+		return socketCfg
+	}
+
+	// Fallback to stdio mode (pool disabled, excluded, or socket failed with fallback enabled)
+	args := def.Args
+	if args == nil {
+		args = []string{}
+	}
+	env := def.Env
+	if env == nil {
+		env = map[string]string{}
+	}
+	mcpCatLog.Info("transport_stdio", slog.String("mcp", name), slog.String("scope", scope))
+	return MCPServerConfig{
+		Type:    "stdio",
+		Command: def.Command,
+		Args:    args,
+		Env:     env,
+	}
+}
+
 // readExistingLocalMCPServers reads mcpServers from an existing .mcp.json file.
 // Returns nil if the file doesn't exist or can't be parsed.
 func readExistingLocalMCPServers(mcpFile string) map[string]json.RawMessage {
@@ -160,51 +207,7 @@ func WriteMCPJsonFromConfig(projectPath string, enabledNames []string) error {
 
 	for _, name := range enabledNames {
 		if def, ok := availableMCPs[name]; ok {
-			// Check if this is an HTTP/SSE MCP (has URL configured)
-			if def.URL != "" {
-				// Start HTTP server if configured
-				if def.HasAutoStartServer() {
-					if err := StartHTTPServer(name, &def); err != nil {
-						mcpCatLog.Warn("http_server_start_failed", slog.String("mcp", name), slog.String("scope", "local"), slog.Any("error", err))
-						// Continue anyway - server might be external or user will troubleshoot
-					}
-				}
-
-				transport := def.Transport
-				if transport == "" {
-					transport = "http" // default to http if URL is set
-				}
-				agentDeckServers[name] = MCPServerConfig{
-					Type:    transport,
-					URL:     def.URL,
-					Headers: def.Headers,
-				}
-				mcpCatLog.Info("transport_http", slog.String("mcp", name), slog.String("scope", "local"), slog.String("transport", transport), slog.String("url", def.URL))
-				continue
-			}
-
-			// Try to use pool socket for this MCP (stdio only)
-			if socketCfg, used := tryPoolSocket(pool, name, "local"); used {
-				agentDeckServers[name] = socketCfg
-				continue
-			}
-
-			// Fallback to stdio mode (pool disabled, excluded, or socket failed with fallback enabled)
-			args := def.Args
-			if args == nil {
-				args = []string{}
-			}
-			env := def.Env
-			if env == nil {
-				env = map[string]string{}
-			}
-			agentDeckServers[name] = MCPServerConfig{
-				Type:    "stdio",
-				Command: def.Command,
-				Args:    args,
-				Env:     env,
-			}
-			mcpCatLog.Info("transport_stdio", slog.String("mcp", name), slog.String("scope", "local"))
+			agentDeckServers[name] = resolveMCPServer(pool, name, def, "local")
 		}
 	}
 
@@ -273,50 +276,7 @@ func WriteGlobalMCP(enabledNames []string) error {
 
 	for _, name := range enabledNames {
 		if def, ok := availableMCPs[name]; ok {
-			// Check if this is an HTTP/SSE MCP (has URL configured)
-			if def.URL != "" {
-				// Start HTTP server if configured
-				if def.HasAutoStartServer() {
-					if err := StartHTTPServer(name, &def); err != nil {
-						mcpCatLog.Warn("http_server_start_failed", slog.String("mcp", name), slog.String("scope", "global"), slog.Any("error", err))
-					}
-				}
-
-				transport := def.Transport
-				if transport == "" {
-					transport = "http" // default to http if URL is set
-				}
-				mcpServers[name] = MCPServerConfig{
-					Type:    transport,
-					URL:     def.URL,
-					Headers: def.Headers,
-				}
-				mcpCatLog.Info("transport_http", slog.String("mcp", name), slog.String("scope", "global"), slog.String("transport", transport), slog.String("url", def.URL))
-				continue
-			}
-
-			// Try to use pool socket for this MCP (stdio only)
-			if socketCfg, used := tryPoolSocket(pool, name, "global"); used {
-				mcpServers[name] = socketCfg
-				continue
-			}
-
-			// Fallback to stdio mode (pool disabled, excluded, or socket failed with fallback enabled)
-			args := def.Args
-			if args == nil {
-				args = []string{}
-			}
-			env := def.Env
-			if env == nil {
-				env = map[string]string{}
-			}
-			mcpServers[name] = MCPServerConfig{
-				Type:    "stdio",
-				Command: def.Command,
-				Args:    args,
-				Env:     env,
-			}
-			mcpCatLog.Info("transport_stdio", slog.String("mcp", name), slog.String("scope", "global"))
+			mcpServers[name] = resolveMCPServer(pool, name, def, "global")
 		}
 	}
 
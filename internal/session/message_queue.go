@@ -0,0 +1,67 @@
+package session
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/statedb"
+)
+
+// notifyDaemonHeartbeatKey is the metadata key the notify-daemon refreshes
+// on every pass over a profile.
+const notifyDaemonHeartbeatKey = "notify_daemon_heartbeat"
+
+// queueDelivererTimeout matches the TUI heartbeat window of AliveInstanceCount.
+const queueDelivererTimeout = 30 * time.Second
+
+func markNotifyDaemonAlive(db *statedb.StateDB) {
+	_ = db.SetMeta(notifyDaemonHeartbeatKey, strconv.FormatInt(time.Now().Unix(), 10))
+}
+
+// QueueDelivererAlive reports whether a TUI or the notify-daemon is watching
+// the profile, so a queued message will be delivered when the turn ends.
+func QueueDelivererAlive(db *statedb.StateDB) bool {
+	if db == nil {
+		return false
+	}
+	if count, err := db.AliveInstanceCount(); err == nil && count > 0 {
+		return true
+	}
+	val, err := db.GetMeta(notifyDaemonHeartbeatKey)
+	if err != nil || val == "" {
+		return false
+	}
+	ts, err := strconv.ParseInt(val, 10, 64)
+	return err == nil && time.Since(time.Unix(ts, 0)) < queueDelivererTimeout
+}
+
+// sendQueuedMessage delivers a message taken off a session's queue. It
+// bypasses the queue so the message isn't queued again if the agent looks
+// busy, and still waits for the agent to be ready. Tests replace it.
+var sendQueuedMessage = SendSessionMessageReliable
+
+// DeliverQueuedMessage sends inst's next queued message when an agent turn
+// ends (running to waiting or idle). It returns the message delivered, or
+// nil when there was nothing to do. One message goes per turn; the next
+// waits for the agent to finish with this one.
+func DeliverQueuedMessage(profile string, db *statedb.StateDB, inst *Instance, from, to Status) (*statedb.QueuedMessageRow, error) {
+	if db == nil || inst == nil || !IsTurnEnd(from, to) {
+		return nil, nil
+	}
+	return DeliverNextQueuedMessage(profile, db, inst.ID)
+}
+
+// DeliverNextQueuedMessage takes the next message off a session's queue and
+// sends it. A message that fails to send goes back to the front of the
+// queue. It returns nil when the queue is empty.
+func DeliverNextQueuedMessage(profile string, db *statedb.StateDB, instanceID string) (*statedb.QueuedMessageRow, error) {
+	m, err := db.PopQueuedMessage(instanceID)
+	if err != nil || m == nil {
+		return nil, err
+	}
+	if err := sendQueuedMessage(profile, instanceID, m.Message); err != nil {
+		_ = db.PushFrontQueuedMessage(m)
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,131 @@
+package session
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/statedb"
+)
+
+func TestDeliverQueuedMessage(t *testing.T) {
+	db, err := statedb.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	var sent []string
+	var sendErr error
+	orig := sendQueuedMessage
+	sendQueuedMessage = func(profile, sessionRef, message string) error {
+		if sendErr != nil {
+			return sendErr
+		}
+		sent = append(sent, sessionRef+": "+message)
+		return nil
+	}
+	t.Cleanup(func() { sendQueuedMessage = orig })
+
+	inst := NewInstanceWithTool("api", t.TempDir(), "claude")
+	_, _ = db.EnqueueMessage(inst.ID, "first")
+	_, _ = db.EnqueueMessage(inst.ID, "second")
+
+	// Only the end of a turn delivers
+	if m, err := DeliverQueuedMessage("test", db, inst, StatusWaiting, StatusRunning); m != nil || err != nil {
+		t.Fatalf("waiting->running delivered %+v, %v", m, err)
+	}
+	m, err := DeliverQueuedMessage("test", db, inst, StatusRunning, StatusWaiting)
+	if err != nil || m == nil || m.Message != "first" {
+		t.Fatalf("running->waiting delivered %+v, %v", m, err)
+	}
+	if len(sent) != 1 || sent[0] != inst.ID+": first" {
+		t.Fatalf("sent = %v", sent)
+	}
+
+	// A failed send keeps the message at the front
+	sendErr = errors.New("session is not running")
+	if _, err := DeliverQueuedMessage("test", db, inst, StatusRunning, StatusIdle); err == nil {
+		t.Fatal("expected send error")
+	}
+	queued, _ := db.LoadQueuedMessages(inst.ID)
+	if len(queued) != 1 || queued[0].Message != "second" {
+		t.Fatalf("queue after failed send = %+v", queued)
+	}
+
+	sendErr = nil
+	if m, _ := DeliverQueuedMessage("test", db, inst, StatusRunning, StatusIdle); m == nil || m.Message != "second" {
+		t.Fatalf("retry delivered %+v", m)
+	}
+	if m, err := DeliverQueuedMessage("test", db, inst, StatusRunning, StatusIdle); m != nil || err != nil {
+		t.Fatalf("empty queue delivered %+v, %v", m, err)
+	}
+}
+
+func TestRunTurnEndHooksDeliversQueue(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	db, err := statedb.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	var sent []string
+	orig := sendQueuedMessage
+	sendQueuedMessage = func(profile, sessionRef, message string) error {
+		sent = append(sent, message)
+		return nil
+	}
+	t.Cleanup(func() { sendQueuedMessage = orig })
+
+	inst := NewInstanceWithTool("api", t.TempDir(), "claude")
+	_, _ = db.EnqueueMessage(inst.ID, "first")
+
+	if res := RunTurnEndHooks("test", db, inst, StatusWaiting, StatusIdle); res.Delivered != nil || len(sent) != 0 {
+		t.Fatalf("waiting->idle ran hooks: %+v", res)
+	}
+	res := RunTurnEndHooks("test", db, inst, StatusRunning, StatusWaiting)
+	if res.Rule != nil || res.Delivered == nil || res.Delivered.Message != "first" || len(sent) != 1 {
+		t.Fatalf("turn end without a policy should deliver the queue, got %+v sent=%v", res, sent)
+	}
+}
+
+func TestQueueDelivererAlive(t *testing.T) {
+	db, err := statedb.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	if QueueDelivererAlive(db) {
+		t.Fatal("expected no deliverer without a TUI or notify-daemon")
+	}
+	markNotifyDaemonAlive(db)
+	if !QueueDelivererAlive(db) {
+		t.Fatal("expected the notify-daemon to count as a deliverer")
+	}
+	stale := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	if err := db.SetMeta(notifyDaemonHeartbeatKey, stale); err != nil {
+		t.Fatal(err)
+	}
+	if QueueDelivererAlive(db) {
+		t.Fatal("expected a stale notify-daemon heartbeat to be ignored")
+	}
+	if err := db.RegisterInstance(false); err != nil {
+		t.Fatal(err)
+	}
+	if !QueueDelivererAlive(db) {
+		t.Fatal("expected a running TUI to count as a deliverer")
+	}
+}
@@ -0,0 +1,177 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OpenCode keeps one JSON file per record under its storage directory:
+//   message/<sessionID>/<messageID>.json  role, model, tokens and cost
+//   part/<messageID>/<partID>.json        message parts, incl. tool calls
+
+// GetOpenCodeStorageDir returns OpenCode's storage directory
+// ($XDG_DATA_HOME/opencode/storage, default ~/.local/share/opencode/storage)
+func GetOpenCodeStorageDir() string {
+	if xdg := strings.TrimSpace(os.Getenv("XDG_DATA_HOME")); xdg != "" {
+		return filepath.Join(xdg, "opencode", "storage")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), ".local", "share", "opencode", "storage")
+	}
+	return filepath.Join(home, ".local", "share", "opencode", "storage")
+}
+
+// openCodePart is a message part: text, or a tool call with its input
+type openCodePart struct {
+	Type  string `json:"type"`
+	Tool  string `json:"tool"`
+	Text  string `json:"text"`
+	State struct {
+		Input json.RawMessage `json:"input"`
+	} `json:"state"`
+}
+
+// openCodeMessage is one message record in OpenCode's storage
+type openCodeMessage struct {
+	ID      string `json:"id"`
+	Role    string `json:"role"`
+	ModelID string `json:"modelID"`
+	Time    struct {
+		Created   int64 `json:"created"`   // Unix milliseconds
+		Completed int64 `json:"completed"` // Unix milliseconds
+	} `json:"time"`
+	Cost   float64 `json:"cost"`
+	Tokens struct {
+		Input     int `json:"input"`
+		Output    int `json:"output"`
+		Reasoning int `json:"reasoning"`
+		Cache     struct {
+			Read  int `json:"read"`
+			Write int `json:"write"`
+		} `json:"cache"`
+	} `json:"tokens"`
+	// Older OpenCode versions inline parts in the message
+	Parts []openCodePart `json:"parts"`
+}
+
+// ParseOpenCodeSession reads an OpenCode session's messages and parts from
+// storageDir and returns analytics. OpenCode records per-message cost itself,
+// so EstimatedCost is the sum of those values.
+func ParseOpenCodeSession(storageDir, sessionID string) (*AgentAnalytics, error) {
+	messageDir := filepath.Join(storageDir, "message", sessionID)
+	entries, err := os.ReadDir(messageDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []openCodeMessage
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(messageDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var msg openCodeMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue // Skip malformed records
+		}
+		messages = append(messages, msg)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Time.Created < messages[j].Time.Created
+	})
+
+	analytics := &AgentAnalytics{Tool: "opencode"}
+	toolCounts := make(map[string]int)
+	var firstTime, lastTime time.Time
+
+	for _, msg := range messages {
+		if msg.Time.Created > 0 {
+			created := time.UnixMilli(msg.Time.Created)
+			if firstTime.IsZero() {
+				firstTime = created
+			}
+			if created.After(lastTime) {
+				lastTime = created
+			}
+		}
+		if msg.Time.Completed > 0 {
+			if completed := time.UnixMilli(msg.Time.Completed); completed.After(lastTime) {
+				lastTime = completed
+			}
+		}
+
+		switch msg.Role {
+		case "user":
+			analytics.TotalTurns++
+		case "assistant":
+			analytics.InputTokens += msg.Tokens.Input
+			analytics.OutputTokens += msg.Tokens.Output + msg.Tokens.Reasoning
+			analytics.CacheReadTokens += msg.Tokens.Cache.Read
+			analytics.CacheWriteTokens += msg.Tokens.Cache.Write
+			analytics.EstimatedCost += msg.Cost
+			if msg.ModelID != "" {
+				analytics.Model = msg.ModelID
+			}
+
+			// Context of the latest completed request
+			if contextTokens := msg.Tokens.Input + msg.Tokens.Cache.Read + msg.Tokens.Cache.Write; contextTokens > 0 {
+				analytics.CurrentContextTokens = contextTokens
+			}
+
+			parts := msg.Parts
+			if len(parts) == 0 {
+				parts = readOpenCodeParts(storageDir, msg.ID)
+			}
+			for _, part := range parts {
+				if part.Type == "tool" && part.Tool != "" {
+					toolCounts[part.Tool]++
+				}
+			}
+		}
+	}
+
+	analytics.ToolCalls = toolCallsFromCounts(toolCounts)
+	analytics.StartTime = firstTime
+	analytics.LastActive = lastTime
+	if !firstTime.IsZero() && !lastTime.IsZero() {
+		analytics.Duration = lastTime.Sub(firstTime)
+	}
+
+	return analytics, nil
+}
+
+// readOpenCodeParts reads the part records of one message
+func readOpenCodeParts(storageDir, messageID string) []openCodePart {
+	if messageID == "" {
+		return nil
+	}
+	partDir := filepath.Join(storageDir, "part", messageID)
+	entries, err := os.ReadDir(partDir)
+	if err != nil {
+		return nil
+	}
+
+	var parts []openCodePart
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(partDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var part openCodePart
+		if err := json.Unmarshal(data, &part); err == nil {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
@@ -0,0 +1,100 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeOpenCodeRecord(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeOpenCodeFixture(t *testing.T, storageDir, sessionID string) {
+	t.Helper()
+	msgDir := filepath.Join(storageDir, "message", sessionID)
+	writeOpenCodeRecord(t, msgDir, "msg_1.json",
+		`{"id":"msg_1","sessionID":"`+sessionID+`","role":"user","time":{"created":1759312800000}}`)
+	writeOpenCodeRecord(t, msgDir, "msg_2.json",
+		`{"id":"msg_2","sessionID":"`+sessionID+`","role":"assistant","modelID":"claude-sonnet-4","providerID":"anthropic","time":{"created":1759312801000,"completed":1759312810000},"cost":0.012,"tokens":{"input":100,"output":300,"reasoning":50,"cache":{"read":4000,"write":1000}}}`)
+	writeOpenCodeRecord(t, msgDir, "msg_3.json",
+		`{"id":"msg_3","sessionID":"`+sessionID+`","role":"user","time":{"created":1759312840000}}`)
+	// Older format with inline parts
+	writeOpenCodeRecord(t, msgDir, "msg_4.json",
+		`{"id":"msg_4","sessionID":"`+sessionID+`","role":"assistant","modelID":"claude-sonnet-4","time":{"created":1759312841000,"completed":1759312860000},"cost":0.008,"tokens":{"input":200,"output":100,"reasoning":0,"cache":{"read":5000,"write":0}},"parts":[{"type":"text"},{"type":"tool","tool":"edit"}]}`)
+	writeOpenCodeRecord(t, msgDir, "broken.json", `{not json`)
+
+	partDir := filepath.Join(storageDir, "part", "msg_2")
+	writeOpenCodeRecord(t, partDir, "prt_1.json", `{"id":"prt_1","type":"text","text":"hi"}`)
+	writeOpenCodeRecord(t, partDir, "prt_2.json", `{"id":"prt_2","type":"tool","tool":"bash"}`)
+	writeOpenCodeRecord(t, partDir, "prt_3.json", `{"id":"prt_3","type":"tool","tool":"read"}`)
+	writeOpenCodeRecord(t, partDir, "prt_4.json", `{"id":"prt_4","type":"tool","tool":"bash"}`)
+}
+
+func TestParseOpenCodeSession(t *testing.T) {
+	storageDir := t.TempDir()
+	writeOpenCodeFixture(t, storageDir, "ses_abc")
+
+	a, err := ParseOpenCodeSession(storageDir, "ses_abc")
+	if err != nil {
+		t.Fatalf("ParseOpenCodeSession: %v", err)
+	}
+
+	if a.Tool != "opencode" || a.Model != "claude-sonnet-4" {
+		t.Errorf("Tool/Model = %q/%q", a.Tool, a.Model)
+	}
+	if a.InputTokens != 300 || a.OutputTokens != 450 || a.CacheReadTokens != 9000 || a.CacheWriteTokens != 1000 {
+		t.Errorf("tokens = %+v", a)
+	}
+	// Last assistant request: input + cache read + cache write
+	if a.CurrentContextTokens != 5200 {
+		t.Errorf("CurrentContextTokens = %d, want 5200", a.CurrentContextTokens)
+	}
+	if a.TotalTurns != 2 {
+		t.Errorf("TotalTurns = %d, want 2", a.TotalTurns)
+	}
+	if a.EstimatedCost < 0.01999 || a.EstimatedCost > 0.02001 {
+		t.Errorf("EstimatedCost = %f, want recorded 0.02", a.EstimatedCost)
+	}
+	want := []ToolCall{{Name: "bash", Count: 2}, {Name: "edit", Count: 1}, {Name: "read", Count: 1}}
+	if len(a.ToolCalls) != len(want) {
+		t.Fatalf("ToolCalls = %+v, want %+v", a.ToolCalls, want)
+	}
+	for i := range want {
+		if a.ToolCalls[i] != want[i] {
+			t.Errorf("ToolCalls[%d] = %+v, want %+v", i, a.ToolCalls[i], want[i])
+		}
+	}
+	if a.Duration != 60*time.Second {
+		t.Errorf("Duration = %v, want 1m0s", a.Duration)
+	}
+}
+
+func TestParseOpenCodeSession_Missing(t *testing.T) {
+	if _, err := ParseOpenCodeSession(t.TempDir(), "ses_missing"); err == nil {
+		t.Error("expected error for a session without messages")
+	}
+}
+
+func TestInstanceLoadAnalytics_OpenCode(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	writeOpenCodeFixture(t, filepath.Join(dataHome, "opencode", "storage"), "ses_abc")
+
+	inst := NewInstanceWithTool("opencode-test", "/tmp/project", "opencode")
+	inst.OpenCodeSessionID = "ses_abc"
+	a, err := inst.LoadAnalytics()
+	if err != nil || a == nil {
+		t.Fatalf("LoadAnalytics = %+v, %v", a, err)
+	}
+	if a.Tool != "opencode" || a.TotalTurns != 2 {
+		t.Errorf("unexpected analytics %+v", a)
+	}
+}
@@ -0,0 +1,141 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// OpenCode reads MCPs from the "mcp" block of opencode.json: the global
+// config in ~/.config/opencode/ and a project config in the project root,
+// which OpenCode merges over the global one.
+
+// openCodeSchemaURL is set as $schema when agent-deck creates opencode.json
+const openCodeSchemaURL = "https://opencode.ai/config.json"
+
+// OpenCodeMCPServer is one entry of opencode.json's "mcp" block
+type OpenCodeMCPServer struct {
+	Type        string            `json:"type"` // "local" or "remote"
+	Command     []string          `json:"command,omitempty"`
+	Environment map[string]string `json:"environment,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Enabled     *bool             `json:"enabled,omitempty"`
+}
+
+// GetOpenCodeConfigDir returns OpenCode's global config directory
+// ($XDG_CONFIG_HOME/opencode, default ~/.config/opencode)
+func GetOpenCodeConfigDir() string {
+	if xdg := strings.TrimSpace(os.Getenv("XDG_CONFIG_HOME")); xdg != "" {
+		return filepath.Join(xdg, "opencode")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), ".config", "opencode")
+	}
+	return filepath.Join(home, ".config", "opencode")
+}
+
+// readOpenCodeMCPNames returns the enabled MCP names in an opencode.json
+func readOpenCodeMCPNames(configFile string) []string {
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil
+	}
+	var config struct {
+		MCP map[string]OpenCodeMCPServer `json:"mcp"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil
+	}
+	var names []string
+	for name, server := range config.MCP {
+		if server.Enabled == nil || *server.Enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetOpenCodeMCPInfo returns an OpenCode session's MCPs: Global from the
+// global opencode.json, Local from the project's opencode.json.
+func GetOpenCodeMCPInfo(projectPath string) *MCPInfo {
+	info := &MCPInfo{Global: readOpenCodeMCPNames(filepath.Join(GetOpenCodeConfigDir(), "opencode.json"))}
+	if projectPath != "" {
+		for _, name := range readOpenCodeMCPNames(filepath.Join(projectPath, "opencode.json")) {
+			info.LocalMCPs = append(info.LocalMCPs, LocalMCP{Name: name, SourcePath: projectPath})
+		}
+	}
+	return info
+}
+
+// WriteOpenCodeLocalMCP sets the agent-deck managed MCPs in the project's opencode.json
+func WriteOpenCodeLocalMCP(projectPath string, enabledNames []string) error {
+	return writeOpenCodeMCP(filepath.Join(projectPath, "opencode.json"), enabledNames, "local")
+}
+
+// WriteOpenCodeGlobalMCP sets the agent-deck managed MCPs in the global opencode.json
+func WriteOpenCodeGlobalMCP(enabledNames []string) error {
+	dir := GetOpenCodeConfigDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create OpenCode config dir: %w", err)
+	}
+	return writeOpenCodeMCP(filepath.Join(dir, "opencode.json"), enabledNames, "global")
+}
+
+// writeOpenCodeMCP rewrites the "mcp" block of an opencode.json, preserving
+// other settings and MCPs not defined in agent-deck's config.toml
+func writeOpenCodeMCP(configFile string, enabledNames []string, scope string) error {
+	rawConfig := make(map[string]interface{})
+	if data, err := os.ReadFile(configFile); err == nil {
+		if err := json.Unmarshal(data, &rawConfig); err != nil {
+			return fmt.Errorf("failed to parse %s (comments aren't supported): %w", configFile, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", configFile, err)
+	} else {
+		rawConfig["$schema"] = openCodeSchemaURL
+	}
+
+	availableMCPs := GetAvailableMCPs()
+	pool := GetGlobalPool()
+
+	merged := make(map[string]interface{})
+	if existing, ok := rawConfig["mcp"].(map[string]interface{}); ok {
+		for name, cfg := range existing {
+			if _, managed := availableMCPs[name]; !managed {
+				merged[name] = cfg
+			}
+		}
+	}
+	for _, name := range enabledNames {
+		if def, ok := availableMCPs[name]; ok {
+			merged[name] = toOpenCodeMCPServer(resolveMCPServer(pool, name, def, scope))
+		}
+	}
+	rawConfig["mcp"] = merged
+
+	data, err := json.MarshalIndent(rawConfig, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal opencode.json: %w", err)
+	}
+	return writeFileAtomic(configFile, data, 0644)
+}
+
+// toOpenCodeMCPServer converts a resolved MCP to OpenCode's format
+func toOpenCodeMCPServer(cfg MCPServerConfig) OpenCodeMCPServer {
+	enabled := true
+	if cfg.URL != "" {
+		return OpenCodeMCPServer{Type: "remote", URL: cfg.URL, Headers: cfg.Headers, Enabled: &enabled}
+	}
+	command := append([]string{cfg.Command}, cfg.Args...)
+	server := OpenCodeMCPServer{Type: "local", Command: command, Enabled: &enabled}
+	if len(cfg.Env) > 0 {
+		server.Environment = cfg.Env
+	}
+	return server
+}
@@ -0,0 +1,92 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteOpenCodeLocalMCP(t *testing.T) {
+	project := t.TempDir()
+	withTestMCPs(t, codexTestMCPs())
+
+	configFile := filepath.Join(project, "opencode.json")
+	initial := `{"theme": "tokyonight", "mcp": {"mine": {"type": "local", "command": ["my-mcp"]}, "fs": {"type": "local", "command": ["old"]}}}`
+	if err := os.WriteFile(configFile, []byte(initial), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteOpenCodeLocalMCP(project, []string{"exa", "remote"}); err != nil {
+		t.Fatalf("WriteOpenCodeLocalMCP: %v", err)
+	}
+	data, _ := os.ReadFile(configFile)
+	var config struct {
+		Theme string                       `json:"theme"`
+		MCP   map[string]OpenCodeMCPServer `json:"mcp"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Theme != "tokyonight" {
+		t.Error("other settings should be preserved")
+	}
+	if _, ok := config.MCP["mine"]; !ok {
+		t.Error("MCPs not defined in config.toml should be preserved")
+	}
+	if _, ok := config.MCP["fs"]; ok {
+		t.Error("managed MCPs not enabled should be removed")
+	}
+	exa := config.MCP["exa"]
+	if exa.Type != "local" || len(exa.Command) != 3 || exa.Command[0] != "npx" || exa.Environment["EXA_API_KEY"] != "k\"1" {
+		t.Errorf("exa = %+v", exa)
+	}
+	remote := config.MCP["remote"]
+	if remote.Type != "remote" || remote.URL != "https://example.com/mcp" || remote.Headers["Authorization"] != "Bearer t" {
+		t.Errorf("remote = %+v", remote)
+	}
+
+	info := GetOpenCodeMCPInfo(project)
+	if local := info.Local(); len(local) != 3 {
+		t.Errorf("local = %v, want exa, mine, remote", local)
+	}
+}
+
+func TestWriteOpenCodeGlobalMCP(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	withTestMCPs(t, codexTestMCPs())
+
+	if err := WriteOpenCodeGlobalMCP([]string{"fs"}); err != nil {
+		t.Fatalf("WriteOpenCodeGlobalMCP: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(GetOpenCodeConfigDir(), "opencode.json"))
+	if err != nil {
+		t.Fatal("global opencode.json should be created")
+	}
+	var config map[string]interface{}
+	_ = json.Unmarshal(data, &config)
+	if config["$schema"] != openCodeSchemaURL {
+		t.Error("a new opencode.json should get the $schema")
+	}
+	if info := GetOpenCodeMCPInfo(""); len(info.Global) != 1 || info.Global[0] != "fs" {
+		t.Errorf("global = %v, want [fs]", info.Global)
+	}
+}
+
+func TestOpenCodeMCPNamesSkipDisabled(t *testing.T) {
+	project := t.TempDir()
+	_ = os.WriteFile(filepath.Join(project, "opencode.json"),
+		[]byte(`{"mcp": {"on": {"type": "local", "command": ["a"]}, "off": {"type": "local", "command": ["b"], "enabled": false}}}`), 0644)
+	if got := GetOpenCodeMCPInfo(project).Local(); len(got) != 1 || got[0] != "on" {
+		t.Errorf("local = %v, want [on]", got)
+	}
+}
+
+func TestWriteOpenCodeMCPRejectsComments(t *testing.T) {
+	project := t.TempDir()
+	withTestMCPs(t, codexTestMCPs())
+	_ = os.WriteFile(filepath.Join(project, "opencode.json"), []byte("{\n  // comment\n}"), 0644)
+	if err := WriteOpenCodeLocalMCP(project, []string{"fs"}); err == nil {
+		t.Error("a file that can't be parsed should not be overwritten")
+	}
+}
@@ -0,0 +1,280 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+const promptsDirName = "prompts"
+
+// Prompt is a reusable message from the profile's prompt library. Markdown
+// (and plain text) prompts are the file body with optional front-matter:
+//
+//	---
+//	description: Review the diff for security issues
+//	focus: injection and authentication
+//	---
+//	Review the changes on {{branch}}, focusing on {{focus}}:
+//	{{files_changed}}
+//
+// TOML prompts set description, prompt and a [vars] table. Front-matter keys
+// other than description, and [vars] entries, are defaults for variables.
+type Prompt struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Body        string            `json:"body"`
+	Vars        map[string]string `json:"vars,omitempty"`
+	Path        string            `json:"path"`
+}
+
+// promptFile is the TOML prompt format
+type promptFile struct {
+	Description string            `toml:"description"`
+	Prompt      string            `toml:"prompt"`
+	Vars        map[string]string `toml:"vars"`
+}
+
+// promptExtensions are the file types loaded from the prompts directory
+var promptExtensions = map[string]bool{".md": true, ".txt": true, ".toml": true}
+
+// PromptSessionVars are the variables filled from the target session
+var PromptSessionVars = []string{
+	"title", "project", "path", "group", "tool", "branch", "files_changed", "last_response", "date",
+}
+
+// promptVarRe matches {{name}} placeholders
+var promptVarRe = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// PromptsDir returns the profile's prompt library directory
+func PromptsDir(profile string) (string, error) {
+	profileDir, err := GetProfileDir(GetEffectiveProfile(profile))
+	if err != nil {
+		return "", fmt.Errorf("resolve profile dir: %w", err)
+	}
+	return filepath.Join(profileDir, promptsDirName), nil
+}
+
+// ListPrompts returns the profile's prompts sorted by name. Files that fail
+// to parse are skipped.
+func ListPrompts(profile string) ([]Prompt, error) {
+	dir, err := PromptsDir(profile)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read prompts: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var prompts []Prompt
+	for _, entry := range entries {
+		if entry.IsDir() || !promptExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			continue
+		}
+		p, err := LoadPromptFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			sessionLog.Warn("prompt_parse_failed", slog.String("file", entry.Name()), slog.String("error", err.Error()))
+			continue
+		}
+		if seen[p.Name] {
+			continue
+		}
+		seen[p.Name] = true
+		prompts = append(prompts, *p)
+	}
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].Name < prompts[j].Name })
+	return prompts, nil
+}
+
+// GetPrompt returns the named prompt (the file name without extension)
+func GetPrompt(profile, name string) (*Prompt, error) {
+	prompts, err := ListPrompts(profile)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range prompts {
+		if p.Name == name {
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("prompt '%s' not found", name)
+}
+
+// LoadPromptFile parses a .md, .txt or .toml prompt file
+func LoadPromptFile(path string) (*Prompt, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ext := filepath.Ext(path)
+	p := &Prompt{Name: strings.TrimSuffix(filepath.Base(path), ext), Path: path}
+
+	if strings.EqualFold(ext, ".toml") {
+		var f promptFile
+		if _, err := toml.Decode(string(raw), &f); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+		}
+		p.Description = strings.TrimSpace(f.Description)
+		p.Body = strings.TrimSpace(f.Prompt)
+		p.Vars = f.Vars
+	} else {
+		p.Body, p.Description, p.Vars = parsePromptFrontMatter(string(raw))
+	}
+	if p.Body == "" {
+		return nil, fmt.Errorf("%s has no prompt text", filepath.Base(path))
+	}
+	return p, nil
+}
+
+// parsePromptFrontMatter splits "---" key: value front-matter from a
+// markdown body
+func parsePromptFrontMatter(text string) (body, description string, vars map[string]string) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return strings.TrimSpace(text), "", nil
+	}
+	header, rest, ok := strings.Cut(text[4:], "\n---")
+	if !ok {
+		return strings.TrimSpace(text), "", nil
+	}
+	// Drop the rest of the closing delimiter line
+	if _, after, found := strings.Cut(rest, "\n"); found {
+		rest = after
+	} else {
+		rest = ""
+	}
+
+	for _, line := range strings.Split(header, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, val, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		val = strings.Trim(strings.TrimSpace(val), `"'`)
+		if strings.EqualFold(key, "description") {
+			description = val
+			continue
+		}
+		if vars == nil {
+			vars = make(map[string]string)
+		}
+		vars[key] = val
+	}
+	return strings.TrimSpace(rest), description, vars
+}
+
+// Variables returns the placeholder names in the prompt body, in order of
+// first use
+func (p *Prompt) Variables() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range promptVarRe.FindAllStringSubmatch(p.Body, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// Render fills the prompt's placeholders for inst. Values in vars win, then
+// the session's context (see PromptSessionVars), then the prompt's defaults.
+// A placeholder without a value is an error.
+func (p *Prompt) Render(inst *Instance, vars map[string]string) (string, error) {
+	names := p.Variables()
+	context := promptSessionContext(inst, names)
+
+	values := make(map[string]string, len(names))
+	var missing []string
+	for _, name := range names {
+		if v, ok := vars[name]; ok {
+			values[name] = v
+			continue
+		}
+		sessionValue, isSessionVar := context[name]
+		if sessionValue != "" {
+			values[name] = sessionValue
+			continue
+		}
+		if v, ok := p.Vars[name]; ok {
+			values[name] = v
+			continue
+		}
+		if !isSessionVar {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("prompt '%s' needs a value for %s", p.Name, strings.Join(missing, ", "))
+	}
+
+	return promptVarRe.ReplaceAllStringFunc(p.Body, func(m string) string {
+		return values[promptVarRe.FindStringSubmatch(m)[1]]
+	}), nil
+}
+
+// promptSessionContext computes the session variables among names. Values
+// that cost a git call or a transcript read are only computed when used.
+func promptSessionContext(inst *Instance, names []string) map[string]string {
+	context := make(map[string]string)
+	if inst == nil {
+		return context
+	}
+	for _, name := range names {
+		switch name {
+		case "title":
+			context[name] = inst.Title
+		case "project":
+			context[name] = filepath.Base(inst.ProjectPath)
+		case "path":
+			context[name] = inst.ProjectPath
+		case "group":
+			context[name] = inst.GroupPath
+		case "tool":
+			context[name] = inst.Tool
+		case "date":
+			context[name] = time.Now().Format("2006-01-02")
+		case "branch":
+			branch := inst.WorktreeBranch
+			if branch == "" && git.IsGitRepo(inst.ProjectPath) {
+				branch, _ = git.GetCurrentBranch(inst.ProjectPath)
+			}
+			context[name] = branch
+		case "files_changed":
+			var files []string
+			if git.IsGitRepo(inst.ProjectPath) {
+				if stats, err := git.UncommittedFiles(inst.ProjectPath); err == nil {
+					for _, f := range stats {
+						files = append(files, f.Path)
+					}
+				}
+			}
+			context[name] = strings.Join(files, "\n")
+		case "last_response":
+			context[name] = ""
+			if resp, err := inst.GetLastResponseBestEffort(); err == nil && resp != nil {
+				context[name] = strings.TrimSpace(resp.Content)
+			}
+		}
+	}
+	return context
+}
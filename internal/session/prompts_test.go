@@ -0,0 +1,121 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePromptFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListPrompts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	const profile = "prompts-test"
+
+	if list, err := ListPrompts(profile); err != nil || len(list) != 0 {
+		t.Fatalf("ListPrompts without a prompts dir = %v, %v", list, err)
+	}
+
+	dir, err := PromptsDir(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePromptFile(t, dir, "security.md", "---\ndescription: Review for security\nfocus: \"auth\"\n---\nReview {{branch}} for {{focus}}.\n")
+	writePromptFile(t, dir, "tests.toml", "description = \"Write tests\"\nprompt = \"\"\"\nWrite tests for:\n{{files_changed}}\n\"\"\"\n[vars]\nframework = \"go test\"\n")
+	writePromptFile(t, dir, "plain.txt", "Summarize your progress.")
+	writePromptFile(t, dir, "empty.md", "---\ndescription: nothing\n---\n")
+	writePromptFile(t, dir, "notes.json", "{}")
+
+	list, err := ListPrompts(profile)
+	if err != nil {
+		t.Fatalf("ListPrompts: %v", err)
+	}
+	var names []string
+	for _, p := range list {
+		names = append(names, p.Name)
+	}
+	if got := strings.Join(names, ","); got != "plain,security,tests" {
+		t.Fatalf("prompts = %s, want plain,security,tests", got)
+	}
+
+	security, err := GetPrompt(profile, "security")
+	if err != nil {
+		t.Fatalf("GetPrompt: %v", err)
+	}
+	if security.Description != "Review for security" || security.Vars["focus"] != "auth" || security.Body != "Review {{branch}} for {{focus}}." {
+		t.Errorf("security = %+v", security)
+	}
+	tests, _ := GetPrompt(profile, "tests")
+	if tests.Description != "Write tests" || tests.Vars["framework"] != "go test" || !strings.HasPrefix(tests.Body, "Write tests for:") {
+		t.Errorf("tests = %+v", tests)
+	}
+	if _, err := GetPrompt(profile, "missing"); err == nil {
+		t.Error("GetPrompt should fail for an unknown prompt")
+	}
+}
+
+func TestPromptRender(t *testing.T) {
+	inst := NewInstanceWithTool("api", t.TempDir(), "claude")
+	inst.GroupPath = "work"
+	inst.WorktreeBranch = "feature/login"
+
+	p := &Prompt{
+		Name: "review",
+		Body: "Review {{ branch }} of {{title}} ({{group}}) for {{focus}}. Changed:\n{{files_changed}}",
+		Vars: map[string]string{"focus": "auth", "branch": "main"},
+	}
+	if got := strings.Join(p.Variables(), ","); got != "branch,title,group,focus,files_changed" {
+		t.Errorf("Variables = %s", got)
+	}
+
+	// Session context beats defaults; files_changed is empty outside git
+	got, err := p.Render(inst, nil)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if want := "Review feature/login of api (work) for auth. Changed:\n"; got != want {
+		t.Errorf("Render = %q, want %q", got, want)
+	}
+
+	// Explicit values beat both
+	got, err = p.Render(inst, map[string]string{"focus": "injection", "branch": "hotfix"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.HasPrefix(got, "Review hotfix of api (work) for injection.") {
+		t.Errorf("Render with vars = %q", got)
+	}
+
+	// Defaults fill session variables the session has no value for
+	inst.WorktreeBranch = ""
+	if got, _ = p.Render(inst, nil); !strings.HasPrefix(got, "Review main of api") {
+		t.Errorf("Render with default branch = %q", got)
+	}
+
+	missing := &Prompt{Name: "ask", Body: "Answer {{question}} in {{language}}"}
+	if _, err := missing.Render(inst, map[string]string{"language": "Go"}); err == nil || !strings.Contains(err.Error(), "question") {
+		t.Errorf("Render error = %v, want missing question", err)
+	}
+}
+
+func TestParsePromptFrontMatter(t *testing.T) {
+	body, desc, vars := parsePromptFrontMatter("---\r\n# comment\r\ndescription: Triage\r\nseverity: high\r\n---\r\n\r\nTriage at {{severity}}\r\n")
+	if body != "Triage at {{severity}}" || desc != "Triage" || vars["severity"] != "high" || len(vars) != 1 {
+		t.Errorf("got body %q desc %q vars %v", body, desc, vars)
+	}
+
+	// Unterminated front-matter is body text
+	body, desc, _ = parsePromptFrontMatter("---\nnot closed")
+	if body != "---\nnot closed" || desc != "" {
+		t.Errorf("unterminated: body %q desc %q", body, desc)
+	}
+}
@@ -9,10 +9,23 @@ import (
 	"strings"
 )
 
-// SendSessionMessageReliable sends a message using the same queued/reliable semantics
-// as `agent-deck session send` (default mode, no --no-wait).
-// It invokes the CLI command to keep behavior identical across callers.
+// SendSessionMessageReliable sends a message using the same reliable semantics
+// as `agent-deck session send --no-queue`: it waits for the agent to be ready
+// and never queues. It invokes the CLI command to keep behavior identical
+// across callers.
 func SendSessionMessageReliable(profile, sessionRef, message string) error {
+	return sendSessionMessage(profile, sessionRef, message, "--no-queue")
+}
+
+// SendOrQueueSessionMessage is like SendSessionMessageReliable, but queues the
+// message while the agent is busy and a TUI or notify-daemon will deliver it
+// when the turn ends (default `agent-deck session send`).
+func SendOrQueueSessionMessage(profile, sessionRef, message string) error {
+	return sendSessionMessage(profile, sessionRef, message)
+}
+
+// sendSessionMessage runs `agent-deck session send` with extra flags
+func sendSessionMessage(profile, sessionRef, message string, flags ...string) error {
 	sessionRef = strings.TrimSpace(sessionRef)
 	message = strings.TrimSpace(message)
 	if sessionRef == "" {
@@ -28,6 +41,7 @@ func SendSessionMessageReliable(profile, sessionRef, message string) error {
 		args = append(args, "-p", profile)
 	}
 	args = append(args, "session", "send", sessionRef, message, "-q")
+	args = append(args, flags...)
 
 	cmd := exec.Command(bin, args...)
 	var stderr bytes.Buffer
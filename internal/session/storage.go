@@ -321,6 +321,9 @@ func (s *Storage) DeleteInstance(id string) error {
 	if err := s.db.DeleteInstance(id); err != nil {
 		return fmt.Errorf("failed to delete instance %s: %w", id, err)
 	}
+	if err := RemoveCodexSessionHome(id); err != nil {
+		storageLog.Warn("codex_home_remove_failed", slog.String("instance", id), slog.String("error", err.Error()))
+	}
 
 	_ = s.db.Touch()
 	return nil
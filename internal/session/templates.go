@@ -0,0 +1,320 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const templatesFileName = "templates.json"
+
+// SessionTemplate is a named, reusable session setup. String fields may
+// contain placeholders that are expanded when the template is applied; see
+// TemplateVars.
+type SessionTemplate struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	Tool    string `json:"tool"`
+	Command string `json:"command,omitempty"`
+	Wrapper string `json:"wrapper,omitempty"`
+
+	// Path and Group are used when the caller doesn't provide them.
+	Path  string `json:"path,omitempty"`
+	Group string `json:"group,omitempty"`
+	// Title names new sessions, e.g. "{project}-{branch}".
+	Title string `json:"title,omitempty"`
+
+	// Worktree creates the session in a new worktree on Branch.
+	Worktree bool   `json:"worktree,omitempty"`
+	Branch   string `json:"branch,omitempty"`
+
+	MCPs   []string        `json:"mcps,omitempty"`
+	Skills []TemplateSkill `json:"skills,omitempty"`
+
+	ToolOptions    json.RawMessage `json:"tool_options,omitempty"`
+	GeminiYoloMode *bool           `json:"gemini_yolo_mode,omitempty"`
+
+	// InitialMessage is sent once the agent is ready.
+	InitialMessage string `json:"initial_message,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TemplateSkill is a skill attached to sessions created from a template.
+type TemplateSkill struct {
+	Name   string `json:"name"`
+	Source string `json:"source,omitempty"`
+}
+
+// DefaultTemplateBranch is used for worktree templates without a branch pattern.
+const DefaultTemplateBranch = "feature/{title}"
+
+// TemplateVars are the values substituted into template placeholders:
+// {project}, {branch}, {title}, {group}, {path} and {date}.
+type TemplateVars struct {
+	Project string
+	Branch  string
+	Title   string
+	Group   string
+	Path    string
+}
+
+// Expand replaces the placeholders in s. Unknown placeholders are left as-is.
+func (v TemplateVars) Expand(s string) string {
+	if !strings.Contains(s, "{") {
+		return s
+	}
+	return strings.NewReplacer(
+		"{project}", v.Project,
+		"{branch}", v.Branch,
+		"{title}", v.Title,
+		"{group}", v.Group,
+		"{path}", v.Path,
+		"{date}", time.Now().Format("2006-01-02"),
+	).Replace(s)
+}
+
+// ResolveNames fills in the title and branch for a session created from t
+// in projectPath. Explicit values win. The branch pattern may use {title}
+// and the title pattern may use {branch}; when neither is given, {title} in
+// the branch pattern falls back to the project name.
+func (t *SessionTemplate) ResolveNames(projectPath, title, branch string) (string, string) {
+	vars := TemplateVars{Project: filepath.Base(projectPath), Path: projectPath, Title: title, Group: t.Group}
+	pattern := t.Branch
+	if pattern == "" {
+		pattern = DefaultTemplateBranch
+	}
+	needBranch := branch == "" && t.Worktree
+	if needBranch && (title != "" || !strings.Contains(pattern, "{title}")) {
+		branch = vars.Expand(pattern)
+		needBranch = false
+	}
+	vars.Branch = branch
+	if title == "" && t.Title != "" {
+		title = vars.Expand(t.Title)
+	}
+	if needBranch {
+		vars.Title = title
+		if vars.Title == "" {
+			vars.Title = vars.Project
+		}
+		branch = vars.Expand(pattern)
+	}
+	return title, branch
+}
+
+// ApplyTo copies the template's tool settings onto inst.
+func (t *SessionTemplate) ApplyTo(inst *Instance, vars TemplateVars) {
+	if t.Tool != "" {
+		inst.Tool = t.Tool
+	}
+	if t.Command != "" {
+		inst.Command = vars.Expand(t.Command)
+	}
+	if t.Wrapper != "" {
+		inst.Wrapper = vars.Expand(t.Wrapper)
+	}
+	if len(t.ToolOptions) > 0 {
+		inst.ToolOptionsJSON = t.ToolOptions
+	}
+	if t.GeminiYoloMode != nil {
+		yolo := *t.GeminiYoloMode
+		inst.GeminiYoloMode = &yolo
+	}
+}
+
+// AttachResources writes the template's MCPs and skills for projectPath.
+func (t *SessionTemplate) AttachResources(projectPath string) error {
+	if len(t.MCPs) > 0 {
+		if err := WriteMCPJsonFromConfig(projectPath, t.MCPs); err != nil {
+			return fmt.Errorf("attach MCPs: %w", err)
+		}
+	}
+	return t.AttachSkills(projectPath)
+}
+
+// AttachSkills attaches the template's skills to projectPath. Every skill is
+// attempted; failures are reported together.
+func (t *SessionTemplate) AttachSkills(projectPath string) error {
+	var errs []string
+	for _, skill := range t.Skills {
+		if _, err := AttachSkillToProject(projectPath, skill.Name, skill.Source); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", skill.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("attach skills: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// TemplateFromInstance captures inst's setup as a template. Conversation
+// state (resume IDs) is dropped so every session starts fresh.
+func TemplateFromInstance(inst *Instance, name string) SessionTemplate {
+	t := SessionTemplate{
+		Name:           name,
+		Tool:           inst.Tool,
+		Command:        inst.Command,
+		Wrapper:        inst.Wrapper,
+		Group:          inst.GroupPath,
+		GeminiYoloMode: inst.GeminiYoloMode,
+		CreatedAt:      time.Now(),
+	}
+	if inst.IsWorktree() {
+		t.Worktree = true
+		t.Path = inst.WorktreeRepoRoot
+	} else {
+		t.Path = inst.ProjectPath
+	}
+
+	switch inst.Tool {
+	case "claude":
+		if opts, err := UnmarshalClaudeOptions(inst.ToolOptionsJSON); err == nil && opts != nil {
+			opts.SessionMode, opts.ResumeSessionID = "", ""
+			t.ToolOptions, _ = MarshalToolOptions(opts)
+		}
+	case "opencode":
+		if opts, err := UnmarshalOpenCodeOptions(inst.ToolOptionsJSON); err == nil && opts != nil {
+			opts.SessionMode, opts.ResumeSessionID = "", ""
+			t.ToolOptions, _ = MarshalToolOptions(opts)
+		}
+	default:
+		t.ToolOptions = inst.ToolOptionsJSON
+	}
+
+	// Only MCPs from the project's own .mcp.json; parent directories and
+	// global config apply anyway.
+	if info := inst.GetMCPInfo(); info != nil {
+		for _, mcp := range info.LocalMCPs {
+			if filepath.Clean(mcp.SourcePath) == filepath.Clean(inst.ProjectPath) {
+				t.MCPs = append(t.MCPs, mcp.Name)
+			}
+		}
+	}
+	if skills, err := GetAttachedProjectSkills(inst.ProjectPath); err == nil {
+		for _, s := range skills {
+			t.Skills = append(t.Skills, TemplateSkill{Name: s.Name, Source: s.Source})
+		}
+	}
+	return t
+}
+
+// templatesMu serializes read-modify-write cycles on the templates file
+// within this process.
+var templatesMu sync.Mutex
+
+func templatesPath(profile string) (string, error) {
+	profileDir, err := GetProfileDir(GetEffectiveProfile(profile))
+	if err != nil {
+		return "", fmt.Errorf("resolve profile dir: %w", err)
+	}
+	return filepath.Join(profileDir, templatesFileName), nil
+}
+
+func loadTemplateMap(profile string) (map[string]SessionTemplate, error) {
+	path, err := templatesPath(profile)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]SessionTemplate{}, nil
+		}
+		return nil, fmt.Errorf("read templates: %w", err)
+	}
+	templates := map[string]SessionTemplate{}
+	if err := json.Unmarshal(raw, &templates); err != nil {
+		return nil, fmt.Errorf("parse templates: %w", err)
+	}
+	return templates, nil
+}
+
+func writeTemplateMap(profile string, templates map[string]SessionTemplate) error {
+	path, err := templatesPath(profile)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("mkdir templates dir: %w", err)
+	}
+	raw, err := json.MarshalIndent(templates, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal templates: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("write temp templates: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename templates: %w", err)
+	}
+	return nil
+}
+
+// ListTemplates returns the profile's templates sorted by name.
+func ListTemplates(profile string) ([]SessionTemplate, error) {
+	templates, err := loadTemplateMap(profile)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]SessionTemplate, 0, len(templates))
+	for _, t := range templates {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// GetTemplate returns the named template.
+func GetTemplate(profile, name string) (*SessionTemplate, error) {
+	templates, err := loadTemplateMap(profile)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := templates[name]
+	if !ok {
+		return nil, fmt.Errorf("template '%s' not found", name)
+	}
+	return &t, nil
+}
+
+// SaveTemplate stores t, replacing any template with the same name.
+func SaveTemplate(profile string, t SessionTemplate) error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("template name cannot be empty")
+	}
+	templatesMu.Lock()
+	defer templatesMu.Unlock()
+
+	templates, err := loadTemplateMap(profile)
+	if err != nil {
+		return err
+	}
+	templates[t.Name] = t
+	return writeTemplateMap(profile, templates)
+}
+
+// DeleteTemplate removes the named template.
+func DeleteTemplate(profile, name string) error {
+	templatesMu.Lock()
+	defer templatesMu.Unlock()
+
+	templates, err := loadTemplateMap(profile)
+	if err != nil {
+		return err
+	}
+	if _, ok := templates[name]; !ok {
+		return fmt.Errorf("template '%s' not found", name)
+	}
+	delete(templates, name)
+	return writeTemplateMap(profile, templates)
+}
@@ -0,0 +1,164 @@
+package session
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTemplateVarsExpand(t *testing.T) {
+	vars := TemplateVars{Project: "api", Branch: "fix/login", Title: "login", Group: "work", Path: "/src/api"}
+	got := vars.Expand("{project}:{branch}:{title}:{group}:{path}:{unknown}")
+	want := "api:fix/login:login:work:/src/api:{unknown}"
+	if got != want {
+		t.Errorf("Expand = %q, want %q", got, want)
+	}
+	if got := vars.Expand("{date}"); got != time.Now().Format("2006-01-02") {
+		t.Errorf("Expand({date}) = %q", got)
+	}
+}
+
+func TestSessionTemplateResolveNames(t *testing.T) {
+	tests := []struct {
+		name       string
+		tmpl       SessionTemplate
+		title      string
+		branch     string
+		wantTitle  string
+		wantBranch string
+	}{
+		{
+			name:       "explicit values win",
+			tmpl:       SessionTemplate{Title: "{project}-x", Worktree: true, Branch: "feature/{title}"},
+			title:      "mine",
+			branch:     "fix/it",
+			wantTitle:  "mine",
+			wantBranch: "fix/it",
+		},
+		{
+			name:       "branch from title",
+			tmpl:       SessionTemplate{Worktree: true, Branch: "review/{title}"},
+			title:      "login",
+			wantTitle:  "login",
+			wantBranch: "review/login",
+		},
+		{
+			name:       "title from branch",
+			tmpl:       SessionTemplate{Title: "{project}-{branch}", Worktree: true},
+			branch:     "fix/login",
+			wantTitle:  "api-fix/login",
+			wantBranch: "fix/login",
+		},
+		{
+			name:       "title pattern feeds default branch",
+			tmpl:       SessionTemplate{Title: "{project}-review", Worktree: true},
+			wantTitle:  "api-review",
+			wantBranch: "feature/api-review",
+		},
+		{
+			name:       "no title falls back to project",
+			tmpl:       SessionTemplate{Worktree: true},
+			wantTitle:  "",
+			wantBranch: "feature/api",
+		},
+		{
+			name:      "no worktree leaves branch empty",
+			tmpl:      SessionTemplate{Title: "{project}"},
+			wantTitle: "api",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, branch := tt.tmpl.ResolveNames("/src/api", tt.title, tt.branch)
+			if title != tt.wantTitle || branch != tt.wantBranch {
+				t.Errorf("ResolveNames = (%q, %q), want (%q, %q)", title, branch, tt.wantTitle, tt.wantBranch)
+			}
+		})
+	}
+}
+
+func TestSessionTemplateApplyTo(t *testing.T) {
+	yolo := true
+	tmpl := SessionTemplate{
+		Tool:           "gemini",
+		Command:        "gemini",
+		Wrapper:        "nice {command} # {project}",
+		ToolOptions:    json.RawMessage(`{"tool":"gemini"}`),
+		GeminiYoloMode: &yolo,
+	}
+	inst := NewInstance("t", "/src/api")
+	tmpl.ApplyTo(inst, TemplateVars{Project: "api"})
+
+	if inst.Tool != "gemini" || inst.Command != "gemini" {
+		t.Errorf("tool/command = %q/%q", inst.Tool, inst.Command)
+	}
+	// {command} is not a template placeholder and must survive for the wrapper
+	if inst.Wrapper != "nice {command} # api" {
+		t.Errorf("Wrapper = %q", inst.Wrapper)
+	}
+	if inst.GeminiYoloMode == nil || !*inst.GeminiYoloMode {
+		t.Error("GeminiYoloMode not applied")
+	}
+	if string(inst.ToolOptionsJSON) != `{"tool":"gemini"}` {
+		t.Errorf("ToolOptionsJSON = %s", inst.ToolOptionsJSON)
+	}
+}
+
+func TestTemplateFromInstance_DropsResumeState(t *testing.T) {
+	inst := NewInstanceWithGroupAndTool("api", t.TempDir(), "work", "claude")
+	inst.Command = "claude"
+	if err := inst.SetClaudeOptions(&ClaudeOptions{SessionMode: "resume", ResumeSessionID: "abc", UseChrome: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := TemplateFromInstance(inst, "base")
+	if tmpl.Name != "base" || tmpl.Tool != "claude" || tmpl.Group != "work" || tmpl.Path != inst.ProjectPath {
+		t.Errorf("unexpected template: %+v", tmpl)
+	}
+	opts, err := UnmarshalClaudeOptions(tmpl.ToolOptions)
+	if err != nil || opts == nil {
+		t.Fatalf("UnmarshalClaudeOptions: %v", err)
+	}
+	if opts.SessionMode != "" || opts.ResumeSessionID != "" || !opts.UseChrome {
+		t.Errorf("options = %+v, want resume state dropped and chrome kept", opts)
+	}
+}
+
+func TestTemplateStore(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	const profile = "templates-test"
+
+	if list, err := ListTemplates(profile); err != nil || len(list) != 0 {
+		t.Fatalf("ListTemplates on empty store = %v, %v", list, err)
+	}
+	if err := SaveTemplate(profile, SessionTemplate{Name: " "}); err == nil {
+		t.Error("SaveTemplate accepted an empty name")
+	}
+	for _, name := range []string{"zeta", "alpha"} {
+		if err := SaveTemplate(profile, SessionTemplate{Name: name, Tool: "claude"}); err != nil {
+			t.Fatalf("SaveTemplate(%s): %v", name, err)
+		}
+	}
+	if err := SaveTemplate(profile, SessionTemplate{Name: "alpha", Tool: "codex", MCPs: []string{"memory"}}); err != nil {
+		t.Fatalf("SaveTemplate overwrite: %v", err)
+	}
+
+	list, err := ListTemplates(profile)
+	if err != nil || len(list) != 2 || list[0].Name != "alpha" || list[1].Name != "zeta" {
+		t.Fatalf("ListTemplates = %+v, %v", list, err)
+	}
+	got, err := GetTemplate(profile, "alpha")
+	if err != nil || got.Tool != "codex" || len(got.MCPs) != 1 {
+		t.Fatalf("GetTemplate = %+v, %v", got, err)
+	}
+
+	if err := DeleteTemplate(profile, "alpha"); err != nil {
+		t.Fatalf("DeleteTemplate: %v", err)
+	}
+	if err := DeleteTemplate(profile, "alpha"); err == nil {
+		t.Error("DeleteTemplate of missing template should fail")
+	}
+	if _, err := GetTemplate(profile, "alpha"); err == nil {
+		t.Error("GetTemplate after delete should fail")
+	}
+}
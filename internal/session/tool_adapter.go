@@ -0,0 +1,438 @@
+package session
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+// ToolAdapter is the extension point for agent CLIs. Each adapter owns the
+// tool-specific parts of a session's lifecycle: how the command line is
+// built, how the tool's conversation ID is discovered and resumed, how a
+// conversation is forked, how its transcript is read, which terminal
+// patterns signal busy/waiting, and where its MCP config lives.
+//
+// The built-in tools (claude, gemini, codex, opencode) are Go adapters.
+// Tools configured under [tools.<name>] in config.toml get a declarative
+// adapter driven by the TOML fields (see ToolAdapterDef). Additional Go
+// adapters can be added with RegisterToolAdapter.
+type ToolAdapter interface {
+	// Name is the tool name sessions are created with (Instance.Tool)
+	Name() string
+
+	// BuildCommand returns the shell command that starts the tool, resuming
+	// the tracked conversation when there is one
+	BuildCommand(i *Instance, baseCommand string) string
+
+	// SessionID returns the tool conversation ID tracked for the session,
+	// or "" if none has been discovered yet
+	SessionID(i *Instance) string
+
+	// DiscoverSession kicks off discovery of the tool's conversation ID after
+	// the tmux session starts. It must not block.
+	DiscoverSession(i *Instance)
+
+	// CanResume reports whether a restart can respawn the tool in place
+	// rather than recreating the session. It must be cheap: the UI calls it
+	// to decide whether restart is offered.
+	CanResume(i *Instance) bool
+
+	// ResumeCommand refreshes the tracked conversation ID and returns the
+	// command a restart runs, resuming the conversation when one is known.
+	// ok is false when the tool has nothing to resume and the session should
+	// start fresh through BuildCommand.
+	ResumeCommand(i *Instance) (command string, ok bool)
+
+	// CanFork reports whether the conversation can be forked
+	CanFork(i *Instance) bool
+
+	// Fork returns a new, not yet started instance that continues a copy of
+	// the conversation. opts (may be nil) moves the fork into a worktree.
+	Fork(i *Instance, newTitle, newGroupPath string, opts *ForkOptions) (*Instance, error)
+
+	// LastResponse returns the latest assistant message from the transcript
+	LastResponse(i *Instance) (*ResponseOutput, error)
+
+	// Transcript returns the conversation, oldest message first. Returns nil
+	// (no error) when there is no transcript yet.
+	Transcript(i *Instance) ([]TranscriptMessage, error)
+
+	// Analytics parses the transcript into the common analytics shape.
+	// Returns nil (no error) when there is no transcript yet.
+	Analytics(i *Instance) (*AgentAnalytics, error)
+
+	// StatusPatterns returns the default busy/prompt hints used for status
+	// detection; config.toml overrides and extras are merged on top
+	StatusPatterns() *tmux.RawPatterns
+
+	// MCPInfo returns the MCPs attached to the session, or nil if the tool
+	// has no MCP support
+	MCPInfo(i *Instance) *MCPInfo
+}
+
+var (
+	toolAdaptersMu sync.RWMutex
+	toolAdapters   = map[string]ToolAdapter{
+		"claude":   claudeAdapter{},
+		"gemini":   geminiAdapter{},
+		"codex":    codexAdapter{},
+		"opencode": openCodeAdapter{},
+	}
+)
+
+// RegisterToolAdapter registers a Go adapter, replacing any adapter with the
+// same name. Registered adapters take precedence over [tools.<name>] entries.
+func RegisterToolAdapter(a ToolAdapter) {
+	toolAdaptersMu.Lock()
+	defer toolAdaptersMu.Unlock()
+	toolAdapters[a.Name()] = a
+}
+
+// GetToolAdapter returns the adapter for a tool: a registered Go adapter, a
+// declarative adapter for a [tools.<name>] entry, or nil for plain commands
+func GetToolAdapter(tool string) ToolAdapter {
+	toolAdaptersMu.RLock()
+	a, ok := toolAdapters[tool]
+	toolAdaptersMu.RUnlock()
+	if ok {
+		return a
+	}
+	if def := GetToolDef(tool); def != nil {
+		return newDeclarativeAdapter(tool, def)
+	}
+	return nil
+}
+
+// ForkOptions places a fork in another working directory, typically a git
+// worktree created for it. Nil forks into the parent's project path.
+type ForkOptions struct {
+	WorkDir          string
+	WorktreePath     string
+	WorktreeRepoRoot string
+	WorktreeBranch   string
+}
+
+// newForkedInstance creates the instance shell for a fork of i: title, group
+// (default: the parent's) and working directory from opts
+func (i *Instance) newForkedInstance(newTitle, newGroupPath string, opts *ForkOptions) *Instance {
+	projectPath := i.ProjectPath
+	if opts != nil && opts.WorkDir != "" {
+		projectPath = opts.WorkDir
+	}
+	forked := NewInstance(newTitle, projectPath)
+	forked.GroupPath = i.GroupPath
+	if newGroupPath != "" {
+		forked.GroupPath = newGroupPath
+	}
+	if opts != nil && opts.WorktreePath != "" {
+		forked.WorktreePath = opts.WorktreePath
+		forked.WorktreeRepoRoot = opts.WorktreeRepoRoot
+		forked.WorktreeBranch = opts.WorktreeBranch
+	}
+	return forked
+}
+
+// toolSessionIDFromEnv reads a tool session ID that async detection stored in
+// the tmux environment, or "" when the session isn't running
+func (i *Instance) toolSessionIDFromEnv(name string) string {
+	if i.tmuxSession == nil || !i.tmuxSession.Exists() {
+		return ""
+	}
+	envID, err := i.tmuxSession.GetEnvironment(name)
+	if err != nil || envID == "" {
+		return ""
+	}
+	sessionLog.Info("restart_recovered_session_id", slog.String("tool", i.Tool), slog.String("session_id", envID))
+	return envID
+}
+
+// errForkUnsupported is returned by adapters whose tool cannot fork
+func errForkUnsupported(tool string) error {
+	return fmt.Errorf("cannot fork: %s sessions do not support forking", tool)
+}
+
+// claudeAdapter drives Claude Code: capture-resume via CLAUDE_SESSION_ID,
+// JSONL transcripts under ~/.claude/projects, .mcp.json for local MCPs
+type claudeAdapter struct{}
+
+func (claudeAdapter) Name() string { return "claude" }
+
+func (claudeAdapter) BuildCommand(i *Instance, baseCommand string) string {
+	return i.buildClaudeCommand(baseCommand)
+}
+
+func (claudeAdapter) SessionID(i *Instance) string { return i.ClaudeSessionID }
+
+// DiscoverSession is a no-op: the session ID arrives through hooks and the
+// tmux environment, and is reconciled in UpdateStatus
+func (claudeAdapter) DiscoverSession(*Instance) {}
+
+func (claudeAdapter) CanResume(i *Instance) bool { return i.ClaudeSessionID != "" }
+
+// ResumeCommand syncs from disk first to pick up a conversation /clear
+// switched to
+func (claudeAdapter) ResumeCommand(i *Instance) (string, bool) {
+	i.syncClaudeSessionFromDisk()
+	if i.ClaudeSessionID == "" {
+		return "", false
+	}
+	return i.buildClaudeResumeCommand(), true
+}
+
+func (claudeAdapter) CanFork(i *Instance) bool {
+	return i.ClaudeSessionID != "" && time.Since(i.ClaudeDetectedAt) < 5*time.Minute
+}
+
+func (claudeAdapter) Fork(i *Instance, newTitle, newGroupPath string, opts *ForkOptions) (*Instance, error) {
+	var claudeOpts *ClaudeOptions
+	if opts != nil {
+		claudeOpts = i.ForkClaudeOptions()
+		claudeOpts.WorkDir = opts.WorkDir
+		claudeOpts.WorktreePath = opts.WorktreePath
+		claudeOpts.WorktreeRepoRoot = opts.WorktreeRepoRoot
+		claudeOpts.WorktreeBranch = opts.WorktreeBranch
+	}
+	forked, _, err := i.CreateForkedInstanceWithOptions(newTitle, newGroupPath, claudeOpts)
+	return forked, err
+}
+
+func (claudeAdapter) LastResponse(i *Instance) (*ResponseOutput, error) {
+	return i.getClaudeLastResponse()
+}
+
+func (claudeAdapter) Transcript(i *Instance) ([]TranscriptMessage, error) {
+	jsonlPath := i.GetJSONLPath()
+	if jsonlPath == "" {
+		return nil, nil
+	}
+	return readClaudeTranscript(jsonlPath)
+}
+
+func (claudeAdapter) Analytics(i *Instance) (*AgentAnalytics, error) {
+	jsonlPath := i.GetJSONLPath()
+	if jsonlPath == "" {
+		return nil, nil
+	}
+	analytics, err := ParseSessionJSONL(jsonlPath)
+	if err != nil {
+		return nil, err
+	}
+	return NewAgentAnalyticsFromClaude(analytics), nil
+}
+
+func (claudeAdapter) StatusPatterns() *tmux.RawPatterns { return tmux.DefaultRawPatterns("claude") }
+
+func (claudeAdapter) MCPInfo(i *Instance) *MCPInfo { return GetMCPInfo(i.ProjectPath) }
+
+// geminiAdapter drives Gemini CLI: session files under ~/.gemini/tmp, the
+// global settings.json for MCPs
+type geminiAdapter struct{}
+
+func (geminiAdapter) Name() string { return "gemini" }
+
+func (geminiAdapter) BuildCommand(i *Instance, baseCommand string) string {
+	return i.buildGeminiCommand(baseCommand)
+}
+
+func (geminiAdapter) SessionID(i *Instance) string { return i.GeminiSessionID }
+
+// DiscoverSession is a no-op: UpdateStatus picks up the newest session file
+func (geminiAdapter) DiscoverSession(*Instance) {}
+
+func (geminiAdapter) CanResume(i *Instance) bool { return i.GeminiSessionID != "" }
+
+// ResumeCommand always switches to the newest session first: the user may
+// have started a new one since the ID was recorded
+func (geminiAdapter) ResumeCommand(i *Instance) (string, bool) {
+	i.UpdateGeminiSession(nil)
+	if i.GeminiSessionID == "" {
+		return "", false
+	}
+	return i.buildGeminiCommand("gemini"), true
+}
+
+func (geminiAdapter) CanFork(i *Instance) bool { return i.CanForkGemini() }
+
+func (geminiAdapter) Fork(i *Instance, newTitle, newGroupPath string, opts *ForkOptions) (*Instance, error) {
+	return i.CreateForkedGeminiInstance(newTitle, newGroupPath, opts)
+}
+
+func (geminiAdapter) LastResponse(i *Instance) (*ResponseOutput, error) {
+	return i.getGeminiLastResponse()
+}
+
+func (geminiAdapter) Transcript(i *Instance) ([]TranscriptMessage, error) {
+	if i.GeminiSessionID == "" {
+		return nil, nil
+	}
+	sessionFile := findGeminiSessionFile(i.ProjectPath, i.GeminiSessionID)
+	if sessionFile == "" {
+		return nil, nil
+	}
+	return readGeminiTranscript(sessionFile)
+}
+
+func (geminiAdapter) Analytics(i *Instance) (*AgentAnalytics, error) {
+	// Gemini analytics are refreshed in the background by UpdateStatus();
+	// read from disk only when no snapshot exists yet (e.g. CLI callers)
+	if i.GeminiAnalytics != nil {
+		return NewAgentAnalyticsFromGemini(i.GeminiAnalytics), nil
+	}
+	if i.GeminiSessionID == "" {
+		return nil, nil
+	}
+	analytics := &GeminiSessionAnalytics{}
+	if err := UpdateGeminiAnalyticsFromDisk(i.ProjectPath, i.GeminiSessionID, analytics); err != nil {
+		return nil, err
+	}
+	return NewAgentAnalyticsFromGemini(analytics), nil
+}
+
+func (geminiAdapter) StatusPatterns() *tmux.RawPatterns { return tmux.DefaultRawPatterns("gemini") }
+
+func (geminiAdapter) MCPInfo(i *Instance) *MCPInfo { return GetGeminiMCPInfo(i.ProjectPath) }
+
+// codexAdapter drives Codex CLI: rollout JSONL under $CODEX_HOME/sessions,
+// a per-session CODEX_HOME for local MCPs
+type codexAdapter struct{}
+
+func (codexAdapter) Name() string { return "codex" }
+
+// BuildCommand also records the start time that session discovery uses to
+// ignore older rollouts
+func (codexAdapter) BuildCommand(i *Instance, baseCommand string) string {
+	command := i.buildCodexCommand(baseCommand)
+	i.CodexStartedAt = time.Now().UnixMilli()
+	return command
+}
+
+func (codexAdapter) SessionID(i *Instance) string { return i.CodexSessionID }
+
+// DiscoverSession is skipped when the ID is already known (forks)
+func (codexAdapter) DiscoverSession(i *Instance) {
+	if i.CodexSessionID == "" {
+		go i.detectCodexSessionAsync()
+	}
+}
+
+// CanResume is true even before discovery: a restart then starts fresh
+func (codexAdapter) CanResume(*Instance) bool { return true }
+
+// ResumeCommand switches to the newest rollout, falling back to the ID async
+// detection left in the tmux environment. Without one it starts fresh and
+// re-arms the start time discovery uses.
+func (codexAdapter) ResumeCommand(i *Instance) (string, bool) {
+	i.UpdateCodexSession(nil)
+	if i.CodexSessionID == "" {
+		if envID := i.toolSessionIDFromEnv("CODEX_SESSION_ID"); envID != "" {
+			i.CodexSessionID = envID
+			i.CodexDetectedAt = time.Now()
+		}
+	}
+	command := i.buildCodexCommand("codex")
+	if i.CodexSessionID == "" {
+		i.CodexStartedAt = time.Now().UnixMilli()
+	}
+	return command, true
+}
+
+func (codexAdapter) CanFork(i *Instance) bool { return i.CanForkCodex() }
+
+func (codexAdapter) Fork(i *Instance, newTitle, newGroupPath string, opts *ForkOptions) (*Instance, error) {
+	return i.CreateForkedCodexInstance(newTitle, newGroupPath, opts)
+}
+
+func (codexAdapter) LastResponse(i *Instance) (*ResponseOutput, error) {
+	return i.getTerminalLastResponse()
+}
+
+func (codexAdapter) Transcript(i *Instance) ([]TranscriptMessage, error) {
+	path := FindCodexSessionFile(i.CodexSessionID)
+	if path == "" {
+		return nil, nil
+	}
+	return readCodexTranscript(path)
+}
+
+func (codexAdapter) Analytics(i *Instance) (*AgentAnalytics, error) {
+	path := FindCodexSessionFile(i.CodexSessionID)
+	if path == "" {
+		return nil, nil
+	}
+	return ParseCodexSessionJSONL(path)
+}
+
+func (codexAdapter) StatusPatterns() *tmux.RawPatterns { return tmux.DefaultRawPatterns("codex") }
+
+func (codexAdapter) MCPInfo(i *Instance) *MCPInfo { return GetCodexMCPInfo(i.ID, i.ProjectPath) }
+
+// openCodeAdapter drives OpenCode: sessions listed by `opencode session
+// list`, JSON records under its storage dir, opencode.json for MCPs
+type openCodeAdapter struct{}
+
+func (openCodeAdapter) Name() string { return "opencode" }
+
+// BuildCommand also records the start time used by session discovery
+func (openCodeAdapter) BuildCommand(i *Instance, baseCommand string) string {
+	command := i.buildOpenCodeCommand(baseCommand)
+	i.OpenCodeStartedAt = time.Now().UnixMilli()
+	return command
+}
+
+func (openCodeAdapter) SessionID(i *Instance) string { return i.OpenCodeSessionID }
+
+func (openCodeAdapter) DiscoverSession(i *Instance) { go i.detectOpenCodeSessionAsync() }
+
+// CanResume is true even before discovery: a restart then starts fresh
+func (openCodeAdapter) CanResume(*Instance) bool { return true }
+
+// ResumeCommand recovers an ID async detection stored in the tmux
+// environment but the instance hasn't saved yet. Without one it starts fresh
+// and re-arms the start time discovery uses.
+func (openCodeAdapter) ResumeCommand(i *Instance) (string, bool) {
+	if i.OpenCodeSessionID == "" {
+		if envID := i.toolSessionIDFromEnv("OPENCODE_SESSION_ID"); envID != "" {
+			i.OpenCodeSessionID = envID
+			i.OpenCodeDetectedAt = time.Now()
+		}
+	}
+	if i.OpenCodeSessionID == "" {
+		i.OpenCodeStartedAt = time.Now().UnixMilli()
+	}
+	return i.buildOpenCodeCommand("opencode"), true
+}
+
+func (openCodeAdapter) CanFork(i *Instance) bool { return i.CanForkOpenCode() }
+
+// Fork ignores opts: the export/import script runs in the parent's directory
+func (openCodeAdapter) Fork(i *Instance, newTitle, newGroupPath string, _ *ForkOptions) (*Instance, error) {
+	forked, _, err := i.CreateForkedOpenCodeInstanceWithOptions(newTitle, newGroupPath, nil)
+	return forked, err
+}
+
+func (openCodeAdapter) LastResponse(i *Instance) (*ResponseOutput, error) {
+	return i.getTerminalLastResponse()
+}
+
+func (openCodeAdapter) Transcript(i *Instance) ([]TranscriptMessage, error) {
+	if i.OpenCodeSessionID == "" {
+		return nil, nil
+	}
+	return readOpenCodeTranscript(GetOpenCodeStorageDir(), i.OpenCodeSessionID)
+}
+
+func (openCodeAdapter) Analytics(i *Instance) (*AgentAnalytics, error) {
+	if i.OpenCodeSessionID == "" {
+		return nil, nil
+	}
+	return ParseOpenCodeSession(GetOpenCodeStorageDir(), i.OpenCodeSessionID)
+}
+
+func (openCodeAdapter) StatusPatterns() *tmux.RawPatterns {
+	return tmux.DefaultRawPatterns("opencode")
+}
+
+func (openCodeAdapter) MCPInfo(i *Instance) *MCPInfo { return GetOpenCodeMCPInfo(i.ProjectPath) }
@@ -0,0 +1,520 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+// ToolAdapterDef configures a declarative adapter for a custom tool, set as
+// [tools.<name>.adapter] in config.toml. Paths and command templates expand
+// ~, $VARS, {project} (session path) and {session_id}.
+//
+// Extractors read the transcript: a value starting with "$" is a JSONPath
+// evaluated against each JSONL record (or the whole document for
+// format = "json"); a value prefixed with "re:" is a regex applied to the raw
+// transcript, capturing group 1 (or the whole match).
+type ToolAdapterDef struct {
+	// SessionGlob matches the tool's transcript files, e.g.
+	// "~/.local/share/goose/sessions/*.jsonl". The newest file written after
+	// the session starts is taken as its conversation.
+	SessionGlob string `toml:"session_glob"`
+
+	// SessionIDRegex extracts the session ID from a transcript file name
+	// (group 1 or whole match). Default: the file name without extension.
+	SessionIDRegex string `toml:"session_id_regex"`
+
+	// Transcript is an explicit transcript path, e.g.
+	// "{project}/.aider.chat.history.md". Default: the discovered file.
+	Transcript string `toml:"transcript"`
+
+	// Format is the transcript format: "jsonl" (default), "json" or "text"
+	Format string `toml:"format"`
+
+	// ResumeCommand resumes a conversation, e.g.
+	// "goose session --resume --name {session_id}". Overrides resume_flag.
+	ResumeCommand string `toml:"resume_command"`
+
+	// ForkCommand starts a copy of the conversation {session_id}. Leave
+	// empty if the tool cannot fork.
+	ForkCommand string `toml:"fork_command"`
+
+	// Transcript extractors
+	LastResponse string `toml:"last_response"` // last match is the latest assistant message
+	Model        string `toml:"model"`         // last match wins
+	InputTokens  string `toml:"input_tokens"`  // matches are summed
+	OutputTokens string `toml:"output_tokens"` // matches are summed
+	ToolCall     string `toml:"tool_call"`     // each match counts one call of the named tool
+	Turn         string `toml:"turn"`          // each match counts one turn
+
+	// MCPConfig is a JSON file listing the tool's MCP servers; MCPPath is
+	// the JSONPath of the server object inside it (default "$.mcpServers")
+	MCPConfig string `toml:"mcp_config"`
+	MCPPath   string `toml:"mcp_path"`
+}
+
+// declarativeAdapter implements ToolAdapter from a [tools.<name>] entry.
+// Without an [adapter] table it behaves like the classic custom tool:
+// resume_flag + session_id_env capture and config.toml status patterns.
+type declarativeAdapter struct {
+	name string
+	def  *ToolDef
+}
+
+func newDeclarativeAdapter(name string, def *ToolDef) *declarativeAdapter {
+	return &declarativeAdapter{name: name, def: def}
+}
+
+// spec returns the [adapter] table, or an empty one
+func (a *declarativeAdapter) spec() *ToolAdapterDef {
+	if a.def.Adapter != nil {
+		return a.def.Adapter
+	}
+	return &ToolAdapterDef{}
+}
+
+func (a *declarativeAdapter) Name() string { return a.name }
+
+func (a *declarativeAdapter) BuildCommand(i *Instance, baseCommand string) string {
+	if sessionID := a.SessionID(i); sessionID != "" && a.spec().ResumeCommand != "" {
+		return i.buildEnvSourceCommand() + a.resumeCommand(i, sessionID)
+	}
+	return i.buildGenericCommand(baseCommand)
+}
+
+// resumeCommand builds the command that resumes sessionID and re-exports it
+// to the tmux environment
+func (a *declarativeAdapter) resumeCommand(i *Instance, sessionID string) string {
+	var cmd string
+	if tmpl := a.spec().ResumeCommand; tmpl != "" {
+		cmd = expandAdapterTemplate(tmpl, i, sessionID)
+	} else {
+		cmd = fmt.Sprintf("%s %s %s", i.Command, a.def.ResumeFlag, sessionID)
+	}
+	if a.def.DangerousMode && a.def.DangerousFlag != "" {
+		cmd += " " + a.def.DangerousFlag
+	}
+	if a.def.SessionIDEnv != "" {
+		cmd = fmt.Sprintf("tmux set-environment %s %s && %s", a.def.SessionIDEnv, sessionID, cmd)
+	}
+	return cmd
+}
+
+func (a *declarativeAdapter) SessionID(i *Instance) string {
+	return i.GetGenericSessionID()
+}
+
+// DiscoverSession polls session_glob for the newest transcript written after
+// start and stores its ID in session_id_env
+func (a *declarativeAdapter) DiscoverSession(i *Instance) {
+	spec := a.spec()
+	if spec.SessionGlob == "" || a.def.SessionIDEnv == "" || a.SessionID(i) != "" {
+		return
+	}
+	since := time.Now().Add(-2 * time.Second)
+	go func() {
+		for _, delay := range []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second} {
+			time.Sleep(delay)
+			if sessionID := a.findSession(i, since); sessionID != "" {
+				if i.tmuxSession != nil {
+					if err := i.tmuxSession.SetEnvironment(a.def.SessionIDEnv, sessionID); err != nil {
+						sessionLog.Warn("adapter_set_env_failed", slog.String("tool", a.name), slog.String("error", err.Error()))
+					}
+				}
+				sessionLog.Debug("adapter_session_detected", slog.String("tool", a.name), slog.String("session_id", sessionID))
+				return
+			}
+		}
+		sessionLog.Debug("adapter_session_not_found", slog.String("tool", a.name))
+	}()
+}
+
+// findSession returns the ID of the newest transcript modified after since
+func (a *declarativeAdapter) findSession(i *Instance, since time.Time) string {
+	matches, _ := filepath.Glob(expandAdapterTemplate(a.spec().SessionGlob, i, ""))
+	var bestID string
+	var bestTime time.Time
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || info.ModTime().Before(since) {
+			continue
+		}
+		if id := a.sessionIDFromPath(path); id != "" && (bestID == "" || info.ModTime().After(bestTime)) {
+			bestID = id
+			bestTime = info.ModTime()
+		}
+	}
+	return bestID
+}
+
+// sessionIDFromPath extracts the session ID from a transcript file name
+func (a *declarativeAdapter) sessionIDFromPath(path string) string {
+	name := filepath.Base(path)
+	pattern := a.spec().SessionIDRegex
+	if pattern == "" {
+		return strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return ""
+	}
+	m := re.FindStringSubmatch(name)
+	switch {
+	case len(m) > 1:
+		return m[1]
+	case len(m) == 1:
+		return m[0]
+	}
+	return ""
+}
+
+func (a *declarativeAdapter) CanResume(i *Instance) bool {
+	if a.def.SessionIDEnv == "" || (a.def.ResumeFlag == "" && a.spec().ResumeCommand == "") {
+		return false
+	}
+	return a.SessionID(i) != ""
+}
+
+func (a *declarativeAdapter) ResumeCommand(i *Instance) (string, bool) {
+	if !a.CanResume(i) {
+		return "", false
+	}
+	return a.resumeCommand(i, a.SessionID(i)), true
+}
+
+func (a *declarativeAdapter) CanFork(i *Instance) bool {
+	return a.spec().ForkCommand != "" && a.SessionID(i) != ""
+}
+
+func (a *declarativeAdapter) Fork(i *Instance, newTitle, newGroupPath string, opts *ForkOptions) (*Instance, error) {
+	sessionID := a.SessionID(i)
+	if a.spec().ForkCommand == "" || sessionID == "" {
+		return nil, errForkUnsupported(a.name)
+	}
+	forked := i.newForkedInstance(newTitle, newGroupPath, opts)
+	forked.Tool = a.name
+	forked.Command = i.buildEnvSourceCommand() + expandAdapterTemplate(a.spec().ForkCommand, i, sessionID)
+	return forked, nil
+}
+
+// transcriptPath resolves the session's transcript file, or ""
+func (a *declarativeAdapter) transcriptPath(i *Instance) string {
+	spec := a.spec()
+	sessionID := a.SessionID(i)
+	if spec.Transcript != "" {
+		if strings.Contains(spec.Transcript, "{session_id}") && sessionID == "" {
+			return ""
+		}
+		return expandAdapterTemplate(spec.Transcript, i, sessionID)
+	}
+	if spec.SessionGlob == "" || sessionID == "" {
+		return ""
+	}
+	matches, _ := filepath.Glob(expandAdapterTemplate(spec.SessionGlob, i, sessionID))
+	for _, path := range matches {
+		if a.sessionIDFromPath(path) == sessionID {
+			return path
+		}
+	}
+	return ""
+}
+
+// hasTranscript reports whether the adapter is configured to read a transcript
+func (a *declarativeAdapter) hasTranscript() bool {
+	spec := a.spec()
+	return spec.Transcript != "" || spec.SessionGlob != ""
+}
+
+func (a *declarativeAdapter) LastResponse(i *Instance) (*ResponseOutput, error) {
+	if path := a.transcriptPath(i); path != "" && a.spec().LastResponse != "" {
+		values, err := extractTranscript(path, a.spec().Format, a.spec().LastResponse)
+		if err != nil {
+			return nil, err
+		}
+		for k := len(values) - 1; k >= 0; k-- {
+			if content := strings.TrimSpace(values[k]); content != "" {
+				return &ResponseOutput{Tool: a.name, Role: "assistant", Content: content, SessionID: a.SessionID(i)}, nil
+			}
+		}
+	}
+	return i.getTerminalLastResponse()
+}
+
+// Transcript only knows the latest assistant message: the TOML fields
+// describe how to extract values, not how to split the transcript into turns
+func (a *declarativeAdapter) Transcript(i *Instance) ([]TranscriptMessage, error) {
+	path := a.transcriptPath(i)
+	if path == "" || a.spec().LastResponse == "" {
+		return nil, nil
+	}
+	values, err := extractTranscript(path, a.spec().Format, a.spec().LastResponse)
+	if err != nil {
+		return nil, err
+	}
+	for k := len(values) - 1; k >= 0; k-- {
+		if content := strings.TrimSpace(values[k]); content != "" {
+			return []TranscriptMessage{{Role: "assistant", Text: content}}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (a *declarativeAdapter) Analytics(i *Instance) (*AgentAnalytics, error) {
+	path := a.transcriptPath(i)
+	if path == "" {
+		return nil, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	spec := a.spec()
+	analytics := &AgentAnalytics{Tool: a.name, LastActive: info.ModTime()}
+
+	extract := func(expr string) ([]string, error) {
+		if expr == "" {
+			return nil, nil
+		}
+		return extractTranscript(path, spec.Format, expr)
+	}
+	sum := func(values []string) int {
+		total := 0
+		for _, v := range values {
+			n, _ := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			total += int(n)
+		}
+		return total
+	}
+
+	models, err := extract(spec.Model)
+	if err != nil {
+		return nil, err
+	}
+	if len(models) > 0 {
+		analytics.Model = models[len(models)-1]
+	}
+	inputs, _ := extract(spec.InputTokens)
+	outputs, _ := extract(spec.OutputTokens)
+	turns, _ := extract(spec.Turn)
+	tools, _ := extract(spec.ToolCall)
+	analytics.InputTokens = sum(inputs)
+	analytics.OutputTokens = sum(outputs)
+	analytics.TotalTurns = len(turns)
+
+	toolCounts := make(map[string]int)
+	for _, name := range tools {
+		if name = strings.TrimSpace(name); name != "" {
+			toolCounts[name]++
+		}
+	}
+	analytics.ToolCalls = toolCallsFromCounts(toolCounts)
+	return analytics, nil
+}
+
+// StatusPatterns returns nil: a custom tool's busy/prompt patterns come from
+// its [tools.<name>] entry, which MergeToolPatterns applies
+func (a *declarativeAdapter) StatusPatterns() *tmux.RawPatterns { return nil }
+
+func (a *declarativeAdapter) MCPInfo(i *Instance) *MCPInfo {
+	spec := a.spec()
+	if spec.MCPConfig == "" {
+		return nil
+	}
+	data, err := os.ReadFile(expandAdapterTemplate(spec.MCPConfig, i, ""))
+	if err != nil {
+		return &MCPInfo{}
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return &MCPInfo{}
+	}
+	mcpPath := spec.MCPPath
+	if mcpPath == "" {
+		mcpPath = "$.mcpServers"
+	}
+	info := &MCPInfo{}
+	for _, v := range evalJSONPath(doc, mcpPath) {
+		if servers, ok := v.(map[string]any); ok {
+			for name := range servers {
+				info.Global = append(info.Global, name)
+			}
+		}
+	}
+	sort.Strings(info.Global)
+	return info
+}
+
+// expandAdapterTemplate substitutes {project} and {session_id}, then expands
+// ~ and environment variables
+func expandAdapterTemplate(tmpl string, i *Instance, sessionID string) string {
+	s := strings.ReplaceAll(tmpl, "{project}", i.ProjectPath)
+	s = strings.ReplaceAll(s, "{session_id}", sessionID)
+	s = os.ExpandEnv(s)
+	if strings.HasPrefix(s, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			s = filepath.Join(home, s[2:])
+		}
+	}
+	return s
+}
+
+// extractTranscript applies an extractor to a transcript file and returns
+// the matched values in transcript order
+func extractTranscript(path, format, expr string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if pattern, ok := strings.CutPrefix(expr, "re:"); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid extractor %q: %w", expr, err)
+		}
+		var values []string
+		for _, m := range re.FindAllStringSubmatch(string(data), -1) {
+			if len(m) > 1 {
+				values = append(values, m[1])
+			} else {
+				values = append(values, m[0])
+			}
+		}
+		return values, nil
+	}
+
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("invalid extractor %q: must start with $ (JSONPath) or re:", expr)
+	}
+
+	var values []string
+	collect := func(doc any) {
+		for _, v := range evalJSONPath(doc, expr) {
+			if s := jsonValueString(v); s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+
+	if format == "json" {
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		collect(doc)
+		return values, nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var doc any
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			continue // Skip malformed lines
+		}
+		collect(doc)
+	}
+	return values, scanner.Err()
+}
+
+// jsonValueString renders an extracted JSON value as text; arrays of strings
+// or {"text": ...} blocks (common message content shapes) are joined
+func jsonValueString(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	case []any:
+		var parts []string
+		for _, item := range val {
+			if s := jsonValueString(item); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, "\n")
+	case map[string]any:
+		if text, ok := val["text"].(string); ok {
+			return text
+		}
+	}
+	return ""
+}
+
+// jsonPathToken matches one step of the supported JSONPath subset:
+// .key, ['key'], [n] (negative counts from the end) and [*]
+var jsonPathToken = regexp.MustCompile(`^(?:\.([A-Za-z0-9_\-]+)|\.\*|\['([^']*)'\]|\[(-?\d+)\]|\[\*\])`)
+
+// evalJSONPath evaluates a JSONPath subset ($, .key, ['key'], [n], [-n],
+// [*], .*) and returns every matched value
+func evalJSONPath(doc any, path string) []any {
+	rest, ok := strings.CutPrefix(path, "$")
+	if !ok {
+		return nil
+	}
+	current := []any{doc}
+	for rest != "" {
+		m := jsonPathToken.FindStringSubmatch(rest)
+		if m == nil {
+			return nil
+		}
+		rest = rest[len(m[0]):]
+
+		var next []any
+		for _, node := range current {
+			switch {
+			case m[1] != "" || strings.HasPrefix(m[0], "['"):
+				key := m[1]
+				if key == "" {
+					key = m[2]
+				}
+				if obj, ok := node.(map[string]any); ok {
+					if v, ok := obj[key]; ok {
+						next = append(next, v)
+					}
+				}
+			case m[3] != "":
+				arr, ok := node.([]any)
+				if !ok {
+					continue
+				}
+				idx, _ := strconv.Atoi(m[3])
+				if idx < 0 {
+					idx += len(arr)
+				}
+				if idx >= 0 && idx < len(arr) {
+					next = append(next, arr[idx])
+				}
+			default: // [*] or .*
+				switch val := node.(type) {
+				case []any:
+					next = append(next, val...)
+				case map[string]any:
+					keys := make([]string, 0, len(val))
+					for k := range val {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					for _, k := range keys {
+						next = append(next, val[k])
+					}
+				}
+			}
+		}
+		current = next
+	}
+	return current
+}
@@ -0,0 +1,315 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// withTestTools installs [tools.*] entries in the cached user config
+func withTestTools(t *testing.T, tools map[string]ToolDef) {
+	t.Helper()
+	userConfigCacheMu.Lock()
+	userConfigCache = &UserConfig{Tools: tools}
+	userConfigCacheMu.Unlock()
+	t.Cleanup(func() {
+		userConfigCacheMu.Lock()
+		userConfigCache = nil
+		userConfigCacheMu.Unlock()
+	})
+}
+
+func TestGetToolAdapter(t *testing.T) {
+	withTestTools(t, map[string]ToolDef{"goose": {Command: "goose session"}})
+
+	for _, tool := range []string{"claude", "gemini", "codex", "opencode", "goose"} {
+		a := GetToolAdapter(tool)
+		if a == nil || a.Name() != tool {
+			t.Errorf("GetToolAdapter(%q) = %v", tool, a)
+		}
+	}
+	if _, ok := GetToolAdapter("goose").(*declarativeAdapter); !ok {
+		t.Error("custom tools should get a declarative adapter")
+	}
+	if a := GetToolAdapter("shell"); a != nil {
+		t.Errorf("plain commands should have no adapter, got %v", a)
+	}
+}
+
+func TestBuiltinAdapters_Fork(t *testing.T) {
+	// Without a detected conversation there is nothing to fork
+	for _, tool := range []string{"gemini", "codex", "opencode"} {
+		inst := NewInstanceWithTool("fork-test", "/tmp/project", tool)
+		if inst.CanFork() {
+			t.Errorf("%s: CanFork = true without a session ID", tool)
+		}
+		if _, err := inst.ForkWithAdapter("copy", "", nil); err == nil {
+			t.Errorf("%s: expected fork error", tool)
+		}
+	}
+
+	inst := NewInstanceWithTool("fork-test", "/tmp/project", "claude")
+	inst.ClaudeSessionID = "abc-123"
+	inst.ClaudeDetectedAt = time.Now()
+	if !inst.CanFork() {
+		t.Error("claude with a recent session ID should fork")
+	}
+}
+
+func TestBuiltinAdapters_ResumeCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("CODEX_HOME", t.TempDir())
+
+	// Nothing to resume: the restart recreates the session fresh
+	for _, tool := range []string{"claude", "gemini"} {
+		inst := NewInstanceWithTool("resume-test", "/tmp/project", tool)
+		if _, ok := GetToolAdapter(tool).ResumeCommand(inst); ok {
+			t.Errorf("%s: ResumeCommand ok without a session ID", tool)
+		}
+	}
+
+	// OpenCode restarts fresh before discovery and re-arms it
+	inst := NewInstanceWithTool("resume-test", "/tmp/project", "opencode")
+	cmd, ok := GetToolAdapter("opencode").ResumeCommand(inst)
+	if !ok || !strings.Contains(cmd, "opencode") || inst.OpenCodeStartedAt == 0 {
+		t.Errorf("opencode fresh restart = %q, %v (started %d)", cmd, ok, inst.OpenCodeStartedAt)
+	}
+	inst.OpenCodeSessionID = "ses_1"
+	if cmd, ok := GetToolAdapter("opencode").ResumeCommand(inst); !ok || !strings.Contains(cmd, "opencode -s ses_1") {
+		t.Errorf("opencode resume = %q, %v", cmd, ok)
+	}
+}
+
+func TestEvalJSONPath(t *testing.T) {
+	var doc any
+	if err := json.Unmarshal([]byte(`{"message":{"role":"assistant","content":[{"type":"text","text":"a"},{"type":"text","text":"b"}]},"usage":{"in":3},"odd key":{"x":1}}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"$.message.role", []string{"assistant"}},
+		{"$.message.content[0].text", []string{"a"}},
+		{"$.message.content[-1].text", []string{"b"}},
+		{"$.message.content[*].text", []string{"a", "b"}},
+		{"$['odd key'].x", []string{"1"}},
+		{"$.usage.in", []string{"3"}},
+		{"$.missing.path", nil},
+		{"$.message.content[5]", nil},
+		{"message.role", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, v := range evalJSONPath(doc, tt.path) {
+			got = append(got, jsonValueString(v))
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("evalJSONPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDeclarativeAdapter_TranscriptJSONL(t *testing.T) {
+	project := t.TempDir()
+	transcript := `{"role":"user","content":"hi"}
+{"role":"assistant","model":"gpt-4.1","content":[{"type":"text","text":"first answer"}],"usage":{"input":100,"output":20}}
+{"role":"tool","name":"shell"}
+not json
+{"role":"user","content":"again"}
+{"role":"tool","name":"shell"}
+{"role":"tool","name":"edit"}
+{"role":"assistant","model":"gpt-4.1","content":[{"type":"text","text":"second answer"}],"usage":{"input":300,"output":40}}
+`
+	if err := os.WriteFile(filepath.Join(project, "chat.jsonl"), []byte(transcript), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	withTestTools(t, map[string]ToolDef{"goose": {
+		Command: "goose session",
+		Adapter: &ToolAdapterDef{
+			Transcript:   "{project}/chat.jsonl",
+			LastResponse: "$.content[*].text",
+			Model:        "$.model",
+			InputTokens:  "$.usage.input",
+			OutputTokens: "$.usage.output",
+			ToolCall:     "$.name",
+			Turn:         "re:\"role\":\"user\"",
+		},
+	}})
+
+	inst := NewInstanceWithTool("goose-test", project, "goose")
+	resp, err := inst.GetLastResponse()
+	if err != nil {
+		t.Fatalf("GetLastResponse: %v", err)
+	}
+	if resp.Content != "second answer" || resp.Tool != "goose" {
+		t.Errorf("response = %+v", resp)
+	}
+
+	if !ToolHasAnalytics("goose") {
+		t.Error("ToolHasAnalytics(goose) = false with a transcript configured")
+	}
+	a, err := inst.LoadAnalytics()
+	if err != nil || a == nil {
+		t.Fatalf("LoadAnalytics = %+v, %v", a, err)
+	}
+	if a.Model != "gpt-4.1" || a.InputTokens != 400 || a.OutputTokens != 60 || a.TotalTurns != 2 {
+		t.Errorf("analytics = %+v", a)
+	}
+	want := []ToolCall{{Name: "edit", Count: 1}, {Name: "shell", Count: 2}}
+	if len(a.ToolCalls) != 2 || a.ToolCalls[0] != want[0] || a.ToolCalls[1] != want[1] {
+		t.Errorf("ToolCalls = %+v, want %+v", a.ToolCalls, want)
+	}
+}
+
+func TestDeclarativeAdapter_TextTranscript(t *testing.T) {
+	project := t.TempDir()
+	history := "#### fix the bug\n\nSure, patched main.go.\n\n#### thanks\n\nYou're welcome!\n"
+	if err := os.WriteFile(filepath.Join(project, ".aider.chat.history.md"), []byte(history), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	withTestTools(t, map[string]ToolDef{"aider": {
+		Command: "aider",
+		Adapter: &ToolAdapterDef{
+			Transcript:   "{project}/.aider.chat.history.md",
+			Format:       "text",
+			LastResponse: `re:(?m)^#### .*\n\n((?:[^#\n].*\n?)+)`,
+			Turn:         "re:(?m)^#### ",
+		},
+	}})
+
+	inst := NewInstanceWithTool("aider-test", project, "aider")
+	resp, err := inst.GetLastResponse()
+	if err != nil {
+		t.Fatalf("GetLastResponse: %v", err)
+	}
+	if resp.Content != "You're welcome!" {
+		t.Errorf("Content = %q", resp.Content)
+	}
+	if a, err := inst.LoadAnalytics(); err != nil || a.TotalTurns != 2 {
+		t.Errorf("LoadAnalytics = %+v, %v", a, err)
+	}
+}
+
+func TestDeclarativeAdapter_NoAdapterTable(t *testing.T) {
+	withTestTools(t, map[string]ToolDef{"vibe": {Command: "vibe", ResumeFlag: "--resume", SessionIDEnv: "VIBE_SESSION_ID"}})
+
+	if ToolHasAnalytics("vibe") {
+		t.Error("custom tools without a transcript have no analytics")
+	}
+	inst := NewInstanceWithTool("vibe-test", "/tmp/project", "vibe")
+	if a, err := inst.LoadAnalytics(); a != nil || err != nil {
+		t.Errorf("LoadAnalytics = %+v, %v; want nil, nil", a, err)
+	}
+	if inst.CanFork() {
+		t.Error("custom tools without fork_command cannot fork")
+	}
+	if info := inst.GetMCPInfo(); info != nil {
+		t.Errorf("GetMCPInfo = %+v, want nil without mcp_config", info)
+	}
+	inst.Command = "vibe"
+	if got := inst.buildStartCommand(); !strings.HasSuffix(got, "vibe") {
+		t.Errorf("buildStartCommand = %q", got)
+	}
+}
+
+func TestDeclarativeAdapter_ResumeAndFork(t *testing.T) {
+	withTestTools(t, map[string]ToolDef{"goose": {
+		Command:      "goose session",
+		SessionIDEnv: "GOOSE_SESSION_ID",
+		Adapter: &ToolAdapterDef{
+			ResumeCommand: "goose session --resume --name {session_id}",
+			ForkCommand:   "goose session --fork {session_id}",
+		},
+	}})
+	a := GetToolAdapter("goose").(*declarativeAdapter)
+	inst := NewInstanceWithTool("goose-test", "/tmp/project", "goose")
+
+	got := a.resumeCommand(inst, "s1")
+	want := "tmux set-environment GOOSE_SESSION_ID s1 && goose session --resume --name s1"
+	if got != want {
+		t.Errorf("resumeCommand = %q, want %q", got, want)
+	}
+	// No session ID known yet (no tmux env)
+	if a.CanResume(inst) || a.CanFork(inst) {
+		t.Error("resume/fork need a discovered session ID")
+	}
+	if _, err := a.Fork(inst, "copy", "", nil); err == nil {
+		t.Error("expected fork error without a session ID")
+	}
+}
+
+func TestDeclarativeAdapter_FindSession(t *testing.T) {
+	dir := t.TempDir()
+	withTestTools(t, map[string]ToolDef{"goose": {
+		Command: "goose session",
+		Adapter: &ToolAdapterDef{
+			SessionGlob:    filepath.Join(dir, "*.jsonl"),
+			SessionIDRegex: `^session-(.+)\.jsonl$`,
+		},
+	}})
+	a := GetToolAdapter("goose").(*declarativeAdapter)
+	inst := NewInstanceWithTool("goose-test", "/tmp/project", "goose")
+
+	old := filepath.Join(dir, "session-old.jsonl")
+	fresh := filepath.Join(dir, "session-new.jsonl")
+	for _, p := range []string{old, fresh} {
+		if err := os.WriteFile(p, []byte("{}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(old, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := a.findSession(inst, time.Now().Add(-time.Minute)); got != "new" {
+		t.Errorf("findSession = %q, want new", got)
+	}
+	if got := a.findSession(inst, time.Now().Add(time.Minute)); got != "" {
+		t.Errorf("files older than the start should be ignored, got %q", got)
+	}
+}
+
+func TestDeclarativeAdapter_MCPInfo(t *testing.T) {
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, "mcp.json"),
+		[]byte(`{"extensions":{"github":{},"exa":{}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	withTestTools(t, map[string]ToolDef{"goose": {
+		Command: "goose session",
+		Adapter: &ToolAdapterDef{MCPConfig: "{project}/mcp.json", MCPPath: "$.extensions"},
+	}})
+
+	info := NewInstanceWithTool("goose-test", project, "goose").GetMCPInfo()
+	if info == nil || strings.Join(info.Global, ",") != "exa,github" {
+		t.Errorf("GetMCPInfo = %+v", info)
+	}
+}
+
+func TestToolAdapterDef_TOML(t *testing.T) {
+	content := `
+[tools.goose]
+command = "goose session"
+session_id_env = "GOOSE_SESSION_ID"
+
+[tools.goose.adapter]
+session_glob = "~/.local/share/goose/sessions/*.jsonl"
+resume_command = "goose session --resume --name {session_id}"
+last_response = "$.content[*].text"
+`
+	var config UserConfig
+	if _, err := toml.Decode(content, &config); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	def := config.Tools["goose"]
+	if def.Adapter == nil || def.Adapter.SessionGlob == "" || def.Adapter.LastResponse != "$.content[*].text" {
+		t.Errorf("adapter = %+v", def.Adapter)
+	}
+}
@@ -2,6 +2,7 @@ package session
 
 import (
 	"encoding/json"
+	"strings"
 )
 
 // ToolOptions is the interface for tool-specific launch options
@@ -29,6 +30,18 @@ type ClaudeOptions struct {
 	UseChrome bool `json:"use_chrome,omitempty"`
 	// UseTeammateMode adds --teammate-mode tmux flag
 	UseTeammateMode bool `json:"use_teammate_mode,omitempty"`
+	// Model adds --model (alias like "sonnet" or a full model name)
+	Model string `json:"model,omitempty"`
+	// PermissionMode adds --permission-mode (default, acceptEdits, plan, bypassPermissions)
+	PermissionMode string `json:"permission_mode,omitempty"`
+	// AllowedTools adds --allowedTools (e.g. "Bash(git:*)", "Edit")
+	AllowedTools []string `json:"allowed_tools,omitempty"`
+	// DisallowedTools adds --disallowedTools
+	DisallowedTools []string `json:"disallowed_tools,omitempty"`
+	// AppendSystemPrompt adds --append-system-prompt
+	AppendSystemPrompt string `json:"append_system_prompt,omitempty"`
+	// AddDirs adds one --add-dir per directory
+	AddDirs []string `json:"add_dirs,omitempty"`
 
 	// Transient fields for worktree fork (not persisted)
 	WorkDir          string `json:"-"`
@@ -70,7 +83,7 @@ func (o *ClaudeOptions) ToArgs() []string {
 		args = append(args, "--teammate-mode", "tmux")
 	}
 
-	return args
+	return append(args, o.LaunchArgs()...)
 }
 
 // ToArgsForFork returns arguments suitable for fork resume command
@@ -90,9 +103,82 @@ func (o *ClaudeOptions) ToArgsForFork() []string {
 		args = append(args, "--teammate-mode", "tmux")
 	}
 
+	return append(args, o.LaunchArgs()...)
+}
+
+// ClaudePermissionModes lists the values accepted by claude --permission-mode
+var ClaudePermissionModes = []string{"default", "acceptEdits", "plan", "bypassPermissions"}
+
+// IsValidClaudePermissionMode reports whether mode is empty or a known permission mode
+func IsValidClaudePermissionMode(mode string) bool {
+	return mode == "" || containsString(ClaudePermissionModes, mode)
+}
+
+// LaunchArgs returns the model, permission, tool filter, system prompt and
+// --add-dir arguments. These apply to new, resumed, restarted and forked
+// sessions alike.
+func (o *ClaudeOptions) LaunchArgs() []string {
+	var args []string
+	if o.Model != "" {
+		args = append(args, "--model", o.Model)
+	}
+	if o.PermissionMode != "" {
+		args = append(args, "--permission-mode", o.PermissionMode)
+	}
+	if len(o.AllowedTools) > 0 {
+		args = append(args, "--allowedTools", strings.Join(o.AllowedTools, ","))
+	}
+	if len(o.DisallowedTools) > 0 {
+		args = append(args, "--disallowedTools", strings.Join(o.DisallowedTools, ","))
+	}
+	if o.AppendSystemPrompt != "" {
+		args = append(args, "--append-system-prompt", o.AppendSystemPrompt)
+	}
+	for _, dir := range o.AddDirs {
+		args = append(args, "--add-dir", dir)
+	}
 	return args
 }
 
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseToolList splits a comma-separated tool list, trimming blanks.
+// Commas inside parentheses (e.g. "Bash(git diff:*)") are kept.
+func ParseToolList(s string) []string {
+	var tools []string
+	depth, start := 0, 0
+	flush := func(end int) {
+		if t := strings.TrimSpace(s[start:end]); t != "" {
+			tools = append(tools, t)
+		}
+	}
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				flush(i)
+				start = i + 1
+			}
+		}
+	}
+	flush(len(s))
+	return tools
+}
+
 // NewClaudeOptions creates ClaudeOptions with defaults from config
 func NewClaudeOptions(config *UserConfig) *ClaudeOptions {
 	opts := &ClaudeOptions{
@@ -110,6 +196,30 @@ type CodexOptions struct {
 	// YoloMode enables --yolo flag (bypass approvals and sandbox)
 	// nil = inherit from global config, true/false = explicit override
 	YoloMode *bool `json:"yolo_mode,omitempty"`
+	// Model adds --model (e.g. "o3", "gpt-5-codex")
+	Model string `json:"model,omitempty"`
+	// Profile adds --profile, selecting a [profiles.<name>] table in Codex's config.toml
+	Profile string `json:"profile,omitempty"`
+	// Sandbox adds --sandbox (read-only, workspace-write, danger-full-access)
+	Sandbox string `json:"sandbox,omitempty"`
+	// ApprovalPolicy adds --ask-for-approval (untrusted, on-failure, on-request, never)
+	ApprovalPolicy string `json:"approval_policy,omitempty"`
+}
+
+// CodexSandboxModes lists the values accepted by codex --sandbox
+var CodexSandboxModes = []string{"read-only", "workspace-write", "danger-full-access"}
+
+// CodexApprovalPolicies lists the values accepted by codex --ask-for-approval
+var CodexApprovalPolicies = []string{"untrusted", "on-failure", "on-request", "never"}
+
+// IsValidCodexSandboxMode reports whether mode is empty or a known sandbox mode
+func IsValidCodexSandboxMode(mode string) bool {
+	return mode == "" || containsString(CodexSandboxModes, mode)
+}
+
+// IsValidCodexApprovalPolicy reports whether policy is empty or a known approval policy
+func IsValidCodexApprovalPolicy(policy string) bool {
+	return policy == "" || containsString(CodexApprovalPolicies, policy)
 }
 
 // ToolName returns "codex"
@@ -117,21 +227,90 @@ func (o *CodexOptions) ToolName() string {
 	return "codex"
 }
 
-// ToArgs returns command-line arguments based on options
+// ToArgs returns command-line arguments based on options.
+// --yolo already bypasses the sandbox and approvals, so Sandbox and
+// ApprovalPolicy are left out when it is set.
 func (o *CodexOptions) ToArgs() []string {
 	var args []string
 	if o.YoloMode != nil && *o.YoloMode {
 		args = append(args, "--yolo")
+	} else {
+		if o.Sandbox != "" {
+			args = append(args, "--sandbox", o.Sandbox)
+		}
+		if o.ApprovalPolicy != "" {
+			args = append(args, "--ask-for-approval", o.ApprovalPolicy)
+		}
+	}
+	if o.Model != "" {
+		args = append(args, "--model", o.Model)
+	}
+	if o.Profile != "" {
+		args = append(args, "--profile", o.Profile)
 	}
 	return args
 }
 
+// Merge returns a copy of o with the fields set in override replacing its own
+func (o *CodexOptions) Merge(override *CodexOptions) *CodexOptions {
+	merged := *o
+	if override == nil {
+		return &merged
+	}
+	if override.YoloMode != nil {
+		yolo := *override.YoloMode
+		merged.YoloMode = &yolo
+	}
+	if override.Model != "" {
+		merged.Model = override.Model
+	}
+	if override.Profile != "" {
+		merged.Profile = override.Profile
+	}
+	if override.Sandbox != "" {
+		merged.Sandbox = override.Sandbox
+	}
+	if override.ApprovalPolicy != "" {
+		merged.ApprovalPolicy = override.ApprovalPolicy
+	}
+	return &merged
+}
+
 // NewCodexOptions creates CodexOptions with defaults from global config
 func NewCodexOptions(config *UserConfig) *CodexOptions {
 	opts := &CodexOptions{}
-	if config != nil && config.Codex.YoloMode {
-		yolo := true
-		opts.YoloMode = &yolo
+	if config != nil {
+		if config.Codex.YoloMode {
+			yolo := true
+			opts.YoloMode = &yolo
+		}
+		opts.Model = config.Codex.Model
+		opts.Profile = config.Codex.Profile
+		opts.Sandbox = config.Codex.Sandbox
+		opts.ApprovalPolicy = config.Codex.ApprovalPolicy
+	}
+	return opts
+}
+
+// NewCodexOptionsForGroup creates CodexOptions with defaults from global
+// config overlaid by [codex.groups."<path>"] entries for groupPath and its
+// ancestors, most specific last
+func NewCodexOptionsForGroup(config *UserConfig, groupPath string) *CodexOptions {
+	opts := NewCodexOptions(config)
+	if config == nil || len(config.Codex.Groups) == 0 || groupPath == "" {
+		return opts
+	}
+	parts := strings.Split(groupPath, "/")
+	for n := 1; n <= len(parts); n++ {
+		if group, ok := config.Codex.Groups[strings.Join(parts[:n], "/")]; ok {
+			opts = opts.Merge(&CodexOptions{
+				YoloMode:       group.YoloMode,
+				Model:          group.Model,
+				Profile:        group.Profile,
+				Sandbox:        group.Sandbox,
+				ApprovalPolicy: group.ApprovalPolicy,
+			})
+		}
 	}
 	return opts
 }
@@ -400,6 +400,19 @@ func TestCodexOptions_ToArgs(t *testing.T) {
 			opts:     CodexOptions{YoloMode: boolPtr(false)},
 			expected: nil,
 		},
+		{
+			name: "all options",
+			opts: CodexOptions{Model: "o3", Profile: "work", Sandbox: "workspace-write", ApprovalPolicy: "on-request"},
+			expected: []string{
+				"--sandbox", "workspace-write", "--ask-for-approval", "on-request",
+				"--model", "o3", "--profile", "work",
+			},
+		},
+		{
+			name:     "yolo drops sandbox and approval",
+			opts:     CodexOptions{YoloMode: boolPtr(true), Model: "o3", Sandbox: "read-only", ApprovalPolicy: "never"},
+			expected: []string{"--yolo", "--model", "o3"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -725,6 +738,13 @@ func TestClaudeOptions_RoundTrip(t *testing.T) {
 		SkipPermissions: true,
 		UseChrome:       true,
 		UseTeammateMode: true,
+		Model:           "opus",
+		PermissionMode:  "plan",
+		AllowedTools:    []string{"Bash(git:*)", "Edit"},
+		DisallowedTools: []string{"WebFetch"},
+		AddDirs:         []string{"../shared"},
+
+		AppendSystemPrompt: "Be terse.",
 	}
 
 	// Marshal
@@ -744,3 +764,103 @@ func TestClaudeOptions_RoundTrip(t *testing.T) {
 		t.Errorf("round-trip failed: original=%+v, restored=%+v", original, restored)
 	}
 }
+
+func TestClaudeOptions_LaunchArgs(t *testing.T) {
+	opts := ClaudeOptions{
+		SessionMode:        "continue",
+		Model:              "opus",
+		PermissionMode:     "plan",
+		AllowedTools:       []string{"Bash(git diff:*)", "Edit"},
+		DisallowedTools:    []string{"WebFetch"},
+		AppendSystemPrompt: "Be terse.",
+		AddDirs:            []string{"../shared", "/tmp/data"},
+	}
+	launch := []string{
+		"--model", "opus",
+		"--permission-mode", "plan",
+		"--allowedTools", "Bash(git diff:*),Edit",
+		"--disallowedTools", "WebFetch",
+		"--append-system-prompt", "Be terse.",
+		"--add-dir", "../shared",
+		"--add-dir", "/tmp/data",
+	}
+
+	if got := opts.ToArgs(); !reflect.DeepEqual(got, append([]string{"-c"}, launch...)) {
+		t.Errorf("ToArgs() = %v", got)
+	}
+	if got := opts.ToArgsForFork(); !reflect.DeepEqual(got, launch) {
+		t.Errorf("ToArgsForFork() = %v, expected %v", got, launch)
+	}
+}
+
+func TestParseToolList(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{" , ", nil},
+		{"Edit", []string{"Edit"}},
+		{"Edit, Write ,Read", []string{"Edit", "Write", "Read"}},
+		{"Bash(git add,commit:*),Edit", []string{"Bash(git add,commit:*)", "Edit"}},
+	}
+	for _, tt := range tests {
+		if got := ParseToolList(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseToolList(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidClaudePermissionMode(t *testing.T) {
+	for _, mode := range append([]string{""}, ClaudePermissionModes...) {
+		if !IsValidClaudePermissionMode(mode) {
+			t.Errorf("IsValidClaudePermissionMode(%q) = false, want true", mode)
+		}
+	}
+	if IsValidClaudePermissionMode("yolo") {
+		t.Error("IsValidClaudePermissionMode(\"yolo\") = true, want false")
+	}
+}
+
+func TestNewCodexOptionsForGroup(t *testing.T) {
+	config := &UserConfig{Codex: CodexSettings{
+		Model:   "gpt-5-codex",
+		Sandbox: "workspace-write",
+		Groups: map[string]CodexGroupSettings{
+			"work":        {Sandbox: "read-only", Profile: "work"},
+			"work/client": {ApprovalPolicy: "untrusted", YoloMode: boolPtr(false)},
+		},
+	}}
+
+	got := NewCodexOptionsForGroup(config, "work/client/api")
+	want := &CodexOptions{
+		YoloMode:       boolPtr(false),
+		Model:          "gpt-5-codex",
+		Profile:        "work",
+		Sandbox:        "read-only",
+		ApprovalPolicy: "untrusted",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewCodexOptionsForGroup(work/client/api) = %+v, want %+v", got, want)
+	}
+
+	if got := NewCodexOptionsForGroup(config, "personal"); got.Sandbox != "workspace-write" || got.Profile != "" {
+		t.Errorf("unmatched group should use [codex] defaults, got %+v", got)
+	}
+	if got := NewCodexOptionsForGroup(nil, "work"); !reflect.DeepEqual(got, &CodexOptions{}) {
+		t.Errorf("nil config should give empty options, got %+v", got)
+	}
+}
+
+func TestCodexOptions_Merge(t *testing.T) {
+	base := &CodexOptions{YoloMode: boolPtr(true), Model: "o3", Sandbox: "read-only"}
+	merged := base.Merge(&CodexOptions{YoloMode: boolPtr(false), Sandbox: "workspace-write"})
+
+	want := &CodexOptions{YoloMode: boolPtr(false), Model: "o3", Sandbox: "workspace-write"}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("Merge() = %+v, want %+v", merged, want)
+	}
+	if !*base.YoloMode || base.Sandbox != "read-only" {
+		t.Errorf("Merge must not modify the receiver, got %+v", base)
+	}
+}
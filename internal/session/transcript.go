@@ -0,0 +1,229 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/dag"
+)
+
+// TranscriptMessage is one conversation message in a tool-neutral shape.
+// Tool calls made while answering are attached to the assistant message.
+type TranscriptMessage struct {
+	Role      string // "user" or "assistant"
+	Text      string
+	ToolCalls []TranscriptToolCall
+	Timestamp time.Time
+}
+
+// TranscriptToolCall is a tool invocation recorded in a transcript. Input is
+// the arguments object, or a JSON string for free-form tools (apply_patch).
+type TranscriptToolCall struct {
+	Name  string
+	Input json.RawMessage
+}
+
+// readClaudeTranscript reads the active branch of a Claude JSONL transcript
+func readClaudeTranscript(jsonlPath string) ([]TranscriptMessage, error) {
+	result, err := dag.ReadSessionFile(jsonlPath)
+	if err != nil || result == nil {
+		return nil, err
+	}
+
+	var messages []TranscriptMessage
+	for _, m := range result.Messages {
+		if m.Role != "user" && m.Role != "assistant" {
+			continue
+		}
+		msg := TranscriptMessage{Role: m.Role, Text: strings.TrimSpace(m.Content), Timestamp: m.Timestamp}
+		var body struct {
+			Content []struct {
+				Type  string          `json:"type"`
+				Name  string          `json:"name"`
+				Input json.RawMessage `json:"input"`
+			} `json:"content"`
+		}
+		if json.Unmarshal(m.Message, &body) == nil {
+			for _, block := range body.Content {
+				if block.Type == "tool_use" {
+					msg.ToolCalls = append(msg.ToolCalls, TranscriptToolCall{Name: block.Name, Input: block.Input})
+				}
+			}
+		}
+		// User entries that only carry tool results are not turns
+		if msg.Text == "" && len(msg.ToolCalls) == 0 {
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// readGeminiTranscript reads a Gemini session file ("gemini" messages are the
+// assistant's)
+func readGeminiTranscript(sessionFile string) ([]TranscriptMessage, error) {
+	data, err := os.ReadFile(sessionFile)
+	if err != nil {
+		return nil, err
+	}
+	var session struct {
+		Messages []struct {
+			Timestamp time.Time `json:"timestamp"`
+			Type      string    `json:"type"`
+			Content   string    `json:"content"`
+			ToolCalls []struct {
+				Name string          `json:"name"`
+				Args json.RawMessage `json:"args"`
+			} `json:"toolCalls"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse session file: %w", err)
+	}
+
+	var messages []TranscriptMessage
+	for _, m := range session.Messages {
+		role := m.Type
+		switch m.Type {
+		case "user":
+		case "gemini":
+			role = "assistant"
+		default:
+			continue
+		}
+		msg := TranscriptMessage{Role: role, Text: strings.TrimSpace(m.Content), Timestamp: m.Timestamp}
+		for _, call := range m.ToolCalls {
+			msg.ToolCalls = append(msg.ToolCalls, TranscriptToolCall{Name: call.Name, Input: call.Args})
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// readCodexTranscript reads the response items of a Codex rollout. Tool calls
+// are attached to the preceding assistant message (or a new one).
+func readCodexTranscript(path string) ([]TranscriptMessage, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var messages []TranscriptMessage
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var entry struct {
+			Type      string    `json:"type"`
+			Timestamp time.Time `json:"timestamp"`
+			Payload   struct {
+				Type    string `json:"type"`
+				Role    string `json:"role"`
+				Name    string `json:"name"`
+				Content []struct {
+					Text string `json:"text"`
+				} `json:"content"`
+				Arguments string `json:"arguments"` // function_call: JSON-encoded object
+				Input     string `json:"input"`     // custom_tool_call: free-form text
+			} `json:"payload"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Type != "response_item" {
+			continue
+		}
+
+		p := entry.Payload
+		switch p.Type {
+		case "message":
+			if p.Role != "user" && p.Role != "assistant" {
+				continue
+			}
+			var texts []string
+			for _, c := range p.Content {
+				if c.Text != "" {
+					texts = append(texts, c.Text)
+				}
+			}
+			messages = append(messages, TranscriptMessage{
+				Role:      p.Role,
+				Text:      strings.TrimSpace(strings.Join(texts, "\n")),
+				Timestamp: entry.Timestamp,
+			})
+		case "function_call", "custom_tool_call":
+			call := TranscriptToolCall{Name: p.Name}
+			if p.Type == "function_call" && json.Valid([]byte(p.Arguments)) {
+				call.Input = json.RawMessage(p.Arguments)
+			} else {
+				call.Input, _ = json.Marshal(p.Input)
+			}
+			if n := len(messages); n == 0 || messages[n-1].Role != "assistant" {
+				messages = append(messages, TranscriptMessage{Role: "assistant", Timestamp: entry.Timestamp})
+			}
+			last := &messages[len(messages)-1]
+			last.ToolCalls = append(last.ToolCalls, call)
+		}
+	}
+	return messages, scanner.Err()
+}
+
+// readOpenCodeTranscript reads an OpenCode session's messages with their
+// text and tool parts
+func readOpenCodeTranscript(storageDir, sessionID string) ([]TranscriptMessage, error) {
+	messageDir := filepath.Join(storageDir, "message", sessionID)
+	entries, err := os.ReadDir(messageDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []openCodeMessage
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(messageDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var msg openCodeMessage
+		if json.Unmarshal(data, &msg) == nil {
+			records = append(records, msg)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Time.Created < records[j].Time.Created
+	})
+
+	var messages []TranscriptMessage
+	for _, rec := range records {
+		if rec.Role != "user" && rec.Role != "assistant" {
+			continue
+		}
+		parts := rec.Parts
+		if len(parts) == 0 {
+			parts = readOpenCodeParts(storageDir, rec.ID)
+		}
+		msg := TranscriptMessage{Role: rec.Role}
+		if rec.Time.Created > 0 {
+			msg.Timestamp = time.UnixMilli(rec.Time.Created)
+		}
+		var texts []string
+		for _, part := range parts {
+			switch part.Type {
+			case "text":
+				if part.Text != "" {
+					texts = append(texts, part.Text)
+				}
+			case "tool":
+				msg.ToolCalls = append(msg.ToolCalls, TranscriptToolCall{Name: part.Tool, Input: part.State.Input})
+			}
+		}
+		msg.Text = strings.TrimSpace(strings.Join(texts, "\n"))
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
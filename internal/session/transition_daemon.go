@@ -120,6 +120,7 @@ func (d *TransitionDaemon) syncProfile(profile string) time.Duration {
 	db := storage.GetDB()
 	tuiAlive := false
 	if db != nil {
+		markNotifyDaemonAlive(db)
 		if count, err := db.AliveInstanceCount(); err == nil && count > 0 {
 			tuiAlive = true
 		}
@@ -162,6 +163,11 @@ func (d *TransitionDaemon) syncProfile(profile string) time.Duration {
 	prev := d.lastStatus[profile]
 	for id, to := range statuses {
 		from := normalizeStatusString(prev[id])
+		// The TUI checkpoints its own transitions, applies context policies
+		// and delivers queued messages while it is running.
+		if inst := byID[id]; inst != nil && !tuiAlive {
+			_ = RunTurnEndHooks(profile, db, inst, Status(from), Status(to))
+		}
 		if !ShouldNotifyTransition(from, to) {
 			continue
 		}
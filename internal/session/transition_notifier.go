@@ -1,6 +1,7 @@
 package session
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -142,39 +143,41 @@ func (n *TransitionNotifier) dispatch(event TransitionNotificationEvent) Transit
 		return event
 	}
 
+	target, kind, result := deliverToParentOrConductor(event.Profile, child, byID, instances, buildTransitionMessage(event))
+	if target != nil {
+		event.TargetSessionID = target.ID
+		event.TargetKind = kind
+	}
+	event.DeliveryResult = result
+	return event
+}
+
+// deliverToParentOrConductor sends message to child's parent session,
+// falling back to the profile's conductor. A busy target gets the message
+// queued for the end of its turn. It returns the target (nil when there is
+// none), its kind (parent | conductor) and the delivery result.
+func deliverToParentOrConductor(profile string, child *Instance, byID map[string]*Instance, instances []*Instance, message string) (*Instance, string, string) {
 	parentID := strings.TrimSpace(child.ParentSessionID)
 	if parentID != "" && parentID != child.ID {
 		if parent := byID[parentID]; parent != nil {
-			if err := SendSessionMessageReliable(event.Profile, parent.ID, buildTransitionMessage(event)); err == nil {
-				event.TargetSessionID = parent.ID
-				event.TargetKind = "parent"
-				event.DeliveryResult = transitionDeliverySent
-				return event
+			if err := SendOrQueueSessionMessage(profile, parent.ID, message); err == nil {
+				return parent, "parent", transitionDeliverySent
 			}
 		}
 	}
 
-	conductor := selectFallbackConductor(event.Profile, instances)
+	conductor := selectFallbackConductor(profile, instances)
 	if conductor == nil || conductor.ID == child.ID {
-		event.DeliveryResult = transitionDeliveryDropped
-		return event
+		return nil, "", transitionDeliveryDropped
 	}
 
-	if err := SendSessionMessageReliable(event.Profile, conductor.ID, buildTransitionMessage(event)); err != nil {
-		event.TargetSessionID = conductor.ID
-		event.TargetKind = "conductor"
-		event.DeliveryResult = transitionDeliveryFailed
-		return event
+	if err := SendOrQueueSessionMessage(profile, conductor.ID, message); err != nil {
+		return conductor, "conductor", transitionDeliveryFailed
 	}
-
-	event.TargetSessionID = conductor.ID
-	event.TargetKind = "conductor"
 	if parentID != "" {
-		event.DeliveryResult = transitionDeliveryFallbackSent
-	} else {
-		event.DeliveryResult = transitionDeliverySent
+		return conductor, "conductor", transitionDeliveryFallbackSent
 	}
-	return event
+	return conductor, "conductor", transitionDeliverySent
 }
 
 func buildTransitionMessage(event TransitionNotificationEvent) string {
@@ -320,6 +323,35 @@ func (n *TransitionNotifier) logEvent(event TransitionNotificationEvent) {
 	_, _ = f.Write(append(line, '\n'))
 }
 
+// TransitionDeliveryStats tallies the notifier log by profile and delivery
+// result (sent, fallback_sent, failed, dropped_no_target). A missing log
+// yields an empty map.
+func TransitionDeliveryStats() (map[string]map[string]int, error) {
+	stats := map[string]map[string]int{}
+	f, err := os.Open(transitionNotifyLogPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return stats, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event TransitionNotificationEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.DeliveryResult == "" {
+			continue
+		}
+		if stats[event.Profile] == nil {
+			stats[event.Profile] = map[string]int{}
+		}
+		stats[event.Profile][event.DeliveryResult]++
+	}
+	return stats, scanner.Err()
+}
+
 func transitionNotifyStatePath() string {
 	dir, err := GetAgentDeckDir()
 	if err != nil {
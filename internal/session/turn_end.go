@@ -0,0 +1,36 @@
+package session
+
+import (
+	"github.com/asheshgoplani/agent-deck/internal/git"
+	"github.com/asheshgoplani/agent-deck/internal/statedb"
+)
+
+// TurnEndResult records what RunTurnEndHooks did for one transition.
+type TurnEndResult struct {
+	Checkpoint    *git.Checkpoint
+	CheckpointErr error
+	Rule          *ContextRule // context policy rule that acted, if any
+	PolicyErr     error
+	Delivered     *statedb.QueuedMessageRow
+	DeliveryErr   error
+}
+
+// RunTurnEndHooks runs everything that happens when an agent turn ends, in
+// order: the auto-checkpoint, the context policy, then queued message
+// delivery. Running them in sequence keeps a compaction command and a queued
+// message from being typed into the pane at the same time; when the policy
+// compacted, the queue waits for the compaction's own turn to end. It does
+// nothing for transitions that don't end a turn.
+func RunTurnEndHooks(profile string, db *statedb.StateDB, inst *Instance, from, to Status) TurnEndResult {
+	var res TurnEndResult
+	if inst == nil || !IsTurnEnd(from, to) {
+		return res
+	}
+	res.Checkpoint, res.CheckpointErr = AutoCheckpoint(profile, inst, from, to)
+	res.Rule, res.PolicyErr = ApplyContextPolicy(profile, inst, from, to)
+	if res.Rule != nil && res.Rule.Action == ContextActionCompact {
+		return res
+	}
+	res.Delivered, res.DeliveryErr = DeliverQueuedMessage(profile, db, inst, from, to)
+	return res
+}
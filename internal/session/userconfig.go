@@ -67,6 +67,9 @@ type UserConfig struct {
 	// Worktree defines git worktree preferences
 	Worktree WorktreeSettings `toml:"worktree"`
 
+	// Forge defines GitHub/GitLab/Gitea API access for `worktree pr`
+	Forge ForgeSettings `toml:"forge"`
+
 	// GlobalSearch defines global conversation search settings
 	GlobalSearch GlobalSearchSettings `toml:"global_search"`
 
@@ -105,6 +108,17 @@ type UserConfig struct {
 
 	// Tmux defines tmux option overrides applied to every session
 	Tmux TmuxSettings `toml:"tmux"`
+
+	// Context defines model context-window limits used for context usage
+	// and context policies
+	Context ContextSettings `toml:"context"`
+
+	// Keys overrides TUI key bindings by action ID. An empty list unbinds the action.
+	// Example:
+	// [keys]
+	// restart = ["ctrl+t"]
+	// command_palette = ["ctrl+k", ":", "ctrl+p"]
+	Keys map[string][]string `toml:"keys"`
 }
 
 // ProfileSettings defines per-profile configuration overrides.
@@ -503,6 +517,31 @@ type CodexSettings struct {
 	// YoloMode enables --yolo flag for Codex sessions (bypass approvals and sandbox)
 	// Default: false
 	YoloMode bool `toml:"yolo_mode"`
+
+	// Model is the default --model for new Codex sessions (empty = Codex's default)
+	Model string `toml:"model"`
+
+	// Profile is the default --profile (a [profiles.<name>] table in ~/.codex/config.toml)
+	Profile string `toml:"profile"`
+
+	// Sandbox is the default --sandbox: "read-only", "workspace-write" or "danger-full-access"
+	Sandbox string `toml:"sandbox"`
+
+	// ApprovalPolicy is the default --ask-for-approval: "untrusted", "on-failure", "on-request" or "never"
+	ApprovalPolicy string `toml:"approval_policy"`
+
+	// Groups overrides the defaults above per group path, e.g. [codex.groups."work/client"].
+	// A group's settings also apply to its subgroups.
+	Groups map[string]CodexGroupSettings `toml:"groups"`
+}
+
+// CodexGroupSettings are per-group Codex defaults; empty fields inherit
+type CodexGroupSettings struct {
+	YoloMode       *bool  `toml:"yolo_mode"`
+	Model          string `toml:"model"`
+	Profile        string `toml:"profile"`
+	Sandbox        string `toml:"sandbox"`
+	ApprovalPolicy string `toml:"approval_policy"`
 }
 
 // WorktreeSettings contains git worktree preferences.
@@ -519,6 +558,16 @@ type WorktreeSettings struct {
 	// Unknown variables like {foo} are left as-is in the path.
 	// If set, overrides DefaultLocation.
 	PathTemplate *string `toml:"path_template"`
+
+	// NotifyConflicts: when the TUI's conflict radar finds sibling worktree
+	// sessions editing the same files, message their parents or conductor.
+	// Default: false
+	NotifyConflicts bool `toml:"notify_conflicts"`
+
+	// Recipes: provisioning recipes applied to new worktrees, keyed by repo
+	// directory name or absolute repo path. A .agent-deck/worktree.toml file
+	// in the repo takes precedence.
+	Recipes map[string]WorktreeRecipe `toml:"recipes"`
 }
 
 // Template returns the path template if set, or empty string if nil.
@@ -529,6 +578,61 @@ func (w *WorktreeSettings) Template() string {
 	return *w.PathTemplate
 }
 
+// ForgeSettings configures the code forges used by `agent-deck worktree pr`.
+// Example:
+//
+//	[forge]
+//	github_token = "ghp_..."
+//
+//	[forge.hosts."git.example.com"]
+//	type = "gitea"
+//	token = "..."
+type ForgeSettings struct {
+	// GitHubToken is used for GitHub hosts without a per-host token.
+	// Falls back to $GITHUB_TOKEN or $GH_TOKEN.
+	GitHubToken string `toml:"github_token"`
+
+	// GitLabToken is used for GitLab hosts. Falls back to $GITLAB_TOKEN.
+	GitLabToken string `toml:"gitlab_token"`
+
+	// GiteaToken is used for Gitea/Forgejo hosts. Falls back to $GITEA_TOKEN.
+	GiteaToken string `toml:"gitea_token"`
+
+	// Hosts configures self-hosted forges, keyed by remote hostname
+	Hosts map[string]ForgeHostSettings `toml:"hosts"`
+}
+
+// ForgeHostSettings configures one forge host.
+type ForgeHostSettings struct {
+	// Type is "github", "gitlab" or "gitea". Detected for well-known hosts.
+	Type string `toml:"type"`
+
+	// Token overrides the per-type token for this host
+	Token string `toml:"token"`
+
+	// APIURL overrides the REST API base URL (e.g. "https://git.example.com/api/v1")
+	APIURL string `toml:"api_url"`
+}
+
+// ContextSettings configures the context windows used to compute context
+// usage. Example:
+//
+//	[context]
+//	default_limit = 200000
+//
+//	[context.model_limits]
+//	"claude-sonnet-4-5" = 1000000
+//	"gpt-5-codex" = 400000
+type ContextSettings struct {
+	// ModelLimits maps model names to their context window in tokens. Keys
+	// match by longest prefix and override the window a tool reports.
+	ModelLimits map[string]int `toml:"model_limits"`
+
+	// DefaultLimit is used when neither the model nor the tool has a known
+	// window (default: 200000)
+	DefaultLimit int `toml:"default_limit"`
+}
+
 // GlobalSearchSettings defines global conversation search configuration
 type GlobalSearchSettings struct {
 	// Enabled enables/disables global search feature (default: true when loaded via LoadUserConfig)
@@ -618,6 +722,10 @@ type ToolDef struct {
 
 	// SpinnerCharsExtra appends additional spinner characters to the built-in defaults
 	SpinnerCharsExtra []string `toml:"spinner_chars_extra"`
+
+	// Adapter configures transcript discovery, resume/fork commands, response
+	// and analytics extractors, and MCP config for this tool ([tools.<name>.adapter])
+	Adapter *ToolAdapterDef `toml:"adapter"`
 }
 
 // HTTPServerConfig defines how to auto-start an HTTP MCP server
@@ -990,7 +1098,10 @@ func GetToolBusyPatterns(toolName string) []string {
 // Works for ALL tools: built-in (claude, gemini, etc.) and custom.
 // Returns nil only if there are no defaults AND no config entry.
 func MergeToolPatterns(toolName string) *tmux.RawPatterns {
-	defaults := tmux.DefaultRawPatterns(toolName)
+	var defaults *tmux.RawPatterns
+	if adapter := GetToolAdapter(toolName); adapter != nil {
+		defaults = adapter.StatusPatterns()
+	}
 	toolDef := GetToolDef(toolName)
 
 	// No defaults and no config entry: nothing to do
@@ -1118,6 +1229,24 @@ func GetWorktreeSettings() WorktreeSettings {
 	return settings
 }
 
+// GetForgeSettings returns forge settings (empty when unconfigured)
+func GetForgeSettings() ForgeSettings {
+	config, err := LoadUserConfig()
+	if err != nil || config == nil {
+		return ForgeSettings{}
+	}
+	return config.Forge
+}
+
+// GetContextSettings returns context-window settings
+func GetContextSettings() ContextSettings {
+	config, err := LoadUserConfig()
+	if err != nil || config == nil {
+		return ContextSettings{}
+	}
+	return config.Context
+}
+
 // GetUpdateSettings returns update settings with defaults applied
 func GetUpdateSettings() UpdateSettings {
 	config, err := LoadUserConfig()
@@ -1358,6 +1487,16 @@ func CreateExampleConfig() error {
 # [codex]
 # Enable --yolo (bypass approvals and sandbox) by default (default: false)
 # yolo_mode = true
+# Default model, config profile, sandbox and approval policy for new sessions
+# model = "gpt-5-codex"
+# profile = ""
+# sandbox = "workspace-write"          # read-only, workspace-write, danger-full-access
+# approval_policy = "on-request"       # untrusted, on-failure, on-request, never
+#
+# Per-group overrides (also apply to subgroups)
+# [codex.groups."work/client"]
+# sandbox = "read-only"
+# approval_policy = "untrusted"
 
 # Log file management
 # Agent-deck logs session output to ~/.agent-deck/logs/ for status detection
@@ -1402,6 +1541,30 @@ auto_cleanup = true
 # Custom path template (overrides default_location if set)
 # Variables: {repo-name}, {repo-root}, {branch}, {session-id}
 # path_template = "../worktrees/{repo-name}/{branch}"
+# Message parent/conductor sessions when sibling worktrees edit the same files
+# notify_conflicts = false
+# Provisioning recipe for new worktrees of a repo (keyed by repo name or path).
+# A .agent-deck/worktree.toml file in the repo (same keys) takes precedence.
+# [worktree.recipes.my-app]
+# copy = [".env", "config/*.local.yml"]   # copied from the main worktree
+# symlink = ["node_modules"]              # symlinked to the main worktree
+# commands = ["npm install"]              # run in the new worktree
+# timeout = "5m"                          # per command
+# ports = ["PORT", "API_PORT"]            # free ports written to env_file
+# env_file = ".env.worktree"
+# env = { API_URL = "http://localhost:${API_PORT}" }
+
+# Forge API access for 'agent-deck worktree pr' (GitHub, GitLab, Gitea)
+# Tokens fall back to $GITHUB_TOKEN / $GH_TOKEN, $GITLAB_TOKEN and $GITEA_TOKEN
+# [forge]
+# github_token = ""
+# gitlab_token = ""
+# gitea_token = ""
+# Self-hosted forges, keyed by the hostname in the git remote
+# [forge.hosts."git.example.com"]
+# type = "gitea"
+# token = ""
+# api_url = "https://git.example.com/api/v1"
 
 # Default scope for MCP operations: "local", "global", or "user"
 # "local" writes to .mcp.json (project-only, default)
@@ -808,3 +808,36 @@ inject_status_line = true
 		t.Error("GetInjectStatusLine should be true when set to true")
 	}
 }
+
+func TestCodexConfigGroups(t *testing.T) {
+	tmpDir := t.TempDir()
+	configContent := `
+[codex]
+model = "gpt-5-codex"
+sandbox = "workspace-write"
+
+[codex.groups."work/client"]
+sandbox = "read-only"
+yolo_mode = false
+`
+	configPath := filepath.Join(tmpDir, "config.toml")
+	if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	var config UserConfig
+	if _, err := toml.DecodeFile(configPath, &config); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+
+	if config.Codex.Model != "gpt-5-codex" || config.Codex.Sandbox != "workspace-write" {
+		t.Errorf("unexpected [codex] settings: %+v", config.Codex)
+	}
+	group, ok := config.Codex.Groups["work/client"]
+	if !ok {
+		t.Fatalf("expected work/client group, got %v", config.Codex.Groups)
+	}
+	if group.Sandbox != "read-only" || group.YoloMode == nil || *group.YoloMode {
+		t.Errorf("unexpected group settings: %+v", group)
+	}
+}
@@ -1,6 +1,8 @@
 package session
 
 import (
+	"crypto/rand"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -30,6 +32,18 @@ func findNewestFile(pattern string) (string, time.Time) {
 	return newestPath, newestTime
 }
 
+// newConversationUUID returns a random (version 4) UUID, the session ID
+// format used by Gemini CLI and Codex
+func newConversationUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
 // GetDirectoryCompletions returns a list of directories that match the input prefix.
 // Supports absolute, relative, and tilde-prefixed (~) paths.
 func GetDirectoryCompletions(input string) ([]string, error) {
@@ -0,0 +1,594 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+const (
+	// WorktreeRecipeFile is the per-repo provisioning recipe, relative to the repo root.
+	WorktreeRecipeFile = ".agent-deck/worktree.toml"
+
+	provisionResultFileName     = "agent-deck-provision.json"
+	defaultProvisionTimeout     = 5 * time.Minute
+	defaultProvisionEnvFile     = ".env.worktree"
+	maxProvisionOutputBytes     = 16 * 1024
+	provisionCommandKillTimeout = 5 * time.Second
+)
+
+// WorktreeRecipe describes how to turn a bare worktree checkout into a
+// working environment.
+type WorktreeRecipe struct {
+	// Copy lists files, directories or glob patterns (relative to the main
+	// worktree) to copy into the new worktree, e.g. ".env" or "config/*.local.yml".
+	Copy []string `toml:"copy"`
+
+	// Symlink lists paths to link back to the main worktree instead of
+	// copying, e.g. "node_modules" or ".venv".
+	Symlink []string `toml:"symlink"`
+
+	// Commands run in order with `sh -c` inside the new worktree. Commands
+	// from a repo's .agent-deck/worktree.toml only run once the recipe is
+	// approved with `agent-deck worktree trust`.
+	Commands []string `toml:"commands"`
+
+	// Timeout per command as a Go duration (default "5m").
+	Timeout string `toml:"timeout"`
+
+	// Ports names variables that each get a free TCP port.
+	Ports []string `toml:"ports"`
+
+	// Env seeds extra variables; values may reference ports and the
+	// AGENT_DECK_* variables with ${NAME}.
+	Env map[string]string `toml:"env"`
+
+	// EnvFile receives the port and env variables (default ".env.worktree").
+	EnvFile string `toml:"env_file"`
+}
+
+// IsEmpty reports whether the recipe has nothing to do.
+func (r WorktreeRecipe) IsEmpty() bool {
+	return len(r.Copy) == 0 && len(r.Symlink) == 0 && len(r.Commands) == 0 && len(r.Ports) == 0 && len(r.Env) == 0
+}
+
+// GetTimeout returns the per-command timeout, defaulting to 5 minutes.
+func (r WorktreeRecipe) GetTimeout() time.Duration {
+	if d, err := time.ParseDuration(r.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultProvisionTimeout
+}
+
+// GetEnvFile returns the env file path relative to the worktree.
+func (r WorktreeRecipe) GetEnvFile() string {
+	if r.EnvFile != "" {
+		return r.EnvFile
+	}
+	return defaultProvisionEnvFile
+}
+
+// ProvisionResult records what provisioning did to a worktree. It is stored
+// in the worktree's git directory so it goes away with the worktree.
+type ProvisionResult struct {
+	Recipe     string                   `json:"recipe"`
+	StartedAt  time.Time                `json:"started_at"`
+	FinishedAt time.Time                `json:"finished_at"`
+	Copied     []string                 `json:"copied,omitempty"`
+	Linked     []string                 `json:"linked,omitempty"`
+	Ports      map[string]int           `json:"ports,omitempty"`
+	EnvFile    string                   `json:"env_file,omitempty"`
+	Commands   []ProvisionCommandResult `json:"commands,omitempty"`
+	Errors     []string                 `json:"errors,omitempty"`
+
+	// SkippedCommands were not run because the repo's recipe isn't trusted
+	SkippedCommands []string `json:"skipped_commands,omitempty"`
+	// SkippedEnv names host variables an untrusted recipe's env referenced;
+	// they were expanded to empty strings instead of the user's values
+	SkippedEnv []string `json:"skipped_env,omitempty"`
+}
+
+// Untrusted reports whether parts of the recipe were held back because the
+// repo's recipe hasn't been approved.
+func (r *ProvisionResult) Untrusted() bool {
+	return len(r.SkippedCommands) > 0 || len(r.SkippedEnv) > 0
+}
+
+// ProvisionCommandResult is the outcome of one recipe command.
+type ProvisionCommandResult struct {
+	Command  string        `json:"command"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"duration"`
+	TimedOut bool          `json:"timed_out,omitempty"`
+	// Output is the tail of combined stdout/stderr.
+	Output string `json:"output,omitempty"`
+}
+
+// OK reports whether every step succeeded.
+func (r *ProvisionResult) OK() bool {
+	if len(r.Errors) > 0 || r.Untrusted() {
+		return false
+	}
+	for _, c := range r.Commands {
+		if c.ExitCode != 0 || c.TimedOut {
+			return false
+		}
+	}
+	return true
+}
+
+// Summary is a one-line description like "copied 2, linked 1, 1/1 commands ok".
+func (r *ProvisionResult) Summary() string {
+	okCommands := 0
+	for _, c := range r.Commands {
+		if c.ExitCode == 0 && !c.TimedOut {
+			okCommands++
+		}
+	}
+	parts := []string{fmt.Sprintf("copied %d", len(r.Copied)), fmt.Sprintf("linked %d", len(r.Linked))}
+	if len(r.Commands) > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d commands ok", okCommands, len(r.Commands)))
+	}
+	if len(r.SkippedCommands) > 0 {
+		parts = append(parts, fmt.Sprintf("%d commands skipped (recipe not trusted)", len(r.SkippedCommands)))
+	}
+	if len(r.SkippedEnv) > 0 {
+		parts = append(parts, fmt.Sprintf("%d env vars not expanded (recipe not trusted)", len(r.SkippedEnv)))
+	}
+	if len(r.Ports) > 0 {
+		parts = append(parts, fmt.Sprintf("%d ports", len(r.Ports)))
+	}
+	if len(r.Errors) > 0 {
+		parts = append(parts, fmt.Sprintf("%d errors", len(r.Errors)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// LoadWorktreeRecipe finds the provisioning recipe for the repo at repoRoot:
+// the repo's .agent-deck/worktree.toml, else a [worktree.recipes] entry keyed
+// by repo path or, failing that, directory name. It returns the recipe and
+// where it came from, or nil when the repo has none.
+func LoadWorktreeRecipe(repoRoot string) (*WorktreeRecipe, string, error) {
+	path := filepath.Join(repoRoot, WorktreeRecipeFile)
+	if _, err := os.Stat(path); err == nil {
+		var recipe WorktreeRecipe
+		if _, err := toml.DecodeFile(path, &recipe); err != nil {
+			return nil, "", fmt.Errorf("parse %s: %w", WorktreeRecipeFile, err)
+		}
+		return &recipe, path, nil
+	}
+
+	recipes := GetWorktreeSettings().Recipes
+	keys := make([]string, 0, len(recipes))
+	for key := range recipes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	match := ""
+	for _, key := range keys {
+		if filepath.Clean(ExpandPath(key)) == filepath.Clean(repoRoot) {
+			match = key
+			break
+		}
+		if match == "" && key == filepath.Base(repoRoot) {
+			match = key
+		}
+	}
+	if match == "" {
+		return nil, "", nil
+	}
+	recipe := recipes[match]
+	return &recipe, "config.toml [worktree.recipes." + match + "]", nil
+}
+
+// ProvisionWorktree applies the repo's recipe to a freshly created worktree.
+// Individual failures are recorded in the result rather than aborting, so a
+// broken install step never prevents the session from starting. It returns
+// nil when the repo has no recipe.
+func ProvisionWorktree(repoRoot, worktreePath string) (*ProvisionResult, error) {
+	mainWorktree, err := git.GetMainWorktreePath(repoRoot)
+	if err != nil {
+		mainWorktree = repoRoot
+	}
+	recipe, source, err := LoadWorktreeRecipe(mainWorktree)
+	if err != nil {
+		return nil, err
+	}
+	if recipe == nil || recipe.IsEmpty() {
+		return nil, nil
+	}
+
+	result := &ProvisionResult{Recipe: source, StartedAt: time.Now()}
+
+	for _, pattern := range recipe.Copy {
+		result.provisionPaths(mainWorktree, worktreePath, pattern, false)
+	}
+	for _, pattern := range recipe.Symlink {
+		result.provisionPaths(mainWorktree, worktreePath, pattern, true)
+	}
+
+	env := map[string]string{
+		"AGENT_DECK_WORKTREE":      worktreePath,
+		"AGENT_DECK_MAIN_WORKTREE": mainWorktree,
+	}
+	if branch, err := git.GetCurrentBranch(worktreePath); err == nil {
+		env["AGENT_DECK_BRANCH"] = branch
+	}
+	if len(recipe.Ports) > 0 {
+		result.Ports = allocatePorts(recipe.Ports, usedProvisionPorts(mainWorktree))
+		for name, port := range result.Ports {
+			env[name] = fmt.Sprint(port)
+		}
+	}
+	// A cloned repo's recipe must not run code or read the user's
+	// environment until the user has read and approved it; recipes from
+	// config.toml are the user's own
+	trusted := true
+	if source == filepath.Join(mainWorktree, WorktreeRecipeFile) {
+		trusted, _ = IsWorktreeRecipeTrusted(mainWorktree)
+	}
+
+	seeded := make(map[string]string, len(result.Ports)+len(recipe.Env))
+	for name, port := range result.Ports {
+		seeded[name] = fmt.Sprint(port)
+	}
+	skippedEnv := make(map[string]bool)
+	for name, value := range recipe.Env {
+		seeded[name] = os.Expand(value, func(key string) string {
+			if v, ok := env[key]; ok {
+				return v
+			}
+			if !trusted {
+				skippedEnv[key] = true
+				return ""
+			}
+			return os.Getenv(key)
+		})
+	}
+	for key := range skippedEnv {
+		result.SkippedEnv = append(result.SkippedEnv, key)
+	}
+	sort.Strings(result.SkippedEnv)
+	for name, value := range seeded {
+		env[name] = value
+	}
+	if len(seeded) > 0 {
+		result.EnvFile = recipe.GetEnvFile()
+		if !isContainedPath(result.EnvFile) {
+			result.Errors = append(result.Errors, fmt.Sprintf("env_file %s: outside the worktree", result.EnvFile))
+		} else if err := writeProvisionEnvFile(filepath.Join(worktreePath, result.EnvFile), seeded); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		} else if err := excludeFromGit(worktreePath, result.EnvFile); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+
+	commands := recipe.Commands
+	if !trusted {
+		result.SkippedCommands = commands
+		commands = nil
+	}
+
+	cmdEnv := os.Environ()
+	for name, value := range env {
+		cmdEnv = append(cmdEnv, name+"="+value)
+	}
+	for _, command := range commands {
+		result.Commands = append(result.Commands, runProvisionCommand(worktreePath, command, cmdEnv, recipe.GetTimeout()))
+	}
+
+	result.FinishedAt = time.Now()
+	if err := saveProvisionResult(worktreePath, result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// provisionPaths copies or symlinks every match of pattern from src into dst.
+func (r *ProvisionResult) provisionPaths(src, dst, pattern string, link bool) {
+	matches, err := filepath.Glob(filepath.Join(src, pattern))
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("invalid pattern %q: %v", pattern, err))
+		return
+	}
+	if len(matches) == 0 {
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: no match in main worktree", pattern))
+		return
+	}
+	for _, from := range matches {
+		rel, err := filepath.Rel(src, from)
+		if err != nil {
+			continue
+		}
+		// Patterns like "../secrets" must not reach outside either worktree
+		if !isContainedPath(rel) {
+			r.Errors = append(r.Errors, fmt.Sprintf("%s: outside the main worktree", pattern))
+			continue
+		}
+		to := filepath.Join(dst, rel)
+		// Tracked files are already checked out; never clobber them
+		if _, err := os.Lstat(to); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("%s: %v", rel, err))
+			continue
+		}
+		if link {
+			if err := os.Symlink(from, to); err != nil {
+				r.Errors = append(r.Errors, fmt.Sprintf("link %s: %v", rel, err))
+				continue
+			}
+			r.Linked = append(r.Linked, rel)
+			continue
+		}
+		if err := copyPath(from, to); err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("copy %s: %v", rel, err))
+			continue
+		}
+		r.Copied = append(r.Copied, rel)
+	}
+}
+
+// isContainedPath reports whether rel stays inside the directory it is
+// relative to.
+func isContainedPath(rel string) bool {
+	rel = filepath.Clean(rel)
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	return true
+}
+
+// copyPath copies a file, symlink or directory tree, preserving modes.
+func copyPath(from, to string) error {
+	info, err := os.Lstat(from)
+	if err != nil {
+		return err
+	}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(from)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, to)
+	case info.IsDir():
+		if err := os.MkdirAll(to, info.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(from)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := copyPath(filepath.Join(from, entry.Name()), filepath.Join(to, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// usedProvisionPorts returns ports already handed to sibling worktrees.
+func usedProvisionPorts(mainWorktree string) map[int]bool {
+	used := make(map[int]bool)
+	worktrees, err := git.ListWorktrees(mainWorktree)
+	if err != nil {
+		return used
+	}
+	for _, wt := range worktrees {
+		if prev, err := LoadProvisionResult(wt.Path); err == nil && prev != nil {
+			for _, port := range prev.Ports {
+				used[port] = true
+			}
+		}
+	}
+	return used
+}
+
+// allocatePorts picks a currently free TCP port for each name, skipping
+// ports already assigned to other worktrees.
+func allocatePorts(names []string, used map[int]bool) map[string]int {
+	ports := make(map[string]int, len(names))
+	for _, name := range names {
+		for attempt := 0; attempt < 20; attempt++ {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				break
+			}
+			port := l.Addr().(*net.TCPAddr).Port
+			l.Close()
+			if !used[port] {
+				used[port] = true
+				ports[name] = port
+				break
+			}
+		}
+	}
+	return ports
+}
+
+func writeProvisionEnvFile(path string, vars map[string]string) error {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("# Generated by agent-deck worktree provisioning\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=%s\n", name, shellQuote(vars[name]))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("write env file: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		return fmt.Errorf("write env file: %w", err)
+	}
+	return nil
+}
+
+// excludeFromGit adds rel to the repo's info/exclude so generated files
+// don't show up as untracked changes.
+func excludeFromGit(worktreePath, rel string) error {
+	out, err := exec.Command("git", "-C", worktreePath, "rev-parse", "--git-path", "info/exclude").Output()
+	if err != nil {
+		return fmt.Errorf("resolve info/exclude: %w", err)
+	}
+	path := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(worktreePath, path)
+	}
+	pattern := "/" + filepath.ToSlash(filepath.Clean(rel))
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read info/exclude: %w", err)
+	}
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("write info/exclude: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("write info/exclude: %w", err)
+	}
+	defer f.Close()
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		pattern = "\n" + pattern
+	}
+	if _, err := f.WriteString(pattern + "\n"); err != nil {
+		return fmt.Errorf("write info/exclude: %w", err)
+	}
+	return nil
+}
+
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:@%+,=", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func runProvisionCommand(dir, command string, env []string, timeout time.Duration) ProvisionCommandResult {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Kill the whole process group on timeout so children (npm, pip, ...)
+	// don't outlive the shell and keep the output pipes open.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = provisionCommandKillTimeout
+
+	start := time.Now()
+	err := cmd.Run()
+	res := ProvisionCommandResult{Command: command, Duration: time.Since(start)}
+
+	out := output.Bytes()
+	if len(out) > maxProvisionOutputBytes {
+		out = out[len(out)-maxProvisionOutputBytes:]
+	}
+	res.Output = string(out)
+
+	if ctx.Err() == context.DeadlineExceeded {
+		res.TimedOut = true
+		res.ExitCode = -1
+		return res
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		res.ExitCode = exitErr.ExitCode()
+	} else if err != nil {
+		res.ExitCode = -1
+		res.Output += err.Error()
+	}
+	return res
+}
+
+func provisionResultPath(worktreePath string) (string, error) {
+	out, err := exec.Command("git", "-C", worktreePath, "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return "", fmt.Errorf("resolve git dir: %w", err)
+	}
+	return filepath.Join(strings.TrimSpace(string(out)), provisionResultFileName), nil
+}
+
+func saveProvisionResult(worktreePath string, result *ProvisionResult) error {
+	path, err := provisionResultPath(worktreePath)
+	if err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal provision result: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		return fmt.Errorf("write provision result: %w", err)
+	}
+	return nil
+}
+
+// LoadProvisionResult returns the stored provisioning result of a worktree,
+// or nil when it was never provisioned.
+func LoadProvisionResult(worktreePath string) (*ProvisionResult, error) {
+	path, err := provisionResultPath(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read provision result: %w", err)
+	}
+	var result ProvisionResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("parse provision result: %w", err)
+	}
+	return &result, nil
+}
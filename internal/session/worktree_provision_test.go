@@ -0,0 +1,282 @@
+package session
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+func TestProvisionWorktree(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repo := t.TempDir()
+	runGitCmd(t, repo, "init", "-b", "main")
+	runGitCmd(t, repo, "config", "user.email", "test@test.com")
+	runGitCmd(t, repo, "config", "user.name", "Test User")
+	files := map[string]string{
+		".gitignore":                ".env\nnode_modules\nready.txt\n*.local.yml\n",
+		".env":                      "SECRET=1\n",
+		"node_modules/pkg/index.js": "module.exports = 1\n",
+		"config/app.local.yml":      "debug: true\n",
+		"README.md":                 "# repo\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recipe := `copy = [".env", "config/*.local.yml", "missing.txt"]
+symlink = ["node_modules"]
+commands = ["echo port=$PORT > ready.txt", "echo boom >&2; exit 3", "sleep 5"]
+timeout = "300ms"
+ports = ["PORT"]
+env = { API_URL = "http://localhost:${PORT}/api" }
+`
+	if err := os.MkdirAll(filepath.Join(repo, ".agent-deck"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, WorktreeRecipeFile), []byte(recipe), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGitCmd(t, repo, "add", "README.md", ".gitignore")
+	runGitCmd(t, repo, "commit", "-m", "init")
+	if err := TrustWorktreeRecipe(repo); err != nil {
+		t.Fatalf("TrustWorktreeRecipe: %v", err)
+	}
+
+	wt := filepath.Join(t.TempDir(), "feature")
+	if err := git.CreateWorktree(repo, wt, "feature"); err != nil {
+		t.Fatalf("CreateWorktree: %v", err)
+	}
+	result, err := ProvisionWorktree(repo, wt)
+	if err != nil || result == nil {
+		t.Fatalf("ProvisionWorktree: %+v %v", result, err)
+	}
+
+	if got, _ := os.ReadFile(filepath.Join(wt, ".env")); string(got) != "SECRET=1\n" {
+		t.Fatalf(".env not copied: %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(wt, "config", "app.local.yml")); err != nil {
+		t.Fatalf("glob copy missing: %v", err)
+	}
+	if target, err := os.Readlink(filepath.Join(wt, "node_modules")); err != nil || target != filepath.Join(repo, "node_modules") {
+		t.Fatalf("node_modules not linked: %q %v", target, err)
+	}
+
+	port := result.Ports["PORT"]
+	if port == 0 {
+		t.Fatalf("expected PORT allocated, got %v", result.Ports)
+	}
+	ready, _ := os.ReadFile(filepath.Join(wt, "ready.txt"))
+	if strings.TrimSpace(string(ready)) != "port="+strconv.Itoa(port) {
+		t.Fatalf("command did not see PORT: %q", ready)
+	}
+	envFile, _ := os.ReadFile(filepath.Join(wt, ".env.worktree"))
+	if !strings.Contains(string(envFile), "PORT="+strconv.Itoa(port)) || !strings.Contains(string(envFile), "API_URL=http://localhost:"+strconv.Itoa(port)+"/api") {
+		t.Fatalf("unexpected env file %q", envFile)
+	}
+
+	if len(result.Commands) != 3 {
+		t.Fatalf("expected 3 command results, got %+v", result.Commands)
+	}
+	if c := result.Commands[1]; c.ExitCode != 3 || !strings.Contains(c.Output, "boom") {
+		t.Fatalf("expected captured failure, got %+v", c)
+	}
+	if !result.Commands[2].TimedOut {
+		t.Fatalf("expected timeout, got %+v", result.Commands[2])
+	}
+	if result.OK() || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "missing.txt") {
+		t.Fatalf("expected missing.txt error and failed result, got %+v", result.Errors)
+	}
+
+	// The generated env file is excluded, once, rather than left untracked
+	if status, _ := exec.Command("git", "-C", wt, "status", "--porcelain").Output(); strings.TrimSpace(string(status)) != "" {
+		t.Fatalf("expected clean worktree, got %q", status)
+	}
+	if err := excludeFromGit(wt, ".env.worktree"); err != nil {
+		t.Fatal(err)
+	}
+	exclude, _ := os.ReadFile(filepath.Join(repo, ".git", "info", "exclude"))
+	if n := strings.Count(string(exclude), "/.env.worktree\n"); n != 1 {
+		t.Fatalf("expected env file excluded once, got %d in %q", n, exclude)
+	}
+
+	loaded, err := LoadProvisionResult(wt)
+	if err != nil || loaded == nil || loaded.Ports["PORT"] != port || loaded.EnvFile != ".env.worktree" {
+		t.Fatalf("LoadProvisionResult: %+v %v", loaded, err)
+	}
+	if status, _ := os.ReadFile(filepath.Join(wt, provisionResultFileName)); status != nil {
+		t.Fatal("provision result must not be written into the working tree")
+	}
+
+	// Repos without a recipe are left alone
+	if res, err := ProvisionWorktree(t.TempDir(), wt); err != nil || res != nil {
+		t.Fatalf("expected no-op without recipe, got %+v %v", res, err)
+	}
+}
+
+func TestProvisionWorktree_UntrustedRecipeSkipsCommands(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PROVISION_TEST_SECRET", "hunter2")
+	repo := t.TempDir()
+	runGitCmd(t, repo, "init", "-b", "main")
+	runGitCmd(t, repo, "config", "user.email", "test@test.com")
+	runGitCmd(t, repo, "config", "user.name", "Test User")
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("# repo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".env"), []byte("SECRET=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeRecipe := func(commands string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(repo, ".agent-deck"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		recipe := "copy = [\".env\"]\ncommands = [" + commands + "]\n" +
+			"[env]\nLEAK = \"${PROVISION_TEST_SECRET}\"\nWT = \"${AGENT_DECK_WORKTREE}\"\n"
+		if err := os.WriteFile(filepath.Join(repo, WorktreeRecipeFile), []byte(recipe), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeRecipe(`"touch pwned.txt"`)
+	runGitCmd(t, repo, "add", "README.md")
+	runGitCmd(t, repo, "commit", "-m", "init")
+
+	provision := func(name string) (*ProvisionResult, string) {
+		t.Helper()
+		wt := filepath.Join(t.TempDir(), name)
+		if err := git.CreateWorktree(repo, wt, name); err != nil {
+			t.Fatalf("CreateWorktree: %v", err)
+		}
+		result, err := ProvisionWorktree(repo, wt)
+		if err != nil || result == nil {
+			t.Fatalf("ProvisionWorktree: %+v %v", result, err)
+		}
+		return result, wt
+	}
+
+	result, wt := provision("untrusted")
+	if _, err := os.Stat(filepath.Join(wt, "pwned.txt")); err == nil {
+		t.Fatal("untrusted recipe command ran")
+	}
+	if len(result.Commands) != 0 || len(result.SkippedCommands) != 1 || result.OK() {
+		t.Fatalf("expected skipped command, got %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(wt, ".env")); err != nil {
+		t.Fatalf("copy should not need trust: %v", err)
+	}
+	envFile, err := os.ReadFile(filepath.Join(wt, result.EnvFile))
+	if err != nil {
+		t.Fatalf("read env file: %v", err)
+	}
+	if strings.Contains(string(envFile), "hunter2") || !strings.Contains(string(envFile), wt) {
+		t.Fatalf("untrusted recipe should only expand agent-deck variables, got:\n%s", envFile)
+	}
+	if len(result.SkippedEnv) != 1 || result.SkippedEnv[0] != "PROVISION_TEST_SECRET" || !result.Untrusted() {
+		t.Fatalf("expected skipped host variable, got %+v", result)
+	}
+
+	if err := TrustWorktreeRecipe(repo); err != nil {
+		t.Fatalf("TrustWorktreeRecipe: %v", err)
+	}
+	result, wt = provision("trusted")
+	if _, err := os.Stat(filepath.Join(wt, "pwned.txt")); err != nil || len(result.SkippedCommands) != 0 {
+		t.Fatalf("trusted recipe command did not run: %+v", result)
+	}
+	if envFile, _ := os.ReadFile(filepath.Join(wt, result.EnvFile)); !strings.Contains(string(envFile), "hunter2") {
+		t.Fatalf("trusted recipe should expand host variables, got:\n%s", envFile)
+	}
+
+	// Editing the recipe withdraws the approval
+	writeRecipe(`"touch other.txt"`)
+	if trusted, _ := IsWorktreeRecipeTrusted(repo); trusted {
+		t.Fatal("edited recipe should need approving again")
+	}
+	if result, _ = provision("edited"); len(result.SkippedCommands) != 1 {
+		t.Fatalf("expected edited recipe's command skipped, got %+v", result)
+	}
+}
+
+func TestProvisionWorktree_RejectsPathsOutsideWorktree(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	parent := t.TempDir()
+	repo := filepath.Join(parent, "repo")
+	if err := os.MkdirAll(filepath.Join(repo, ".agent-deck"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	runGitCmd(t, repo, "init", "-b", "main")
+	runGitCmd(t, repo, "config", "user.email", "test@test.com")
+	runGitCmd(t, repo, "config", "user.name", "Test User")
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("# repo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(parent, "outside.txt"), []byte("secret\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	recipe := `copy = ["../outside.txt"]
+symlink = ["../*.txt"]
+env = { A = "1" }
+env_file = "../../leak.env"
+`
+	if err := os.WriteFile(filepath.Join(repo, WorktreeRecipeFile), []byte(recipe), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGitCmd(t, repo, "add", "README.md")
+	runGitCmd(t, repo, "commit", "-m", "init")
+
+	wtParent := filepath.Join(t.TempDir(), "a", "b")
+	wt := filepath.Join(wtParent, "feature")
+	if err := git.CreateWorktree(repo, wt, "feature"); err != nil {
+		t.Fatalf("CreateWorktree: %v", err)
+	}
+	result, err := ProvisionWorktree(repo, wt)
+	if err != nil || result == nil {
+		t.Fatalf("ProvisionWorktree: %+v %v", result, err)
+	}
+	if len(result.Copied) != 0 || len(result.Linked) != 0 || len(result.Errors) != 3 {
+		t.Fatalf("expected all three escapes rejected, got %+v", result)
+	}
+	for _, p := range []string{filepath.Join(wtParent, "outside.txt"), filepath.Join(filepath.Dir(wtParent), "leak.env")} {
+		if _, err := os.Lstat(p); err == nil {
+			t.Fatalf("provisioning wrote outside the worktree: %s", p)
+		}
+	}
+}
+
+func TestLoadWorktreeRecipe_PrefersPathOverName(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repo := filepath.Join(t.TempDir(), "api")
+	userConfigCacheMu.Lock()
+	userConfigCache = &UserConfig{Worktree: WorktreeSettings{Recipes: map[string]WorktreeRecipe{
+		"api":         {Commands: []string{"by-name"}},
+		repo:          {Commands: []string{"by-path"}},
+		"unrelated":   {Commands: []string{"other"}},
+		"/elsewhere/": {Commands: []string{"other"}},
+	}}}
+	userConfigCacheMu.Unlock()
+	t.Cleanup(func() {
+		userConfigCacheMu.Lock()
+		userConfigCache = nil
+		userConfigCacheMu.Unlock()
+	})
+
+	for i := 0; i < 20; i++ {
+		recipe, source, err := LoadWorktreeRecipe(repo)
+		if err != nil || recipe == nil || recipe.Commands[0] != "by-path" {
+			t.Fatalf("expected the path-keyed recipe, got %+v from %q (%v)", recipe, source, err)
+		}
+	}
+	recipe, _, _ := LoadWorktreeRecipe(filepath.Join(t.TempDir(), "api"))
+	if recipe == nil || recipe.Commands[0] != "by-name" {
+		t.Fatalf("expected the name-keyed recipe, got %+v", recipe)
+	}
+}
@@ -0,0 +1,138 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const worktreeTrustFileName = "worktree_trust.json"
+
+// worktreeTrust records which repo-committed recipes may run their commands,
+// keyed by main worktree path. The value is the SHA-256 of the recipe file,
+// so any edit to the recipe needs approving again.
+type worktreeTrust struct {
+	Repos map[string]string `json:"repos,omitempty"`
+}
+
+// worktreeTrustMu serializes read-modify-write cycles on the trust file
+// within this process.
+var worktreeTrustMu sync.Mutex
+
+func worktreeTrustPath() (string, error) {
+	dir, err := GetAgentDeckDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, worktreeTrustFileName), nil
+}
+
+func loadWorktreeTrust() (*worktreeTrust, error) {
+	path, err := worktreeTrustPath()
+	if err != nil {
+		return nil, err
+	}
+	trust := &worktreeTrust{}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return trust, nil
+		}
+		return nil, fmt.Errorf("read worktree trust: %w", err)
+	}
+	if err := json.Unmarshal(raw, trust); err != nil {
+		return nil, fmt.Errorf("parse worktree trust: %w", err)
+	}
+	return trust, nil
+}
+
+func updateWorktreeTrust(mutate func(*worktreeTrust)) error {
+	worktreeTrustMu.Lock()
+	defer worktreeTrustMu.Unlock()
+
+	trust, err := loadWorktreeTrust()
+	if err != nil {
+		return err
+	}
+	mutate(trust)
+
+	path, err := worktreeTrustPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("mkdir worktree trust dir: %w", err)
+	}
+	raw, err := json.MarshalIndent(trust, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal worktree trust: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("write temp worktree trust: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename worktree trust: %w", err)
+	}
+	return nil
+}
+
+// worktreeRecipeHash returns the SHA-256 of the repo's recipe file
+func worktreeRecipeHash(mainWorktree string) (string, error) {
+	raw, err := os.ReadFile(filepath.Join(mainWorktree, WorktreeRecipeFile))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func worktreeTrustKey(mainWorktree string) string {
+	if abs, err := filepath.Abs(mainWorktree); err == nil {
+		mainWorktree = abs
+	}
+	return filepath.Clean(mainWorktree)
+}
+
+// IsWorktreeRecipeTrusted reports whether the commands in the repo's
+// .agent-deck/worktree.toml were approved with `agent-deck worktree trust`
+// in their current form.
+func IsWorktreeRecipeTrusted(mainWorktree string) (bool, error) {
+	hash, err := worktreeRecipeHash(mainWorktree)
+	if err != nil {
+		return false, err
+	}
+	trust, err := loadWorktreeTrust()
+	if err != nil {
+		return false, err
+	}
+	return trust.Repos[worktreeTrustKey(mainWorktree)] == hash, nil
+}
+
+// TrustWorktreeRecipe approves the current contents of the repo's
+// .agent-deck/worktree.toml, letting its commands run on new worktrees.
+func TrustWorktreeRecipe(mainWorktree string) error {
+	hash, err := worktreeRecipeHash(mainWorktree)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", WorktreeRecipeFile, err)
+	}
+	return updateWorktreeTrust(func(t *worktreeTrust) {
+		if t.Repos == nil {
+			t.Repos = map[string]string{}
+		}
+		t.Repos[worktreeTrustKey(mainWorktree)] = hash
+	})
+}
+
+// UntrustWorktreeRecipe withdraws approval of the repo's recipe commands.
+func UntrustWorktreeRecipe(mainWorktree string) error {
+	return updateWorktreeTrust(func(t *worktreeTrust) {
+		delete(t.Repos, worktreeTrustKey(mainWorktree))
+	})
+}
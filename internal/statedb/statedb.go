@@ -15,7 +15,7 @@ import (
 
 // SchemaVersion tracks the current database schema version.
 // Bump this when adding migrations.
-const SchemaVersion = 1
+const SchemaVersion = 2
 
 // StateDB wraps a SQLite database for session/group persistence.
 // Thread-safe for concurrent use from multiple goroutines within one process.
@@ -55,6 +55,14 @@ type GroupRow struct {
 	DefaultPath string
 }
 
+// QueuedMessageRow is a message waiting for its session to finish a turn.
+type QueuedMessageRow struct {
+	ID         int64
+	InstanceID string
+	Message    string
+	CreatedAt  time.Time
+}
+
 // StatusRow holds status + acknowledgment for a session.
 type StatusRow struct {
 	Status       string
@@ -196,6 +204,24 @@ func (s *StateDB) Migrate() error {
 		return fmt.Errorf("statedb: create heartbeats: %w", err)
 	}
 
+	// message queue (per-session FIFO, ordered by position)
+	if _, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS message_queue (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			instance_id TEXT NOT NULL,
+			position    INTEGER NOT NULL,
+			message     TEXT NOT NULL,
+			created_at  INTEGER NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("statedb: create message queue: %w", err)
+	}
+	if _, err := tx.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_message_queue_instance ON message_queue (instance_id, position)
+	`); err != nil {
+		return fmt.Errorf("statedb: index message queue: %w", err)
+	}
+
 	// Set schema version only when missing or changed.
 	// Avoiding a write on every open reduces lock contention between CLI processes.
 	schemaVersion := fmt.Sprintf("%d", SchemaVersion)
@@ -358,9 +384,12 @@ func (s *StateDB) LoadInstances() ([]*InstanceRow, error) {
 	return result, rows.Err()
 }
 
-// DeleteInstance removes an instance by ID.
+// DeleteInstance removes an instance by ID, along with its queued messages.
 func (s *StateDB) DeleteInstance(id string) error {
-	_, err := s.db.Exec("DELETE FROM instances WHERE id = ?", id)
+	if _, err := s.db.Exec("DELETE FROM instances WHERE id = ?", id); err != nil {
+		return err
+	}
+	_, err := s.db.Exec("DELETE FROM message_queue WHERE instance_id = ?", id)
 	return err
 }
 
@@ -593,6 +622,167 @@ func (s *StateDB) ResignPrimary() error {
 	return err
 }
 
+// --- Message Queue ---
+
+// EnqueueMessage appends a message to the end of an instance's queue.
+func (s *StateDB) EnqueueMessage(instanceID, message string) (*QueuedMessageRow, error) {
+	now := time.Now()
+	res, err := s.db.Exec(`
+		INSERT INTO message_queue (instance_id, position, message, created_at)
+		SELECT ?, COALESCE(MAX(position), 0) + 1, ?, ?
+		FROM message_queue WHERE instance_id = ?
+	`, instanceID, message, now.UnixNano(), instanceID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return &QueuedMessageRow{ID: id, InstanceID: instanceID, Message: message, CreatedAt: now}, nil
+}
+
+// LoadQueuedMessages returns an instance's queued messages, next first.
+func (s *StateDB) LoadQueuedMessages(instanceID string) ([]*QueuedMessageRow, error) {
+	rows, err := s.db.Query(`
+		SELECT id, instance_id, message, created_at
+		FROM message_queue WHERE instance_id = ? ORDER BY position, id
+	`, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*QueuedMessageRow
+	for rows.Next() {
+		m := &QueuedMessageRow{}
+		var createdAt int64
+		if err := rows.Scan(&m.ID, &m.InstanceID, &m.Message, &createdAt); err != nil {
+			return nil, err
+		}
+		m.CreatedAt = time.Unix(0, createdAt)
+		result = append(result, m)
+	}
+	return result, rows.Err()
+}
+
+// QueueDepths returns the number of queued messages per instance. Instances
+// with an empty queue are omitted.
+func (s *StateDB) QueueDepths() (map[string]int, error) {
+	rows, err := s.db.Query("SELECT instance_id, COUNT(*) FROM message_queue GROUP BY instance_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]int)
+	for rows.Next() {
+		var id string
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		result[id] = n
+	}
+	return result, rows.Err()
+}
+
+// PopQueuedMessage removes and returns the next message for an instance, or
+// nil when the queue is empty. When several processes pop at once, each
+// message goes to exactly one of them.
+func (s *StateDB) PopQueuedMessage(instanceID string) (*QueuedMessageRow, error) {
+	for {
+		msgs, err := s.LoadQueuedMessages(instanceID)
+		if err != nil || len(msgs) == 0 {
+			return nil, err
+		}
+		res, err := s.db.Exec("DELETE FROM message_queue WHERE id = ?", msgs[0].ID)
+		if err != nil {
+			return nil, err
+		}
+		if n, _ := res.RowsAffected(); n == 1 {
+			return msgs[0], nil
+		}
+		// Another process popped it first; try the next one
+	}
+}
+
+// PushFrontQueuedMessage puts a popped message back at the front of its
+// instance's queue, e.g. after a failed delivery.
+func (s *StateDB) PushFrontQueuedMessage(m *QueuedMessageRow) error {
+	_, err := s.db.Exec(`
+		INSERT INTO message_queue (id, instance_id, position, message, created_at)
+		SELECT ?, ?, COALESCE(MIN(position), 1) - 1, ?, ?
+		FROM message_queue WHERE instance_id = ?
+	`, m.ID, m.InstanceID, m.Message, m.CreatedAt.UnixNano(), m.InstanceID)
+	return err
+}
+
+// DeleteQueuedMessage cancels one queued message. It reports whether the
+// message was in the instance's queue.
+func (s *StateDB) DeleteQueuedMessage(instanceID string, id int64) (bool, error) {
+	res, err := s.db.Exec("DELETE FROM message_queue WHERE instance_id = ? AND id = ?", instanceID, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ClearQueuedMessages cancels all of an instance's queued messages and
+// returns how many there were.
+func (s *StateDB) ClearQueuedMessages(instanceID string) (int, error) {
+	res, err := s.db.Exec("DELETE FROM message_queue WHERE instance_id = ?", instanceID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// MoveQueuedMessage moves a queued message to index (0 = next to deliver)
+// in its instance's queue. Out-of-range indexes are clamped. It reports
+// whether the message was in the instance's queue.
+func (s *StateDB) MoveQueuedMessage(instanceID string, id int64, index int) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.Query("SELECT id FROM message_queue WHERE instance_id = ? ORDER BY position, id", instanceID)
+	if err != nil {
+		return false, err
+	}
+	var ids []int64
+	found := false
+	for rows.Next() {
+		var rowID int64
+		if err := rows.Scan(&rowID); err != nil {
+			rows.Close()
+			return false, err
+		}
+		if rowID == id {
+			found = true
+			continue
+		}
+		ids = append(ids, rowID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || !found {
+		return false, err
+	}
+
+	index = max(0, min(index, len(ids)))
+	ids = append(ids[:index], append([]int64{id}, ids[index:]...)...)
+	for pos, rowID := range ids {
+		if _, err := tx.Exec("UPDATE message_queue SET position = ? WHERE id = ?", pos+1, rowID); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit()
+}
+
 // --- Metadata ---
 
 // SetMeta sets a key-value pair in the metadata table.
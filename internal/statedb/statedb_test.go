@@ -609,3 +609,82 @@ func TestGlobalSingleton(t *testing.T) {
 		t.Error("Expected nil after clearing")
 	}
 }
+
+func queuedMessages(t *testing.T, db *StateDB, instanceID string) []string {
+	t.Helper()
+	rows, err := db.LoadQueuedMessages(instanceID)
+	if err != nil {
+		t.Fatalf("LoadQueuedMessages: %v", err)
+	}
+	var msgs []string
+	for _, r := range rows {
+		msgs = append(msgs, r.Message)
+	}
+	return msgs
+}
+
+func TestMessageQueue(t *testing.T) {
+	db := newTestDB(t)
+
+	first, err := db.EnqueueMessage("inst-1", "first")
+	if err != nil {
+		t.Fatalf("EnqueueMessage: %v", err)
+	}
+	second, _ := db.EnqueueMessage("inst-1", "second")
+	third, _ := db.EnqueueMessage("inst-1", "third")
+	if _, err := db.EnqueueMessage("inst-2", "other"); err != nil {
+		t.Fatalf("EnqueueMessage: %v", err)
+	}
+
+	depths, err := db.QueueDepths()
+	if err != nil {
+		t.Fatalf("QueueDepths: %v", err)
+	}
+	if depths["inst-1"] != 3 || depths["inst-2"] != 1 || len(depths) != 2 {
+		t.Errorf("QueueDepths = %v", depths)
+	}
+
+	// Move third to the front, then first to the back (clamped)
+	if ok, err := db.MoveQueuedMessage("inst-1", third.ID, 0); err != nil || !ok {
+		t.Fatalf("MoveQueuedMessage: %v, %v", ok, err)
+	}
+	if ok, _ := db.MoveQueuedMessage("inst-1", first.ID, 99); !ok {
+		t.Fatal("MoveQueuedMessage should find first")
+	}
+	if got := queuedMessages(t, db, "inst-1"); len(got) != 3 || got[0] != "third" || got[1] != "second" || got[2] != "first" {
+		t.Errorf("order after moves = %v", got)
+	}
+	if ok, _ := db.MoveQueuedMessage("inst-2", first.ID, 0); ok {
+		t.Error("MoveQueuedMessage should not move another instance's message")
+	}
+
+	// Pop delivers in order; a pushed-back message is next again
+	popped, err := db.PopQueuedMessage("inst-1")
+	if err != nil || popped == nil || popped.Message != "third" {
+		t.Fatalf("PopQueuedMessage = %+v, %v", popped, err)
+	}
+	if err := db.PushFrontQueuedMessage(popped); err != nil {
+		t.Fatalf("PushFrontQueuedMessage: %v", err)
+	}
+	if got := queuedMessages(t, db, "inst-1"); got[0] != "third" {
+		t.Errorf("order after push front = %v", got)
+	}
+
+	if ok, err := db.DeleteQueuedMessage("inst-1", second.ID); err != nil || !ok {
+		t.Fatalf("DeleteQueuedMessage: %v, %v", ok, err)
+	}
+	if n, err := db.ClearQueuedMessages("inst-1"); err != nil || n != 2 {
+		t.Fatalf("ClearQueuedMessages = %d, %v", n, err)
+	}
+	if popped, _ := db.PopQueuedMessage("inst-1"); popped != nil {
+		t.Errorf("PopQueuedMessage on empty queue = %+v", popped)
+	}
+
+	// Deleting the instance drops its queue
+	if err := db.DeleteInstance("inst-2"); err != nil {
+		t.Fatalf("DeleteInstance: %v", err)
+	}
+	if got := queuedMessages(t, db, "inst-2"); len(got) != 0 {
+		t.Errorf("queue after DeleteInstance = %v", got)
+	}
+}
@@ -0,0 +1,360 @@
+package ui
+
+import (
+	"log/slog"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// Action is one TUI command. The registry of actions drives the main-view
+// key map (including [keys] overrides from config.toml), the help overlay and
+// the command palette.
+//
+// Keys are the default bindings as reported by tea.KeyMsg.String(); they are
+// also what runMainKey switches on. An action without an ID is a help-only
+// note (e.g. "n → w") and can't be rebound or run from the palette.
+type Action struct {
+	ID      string
+	Section string
+	Title   string
+	Keys    []string
+	HelpKey string // Key label in help when the keys don't read well (e.g. "1-9")
+
+	NoPalette bool               // Hidden from the command palette (e.g. cursor movement)
+	Available func(h *Home) bool // Whether the action applies in the current context (nil = always)
+
+	// Run executes the action from the palette. nil runs the default key.
+	Run func(h *Home) (tea.Model, tea.Cmd)
+
+	// Args lists the choices for a parameterized action ("Move selected to
+	// group…"); the palette asks for one and calls RunArg with it.
+	Args   func(h *Home) []PaletteArg
+	RunArg func(h *Home, arg PaletteArg) (tea.Model, tea.Cmd)
+}
+
+// PaletteArg is one choice for a parameterized action
+type PaletteArg struct {
+	Label string // What the palette shows and matches against
+	Value string // What RunArg receives (session ID, group path, ...)
+}
+
+// Help sections in display order
+const (
+	sectionNavigation  = "NAVIGATION"
+	sectionSessions    = "SESSIONS"
+	sectionMCP         = "MCP & SKILLS"
+	sectionMultiSelect = "MULTI-SELECT"
+	sectionWorktrees   = "WORKTREES"
+	sectionGroups      = "GROUPS"
+	sectionSearch      = "SEARCH & FILTER"
+	sectionOther       = "OTHER"
+)
+
+var actionSections = []string{
+	sectionNavigation, sectionSessions, sectionMCP, sectionMultiSelect,
+	sectionWorktrees, sectionGroups, sectionSearch, sectionOther,
+}
+
+// Availability helpers
+func onSession(h *Home) bool { return h.getSelectedSession() != nil }
+
+func onGroup(h *Home) bool {
+	return h.cursor < len(h.flatItems) && h.flatItems[h.cursor].Type == session.ItemTypeGroup
+}
+
+func onItem(h *Home) bool { return h.cursor < len(h.flatItems) }
+
+func onTool(tools ...string) func(h *Home) bool {
+	return func(h *Home) bool {
+		inst := h.getSelectedSession()
+		if inst == nil {
+			return false
+		}
+		for _, t := range tools {
+			if inst.Tool == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func hasTargets(h *Home) bool { return len(h.actionTargets()) > 0 }
+
+// defaultActions returns the action registry
+func defaultActions() []*Action {
+	return []*Action{
+		// Navigation
+		{ID: "move_down", Section: sectionNavigation, Title: "Move down", Keys: []string{"down", "j"}, NoPalette: true},
+		{ID: "move_up", Section: sectionNavigation, Title: "Move up", Keys: []string{"up", "k"}, NoPalette: true},
+		{ID: "half_page_up", Section: sectionNavigation, Title: "Half page up", Keys: []string{"ctrl+u"}, NoPalette: true},
+		{ID: "half_page_down", Section: sectionNavigation, Title: "Half page down", Keys: []string{"ctrl+d"}, NoPalette: true},
+		{ID: "page_up", Section: sectionNavigation, Title: "Full page up", Keys: []string{"ctrl+b"}, NoPalette: true},
+		{ID: "page_down", Section: sectionNavigation, Title: "Full page down", Keys: []string{"ctrl+f"}, NoPalette: true},
+		{ID: "jump_top", Section: sectionNavigation, Title: "Jump to top", HelpKey: "gg", Run: func(h *Home) (tea.Model, tea.Cmd) {
+			h.cursor = 0
+			h.syncViewport()
+			return h, nil
+		}},
+		{ID: "collapse", Section: sectionNavigation, Title: "Collapse / parent", Keys: []string{"h", "left"}, NoPalette: true},
+		{ID: "expand", Section: sectionNavigation, Title: "Expand / toggle group", HelpKey: "l / Right", Keys: []string{"tab", "l", "right"}, Available: onGroup},
+		{ID: "jump_group", Section: sectionNavigation, Title: "Jump to group", HelpKey: "1-9", Keys: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"}, NoPalette: true},
+		{ID: "attach", Section: sectionNavigation, Title: "Attach / toggle", Keys: []string{"enter"}, Available: onItem},
+		{ID: "goto_session", Section: sectionNavigation, Title: "Go to session…", Args: gotoSessionArgs, RunArg: runGotoSession},
+		{ID: "goto_group", Section: sectionNavigation, Title: "Go to group…", Args: groupArgs, RunArg: runGotoGroup},
+
+		// Sessions
+		{ID: "new_session", Section: sectionSessions, Title: "New session", Keys: []string{"n"}},
+		{ID: "quick_session", Section: sectionSessions, Title: "Quick create (auto name, smart defaults)", Keys: []string{"N"}},
+		{ID: "rename", Section: sectionSessions, Title: "Rename session or group", Keys: []string{"r"}, Available: onItem},
+		{ID: "restart", Section: sectionSessions, Title: "Restart session", Keys: []string{"R"}, Available: onSession},
+		{ID: "delete", Section: sectionSessions, Title: "Delete session or group", Keys: []string{"d"}, Available: onItem},
+		{ID: "undo_delete", Section: sectionSessions, Title: "Undo delete", Keys: []string{"ctrl+z"}, Available: func(h *Home) bool { return len(h.undoStack) > 0 }},
+		{ID: "move", Section: sectionSessions, Title: "Move to group", Keys: []string{"M", "shift+m"}, Available: onSession},
+		{ID: "move_to_group", Section: sectionSessions, Title: "Move selected to group…", Available: hasTargets, Args: groupArgs, RunArg: runMoveToGroup},
+		{ID: "reorder_up", Section: sectionSessions, Title: "Reorder up", HelpKey: "K / Shift+↑", Keys: []string{"shift+up", "K"}, NoPalette: true},
+		{ID: "reorder_down", Section: sectionSessions, Title: "Reorder down", HelpKey: "J / Shift+↓", Keys: []string{"shift+down", "J"}, NoPalette: true},
+		{ID: "mark_unread", Section: sectionSessions, Title: "Mark unread", Keys: []string{"u"}, Available: onSession},
+		{ID: "preview_mode", Section: sectionSessions, Title: "Toggle preview mode (output/stats/both/git)", Keys: []string{"v"}},
+		{ID: "diff_scroll_up", Section: sectionSessions, Title: "Scroll git diff up (git preview mode)", Keys: []string{"["}, NoPalette: true},
+		{ID: "diff_scroll_down", Section: sectionSessions, Title: "Scroll git diff down (git preview mode)", Keys: []string{"]"}, NoPalette: true},
+		{ID: "fork_quick", Section: sectionSessions, Title: "Quick fork (Claude only)", Keys: []string{"f"}, Available: onSession},
+		{ID: "fork", Section: sectionSessions, Title: "Fork with options (Claude only)", Keys: []string{"F", "shift+f"}, Available: onSession},
+		{ID: "copy_output", Section: sectionSessions, Title: "Copy output to clipboard", Keys: []string{"c"}, Available: onSession},
+		{ID: "send_output", Section: sectionSessions, Title: "Send output to session", Keys: []string{"x"}, Available: onSession},
+		{ID: "send_prompt", Section: sectionSessions, Title: "Send prompt from library…", Keys: []string{"p"}, Available: hasTargets, Args: promptArgs, RunArg: runSendPrompt},
+		{ID: "gemini_yolo", Section: sectionSessions, Title: "Toggle YOLO mode (Gemini)", Keys: []string{"y"}, Available: onTool("gemini")},
+		{ID: "gemini_model", Section: sectionSessions, Title: "Choose model (Gemini)", Keys: []string{"ctrl+g"}, Available: onTool("gemini")},
+
+		// MCP & skills
+		{ID: "mcp_manager", Section: sectionMCP, Title: "MCP Manager", Keys: []string{"m"}, Available: onTool("claude", "gemini", "codex", "opencode")},
+		{ID: "attach_mcp", Section: sectionMCP, Title: "Attach MCP to selected…", Available: hasTargets, Args: attachMCPArgs, RunArg: runBulkMCP(BulkAttachMCP)},
+		{ID: "detach_mcp", Section: sectionMCP, Title: "Detach MCP from selected…", Available: hasTargets, Args: detachMCPArgs, RunArg: runBulkMCP(BulkDetachMCP)},
+		{ID: "skills_manager", Section: sectionMCP, Title: "Skills Manager (Claude)", Keys: []string{"s"}, Available: onTool("claude")},
+
+		// Multi-select
+		{ID: "select_toggle", Section: sectionMultiSelect, Title: "Mark session (whole group on header)", Keys: []string{" "}, NoPalette: true},
+		{ID: "select_range", Section: sectionMultiSelect, Title: "Visual range (V again to keep)", Keys: []string{"V"}},
+		{ID: "select_all", Section: sectionMultiSelect, Title: "Select all matching status filter", Keys: []string{"*"}},
+		{ID: "bulk_actions", Section: sectionMultiSelect, Title: "Bulk actions (send, MCP, stop, ...)", Keys: []string{"B"}, Available: func(h *Home) bool { return h.hasBulkSelection() }},
+		{ID: "grid_view", Section: sectionMultiSelect, Title: "Grid view of 2-9 selected sessions", Keys: []string{"T"}},
+		{Section: sectionMultiSelect, Title: "Restart/delete/move/fork selection", HelpKey: "R d M f"},
+		{Section: sectionMultiSelect, Title: "Clear selection", HelpKey: "Esc"},
+
+		// Worktrees
+		{ID: "worktree_finish", Section: sectionWorktrees, Title: "Finish worktree (merge + cleanup)", Keys: []string{"W", "shift+w"}, Available: onSession},
+		{Section: sectionWorktrees, Title: "Create session in worktree", HelpKey: "n → w"},
+		{Section: sectionWorktrees, Title: "Fork session into worktree", HelpKey: "F → w"},
+
+		// Groups
+		{ID: "new_group", Section: sectionGroups, Title: "New group (subgroup on a group)", Keys: []string{"g"}},
+
+		// Search & filter
+		{ID: "search", Section: sectionSearch, Title: "Open search", Keys: []string{"/"}},
+		{ID: "global_search", Section: sectionSearch, Title: "Global search", Keys: []string{"G"}},
+		{ID: "filter_all", Section: sectionSearch, Title: "Show all statuses", Keys: []string{"0"}},
+		{ID: "filter_running", Section: sectionSearch, Title: "Filter running", Keys: []string{"!", "shift+1"}},
+		{ID: "filter_waiting", Section: sectionSearch, Title: "Filter waiting", Keys: []string{"@", "shift+2"}},
+		{ID: "filter_idle", Section: sectionSearch, Title: "Filter idle", Keys: []string{"#", "shift+3"}},
+		{ID: "filter_error", Section: sectionSearch, Title: "Filter error", Keys: []string{"$", "shift+4"}},
+
+		// Other
+		{ID: "command_palette", Section: sectionOther, Title: "Command palette", Keys: []string{"ctrl+k", ":"}, NoPalette: true},
+		{ID: "settings", Section: sectionOther, Title: "Settings", Keys: []string{"S"}},
+		{ID: "reload", Section: sectionOther, Title: "Reload from disk", Keys: []string{"ctrl+r"}},
+		{ID: "import", Section: sectionOther, Title: "Import tmux sessions", Keys: []string{"i"}},
+		{Section: sectionOther, Title: "Detach from session", HelpKey: "Ctrl+Q"},
+		{ID: "quit", Section: sectionOther, Title: "Quit", Keys: []string{"q", "ctrl+c"}},
+		{ID: "help", Section: sectionOther, Title: "This help", Keys: []string{"?"}},
+	}
+}
+
+// KeyMap resolves pressed keys to actions, applying [keys] overrides
+type KeyMap struct {
+	actions   []*Action
+	byID      map[string]*Action
+	bound     map[string]*Action  // effective key -> action
+	defaults  map[string]bool     // every default key of a rebindable action
+	overrides map[string][]string // action ID -> keys, for overridden actions only
+}
+
+// NewKeyMap builds the key map for actions. overrides maps action IDs to
+// replacement keys; unknown IDs are logged and ignored.
+func NewKeyMap(actions []*Action, overrides map[string][]string) *KeyMap {
+	k := &KeyMap{
+		actions:   actions,
+		byID:      make(map[string]*Action),
+		bound:     make(map[string]*Action),
+		defaults:  make(map[string]bool),
+		overrides: make(map[string][]string),
+	}
+	for _, a := range actions {
+		if a.ID == "" {
+			continue
+		}
+		k.byID[a.ID] = a
+		for _, key := range a.Keys {
+			k.defaults[key] = true
+		}
+	}
+	for id, keys := range overrides {
+		if _, ok := k.byID[id]; !ok {
+			uiLog.Warn("keys_unknown_action", slog.String("action", id))
+			continue
+		}
+		normalized := make([]string, 0, len(keys))
+		for _, key := range keys {
+			if key = normalizeKey(key); key != "" {
+				normalized = append(normalized, key)
+			}
+		}
+		k.overrides[id] = normalized
+	}
+
+	// Defaults first so overrides win when a key is claimed twice
+	for _, a := range actions {
+		if _, overridden := k.overrides[a.ID]; a.ID == "" || overridden {
+			continue
+		}
+		for _, key := range a.Keys {
+			k.bound[key] = a
+		}
+	}
+	for id, keys := range k.overrides {
+		for _, key := range keys {
+			k.bound[key] = k.byID[id]
+		}
+	}
+	return k
+}
+
+// normalizeKey accepts "space" and mixed-case modifiers in config
+func normalizeKey(key string) string {
+	key = strings.TrimSpace(key)
+	if strings.EqualFold(key, "space") {
+		return " "
+	}
+	if i := strings.LastIndex(key, "+"); i > 0 && i < len(key)-1 {
+		mods := strings.ToLower(key[:i+1])
+		if strings.Contains(mods, "ctrl+") {
+			// Terminals can't tell ctrl+R from ctrl+r; bubbletea reports lowercase
+			return mods + strings.ToLower(key[i+1:])
+		}
+		return mods + key[i+1:]
+	}
+	return key
+}
+
+// Resolve maps a pressed key to the default key runMainKey understands.
+// ok is false when the key was unbound by an override.
+func (k *KeyMap) Resolve(key string) (string, bool) {
+	if a, ok := k.bound[key]; ok {
+		for _, d := range a.Keys {
+			if d == key {
+				return key, true
+			}
+		}
+		if len(a.Keys) == 0 {
+			// Palette-only action bound by the user: the key has no switch case
+			return "", false
+		}
+		return a.Keys[0], true
+	}
+	if k.defaults[key] {
+		return "", false
+	}
+	return key, true
+}
+
+// ActionForKey returns the action a pressed key triggers, if any
+func (k *KeyMap) ActionForKey(key string) *Action {
+	return k.bound[key]
+}
+
+// Action returns the action with id
+func (k *KeyMap) Action(id string) *Action {
+	return k.byID[id]
+}
+
+// Actions returns the registry in order
+func (k *KeyMap) Actions() []*Action {
+	return k.actions
+}
+
+// Keys returns the effective keys of a
+func (k *KeyMap) Keys(a *Action) []string {
+	if keys, ok := k.overrides[a.ID]; ok {
+		return keys
+	}
+	return a.Keys
+}
+
+// Label renders the effective keys of a for help and the palette
+func (k *KeyMap) Label(a *Action) string {
+	if _, overridden := k.overrides[a.ID]; !overridden && a.HelpKey != "" {
+		return a.HelpKey
+	}
+	keys := k.Keys(a)
+	var labels []string
+	for _, key := range keys {
+		// "M" and "shift+m" are the same key on most terminals; show one
+		if strings.HasPrefix(key, "shift+") && len([]rune(key)) == len("shift+")+1 && len(keys) > 1 {
+			continue
+		}
+		labels = append(labels, keyLabel(key))
+	}
+	return strings.Join(labels, " / ")
+}
+
+// DisplayKey returns the label to show in place of defaultKey in the help
+// bar, following any override of the action that owns it.
+func (k *KeyMap) DisplayKey(defaultKey string) string {
+	for _, a := range k.actions {
+		if a.ID == "" {
+			continue
+		}
+		for _, key := range a.Keys {
+			if key != defaultKey {
+				continue
+			}
+			if keys, ok := k.overrides[a.ID]; ok {
+				if len(keys) == 0 {
+					return "-"
+				}
+				return keyLabel(keys[0])
+			}
+			return defaultKey
+		}
+	}
+	return defaultKey
+}
+
+// keyLabel renders a key string for display ("ctrl+z" -> "Ctrl+Z")
+func keyLabel(key string) string {
+	switch key {
+	case " ":
+		return "Space"
+	case "up", "down", "left", "right", "enter", "tab", "esc":
+		return strings.ToUpper(key[:1]) + key[1:]
+	}
+	if i := strings.LastIndex(key, "+"); i > 0 && i < len(key)-1 {
+		var mods []string
+		for _, m := range strings.Split(key[:i], "+") {
+			mods = append(mods, strings.ToUpper(m[:1])+m[1:])
+		}
+		return strings.Join(mods, "+") + "+" + keyLabel(capitalizeKey(key[i+1:]))
+	}
+	return key
+}
+
+func capitalizeKey(k string) string {
+	r := []rune(k)
+	if len(r) == 1 {
+		return string(unicode.ToUpper(r[0]))
+	}
+	return k
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeyMapOverrides(t *testing.T) {
+	k := NewKeyMap(defaultActions(), map[string][]string{
+		"restart":         {"ctrl+R"}, // Modifiers are case-insensitive
+		"mark_unread":     {},         // Unbound
+		"command_palette": {"space"},
+		"no_such_action":  {"z"},
+	})
+
+	tests := []struct {
+		key  string
+		want string
+		ok   bool
+	}{
+		{"ctrl+r", "R", true},      // Override key resolves to the default key
+		{"R", "", false},           // Default key of an overridden action is freed
+		{"u", "", false},           // Unbound
+		{" ", "ctrl+k", true},      // Override beats the default select_toggle binding
+		{"n", "n", true},           // Untouched defaults pass through
+		{"ctrl+x", "ctrl+x", true}, // Keys outside the registry pass through
+	}
+	for _, tt := range tests {
+		got, ok := k.Resolve(tt.key)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Resolve(%q) = %q, %v; want %q, %v", tt.key, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if got := k.Label(k.Action("restart")); got != "Ctrl+R" {
+		t.Errorf("restart label = %q, want Ctrl+R", got)
+	}
+	if got := k.Label(k.Action("mark_unread")); got != "" {
+		t.Errorf("unbound label = %q, want empty", got)
+	}
+	if got := k.DisplayKey("R"); got != "Ctrl+R" {
+		t.Errorf("DisplayKey(R) = %q, want Ctrl+R", got)
+	}
+	if got := k.DisplayKey("u"); got != "-" {
+		t.Errorf("DisplayKey(u) = %q, want -", got)
+	}
+	if got := k.DisplayKey("n"); got != "n" {
+		t.Errorf("DisplayKey(n) = %q, want n", got)
+	}
+}
+
+func TestKeyMapPaletteOnlyBinding(t *testing.T) {
+	k := NewKeyMap(defaultActions(), map[string][]string{"goto_session": {"ctrl+o"}})
+	if a := k.ActionForKey("ctrl+o"); a == nil || a.ID != "goto_session" {
+		t.Fatalf("ctrl+o should trigger goto_session, got %v", a)
+	}
+	if _, ok := k.Resolve("ctrl+o"); ok {
+		t.Error("a keyless action has no default key to resolve to")
+	}
+}
+
+func TestKeyLabel(t *testing.T) {
+	tests := map[string]string{
+		" ":          "Space",
+		"enter":      "Enter",
+		"ctrl+z":     "Ctrl+Z",
+		"shift+down": "Shift+Down",
+		"ctrl+alt+k": "Ctrl+Alt+K",
+		"x":          "x",
+	}
+	for key, want := range tests {
+		if got := keyLabel(key); got != want {
+			t.Errorf("keyLabel(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestHelpFollowsKeyMap(t *testing.T) {
+	h := NewHelpOverlay()
+	h.SetKeyMap(NewKeyMap(defaultActions(), map[string][]string{"restart": {"ctrl+r"}, "reload": {}}))
+	h.SetSize(100, 200)
+	h.Show()
+	view := h.View()
+	for _, want := range []string{"NAVIGATION", "MULTI-SELECT", "Ctrl+R", "Restart session", "Ctrl+K / :"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("help should contain %q", want)
+		}
+	}
+	if strings.Contains(view, "Reload from disk") {
+		t.Error("an unbound action should not be listed")
+	}
+}
@@ -39,8 +39,8 @@ func TestAnalyticsPanel_SetAnalytics(t *testing.T) {
 
 	panel.SetAnalytics(analytics)
 
-	if panel.analytics != analytics {
-		t.Error("SetAnalytics should set the analytics pointer")
+	if panel.analytics == nil || panel.analytics.Tool != "claude" || panel.analytics.InputTokens != 1000 || panel.analytics.OutputTokens != 500 {
+		t.Errorf("SetAnalytics should normalize the analytics, got %+v", panel.analytics)
 	}
 }
 
@@ -607,3 +607,41 @@ func TestAnalyticsPanel_SetDisplaySettings(t *testing.T) {
 		t.Error("View should NOT show tools when disabled")
 	}
 }
+
+func TestAnalyticsPanel_View_AgentAnalytics(t *testing.T) {
+	panel := NewAnalyticsPanel()
+	panel.SetAgentAnalytics(&session.AgentAnalytics{
+		Tool:                 "codex",
+		Model:                "gpt-5-codex",
+		InputTokens:          10000,
+		OutputTokens:         1500,
+		CacheReadTokens:      20000,
+		CurrentContextTokens: 136000,
+		ContextWindow:        272000,
+		TotalTurns:           2,
+		ToolCalls:            []session.ToolCall{{Name: "shell", Count: 2}},
+		EstimatedCost:        0.03,
+	})
+	panel.SetDisplaySettings(allSectionsEnabled())
+	panel.SetSize(60, 30)
+
+	view := panel.View()
+	for _, want := range []string{"50.0%", "Cache Read:", "Turns:", "Model:", "gpt-5-codex", "shell", "$0.0300"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View should contain %q, got:\n%s", want, view)
+		}
+	}
+}
+
+func TestAnalyticsPanel_SetGeminiAnalytics(t *testing.T) {
+	panel := NewAnalyticsPanel()
+	panel.SetGeminiAnalytics(&session.GeminiSessionAnalytics{InputTokens: 100, Model: "gemini-2.5-pro"})
+	if panel.analytics == nil || panel.analytics.Tool != "gemini" || panel.analytics.Model != "gemini-2.5-pro" {
+		t.Errorf("SetGeminiAnalytics should normalize the analytics, got %+v", panel.analytics)
+	}
+
+	panel.SetAgentAnalytics(nil)
+	if !strings.Contains(panel.View(), "No analytics available") {
+		t.Error("clearing analytics should render the empty state")
+	}
+}
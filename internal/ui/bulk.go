@@ -0,0 +1,519 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+// Multi-select: sessions are marked with space (or a whole group from its
+// header), a visual range with V, or everything matching the status filter
+// with *. Bulk actions then run over the selection one session at a time,
+// reporting into the bulk dialog's progress view.
+
+// bulkStepMsg reports the outcome of one target of a bulk operation
+type bulkStepMsg struct {
+	index   int
+	inner   tea.Msg // message from a reused single-session cmd (restart/delete/fork)
+	err     error
+	skipped string
+}
+
+// hasBulkSelection reports whether any session is marked or a visual range is active
+func (h *Home) hasBulkSelection() bool {
+	return len(h.bulkSelected) > 0 || h.visualAnchor != ""
+}
+
+// isBulkMarked reports whether id is part of the selection (marked or in the
+// visual range). The range comes from visualRange, which renderSessionList
+// computes once per frame.
+func (h *Home) isBulkMarked(id string) bool {
+	return h.bulkSelected[id] || h.visualRange[id]
+}
+
+// visualItemKey identifies a list item across rebuilds of flatItems
+func visualItemKey(item session.Item) string {
+	if item.Type == session.ItemTypeSession && item.Session != nil {
+		return "session:" + item.Session.ID
+	}
+	return "group:" + item.Path
+}
+
+// visualRangeSet returns the visual range as a set of session IDs
+func (h *Home) visualRangeSet() map[string]bool {
+	ids := h.visualRangeIDs()
+	if len(ids) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// visualRangeIDs returns the sessions between the visual anchor and the
+// cursor. An anchor that left the list (deleted, filtered or collapsed away)
+// shrinks the range to the cursor.
+func (h *Home) visualRangeIDs() []string {
+	if h.visualAnchor == "" || len(h.flatItems) == 0 {
+		return nil
+	}
+	anchor := h.cursor
+	for i, item := range h.flatItems {
+		if visualItemKey(item) == h.visualAnchor {
+			anchor = i
+			break
+		}
+	}
+	lo, hi := anchor, h.cursor
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	if hi >= len(h.flatItems) {
+		hi = len(h.flatItems) - 1
+	}
+	var ids []string
+	for i := lo; i <= hi; i++ {
+		if item := h.flatItems[i]; item.Type == session.ItemTypeSession && item.Session != nil {
+			ids = append(ids, item.Session.ID)
+		}
+	}
+	return ids
+}
+
+// selectedSessions returns the selection in list order. Marks for sessions
+// that no longer exist are ignored.
+func (h *Home) selectedSessions() []*session.Instance {
+	ids := make(map[string]bool, len(h.bulkSelected))
+	for id := range h.bulkSelected {
+		ids[id] = true
+	}
+	for _, id := range h.visualRangeIDs() {
+		ids[id] = true
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var result []*session.Instance
+	seen := make(map[string]bool, len(ids))
+	// flatItems order first so the summary matches what's on screen, then
+	// anything hidden in collapsed groups or by the status filter
+	for _, item := range h.flatItems {
+		if item.Type == session.ItemTypeSession && item.Session != nil && ids[item.Session.ID] {
+			result = append(result, item.Session)
+			seen[item.Session.ID] = true
+		}
+	}
+	h.instancesMu.RLock()
+	for _, inst := range h.instances {
+		if ids[inst.ID] && !seen[inst.ID] {
+			result = append(result, inst)
+		}
+	}
+	h.instancesMu.RUnlock()
+	return result
+}
+
+// toggleBulkMark marks or unmarks the item under the cursor. On a group
+// header it toggles every session in the group and its subgroups.
+func (h *Home) toggleBulkMark() {
+	if h.cursor >= len(h.flatItems) {
+		return
+	}
+	item := h.flatItems[h.cursor]
+	switch item.Type {
+	case session.ItemTypeSession:
+		if item.Session == nil {
+			return
+		}
+		if h.bulkSelected[item.Session.ID] {
+			delete(h.bulkSelected, item.Session.ID)
+		} else {
+			h.bulkSelected[item.Session.ID] = true
+		}
+	case session.ItemTypeGroup:
+		h.instancesMu.RLock()
+		var members []string
+		for _, inst := range h.instances {
+			if inst.GroupPath == item.Path || strings.HasPrefix(inst.GroupPath, item.Path+"/") {
+				members = append(members, inst.ID)
+			}
+		}
+		h.instancesMu.RUnlock()
+		h.toggleBulkSet(members)
+	}
+}
+
+// selectAllMatching toggles every session matching the status filter (all
+// sessions when no filter is active), including ones in collapsed groups.
+func (h *Home) selectAllMatching() {
+	h.instancesMu.RLock()
+	var ids []string
+	for _, inst := range h.instances {
+		if h.statusFilter == "" || inst.Status == h.statusFilter {
+			ids = append(ids, inst.ID)
+		}
+	}
+	h.instancesMu.RUnlock()
+	h.toggleBulkSet(ids)
+}
+
+// toggleBulkSet selects all of ids, or unselects them if they're all selected already
+func (h *Home) toggleBulkSet(ids []string) {
+	all := len(ids) > 0
+	for _, id := range ids {
+		if !h.bulkSelected[id] {
+			all = false
+			break
+		}
+	}
+	for _, id := range ids {
+		if all {
+			delete(h.bulkSelected, id)
+		} else {
+			h.bulkSelected[id] = true
+		}
+	}
+}
+
+// toggleVisualMode starts a visual range at the cursor, or commits the
+// current range to the marked set.
+func (h *Home) toggleVisualMode() {
+	if h.visualAnchor == "" {
+		if h.cursor < len(h.flatItems) {
+			h.visualAnchor = visualItemKey(h.flatItems[h.cursor])
+		}
+		return
+	}
+	for _, id := range h.visualRangeIDs() {
+		h.bulkSelected[id] = true
+	}
+	h.visualAnchor = ""
+}
+
+// clearBulkSelection drops all marks and ends visual mode
+func (h *Home) clearBulkSelection() {
+	h.bulkSelected = make(map[string]bool)
+	h.visualAnchor = ""
+}
+
+// openBulkDialog shows the bulk menu for the selection. A non-nil action
+// skips the menu and goes straight to that action.
+func (h *Home) openBulkDialog(action *BulkAction) (tea.Model, tea.Cmd) {
+	targets := h.selectedSessions()
+	if len(targets) == 0 {
+		h.setError(fmt.Errorf("no sessions selected"))
+		return h, nil
+	}
+	// Commit a pending visual range so the selection survives cursor moves
+	if h.visualAnchor != "" {
+		h.toggleVisualMode()
+	}
+	h.bulkDialog.SetSize(h.width, h.height)
+	if action != nil {
+		h.bulkDialog.ShowAction(targets, *action, h.bulkChoices(*action, targets))
+	} else {
+		h.bulkDialog.Show(targets)
+	}
+	return h, nil
+}
+
+// openBulkAction opens the bulk dialog directly at action
+func (h *Home) openBulkAction(action BulkAction) (tea.Model, tea.Cmd) {
+	return h.openBulkDialog(&action)
+}
+
+// bulkChoices returns the picker entries for actions that need one
+func (h *Home) bulkChoices(action BulkAction, targets []*session.Instance) []string {
+	switch action {
+	case BulkMove:
+		var paths []string
+		for _, g := range h.groupTree.GroupList {
+			paths = append(paths, g.Path)
+		}
+		return paths
+	case BulkAttachMCP:
+		return session.GetAvailableMCPNames()
+	case BulkDetachMCP:
+		// Union of the targets' locally attached MCPs
+		seen := make(map[string]bool)
+		var names []string
+		for _, inst := range targets {
+			for _, name := range inst.LocalMCPNames() {
+				if !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+			}
+		}
+		sort.Strings(names)
+		return names
+	case BulkSendPrompt:
+		prompts, err := session.ListPrompts(h.profile)
+		if err != nil {
+			uiLog.Warn("list_prompts_failed", slog.String("error", err.Error()))
+		}
+		names := make([]string, 0, len(prompts))
+		for _, p := range prompts {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+	return nil
+}
+
+// handleBulkDialogKey handles keys while the bulk dialog is visible
+func (h *Home) handleBulkDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	d := h.bulkDialog
+	key := msg.String()
+
+	switch {
+	case d.inProgress():
+		if d.Finished() {
+			d.Hide()
+			h.clearBulkSelection()
+			return h, nil
+		}
+		if key == "esc" || key == "ctrl+c" {
+			d.Cancel()
+		}
+		return h, nil
+
+	case d.inMenu():
+		switch key {
+		case "esc":
+			d.Hide()
+			return h, nil
+		case "enter":
+			action := d.MenuAction()
+			d.selectAction(action, h.bulkChoices(action, d.Targets()))
+			return h, nil
+		}
+		if action, ok := d.MenuShortcut(key); ok {
+			d.selectAction(action, h.bulkChoices(action, d.Targets()))
+			return h, nil
+		}
+
+	case d.inPick(), d.inInput():
+		switch key {
+		case "esc":
+			d.Hide()
+			return h, nil
+		case "enter":
+			d.Confirm()
+			return h, nil
+		}
+
+	case d.inConfirm():
+		switch key {
+		case "y", "Y", "enter":
+			d.StartProgress()
+			return h, h.nextBulkStep()
+		case "n", "N", "esc":
+			d.Hide()
+			return h, nil
+		}
+		return h, nil
+	}
+
+	var cmd tea.Cmd
+	h.bulkDialog, cmd = d.Update(msg)
+	return h, cmd
+}
+
+// nextBulkStep starts the next target of the running bulk operation
+func (h *Home) nextBulkStep() tea.Cmd {
+	d := h.bulkDialog
+	i := d.NextTarget()
+	if i < 0 {
+		h.finishBulk()
+		return nil
+	}
+	inst := d.Targets()[i]
+
+	skip := func(reason string) tea.Cmd {
+		return func() tea.Msg { return bulkStepMsg{index: i, skipped: reason} }
+	}
+	// Sessions deleted elsewhere while the run was in progress
+	if h.getInstanceByID(inst.ID) == nil {
+		return skip("no longer exists")
+	}
+	wrap := func(cmd tea.Cmd) tea.Cmd {
+		return func() tea.Msg { return bulkStepMsg{index: i, inner: cmd()} }
+	}
+
+	switch d.Action() {
+	case BulkRestart:
+		if h.hasActiveAnimation(inst.ID) {
+			return skip("starting")
+		}
+		if !inst.CanRestart() {
+			return skip("can't restart")
+		}
+		h.resumingSessions[inst.ID] = time.Now()
+		return wrap(h.restartSession(inst))
+
+	case BulkStop:
+		if !inst.Exists() {
+			return skip("not running")
+		}
+		return func() tea.Msg { return bulkStepMsg{index: i, err: inst.Kill()} }
+
+	case BulkDelete:
+		return wrap(h.deleteSession(inst))
+
+	case BulkMove:
+		// Group tree changes happen on the UI goroutine in the step handler
+		if inst.GroupPath == d.Choice() {
+			return skip("already in group")
+		}
+		return func() tea.Msg { return bulkStepMsg{index: i} }
+
+	case BulkSend:
+		tmuxSess := inst.GetTmuxSession()
+		if tmuxSess == nil || !tmuxSess.Exists() {
+			return skip("not running")
+		}
+		message := d.Message()
+		return func() tea.Msg { return bulkStepMsg{index: i, err: tmuxSess.SendKeysAndEnter(message)} }
+
+	case BulkAttachMCP, BulkDetachMCP:
+		if !inst.HasLocalMCPScope() {
+			return skip("no local MCP scope")
+		}
+		attach := d.Action() == BulkAttachMCP
+		name := d.Choice()
+		return func() tea.Msg {
+			return bulkStepMsg{index: i, err: applyBulkMCP(inst, name, attach)}
+		}
+
+	case BulkFork:
+		if !inst.CanFork() {
+			return skip("can't fork")
+		}
+		return wrap(h.forkSessionCmd(inst, inst.Title+" (fork)", inst.GroupPath))
+
+	case BulkSendPrompt:
+		tmuxSess := inst.GetTmuxSession()
+		if tmuxSess == nil || !tmuxSess.Exists() {
+			return skip("not running")
+		}
+		profile, name := h.profile, d.Choice()
+		return func() tea.Msg {
+			return bulkStepMsg{index: i, err: sendLibraryPrompt(profile, name, inst, tmuxSess)}
+		}
+	}
+	return skip("unsupported")
+}
+
+// sendLibraryPrompt fills in the named prompt for inst and types it into
+// the session. Rendering reads git state and the transcript, so it runs in
+// the step's command rather than on the UI goroutine.
+func sendLibraryPrompt(profile, name string, inst *session.Instance, tmuxSess *tmux.Session) error {
+	p, err := session.GetPrompt(profile, name)
+	if err != nil {
+		return err
+	}
+	text, err := p.Render(inst, nil)
+	if err != nil {
+		return err
+	}
+	return tmuxSess.SendKeysAndEnter(text)
+}
+
+// applyBulkMCP attaches or detaches a local MCP for inst (its project, or
+// its own CODEX_HOME for Codex) and restarts the session so it picks up the
+// change. Projects shared by several selected sessions are only rewritten once.
+func applyBulkMCP(inst *session.Instance, name string, attach bool) error {
+	current := inst.LocalMCPNames()
+	var updated []string
+	present := false
+	for _, n := range current {
+		if n == name {
+			present = true
+			if attach {
+				updated = append(updated, n)
+			}
+			continue
+		}
+		updated = append(updated, n)
+	}
+	if attach && !present {
+		updated = append(updated, name)
+	}
+	if present != attach {
+		if err := inst.WriteLocalMCPs(updated); err != nil {
+			return fmt.Errorf("failed to write local MCPs: %w", err)
+		}
+	}
+	if inst.Exists() {
+		return inst.Restart()
+	}
+	return nil
+}
+
+// handleBulkStep records a step's outcome and starts the next one
+func (h *Home) handleBulkStep(msg bulkStepMsg) (tea.Model, tea.Cmd) {
+	d := h.bulkDialog
+	if !d.IsVisible() || !d.inProgress() {
+		return h, nil
+	}
+
+	err := msg.err
+	var cmds []tea.Cmd
+	if msg.inner != nil {
+		// Reuse the single-session handlers for state updates, then take
+		// their error for the progress view.
+		switch inner := msg.inner.(type) {
+		case sessionRestartedMsg:
+			err = inner.err
+		case sessionDeletedMsg:
+			err = inner.killErr
+		case sessionForkedMsg:
+			err = inner.err
+		}
+		_, cmd := h.Update(msg.inner)
+		cmds = append(cmds, cmd)
+		// The handlers report through the error bar; the dialog shows it instead
+		h.clearError()
+	} else if msg.skipped == "" && err == nil && d.Action() == BulkMove {
+		err = h.bulkMoveSession(d.Targets()[msg.index], d.Choice())
+	}
+
+	d.RecordResult(msg.index, err, msg.skipped)
+	cmds = append(cmds, h.nextBulkStep())
+	return h, tea.Batch(cmds...)
+}
+
+// bulkMoveSession moves inst into groupPath
+func (h *Home) bulkMoveSession(inst *session.Instance, groupPath string) error {
+	if inst = h.getInstanceByID(inst.ID); inst == nil {
+		return fmt.Errorf("session no longer exists")
+	}
+	h.groupTree.MoveSessionToGroup(inst, groupPath)
+	return nil
+}
+
+// finishBulk persists state once a bulk operation has no targets left
+func (h *Home) finishBulk() {
+	switch h.bulkDialog.Action() {
+	case BulkMove:
+		h.instancesMu.Lock()
+		h.instances = h.groupTree.GetAllInstances()
+		h.instancesMu.Unlock()
+		h.rebuildFlatItems()
+		h.saveInstances()
+	case BulkStop, BulkAttachMCP, BulkDetachMCP:
+		h.cachedStatusCounts.valid.Store(false)
+		h.saveInstances()
+	}
+}
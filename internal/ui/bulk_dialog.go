@@ -0,0 +1,514 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// BulkAction is an operation applied to every multi-selected session
+type BulkAction int
+
+const (
+	BulkRestart BulkAction = iota
+	BulkStop
+	BulkDelete
+	BulkMove
+	BulkSend
+	BulkAttachMCP
+	BulkDetachMCP
+	BulkFork
+	BulkSendPrompt
+)
+
+// bulkActions is the menu order; key is the shortcut inside the menu
+var bulkActions = []struct {
+	action BulkAction
+	key    string
+	label  string
+	verb   string // progress/confirm wording
+}{
+	{BulkRestart, "R", "Restart", "Restart"},
+	{BulkStop, "s", "Stop", "Stop"},
+	{BulkDelete, "d", "Delete", "Delete"},
+	{BulkMove, "M", "Move to group", "Move"},
+	{BulkSend, "x", "Send message", "Send to"},
+	{BulkAttachMCP, "a", "Attach MCP", "Attach MCP to"},
+	{BulkDetachMCP, "D", "Detach MCP", "Detach MCP from"},
+	{BulkFork, "f", "Fork", "Fork"},
+	{BulkSendPrompt, "p", "Send prompt", "Send prompt to"},
+}
+
+func bulkVerb(action BulkAction) string {
+	for _, a := range bulkActions {
+		if a.action == action {
+			return a.verb
+		}
+	}
+	return ""
+}
+
+type bulkStage int
+
+const (
+	bulkStageMenu bulkStage = iota
+	bulkStagePick
+	bulkStageInput
+	bulkStageConfirm
+	bulkStageProgress
+)
+
+// bulkResult is the outcome of one target in the progress view
+type bulkResult struct {
+	done    bool
+	err     error
+	skipped string // reason the session was skipped
+}
+
+// BulkDialog walks through a bulk operation on the multi-selection: pick an
+// action, supply its argument (group, MCP, message), confirm against a
+// summary of the affected sessions, then watch per-session progress.
+type BulkDialog struct {
+	visible       bool
+	width, height int
+	stage         bulkStage
+	action        BulkAction
+	targets       []*session.Instance
+	cursor        int
+	choices       []string // groups, MCP or prompt names for bulkStagePick
+	choice        string
+	input         textinput.Model
+	results       []bulkResult
+	next          int // next target index to run
+	cancelled     bool
+}
+
+// NewBulkDialog creates a new bulk operation dialog
+func NewBulkDialog() *BulkDialog {
+	input := textinput.New()
+	input.Placeholder = "message to send to every selected session"
+	input.CharLimit = 4000
+	input.Width = 50
+	return &BulkDialog{input: input}
+}
+
+// Show opens the action menu for targets
+func (d *BulkDialog) Show(targets []*session.Instance) {
+	d.visible = true
+	d.targets = targets
+	d.stage = bulkStageMenu
+	d.cursor = 0
+	d.choice = ""
+	d.choices = nil
+	d.results = nil
+	d.next = 0
+	d.cancelled = false
+	d.input.SetValue("")
+	d.input.Blur()
+}
+
+// ShowAction opens the dialog with action already chosen. choices feeds the
+// picker for actions that need one (move, attach/detach MCP).
+func (d *BulkDialog) ShowAction(targets []*session.Instance, action BulkAction, choices []string) {
+	d.Show(targets)
+	d.selectAction(action, choices)
+}
+
+// ShowConfirm opens the dialog at the confirmation of action with choice
+// (group path, MCP or prompt name) already made.
+func (d *BulkDialog) ShowConfirm(targets []*session.Instance, action BulkAction, choice string) {
+	d.Show(targets)
+	d.action = action
+	d.choice = choice
+	d.stage = bulkStageConfirm
+}
+
+// selectAction advances from the menu to the stage the action needs next
+func (d *BulkDialog) selectAction(action BulkAction, choices []string) {
+	d.action = action
+	d.cursor = 0
+	switch action {
+	case BulkMove, BulkAttachMCP, BulkDetachMCP, BulkSendPrompt:
+		d.choices = choices
+		d.stage = bulkStagePick
+	case BulkSend:
+		d.stage = bulkStageInput
+		d.input.Focus()
+	default:
+		d.stage = bulkStageConfirm
+	}
+}
+
+// Hide closes the dialog
+func (d *BulkDialog) Hide() {
+	d.visible = false
+	d.targets = nil
+	d.results = nil
+	d.input.Blur()
+}
+
+// IsVisible returns whether the dialog is visible
+func (d *BulkDialog) IsVisible() bool {
+	return d.visible
+}
+
+// SetSize updates the dialog dimensions for centering
+func (d *BulkDialog) SetSize(w, h int) {
+	d.width = w
+	d.height = h
+}
+
+// Action returns the chosen action
+func (d *BulkDialog) Action() BulkAction {
+	return d.action
+}
+
+// Choice returns the picked group path, MCP or prompt name
+func (d *BulkDialog) Choice() string {
+	return d.choice
+}
+
+// Message returns the message for BulkSend
+func (d *BulkDialog) Message() string {
+	return strings.TrimSpace(d.input.Value())
+}
+
+// Targets returns the sessions the operation applies to
+func (d *BulkDialog) Targets() []*session.Instance {
+	return d.targets
+}
+
+// MenuAction returns the action under the menu cursor
+func (d *BulkDialog) MenuAction() BulkAction {
+	return bulkActions[d.cursor].action
+}
+
+// MenuShortcut returns the action bound to key in the menu, if any
+func (d *BulkDialog) MenuShortcut(key string) (BulkAction, bool) {
+	for _, a := range bulkActions {
+		if a.key == key {
+			return a.action, true
+		}
+	}
+	return 0, false
+}
+
+// StartProgress switches to the progress view
+func (d *BulkDialog) StartProgress() {
+	d.stage = bulkStageProgress
+	d.results = make([]bulkResult, len(d.targets))
+	d.next = 0
+	d.cancelled = false
+	d.input.Blur()
+}
+
+// NextTarget returns the index of the next target to run, or -1 when the
+// run is finished or cancelled.
+func (d *BulkDialog) NextTarget() int {
+	if d.stage != bulkStageProgress || d.cancelled || d.next >= len(d.targets) {
+		return -1
+	}
+	i := d.next
+	d.next++
+	return i
+}
+
+// RecordResult stores the outcome of target i
+func (d *BulkDialog) RecordResult(i int, err error, skipped string) {
+	if i < 0 || i >= len(d.results) {
+		return
+	}
+	d.results[i] = bulkResult{done: true, err: err, skipped: skipped}
+}
+
+// Cancel stops the run after the in-flight target
+func (d *BulkDialog) Cancel() {
+	d.cancelled = true
+}
+
+// Finished reports whether the progress view has nothing left to run
+func (d *BulkDialog) Finished() bool {
+	if d.stage != bulkStageProgress {
+		return false
+	}
+	if d.next < len(d.targets) && !d.cancelled {
+		return false
+	}
+	// The last started target must have reported back
+	for i := 0; i < d.next; i++ {
+		if !d.results[i].done {
+			return false
+		}
+	}
+	return true
+}
+
+// Counts returns succeeded, failed and skipped totals
+func (d *BulkDialog) Counts() (ok, failed, skipped int) {
+	for _, r := range d.results {
+		switch {
+		case !r.done:
+		case r.skipped != "":
+			skipped++
+		case r.err != nil:
+			failed++
+		default:
+			ok++
+		}
+	}
+	return ok, failed, skipped
+}
+
+// Stage-specific accessors for the parent key handler
+func (d *BulkDialog) inMenu() bool     { return d.stage == bulkStageMenu }
+func (d *BulkDialog) inPick() bool     { return d.stage == bulkStagePick }
+func (d *BulkDialog) inInput() bool    { return d.stage == bulkStageInput }
+func (d *BulkDialog) inConfirm() bool  { return d.stage == bulkStageConfirm }
+func (d *BulkDialog) inProgress() bool { return d.stage == bulkStageProgress }
+
+// Update handles navigation and text input. Enter, y/n and esc are handled
+// by the parent since they trigger actions.
+func (d *BulkDialog) Update(msg tea.KeyMsg) (*BulkDialog, tea.Cmd) {
+	if !d.visible {
+		return d, nil
+	}
+	switch d.stage {
+	case bulkStageMenu:
+		switch msg.String() {
+		case "j", "down":
+			d.cursor = (d.cursor + 1) % len(bulkActions)
+		case "k", "up":
+			d.cursor = (d.cursor - 1 + len(bulkActions)) % len(bulkActions)
+		}
+	case bulkStagePick:
+		if len(d.choices) == 0 {
+			return d, nil
+		}
+		switch msg.String() {
+		case "j", "down":
+			d.cursor = (d.cursor + 1) % len(d.choices)
+		case "k", "up":
+			d.cursor = (d.cursor - 1 + len(d.choices)) % len(d.choices)
+		}
+	case bulkStageInput:
+		var cmd tea.Cmd
+		d.input, cmd = d.input.Update(msg)
+		return d, cmd
+	}
+	return d, nil
+}
+
+// Confirm advances past the pick or input stage. It returns false when the
+// stage has nothing to confirm yet (no choices, empty message).
+func (d *BulkDialog) Confirm() bool {
+	switch d.stage {
+	case bulkStagePick:
+		if len(d.choices) == 0 {
+			return false
+		}
+		d.choice = d.choices[d.cursor]
+	case bulkStageInput:
+		if d.Message() == "" {
+			return false
+		}
+		d.input.Blur()
+	default:
+		return false
+	}
+	d.stage = bulkStageConfirm
+	return true
+}
+
+// bulkSummaryLimit caps the number of session names listed in the dialog
+const bulkSummaryLimit = 8
+
+// View renders the dialog
+func (d *BulkDialog) View() string {
+	if !d.visible {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorAccent)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorTextDim)
+	selectedStyle := lipgloss.NewStyle().Foreground(ColorAccent).Bold(true)
+	normalStyle := lipgloss.NewStyle().Foreground(ColorText)
+	warnStyle := lipgloss.NewStyle().Foreground(ColorRed).Bold(true)
+	footerStyle := lipgloss.NewStyle().Foreground(ColorComment).Italic(true)
+
+	var lines []string
+	var footer string
+
+	switch d.stage {
+	case bulkStageMenu:
+		lines = append(lines, titleStyle.Render(fmt.Sprintf("Bulk Action (%d sessions)", len(d.targets))), "")
+		for i, a := range bulkActions {
+			label := fmt.Sprintf("%-2s %s", a.key, a.label)
+			if i == d.cursor {
+				lines = append(lines, "> "+selectedStyle.Render(label))
+			} else {
+				lines = append(lines, "  "+normalStyle.Render(label))
+			}
+		}
+		footer = "Enter/key choose | Esc cancel | j/k navigate"
+
+	case bulkStagePick:
+		what := "MCP"
+		switch d.action {
+		case BulkMove:
+			what = "group"
+		case BulkSendPrompt:
+			what = "prompt"
+		}
+		lines = append(lines, titleStyle.Render(fmt.Sprintf("%s %d sessions: choose %s", bulkVerb(d.action), len(d.targets), what)), "")
+		if len(d.choices) == 0 {
+			lines = append(lines, dimStyle.Render(fmt.Sprintf("No %ss available", what)))
+		}
+		for i, c := range d.choices {
+			if i == d.cursor {
+				lines = append(lines, "> "+selectedStyle.Render(c))
+			} else {
+				lines = append(lines, "  "+normalStyle.Render(c))
+			}
+		}
+		footer = "Enter choose | Esc cancel | j/k navigate"
+
+	case bulkStageInput:
+		lines = append(lines, titleStyle.Render(fmt.Sprintf("Send message to %d sessions", len(d.targets))), "")
+		lines = append(lines, d.input.View())
+		footer = "Enter continue | Esc cancel"
+
+	case bulkStageConfirm:
+		header := fmt.Sprintf("%s %d sessions?", bulkVerb(d.action), len(d.targets))
+		switch d.action {
+		case BulkMove:
+			header = fmt.Sprintf("Move %d sessions to '%s'?", len(d.targets), d.choice)
+		case BulkAttachMCP:
+			header = fmt.Sprintf("Attach MCP '%s' to %d sessions?", d.choice, len(d.targets))
+		case BulkDetachMCP:
+			header = fmt.Sprintf("Detach MCP '%s' from %d sessions?", d.choice, len(d.targets))
+		case BulkSendPrompt:
+			header = fmt.Sprintf("Send prompt '%s' to %d sessions?", d.choice, len(d.targets))
+		}
+		lines = append(lines, titleStyle.Render(header), "")
+		lines = append(lines, d.summaryLines(normalStyle, dimStyle)...)
+		lines = append(lines, "")
+		switch d.action {
+		case BulkDelete:
+			lines = append(lines, warnStyle.Render("Kills the tmux sessions and removes their worktrees."))
+		case BulkStop:
+			lines = append(lines, dimStyle.Render("Stops the tmux sessions; restart them later with R."))
+		case BulkAttachMCP, BulkDetachMCP:
+			lines = append(lines, dimStyle.Render("Updates each project's .mcp.json and restarts running sessions."))
+		case BulkSend:
+			lines = append(lines, dimStyle.Render("Message: "+truncateBulkText(d.Message(), 60)))
+		case BulkFork:
+			lines = append(lines, dimStyle.Render("Sessions that can't be forked are skipped."))
+		case BulkSendPrompt:
+			lines = append(lines, dimStyle.Render("Variables are filled in per session; stopped sessions are skipped."))
+		}
+		footer = "y/Enter confirm | n/Esc cancel"
+
+	case bulkStageProgress:
+		finished := d.Finished()
+		ok, failed, skipped := d.Counts()
+		title := fmt.Sprintf("%s: %d/%d", bulkVerb(d.action), ok+failed+skipped, len(d.targets))
+		if finished {
+			title = fmt.Sprintf("%s: done — %d ok, %d failed, %d skipped", bulkVerb(d.action), ok, failed, skipped)
+			if d.cancelled && ok+failed+skipped < len(d.targets) {
+				title += " (cancelled)"
+			}
+		}
+		lines = append(lines, titleStyle.Render(title), "")
+		lines = append(lines, d.progressLines(normalStyle, dimStyle)...)
+		if finished {
+			footer = "Any key close"
+		} else {
+			footer = "Esc stop after current session"
+		}
+	}
+
+	lines = append(lines, "", footerStyle.Render(footer))
+
+	dialogWidth := 64
+	if d.width > 0 && d.width < dialogWidth+10 {
+		dialogWidth = d.width - 10
+		if dialogWidth < 30 {
+			dialogWidth = 30
+		}
+	}
+	box := DialogBoxStyle.Width(dialogWidth).Render(strings.Join(lines, "\n"))
+	return centerInScreen(box, d.width, d.height)
+}
+
+// summaryLines lists the affected sessions, capped at bulkSummaryLimit
+func (d *BulkDialog) summaryLines(normalStyle, dimStyle lipgloss.Style) []string {
+	var lines []string
+	for i, inst := range d.targets {
+		if i == bulkSummaryLimit {
+			lines = append(lines, dimStyle.Render(fmt.Sprintf("  … and %d more", len(d.targets)-bulkSummaryLimit)))
+			break
+		}
+		group := ""
+		if inst.GroupPath != "" {
+			group = dimStyle.Render("  " + inst.GroupPath)
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s%s", statusIndicator(inst.GetStatusThreadSafe()), normalStyle.Render(inst.Title), group))
+	}
+	return lines
+}
+
+// progressLines shows per-session state around the running target
+func (d *BulkDialog) progressLines(normalStyle, dimStyle lipgloss.Style) []string {
+	okStyle := lipgloss.NewStyle().Foreground(ColorGreen)
+	errStyle := lipgloss.NewStyle().Foreground(ColorRed)
+
+	// Keep the window on the most recent activity
+	start := 0
+	if d.next > bulkSummaryLimit {
+		start = d.next - bulkSummaryLimit
+	}
+	end := start + bulkSummaryLimit
+	if end > len(d.targets) {
+		end = len(d.targets)
+	}
+
+	var lines []string
+	if start > 0 {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("  ↑ %d more", start)))
+	}
+	for i := start; i < end; i++ {
+		inst := d.targets[i]
+		r := d.results[i]
+		var line string
+		switch {
+		case r.done && r.skipped != "":
+			line = dimStyle.Render(fmt.Sprintf("  - %s (skipped: %s)", inst.Title, r.skipped))
+		case r.done && r.err != nil:
+			line = errStyle.Render("  ✕ ") + normalStyle.Render(inst.Title) + errStyle.Render(": "+truncateBulkText(r.err.Error(), 40))
+		case r.done:
+			line = okStyle.Render("  ✓ ") + normalStyle.Render(inst.Title)
+		case i < d.next:
+			line = lipgloss.NewStyle().Foreground(ColorYellow).Render("  ⟳ ") + normalStyle.Render(inst.Title)
+		default:
+			line = dimStyle.Render("  · " + inst.Title)
+		}
+		lines = append(lines, line)
+	}
+	if end < len(d.targets) {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("  ↓ %d more", len(d.targets)-end)))
+	}
+	return lines
+}
+
+func truncateBulkText(s string, n int) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if len([]rune(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
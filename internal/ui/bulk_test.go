@@ -0,0 +1,256 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// newBulkTestHome returns a Home with sessions a, b in group "work" and c in "play"
+func newBulkTestHome(t *testing.T) (*Home, []*session.Instance) {
+	t.Helper()
+	home := NewHome()
+	home.width = 120
+	home.height = 40
+
+	var insts []*session.Instance
+	for _, spec := range [][2]string{{"a", "work"}, {"b", "work"}, {"c", "play"}} {
+		inst := session.NewInstance(spec[0], "/tmp/"+spec[0])
+		inst.GroupPath = spec[1]
+		insts = append(insts, inst)
+	}
+	home.instancesMu.Lock()
+	home.instances = insts
+	for _, inst := range insts {
+		home.instanceByID[inst.ID] = inst
+	}
+	home.instancesMu.Unlock()
+	home.groupTree = session.NewGroupTree(home.instances)
+	home.rebuildFlatItems()
+	return home, insts
+}
+
+func bulkKey(k string) tea.KeyMsg {
+	switch k {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case " ":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+func cursorTo(t *testing.T, home *Home, match func(session.Item) bool) {
+	t.Helper()
+	for i, item := range home.flatItems {
+		if match(item) {
+			home.cursor = i
+			return
+		}
+	}
+	t.Fatal("item not found in flatItems")
+}
+
+func selectedTitles(home *Home) []string {
+	var titles []string
+	for _, inst := range home.selectedSessions() {
+		titles = append(titles, inst.Title)
+	}
+	return titles
+}
+
+func TestBulkSelection(t *testing.T) {
+	home, insts := newBulkTestHome(t)
+
+	// Space on a session marks it, again unmarks it
+	cursorTo(t, home, func(it session.Item) bool { return it.Session == insts[2] })
+	home.Update(bulkKey(" "))
+	if got := selectedTitles(home); len(got) != 1 || got[0] != "c" {
+		t.Fatalf("after marking c: %v", got)
+	}
+	home.Update(bulkKey(" "))
+	if home.hasBulkSelection() {
+		t.Fatalf("c should be unmarked, got %v", selectedTitles(home))
+	}
+
+	// Space on a group header marks the whole group
+	cursorTo(t, home, func(it session.Item) bool { return it.Type == session.ItemTypeGroup && it.Path == "work" })
+	home.Update(bulkKey(" "))
+	if got := selectedTitles(home); len(got) != 2 {
+		t.Fatalf("group mark = %v, want a and b", got)
+	}
+
+	// Esc clears before anything else
+	home.Update(bulkKey("esc"))
+	if home.hasBulkSelection() {
+		t.Fatal("esc should clear the selection")
+	}
+
+	// * selects everything matching the status filter, toggling off when repeated
+	home.Update(bulkKey("*"))
+	if got := selectedTitles(home); len(got) != 3 {
+		t.Fatalf("* selected %v, want all", got)
+	}
+	home.Update(bulkKey("*"))
+	if home.hasBulkSelection() {
+		t.Fatal("second * should unselect")
+	}
+}
+
+func TestBulkVisualRange(t *testing.T) {
+	home, insts := newBulkTestHome(t)
+
+	cursorTo(t, home, func(it session.Item) bool { return it.Session == insts[0] })
+	home.Update(bulkKey("V"))
+	// Extend the range to the last item; groups inside the range are ignored
+	home.cursor = len(home.flatItems) - 1
+	if got := selectedTitles(home); len(got) < 2 {
+		t.Fatalf("visual range = %v", got)
+	}
+	inRange := len(selectedTitles(home))
+
+	// Second V keeps the range as marks after the cursor moves away
+	home.Update(bulkKey("V"))
+	home.cursor = 0
+	if home.visualAnchor != "" || len(selectedTitles(home)) != inRange {
+		t.Fatalf("committed range = %v, want %d sessions", selectedTitles(home), inRange)
+	}
+}
+
+func TestBulkVisualRangeFollowsAnchorItem(t *testing.T) {
+	home, insts := newBulkTestHome(t)
+
+	cursorTo(t, home, func(it session.Item) bool { return it.Session == insts[1] })
+	home.Update(bulkKey("V"))
+
+	// A session appearing above the range shifts every index by one
+	added := session.NewInstance("new", "/tmp/new")
+	home.flatItems = append([]session.Item{{Type: session.ItemTypeSession, Session: added}}, home.flatItems...)
+	home.cursor++
+	if got := selectedTitles(home); len(got) != 1 || got[0] != "b" {
+		t.Fatalf("range after list change = %v, want [b]", got)
+	}
+
+	home.renderSessionList(120, 40)
+	if !home.isBulkMarked(insts[1].ID) || home.isBulkMarked(insts[0].ID) {
+		t.Fatalf("rendered range = %v", home.visualRange)
+	}
+}
+
+func TestBulkMoveFlow(t *testing.T) {
+	home, insts := newBulkTestHome(t)
+	for _, inst := range insts[:2] {
+		home.bulkSelected[inst.ID] = true
+	}
+
+	// M with a selection opens the bulk dialog on the group picker
+	home.Update(bulkKey("M"))
+	if !home.bulkDialog.IsVisible() || !home.bulkDialog.inPick() {
+		t.Fatal("M should open the bulk group picker")
+	}
+	for home.bulkDialog.choices[home.bulkDialog.cursor] != "play" {
+		home.Update(bulkKey("j"))
+	}
+	home.Update(bulkKey("enter"))
+	if !home.bulkDialog.inConfirm() {
+		t.Fatal("choosing a group should ask for confirmation")
+	}
+
+	// Run each step the way the program loop would
+	_, cmd := home.Update(bulkKey("y"))
+	for cmd != nil {
+		_, cmd = home.Update(cmd())
+	}
+
+	if !home.bulkDialog.Finished() {
+		t.Fatal("bulk move should have finished")
+	}
+	if ok, failed, skipped := home.bulkDialog.Counts(); ok != 2 || failed != 0 || skipped != 0 {
+		t.Errorf("counts = %d ok, %d failed, %d skipped", ok, failed, skipped)
+	}
+	for _, inst := range insts {
+		if inst.GroupPath != "play" {
+			t.Errorf("%s group = %q, want play", inst.Title, inst.GroupPath)
+		}
+	}
+
+	// Any key closes the finished progress view and clears the selection
+	home.Update(bulkKey("x"))
+	if home.bulkDialog.IsVisible() || home.hasBulkSelection() {
+		t.Error("closing the progress view should hide the dialog and clear the selection")
+	}
+}
+
+func TestBulkDialogSkipsAndCancel(t *testing.T) {
+	home, insts := newBulkTestHome(t)
+	for _, inst := range insts {
+		home.bulkSelected[inst.ID] = true
+	}
+
+	// Nothing is running, so stop skips every session
+	home.Update(bulkKey("B"))
+	if !home.bulkDialog.inMenu() {
+		t.Fatal("B should open the bulk menu")
+	}
+	home.Update(bulkKey("s"))
+	if !home.bulkDialog.inConfirm() || home.bulkDialog.Action() != BulkStop {
+		t.Fatal("s in the menu should go to stop confirmation")
+	}
+	_, cmd := home.Update(bulkKey("y"))
+	// Cancel after the first step: the rest never runs
+	home.Update(bulkKey("esc"))
+	for cmd != nil {
+		_, cmd = home.Update(cmd())
+	}
+	ok, failed, skipped := home.bulkDialog.Counts()
+	if ok != 0 || failed != 0 || skipped != 1 || !home.bulkDialog.Finished() {
+		t.Errorf("after cancel: %d ok, %d failed, %d skipped, finished=%v", ok, failed, skipped, home.bulkDialog.Finished())
+	}
+}
+
+func TestSendPromptFlow(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	home, insts := newBulkTestHome(t)
+	home.paletteHistory = make(map[string]paletteUse)
+	dir, err := session.PromptsDir(home.profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "review.md"), []byte("---\ndescription: Review\n---\nReview {{branch}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	home.bulkSelected[insts[0].ID] = true
+	home.bulkSelected[insts[2].ID] = true
+
+	// p asks for a prompt in the palette, then confirms for the selection
+	home.Update(bulkKey("p"))
+	if home.commandPalette.argAction == nil || home.commandPalette.argAction.ID != "send_prompt" {
+		t.Fatal("p should ask for a prompt")
+	}
+	if e := home.commandPalette.Selected(); e == nil || e.arg == nil || e.arg.Value != "review" {
+		t.Fatalf("expected prompt review, got %+v", e)
+	}
+	home.Update(bulkKey("enter"))
+	d := home.bulkDialog
+	if !d.inConfirm() || d.Action() != BulkSendPrompt || d.Choice() != "review" || len(d.Targets()) != 2 {
+		t.Fatal("choosing a prompt should confirm sending it to the selection")
+	}
+
+	// Nothing is running, so every target is skipped
+	_, cmd := home.Update(bulkKey("y"))
+	for cmd != nil {
+		_, cmd = home.Update(cmd())
+	}
+	if ok, failed, skipped := d.Counts(); ok != 0 || failed != 0 || skipped != 2 {
+		t.Errorf("counts = %d ok, %d failed, %d skipped", ok, failed, skipped)
+	}
+}
@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"strings"
+
 	"github.com/asheshgoplani/agent-deck/internal/session"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -19,6 +21,14 @@ type ClaudeOptionsPanel struct {
 	allowSkipPermissions bool
 	useChrome            bool
 	useTeammateMode      bool
+	// Permission mode: index into claudePermissionChoices (0 = not set)
+	permissionMode int
+	// Launch option inputs (shared by new and fork mode)
+	modelInput           textinput.Model
+	allowedToolsInput    textinput.Model
+	disallowedToolsInput textinput.Model
+	systemPromptInput    textinput.Model
+	addDirsInput         textinput.Model
 	// Focus tracking
 	focusIndex int
 	// Whether this panel is for fork dialog (fewer options)
@@ -27,15 +37,42 @@ type ClaudeOptionsPanel struct {
 	focusCount int
 }
 
-// Focus indices for NewDialog mode:
-// 0: Session mode (radio)
-// 1: Resume ID input (only when mode=resume)
-// 2: Skip permissions checkbox
-// 3: Chrome checkbox
+// claudePermissionChoices are the permission mode values; "" leaves
+// the flag off so Claude uses its own default
+var claudePermissionChoices = []struct{ value, label string }{
+	{"", "Default"},
+	{"acceptEdits", "Accept edits"},
+	{"plan", "Plan"},
+	{"bypassPermissions", "Bypass"},
+}
 
-// Focus indices for ForkDialog mode:
-// 0: Skip permissions checkbox
-// 1: Chrome checkbox
+// Focus order for NewDialog mode (see focusTypes):
+// session mode, resume ID (only when mode=resume), skip permissions, chrome,
+// teammate mode, then the launch options below.
+//
+// Focus order for ForkDialog mode:
+// skip permissions, chrome, teammate mode, then the launch options below.
+//
+// Launch options: model, permission mode, allowed tools, disallowed tools,
+// system prompt, extra dirs.
+
+// newOptionInput creates a text input for a launch option
+func newOptionInput(placeholder string, charLimit int) textinput.Model {
+	input := textinput.New()
+	input.Placeholder = placeholder
+	input.CharLimit = charLimit
+	input.Width = 30
+	return input
+}
+
+// initLaunchInputs creates the launch option text inputs
+func (p *ClaudeOptionsPanel) initLaunchInputs() {
+	p.modelInput = newOptionInput("sonnet, opus, or model name", 100)
+	p.allowedToolsInput = newOptionInput("Bash(git:*),Edit", 500)
+	p.disallowedToolsInput = newOptionInput("WebFetch", 500)
+	p.systemPromptInput = newOptionInput("appended to system prompt", 2000)
+	p.addDirsInput = newOptionInput("../shared,/tmp/data", 500)
+}
 
 // NewClaudeOptionsPanel creates a new panel for NewDialog
 func NewClaudeOptionsPanel() *ClaudeOptionsPanel {
@@ -44,30 +81,65 @@ func NewClaudeOptionsPanel() *ClaudeOptionsPanel {
 	resumeInput.CharLimit = 64
 	resumeInput.Width = 30
 
-	return &ClaudeOptionsPanel{
+	p := &ClaudeOptionsPanel{
 		sessionMode:   0, // new
 		resumeIDInput: resumeInput,
 		isForkMode:    false,
 		focusCount:    5, // Will adjust dynamically
 	}
+	p.initLaunchInputs()
+	return p
 }
 
 // NewClaudeOptionsPanelForFork creates a panel for ForkDialog (fewer options)
 func NewClaudeOptionsPanelForFork() *ClaudeOptionsPanel {
-	return &ClaudeOptionsPanel{
+	p := &ClaudeOptionsPanel{
 		sessionMode:   0,
 		resumeIDInput: textinput.New(), // Not used in fork mode
 		isForkMode:    true,
 		focusCount:    3, // skip, chrome, teammate
 	}
+	p.initLaunchInputs()
+	return p
 }
 
-// SetDefaults applies default values from config
+// SetDefaults applies default values from config and clears the launch options
 func (p *ClaudeOptionsPanel) SetDefaults(config *session.UserConfig) {
 	if config != nil {
 		p.skipPermissions = config.Claude.GetDangerousMode()
 		p.allowSkipPermissions = config.Claude.AllowDangerousMode
 	}
+	p.setLaunchOptions(&session.ClaudeOptions{})
+}
+
+// SetOptions applies saved options, e.g. from a session template or the
+// session being forked. Session mode is reset to "new" since neither
+// resumes a conversation.
+func (p *ClaudeOptionsPanel) SetOptions(opts *session.ClaudeOptions) {
+	if opts == nil {
+		return
+	}
+	p.sessionMode = 0
+	p.skipPermissions = opts.SkipPermissions
+	p.allowSkipPermissions = opts.AllowSkipPermissions
+	p.useChrome = opts.UseChrome
+	p.useTeammateMode = opts.UseTeammateMode
+	p.setLaunchOptions(opts)
+}
+
+// setLaunchOptions fills the model, permission, tool, prompt and dir inputs
+func (p *ClaudeOptionsPanel) setLaunchOptions(opts *session.ClaudeOptions) {
+	p.modelInput.SetValue(opts.Model)
+	p.permissionMode = 0
+	for i, choice := range claudePermissionChoices {
+		if choice.value == opts.PermissionMode {
+			p.permissionMode = i
+		}
+	}
+	p.allowedToolsInput.SetValue(strings.Join(opts.AllowedTools, ","))
+	p.disallowedToolsInput.SetValue(strings.Join(opts.DisallowedTools, ","))
+	p.systemPromptInput.SetValue(opts.AppendSystemPrompt)
+	p.addDirsInput.SetValue(strings.Join(opts.AddDirs, ","))
 }
 
 // Focus sets focus to this panel
@@ -79,7 +151,7 @@ func (p *ClaudeOptionsPanel) Focus() {
 // Blur removes focus from this panel
 func (p *ClaudeOptionsPanel) Blur() {
 	p.focusIndex = -1
-	p.resumeIDInput.Blur()
+	p.updateInputFocus()
 }
 
 // IsFocused returns true if any element in the panel has focus
@@ -99,6 +171,16 @@ func (p *ClaudeOptionsPanel) GetOptions() *session.ClaudeOptions {
 		AllowSkipPermissions: p.allowSkipPermissions,
 		UseChrome:            p.useChrome,
 		UseTeammateMode:      p.useTeammateMode,
+		Model:                strings.TrimSpace(p.modelInput.Value()),
+		PermissionMode:       claudePermissionChoices[p.permissionMode].value,
+		AllowedTools:         session.ParseToolList(p.allowedToolsInput.Value()),
+		DisallowedTools:      session.ParseToolList(p.disallowedToolsInput.Value()),
+		AppendSystemPrompt:   strings.TrimSpace(p.systemPromptInput.Value()),
+	}
+	for _, dir := range strings.Split(p.addDirsInput.Value(), ",") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			opts.AddDirs = append(opts.AddDirs, dir)
+		}
 	}
 
 	if !p.isForkMode {
@@ -147,7 +229,7 @@ func (p *ClaudeOptionsPanel) Update(msg tea.Msg) tea.Cmd {
 
 		case " ":
 			// Don't intercept space when focused on a text input
-			if p.isResumeInputFocused() {
+			if p.focusedInput() != nil {
 				break // Let it fall through to text input handling
 			}
 			// Toggle checkbox or radio at current focus
@@ -155,8 +237,9 @@ func (p *ClaudeOptionsPanel) Update(msg tea.Msg) tea.Cmd {
 			return nil
 
 		case "left", "right":
-			// For session mode radio buttons
-			if !p.isForkMode && p.focusIndex == 0 {
+			// For session mode and permission mode radio buttons
+			switch p.getFocusType() {
+			case "sessionMode":
 				if msg.String() == "left" {
 					p.sessionMode--
 					if p.sessionMode < 0 {
@@ -166,14 +249,22 @@ func (p *ClaudeOptionsPanel) Update(msg tea.Msg) tea.Cmd {
 					p.sessionMode = (p.sessionMode + 1) % 3
 				}
 				return nil
+			case "permissionMode":
+				n := len(claudePermissionChoices)
+				if msg.String() == "left" {
+					p.permissionMode = (p.permissionMode + n - 1) % n
+				} else {
+					p.permissionMode = (p.permissionMode + 1) % n
+				}
+				return nil
 			}
 		}
 	}
 
 	// Update text inputs if focused
-	if p.isResumeInputFocused() {
+	if input := p.focusedInput(); input != nil {
 		var cmd tea.Cmd
-		p.resumeIDInput, cmd = p.resumeIDInput.Update(msg)
+		*input, cmd = input.Update(msg)
 		return cmd
 	}
 
@@ -182,95 +273,85 @@ func (p *ClaudeOptionsPanel) Update(msg tea.Msg) tea.Cmd {
 
 // handleSpaceKey handles space key for toggling checkboxes/radios
 func (p *ClaudeOptionsPanel) handleSpaceKey() {
-	if p.isForkMode {
-		switch p.focusIndex {
-		case 0:
-			p.skipPermissions = !p.skipPermissions
-		case 1:
-			p.useChrome = !p.useChrome
-		case 2:
-			p.useTeammateMode = !p.useTeammateMode
-		}
-	} else {
-		// NewDialog mode
-		switch p.getFocusType() {
-		case "sessionMode":
-			// Cycle through modes on space
-			p.sessionMode = (p.sessionMode + 1) % 3
-		case "skipPermissions":
-			p.skipPermissions = !p.skipPermissions
-		case "chrome":
-			p.useChrome = !p.useChrome
-		case "teammateMode":
-			p.useTeammateMode = !p.useTeammateMode
+	switch p.getFocusType() {
+	case "sessionMode":
+		// Cycle through modes on space
+		p.sessionMode = (p.sessionMode + 1) % 3
+	case "permissionMode":
+		p.permissionMode = (p.permissionMode + 1) % len(claudePermissionChoices)
+	case "skipPermissions":
+		p.skipPermissions = !p.skipPermissions
+	case "chrome":
+		p.useChrome = !p.useChrome
+	case "teammateMode":
+		p.useTeammateMode = !p.useTeammateMode
+	}
+}
+
+// focusTypes returns the focusable elements in order
+func (p *ClaudeOptionsPanel) focusTypes() []string {
+	var types []string
+	if !p.isForkMode {
+		types = append(types, "sessionMode")
+		if p.sessionMode == 2 {
+			types = append(types, "resumeInput")
 		}
 	}
+	return append(types,
+		"skipPermissions", "chrome", "teammateMode",
+		"model", "permissionMode", "allowedTools", "disallowedTools", "systemPrompt", "addDirs")
 }
 
 // getFocusType returns what type of element is currently focused
 func (p *ClaudeOptionsPanel) getFocusType() string {
-	if p.isForkMode {
-		switch p.focusIndex {
-		case 0:
-			return "skipPermissions"
-		case 1:
-			return "chrome"
-		case 2:
-			return "teammateMode"
-		}
-	} else {
-		idx := p.focusIndex
-		// 0: session mode
-		if idx == 0 {
-			return "sessionMode"
-		}
-		// 1: resume input (only if mode == resume)
-		if p.sessionMode == 2 {
-			if idx == 1 {
-				return "resumeInput"
-			}
-			idx-- // Adjust for missing resume input
-		}
-		// 2: skip permissions
-		if idx == 1 {
-			return "skipPermissions"
-		}
-		// 3: chrome
-		if idx == 2 {
-			return "chrome"
-		}
-		// 4: teammate mode
-		if idx == 3 {
-			return "teammateMode"
-		}
+	types := p.focusTypes()
+	if p.focusIndex < 0 || p.focusIndex >= len(types) {
+		return ""
 	}
-	return ""
+	return types[p.focusIndex]
 }
 
 // getFocusCount returns the number of focusable elements
 func (p *ClaudeOptionsPanel) getFocusCount() int {
-	if p.isForkMode {
-		return 3 // skip, chrome, teammate
-	}
-
-	count := 4 // session mode, skip, chrome, teammate
-	if p.sessionMode == 2 {
-		count++ // resume input
-	}
-	return count
+	return len(p.focusTypes())
 }
 
 // isResumeInputFocused returns true if resume input is focused
 func (p *ClaudeOptionsPanel) isResumeInputFocused() bool {
-	return !p.isForkMode && p.sessionMode == 2 && p.focusIndex == 1
+	return p.getFocusType() == "resumeInput"
+}
+
+// focusedInput returns the focused text input, or nil if focus is on a
+// checkbox or radio
+func (p *ClaudeOptionsPanel) focusedInput() *textinput.Model {
+	switch p.getFocusType() {
+	case "resumeInput":
+		return &p.resumeIDInput
+	case "model":
+		return &p.modelInput
+	case "allowedTools":
+		return &p.allowedToolsInput
+	case "disallowedTools":
+		return &p.disallowedToolsInput
+	case "systemPrompt":
+		return &p.systemPromptInput
+	case "addDirs":
+		return &p.addDirsInput
+	}
+	return nil
 }
 
 // updateInputFocus updates which text input has focus
 func (p *ClaudeOptionsPanel) updateInputFocus() {
-	p.resumeIDInput.Blur()
+	for _, input := range []*textinput.Model{
+		&p.resumeIDInput, &p.modelInput, &p.allowedToolsInput,
+		&p.disallowedToolsInput, &p.systemPromptInput, &p.addDirsInput,
+	} {
+		input.Blur()
+	}
 
-	if p.isResumeInputFocused() {
-		p.resumeIDInput.Focus()
+	if input := p.focusedInput(); input != nil {
+		input.Focus()
 	}
 }
 
@@ -299,6 +380,7 @@ func (p *ClaudeOptionsPanel) viewForkMode(labelStyle, activeStyle, dimStyle, hea
 	content += renderCheckboxLine("Skip permissions", p.skipPermissions, p.focusIndex == 0)
 	content += renderCheckboxLine("Chrome mode", p.useChrome, p.focusIndex == 1)
 	content += renderCheckboxLine("Teammate mode", p.useTeammateMode, p.focusIndex == 2)
+	content += p.viewLaunchOptions(activeStyle, 3)
 	return content
 }
 
@@ -339,7 +421,44 @@ func (p *ClaudeOptionsPanel) viewNewMode(labelStyle, activeStyle, dimStyle, head
 
 	// Teammate mode checkbox
 	content += renderCheckboxLine("Teammate mode", p.useTeammateMode, p.focusIndex == focusIdx)
+	focusIdx++
+
+	content += p.viewLaunchOptions(activeStyle, focusIdx)
+
+	return content
+}
+
+// viewLaunchOptions renders the model, permission, tool, prompt and dir
+// rows, the first of which has focus index focusIdx
+func (p *ClaudeOptionsPanel) viewLaunchOptions(activeStyle lipgloss.Style, focusIdx int) string {
+	var content string
+	inputLine := func(label string, input textinput.Model) {
+		if p.focusIndex == focusIdx {
+			content += activeStyle.Render("▶ "+label) + input.View() + "\n"
+		} else {
+			content += "  " + label + input.View() + "\n"
+		}
+		focusIdx++
+	}
+
+	inputLine("Model:       ", p.modelInput)
+
+	radioLabel := "  Permission: "
+	if p.focusIndex == focusIdx {
+		radioLabel = activeStyle.Render("▶ Permission: ")
+	}
+	// Cycled with ←/→ rather than listed: four radios don't fit the fork dialog
+	content += radioLabel + p.renderRadio(claudePermissionChoices[p.permissionMode].label, true, p.focusIndex == focusIdx)
+	if p.focusIndex == focusIdx {
+		content += lipgloss.NewStyle().Foreground(ColorComment).Render("  ←/→")
+	}
+	content += "\n"
+	focusIdx++
 
+	inputLine("Allow tools: ", p.allowedToolsInput)
+	inputLine("Deny tools:  ", p.disallowedToolsInput)
+	inputLine("Sys prompt+: ", p.systemPromptInput)
+	inputLine("Add dirs:    ", p.addDirsInput)
 	return content
 }
 
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestClaudeOptionsPanel_SetOptionsRoundTrip(t *testing.T) {
+	p := NewClaudeOptionsPanel()
+	want := &session.ClaudeOptions{
+		SessionMode:        "new",
+		UseChrome:          true,
+		Model:              "opus",
+		PermissionMode:     "plan",
+		AllowedTools:       []string{"Bash(git add,commit:*)", "Edit"},
+		DisallowedTools:    []string{"WebFetch"},
+		AppendSystemPrompt: "Be terse.",
+		AddDirs:            []string{"../shared", "/tmp/data"},
+	}
+	p.SetOptions(want)
+
+	if got := p.GetOptions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetOptions() = %+v, want %+v", got, want)
+	}
+
+	// SetDefaults clears launch options left over from a template
+	p.SetDefaults(nil)
+	got := p.GetOptions()
+	if got.Model != "" || got.PermissionMode != "" || got.AllowedTools != nil || got.AddDirs != nil {
+		t.Errorf("SetDefaults should clear launch options, got %+v", got)
+	}
+}
+
+func TestClaudeOptionsPanel_ForkModeLaunchInputs(t *testing.T) {
+	p := NewClaudeOptionsPanelForFork()
+	p.Focus()
+
+	// skip, chrome, teammate, then model
+	for i := 0; i < 3; i++ {
+		p.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	if p.getFocusType() != "model" {
+		t.Fatalf("focus = %q, want model", p.getFocusType())
+	}
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("son net")})
+	p.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	if p.skipPermissions {
+		t.Error("space in a text input must not toggle checkboxes")
+	}
+
+	p.Update(tea.KeyMsg{Type: tea.KeyDown})
+	p.Update(tea.KeyMsg{Type: tea.KeyRight})
+	p.Update(tea.KeyMsg{Type: tea.KeyRight})
+
+	opts := p.GetOptions()
+	if opts.Model != "son net" {
+		t.Errorf("Model = %q, want typed value", opts.Model)
+	}
+	if opts.PermissionMode != "plan" {
+		t.Errorf("PermissionMode = %q, want plan", opts.PermissionMode)
+	}
+	if opts.SessionMode != "" {
+		t.Errorf("fork mode should not set session mode, got %q", opts.SessionMode)
+	}
+	if view := p.View(); !strings.Contains(view, "Model:") || !strings.Contains(view, "Plan") {
+		t.Errorf("View should show launch options, got:\n%s", view)
+	}
+}
+
+func TestForkDialog_SetOptionsFromSource(t *testing.T) {
+	d := NewForkDialog()
+	d.Show("Test", "/path", "group")
+	d.SetOptions(&session.ClaudeOptions{SessionMode: "resume", ResumeSessionID: "abc", Model: "opus"})
+
+	opts := d.GetOptions()
+	if opts.Model != "opus" {
+		t.Errorf("Model = %q, want opus", opts.Model)
+	}
+	if opts.ResumeSessionID != "" {
+		t.Errorf("fork options should not resume, got %q", opts.ResumeSessionID)
+	}
+}
@@ -0,0 +1,233 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// CodexOptionsPanel is a UI panel for Codex-specific launch options
+// Used in NewDialog, matching ClaudeOptionsPanel's visual style
+type CodexOptionsPanel struct {
+	yoloMode bool
+	// Sandbox and approval: index into the choices below (0 = not set)
+	sandbox        int
+	approvalPolicy int
+	modelInput     textinput.Model
+	profileInput   textinput.Model
+	// Focus tracking (-1 = not focused)
+	focusIndex int
+}
+
+// codexSandboxChoices are the sandbox values; "" leaves the flag off so
+// Codex uses its config.toml / default
+var codexSandboxChoices = append([]string{""}, session.CodexSandboxModes...)
+
+// codexApprovalChoices are the approval policy values; "" leaves the flag off
+var codexApprovalChoices = append([]string{""}, session.CodexApprovalPolicies...)
+
+// Focus order: yolo, model, profile, sandbox, approval
+var codexFocusTypes = []string{"yolo", "model", "profile", "sandbox", "approval"}
+
+// NewCodexOptionsPanel creates a new panel for NewDialog
+func NewCodexOptionsPanel() *CodexOptionsPanel {
+	return &CodexOptionsPanel{
+		modelInput:   newOptionInput("gpt-5-codex, o3, ...", 100),
+		profileInput: newOptionInput("profile in ~/.codex/config.toml", 100),
+		focusIndex:   -1,
+	}
+}
+
+// SetDefaults applies defaults, e.g. from [codex] and [codex.groups] config
+// or a session template
+func (p *CodexOptionsPanel) SetDefaults(opts *session.CodexOptions) {
+	if opts == nil {
+		opts = &session.CodexOptions{}
+	}
+	p.yoloMode = opts.YoloMode != nil && *opts.YoloMode
+	p.modelInput.SetValue(opts.Model)
+	p.profileInput.SetValue(opts.Profile)
+	p.sandbox = choiceIndex(codexSandboxChoices, opts.Sandbox)
+	p.approvalPolicy = choiceIndex(codexApprovalChoices, opts.ApprovalPolicy)
+}
+
+// choiceIndex returns the index of value in choices, or 0 if absent
+func choiceIndex(choices []string, value string) int {
+	for i, c := range choices {
+		if c == value {
+			return i
+		}
+	}
+	return 0
+}
+
+// GetOptions returns current options as CodexOptions
+func (p *CodexOptionsPanel) GetOptions() *session.CodexOptions {
+	yolo := p.yoloMode
+	return &session.CodexOptions{
+		YoloMode:       &yolo,
+		Model:          strings.TrimSpace(p.modelInput.Value()),
+		Profile:        strings.TrimSpace(p.profileInput.Value()),
+		Sandbox:        codexSandboxChoices[p.sandbox],
+		ApprovalPolicy: codexApprovalChoices[p.approvalPolicy],
+	}
+}
+
+// GetYoloMode returns the current YOLO mode state
+func (p *CodexOptionsPanel) GetYoloMode() bool {
+	return p.yoloMode
+}
+
+// Focus sets focus to this panel
+func (p *CodexOptionsPanel) Focus() {
+	p.focusIndex = 0
+	p.updateInputFocus()
+}
+
+// Blur removes focus from this panel
+func (p *CodexOptionsPanel) Blur() {
+	p.focusIndex = -1
+	p.updateInputFocus()
+}
+
+// IsFocused returns true if any element in the panel has focus
+func (p *CodexOptionsPanel) IsFocused() bool {
+	return p.focusIndex >= 0
+}
+
+// AtTop returns true if focus is on the first element
+func (p *CodexOptionsPanel) AtTop() bool {
+	return p.focusIndex <= 0
+}
+
+// getFocusType returns what type of element is currently focused
+func (p *CodexOptionsPanel) getFocusType() string {
+	if p.focusIndex < 0 || p.focusIndex >= len(codexFocusTypes) {
+		return ""
+	}
+	return codexFocusTypes[p.focusIndex]
+}
+
+// focusedInput returns the focused text input, or nil
+func (p *CodexOptionsPanel) focusedInput() *textinput.Model {
+	switch p.getFocusType() {
+	case "model":
+		return &p.modelInput
+	case "profile":
+		return &p.profileInput
+	}
+	return nil
+}
+
+// updateInputFocus updates which text input has focus
+func (p *CodexOptionsPanel) updateInputFocus() {
+	p.modelInput.Blur()
+	p.profileInput.Blur()
+	if input := p.focusedInput(); input != nil {
+		input.Focus()
+	}
+}
+
+// cycle moves a choice index by delta, wrapping around
+func cycle(idx, delta, n int) int {
+	return (idx + delta + n) % n
+}
+
+// Update handles key events
+func (p *CodexOptionsPanel) Update(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "up", "shift+tab":
+			p.focusIndex = cycle(p.focusIndex, -1, len(codexFocusTypes))
+			p.updateInputFocus()
+			return nil
+
+		case "down", "tab":
+			p.focusIndex = cycle(p.focusIndex, 1, len(codexFocusTypes))
+			p.updateInputFocus()
+			return nil
+
+		case " ", "y":
+			// Text inputs take the key; 'y' also arrives from the command
+			// field as a YOLO shortcut while the panel is unfocused
+			if p.focusedInput() != nil {
+				break
+			}
+			p.handleToggle(1)
+			return nil
+
+		case "left", "right":
+			if p.focusedInput() != nil {
+				break
+			}
+			delta := 1
+			if msg.String() == "left" {
+				delta = -1
+			}
+			p.handleToggle(delta)
+			return nil
+		}
+	}
+
+	if input := p.focusedInput(); input != nil {
+		var cmd tea.Cmd
+		*input, cmd = input.Update(msg)
+		return cmd
+	}
+	return nil
+}
+
+// handleToggle toggles the YOLO checkbox or cycles the focused choice
+func (p *CodexOptionsPanel) handleToggle(delta int) {
+	switch p.getFocusType() {
+	case "sandbox":
+		p.sandbox = cycle(p.sandbox, delta, len(codexSandboxChoices))
+	case "approval":
+		p.approvalPolicy = cycle(p.approvalPolicy, delta, len(codexApprovalChoices))
+	default:
+		// "yolo", or unfocused 'y' shortcut
+		p.yoloMode = !p.yoloMode
+	}
+}
+
+// View renders the options panel
+func (p *CodexOptionsPanel) View() string {
+	activeStyle := lipgloss.NewStyle().Foreground(ColorAccent).Bold(true)
+	headerStyle := lipgloss.NewStyle().Foreground(ColorComment)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorComment)
+	valueStyle := lipgloss.NewStyle().Foreground(ColorCyan)
+
+	var content string
+	content += headerStyle.Render("─ Codex Options ─") + "\n"
+	content += renderCheckboxLine("YOLO mode - bypass approvals and sandbox", p.yoloMode, p.focusIndex == 0)
+
+	inputLine := func(label string, input textinput.Model, idx int) {
+		if p.focusIndex == idx {
+			content += activeStyle.Render("▶ "+label) + input.View() + "\n"
+		} else {
+			content += "  " + label + input.View() + "\n"
+		}
+	}
+	choiceLine := func(label, value string, idx int) {
+		if value == "" {
+			value = "codex default"
+		}
+		if p.focusIndex == idx {
+			content += activeStyle.Render("▶ "+label) + activeStyle.Render(value) + dimStyle.Render("  ←/→") + "\n"
+		} else {
+			content += "  " + label + valueStyle.Render(value) + "\n"
+		}
+	}
+
+	inputLine("Model:    ", p.modelInput, 1)
+	inputLine("Profile:  ", p.profileInput, 2)
+	choiceLine("Sandbox:  ", codexSandboxChoices[p.sandbox], 3)
+	choiceLine("Approval: ", codexApprovalChoices[p.approvalPolicy], 4)
+	if p.yoloMode && (p.sandbox != 0 || p.approvalPolicy != 0) {
+		content += dimStyle.Render("  (YOLO overrides sandbox and approval)") + "\n"
+	}
+	return content
+}
@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCodexOptionsPanel_SetDefaultsRoundTrip(t *testing.T) {
+	p := NewCodexOptionsPanel()
+	yolo := false
+	p.SetDefaults(&session.CodexOptions{
+		YoloMode:       &yolo,
+		Model:          "o3",
+		Profile:        "work",
+		Sandbox:        "read-only",
+		ApprovalPolicy: "never",
+	})
+
+	opts := p.GetOptions()
+	if opts.Model != "o3" || opts.Profile != "work" || opts.Sandbox != "read-only" || opts.ApprovalPolicy != "never" {
+		t.Errorf("GetOptions() = %+v", opts)
+	}
+	if opts.YoloMode == nil || *opts.YoloMode {
+		t.Errorf("YoloMode = %v, want explicit false", opts.YoloMode)
+	}
+}
+
+func TestCodexOptionsPanel_Navigation(t *testing.T) {
+	p := NewCodexOptionsPanel()
+	p.SetDefaults(nil)
+
+	// 'y' from the command field toggles YOLO while unfocused
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if !p.GetYoloMode() {
+		t.Fatal("unfocused 'y' should toggle YOLO")
+	}
+
+	p.Focus()
+	p.Update(tea.KeyMsg{Type: tea.KeyDown})
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("gpt-5 y")})
+	if !p.GetYoloMode() {
+		t.Error("typing 'y' in the model input must not toggle YOLO")
+	}
+
+	p.Update(tea.KeyMsg{Type: tea.KeyDown}) // profile
+	p.Update(tea.KeyMsg{Type: tea.KeyDown}) // sandbox
+	p.Update(tea.KeyMsg{Type: tea.KeyRight})
+	p.Update(tea.KeyMsg{Type: tea.KeyDown}) // approval
+	p.Update(tea.KeyMsg{Type: tea.KeyLeft})
+
+	opts := p.GetOptions()
+	if opts.Model != "gpt-5 y" {
+		t.Errorf("Model = %q", opts.Model)
+	}
+	if opts.Sandbox != "read-only" {
+		t.Errorf("Sandbox = %q, want read-only", opts.Sandbox)
+	}
+	if opts.ApprovalPolicy != "never" {
+		t.Errorf("ApprovalPolicy = %q, want never (left wraps)", opts.ApprovalPolicy)
+	}
+	if view := p.View(); !strings.Contains(view, "Codex Options") || !strings.Contains(view, "YOLO overrides") {
+		t.Errorf("unexpected view:\n%s", view)
+	}
+}
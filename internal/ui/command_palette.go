@@ -0,0 +1,231 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sahilm/fuzzy"
+)
+
+// paletteEntry is one row of the command palette: an action, or an argument
+// for the action being completed.
+type paletteEntry struct {
+	action *Action
+	arg    *PaletteArg
+	label  string
+	key    string // Key binding label, empty for arguments
+	group  string // "Recent", "Frequent" or "" (shown only without a query)
+}
+
+// paletteSource adapts entries for fuzzy matching on label, section and ID
+type paletteSource []paletteEntry
+
+func (s paletteSource) String(i int) string {
+	e := s[i]
+	if e.arg != nil {
+		return e.label
+	}
+	return e.label + " " + strings.ToLower(e.action.Section) + " " + e.action.ID
+}
+
+func (s paletteSource) Len() int { return len(s) }
+
+// CommandPalette fuzzy-searches actions and, for parameterized actions,
+// their arguments.
+type CommandPalette struct {
+	visible   bool
+	width     int
+	height    int
+	input     textinput.Model
+	entries   []paletteEntry
+	filtered  []paletteEntry
+	cursor    int
+	argAction *Action // Action whose argument is being picked, nil when picking an action
+}
+
+// NewCommandPalette creates a new command palette
+func NewCommandPalette() *CommandPalette {
+	input := textinput.New()
+	input.Placeholder = "type a command…"
+	input.Prompt = "> "
+	input.CharLimit = 200
+	return &CommandPalette{input: input}
+}
+
+// Show opens the palette listing entries
+func (p *CommandPalette) Show(entries []paletteEntry) {
+	p.visible = true
+	p.argAction = nil
+	p.setEntries(entries)
+}
+
+// ShowArgs switches the palette to picking an argument for action
+func (p *CommandPalette) ShowArgs(action *Action, args []PaletteArg) {
+	entries := make([]paletteEntry, len(args))
+	for i := range args {
+		entries[i] = paletteEntry{action: action, arg: &args[i], label: args[i].Label}
+	}
+	p.visible = true
+	p.argAction = action
+	p.setEntries(entries)
+}
+
+func (p *CommandPalette) setEntries(entries []paletteEntry) {
+	p.entries = entries
+	p.input.SetValue("")
+	p.input.Focus()
+	p.filter()
+}
+
+// Hide closes the palette
+func (p *CommandPalette) Hide() {
+	p.visible = false
+	p.input.Blur()
+	p.entries = nil
+	p.filtered = nil
+	p.argAction = nil
+}
+
+// IsVisible returns whether the palette is visible
+func (p *CommandPalette) IsVisible() bool {
+	return p.visible
+}
+
+// SetSize sets the palette dimensions for centering
+func (p *CommandPalette) SetSize(w, h int) {
+	p.width = w
+	p.height = h
+}
+
+// Selected returns the entry under the cursor
+func (p *CommandPalette) Selected() *paletteEntry {
+	if p.cursor < 0 || p.cursor >= len(p.filtered) {
+		return nil
+	}
+	return &p.filtered[p.cursor]
+}
+
+// filter applies the query; without one, entries keep the caller's order
+func (p *CommandPalette) filter() {
+	query := strings.TrimSpace(p.input.Value())
+	p.cursor = 0
+	if query == "" {
+		p.filtered = p.entries
+		return
+	}
+	matches := fuzzy.FindFrom(query, paletteSource(p.entries))
+	p.filtered = make([]paletteEntry, 0, len(matches))
+	for _, m := range matches {
+		e := p.entries[m.Index]
+		e.group = ""
+		p.filtered = append(p.filtered, e)
+	}
+}
+
+// Update handles typing and navigation. Enter and Esc are handled by the parent.
+func (p *CommandPalette) Update(msg tea.KeyMsg) (*CommandPalette, tea.Cmd) {
+	switch msg.String() {
+	case "down", "ctrl+n", "ctrl+j":
+		if p.cursor < len(p.filtered)-1 {
+			p.cursor++
+		}
+		return p, nil
+	case "up", "ctrl+p":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+		return p, nil
+	}
+	before := p.input.Value()
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	if p.input.Value() != before {
+		p.filter()
+	}
+	return p, cmd
+}
+
+// paletteMaxRows caps the list height
+const paletteMaxRows = 14
+
+// View renders the palette
+func (p *CommandPalette) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorAccent)
+	groupStyle := lipgloss.NewStyle().Foreground(ColorCyan).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(ColorAccent).Bold(true)
+	normalStyle := lipgloss.NewStyle().Foreground(ColorText)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorTextDim)
+	keyStyle := lipgloss.NewStyle().Foreground(ColorPurple)
+	footerStyle := lipgloss.NewStyle().Foreground(ColorComment).Italic(true)
+
+	dialogWidth := 64
+	if p.width > 0 && p.width < dialogWidth+10 {
+		dialogWidth = max(p.width-10, 30)
+	}
+	innerWidth := dialogWidth - 4
+
+	title := "Command Palette"
+	if p.argAction != nil {
+		title = p.argAction.Title
+	}
+	p.input.Width = innerWidth - 4
+
+	lines := []string{titleStyle.Render(title), p.input.View(), ""}
+
+	rows := paletteMaxRows
+	if p.height > 0 {
+		rows = min(rows, max(p.height-12, 3))
+	}
+	start := 0
+	if p.cursor >= rows {
+		start = p.cursor - rows + 1
+	}
+	end := min(start+rows, len(p.filtered))
+
+	if len(p.filtered) == 0 {
+		lines = append(lines, dimStyle.Render("No matching commands"))
+	}
+	lastGroup := ""
+	for i := start; i < end; i++ {
+		e := p.filtered[i]
+		if e.group != lastGroup && e.group != "" {
+			lines = append(lines, groupStyle.Render(e.group))
+		}
+		lastGroup = e.group
+
+		key := ""
+		if e.key != "" {
+			key = keyStyle.Render(e.key)
+		}
+		labelWidth := innerWidth - 2 - lipgloss.Width(key) - 1
+		label := runewidth.Truncate(e.label, max(labelWidth, 8), "…")
+		style := normalStyle
+		prefix := "  "
+		if i == p.cursor {
+			style = selectedStyle
+			prefix = "> "
+		}
+		gap := max(innerWidth-2-runewidth.StringWidth(label)-lipgloss.Width(key), 1)
+		lines = append(lines, prefix+style.Render(label)+strings.Repeat(" ", gap)+key)
+	}
+	if end < len(p.filtered) {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("  … %d more", len(p.filtered)-end)))
+	}
+
+	footer := "↑↓ select • Enter run • Esc close"
+	if p.argAction != nil {
+		footer = "↑↓ select • Enter choose • Esc back"
+	}
+	lines = append(lines, "", footerStyle.Render(footer))
+
+	box := DialogBoxStyle.Width(dialogWidth).Render(strings.Join(lines, "\n"))
+	return centerInScreen(box, p.width, p.height)
+}
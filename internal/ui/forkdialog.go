@@ -17,7 +17,8 @@ type ForkDialog struct {
 	nameInput     textinput.Model
 	groupInput    textinput.Model
 	optionsPanel  *ClaudeOptionsPanel
-	focusIndex    int // 0=name, 1=group, 2=branch(if worktree), 2/3+=options
+	showOptions   bool // Claude options only apply to Claude forks
+	focusIndex    int  // 0=name, 1=group, 2=branch(if worktree), 2/3+=options
 	width         int
 	height        int
 	projectPath   string
@@ -62,6 +63,7 @@ func (d *ForkDialog) Show(originalName, projectPath, groupPath string) {
 	d.nameInput.SetValue(originalName + " (fork)")
 	d.groupInput.SetValue(groupPath)
 	d.focusIndex = 0
+	d.showOptions = true
 	d.nameInput.Focus()
 	d.groupInput.Blur()
 	d.branchInput.Blur()
@@ -110,11 +112,22 @@ func (d *ForkDialog) GetValuesWithWorktree() (name, group, branch string, worktr
 	return
 }
 
+// SetOptions pre-fills the Claude options, e.g. from the session being forked
+func (d *ForkDialog) SetOptions(opts *session.ClaudeOptions) {
+	d.optionsPanel.SetOptions(opts)
+}
+
 // GetOptions returns the current Claude options
 func (d *ForkDialog) GetOptions() *session.ClaudeOptions {
 	return d.optionsPanel.GetOptions()
 }
 
+// SetShowOptions shows or hides the Claude options panel. Forks of other
+// tools (Gemini, Codex, OpenCode) only take name, group and worktree.
+func (d *ForkDialog) SetShowOptions(show bool) {
+	d.showOptions = show
+}
+
 // SetSize sets the dialog dimensions
 func (d *ForkDialog) SetSize(width, height int) {
 	d.width = width
@@ -183,6 +196,9 @@ func (d *ForkDialog) Update(msg tea.Msg) (*ForkDialog, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "tab", "down":
+			if !d.showOptions && d.focusIndex >= optStart-1 {
+				return d, nil // Last field, no options panel below
+			}
 			if d.focusIndex < optStart {
 				// Move from name/group/branch to next field
 				d.focusIndex++
@@ -375,13 +391,18 @@ func (d *ForkDialog) View() string {
 		errLine = "\n" + errStyle.Render("  ⚠ "+d.validationErr) + "\n"
 	}
 
+	optionsView := ""
+	if d.showOptions {
+		optionsView = d.optionsPanel.View()
+	}
+
 	content := titleStyle.Render("Fork Session") + "\n\n" +
 		nameLabel + "\n" +
 		"  " + d.nameInput.View() + "\n\n" +
 		groupLabel + "\n" +
 		"  " + d.groupInput.View() + "\n" +
 		worktreeSection + "\n" +
-		d.optionsPanel.View() +
+		optionsView +
 		errLine + "\n" +
 		lipgloss.NewStyle().Foreground(ColorComment).
 			Render("Enter create │ Esc cancel │ Tab next │ Space toggle")
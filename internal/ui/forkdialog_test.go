@@ -3,6 +3,8 @@ package ui
 import (
 	"strings"
 	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func TestNewForkDialog(t *testing.T) {
@@ -175,3 +177,31 @@ func TestForkDialog_Show_ClearsError(t *testing.T) {
 		t.Error("Show() should clear validationErr")
 	}
 }
+
+func TestForkDialog_SetShowOptions_HidesClaudeOptions(t *testing.T) {
+	d := NewForkDialog()
+	d.SetSize(80, 40)
+	d.Show("Test", "/path", "group")
+
+	if !strings.Contains(d.View(), "Skip permissions") {
+		t.Fatal("Claude forks should show the options panel")
+	}
+
+	d.SetShowOptions(false)
+	if strings.Contains(d.View(), "Skip permissions") {
+		t.Error("non-Claude forks should hide the Claude options")
+	}
+
+	// Tab stops at the group field instead of entering the hidden panel
+	d.Update(tea.KeyMsg{Type: tea.KeyTab})
+	d.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if d.focusIndex != 1 {
+		t.Errorf("focusIndex = %d, want 1 (group)", d.focusIndex)
+	}
+
+	// Show resets to the Claude layout
+	d.Show("Test", "/path", "group")
+	if !d.showOptions {
+		t.Error("Show() should re-enable the options panel")
+	}
+}
@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// GitPanel shows what a session changed in its repository: branch position,
+// uncommitted files, commits made during the session and a scrollable diff.
+type GitPanel struct {
+	activity *git.Activity
+	err      error
+	width    int
+	height   int
+	scroll   int // First visible diff line
+}
+
+// NewGitPanel creates a new git panel
+func NewGitPanel() *GitPanel {
+	return &GitPanel{}
+}
+
+// SetActivity sets the activity to display. The diff scroll position is kept
+// when the same session is refreshed so updates don't jump the view.
+func (p *GitPanel) SetActivity(a *git.Activity, err error, keepScroll bool) {
+	p.activity = a
+	p.err = err
+	if !keepScroll {
+		p.scroll = 0
+	}
+	p.clampScroll()
+}
+
+// SetSize sets the panel dimensions
+func (p *GitPanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+	p.clampScroll()
+}
+
+// ScrollBy moves the diff view by delta lines
+func (p *GitPanel) ScrollBy(delta int) {
+	p.scroll += delta
+	p.clampScroll()
+}
+
+func (p *GitPanel) diffLines() []string {
+	if p.activity == nil || p.activity.Diff == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(p.activity.Diff, "\n"), "\n")
+}
+
+func (p *GitPanel) clampScroll() {
+	maxScroll := len(p.diffLines()) - 1
+	if p.scroll > maxScroll {
+		p.scroll = maxScroll
+	}
+	if p.scroll < 0 {
+		p.scroll = 0
+	}
+}
+
+// View renders the git panel
+func (p *GitPanel) View() string {
+	dimStyle := lipgloss.NewStyle().Foreground(ColorTextDim).Italic(true)
+	labelStyle := lipgloss.NewStyle().Foreground(ColorText)
+	valueStyle := lipgloss.NewStyle().Foreground(ColorText)
+	branchStyle := lipgloss.NewStyle().Foreground(ColorCyan).Bold(true)
+	addStyle := lipgloss.NewStyle().Foreground(ColorGreen)
+	delStyle := lipgloss.NewStyle().Foreground(ColorRed)
+
+	if p.err != nil {
+		return dimStyle.Render("Git activity unavailable: " + p.err.Error())
+	}
+	if p.activity == nil {
+		return dimStyle.Render("Loading git activity...")
+	}
+	a := p.activity
+	var b strings.Builder
+
+	// Branch and position relative to the default branch
+	b.WriteString(labelStyle.Render("Branch:  "))
+	b.WriteString(branchStyle.Render(a.Branch))
+	if a.Base != "" && a.Base != a.Branch {
+		b.WriteString(valueStyle.Render(fmt.Sprintf("  ↑%d ↓%d vs %s", a.Ahead, a.Behind, a.Base)))
+	}
+	b.WriteString("\n")
+
+	// Uncommitted files
+	const maxFiles = 8
+	b.WriteString(labelStyle.Render(fmt.Sprintf("Changes: %d file(s)", len(a.Files))))
+	b.WriteString("\n")
+	for i, f := range a.Files {
+		if i == maxFiles {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("  … and %d more", len(a.Files)-maxFiles)))
+			b.WriteString("\n")
+			break
+		}
+		counts := dimStyle.Render("bin")
+		if !f.Binary {
+			counts = addStyle.Render(fmt.Sprintf("+%d", f.Added)) + " " + delStyle.Render(fmt.Sprintf("-%d", f.Deleted))
+		}
+		path := runewidth.Truncate(f.Path, max(p.width-16, 10), "...")
+		b.WriteString(fmt.Sprintf("  %s %s %s\n", branchStyle.Render(f.Status), valueStyle.Render(path), counts))
+	}
+
+	// Commits made during the session
+	if len(a.Commits) > 0 {
+		b.WriteString(labelStyle.Render(fmt.Sprintf("Commits: %d this session", len(a.Commits))))
+		b.WriteString("\n")
+		for _, c := range a.Commits {
+			subject := runewidth.Truncate(c.Subject, max(p.width-22, 10), "...")
+			b.WriteString(fmt.Sprintf("  %s %s %s\n", branchStyle.Render(c.Hash), valueStyle.Render(subject), dimStyle.Render(formatRelativeTime(c.When))))
+		}
+	}
+
+	// Scrollable diff fills the remaining height
+	lines := p.diffLines()
+	if len(lines) == 0 {
+		b.WriteString("\n")
+		b.WriteString(dimStyle.Render("No uncommitted changes"))
+		return b.String()
+	}
+	used := strings.Count(b.String(), "\n") + 2
+	visible := max(p.height-used, 3)
+	end := min(p.scroll+visible, len(lines))
+
+	header := fmt.Sprintf("Diff %d-%d/%d  [ ] scroll", p.scroll+1, end, len(lines))
+	if a.DiffTruncated {
+		header += " (truncated)"
+	}
+	b.WriteString("\n")
+	b.WriteString(dimStyle.Render(header))
+	b.WriteString("\n")
+	for i, line := range lines[p.scroll:end] {
+		b.WriteString(renderDiffLine(runewidth.Truncate(strings.ReplaceAll(line, "\t", "    "), max(p.width, 10), "…")))
+		if p.scroll+i < end-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// renderDiffLine colors a unified diff line by its prefix.
+func renderDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "diff --git"), strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		return lipgloss.NewStyle().Foreground(ColorText).Bold(true).Render(line)
+	case strings.HasPrefix(line, "@@"):
+		return lipgloss.NewStyle().Foreground(ColorCyan).Render(line)
+	case strings.HasPrefix(line, "+"):
+		return lipgloss.NewStyle().Foreground(ColorGreen).Render(line)
+	case strings.HasPrefix(line, "-"):
+		return lipgloss.NewStyle().Foreground(ColorRed).Render(line)
+	case strings.HasPrefix(line, "index "), strings.HasPrefix(line, "new file"), strings.HasPrefix(line, "deleted file"):
+		return lipgloss.NewStyle().Foreground(ColorTextDim).Render(line)
+	}
+	return lipgloss.NewStyle().Foreground(ColorText).Render(line)
+}
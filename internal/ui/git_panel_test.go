@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+func TestGitPanel_ViewAndScroll(t *testing.T) {
+	var diff strings.Builder
+	diff.WriteString("diff --git a/app.go b/app.go\n@@ -1,40 +1,40 @@\n")
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&diff, "+line %d\n", i)
+	}
+
+	panel := NewGitPanel()
+	panel.SetSize(80, 20)
+	panel.SetActivity(&git.Activity{
+		Branch: "feature/x",
+		Base:   "main",
+		Ahead:  2,
+		Behind: 1,
+		Files:  []git.FileStat{{Path: "app.go", Status: " M", Added: 40}},
+		Diff:   diff.String(),
+	}, nil, false)
+
+	view := panel.View()
+	for _, want := range []string{"feature/x", "↑2 ↓1 vs main", "app.go", "+40", "Diff 1-", "line 0"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in view:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "line 39") {
+		t.Fatal("expected diff to be clipped to the panel height")
+	}
+
+	panel.ScrollBy(1000)
+	if view := panel.View(); !strings.Contains(view, "line 39") {
+		t.Fatalf("expected scrolled view to reach the end:\n%s", view)
+	}
+
+	// Refreshing the same session keeps the scroll position; a new one resets it
+	panel.SetActivity(panel.activity, nil, true)
+	if panel.scroll == 0 {
+		t.Fatal("expected scroll to be kept on refresh")
+	}
+	panel.SetActivity(panel.activity, nil, false)
+	if panel.scroll != 0 {
+		t.Fatal("expected scroll reset for a different session")
+	}
+}
+
+func TestGitPanel_EmptyAndError(t *testing.T) {
+	panel := NewGitPanel()
+	panel.SetSize(60, 10)
+	panel.SetActivity(&git.Activity{Branch: "main", Base: "main"}, nil, false)
+	if view := panel.View(); !strings.Contains(view, "No uncommitted changes") || strings.Contains(view, "vs main") {
+		t.Fatalf("unexpected clean view:\n%s", view)
+	}
+
+	panel.SetActivity(nil, fmt.Errorf("not a git repository"), false)
+	if view := panel.View(); !strings.Contains(view, "not a git repository") {
+		t.Fatalf("expected error in view:\n%s", view)
+	}
+}
@@ -0,0 +1,247 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+// Grid mode tiles 2-9 sessions and refreshes a tile only when PipeManager
+// reports %output for it. Sessions without a control pipe fall back to a
+// slow periodic capture.
+
+const (
+	gridTickInterval     = 250 * time.Millisecond
+	gridFallbackInterval = 2 * time.Second
+)
+
+// gridTickMsg drives grid refreshes while the grid is visible
+type gridTickMsg struct{}
+
+// gridContentMsg carries captured output for grid tiles, keyed by session ID
+type gridContentMsg struct {
+	contents map[string]string
+}
+
+func gridTick() tea.Cmd {
+	return tea.Tick(gridTickInterval, func(time.Time) tea.Msg { return gridTickMsg{} })
+}
+
+// markGridDirty records output for a tmux session. Called from the
+// PipeManager output callback, so it must not touch the grid view itself.
+func (h *Home) markGridDirty(tmuxName string) {
+	if !h.gridActive.Load() {
+		return
+	}
+	h.gridDirtyMu.Lock()
+	h.gridDirty[tmuxName] = true
+	h.gridDirtyMu.Unlock()
+}
+
+// openGridView tiles the multi-selection, or the saved layout when nothing
+// is selected.
+func (h *Home) openGridView() (tea.Model, tea.Cmd) {
+	insts := h.selectedSessions()
+	columns := 0
+	if len(insts) == 0 {
+		layout := h.loadGridLayout()
+		columns = layout.Columns
+		for _, id := range layout.SessionIDs {
+			if inst := h.getInstanceByID(id); inst != nil {
+				insts = append(insts, inst)
+			}
+		}
+	}
+	if len(insts) < gridMinTiles || len(insts) > gridMaxTiles {
+		h.setError(fmt.Errorf("grid view needs %d-%d sessions (mark them with Space), got %d", gridMinTiles, gridMaxTiles, len(insts)))
+		return h, nil
+	}
+
+	h.clearBulkSelection()
+	h.gridView.SetSize(h.width, h.height)
+	h.gridView.Show(insts, columns)
+	h.saveGridLayout()
+	h.gridActive.Store(true)
+	h.lastGridCapture = time.Time{}
+
+	// Make sure every tile has a control pipe so output events arrive
+	if pm := tmux.GetPipeManager(); pm != nil {
+		var names []string
+		for _, inst := range insts {
+			if ts := inst.GetTmuxSession(); ts != nil {
+				names = append(names, ts.Name)
+			}
+		}
+		go func() {
+			for _, name := range names {
+				if err := pm.Connect(name); err != nil {
+					pipeUILog.Debug("grid_pipe_connect_failed", slog.String("session", name), slog.String("error", err.Error()))
+				}
+			}
+		}()
+	}
+
+	return h, tea.Batch(h.captureGridTiles(insts), gridTick())
+}
+
+// closeGridView leaves grid mode
+func (h *Home) closeGridView() {
+	h.gridActive.Store(false)
+	h.gridView.Hide()
+	h.gridDirtyMu.Lock()
+	h.gridDirty = make(map[string]bool)
+	h.gridDirtyMu.Unlock()
+}
+
+// handleGridTick captures tiles that produced output since the last tick,
+// plus tiles without a live pipe every gridFallbackInterval.
+func (h *Home) handleGridTick() tea.Cmd {
+	if !h.gridView.IsVisible() {
+		return nil
+	}
+
+	h.gridDirtyMu.Lock()
+	dirty := h.gridDirty
+	h.gridDirty = make(map[string]bool)
+	h.gridDirtyMu.Unlock()
+
+	// Drop tiles whose sessions were deleted meanwhile
+	for _, inst := range h.gridView.Sessions() {
+		if h.getInstanceByID(inst.ID) == nil {
+			h.gridView.RemoveSession(inst.ID)
+		}
+	}
+	if h.gridView.TileCount() == 0 {
+		h.closeGridView()
+		return nil
+	}
+
+	fallback := time.Since(h.lastGridCapture) >= gridFallbackInterval
+	pm := tmux.GetPipeManager()
+	var stale []*session.Instance
+	for _, inst := range h.gridView.Sessions() {
+		ts := inst.GetTmuxSession()
+		if ts == nil {
+			continue
+		}
+		switch {
+		case dirty[ts.Name], !h.gridView.HasContent(inst.ID):
+			stale = append(stale, inst)
+		case fallback && (pm == nil || !pm.IsConnected(ts.Name)):
+			stale = append(stale, inst)
+		}
+	}
+	if fallback {
+		h.lastGridCapture = time.Now()
+	}
+	if len(stale) == 0 {
+		return gridTick()
+	}
+	return tea.Batch(h.captureGridTiles(stale), gridTick())
+}
+
+// captureGridTiles captures the visible pane of each session in the background
+func (h *Home) captureGridTiles(insts []*session.Instance) tea.Cmd {
+	return func() tea.Msg {
+		contents := make(map[string]string, len(insts))
+		for _, inst := range insts {
+			ts := inst.GetTmuxSession()
+			if ts == nil || !ts.Exists() {
+				continue
+			}
+			if content, err := ts.CapturePane(); err == nil {
+				contents[inst.ID] = content
+			}
+		}
+		return gridContentMsg{contents: contents}
+	}
+}
+
+// handleGridKey handles keys while the grid is visible
+func (h *Home) handleGridKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	g := h.gridView
+	switch msg.String() {
+	case "esc", "q", "T":
+		if g.IsZoomed() && msg.String() == "esc" {
+			g.ToggleZoom()
+			return h, nil
+		}
+		h.closeGridView()
+		return h, nil
+	case "ctrl+c":
+		return h.tryQuit()
+	case "left", "h":
+		g.MoveFocus(-1, 0)
+	case "right", "l":
+		g.MoveFocus(1, 0)
+	case "up", "k":
+		g.MoveFocus(0, -1)
+	case "down", "j":
+		g.MoveFocus(0, 1)
+	case "tab":
+		g.focus = (g.focus + 1) % g.TileCount()
+	case "z":
+		g.ToggleZoom()
+	case "c":
+		g.CycleColumns()
+		h.saveGridLayout()
+	case "x", "d":
+		// Keep at least gridMinTiles; Esc closes the grid instead
+		if g.TileCount() <= gridMinTiles {
+			return h, nil
+		}
+		g.RemoveFocused()
+		h.saveGridLayout()
+	case "enter":
+		if inst := g.Focused(); inst != nil && inst.Exists() {
+			h.isAttaching.Store(true)
+			return h, h.attachSession(inst)
+		}
+	}
+	return h, nil
+}
+
+// saveGridLayout persists the grid layout in the profile's state database
+func (h *Home) saveGridLayout() {
+	if h.storage == nil {
+		return
+	}
+	db := h.storage.GetDB()
+	if db == nil {
+		return
+	}
+	data, err := json.Marshal(h.gridView.Layout())
+	if err != nil {
+		uiLog.Warn("save_grid_layout_marshal_failed", slog.String("error", err.Error()))
+		return
+	}
+	if err := db.SetMeta("grid_layout", string(data)); err != nil {
+		uiLog.Warn("save_grid_layout_failed", slog.String("error", err.Error()))
+	}
+}
+
+// loadGridLayout reads the saved grid layout, or an empty layout
+func (h *Home) loadGridLayout() GridLayout {
+	var layout GridLayout
+	if h.storage == nil {
+		return layout
+	}
+	db := h.storage.GetDB()
+	if db == nil {
+		return layout
+	}
+	val, err := db.GetMeta("grid_layout")
+	if err != nil || val == "" {
+		return layout
+	}
+	if err := json.Unmarshal([]byte(val), &layout); err != nil {
+		uiLog.Warn("load_grid_layout_unmarshal_failed", slog.String("error", err.Error()))
+	}
+	return layout
+}
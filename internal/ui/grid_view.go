@@ -0,0 +1,332 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+const (
+	gridMinTiles = 2
+	gridMaxTiles = 9
+)
+
+// GridLayout is the persisted grid: which sessions are tiled and how many
+// columns they use (0 = automatic).
+type GridLayout struct {
+	SessionIDs []string `json:"session_ids"`
+	Columns    int      `json:"columns,omitempty"`
+}
+
+// gridTile is one session in the grid with its last captured output
+type gridTile struct {
+	inst    *session.Instance
+	content string
+}
+
+// GridView live-renders the tail of several sessions side by side. Content
+// is pushed in by Home (SetContent) whenever a session produces output.
+type GridView struct {
+	visible bool
+	width   int
+	height  int
+	tiles   []*gridTile
+	columns int // 0 = automatic
+	focus   int
+	zoomed  bool
+}
+
+// NewGridView creates a new grid view
+func NewGridView() *GridView {
+	return &GridView{}
+}
+
+// Show opens the grid for insts with the given column setting
+func (g *GridView) Show(insts []*session.Instance, columns int) {
+	g.tiles = make([]*gridTile, 0, len(insts))
+	for _, inst := range insts {
+		g.tiles = append(g.tiles, &gridTile{inst: inst})
+	}
+	g.columns = columns
+	if g.columns < 0 || g.columns > len(g.tiles) {
+		g.columns = 0
+	}
+	g.focus = 0
+	g.zoomed = false
+	g.visible = true
+}
+
+// Hide closes the grid
+func (g *GridView) Hide() {
+	g.visible = false
+	g.tiles = nil
+}
+
+// IsVisible returns whether the grid is shown
+func (g *GridView) IsVisible() bool {
+	return g.visible
+}
+
+// SetSize sets the grid dimensions
+func (g *GridView) SetSize(width, height int) {
+	g.width = width
+	g.height = height
+}
+
+// Layout returns the current layout for persisting
+func (g *GridView) Layout() GridLayout {
+	ids := make([]string, len(g.tiles))
+	for i, t := range g.tiles {
+		ids[i] = t.inst.ID
+	}
+	return GridLayout{SessionIDs: ids, Columns: g.columns}
+}
+
+// Sessions returns the tiled sessions in order
+func (g *GridView) Sessions() []*session.Instance {
+	insts := make([]*session.Instance, len(g.tiles))
+	for i, t := range g.tiles {
+		insts[i] = t.inst
+	}
+	return insts
+}
+
+// Focused returns the session of the focused tile
+func (g *GridView) Focused() *session.Instance {
+	if g.focus < 0 || g.focus >= len(g.tiles) {
+		return nil
+	}
+	return g.tiles[g.focus].inst
+}
+
+// SetContent stores captured output for a session's tile
+func (g *GridView) SetContent(sessionID, content string) {
+	for _, t := range g.tiles {
+		if t.inst.ID == sessionID {
+			t.content = content
+			return
+		}
+	}
+}
+
+// HasContent reports whether the session's tile has been captured at least once
+func (g *GridView) HasContent(sessionID string) bool {
+	for _, t := range g.tiles {
+		if t.inst.ID == sessionID {
+			return t.content != ""
+		}
+	}
+	return false
+}
+
+// Grid returns the number of columns and rows in use
+func (g *GridView) Grid() (cols, rows int) {
+	n := len(g.tiles)
+	if n == 0 {
+		return 0, 0
+	}
+	cols = g.columns
+	if cols <= 0 {
+		// Automatic: as square as possible, wider than tall
+		cols = int(math.Ceil(math.Sqrt(float64(n))))
+	}
+	if cols > n {
+		cols = n
+	}
+	rows = (n + cols - 1) / cols
+	return cols, rows
+}
+
+// MoveFocus moves the focused tile by dx columns and dy rows
+func (g *GridView) MoveFocus(dx, dy int) {
+	cols, _ := g.Grid()
+	if cols == 0 || g.zoomed {
+		return
+	}
+	col := g.focus%cols + dx
+	row := g.focus/cols + dy
+	if col < 0 || col >= cols || row < 0 {
+		return
+	}
+	if idx := row*cols + col; idx < len(g.tiles) {
+		g.focus = idx
+	}
+}
+
+// ToggleZoom shows only the focused tile, or returns to the grid
+func (g *GridView) ToggleZoom() {
+	g.zoomed = !g.zoomed
+}
+
+// IsZoomed reports whether a single tile fills the grid
+func (g *GridView) IsZoomed() bool {
+	return g.zoomed
+}
+
+// CycleColumns steps through automatic, 1, 2, ... n columns
+func (g *GridView) CycleColumns() {
+	g.columns++
+	if g.columns > len(g.tiles) {
+		g.columns = 0
+	}
+}
+
+// RemoveFocused drops the focused tile from the grid
+func (g *GridView) RemoveFocused() {
+	if g.focus < 0 || g.focus >= len(g.tiles) {
+		return
+	}
+	g.tiles = append(g.tiles[:g.focus], g.tiles[g.focus+1:]...)
+	if g.focus >= len(g.tiles) {
+		g.focus = len(g.tiles) - 1
+	}
+	if g.columns > len(g.tiles) {
+		g.columns = 0
+	}
+	g.zoomed = false
+}
+
+// RemoveSession drops a session's tile (e.g. after it was deleted)
+func (g *GridView) RemoveSession(sessionID string) {
+	for i, t := range g.tiles {
+		if t.inst.ID == sessionID {
+			g.focus = i
+			g.RemoveFocused()
+			return
+		}
+	}
+}
+
+// TileCount returns the number of tiles
+func (g *GridView) TileCount() int {
+	return len(g.tiles)
+}
+
+// gridStatusColor is the tile border color for a session status
+func gridStatusColor(status session.Status) lipgloss.TerminalColor {
+	switch status {
+	case session.StatusRunning:
+		return ColorGreen
+	case session.StatusWaiting:
+		return ColorYellow
+	case session.StatusIdle:
+		return ColorTextDim
+	case session.StatusStarting:
+		return ColorAccent
+	default:
+		return ColorRed
+	}
+}
+
+// tailLines returns the last n non-trailing-blank lines of content, cut to width
+func tailLines(content string, n, width int) []string {
+	if n <= 0 {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(content, "\n \t"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = stripControlChars(tmux.StripANSI(line))
+		line = strings.ReplaceAll(line, "\t", "    ")
+		if runewidth.StringWidth(line) > width {
+			line = runewidth.Truncate(line, width, "")
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// View renders the grid
+func (g *GridView) View() string {
+	if !g.visible || g.width == 0 || g.height == 0 {
+		return ""
+	}
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorAccent)
+	hintStyle := lipgloss.NewStyle().Foreground(ColorComment)
+	cols, rows := g.Grid()
+	layout := "auto"
+	if g.columns > 0 {
+		layout = fmt.Sprintf("%d cols", g.columns)
+	}
+	header := headerStyle.Render(fmt.Sprintf(" Grid: %d sessions (%s)", len(g.tiles), layout)) +
+		hintStyle.Render("  ←↓↑→ focus • Enter attach • z zoom • c columns • x remove • Esc close")
+	header = lipgloss.NewStyle().MaxWidth(g.width).Render(header)
+
+	bodyHeight := g.height - 1
+	if g.zoomed {
+		if t := g.tiles[g.focus]; t != nil {
+			return header + "\n" + g.renderTile(t, true, g.width, bodyHeight)
+		}
+	}
+
+	tileWidth := g.width / cols
+	tileHeight := bodyHeight / rows
+	var rowViews []string
+	for r := 0; r < rows; r++ {
+		var rowTiles []string
+		for c := 0; c < cols; c++ {
+			idx := r*cols + c
+			if idx >= len(g.tiles) {
+				break
+			}
+			w := tileWidth
+			if c == cols-1 {
+				w = g.width - tileWidth*(cols-1) // Last column takes the remainder
+			}
+			rowTiles = append(rowTiles, g.renderTile(g.tiles[idx], idx == g.focus, w, tileHeight))
+		}
+		rowViews = append(rowViews, lipgloss.JoinHorizontal(lipgloss.Top, rowTiles...))
+	}
+	return header + "\n" + lipgloss.JoinVertical(lipgloss.Left, rowViews...)
+}
+
+// renderTile draws one session box of outer size width x height
+func (g *GridView) renderTile(t *gridTile, focused bool, width, height int) string {
+	innerWidth := max(width-2, 1)
+	innerHeight := max(height-2, 1)
+	status := t.inst.GetStatusThreadSafe()
+
+	border := lipgloss.RoundedBorder()
+	if focused {
+		border = lipgloss.ThickBorder()
+	}
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorText)
+	if focused {
+		titleStyle = titleStyle.Foreground(ColorAccent)
+	}
+	title := statusIndicator(status) + " " + titleStyle.Render(runewidth.Truncate(t.inst.Title, max(innerWidth-12, 4), "…")) +
+		" " + GetToolStyle(t.inst.Tool).Render(t.inst.Tool)
+
+	lines := []string{lipgloss.NewStyle().MaxWidth(innerWidth).Render(title)}
+	body := tailLines(t.content, innerHeight-1, innerWidth)
+	if len(body) == 0 || (len(body) == 1 && body[0] == "") {
+		msg := "waiting for output…"
+		if !t.inst.Exists() {
+			msg = "not running"
+		}
+		body = []string{lipgloss.NewStyle().Foreground(ColorTextDim).Italic(true).Render(msg)}
+	}
+	// Bottom-align output like a terminal
+	for pad := innerHeight - 1 - len(body); pad > 0; pad-- {
+		lines = append(lines, "")
+	}
+	lines = append(lines, body...)
+
+	return lipgloss.NewStyle().
+		Border(border).
+		BorderForeground(gridStatusColor(status)).
+		Width(innerWidth).
+		Height(innerHeight).
+		MaxHeight(height).
+		Render(strings.Join(lines, "\n"))
+}
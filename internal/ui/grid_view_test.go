@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+func gridInstances(n int) []*session.Instance {
+	insts := make([]*session.Instance, n)
+	for i := range insts {
+		insts[i] = session.NewInstance(string(rune('a'+i)), "/tmp")
+	}
+	return insts
+}
+
+func TestGridViewAutoLayout(t *testing.T) {
+	tests := []struct{ n, cols, rows int }{
+		{2, 2, 1},
+		{3, 2, 2},
+		{4, 2, 2},
+		{5, 3, 2},
+		{6, 3, 2},
+		{7, 3, 3},
+		{9, 3, 3},
+	}
+	g := NewGridView()
+	for _, tt := range tests {
+		g.Show(gridInstances(tt.n), 0)
+		if cols, rows := g.Grid(); cols != tt.cols || rows != tt.rows {
+			t.Errorf("n=%d: grid = %dx%d, want %dx%d", tt.n, cols, rows, tt.cols, tt.rows)
+		}
+	}
+
+	// Explicit columns, cycling back to automatic after n
+	g.Show(gridInstances(3), 1)
+	if cols, rows := g.Grid(); cols != 1 || rows != 3 {
+		t.Errorf("1 column: grid = %dx%d", cols, rows)
+	}
+	g.CycleColumns()
+	g.CycleColumns()
+	g.CycleColumns()
+	if g.Layout().Columns != 0 {
+		t.Errorf("columns after cycling past n = %d, want 0 (auto)", g.Layout().Columns)
+	}
+}
+
+func TestGridViewFocusAndRemove(t *testing.T) {
+	g := NewGridView()
+	insts := gridInstances(5) // 3x2, last row has two tiles
+	g.Show(insts, 0)
+
+	g.MoveFocus(1, 0)
+	g.MoveFocus(0, 1)
+	if g.Focused() != insts[4] {
+		t.Fatalf("focus = %s, want e", g.Focused().Title)
+	}
+	// No tile below the right-most column of the first row: focus stays
+	g.MoveFocus(1, -1)
+	g.MoveFocus(0, 1)
+	if g.Focused() != insts[2] {
+		t.Fatalf("focus = %s, want c", g.Focused().Title)
+	}
+
+	g.RemoveFocused()
+	if g.TileCount() != 4 || g.Focused() != insts[3] {
+		t.Fatalf("after remove: %d tiles, focus %s", g.TileCount(), g.Focused().Title)
+	}
+	if ids := g.Layout().SessionIDs; len(ids) != 4 || ids[2] != insts[3].ID {
+		t.Errorf("layout ids = %v", ids)
+	}
+}
+
+func TestGridViewRendersTail(t *testing.T) {
+	g := NewGridView()
+	insts := gridInstances(2)
+	g.Show(insts, 0)
+	g.SetSize(80, 12)
+
+	var lines []string
+	for i := 0; i < 50; i++ {
+		lines = append(lines, "line-"+strings.Repeat("x", i%3)+string(rune('A'+i%26)))
+	}
+	g.SetContent(insts[0].ID, strings.Join(lines, "\n")+"\n\n")
+
+	view := g.View()
+	if !strings.Contains(view, lines[49]) {
+		t.Error("grid should show the last line of output")
+	}
+	if strings.Contains(view, lines[0]) {
+		t.Error("grid should not show old output that doesn't fit")
+	}
+	if got := len(strings.Split(view, "\n")); got > 12 {
+		t.Errorf("view is %d lines, taller than the screen", got)
+	}
+}
+
+func TestTailLinesTruncates(t *testing.T) {
+	got := tailLines("one\ntwo\nthree-is-long\n\n", 2, 5)
+	if len(got) != 2 || got[0] != "two" || got[1] != "three" {
+		t.Errorf("tailLines = %q", got)
+	}
+}
+
+func TestHomeGridNeedsSelection(t *testing.T) {
+	home, insts := newBulkTestHome(t)
+
+	home.Update(bulkKey("T"))
+	if home.gridView.IsVisible() {
+		t.Fatal("grid should not open without a selection or saved layout")
+	}
+
+	home.bulkSelected[insts[0].ID] = true
+	home.bulkSelected[insts[2].ID] = true
+	home.Update(bulkKey("T"))
+	if !home.gridView.IsVisible() || home.gridView.TileCount() != 2 {
+		t.Fatal("T with two marked sessions should open a two-tile grid")
+	}
+	if home.hasBulkSelection() {
+		t.Error("opening the grid should consume the selection")
+	}
+
+	home.initialLoading = false
+	// Output arrives for a tile through the content message
+	home.Update(gridContentMsg{contents: map[string]string{insts[2].ID: "hello from c\n"}})
+	if !strings.Contains(home.View(), "hello from c") {
+		t.Error("grid tile should render captured output")
+	}
+
+	home.Update(bulkKey("esc"))
+	if home.gridView.IsVisible() || home.gridActive.Load() {
+		t.Error("esc should close the grid")
+	}
+}
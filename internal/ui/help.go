@@ -12,7 +12,8 @@ type HelpOverlay struct {
 	visible      bool
 	width        int
 	height       int
-	scrollOffset int // Current scroll position for small screens
+	scrollOffset int     // Current scroll position for small screens
+	keyMap       *KeyMap // Effective key bindings; nil shows the defaults
 }
 
 // NewHelpOverlay creates a new help overlay
@@ -31,6 +32,11 @@ func (h *HelpOverlay) Hide() {
 	h.visible = false
 }
 
+// SetKeyMap sets the bindings shown, so [keys] overrides appear in help
+func (h *HelpOverlay) SetKeyMap(k *KeyMap) {
+	h.keyMap = k
+}
+
 // IsVisible returns whether the help overlay is visible
 func (h *HelpOverlay) IsVisible() bool {
 	return h.visible
@@ -82,89 +88,42 @@ func (h *HelpOverlay) Update(msg tea.Msg) (*HelpOverlay, tea.Cmd) {
 	return h, nil
 }
 
+// helpSection is one titled block of the help overlay
+type helpSection struct {
+	title string
+	items [][2]string // [key, description]
+}
+
+// sections builds the help from the action registry, in section order
+func (h *HelpOverlay) sections() []helpSection {
+	keyMap := h.keyMap
+	if keyMap == nil {
+		keyMap = NewKeyMap(defaultActions(), nil)
+	}
+	bySection := make(map[string][][2]string)
+	for _, a := range keyMap.Actions() {
+		label := keyMap.Label(a)
+		if label == "" {
+			continue // Palette-only, or unbound by an override
+		}
+		bySection[a.Section] = append(bySection[a.Section], [2]string{label, a.Title})
+	}
+	var sections []helpSection
+	for _, title := range actionSections {
+		if items := bySection[title]; len(items) > 0 {
+			sections = append(sections, helpSection{title: title, items: items})
+		}
+	}
+	return sections
+}
+
 // View renders the help overlay
 func (h *HelpOverlay) View() string {
 	if !h.visible {
 		return ""
 	}
 
-	// Define help sections
-	sections := []struct {
-		title string
-		items [][2]string // [key, description]
-	}{
-		{
-			title: "NAVIGATION",
-			items: [][2]string{
-				{"j / Down", "Move down"},
-				{"k / Up", "Move up"},
-				{"Ctrl+u/d", "Half page up/down"},
-				{"Ctrl+f/b", "Full page up/down"},
-				{"gg / G", "Jump to top/bottom"},
-				{"h / Left", "Collapse / parent"},
-				{"l / Right", "Expand / toggle"},
-				{"1-9", "Jump to group"},
-				{"Enter", "Attach / toggle"},
-			},
-		},
-		{
-			title: "SESSIONS",
-			items: [][2]string{
-				{"n", "New session"},
-				{"N", "Quick create (auto name, smart defaults)"},
-				{"r", "Rename session"},
-				{"Shift+R", "Restart session"},
-				{"d", "Delete session"},
-				{"Ctrl+Z", "Undo delete"},
-				{"M", "Move to group"},
-				{"m", "MCP Manager (Claude/Gemini)"},
-				{"s", "Skills Manager (Claude)"},
-				{"v", "Toggle preview mode (output/stats/both)"},
-				{"u", "Mark unread"},
-				{"K / J", "Reorder up/down"},
-				{"f", "Quick fork (Claude only)"},
-				{"F", "Fork with options (Claude only)"},
-				{"c", "Copy output to clipboard"},
-				{"x", "Send output to session"},
-			},
-		},
-		{
-			title: "WORKTREES",
-			items: [][2]string{
-				{"W", "Finish worktree (merge + cleanup)"},
-				{"n → w", "Create session in worktree"},
-				{"F → w", "Fork session into worktree"},
-			},
-		},
-		{
-			title: "GROUPS",
-			items: [][2]string{
-				{"g", "New group"},
-				{"r", "Rename group"},
-				{"Tab", "Toggle expand"},
-			},
-		},
-		{
-			title: "SEARCH & FILTER",
-			items: [][2]string{
-				{"/", "Open search"},
-				{"/waiting", "Filter waiting"},
-				{"/running", "Filter running"},
-				{"/idle", "Filter idle"},
-			},
-		},
-		{
-			title: "OTHER",
-			items: [][2]string{
-				{"S", "Settings"},
-				{"Ctrl+R", "Reload from disk"},
-				{"i", "Import tmux sessions"},
-				{"Ctrl+Q", "Detach from session"},
-				{"q", "Quit"},
-				{"?", "This help"},
-			},
-		},
-	}
+	sections := h.sections()
 
 	// Styles
 	titleStyle := lipgloss.NewStyle().
@@ -3,11 +3,11 @@ package ui
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -22,6 +22,7 @@ import (
 	"github.com/mattn/go-runewidth"
 
 	"github.com/asheshgoplani/agent-deck/internal/clipboard"
+	"github.com/asheshgoplani/agent-deck/internal/forge"
 	"github.com/asheshgoplani/agent-deck/internal/git"
 	"github.com/asheshgoplani/agent-deck/internal/logging"
 	"github.com/asheshgoplani/agent-deck/internal/session"
@@ -108,8 +109,12 @@ const (
 	PreviewModeBoth      PreviewMode = iota // Show both analytics and output (default)
 	PreviewModeOutput                       // Show output only (content preview)
 	PreviewModeAnalytics                    // Show analytics only
+	PreviewModeGit                          // Show git activity (branch, changes, diff)
 )
 
+// previewModeCount is the number of preview modes the v key cycles through
+const previewModeCount = 4
+
 // Responsive breakpoints for empty state content tiers
 // These define when to show full/compact/minimal content
 const (
@@ -155,18 +160,38 @@ type Home struct {
 	setupWizard          *SetupWizard          // For first-run setup
 	settingsPanel        *SettingsPanel        // For editing settings
 	analyticsPanel       *AnalyticsPanel       // For displaying session analytics
+	gitPanel             *GitPanel             // For displaying git activity (PreviewModeGit)
+	gitPanelSessionID    string                // Session the git panel was last loaded for
+	gitPanelStatus       session.Status        // Session status at the last git refresh
+	gitPanelFetching     bool                  // Git activity fetch in flight
 	geminiModelDialog    *GeminiModelDialog    // For selecting Gemini model
 	sessionPickerDialog  *SessionPickerDialog  // For sending output to another session
 	worktreeFinishDialog *WorktreeFinishDialog // For finishing worktree sessions (merge + cleanup)
+	bulkDialog           *BulkDialog           // For bulk actions on the multi-selection
+	commandPalette       *CommandPalette       // Fuzzy search over every action (Ctrl+K)
+	gridView             *GridView             // Tiled live view of several sessions
+
+	// Grid refresh (see grid.go)
+	gridActive      atomic.Bool     // Grid visible; read by the PipeManager output callback
+	gridDirtyMu     sync.Mutex      // Protects gridDirty
+	gridDirty       map[string]bool // tmux session names with output since the last grid tick
+	lastGridCapture time.Time       // Last fallback capture for tiles without a control pipe
+
+	// Actions and key bindings (see actions.go, palette.go)
+	keyMap         *KeyMap               // Default keys with [keys] overrides from config.toml
+	paletteHistory map[string]paletteUse // Command palette usage by action ID, loaded lazily
+
+	// Multi-select (see bulk.go)
+	bulkSelected map[string]bool // Marked session IDs
+	visualAnchor string          // Item where the visual range started (see visualItemKey, "" = off)
+	visualRange  map[string]bool // Session IDs in the visual range, computed once per render
 
 	// Analytics cache (async fetching with TTL)
-	currentAnalytics       *session.SessionAnalytics                  // Current analytics for selected session (Claude)
-	currentGeminiAnalytics *session.GeminiSessionAnalytics            // Current analytics for selected session (Gemini)
-	analyticsSessionID     string                                     // Session ID for current analytics
-	analyticsFetchingID    string                                     // ID currently being fetched (prevents duplicates)
-	analyticsCache         map[string]*session.SessionAnalytics       // TTL cache: sessionID -> analytics (Claude)
-	geminiAnalyticsCache   map[string]*session.GeminiSessionAnalytics // TTL cache: sessionID -> analytics (Gemini)
-	analyticsCacheTime     map[string]time.Time                       // TTL cache: sessionID -> cache timestamp
+	currentAnalytics    *session.AgentAnalytics            // Current analytics for selected session
+	analyticsSessionID  string                             // Session ID for current analytics
+	analyticsFetchingID string                             // ID currently being fetched (prevents duplicates)
+	analyticsCache      map[string]*session.AgentAnalytics // TTL cache: sessionID -> analytics
+	analyticsCacheTime  map[string]time.Time               // TTL cache: sessionID -> cache timestamp
 
 	// State
 	cursor         int            // Selected item index in flatItems
@@ -218,6 +243,23 @@ type Home struct {
 	worktreeDirtyCacheTs map[string]time.Time // sessionID -> cache timestamp
 	worktreeDirtyMu      sync.Mutex           // Protects dirty cache maps
 
+	// Conflict radar: sibling worktree sessions editing the same files
+	worktreeConflicts      []session.WorktreeConflict
+	worktreeConflictCounts map[string]int  // sessionID -> number of overlapping sessions
+	notifiedConflicts      map[string]bool // WorktreeConflict.Key() already notified
+	lastConflictScan       time.Time
+	conflictScanInFlight   bool
+
+	// Pull request state from `agent-deck worktree pr`: records are reloaded
+	// every prRecordsInterval, each PR refreshed from its forge lazily (60s TTL)
+	prRecords         map[string]forge.Record // sessionID -> last known PR state
+	prRefreshTs       map[string]time.Time    // sessionID -> last forge refresh
+	lastPRRecordsLoad time.Time
+
+	// Message queue depths from statedb (refreshed every queueDepthInterval)
+	queueDepths         map[string]int // sessionID -> queued messages
+	lastQueueDepthCheck time.Time
+
 	// Memory management: periodic cache pruning
 	lastCachePrune time.Time
 
@@ -231,6 +273,7 @@ type Home struct {
 	// Optional in-memory web menu data sink for web mode.
 	webMenuData   *web.MemoryMenuData
 	webMenuDataMu sync.RWMutex
+	shareViewers  *web.ShareViewers // live share-link viewers (web mode only)
 
 	// System theme watcher (active when theme="system"; nil otherwise)
 	themeWatcher *ThemeWatcher
@@ -365,12 +408,14 @@ type loadSessionsMsg struct {
 type sessionCreatedMsg struct {
 	instance *session.Instance
 	err      error
+	warning  error // non-fatal problem to surface once the session is added
 }
 
 type sessionForkedMsg struct {
 	instance *session.Instance
 	sourceID string // ID of the source session that was forked (for cleanup)
 	err      error
+	warning  error // non-fatal problem to surface once the session is added
 }
 
 type refreshMsg struct{}
@@ -409,10 +454,9 @@ type previewDebounceMsg struct {
 
 // analyticsFetchedMsg is sent when async analytics parsing is complete
 type analyticsFetchedMsg struct {
-	sessionID       string
-	analytics       *session.SessionAnalytics
-	geminiAnalytics *session.GeminiSessionAnalytics
-	err             error
+	sessionID string
+	analytics *session.AgentAnalytics
+	err       error
 }
 
 // MaintenanceCompleteMsg is the exported type for sending from main.go via p.Send()
@@ -461,6 +505,38 @@ type worktreeFinishResultMsg struct {
 	sessionTitle string
 	targetBranch string
 	merged       bool
+	conflict     *git.MergeConflictError // Set when the merge pre-flight found conflicts
+	err          error
+}
+
+// gitActivityMsg carries git activity for the preview pane's git panel
+type gitActivityMsg struct {
+	sessionID string
+	status    session.Status
+	activity  *git.Activity
+	err       error
+}
+
+// worktreeConflictsMsg carries the result of a conflict radar scan
+type worktreeConflictsMsg struct {
+	conflicts []session.WorktreeConflict
+	notified  []string // Conflict keys notified during this scan
+}
+
+// pullRequestsMsg carries cached PR records, after optionally refreshing one from its forge
+type pullRequestsMsg struct {
+	records map[string]forge.Record
+}
+
+// queueDepthsMsg carries per-session message queue depths
+type queueDepthsMsg struct {
+	depths map[string]int
+}
+
+// worktreeHandoffResultMsg is sent when a merge conflict was handed back to its session
+type worktreeHandoffResultMsg struct {
+	sessionTitle string
+	fileCount    int
 	err          error
 }
 
@@ -526,9 +602,15 @@ func NewHomeWithProfileAndMode(profile string) *Home {
 		setupWizard:          NewSetupWizard(),
 		settingsPanel:        NewSettingsPanel(),
 		analyticsPanel:       NewAnalyticsPanel(),
+		gitPanel:             NewGitPanel(),
 		geminiModelDialog:    NewGeminiModelDialog(),
 		sessionPickerDialog:  NewSessionPickerDialog(),
 		worktreeFinishDialog: NewWorktreeFinishDialog(),
+		bulkDialog:           NewBulkDialog(),
+		commandPalette:       NewCommandPalette(),
+		gridView:             NewGridView(),
+		gridDirty:            make(map[string]bool),
+		bulkSelected:         make(map[string]bool),
 		cursor:               0,
 		initialLoading:       true, // Show splash until sessions load
 		ctx:                  ctx,
@@ -539,8 +621,7 @@ func NewHomeWithProfileAndMode(profile string) *Home {
 		flatItems:            []session.Item{},
 		previewCache:         make(map[string]string),
 		previewCacheTime:     make(map[string]time.Time),
-		analyticsCache:       make(map[string]*session.SessionAnalytics),
-		geminiAnalyticsCache: make(map[string]*session.GeminiSessionAnalytics),
+		analyticsCache:       make(map[string]*session.AgentAnalytics),
 		analyticsCacheTime:   make(map[string]time.Time),
 		launchingSessions:    make(map[string]time.Time),
 		resumingSessions:     make(map[string]time.Time),
@@ -549,6 +630,9 @@ func NewHomeWithProfileAndMode(profile string) *Home {
 		lastLogActivity:      make(map[string]time.Time),
 		worktreeDirtyCache:   make(map[string]bool),
 		worktreeDirtyCacheTs: make(map[string]time.Time),
+		prRecords:            make(map[string]forge.Record),
+		prRefreshTs:          make(map[string]time.Time),
+		notifiedConflicts:    make(map[string]bool),
 		statusTrigger:        make(chan statusUpdateRequest, 1), // Buffered to avoid blocking
 		statusWorkerDone:     make(chan struct{}),
 		logUpdateChan:        make(chan *session.Instance, 100), // Buffered to absorb bursts
@@ -579,6 +663,7 @@ func NewHomeWithProfileAndMode(profile string) *Home {
 	// Initialize event-driven status detection
 	// Output callback: invoked when PipeManager detects %output from a session
 	outputCallback := func(sessionName string) {
+		h.markGridDirty(sessionName)
 		h.instancesMu.RLock()
 		for _, inst := range h.instances {
 			if inst.GetTmuxSession() != nil && inst.GetTmuxSession().Name == sessionName {
@@ -652,6 +737,14 @@ func NewHomeWithProfileAndMode(profile string) *Home {
 	// Note: Pool initialization happens AFTER loading sessions so we can discover MCPs in use
 	// Pool will be initialized in Init() after sessions are loaded
 
+	// Key bindings: registry defaults with [keys] overrides
+	var keyOverrides map[string][]string
+	if cfg, err := session.LoadUserConfig(); err == nil && cfg != nil {
+		keyOverrides = cfg.Keys
+	}
+	h.keyMap = NewKeyMap(defaultActions(), keyOverrides)
+	h.helpOverlay.SetKeyMap(h.keyMap)
+
 	// Initialize storage watcher for auto-reload
 	// Polls SQLite metadata for external changes (CLI commands, other instances)
 	// and triggers reload with state preservation
@@ -739,6 +832,12 @@ func (h *Home) SetWebMenuData(menuData *web.MemoryMenuData) {
 	}
 }
 
+// SetShareViewers wires the web server's share-link viewer tracker so the
+// session list can show how many people are watching each session.
+func (h *Home) SetShareViewers(viewers *web.ShareViewers) {
+	h.shareViewers = viewers
+}
+
 func (h *Home) getWebMenuData() *web.MemoryMenuData {
 	h.webMenuDataMu.RLock()
 	defer h.webMenuDataMu.RUnlock()
@@ -1186,6 +1285,7 @@ func (h *Home) Init() tea.Cmd {
 
 		h.tick(),
 		h.checkForUpdate(),
+		h.refreshPullRequest(nil),
 	}
 
 	// Start listening for storage changes
@@ -1306,7 +1406,6 @@ func (h *Home) pruneAnalyticsCache() {
 	for id, t := range h.analyticsCacheTime {
 		if now.Sub(t) > maxAge {
 			delete(h.analyticsCache, id)
-			delete(h.geminiAnalyticsCache, id)
 			delete(h.analyticsCacheTime, id)
 		}
 	}
@@ -1527,7 +1626,7 @@ func (h *Home) detectOpenCodeSessionCmd(inst *session.Instance) tea.Cmd {
 
 // getAnalyticsForSession returns cached analytics if still valid (within TTL)
 // Returns nil if cache miss or expired, triggering async fetch
-func (h *Home) getAnalyticsForSession(inst *session.Instance) *session.SessionAnalytics {
+func (h *Home) getAnalyticsForSession(inst *session.Instance) *session.AgentAnalytics {
 	if inst == nil {
 		return nil
 	}
@@ -1545,56 +1644,24 @@ func (h *Home) getAnalyticsForSession(inst *session.Instance) *session.SessionAn
 // fetchAnalytics returns a command that asynchronously parses session analytics
 // This keeps View() pure (no blocking I/O) as per Bubble Tea best practices
 func (h *Home) fetchAnalytics(inst *session.Instance) tea.Cmd {
-	if inst == nil {
+	if inst == nil || !session.ToolHasAnalytics(inst.GetToolThreadSafe()) {
 		return nil
 	}
 	sessionID := inst.ID
 
-	fetchTool := inst.GetToolThreadSafe()
-	if fetchTool == "claude" {
-		claudeSessionID := inst.ClaudeSessionID
-		return func() tea.Msg {
-			// Get JSONL path for this session
-			jsonlPath := inst.GetJSONLPath()
-			if jsonlPath == "" {
-				// No JSONL path available - return empty analytics
-				return analyticsFetchedMsg{
-					sessionID: sessionID,
-					analytics: nil,
-					err:       nil,
-				}
-			}
-
-			// Parse the JSONL file
-			analytics, err := session.ParseSessionJSONL(jsonlPath)
-			if err != nil {
-				uiLog.Warn("analytics_parse_failed", slog.String("session_id", sessionID), slog.String("claude_session_id", claudeSessionID), slog.String("error", err.Error()))
-				return analyticsFetchedMsg{
-					sessionID: sessionID,
-					analytics: nil,
-					err:       err,
-				}
-			}
-
-			return analyticsFetchedMsg{
-				sessionID: sessionID,
-				analytics: analytics,
-				err:       nil,
-			}
+	return func() tea.Msg {
+		// Parse the tool's transcript (Claude/Codex JSONL, Gemini session file,
+		// OpenCode storage) into the common analytics shape
+		analytics, err := inst.LoadAnalytics()
+		if err != nil {
+			uiLog.Warn("analytics_parse_failed", slog.String("session_id", sessionID), slog.String("tool", inst.GetToolThreadSafe()), slog.String("error", err.Error()))
 		}
-	} else if fetchTool == "gemini" {
-		return func() tea.Msg {
-			// Gemini analytics are updated via UpdateGeminiSession which is called in background
-			// during UpdateStatus(). We just return the current snapshot.
-			return analyticsFetchedMsg{
-				sessionID:       sessionID,
-				geminiAnalytics: inst.GeminiAnalytics,
-				err:             nil,
-			}
+		return analyticsFetchedMsg{
+			sessionID: sessionID,
+			analytics: analytics,
+			err:       err,
 		}
 	}
-
-	return nil
 }
 
 // getSelectedSession returns the currently selected session, or nil if a group is selected
@@ -1699,7 +1766,7 @@ func (h *Home) logWorker() {
 						uiLog.Error("log_worker_panic", slog.Any("panic", r))
 					}
 				}()
-				_ = inst.UpdateStatus()
+				h.updateStatus(inst)
 			}()
 		}
 	}
@@ -1788,9 +1855,8 @@ func (h *Home) backgroundStatusUpdate() {
 		}
 
 		g.Go(func() error {
-			oldStatus := inst.GetStatusThreadSafe()
 			instStart := time.Now()
-			_ = inst.UpdateStatus()
+			changed := h.updateStatus(inst)
 			instDur := time.Since(instStart)
 
 			if instDur > 50*time.Millisecond {
@@ -1798,10 +1864,8 @@ func (h *Home) backgroundStatusUpdate() {
 				slowSessions = append(slowSessions, fmt.Sprintf("%s=%v", inst.Title, instDur.Round(time.Millisecond)))
 				slowMu.Unlock()
 			}
-			newStatus := inst.GetStatusThreadSafe()
-			if newStatus != oldStatus {
+			if changed {
 				statusChanged.Store(true)
-				notifLog.Debug("status_changed", slog.String("title", inst.Title), slog.String("old", string(oldStatus)), slog.String("new", string(newStatus)))
 			}
 			return nil
 		})
@@ -1915,7 +1979,7 @@ func (h *Home) syncNotificationsBackground() {
 				if db := statedb.GetGlobal(); db != nil {
 					_ = db.SetAcknowledged(inst.ID, true)
 				}
-				_ = inst.UpdateStatus()
+				h.updateStatus(inst)
 				notifLog.Debug("session_acknowledged", slog.String("title", inst.Title), slog.String("status", string(inst.Status)))
 			}
 		}
@@ -2094,9 +2158,7 @@ func (h *Home) processStatusUpdate(req statusUpdateRequest) {
 	// Step 1: Always update visible sessions (Priority 1B - visible first)
 	for _, inst := range instancesCopy {
 		if visibleIDs[inst.ID] {
-			oldStatus := inst.GetStatusThreadSafe()
-			_ = inst.UpdateStatus() // Ignore errors in background worker
-			if inst.GetStatusThreadSafe() != oldStatus {
+			if h.updateStatus(inst) {
 				statusChanged = true
 			}
 			updated[inst.ID] = true
@@ -2125,9 +2187,7 @@ func (h *Home) processStatusUpdate(req statusUpdateRequest) {
 			continue
 		}
 
-		oldStatus := inst.GetStatusThreadSafe()
-		_ = inst.UpdateStatus() // Ignore errors in background worker
-		if inst.GetStatusThreadSafe() != oldStatus {
+		if h.updateStatus(inst) {
 			statusChanged = true
 		}
 		remaining--
@@ -2142,6 +2202,78 @@ func (h *Home) processStatusUpdate(req statusUpdateRequest) {
 	}
 }
 
+// updateStatus refreshes inst's status and runs the turn-end hooks when it
+// changed. Every TUI path that polls a session goes through it, so a turn
+// end is handled whichever path notices it first. It reports whether the
+// status changed. Errors are ignored, as in the background workers.
+func (h *Home) updateStatus(inst *session.Instance) bool {
+	oldStatus := inst.GetStatusThreadSafe()
+	_ = inst.UpdateStatus()
+	newStatus := inst.GetStatusThreadSafe()
+	if newStatus == oldStatus {
+		return false
+	}
+	notifLog.Debug("status_changed", slog.String("title", inst.Title), slog.String("old", string(oldStatus)), slog.String("new", string(newStatus)))
+	h.runTurnEndHooks(inst, oldStatus, newStatus)
+	return true
+}
+
+// runTurnEndHooks checkpoints, applies the context policy and delivers the
+// next queued message in the background when an agent turn ends. The steps
+// run in order in one goroutine so they never type into the pane at once.
+func (h *Home) runTurnEndHooks(inst *session.Instance, from, to session.Status) {
+	if !session.IsTurnEnd(from, to) {
+		return
+	}
+	var db *statedb.StateDB
+	if h.storage != nil {
+		db = h.storage.GetDB()
+	}
+	go func() {
+		res := session.RunTurnEndHooks(h.profile, db, inst, from, to)
+		if res.CheckpointErr != nil {
+			uiLog.Warn("auto_checkpoint_failed", slog.String("title", inst.Title), slog.String("error", res.CheckpointErr.Error()))
+		} else if res.Checkpoint != nil {
+			uiLog.Debug("auto_checkpoint", slog.String("title", inst.Title), slog.Int("n", res.Checkpoint.N))
+		}
+		if res.PolicyErr != nil {
+			uiLog.Warn("context_policy_failed", slog.String("title", inst.Title), slog.String("error", res.PolicyErr.Error()))
+		} else if res.Rule != nil {
+			uiLog.Info("context_policy", slog.String("title", inst.Title), slog.String("rule", res.Rule.String()))
+		}
+		if res.DeliveryErr != nil {
+			uiLog.Warn("queued_message_failed", slog.String("title", inst.Title), slog.String("error", res.DeliveryErr.Error()))
+		} else if res.Delivered != nil {
+			uiLog.Info("queued_message_sent", slog.String("title", inst.Title), slog.Int64("id", res.Delivered.ID))
+		}
+	}()
+}
+
+// queueDepthInterval is how often the queued message badges are refreshed.
+const queueDepthInterval = 2 * time.Second
+
+// prRecordsInterval is how often PR records are reloaded, picking up PRs
+// opened with `agent-deck worktree pr` while the TUI is running.
+const prRecordsInterval = 15 * time.Second
+
+// loadQueueDepths reads per-session message queue depths in the background
+func (h *Home) loadQueueDepths() tea.Cmd {
+	if h.storage == nil {
+		return nil
+	}
+	db := h.storage.GetDB()
+	if db == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		depths, err := db.QueueDepths()
+		if err != nil {
+			return queueDepthsMsg{}
+		}
+		return queueDepthsMsg{depths: depths}
+	}
+}
+
 // Update handles messages
 func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
@@ -2397,6 +2529,10 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Use forceSave to bypass mtime check - new session creation MUST persist
 			h.forceSaveInstances()
 
+			if msg.warning != nil {
+				h.setError(msg.warning)
+			}
+
 			// Start fetching preview for the new session
 			return h, h.fetchPreview(msg.instance)
 		}
@@ -2464,6 +2600,10 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Use forceSave to bypass mtime check - forked session MUST persist
 			h.forceSaveInstances()
 
+			if msg.warning != nil {
+				h.setError(msg.warning)
+			}
+
 			// Start fetching preview for the forked session
 			return h, h.fetchPreview(msg.instance)
 		}
@@ -2508,7 +2648,6 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		h.invalidatePreviewCache(msg.deletedID)
 		// Clean up analytics caches for deleted session
 		delete(h.analyticsCache, msg.deletedID)
-		delete(h.geminiAnalyticsCache, msg.deletedID)
 		delete(h.analyticsCacheTime, msg.deletedID)
 		h.logActivityMu.Lock()
 		delete(h.lastLogActivity, msg.deletedID)
@@ -2611,6 +2750,18 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		h.forceSaveInstances()
 		return h, nil
 
+	case bulkStepMsg:
+		return h.handleBulkStep(msg)
+
+	case gridTickMsg:
+		return h, h.handleGridTick()
+
+	case gridContentMsg:
+		for id, content := range msg.contents {
+			h.gridView.SetContent(id, content)
+		}
+		return h, nil
+
 	case sessionRestartedMsg:
 		if msg.err != nil {
 			// Restart failed - clear resuming animation immediately so user can retry.
@@ -2840,52 +2991,23 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmds = append(cmds, h.fetchPreview(inst))
 			}
 
-			// Analytics fetch (for Claude/Gemini sessions with analytics enabled)
+			// Analytics fetch (for sessions whose tool has analytics, when enabled)
 			// Use TTL cache - only fetch if cache miss/expired and not already fetching
-			tickTool := inst.GetToolThreadSafe()
-			if (tickTool == "claude" || tickTool == "gemini") && h.analyticsFetchingID != inst.ID {
-				if tickTool == "claude" {
-					cached := h.getAnalyticsForSession(inst)
-					if cached != nil {
-						// Use cached analytics
-						if h.analyticsSessionID != inst.ID {
-							h.currentAnalytics = cached
-							h.currentGeminiAnalytics = nil
-							h.analyticsSessionID = inst.ID
-							h.analyticsPanel.SetAnalytics(cached)
-						}
-					} else {
-						// Cache miss or expired - fetch new analytics
-						config, _ := session.LoadUserConfig()
-						if config != nil && config.GetShowAnalytics() {
-							h.analyticsFetchingID = inst.ID
-							cmds = append(cmds, h.fetchAnalytics(inst))
-						}
+			if session.ToolHasAnalytics(inst.GetToolThreadSafe()) && h.analyticsFetchingID != inst.ID {
+				cached := h.getAnalyticsForSession(inst)
+				if cached != nil {
+					// Use cached analytics
+					if h.analyticsSessionID != inst.ID {
+						h.currentAnalytics = cached
+						h.analyticsSessionID = inst.ID
+						h.analyticsPanel.SetAgentAnalytics(cached)
 					}
-				} else if tickTool == "gemini" {
-					// Check Gemini cache
-					var cached *session.GeminiSessionAnalytics
-					if c, ok := h.geminiAnalyticsCache[inst.ID]; ok {
-						if time.Since(h.analyticsCacheTime[inst.ID]) < analyticsCacheTTL {
-							cached = c
-						}
-					}
-
-					if cached != nil {
-						// Use cached analytics
-						if h.analyticsSessionID != inst.ID {
-							h.currentGeminiAnalytics = cached
-							h.currentAnalytics = nil
-							h.analyticsSessionID = inst.ID
-							h.analyticsPanel.SetGeminiAnalytics(cached)
-						}
-					} else {
-						// Cache miss or expired - fetch new analytics
-						config, _ := session.LoadUserConfig()
-						if config != nil && config.GetShowAnalytics() {
-							h.analyticsFetchingID = inst.ID
-							cmds = append(cmds, h.fetchAnalytics(inst))
-						}
+				} else {
+					// Cache miss or expired - fetch new analytics
+					config, _ := session.LoadUserConfig()
+					if config != nil && config.GetShowAnalytics() {
+						h.analyticsFetchingID = inst.ID
+						cmds = append(cmds, h.fetchAnalytics(inst))
 					}
 				}
 			}
@@ -2907,6 +3029,12 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						return worktreeDirtyCheckMsg{sessionID: sid, isDirty: dirty, err: err}
 					})
 				}
+
+				// PR state and CI checks (only for sessions with a PR, 60s TTL)
+				if _, hasPR := h.prRecords[inst.ID]; hasPR && time.Since(h.prRefreshTs[inst.ID]) > 60*time.Second {
+					h.prRefreshTs[inst.ID] = time.Now()
+					cmds = append(cmds, h.refreshPullRequest(inst))
+				}
 			}
 
 			if len(cmds) > 0 {
@@ -2935,30 +3063,17 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			h.analyticsCacheTime[msg.sessionID] = time.Now()
 
 			if msg.analytics != nil {
-				// Store Claude analytics in TTL cache
+				// Store analytics in TTL cache
 				h.analyticsCache[msg.sessionID] = msg.analytics
 				// Update current analytics for display
 				h.currentAnalytics = msg.analytics
-				h.currentGeminiAnalytics = nil
 				h.analyticsSessionID = msg.sessionID
 				// Update analytics panel with new data
-				h.analyticsPanel.SetAnalytics(msg.analytics)
-			} else if msg.geminiAnalytics != nil {
-				// Store Gemini analytics in TTL cache
-				h.geminiAnalyticsCache[msg.sessionID] = msg.geminiAnalytics
-				// Update current analytics for display
-				h.currentGeminiAnalytics = msg.geminiAnalytics
+				h.analyticsPanel.SetAgentAnalytics(msg.analytics)
+			} else if h.analyticsSessionID == msg.sessionID {
+				// Nothing parsed - clear display if it's the current session
 				h.currentAnalytics = nil
-				h.analyticsSessionID = msg.sessionID
-				// Update analytics panel with new data
-				h.analyticsPanel.SetGeminiAnalytics(msg.geminiAnalytics)
-			} else {
-				// Both nil - clear display if it's the current session
-				if h.analyticsSessionID == msg.sessionID {
-					h.currentAnalytics = nil
-					h.currentGeminiAnalytics = nil
-					h.analyticsPanel.SetAnalytics(nil)
-				}
+				h.analyticsPanel.SetAgentAnalytics(nil)
 			}
 		}
 		return h, nil
@@ -2978,6 +3093,14 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return h, nil
 
 	case worktreeFinishResultMsg:
+		if msg.conflict != nil {
+			if h.worktreeFinishDialog.IsVisible() {
+				h.worktreeFinishDialog.SetConflict(msg.conflict)
+			} else {
+				h.setError(msg.conflict)
+			}
+			return h, nil
+		}
 		if msg.err != nil {
 			// Show error in dialog (user can go back or cancel)
 			if h.worktreeFinishDialog.IsVisible() {
@@ -3006,7 +3129,6 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		h.cachedStatusCounts.valid.Store(false)
 		h.invalidatePreviewCache(msg.sessionID)
 		delete(h.analyticsCache, msg.sessionID)
-		delete(h.geminiAnalyticsCache, msg.sessionID)
 		delete(h.analyticsCacheTime, msg.sessionID)
 		h.worktreeDirtyMu.Lock()
 		delete(h.worktreeDirtyCache, msg.sessionID)
@@ -3045,6 +3167,43 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return h, nil
 
+	case gitActivityMsg:
+		h.gitPanelFetching = false
+		sameSession := msg.sessionID == h.gitPanelSessionID
+		h.gitPanelSessionID = msg.sessionID
+		h.gitPanelStatus = msg.status
+		h.gitPanel.SetActivity(msg.activity, msg.err, sameSession)
+		return h, nil
+
+	case worktreeConflictsMsg:
+		h.conflictScanInFlight = false
+		h.worktreeConflicts = msg.conflicts
+		h.worktreeConflictCounts = session.ConflictCounts(msg.conflicts)
+		for _, key := range msg.notified {
+			h.notifiedConflicts[key] = true
+		}
+		return h, nil
+
+	case pullRequestsMsg:
+		if msg.records != nil {
+			h.prRecords = msg.records
+		}
+		return h, nil
+
+	case queueDepthsMsg:
+		if msg.depths != nil {
+			h.queueDepths = msg.depths
+		}
+		return h, nil
+
+	case worktreeHandoffResultMsg:
+		if msg.err != nil {
+			h.setError(fmt.Errorf("failed to hand off conflict to %s: %v", msg.sessionTitle, msg.err))
+		} else {
+			h.setError(fmt.Errorf("Sent %d conflicted file(s) to '%s' to resolve", msg.fileCount, msg.sessionTitle))
+		}
+		return h, nil
+
 	case sendOutputResultMsg:
 		if msg.err != nil {
 			h.setError(fmt.Errorf("failed to send to %s: %v", msg.targetTitle, msg.err))
@@ -3124,6 +3283,28 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		// Conflict radar: compare sibling worktree changes every minute
+		var conflictCmd tea.Cmd
+		if !h.conflictScanInFlight && time.Since(h.lastConflictScan) >= conflictScanInterval {
+			h.lastConflictScan = time.Now()
+			h.conflictScanInFlight = true
+			conflictCmd = h.scanWorktreeConflicts()
+		}
+
+		// Queued message badges
+		var queueCmd tea.Cmd
+		if time.Since(h.lastQueueDepthCheck) >= queueDepthInterval {
+			h.lastQueueDepthCheck = time.Now()
+			queueCmd = h.loadQueueDepths()
+		}
+
+		// Pull request badges
+		var prCmd tea.Cmd
+		if time.Since(h.lastPRRecordsLoad) >= prRecordsInterval {
+			h.lastPRRecordsLoad = time.Now()
+			prCmd = h.refreshPullRequest(nil)
+		}
+
 		// Full log maintenance (orphan cleanup, etc) every 5 minutes
 		if time.Since(h.lastLogMaintenance) >= logMaintenanceInterval {
 			h.lastLogMaintenance = time.Now()
@@ -3167,7 +3348,16 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			h.previewCacheMu.Unlock()
 		}
-		return h, tea.Batch(h.tick(), previewCmd)
+		// Git panel refreshes on selection change or status transition, not every tick
+		var gitCmd tea.Cmd
+		if selected != nil && h.previewMode == PreviewModeGit && !h.gitPanelFetching {
+			status := selected.GetStatusThreadSafe()
+			if selected.ID != h.gitPanelSessionID || status != h.gitPanelStatus {
+				h.gitPanelFetching = true
+				gitCmd = h.fetchGitActivity(selected, status)
+			}
+		}
+		return h, tea.Batch(h.tick(), previewCmd, conflictCmd, queueCmd, prCmd, gitCmd)
 
 	case globalSearchDebounceMsg, globalSearchResultsMsg:
 		// Route async global search messages to the global search component
@@ -3281,6 +3471,15 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if h.worktreeFinishDialog.IsVisible() {
 			return h.handleWorktreeFinishDialogKey(msg)
 		}
+		if h.commandPalette.IsVisible() {
+			return h.handleCommandPaletteKey(msg)
+		}
+		if h.bulkDialog.IsVisible() {
+			return h.handleBulkDialogKey(msg)
+		}
+		if h.gridView.IsVisible() {
+			return h.handleGridKey(msg)
+		}
 
 		// Main view keys
 		return h.handleMainKey(msg)
@@ -3524,9 +3723,7 @@ func (h *Home) handleNewDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if command == "claude" && claudeOpts != nil {
 			toolOptionsJSON, _ = session.MarshalToolOptions(claudeOpts)
 		} else if command == "codex" {
-			yolo := h.newDialog.GetCodexYoloMode()
-			codexOpts := &session.CodexOptions{YoloMode: &yolo}
-			toolOptionsJSON, _ = session.MarshalToolOptions(codexOpts)
+			toolOptionsJSON, _ = session.MarshalToolOptions(h.newDialog.GetCodexOptions())
 		}
 
 		if _, err := os.Stat(path); os.IsNotExist(err) {
@@ -3539,8 +3736,9 @@ func (h *Home) handleNewDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		h.clearError()
 
 		geminiYoloMode := h.newDialog.IsGeminiYoloMode()
+		tmpl := h.newDialog.GetSelectedTemplate()
 
-		return h, h.createSessionInGroupWithWorktreeAndOptions(name, path, command, groupPath, worktreePath, worktreeRepoRoot, branchName, geminiYoloMode, toolOptionsJSON)
+		return h, h.createSessionInGroupWithWorktreeAndOptions(name, path, command, groupPath, worktreePath, worktreeRepoRoot, branchName, geminiYoloMode, toolOptionsJSON, tmpl)
 
 	case "esc":
 		h.newDialog.Hide()
@@ -3553,13 +3751,72 @@ func (h *Home) handleNewDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return h, cmd
 }
 
-// handleMainKey handles keys in main view
+// handleMainKey handles keys in main view, translating [keys] overrides
+// to the default key of their action
 func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
+	// Actions without a default key run directly when the user bound one
+	if a := h.keyMap.ActionForKey(msg.String()); a != nil && len(a.Keys) == 0 {
+		return h.runAction(a)
+	}
+	key, ok := h.keyMap.Resolve(msg.String())
+	if !ok {
+		return h, nil
+	}
+	return h.runMainKey(key)
+}
+
+// runMainKey runs the main view action bound to a default key
+func (h *Home) runMainKey(key string) (tea.Model, tea.Cmd) {
+	// With a multi-selection, session actions apply to the whole selection
+	if h.hasBulkSelection() {
+		switch key {
+		case "R":
+			return h.openBulkAction(BulkRestart)
+		case "d":
+			return h.openBulkAction(BulkDelete)
+		case "M", "shift+m":
+			return h.openBulkAction(BulkMove)
+		case "f":
+			return h.openBulkAction(BulkFork)
+		}
+	}
+
+	switch key {
 	case "q", "ctrl+c":
 		return h.tryQuit()
 
+	case "ctrl+k", ":":
+		return h.openCommandPalette()
+
+	case " ":
+		// Toggle multi-select mark (whole group on a group header)
+		h.toggleBulkMark()
+		return h, nil
+
+	case "V":
+		// Visual range selection: V starts at the cursor, V again keeps the range
+		h.toggleVisualMode()
+		return h, nil
+
+	case "*":
+		// Select every session matching the status filter (toggle)
+		h.selectAllMatching()
+		return h, nil
+
+	case "B":
+		// Bulk action menu for the selection
+		return h.openBulkDialog(nil)
+
+	case "T":
+		// Tiled live view of the selection (or the saved grid layout)
+		return h.openGridView()
+
 	case "esc":
+		// Clear multi-selection first
+		if h.hasBulkSelection() {
+			h.clearBulkSelection()
+			return h, nil
+		}
 		// Dismiss maintenance banner if visible
 		if h.maintenanceMsg != "" {
 			h.maintenanceMsg = ""
@@ -3801,11 +4058,10 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return h, nil
 
 	case "m":
-		// MCP Manager - for Claude and Gemini sessions
+		// MCP Manager - for Claude, Gemini, Codex and OpenCode sessions
 		if h.cursor < len(h.flatItems) {
 			item := h.flatItems[h.cursor]
-			if item.Type == session.ItemTypeSession && item.Session != nil &&
-				(item.Session.Tool == "claude" || item.Session.Tool == "gemini") {
+			if item.Type == session.ItemTypeSession && item.Session != nil && item.Session.SupportsMCP() {
 				h.mcpDialog.SetSize(h.width, h.height)
 				if err := h.mcpDialog.Show(item.Session.ProjectPath, item.Session.ID, item.Session.Tool); err != nil {
 					h.setError(err)
@@ -4032,6 +4288,12 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Apply user's preferred default tool from config
 		h.newDialog.SetDefaultTool(session.GetDefaultTool())
 
+		templates, err := session.ListTemplates(h.profile)
+		if err != nil {
+			uiLog.Warn("list_templates_failed", slog.String("error", err.Error()))
+		}
+		h.newDialog.SetTemplates(templates)
+
 		// Auto-select parent group from current cursor position
 		groupPath := session.DefaultGroupPath
 		groupName := session.DefaultGroupName
@@ -4085,7 +4347,7 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					}
 					// Clear idle optimization so UpdateStatus does a full check
 					item.Session.ForceNextStatusCheck()
-					_ = item.Session.UpdateStatus()
+					h.updateStatus(item.Session)
 					h.saveInstances()
 				}
 			}
@@ -4093,8 +4355,19 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return h, nil
 
 	case "v":
-		// Toggle preview mode (cycle: both → output-only → analytics-only → both)
-		h.previewMode = (h.previewMode + 1) % 3
+		// Toggle preview mode (cycle: both → output-only → analytics-only → git → both)
+		h.previewMode = (h.previewMode + 1) % previewModeCount
+		return h, nil
+
+	case "[", "]":
+		// Scroll the git panel diff
+		if h.previewMode == PreviewModeGit {
+			if key == "[" {
+				h.gitPanel.ScrollBy(-5)
+			} else {
+				h.gitPanel.ScrollBy(5)
+			}
+		}
 		return h, nil
 
 	case "y":
@@ -4156,6 +4429,13 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return h, nil
 
+	case "p":
+		// Pick a prompt from the library for the selection or the session
+		if len(h.actionTargets()) == 0 {
+			return h, nil
+		}
+		return h.runAction(h.keyMap.Action("send_prompt"))
+
 	case "x":
 		// Send session output to another session
 		if h.cursor < len(h.flatItems) {
@@ -4212,7 +4492,7 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
 		// Quick jump to Nth root group (1-indexed)
-		targetNum := int(msg.String()[0] - '0') // Convert "1" -> 1, "2" -> 2, etc.
+		targetNum := int(key[0] - '0') // Convert "1" -> 1, "2" -> 2, etc.
 		h.jumpToRootGroup(targetNum)
 		return h, nil
 
@@ -4295,7 +4575,7 @@ func (h *Home) handleConfirmDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				h.setError(fmt.Errorf("failed to create directory: %w", err))
 				return h, nil
 			}
-			return h, h.createSessionInGroupWithWorktreeAndOptions(name, path, command, groupPath, "", "", "", false, pendingToolOpts)
+			return h, h.createSessionInGroupWithWorktreeAndOptions(name, path, command, groupPath, "", "", "", false, pendingToolOpts, nil)
 		case "n", "N", "esc":
 			h.confirmDialog.Hide()
 			return h, nil
@@ -4915,8 +5195,9 @@ func (h *Home) loadUIState() {
 	h.pendingCursorRestore = &state
 }
 
-// createSessionInGroupWithWorktreeAndOptions creates a new session with full options including YOLO mode and tool options
-func (h *Home) createSessionInGroupWithWorktreeAndOptions(name, path, command, groupPath, worktreePath, worktreeRepoRoot, worktreeBranch string, geminiYoloMode bool, toolOptionsJSON json.RawMessage) tea.Cmd {
+// createSessionInGroupWithWorktreeAndOptions creates a new session with full options including YOLO mode and tool options.
+// When tmpl is set, its wrapper, MCPs, skills and initial message are applied as well.
+func (h *Home) createSessionInGroupWithWorktreeAndOptions(name, path, command, groupPath, worktreePath, worktreeRepoRoot, worktreeBranch string, geminiYoloMode bool, toolOptionsJSON json.RawMessage, tmpl *session.SessionTemplate) tea.Cmd {
 	return func() tea.Msg {
 		// Check tmux availability before creating session
 		if err := tmux.IsTmuxAvailable(); err != nil {
@@ -4953,10 +5234,12 @@ func (h *Home) createSessionInGroupWithWorktreeAndOptions(name, path, command, g
 		inst.Command = command
 
 		// Set worktree fields if provided
+		var provisionWarning error
 		if worktreePath != "" {
 			inst.WorktreePath = worktreePath
 			inst.WorktreeRepoRoot = worktreeRepoRoot
 			inst.WorktreeBranch = worktreeBranch
+			provisionWarning = provisionWorktree(worktreeRepoRoot, worktreePath)
 		}
 
 		// Set Gemini YOLO mode if enabled (per-session override)
@@ -4969,11 +5252,61 @@ func (h *Home) createSessionInGroupWithWorktreeAndOptions(name, path, command, g
 			inst.ToolOptionsJSON = toolOptionsJSON
 		}
 
+		var initialMessage string
+		if tmpl != nil {
+			projectPath := path
+			if worktreeRepoRoot != "" {
+				projectPath = worktreeRepoRoot
+			}
+			vars := session.TemplateVars{
+				Project: filepath.Base(projectPath),
+				Branch:  worktreeBranch,
+				Title:   name,
+				Group:   groupPath,
+				Path:    path,
+			}
+			if tmpl.Wrapper != "" {
+				inst.Wrapper = vars.Expand(tmpl.Wrapper)
+			}
+			if err := tmpl.AttachResources(path); err != nil {
+				uiLog.Warn("template_attach_failed", slog.String("template", tmpl.Name), slog.String("error", err.Error()))
+			}
+			initialMessage = vars.Expand(tmpl.InitialMessage)
+		}
+
 		if err := inst.Start(); err != nil {
 			return sessionCreatedMsg{err: err}
 		}
-		return sessionCreatedMsg{instance: inst}
+		if initialMessage != "" {
+			// Delivery waits for the agent to become ready; don't hold up the UI
+			go func() {
+				if err := inst.SendMessageWhenReady(initialMessage); err != nil {
+					uiLog.Warn("template_message_failed", slog.String("session", inst.ID), slog.String("error", err.Error()))
+				}
+			}()
+		}
+		return sessionCreatedMsg{instance: inst, warning: provisionWarning}
+	}
+}
+
+// provisionWorktree applies the repo's provisioning recipe to a new worktree.
+// It runs inside the session creation command, so install steps never block
+// the UI; results are logged and shown by `agent-deck worktree info`. It
+// returns a warning for the TUI when an untrusted recipe was held back.
+func provisionWorktree(repoRoot, worktreePath string) error {
+	result, err := session.ProvisionWorktree(repoRoot, worktreePath)
+	if err != nil {
+		uiLog.Warn("worktree_provision_failed", slog.String("path", worktreePath), slog.String("error", err.Error()))
+	}
+	if result == nil {
+		return nil
+	}
+	uiLog.Info("worktree_provisioned", slog.String("path", worktreePath), slog.String("summary", result.Summary()), slog.Bool("ok", result.OK()))
+	if !result.Untrusted() {
+		return nil
 	}
+	uiLog.Warn("worktree_recipe_untrusted", slog.String("repo", repoRoot), slog.Int("skipped_commands", len(result.SkippedCommands)), slog.Int("skipped_env", len(result.SkippedEnv)))
+	return fmt.Errorf("warning: worktree recipe not trusted, commands and host env skipped (approve with: agent-deck worktree trust %s)", repoRoot)
 }
 
 // quickForkSession performs a quick fork with default title suffix " (fork)"
@@ -5085,7 +5418,7 @@ func (h *Home) quickCreateSession() tea.Cmd {
 	return h.createSessionInGroupWithWorktreeAndOptions(
 		name, projectPath, command, groupPath,
 		"", "", "", // no worktree
-		geminiYoloMode, toolOptionsJSON,
+		geminiYoloMode, toolOptionsJSON, nil,
 	)
 }
 
@@ -5116,6 +5449,11 @@ func (h *Home) forkSessionWithDialog(source *session.Instance) tea.Cmd {
 	}
 	// Pre-populate dialog with source session info
 	h.forkDialog.Show(source.Title, source.ProjectPath, source.GroupPath)
+	// Carry over the source's model, permission mode, tool filters etc.
+	h.forkDialog.SetOptions(source.GetClaudeOptions())
+	// Other tools fork through their adapter, which takes no Claude options
+	adapter := session.GetToolAdapter(source.Tool)
+	h.forkDialog.SetShowOptions(adapter == nil || adapter.Name() == "claude")
 	return nil
 }
 
@@ -5143,13 +5481,28 @@ func (h *Home) forkSessionCmdWithOptions(source *session.Instance, title, groupP
 			return sessionForkedMsg{err: fmt.Errorf("cannot fork session: %w", err), sourceID: sourceID}
 		}
 
+		var provisionWarning error
+		if opts != nil && opts.WorktreePath != "" {
+			provisionWarning = provisionWorktree(opts.WorktreeRepoRoot, opts.WorktreePath)
+		}
+
 		var inst *session.Instance
 		var err error
 
-		switch source.Tool {
-		case "opencode":
-			inst, _, err = source.CreateForkedOpenCodeInstance(title, groupPath)
-		default:
+		if adapter := session.GetToolAdapter(source.Tool); adapter != nil && adapter.Name() != "claude" {
+			// Gemini, Codex, OpenCode and custom tools fork through their
+			// adapter; only the worktree placement applies to them
+			var forkOpts *session.ForkOptions
+			if opts != nil && opts.WorkDir != "" {
+				forkOpts = &session.ForkOptions{
+					WorkDir:          opts.WorkDir,
+					WorktreePath:     opts.WorktreePath,
+					WorktreeRepoRoot: opts.WorktreeRepoRoot,
+					WorktreeBranch:   opts.WorktreeBranch,
+				}
+			}
+			inst, err = source.ForkWithAdapter(title, groupPath, forkOpts)
+		} else {
 			inst, _, err = source.CreateForkedInstanceWithOptions(title, groupPath, opts)
 		}
 		if err != nil {
@@ -5165,7 +5518,7 @@ func (h *Home) forkSessionCmdWithOptions(source *session.Instance, title, groupP
 			go inst.DetectOpenCodeSession()
 		}
 
-		return sessionForkedMsg{instance: inst, sourceID: sourceID}
+		return sessionForkedMsg{instance: inst, sourceID: sourceID, warning: provisionWarning}
 	}
 }
 
@@ -5504,6 +5857,19 @@ func (h *Home) renderFilterBar() string {
 		}
 	}
 
+	// Multi-select pill
+	if h.hasBulkSelection() {
+		label := fmt.Sprintf("◆ %d selected", len(h.selectedSessions()))
+		if h.visualAnchor != "" {
+			label = "VISUAL " + label
+		}
+		pills = append(pills, lipgloss.NewStyle().
+			Foreground(ColorBg).
+			Background(ColorYellow).
+			Bold(true).
+			Padding(0, 1).Render(label))
+	}
+
 	// Hint for keyboard shortcuts (shift+number to filter, 0 to clear)
 	hintStyle := lipgloss.NewStyle().Foreground(ColorComment).Faint(true)
 	hint := hintStyle.Render("  !@#$ filter • 0 all")
@@ -5524,6 +5890,9 @@ func (h *Home) updateSizes() {
 	h.confirmDialog.SetSize(h.width, h.height)
 	h.geminiModelDialog.SetSize(h.width, h.height)
 	h.worktreeFinishDialog.SetSize(h.width, h.height)
+	h.bulkDialog.SetSize(h.width, h.height)
+	h.commandPalette.SetSize(h.width, h.height)
+	h.gridView.SetSize(h.width, h.height)
 }
 
 // View renders the UI
@@ -5610,6 +5979,15 @@ func (h *Home) View() string {
 	if h.worktreeFinishDialog.IsVisible() {
 		return h.worktreeFinishDialog.View()
 	}
+	if h.commandPalette.IsVisible() {
+		return h.commandPalette.View()
+	}
+	if h.bulkDialog.IsVisible() {
+		return h.bulkDialog.View()
+	}
+	if h.gridView.IsVisible() {
+		return h.gridView.View()
+	}
 
 	// Reuse viewBuilder to reduce allocations (reset and pre-allocate)
 	h.viewBuilder.Reset()
@@ -6382,6 +6760,154 @@ func renderForkHintLine(b *strings.Builder) {
 	b.WriteString("\n")
 }
 
+// renderSyncedMCPLine renders a session's MCPs with source indicators and
+// sync status: pending (⟳) MCPs need a restart, stale (✕) ones are still
+// loaded but were removed from the config.
+func renderSyncedMCPLine(b *strings.Builder, selected *session.Instance, width int) {
+	labelStyle := lipgloss.NewStyle().Foreground(ColorText)
+	valueStyle := lipgloss.NewStyle().Foreground(ColorText)
+
+	mcpInfo := selected.GetMCPInfo()
+	hasLoadedMCPs := len(selected.LoadedMCPNames) > 0
+	hasMCPs := mcpInfo != nil && mcpInfo.HasAny()
+
+	if hasMCPs || hasLoadedMCPs {
+		b.WriteString(labelStyle.Render("MCPs:    "))
+
+		// Build set of loaded MCPs for comparison
+		loadedSet := make(map[string]bool)
+		for _, name := range selected.LoadedMCPNames {
+			loadedSet[name] = true
+		}
+
+		// Build set of current MCPs (from config)
+		currentSet := make(map[string]bool)
+		if mcpInfo != nil {
+			for _, name := range mcpInfo.Global {
+				currentSet[name] = true
+			}
+			for _, name := range mcpInfo.Project {
+				currentSet[name] = true
+			}
+			for _, mcp := range mcpInfo.LocalMCPs {
+				currentSet[mcp.Name] = true
+			}
+		}
+
+		// Styles for different MCP states
+		pendingStyle := lipgloss.NewStyle().Foreground(ColorYellow)
+		staleStyle := lipgloss.NewStyle().Foreground(ColorText)
+
+		var mcpParts []string
+
+		// Helper to add MCP with appropriate styling
+		addMCP := func(name, source string) {
+			label := name + " (" + source + ")"
+			if !hasLoadedMCPs {
+				// Old session without LoadedMCPNames - show all as normal (no sync info)
+				mcpParts = append(mcpParts, valueStyle.Render(label))
+			} else if loadedSet[name] {
+				// In both loaded and current - active (normal style)
+				mcpParts = append(mcpParts, valueStyle.Render(label))
+			} else {
+				// In current but not loaded - pending (needs restart)
+				mcpParts = append(mcpParts, pendingStyle.Render(label+" ⟳"))
+			}
+		}
+
+		// Add MCPs from current config with source indicators
+		if mcpInfo != nil {
+			for _, name := range mcpInfo.Global {
+				addMCP(name, "g")
+			}
+			for _, name := range mcpInfo.Project {
+				addMCP(name, "p")
+			}
+			for _, mcp := range mcpInfo.LocalMCPs {
+				// Show source path if different from project path
+				sourceIndicator := "l"
+				if mcp.SourcePath != selected.ProjectPath {
+					// Show abbreviated path (just directory name)
+					sourceIndicator = "l:" + filepath.Base(mcp.SourcePath)
+				}
+				addMCP(mcp.Name, sourceIndicator)
+			}
+		}
+
+		// Add stale MCPs (loaded but no longer in config)
+		if hasLoadedMCPs {
+			for _, name := range selected.LoadedMCPNames {
+				if !currentSet[name] {
+					// Still running but removed from config
+					mcpParts = append(mcpParts, staleStyle.Render(name+" ✕"))
+				}
+			}
+		}
+
+		// Calculate available width for MCPs (width - 4 for panel padding - 9 for "MCPs:    " label)
+		mcpMaxWidth := width - 4 - 9
+		if mcpMaxWidth < 20 {
+			mcpMaxWidth = 20 // Minimum sensible width
+		}
+
+		// Build MCPs progressively to fit within available width
+		var mcpResult strings.Builder
+		mcpCount := 0
+		currentWidth := 0
+
+		for i, part := range mcpParts {
+			// Strip ANSI codes to measure actual display width
+			plainPart := tmux.StripANSI(part)
+			partWidth := runewidth.StringWidth(plainPart)
+
+			// Calculate width including separator if not first
+			addedWidth := partWidth
+			if mcpCount > 0 {
+				addedWidth += 2 // ", " separator
+			}
+
+			remaining := len(mcpParts) - i
+			isLast := remaining == 1
+
+			// For non-last MCPs: reserve space for "+N more" indicator
+			// For last MCP: just check if it fits without indicator
+			var wouldExceed bool
+			if isLast {
+				// Last MCP - just check if it fits
+				wouldExceed = currentWidth+addedWidth > mcpMaxWidth
+			} else {
+				// Not last - check with indicator space reserved
+				moreIndicator := fmt.Sprintf(" (+%d more)", remaining)
+				moreWidth := runewidth.StringWidth(moreIndicator)
+				wouldExceed = currentWidth+addedWidth+moreWidth > mcpMaxWidth
+			}
+
+			if wouldExceed {
+				// Would exceed - show indicator for remaining
+				moreStyle := lipgloss.NewStyle().Foreground(ColorText).Italic(true)
+				if mcpCount > 0 {
+					mcpResult.WriteString(moreStyle.Render(fmt.Sprintf(" (+%d more)", remaining)))
+				} else {
+					// No MCPs fit - just show count
+					mcpResult.WriteString(moreStyle.Render(fmt.Sprintf("(%d MCPs)", len(mcpParts))))
+				}
+				break
+			}
+
+			// Add separator if not first
+			if mcpCount > 0 {
+				mcpResult.WriteString(", ")
+			}
+			mcpResult.WriteString(part)
+			currentWidth += addedWidth
+			mcpCount++
+		}
+
+		b.WriteString(mcpResult.String())
+		b.WriteString("\n")
+	}
+}
+
 // renderSimpleMCPLine renders MCPs without sync status (for Gemini and other tools).
 // Width-aware truncation shows "(+N more)" when MCPs don't fit.
 func renderSimpleMCPLine(b *strings.Builder, mcpInfo *session.MCPInfo, width int) {
@@ -6520,7 +7046,7 @@ func (h *Home) renderHelpBarMinimal() string {
 			if item.Session != nil && item.Session.CanFork() {
 				contextKeys += " " + keyStyle.Render("f")
 			}
-			if item.Session != nil && (item.Session.Tool == "claude" || item.Session.Tool == "gemini") {
+			if item.Session != nil && item.Session.SupportsMCP() {
 				contextKeys += " " + keyStyle.Render("m")
 			}
 			if item.Session != nil && item.Session.Tool == "claude" {
@@ -6581,10 +7107,10 @@ func (h *Home) renderHelpBarCompact() string {
 			if item.Session != nil && item.Session.CanFork() {
 				contextHints = append(contextHints, h.helpKeyShort("f", "Fork"))
 			}
-			if item.Session != nil && (item.Session.Tool == "claude" || item.Session.Tool == "gemini") {
+			if item.Session != nil && item.Session.SupportsMCP() {
 				contextHints = append(contextHints, h.helpKeyShort("m", "MCP"))
-				contextHints = append(contextHints, h.helpKeyShort("v", h.previewModeShort()))
 			}
+			contextHints = append(contextHints, h.helpKeyShort("v", h.previewModeShort()))
 			if item.Session != nil && item.Session.Tool == "claude" {
 				contextHints = append(contextHints, h.helpKeyShort("s", "Skills"))
 			}
@@ -6622,6 +7148,7 @@ func (h *Home) renderHelpBarCompact() string {
 
 // helpKeyShort formats a compact keyboard shortcut (no padding)
 func (h *Home) helpKeyShort(key, desc string) string {
+	key = h.keyMap.DisplayKey(key)
 	keyStyle := lipgloss.NewStyle().
 		Foreground(ColorBg).
 		Background(ColorAccent).
@@ -6637,6 +7164,8 @@ func (h *Home) previewModeShort() string {
 		return "Out"
 	case PreviewModeAnalytics:
 		return "Stats"
+	case PreviewModeGit:
+		return "Git"
 	default:
 		return "Both"
 	}
@@ -6653,7 +7182,26 @@ func (h *Home) renderHelpBarFull() string {
 	var secondaryHints []string // Edit actions (rename, move, delete)
 	var contextTitle string
 
-	if len(h.flatItems) == 0 {
+	if h.hasBulkSelection() {
+		contextTitle = fmt.Sprintf("%d selected", len(h.selectedSessions()))
+		if h.visualAnchor != "" {
+			contextTitle = "Visual " + contextTitle
+		}
+		primaryHints = []string{
+			h.helpKey("Space", "Mark"),
+			h.helpKey("V", "Range"),
+			h.helpKey("*", "All"),
+			h.helpKey("B", "Actions"),
+			h.helpKey("T", "Grid"),
+			h.helpKey("R", "Restart"),
+			h.helpKey("f", "Fork"),
+		}
+		secondaryHints = []string{
+			h.helpKey("M", "Move"),
+			h.helpKey("d", "Delete"),
+			h.helpKey("Esc", "Clear"),
+		}
+	} else if len(h.flatItems) == 0 {
 		contextTitle = "Empty"
 		primaryHints = []string{
 			h.helpKey("n/N", "New/Quick"),
@@ -6685,10 +7233,13 @@ func (h *Home) renderHelpBarFull() string {
 			if item.Session != nil && item.Session.CanFork() {
 				primaryHints = append(primaryHints, h.helpKey("f/F", "Fork"))
 			}
-			// Show MCP Manager and preview mode toggle for Claude and Gemini sessions
-			if item.Session != nil && (item.Session.Tool == "claude" || item.Session.Tool == "gemini") {
+			// Show MCP Manager for Claude and Gemini sessions
+			if item.Session != nil && item.Session.SupportsMCP() {
 				primaryHints = append(primaryHints, h.helpKey("m", "MCP"))
-				primaryHints = append(primaryHints, h.helpKey("v", h.previewModeShort()))
+			}
+			primaryHints = append(primaryHints, h.helpKey("v", h.previewModeShort()))
+			if h.previewMode == PreviewModeGit {
+				primaryHints = append(primaryHints, h.helpKey("[ ]", "Scroll diff"))
 			}
 			if item.Session != nil && item.Session.Tool == "claude" {
 				primaryHints = append(primaryHints, h.helpKey("s", "Skills"))
@@ -6740,7 +7291,7 @@ func (h *Home) renderHelpBarFull() string {
 	// Global shortcuts (right side) - more compact with separators
 	globalStyle := lipgloss.NewStyle().Foreground(ColorComment)
 	globalHints := globalStyle.Render("↑↓ Nav") + sep +
-		globalStyle.Render("/ Search  G Global") + sep +
+		globalStyle.Render("/ Search  G Global  ^K Commands") + sep +
 		globalStyle.Render("? Help  q Quit")
 
 	// Calculate spacing between left (context) and right (global) portions
@@ -6764,6 +7315,7 @@ func (h *Home) renderHelpBarFull() string {
 
 // helpKey formats a keyboard shortcut for the help bar
 func (h *Home) helpKey(key, desc string) string {
+	key = h.keyMap.DisplayKey(key)
 	keyStyle := lipgloss.NewStyle().
 		Foreground(ColorBg).
 		Background(ColorAccent).
@@ -6776,6 +7328,7 @@ func (h *Home) helpKey(key, desc string) string {
 // renderSessionList renders the left panel with hierarchical session list
 func (h *Home) renderSessionList(width, height int) string {
 	var b strings.Builder
+	h.visualRange = h.visualRangeSet()
 
 	if len(h.flatItems) == 0 {
 		// Responsive empty state - adapts to available space
@@ -7035,6 +7588,14 @@ func (h *Home) renderSessionItem(b *strings.Builder, item session.Item, selected
 			baseIndent = groupIndent + " " + treeStyle.Render("│")
 		}
 	}
+	// Multi-select mark replaces the prefix (keeps the cursor color when selected)
+	if h.isBulkMarked(inst.ID) {
+		if selected {
+			selectionPrefix = SessionSelectionPrefix.Render("◆")
+		} else {
+			selectionPrefix = BulkMarkStyle.Render("◆")
+		}
+	}
 
 	title := titleStyle.Render(inst.Title)
 	tool := toolStyle.Render(" " + instTool)
@@ -7063,10 +7624,58 @@ func (h *Home) renderSessionItem(b *strings.Builder, item session.Item, selected
 		worktreeBadge = wtStyle.Render(" [" + branch + "]")
 	}
 
-	// Build row: [baseIndent][selection][tree][status] [title] [tool] [yolo] [worktree]
+	// Conflict radar badge: other worktree sessions editing the same files
+	overlapBadge := ""
+	if n := h.worktreeConflictCounts[inst.ID]; n > 0 {
+		overlapStyle := lipgloss.NewStyle().Foreground(ColorOrange)
+		if selected {
+			overlapStyle = SessionStatusSelStyle
+		}
+		overlapBadge = overlapStyle.Render(fmt.Sprintf(" [⚠ %d overlap]", n))
+	}
+
+	// Pull request badge for sessions with a PR opened via `worktree pr`
+	prBadge := ""
+	if rec, ok := h.prRecords[inst.ID]; ok {
+		prStyle := lipgloss.NewStyle().Foreground(ColorGreen)
+		switch {
+		case rec.CheckState == forge.CheckFailure:
+			prStyle = lipgloss.NewStyle().Foreground(ColorRed)
+		case rec.CheckState == forge.CheckPending || rec.PR.State == forge.PRStateDraft:
+			prStyle = lipgloss.NewStyle().Foreground(ColorYellow)
+		case rec.PR.State == forge.PRStateClosed:
+			prStyle = lipgloss.NewStyle().Foreground(ColorComment)
+		}
+		if selected {
+			prStyle = SessionStatusSelStyle
+		}
+		prBadge = prStyle.Render(" [PR " + rec.Badge() + "]")
+	}
+
+	// Viewer badge for sessions watched through web share links
+	viewerBadge := ""
+	if n := h.shareViewers.Count(inst.ID); n > 0 {
+		viewerStyle := lipgloss.NewStyle().Foreground(ColorPurple)
+		if selected {
+			viewerStyle = SessionStatusSelStyle
+		}
+		viewerBadge = viewerStyle.Render(fmt.Sprintf(" [%d watching]", n))
+	}
+
+	// Queue badge: messages waiting for the agent to finish its turn
+	queueBadge := ""
+	if n := h.queueDepths[inst.ID]; n > 0 {
+		queueStyle := lipgloss.NewStyle().Foreground(ColorCyan)
+		if selected {
+			queueStyle = SessionStatusSelStyle
+		}
+		queueBadge = queueStyle.Render(fmt.Sprintf(" [%d queued]", n))
+	}
+
+	// Build row: [baseIndent][selection][tree][status] [title] [tool] [yolo] [worktree] [overlap] [pr] [viewers] [queue]
 	// Format: " ├─ ● session-name tool" or "▶└─ ● session-name tool"
 	// Sub-sessions get extra indent: "   ├─◐ sub-session tool"
-	row := fmt.Sprintf("%s%s%s %s %s%s%s%s", baseIndent, selectionPrefix, treeStyle.Render(treeConnector), status, title, tool, yoloBadge, worktreeBadge)
+	row := fmt.Sprintf("%s%s%s %s %s%s%s%s%s%s%s%s", baseIndent, selectionPrefix, treeStyle.Render(treeConnector), status, title, tool, yoloBadge, worktreeBadge, overlapBadge, prBadge, viewerBadge, queueBadge)
 	b.WriteString(row)
 	b.WriteString("\n")
 }
@@ -7506,6 +8115,31 @@ func (h *Home) renderPreviewPane(width, height int) string {
 		b.WriteString(dirtyStyle.Render(dirtyLabel))
 		b.WriteString("\n")
 
+		// Conflict radar: overlapping edits with sibling worktrees
+		for _, c := range h.worktreeConflicts {
+			other := c.B
+			if c.B.SessionID == selected.ID {
+				other = c.A
+			} else if c.A.SessionID != selected.ID {
+				continue
+			}
+			overlapStyle := lipgloss.NewStyle().Foreground(ColorOrange)
+			b.WriteString(wtLabelStyle.Render("Overlap: "))
+			b.WriteString(overlapStyle.Render(fmt.Sprintf("%s (%d file(s), %d same lines)", other.Title, len(c.Files), c.HunkOverlaps())))
+			b.WriteString("\n")
+		}
+
+		// Pull request (from `agent-deck worktree pr`)
+		if rec, ok := h.prRecords[selected.ID]; ok {
+			b.WriteString(wtLabelStyle.Render("PR:      "))
+			b.WriteString(wtValueStyle.Render(rec.Badge()))
+			b.WriteString("\n")
+			for _, c := range rec.Checks {
+				b.WriteString(wtHintStyle.Render(fmt.Sprintf("  %s %s", c.Name, c.State)))
+				b.WriteString("\n")
+			}
+		}
+
 		// Finish hint
 		b.WriteString(wtHintStyle.Render("Finish:  "))
 		b.WriteString(wtKeyStyle.Render("W"))
@@ -7541,146 +8175,7 @@ func (h *Home) renderPreviewPane(width, height int) string {
 			b.WriteString("\n")
 		}
 
-		// MCP servers - compact format with source indicators and sync status
-		mcpInfo := selected.GetMCPInfo()
-		hasLoadedMCPs := len(selected.LoadedMCPNames) > 0
-		hasMCPs := mcpInfo != nil && mcpInfo.HasAny()
-
-		if hasMCPs || hasLoadedMCPs {
-			b.WriteString(labelStyle.Render("MCPs:    "))
-
-			// Build set of loaded MCPs for comparison
-			loadedSet := make(map[string]bool)
-			for _, name := range selected.LoadedMCPNames {
-				loadedSet[name] = true
-			}
-
-			// Build set of current MCPs (from config)
-			currentSet := make(map[string]bool)
-			if mcpInfo != nil {
-				for _, name := range mcpInfo.Global {
-					currentSet[name] = true
-				}
-				for _, name := range mcpInfo.Project {
-					currentSet[name] = true
-				}
-				for _, mcp := range mcpInfo.LocalMCPs {
-					currentSet[mcp.Name] = true
-				}
-			}
-
-			// Styles for different MCP states
-			pendingStyle := lipgloss.NewStyle().Foreground(ColorYellow)
-			staleStyle := lipgloss.NewStyle().Foreground(ColorText)
-
-			var mcpParts []string
-
-			// Helper to add MCP with appropriate styling
-			addMCP := func(name, source string) {
-				label := name + " (" + source + ")"
-				if !hasLoadedMCPs {
-					// Old session without LoadedMCPNames - show all as normal (no sync info)
-					mcpParts = append(mcpParts, valueStyle.Render(label))
-				} else if loadedSet[name] {
-					// In both loaded and current - active (normal style)
-					mcpParts = append(mcpParts, valueStyle.Render(label))
-				} else {
-					// In current but not loaded - pending (needs restart)
-					mcpParts = append(mcpParts, pendingStyle.Render(label+" ⟳"))
-				}
-			}
-
-			// Add MCPs from current config with source indicators
-			if mcpInfo != nil {
-				for _, name := range mcpInfo.Global {
-					addMCP(name, "g")
-				}
-				for _, name := range mcpInfo.Project {
-					addMCP(name, "p")
-				}
-				for _, mcp := range mcpInfo.LocalMCPs {
-					// Show source path if different from project path
-					sourceIndicator := "l"
-					if mcp.SourcePath != selected.ProjectPath {
-						// Show abbreviated path (just directory name)
-						sourceIndicator = "l:" + filepath.Base(mcp.SourcePath)
-					}
-					addMCP(mcp.Name, sourceIndicator)
-				}
-			}
-
-			// Add stale MCPs (loaded but no longer in config)
-			if hasLoadedMCPs {
-				for _, name := range selected.LoadedMCPNames {
-					if !currentSet[name] {
-						// Still running but removed from config
-						mcpParts = append(mcpParts, staleStyle.Render(name+" ✕"))
-					}
-				}
-			}
-
-			// Calculate available width for MCPs (width - 4 for panel padding - 9 for "MCPs:    " label)
-			mcpMaxWidth := width - 4 - 9
-			if mcpMaxWidth < 20 {
-				mcpMaxWidth = 20 // Minimum sensible width
-			}
-
-			// Build MCPs progressively to fit within available width
-			var mcpResult strings.Builder
-			mcpCount := 0
-			currentWidth := 0
-
-			for i, part := range mcpParts {
-				// Strip ANSI codes to measure actual display width
-				plainPart := tmux.StripANSI(part)
-				partWidth := runewidth.StringWidth(plainPart)
-
-				// Calculate width including separator if not first
-				addedWidth := partWidth
-				if mcpCount > 0 {
-					addedWidth += 2 // ", " separator
-				}
-
-				remaining := len(mcpParts) - i
-				isLast := remaining == 1
-
-				// For non-last MCPs: reserve space for "+N more" indicator
-				// For last MCP: just check if it fits without indicator
-				var wouldExceed bool
-				if isLast {
-					// Last MCP - just check if it fits
-					wouldExceed = currentWidth+addedWidth > mcpMaxWidth
-				} else {
-					// Not last - check with indicator space reserved
-					moreIndicator := fmt.Sprintf(" (+%d more)", remaining)
-					moreWidth := runewidth.StringWidth(moreIndicator)
-					wouldExceed = currentWidth+addedWidth+moreWidth > mcpMaxWidth
-				}
-
-				if wouldExceed {
-					// Would exceed - show indicator for remaining
-					moreStyle := lipgloss.NewStyle().Foreground(ColorText).Italic(true)
-					if mcpCount > 0 {
-						mcpResult.WriteString(moreStyle.Render(fmt.Sprintf(" (+%d more)", remaining)))
-					} else {
-						// No MCPs fit - just show count
-						mcpResult.WriteString(moreStyle.Render(fmt.Sprintf("(%d MCPs)", len(mcpParts))))
-					}
-					break
-				}
-
-				// Add separator if not first
-				if mcpCount > 0 {
-					mcpResult.WriteString(", ")
-				}
-				mcpResult.WriteString(part)
-				currentWidth += addedWidth
-				mcpCount++
-			}
-
-			b.WriteString(mcpResult.String())
-			b.WriteString("\n")
-		}
+		renderSyncedMCPLine(&b, selected, width)
 
 		// Fork hint when session can be forked
 		if selected.CanFork() {
@@ -7786,6 +8281,7 @@ func (h *Home) renderPreviewPane(width, height int) string {
 				b.WriteString("\n")
 			}
 		}
+		renderSyncedMCPLine(&b, selected, width)
 	}
 
 	// Codex-specific info (session ID, detection)
@@ -7798,6 +8294,7 @@ func (h *Home) renderPreviewPane(width, height int) string {
 		if selected.CodexSessionID != "" {
 			renderDetectedAtLine(&b, selected.CodexDetectedAt)
 		}
+		renderSyncedMCPLine(&b, selected, width)
 	}
 
 	// Custom tool info (tools defined in config.toml that aren't built-in)
@@ -7899,9 +8396,37 @@ func (h *Home) renderPreviewPane(width, height int) string {
 		return content
 	}
 
+	// Git activity mode replaces analytics and output entirely
+	if h.previewMode == PreviewModeGit {
+		b.WriteString(renderSectionDivider("Git", width-4))
+		b.WriteString("\n")
+		if h.gitPanelSessionID == selected.ID {
+			used := strings.Count(b.String(), "\n")
+			h.gitPanel.SetSize(width-4, height-used)
+			b.WriteString(h.gitPanel.View())
+		} else {
+			b.WriteString(lipgloss.NewStyle().Foreground(ColorText).Italic(true).Render("Loading git activity..."))
+		}
+		b.WriteString("\n")
+
+		// Pad output to exact height to prevent layout shifts
+		content := b.String()
+		lines := strings.Split(content, "\n")
+		if len(lines) > height {
+			content = strings.Join(lines[:height], "\n")
+		}
+		for i := len(lines); i < height; i++ {
+			content += "\n"
+		}
+		if len(content) > 0 && content[len(content)-1] == '\n' {
+			content = content[:len(content)-1]
+		}
+		return content
+	}
+
 	// Check preview settings for what to show
 	config, _ := session.LoadUserConfig()
-	showAnalytics := config != nil && config.GetShowAnalytics() && (selected.Tool == "claude" || selected.Tool == "gemini")
+	showAnalytics := config != nil && config.GetShowAnalytics() && session.ToolHasAnalytics(selected.Tool)
 	showOutput := config == nil || config.GetShowOutput() // Default to true if config fails
 
 	// Apply preview mode override (v key cycles through modes)
@@ -7910,7 +8435,7 @@ func (h *Home) renderPreviewPane(width, height int) string {
 		showAnalytics = false
 		showOutput = true
 	case PreviewModeAnalytics:
-		// showAnalytics keeps its default value (only available for Claude/Gemini)
+		// showAnalytics keeps its default value (only available for tools with analytics)
 		showOutput = false
 		// PreviewModeBoth: use config settings (default)
 	}
@@ -7921,7 +8446,7 @@ func (h *Home) renderPreviewPane(width, height int) string {
 	_, isSessionForking := h.forkingSessions[selected.ID]
 	isStartingUp := isSessionLaunching || isSessionResuming || isSessionForking
 
-	// Analytics panel (for Claude/Gemini sessions with analytics enabled)
+	// Analytics panel (for sessions whose tool has analytics, when enabled)
 	// Skip showing "Loading analytics..." during startup - let the launch animation take focus
 	if showAnalytics && !isStartingUp {
 		analyticsHeader := renderSectionDivider("Analytics", width-4)
@@ -7929,7 +8454,7 @@ func (h *Home) renderPreviewPane(width, height int) string {
 		b.WriteString("\n")
 
 		// Check if we have analytics for this session
-		if h.analyticsSessionID == selected.ID && (h.currentAnalytics != nil || h.currentGeminiAnalytics != nil) {
+		if h.analyticsSessionID == selected.ID && h.currentAnalytics != nil {
 			// Pass display settings from config
 			if config != nil {
 				h.analyticsPanel.SetDisplaySettings(config.Preview.GetAnalyticsSettings())
@@ -8588,6 +9113,7 @@ func (h *Home) handleWorktreeFinishDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd
 	case "confirm":
 		// Execute the finish operation
 		mergeEnabled, targetBranch, keepBranch := h.worktreeFinishDialog.GetOptions()
+		strategy := h.worktreeFinishDialog.GetStrategy()
 		h.worktreeFinishDialog.SetExecuting(true)
 
 		sid := h.worktreeFinishDialog.sessionID
@@ -8601,7 +9127,20 @@ func (h *Home) handleWorktreeFinishDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd
 		inst := h.instanceByID[sid]
 		h.instancesMu.RUnlock()
 
-		return h, h.finishWorktree(inst, sid, sTitle, branch, repoRoot, wtPath, mergeEnabled, targetBranch, keepBranch)
+		return h, h.finishWorktree(inst, sid, sTitle, branch, repoRoot, wtPath, mergeEnabled, targetBranch, strategy, keepBranch)
+
+	case "handoff":
+		conflict := h.worktreeFinishDialog.GetConflict()
+		sid := h.worktreeFinishDialog.sessionID
+		h.worktreeFinishDialog.Hide()
+
+		h.instancesMu.RLock()
+		inst := h.instanceByID[sid]
+		h.instancesMu.RUnlock()
+		if inst == nil || conflict == nil {
+			return h, nil
+		}
+		return h, h.handoffWorktreeConflict(inst, conflict)
 
 	case "input":
 		// Pass through to text input
@@ -8613,32 +9152,25 @@ func (h *Home) handleWorktreeFinishDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd
 }
 
 // finishWorktree performs the worktree finish operation asynchronously:
-// merge branch, remove worktree, delete branch, kill session, remove from storage
-func (h *Home) finishWorktree(inst *session.Instance, sessionID, sessionTitle, branchName, repoRoot, worktreePath string, mergeEnabled bool, targetBranch string, keepBranch bool) tea.Cmd {
+// merge branch, remove worktree, delete branch, kill session, remove from storage.
+// The merge is checked with a merge-tree pre-flight first, so a conflict is
+// reported before anything in the main worktree changes.
+func (h *Home) finishWorktree(inst *session.Instance, sessionID, sessionTitle, branchName, repoRoot, worktreePath string, mergeEnabled bool, targetBranch string, strategy git.MergeStrategy, keepBranch bool) tea.Cmd {
 	return func() tea.Msg {
 		merged := false
 
 		// Step 1: Merge (if requested)
 		if mergeEnabled {
-			// Checkout target branch in main repo
-			cmd := exec.Command("git", "-C", repoRoot, "checkout", targetBranch)
-			checkoutOutput, err := cmd.CombinedOutput()
-			if err != nil {
-				return worktreeFinishResultMsg{
-					sessionID: sessionID, sessionTitle: sessionTitle,
-					err: fmt.Errorf("failed to checkout %s: %s", targetBranch, strings.TrimSpace(string(checkoutOutput))),
-				}
+			err := git.PreflightFinish(repoRoot, targetBranch, branchName, strategy)
+			if err == nil {
+				err = git.FinishBranch(repoRoot, worktreePath, targetBranch, branchName, strategy)
 			}
-
-			// Merge the worktree branch
-			if err := git.MergeBranch(repoRoot, branchName); err != nil {
-				// Abort the merge to leave things clean
-				abortCmd := exec.Command("git", "-C", repoRoot, "merge", "--abort")
-				_ = abortCmd.Run()
-				return worktreeFinishResultMsg{
-					sessionID: sessionID, sessionTitle: sessionTitle,
-					err: fmt.Errorf("merge failed (aborted): %v", err),
-				}
+			var conflict *git.MergeConflictError
+			if errors.As(err, &conflict) {
+				return worktreeFinishResultMsg{sessionID: sessionID, sessionTitle: sessionTitle, conflict: conflict}
+			}
+			if err != nil {
+				return worktreeFinishResultMsg{sessionID: sessionID, sessionTitle: sessionTitle, err: err}
 			}
 			merged = true
 		}
@@ -8669,6 +9201,105 @@ func (h *Home) finishWorktree(inst *session.Instance, sessionID, sessionTitle, b
 	}
 }
 
+// fetchGitActivity collects git activity for the session's project in the
+// background. Commits are limited to those made since the session started.
+func (h *Home) fetchGitActivity(inst *session.Instance, status session.Status) tea.Cmd {
+	sessionID := inst.ID
+	dir := inst.ProjectPath
+	if inst.IsWorktree() && inst.WorktreePath != "" {
+		dir = inst.WorktreePath
+	}
+	since := inst.CreatedAt
+	return func() tea.Msg {
+		if !git.IsGitRepo(dir) {
+			return gitActivityMsg{sessionID: sessionID, status: status, err: fmt.Errorf("%s is not a git repository", dir)}
+		}
+		activity, err := git.CollectActivity(dir, since)
+		return gitActivityMsg{sessionID: sessionID, status: status, activity: activity, err: err}
+	}
+}
+
+// conflictScanInterval is how often the conflict radar re-diffs worktrees.
+const conflictScanInterval = time.Minute
+
+// scanWorktreeConflicts runs the conflict radar in the background. When
+// [worktree] notify_conflicts is set, new overlaps are reported to the
+// sessions' parents or conductor once per file set.
+func (h *Home) scanWorktreeConflicts() tea.Cmd {
+	h.instancesMu.RLock()
+	instances := make([]*session.Instance, len(h.instances))
+	copy(instances, h.instances)
+	h.instancesMu.RUnlock()
+
+	alreadyNotified := make(map[string]bool, len(h.notifiedConflicts))
+	for k := range h.notifiedConflicts {
+		alreadyNotified[k] = true
+	}
+	profile := h.profile
+
+	return func() tea.Msg {
+		conflicts := session.FindWorktreeConflicts(instances)
+		var notified []string
+		if session.GetWorktreeSettings().NotifyConflicts {
+			for _, c := range conflicts {
+				if alreadyNotified[c.Key()] {
+					continue
+				}
+				if _, err := session.NotifyWorktreeConflict(profile, c, instances); err == nil {
+					notified = append(notified, c.Key())
+				}
+			}
+		}
+		return worktreeConflictsMsg{conflicts: conflicts, notified: notified}
+	}
+}
+
+// refreshPullRequest reloads cached PR records. When inst is set and has a
+// PR record, its state and CI checks are first refreshed from the forge.
+func (h *Home) refreshPullRequest(inst *session.Instance) tea.Cmd {
+	profile := h.profile
+	var sessionID, repoRoot string
+	if inst != nil {
+		sessionID, repoRoot = inst.ID, inst.WorktreeRepoRoot
+	}
+	return func() tea.Msg {
+		records, err := forge.LoadRecords(profile)
+		if err != nil {
+			return pullRequestsMsg{}
+		}
+		rec, ok := records[sessionID]
+		if !ok || repoRoot == "" {
+			return pullRequestsMsg{records: records}
+		}
+		f, _, err := forge.ForRepo(repoRoot, rec.GitRemote())
+		if err != nil {
+			return pullRequestsMsg{records: records}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if refreshed, err := forge.Refresh(ctx, f, rec); err == nil {
+			records[sessionID] = refreshed
+			_ = forge.SaveRecord(profile, refreshed)
+		}
+		return pullRequestsMsg{records: records}
+	}
+}
+
+// handoffWorktreeConflict asks the session that owns the branch to resolve
+// the merge conflicts in its own worktree.
+func (h *Home) handoffWorktreeConflict(inst *session.Instance, conflict *git.MergeConflictError) tea.Cmd {
+	return func() tea.Msg {
+		tmuxSession := inst.GetTmuxSession()
+		if tmuxSession == nil {
+			return worktreeHandoffResultMsg{sessionTitle: inst.Title, err: fmt.Errorf("session has no tmux pane")}
+		}
+		if err := tmuxSession.SendKeysAndEnter(git.ConflictHandoffPrompt(conflict)); err != nil {
+			return worktreeHandoffResultMsg{sessionTitle: inst.Title, err: err}
+		}
+		return worktreeHandoffResultMsg{sessionTitle: inst.Title, fileCount: len(conflict.Files)}
+	}
+}
+
 // getOtherActiveSessions returns sessions excluding the given ID and error-status sessions.
 func (h *Home) getOtherActiveSessions(excludeID string) []*session.Instance {
 	var result []*session.Instance
@@ -197,6 +197,48 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 				})
 			}
 		}
+	} else if tool == "codex" || tool == "opencode" {
+		// Codex/OpenCode: LOCAL (session CODEX_HOME / project opencode.json) and GLOBAL
+		var mcpInfo *session.MCPInfo
+		if tool == "codex" {
+			mcpInfo = session.GetCodexMCPInfo(sessionID, projectPath)
+		} else {
+			mcpInfo = session.GetOpenCodeMCPInfo(projectPath)
+		}
+		localAttachedNames := make(map[string]bool)
+		for _, name := range mcpInfo.Local() {
+			localAttachedNames[name] = true
+		}
+		globalAttachedNames := make(map[string]bool)
+		for _, name := range mcpInfo.Global {
+			globalAttachedNames[name] = true
+		}
+
+		for _, name := range allNames {
+			item := itemsMap[name]
+			if localAttachedNames[name] {
+				m.localAttached = append(m.localAttached, item)
+			} else if !globalAttachedNames[name] {
+				m.localAvailable = append(m.localAvailable, item)
+			}
+			if globalAttachedNames[name] {
+				m.globalAttached = append(m.globalAttached, item)
+			} else {
+				m.globalAvailable = append(m.globalAvailable, item)
+			}
+		}
+
+		// Orphans: attached in the tool's config but not in config.toml pool
+		for _, name := range mcpInfo.Local() {
+			if !poolNames[name] {
+				m.localAttached = append(m.localAttached, MCPItem{Name: name, Description: "(not in config.toml)", IsOrphan: true})
+			}
+		}
+		for _, name := range mcpInfo.Global {
+			if !poolNames[name] {
+				m.globalAttached = append(m.globalAttached, MCPItem{Name: name, Description: "(not in config.toml)", IsOrphan: true})
+			}
+		}
 	} else {
 		// Claude: Load LOCAL attached from .mcp.json
 		localAttachedNames := make(map[string]bool)
@@ -289,9 +331,15 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 
 	m.visible = true
 	m.projectPath = projectPath
-	// Gemini only has global scope, Claude uses configured default
+	// Gemini only has global scope, the others use the configured default
+	// (Codex and OpenCode have no USER scope, so "user" means their global)
 	if tool == "gemini" {
 		m.scope = MCPScopeGlobal
+	} else if !m.hasUserScope() {
+		m.scope = MCPScopeLocal
+		if scope := session.GetMCPDefaultScope(); scope == "global" || scope == "user" {
+			m.scope = MCPScopeGlobal
+		}
 	} else {
 		switch session.GetMCPDefaultScope() {
 		case "global":
@@ -319,6 +367,11 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 	return nil
 }
 
+// hasUserScope reports whether the tool has a USER scope (Claude's ~/.claude.json)
+func (m *MCPDialog) hasUserScope() bool {
+	return m.tool != "gemini" && m.tool != "codex" && m.tool != "opencode"
+}
+
 // Hide hides the dialog
 func (m *MCPDialog) Hide() {
 	m.visible = false
@@ -531,6 +584,10 @@ func (m *MCPDialog) Apply() error {
 		return nil
 	}
 
+	if m.tool == "codex" || m.tool == "opencode" {
+		return m.applyCodexOpenCode()
+	}
+
 	// Claude: Apply LOCAL changes
 	if m.localChanged {
 		// Get names of attached MCPs
@@ -595,19 +652,61 @@ func (m *MCPDialog) Apply() error {
 	return nil
 }
 
+// applyCodexOpenCode writes LOCAL and GLOBAL changes for Codex (session
+// CODEX_HOME, ~/.codex/config.toml) or OpenCode (project and global opencode.json)
+func (m *MCPDialog) applyCodexOpenCode() error {
+	names := func(items []MCPItem) []string {
+		out := make([]string, len(items))
+		for i, item := range items {
+			out[i] = item.Name
+		}
+		return out
+	}
+
+	if m.localChanged {
+		var err error
+		if m.tool == "codex" {
+			err = session.WriteCodexSessionMCP(m.sessionID, names(m.localAttached))
+		} else {
+			err = session.WriteOpenCodeLocalMCP(m.projectPath, names(m.localAttached))
+		}
+		if err != nil {
+			m.err = err
+			return err
+		}
+	}
+	if m.globalChanged {
+		var err error
+		if m.tool == "codex" {
+			err = session.WriteCodexGlobalMCP(names(m.globalAttached))
+		} else {
+			err = session.WriteOpenCodeGlobalMCP(names(m.globalAttached))
+		}
+		if err != nil {
+			m.err = err
+			return err
+		}
+	}
+	return nil
+}
+
 // Update handles input
 func (m *MCPDialog) Update(msg tea.KeyMsg) (*MCPDialog, tea.Cmd) {
 	list, idx := m.getCurrentList()
 
 	switch msg.String() {
 	case "tab":
-		// Switch scope: LOCAL -> GLOBAL -> USER -> LOCAL (Claude only)
+		// Switch scope: LOCAL -> GLOBAL -> USER -> LOCAL (USER is Claude only)
 		// Gemini only has global scope, so Tab does nothing
 		if m.tool != "gemini" {
 			switch m.scope {
 			case MCPScopeLocal:
 				m.scope = MCPScopeGlobal
 			case MCPScopeGlobal:
+				if !m.hasUserScope() {
+					m.scope = MCPScopeLocal
+					break
+				}
 				m.scope = MCPScopeUser
 			case MCPScopeUser:
 				m.scope = MCPScopeLocal
@@ -658,8 +757,13 @@ func (m *MCPDialog) View() string {
 
 	// Title varies by tool
 	title := "MCP Manager"
-	if m.tool == "gemini" {
+	switch m.tool {
+	case "gemini":
 		title = "MCP Manager (Gemini)"
+	case "codex":
+		title = "MCP Manager (Codex)"
+	case "opencode":
+		title = "MCP Manager (OpenCode)"
 	}
 
 	// Scope tabs - Gemini only has global
@@ -696,7 +800,10 @@ func (m *MCPDialog) View() string {
 			userTab = "[" + userTab + "]"
 		}
 
-		tabs = localStyle.Render(localTab) + " ─── " + globalStyle.Render(globalTab) + " ─── " + userStyle.Render(userTab)
+		tabs = localStyle.Render(localTab) + " ─── " + globalStyle.Render(globalTab)
+		if m.hasUserScope() {
+			tabs += " ─── " + userStyle.Render(userTab)
+		}
 	}
 
 	// Get current scope's lists
@@ -728,9 +835,18 @@ func (m *MCPDialog) View() string {
 
 	// Scope description
 	var scopeDesc string
-	if m.tool == "gemini" {
+	switch {
+	case m.tool == "gemini":
 		scopeDesc = DimStyle.Render("Writes to: ~/.gemini/settings.json")
-	} else {
+	case m.tool == "codex" && m.scope == MCPScopeLocal:
+		scopeDesc = DimStyle.Render("Writes to: session CODEX_HOME (this session only)")
+	case m.tool == "codex":
+		scopeDesc = DimStyle.Render("Writes to: ~/.codex/config.toml (all Codex sessions)")
+	case m.tool == "opencode" && m.scope == MCPScopeLocal:
+		scopeDesc = DimStyle.Render("Writes to: opencode.json (this project only)")
+	case m.tool == "opencode":
+		scopeDesc = DimStyle.Render("Writes to: ~/.config/opencode/opencode.json")
+	default:
 		switch m.scope {
 		case MCPScopeLocal:
 			if !session.GetManageMCPJson() {
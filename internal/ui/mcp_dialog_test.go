@@ -75,3 +75,29 @@ func TestMCPDialog_TypeJumpResetOnScopeSwitch(t *testing.T) {
 		t.Fatalf("expected jump in global list to zeta (index 0), got %d", dialog.globalAvailableIdx)
 	}
 }
+
+func TestMCPDialog_CodexOpenCodeHaveNoUserScope(t *testing.T) {
+	for _, tool := range []string{"codex", "opencode"} {
+		dialog := NewMCPDialog()
+		dialog.visible = true
+		dialog.tool = tool
+		dialog.scope = MCPScopeLocal
+
+		_, _ = dialog.Update(tea.KeyMsg{Type: tea.KeyTab})
+		if dialog.scope != MCPScopeGlobal {
+			t.Fatalf("%s: tab from LOCAL should go to GLOBAL", tool)
+		}
+		_, _ = dialog.Update(tea.KeyMsg{Type: tea.KeyTab})
+		if dialog.scope != MCPScopeLocal {
+			t.Fatalf("%s: tab from GLOBAL should wrap to LOCAL, got scope %d", tool, dialog.scope)
+		}
+	}
+
+	claude := NewMCPDialog()
+	claude.tool = "claude"
+	claude.scope = MCPScopeGlobal
+	_, _ = claude.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if claude.scope != MCPScopeUser {
+		t.Error("claude keeps its USER scope")
+	}
+}
@@ -19,7 +19,7 @@ type NewDialog struct {
 	commandInput         textinput.Model
 	claudeOptions        *ClaudeOptionsPanel // Claude-specific options (concrete for value extraction)
 	geminiOptions        *YoloOptionsPanel   // Gemini YOLO panel (concrete for value extraction)
-	codexOptions         *YoloOptionsPanel   // Codex YOLO panel (concrete for value extraction)
+	codexOptions         *CodexOptionsPanel  // Codex options (concrete for value extraction)
 	toolOptions          OptionsPanel        // Currently active tool options panel (nil if none)
 	focusIndex           int                 // 0=name, 1=path, 2=command, 3+=options
 	width                int
@@ -39,6 +39,9 @@ type NewDialog struct {
 	// Inline validation error displayed inside the dialog
 	validationErr string
 	pathCycler    session.CompletionCycler // Path autocomplete state
+	// Saved session templates; templateCursor 0 means none, i means templates[i-1]
+	templates      []session.SessionTemplate
+	templateCursor int
 }
 
 // buildPresetCommands returns the list of commands for the picker,
@@ -92,7 +95,7 @@ func NewNewDialog() *NewDialog {
 		branchInput:     branchInput,
 		claudeOptions:   NewClaudeOptionsPanel(),
 		geminiOptions:   NewYoloOptionsPanel("Gemini", "YOLO mode - auto-approve all"),
-		codexOptions:    NewYoloOptionsPanel("Codex", "YOLO mode - bypass approvals and sandbox"),
+		codexOptions:    NewCodexOptionsPanel(),
 		focusIndex:      0,
 		visible:         false,
 		presetCommands:  buildPresetCommands(),
@@ -131,6 +134,7 @@ func (d *NewDialog) ShowInGroup(groupPath, groupName, defaultPath string) {
 	d.worktreeEnabled = false
 	d.branchInput.SetValue("")
 	d.branchAutoSet = false
+	d.templateCursor = 0
 	// Set path input to group's default path if provided, otherwise use current working directory
 	if defaultPath != "" {
 		d.pathInput.SetValue(defaultPath)
@@ -142,10 +146,10 @@ func (d *NewDialog) ShowInGroup(groupPath, groupName, defaultPath string) {
 	}
 	// Initialize tool options from global config
 	d.geminiOptions.SetDefaults(false)
-	d.codexOptions.SetDefaults(false)
+	d.codexOptions.SetDefaults(nil)
 	if userConfig, err := session.LoadUserConfig(); err == nil && userConfig != nil {
 		d.geminiOptions.SetDefaults(userConfig.Gemini.YoloMode)
-		d.codexOptions.SetDefaults(userConfig.Codex.YoloMode)
+		d.codexOptions.SetDefaults(session.NewCodexOptionsForGroup(userConfig, groupPath))
 		d.claudeOptions.SetDefaults(userConfig)
 	}
 }
@@ -172,6 +176,93 @@ func (d *NewDialog) SetDefaultTool(tool string) {
 	d.updateToolOptions()
 }
 
+// SetTemplates sets the saved templates offered by the picker (ctrl+t).
+// Call this before Show/ShowInGroup.
+func (d *NewDialog) SetTemplates(templates []session.SessionTemplate) {
+	d.templates = templates
+	d.templateCursor = 0
+}
+
+// GetSelectedTemplate returns the template picked in the dialog, or nil.
+func (d *NewDialog) GetSelectedTemplate() *session.SessionTemplate {
+	if d.templateCursor <= 0 || d.templateCursor > len(d.templates) {
+		return nil
+	}
+	t := d.templates[d.templateCursor-1]
+	return &t
+}
+
+// cycleTemplate selects the next template (wrapping back to none) and
+// prefills the dialog from it.
+func (d *NewDialog) cycleTemplate() {
+	if len(d.templates) == 0 {
+		return
+	}
+	d.templateCursor = (d.templateCursor + 1) % (len(d.templates) + 1)
+	if t := d.GetSelectedTemplate(); t != nil {
+		d.applyTemplate(t)
+	}
+}
+
+// applyTemplate fills in command, path, worktree, name and tool options from
+// t. Fields the template doesn't set keep their current values.
+func (d *NewDialog) applyTemplate(t *session.SessionTemplate) {
+	if t.Path != "" {
+		d.pathInput.SetValue(t.Path)
+	}
+
+	d.commandCursor = 0
+	d.commandInput.SetValue("")
+	found := false
+	for i, cmd := range d.presetCommands {
+		if cmd != "" && cmd == t.Tool {
+			d.commandCursor = i
+			found = true
+			break
+		}
+	}
+	if !found && t.Command != "" {
+		d.commandInput.SetValue(t.Command)
+	}
+	d.updateToolOptions()
+
+	switch t.Tool {
+	case "claude":
+		if opts, err := session.UnmarshalClaudeOptions(t.ToolOptions); err == nil && opts != nil {
+			d.claudeOptions.SetOptions(opts)
+		}
+	case "codex":
+		if opts, err := session.UnmarshalCodexOptions(t.ToolOptions); err == nil && opts != nil {
+			// Template values win over the group/global defaults already shown
+			d.codexOptions.SetDefaults(d.codexOptions.GetOptions().Merge(opts))
+		}
+	case "gemini":
+		if t.GeminiYoloMode != nil {
+			d.geminiOptions.SetDefaults(*t.GeminiYoloMode)
+		}
+	}
+
+	_, path, _ := d.GetValues()
+	name := strings.TrimSpace(d.nameInput.Value())
+	if name == "" && t.Title != "" {
+		name, _ = t.ResolveNames(path, "", "")
+		d.nameInput.SetValue(name)
+		d.nameInput.SetCursor(len(name))
+	}
+
+	d.worktreeEnabled = t.Worktree
+	d.branchInput.SetValue("")
+	d.branchAutoSet = false
+	if d.worktreeEnabled {
+		d.autoBranchFromName()
+		d.branchAutoSet = true // keep following the name as it's typed
+	}
+	if d.focusIndex > d.getMaxFocusIndex() {
+		d.focusIndex = d.getMaxFocusIndex()
+	}
+	d.updateFocus()
+}
+
 // GetSelectedGroup returns the parent group path
 func (d *NewDialog) GetSelectedGroup() string {
 	return d.parentGroupPath
@@ -240,14 +331,19 @@ func (d *NewDialog) ToggleWorktree() {
 	}
 }
 
-// autoBranchFromName sets the branch input to "feature/<session-name>" if the
-// name field is non-empty and the branch hasn't been manually edited.
+// autoBranchFromName sets the branch input to "feature/<session-name>" (or
+// the selected template's branch pattern) if the name field is non-empty and
+// the branch hasn't been manually edited.
 func (d *NewDialog) autoBranchFromName() {
 	name := strings.TrimSpace(d.nameInput.Value())
 	if name == "" {
 		return
 	}
 	branch := "feature/" + name
+	if t := d.GetSelectedTemplate(); t != nil && t.Worktree {
+		_, path, _ := d.GetValues()
+		_, branch = t.ResolveNames(path, name, "")
+	}
 	d.branchInput.SetValue(branch)
 	d.branchAutoSet = true
 }
@@ -270,9 +366,9 @@ func (d *NewDialog) IsGeminiYoloMode() bool {
 	return d.geminiOptions.GetYoloMode()
 }
 
-// GetCodexYoloMode returns the Codex YOLO mode state
-func (d *NewDialog) GetCodexYoloMode() bool {
-	return d.codexOptions.GetYoloMode()
+// GetCodexOptions returns the Codex-specific options (only relevant if command is "codex")
+func (d *NewDialog) GetCodexOptions() *session.CodexOptions {
+	return d.codexOptions.GetOptions()
 }
 
 // GetSelectedCommand returns the currently selected command/tool
@@ -516,6 +612,10 @@ func (d *NewDialog) Update(msg tea.Msg) (*NewDialog, tea.Cmd) {
 			// Let parent handle enter (create session)
 			return d, nil
 
+		case "ctrl+t":
+			d.cycleTemplate()
+			return d, nil
+
 		case "left":
 			if d.focusIndex == 2 {
 				d.commandCursor--
@@ -660,7 +760,16 @@ func (d *NewDialog) View() string {
 	content.WriteString("\n")
 	groupInfoStyle := lipgloss.NewStyle().Foreground(ColorPurple) // Purple for group context
 	content.WriteString(groupInfoStyle.Render("  in group: " + d.parentGroupName))
-	content.WriteString("\n\n")
+	content.WriteString("\n")
+	if len(d.templates) > 0 {
+		templateName := "none"
+		if t := d.GetSelectedTemplate(); t != nil {
+			templateName = t.Name
+		}
+		content.WriteString(groupInfoStyle.Render(fmt.Sprintf("  template: %s (^T %d/%d)", templateName, d.templateCursor, len(d.templates))))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
 
 	// Name input
 	if d.focusIndex == 0 {
@@ -839,7 +948,9 @@ func (d *NewDialog) View() string {
 		Foreground(ColorComment). // Use consistent theme color
 		MarginTop(1)
 	helpText := "Tab next/accept │ ↑↓ navigate │ Enter create │ Esc cancel"
-	if d.focusIndex == 1 {
+	if len(d.templates) > 0 && d.focusIndex == 0 {
+		helpText = "^T template │ Tab next │ ↑↓ navigate │ Enter create │ Esc cancel"
+	} else if d.focusIndex == 1 {
 		helpText = "Tab autocomplete │ ^N/^P recent │ ↑↓ navigate │ Enter create │ Esc cancel"
 	} else if d.focusIndex == 2 {
 		selectedCmd := d.GetSelectedCommand()
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/asheshgoplani/agent-deck/internal/session"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -777,3 +778,38 @@ func TestNewDialog_ShowInGroup_ResetsBranchAutoSet(t *testing.T) {
 		t.Error("branchAutoSet should be reset to false on ShowInGroup")
 	}
 }
+
+func TestNewDialog_TemplatePicker(t *testing.T) {
+	d := NewNewDialog()
+	d.SetTemplates([]session.SessionTemplate{
+		{Name: "reviewer", Tool: "claude", Path: "/tmp", Worktree: true, Branch: "review/{title}"},
+	})
+	d.Show()
+	d.nameInput.SetValue("login")
+
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	tmpl := d.GetSelectedTemplate()
+	if tmpl == nil || tmpl.Name != "reviewer" {
+		t.Fatalf("selected template = %v, want reviewer", tmpl)
+	}
+	name, path, command, branch, worktree := d.GetValuesWithWorktree()
+	if name != "login" || path != "/tmp" || command != "claude" {
+		t.Errorf("values = %q %q %q, want login /tmp claude", name, path, command)
+	}
+	if !worktree || branch != "review/login" {
+		t.Errorf("worktree = %v branch = %q, want true review/login", worktree, branch)
+	}
+
+	// Typing the name keeps the template's branch pattern
+	d.nameInput.SetValue("logi")
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n2")})
+	if got := strings.TrimSpace(d.branchInput.Value()); got != "review/login2" {
+		t.Errorf("branch after typing = %q, want review/login2", got)
+	}
+
+	// Cycling wraps back to no template
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if d.GetSelectedTemplate() != nil {
+		t.Error("expected no template after cycling past the last one")
+	}
+}
@@ -3,7 +3,7 @@ package ui
 import tea "github.com/charmbracelet/bubbletea"
 
 // OptionsPanel is the interface for tool-specific option panels in session dialogs.
-// Implemented by ClaudeOptionsPanel and YoloOptionsPanel.
+// Implemented by ClaudeOptionsPanel, CodexOptionsPanel and YoloOptionsPanel.
 type OptionsPanel interface {
 	Focus()
 	Blur()
@@ -0,0 +1,322 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// paletteUse is the usage record behind recent/frequent palette commands
+type paletteUse struct {
+	Count int       `json:"count"`
+	Last  time.Time `json:"last"`
+}
+
+// paletteGroupSize is how many recent and frequent commands are listed first
+const paletteGroupSize = 5
+
+// openCommandPalette lists every action available in the current context,
+// recent and frequent ones first.
+func (h *Home) openCommandPalette() (tea.Model, tea.Cmd) {
+	if h.paletteHistory == nil {
+		h.paletteHistory = h.loadPaletteHistory()
+	}
+	h.commandPalette.SetSize(h.width, h.height)
+	h.commandPalette.Show(h.paletteEntries())
+	return h, nil
+}
+
+// paletteEntries orders available actions: recent, frequent, then registry order
+func (h *Home) paletteEntries() []paletteEntry {
+	var available []*Action
+	for _, a := range h.keyMap.Actions() {
+		if a.ID == "" || a.NoPalette || (a.Available != nil && !a.Available(h)) {
+			continue
+		}
+		available = append(available, a)
+	}
+
+	entry := func(a *Action, group string) paletteEntry {
+		return paletteEntry{action: a, label: a.Title, key: h.keyMap.Label(a), group: group}
+	}
+	used := make(map[string]bool)
+	var entries []paletteEntry
+
+	byUse := func(less func(a, b paletteUse) bool) []*Action {
+		var list []*Action
+		for _, a := range available {
+			if _, ok := h.paletteHistory[a.ID]; ok && !used[a.ID] {
+				list = append(list, a)
+			}
+		}
+		sort.SliceStable(list, func(i, j int) bool {
+			return less(h.paletteHistory[list[i].ID], h.paletteHistory[list[j].ID])
+		})
+		if len(list) > paletteGroupSize {
+			list = list[:paletteGroupSize]
+		}
+		return list
+	}
+	for _, a := range byUse(func(a, b paletteUse) bool { return a.Last.After(b.Last) }) {
+		entries = append(entries, entry(a, "Recent"))
+		used[a.ID] = true
+	}
+	for _, a := range byUse(func(a, b paletteUse) bool { return a.Count > b.Count }) {
+		if h.paletteHistory[a.ID].Count < 2 {
+			continue // Once isn't frequent
+		}
+		entries = append(entries, entry(a, "Frequent"))
+		used[a.ID] = true
+	}
+	group := ""
+	if len(entries) > 0 {
+		group = "All commands"
+	}
+	for _, a := range available {
+		if !used[a.ID] {
+			entries = append(entries, entry(a, group))
+		}
+	}
+	return entries
+}
+
+// handleCommandPaletteKey handles keys while the palette is visible
+func (h *Home) handleCommandPaletteKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := h.commandPalette
+	switch msg.String() {
+	case "esc":
+		if p.argAction != nil {
+			p.Show(h.paletteEntries())
+			return h, nil
+		}
+		p.Hide()
+		return h, nil
+	case "ctrl+c":
+		p.Hide()
+		return h, nil
+	case "enter":
+		e := p.Selected()
+		if e == nil {
+			return h, nil
+		}
+		if e.arg != nil {
+			p.Hide()
+			return e.action.RunArg(h, *e.arg)
+		}
+		h.recordPaletteUse(e.action.ID)
+		p.Hide()
+		return h.runAction(e.action)
+	}
+	var cmd tea.Cmd
+	h.commandPalette, cmd = p.Update(msg)
+	return h, cmd
+}
+
+// runAction executes an action as if its default key was pressed, or asks
+// for its argument in the palette.
+func (h *Home) runAction(a *Action) (tea.Model, tea.Cmd) {
+	switch {
+	case a.Args != nil:
+		args := a.Args(h)
+		if len(args) == 0 {
+			h.setError(fmt.Errorf("%s: nothing to choose from", a.Title))
+			return h, nil
+		}
+		h.commandPalette.SetSize(h.width, h.height)
+		h.commandPalette.ShowArgs(a, args)
+		return h, nil
+	case a.Run != nil:
+		return a.Run(h)
+	case len(a.Keys) > 0:
+		return h.runMainKey(a.Keys[0])
+	}
+	return h, nil
+}
+
+// recordPaletteUse bumps an action's usage and persists the history
+func (h *Home) recordPaletteUse(id string) {
+	u := h.paletteHistory[id]
+	u.Count++
+	u.Last = time.Now()
+	h.paletteHistory[id] = u
+	h.savePaletteHistory()
+}
+
+// savePaletteHistory persists palette usage in the profile's state database
+func (h *Home) savePaletteHistory() {
+	if h.storage == nil {
+		return
+	}
+	db := h.storage.GetDB()
+	if db == nil {
+		return
+	}
+	data, err := json.Marshal(h.paletteHistory)
+	if err != nil {
+		uiLog.Warn("save_palette_history_marshal_failed", slog.String("error", err.Error()))
+		return
+	}
+	if err := db.SetMeta("palette_history", string(data)); err != nil {
+		uiLog.Warn("save_palette_history_failed", slog.String("error", err.Error()))
+	}
+}
+
+// loadPaletteHistory reads palette usage, or an empty history
+func (h *Home) loadPaletteHistory() map[string]paletteUse {
+	history := make(map[string]paletteUse)
+	if h.storage == nil {
+		return history
+	}
+	db := h.storage.GetDB()
+	if db == nil {
+		return history
+	}
+	val, err := db.GetMeta("palette_history")
+	if err != nil || val == "" {
+		return history
+	}
+	if err := json.Unmarshal([]byte(val), &history); err != nil {
+		uiLog.Warn("load_palette_history_unmarshal_failed", slog.String("error", err.Error()))
+		return make(map[string]paletteUse)
+	}
+	return history
+}
+
+// actionTargets returns the multi-selection, or the session under the cursor
+func (h *Home) actionTargets() []*session.Instance {
+	if targets := h.selectedSessions(); len(targets) > 0 {
+		return targets
+	}
+	if inst := h.getSelectedSession(); inst != nil {
+		return []*session.Instance{inst}
+	}
+	return nil
+}
+
+// Parameterized actions
+
+func gotoSessionArgs(h *Home) []PaletteArg {
+	h.instancesMu.RLock()
+	defer h.instancesMu.RUnlock()
+	args := make([]PaletteArg, 0, len(h.instances))
+	for _, inst := range h.instances {
+		label := inst.Title
+		if inst.GroupPath != "" {
+			label += "  (" + inst.GroupPath + ")"
+		}
+		args = append(args, PaletteArg{Label: label, Value: inst.ID})
+	}
+	return args
+}
+
+func runGotoSession(h *Home, arg PaletteArg) (tea.Model, tea.Cmd) {
+	inst := h.getInstanceByID(arg.Value)
+	if inst == nil {
+		return h, nil
+	}
+	// A status filter could hide the session
+	if h.statusFilter != "" && inst.Status != h.statusFilter {
+		h.statusFilter = ""
+	}
+	if inst.GroupPath != "" {
+		h.groupTree.ExpandGroupWithParents(inst.GroupPath)
+	}
+	h.rebuildFlatItems()
+	for i, item := range h.flatItems {
+		if item.Type == session.ItemTypeSession && item.Session != nil && item.Session.ID == inst.ID {
+			h.cursor = i
+			h.syncViewport()
+			return h, h.fetchPreview(inst)
+		}
+	}
+	return h, nil
+}
+
+func groupArgs(h *Home) []PaletteArg {
+	args := make([]PaletteArg, 0, len(h.groupTree.GroupList))
+	for _, g := range h.groupTree.GroupList {
+		args = append(args, PaletteArg{Label: g.Path, Value: g.Path})
+	}
+	return args
+}
+
+func runGotoGroup(h *Home, arg PaletteArg) (tea.Model, tea.Cmd) {
+	h.groupTree.ExpandGroupWithParents(arg.Value)
+	h.rebuildFlatItems()
+	for i, item := range h.flatItems {
+		if item.Type == session.ItemTypeGroup && item.Path == arg.Value {
+			h.cursor = i
+			h.syncViewport()
+			break
+		}
+	}
+	return h, nil
+}
+
+func runMoveToGroup(h *Home, arg PaletteArg) (tea.Model, tea.Cmd) {
+	return h.confirmBulk(BulkMove, arg.Value)
+}
+
+func attachMCPArgs(h *Home) []PaletteArg {
+	var args []PaletteArg
+	for _, name := range session.GetAvailableMCPNames() {
+		args = append(args, PaletteArg{Label: name, Value: name})
+	}
+	return args
+}
+
+func detachMCPArgs(h *Home) []PaletteArg {
+	var args []PaletteArg
+	for _, name := range h.bulkChoices(BulkDetachMCP, h.actionTargets()) {
+		args = append(args, PaletteArg{Label: name, Value: name})
+	}
+	return args
+}
+
+func runBulkMCP(action BulkAction) func(h *Home, arg PaletteArg) (tea.Model, tea.Cmd) {
+	return func(h *Home, arg PaletteArg) (tea.Model, tea.Cmd) {
+		return h.confirmBulk(action, arg.Value)
+	}
+}
+
+func promptArgs(h *Home) []PaletteArg {
+	prompts, err := session.ListPrompts(h.profile)
+	if err != nil {
+		uiLog.Warn("list_prompts_failed", slog.String("error", err.Error()))
+	}
+	args := make([]PaletteArg, 0, len(prompts))
+	for _, p := range prompts {
+		label := p.Name
+		if p.Description != "" {
+			label += "  (" + p.Description + ")"
+		}
+		args = append(args, PaletteArg{Label: label, Value: p.Name})
+	}
+	return args
+}
+
+func runSendPrompt(h *Home, arg PaletteArg) (tea.Model, tea.Cmd) {
+	return h.confirmBulk(BulkSendPrompt, arg.Value)
+}
+
+// confirmBulk opens the bulk dialog's confirmation for the action targets
+// with choice already made.
+func (h *Home) confirmBulk(action BulkAction, choice string) (tea.Model, tea.Cmd) {
+	targets := h.actionTargets()
+	if len(targets) == 0 {
+		h.setError(fmt.Errorf("no sessions selected"))
+		return h, nil
+	}
+	if h.visualAnchor != "" {
+		h.toggleVisualMode()
+	}
+	h.bulkDialog.SetSize(h.width, h.height)
+	h.bulkDialog.ShowConfirm(targets, action, choice)
+	return h, nil
+}
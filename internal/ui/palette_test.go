@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+func typeInto(home *Home, text string) {
+	for _, r := range text {
+		home.Update(bulkKey(string(r)))
+	}
+}
+
+func TestCommandPaletteFuzzyFilter(t *testing.T) {
+	home, _ := newBulkTestHome(t)
+	cursorTo(t, home, func(item session.Item) bool { return item.Type == session.ItemTypeSession })
+	home.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+	if !home.commandPalette.IsVisible() {
+		t.Fatal("ctrl+k should open the palette")
+	}
+
+	typeInto(home, "rstrt")
+	e := home.commandPalette.Selected()
+	if e == nil || e.action.ID != "restart" {
+		t.Fatalf("fuzzy \"rstrt\" should select restart, got %+v", e)
+	}
+	if e.key != "R" {
+		t.Errorf("restart entry key = %q, want R", e.key)
+	}
+
+	home.Update(bulkKey("esc"))
+	if home.commandPalette.IsVisible() {
+		t.Error("esc should close the palette")
+	}
+}
+
+func TestCommandPaletteHidesUnavailable(t *testing.T) {
+	home, _ := newBulkTestHome(t)
+	cursorTo(t, home, func(item session.Item) bool { return item.Type == session.ItemTypeGroup })
+	home.Update(bulkKey(":"))
+	for _, e := range home.commandPalette.entries {
+		switch e.action.ID {
+		case "restart", "bulk_actions", "move_down":
+			t.Errorf("%s should not be offered on a group header without a selection", e.action.ID)
+		}
+	}
+}
+
+func TestCommandPaletteArgFlow(t *testing.T) {
+	home, insts := newBulkTestHome(t)
+	home.bulkSelected[insts[0].ID] = true
+	home.bulkSelected[insts[1].ID] = true
+	home.paletteHistory = make(map[string]paletteUse) // Skip the profile's stored history
+
+	home.Update(bulkKey(":"))
+	typeInto(home, "move selected")
+	if e := home.commandPalette.Selected(); e == nil || e.action.ID != "move_to_group" {
+		t.Fatalf("expected move_to_group, got %+v", e)
+	}
+	home.Update(bulkKey("enter"))
+	if home.commandPalette.argAction == nil {
+		t.Fatal("a parameterized action should ask for its argument")
+	}
+
+	typeInto(home, "play")
+	if e := home.commandPalette.Selected(); e == nil || e.arg == nil || e.arg.Value != "play" {
+		t.Fatalf("expected group play, got %+v", e)
+	}
+	home.Update(bulkKey("enter"))
+	if home.commandPalette.IsVisible() {
+		t.Error("choosing an argument should close the palette")
+	}
+	if !home.bulkDialog.IsVisible() || home.bulkDialog.Action() != BulkMove || home.bulkDialog.Choice() != "play" {
+		t.Fatal("move should open the bulk confirmation for group play")
+	}
+	if len(home.bulkDialog.Targets()) != 2 {
+		t.Errorf("targets = %d, want 2", len(home.bulkDialog.Targets()))
+	}
+	if home.paletteHistory["move_to_group"].Count != 1 {
+		t.Error("running an action should record it in the history")
+	}
+}
+
+func TestCommandPaletteRecentFirst(t *testing.T) {
+	home, _ := newBulkTestHome(t)
+	now := time.Now()
+	home.paletteHistory = map[string]paletteUse{
+		"settings": {Count: 1, Last: now.Add(-time.Hour)},
+		"reload":   {Count: 1, Last: now},
+		"import":   {Count: 4, Last: now.Add(-2 * time.Hour)},
+	}
+	entries := home.paletteEntries()
+	var got []string
+	for _, e := range entries[:3] {
+		got = append(got, e.group+":"+e.action.ID)
+	}
+	want := "Recent:reload Recent:settings Recent:import"
+	if strings.Join(got, " ") != want {
+		t.Errorf("first entries = %v, want %s", got, want)
+	}
+	if entries[3].group != "All commands" {
+		t.Errorf("entry after the history = %q, want All commands", entries[3].group)
+	}
+}
+
+func TestMainKeyOverride(t *testing.T) {
+	home, _ := newBulkTestHome(t)
+	home.keyMap = NewKeyMap(defaultActions(), map[string][]string{"command_palette": {"ctrl+p"}})
+
+	home.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+	if home.commandPalette.IsVisible() {
+		t.Fatal("ctrl+k was rebound and should do nothing")
+	}
+	home.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	if !home.commandPalette.IsVisible() {
+		t.Fatal("ctrl+p should open the palette")
+	}
+}
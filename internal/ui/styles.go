@@ -248,6 +248,7 @@ var (
 
 	// Selection indicator
 	SessionSelectionPrefix lipgloss.Style
+	BulkMarkStyle          lipgloss.Style // Multi-select mark
 
 	// Group item styles
 	GroupExpandStyle   lipgloss.Style
@@ -499,6 +500,7 @@ func initStyles() {
 
 	// Selection indicator
 	SessionSelectionPrefix = lipgloss.NewStyle().Foreground(ColorAccent).Bold(true)
+	BulkMarkStyle = lipgloss.NewStyle().Foreground(ColorYellow).Bold(true)
 
 	// Group item styles
 	GroupExpandStyle = lipgloss.NewStyle().Foreground(ColorText)
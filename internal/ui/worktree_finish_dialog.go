@@ -4,13 +4,14 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/asheshgoplani/agent-deck/internal/git"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/lipgloss"
 )
 
 // WorktreeFinishDialog handles the two-step worktree finish flow:
-// Step 0: Configure options (merge toggle, target branch, keep branch)
-// Step 1: Confirm the destructive actions
+// Step 0: Configure options (merge toggle, target branch, strategy, keep branch)
+// Step 1: Confirm the destructive actions (or review a merge conflict)
 type WorktreeFinishDialog struct {
 	visible bool
 	width   int
@@ -26,17 +27,27 @@ type WorktreeFinishDialog struct {
 	dirtyChecked bool // True once async dirty check has returned
 	isExecuting  bool // True while finish operation is running
 	errorMsg     string
+	conflict     *git.MergeConflictError // Set when the pre-flight found conflicts
 
 	// Options (step 0)
-	mergeEnabled bool
-	keepBranch   bool
-	targetInput  textinput.Model
+	mergeEnabled  bool
+	keepBranch    bool
+	targetInput   textinput.Model
+	strategyIndex int // Index into git.MergeStrategies
 
 	// Dialog state
 	step       int // 0=options, 1=confirm
-	focusIndex int // 0=merge checkbox, 1=target input, 2=keep-branch checkbox
+	focusIndex int // 0=merge checkbox, 1=target input, 2=strategy, 3=keep-branch checkbox
 }
 
+const (
+	finishFocusMerge = iota
+	finishFocusTarget
+	finishFocusStrategy
+	finishFocusKeep
+	finishFocusCount
+)
+
 // NewWorktreeFinishDialog creates a new worktree finish dialog
 func NewWorktreeFinishDialog() *WorktreeFinishDialog {
 	targetInput := textinput.New()
@@ -62,8 +73,10 @@ func (d *WorktreeFinishDialog) Show(sessionID, sessionTitle, branchName, repoRoo
 	d.dirtyChecked = false
 	d.isExecuting = false
 	d.errorMsg = ""
+	d.conflict = nil
 	d.mergeEnabled = true
 	d.keepBranch = false
+	d.strategyIndex = 0
 	d.step = 0
 	d.focusIndex = 0
 	d.targetInput.SetValue(defaultBranch)
@@ -101,6 +114,20 @@ func (d *WorktreeFinishDialog) SetError(msg string) {
 	d.isExecuting = false
 }
 
+// SetConflict shows the files a merge would conflict on. The main worktree
+// is untouched; the user can hand the conflict back to the session.
+func (d *WorktreeFinishDialog) SetConflict(conflict *git.MergeConflictError) {
+	d.conflict = conflict
+	d.errorMsg = ""
+	d.isExecuting = false
+	d.step = 1
+}
+
+// GetConflict returns the conflict being shown, if any.
+func (d *WorktreeFinishDialog) GetConflict() *git.MergeConflictError {
+	return d.conflict
+}
+
 // SetExecuting sets the executing state
 func (d *WorktreeFinishDialog) SetExecuting(executing bool) {
 	d.isExecuting = executing
@@ -120,6 +147,11 @@ func (d *WorktreeFinishDialog) GetOptions() (mergeEnabled bool, targetBranch str
 	return d.mergeEnabled, target, d.keepBranch
 }
 
+// GetStrategy returns the selected merge strategy
+func (d *WorktreeFinishDialog) GetStrategy() git.MergeStrategy {
+	return git.MergeStrategies[d.strategyIndex]
+}
+
 // HandleKey processes a key event and returns the action to take.
 // Returns: action string ("close", "confirm", ""), and whether the dialog handled the key.
 func (d *WorktreeFinishDialog) HandleKey(key string) (action string) {
@@ -127,6 +159,22 @@ func (d *WorktreeFinishDialog) HandleKey(key string) (action string) {
 		return "" // Block input while executing
 	}
 
+	if d.step == 1 && d.conflict != nil {
+		// Conflict step: hand off, go back, or close
+		switch key {
+		case "h":
+			return "handoff"
+		case "n":
+			d.conflict = nil
+			d.step = 0
+			return ""
+		case "esc":
+			d.Hide()
+			return "close"
+		}
+		return ""
+	}
+
 	if d.step == 1 {
 		// Confirm step: y/n/esc
 		switch key {
@@ -152,38 +200,28 @@ func (d *WorktreeFinishDialog) HandleKey(key string) (action string) {
 		return "close"
 
 	case "tab", "down":
-		if d.mergeEnabled {
-			d.focusIndex = (d.focusIndex + 1) % 3 // merge, target, keep-branch
-		} else {
-			// Skip target input when merge disabled
-			if d.focusIndex == 0 {
-				d.focusIndex = 2
-			} else {
-				d.focusIndex = 0
-			}
-		}
-		d.updateFocus()
+		d.moveFocus(1)
 		return ""
 
 	case "shift+tab", "up":
-		if d.mergeEnabled {
-			d.focusIndex = (d.focusIndex + 2) % 3
-		} else {
-			if d.focusIndex == 2 {
-				d.focusIndex = 0
-			} else {
-				d.focusIndex = 2
-			}
-		}
-		d.updateFocus()
+		d.moveFocus(-1)
 		return ""
 
+	case "left", "right":
+		if d.focusIndex == finishFocusStrategy {
+			d.cycleStrategy(key == "right")
+			return ""
+		}
+
 	case " ":
-		// Toggle checkboxes
-		if d.focusIndex == 0 {
+		// Toggle checkboxes / cycle strategy
+		switch d.focusIndex {
+		case finishFocusMerge:
 			d.mergeEnabled = !d.mergeEnabled
-			// Tab handler already skips target input when merge is disabled
-		} else if d.focusIndex == 2 {
+			// moveFocus skips merge-only fields when merge is disabled
+		case finishFocusStrategy:
+			d.cycleStrategy(true)
+		case finishFocusKeep:
 			d.keepBranch = !d.keepBranch
 		}
 		return ""
@@ -206,7 +244,7 @@ func (d *WorktreeFinishDialog) HandleKey(key string) (action string) {
 	}
 
 	// Pass through to target input if focused
-	if d.focusIndex == 1 && d.mergeEnabled {
+	if d.focusIndex == finishFocusTarget && d.mergeEnabled {
 		// Let the caller handle textinput update
 		return "input"
 	}
@@ -216,14 +254,35 @@ func (d *WorktreeFinishDialog) HandleKey(key string) (action string) {
 
 // UpdateTargetInput updates the target branch text input with a message
 func (d *WorktreeFinishDialog) UpdateTargetInput(msg interface{}) {
-	if d.focusIndex == 1 && d.mergeEnabled {
+	if d.focusIndex == finishFocusTarget && d.mergeEnabled {
 		d.targetInput, _ = d.targetInput.Update(msg)
 	}
 }
 
+// moveFocus advances focus by delta, skipping the target and strategy
+// fields while merge is disabled.
+func (d *WorktreeFinishDialog) moveFocus(delta int) {
+	for {
+		d.focusIndex = (d.focusIndex + delta + finishFocusCount) % finishFocusCount
+		if d.mergeEnabled || (d.focusIndex != finishFocusTarget && d.focusIndex != finishFocusStrategy) {
+			break
+		}
+	}
+	d.updateFocus()
+}
+
+func (d *WorktreeFinishDialog) cycleStrategy(forward bool) {
+	n := len(git.MergeStrategies)
+	if forward {
+		d.strategyIndex = (d.strategyIndex + 1) % n
+	} else {
+		d.strategyIndex = (d.strategyIndex + n - 1) % n
+	}
+}
+
 func (d *WorktreeFinishDialog) updateFocus() {
 	d.targetInput.Blur()
-	if d.focusIndex == 1 && d.mergeEnabled {
+	if d.focusIndex == finishFocusTarget && d.mergeEnabled {
 		d.targetInput.Focus()
 	}
 }
@@ -303,7 +362,7 @@ func (d *WorktreeFinishDialog) viewOptions(titleStyle, labelStyle, valueStyle, c
 	if d.mergeEnabled {
 		mergeCheck = "[x]"
 	}
-	if d.focusIndex == 0 {
+	if d.focusIndex == finishFocusMerge {
 		b.WriteString(checkboxActiveStyle.Render(fmt.Sprintf("▶ %s Merge into target branch", mergeCheck)))
 	} else {
 		b.WriteString(checkboxStyle.Render(fmt.Sprintf("  %s Merge into target branch", mergeCheck)))
@@ -312,14 +371,23 @@ func (d *WorktreeFinishDialog) viewOptions(titleStyle, labelStyle, valueStyle, c
 
 	// Target input (only when merge enabled)
 	if d.mergeEnabled {
-		if d.focusIndex == 1 {
-			activeLabelStyle := lipgloss.NewStyle().Foreground(ColorAccent).Bold(true)
+		activeLabelStyle := lipgloss.NewStyle().Foreground(ColorAccent).Bold(true)
+		if d.focusIndex == finishFocusTarget {
 			b.WriteString(activeLabelStyle.Render("  ▶ Target: "))
 		} else {
 			b.WriteString(labelStyle.Render("    Target: "))
 		}
 		b.WriteString(d.targetInput.View())
 		b.WriteString("\n")
+
+		if d.focusIndex == finishFocusStrategy {
+			b.WriteString(activeLabelStyle.Render("  ▶ Strategy: "))
+			b.WriteString(valueStyle.Render("◀ " + string(d.GetStrategy()) + " ▶"))
+		} else {
+			b.WriteString(labelStyle.Render("    Strategy: "))
+			b.WriteString(valueStyle.Render(string(d.GetStrategy())))
+		}
+		b.WriteString("\n")
 	}
 
 	// Keep branch checkbox
@@ -327,7 +395,7 @@ func (d *WorktreeFinishDialog) viewOptions(titleStyle, labelStyle, valueStyle, c
 	if d.keepBranch {
 		keepCheck = "[x]"
 	}
-	if d.focusIndex == 2 {
+	if d.focusIndex == finishFocusKeep {
 		b.WriteString(checkboxActiveStyle.Render(fmt.Sprintf("▶ %s Keep branch after finish", keepCheck)))
 	} else {
 		b.WriteString(checkboxStyle.Render(fmt.Sprintf("  %s Keep branch after finish", keepCheck)))
@@ -342,7 +410,7 @@ func (d *WorktreeFinishDialog) viewOptions(titleStyle, labelStyle, valueStyle, c
 	}
 
 	b.WriteString("\n")
-	b.WriteString(footerStyle.Render("Tab next | Space toggle | Enter confirm | Esc cancel"))
+	b.WriteString(footerStyle.Render("Tab next | Space toggle | ←/→ strategy | Enter confirm | Esc cancel"))
 
 	dialog := boxStyle.Render(b.String())
 	return lipgloss.Place(d.width, d.height, lipgloss.Center, lipgloss.Center, dialog)
@@ -359,6 +427,29 @@ func (d *WorktreeFinishDialog) viewConfirm(titleStyle, labelStyle, errStyle, foo
 		return lipgloss.Place(d.width, d.height, lipgloss.Center, lipgloss.Center, dialog)
 	}
 
+	if d.conflict != nil {
+		b.WriteString(errStyle.Render("Merge Would Conflict"))
+		b.WriteString("\n\n")
+		b.WriteString(labelStyle.Render(fmt.Sprintf("  %s → %s conflicts in:", d.conflict.Source, d.conflict.Target)))
+		b.WriteString("\n")
+		const maxFiles = 8
+		for i, f := range d.conflict.Files {
+			if i == maxFiles {
+				b.WriteString(labelStyle.Render(fmt.Sprintf("  … and %d more", len(d.conflict.Files)-maxFiles)))
+				b.WriteString("\n")
+				break
+			}
+			b.WriteString(labelStyle.Render("  • " + f))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		b.WriteString(labelStyle.Render("  Nothing was changed."))
+		b.WriteString("\n\n")
+		b.WriteString(footerStyle.Render("h Hand off to session | n back | Esc cancel"))
+		dialog := boxStyle.Render(b.String())
+		return lipgloss.Place(d.width, d.height, lipgloss.Center, lipgloss.Center, dialog)
+	}
+
 	if d.errorMsg != "" {
 		b.WriteString(errStyle.Render("Finish Failed"))
 		b.WriteString("\n\n")
@@ -382,7 +473,7 @@ func (d *WorktreeFinishDialog) viewConfirm(titleStyle, labelStyle, errStyle, foo
 
 	actionStyle := lipgloss.NewStyle().Foreground(ColorText)
 	if d.mergeEnabled {
-		b.WriteString(actionStyle.Render(fmt.Sprintf("  • Merge %s → %s", d.branchName, target)))
+		b.WriteString(actionStyle.Render(fmt.Sprintf("  • Merge %s → %s (%s)", d.branchName, target, d.GetStrategy())))
 		b.WriteString("\n")
 	}
 	b.WriteString(actionStyle.Render("  • Remove worktree directory"))
@@ -6,9 +6,9 @@ import (
 )
 
 // YoloOptionsPanel is a UI panel for YOLO/dangerous mode options.
-// Used for Gemini and Codex in NewDialog, matching ClaudeOptionsPanel's visual style.
+// Used for Gemini in NewDialog, matching ClaudeOptionsPanel's visual style.
 type YoloOptionsPanel struct {
-	toolName string // "Gemini" or "Codex"
+	toolName string // e.g. "Gemini"
 	label    string // Checkbox label text
 	yoloMode bool
 	focused  bool
@@ -0,0 +1,91 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/logging"
+)
+
+// AuditLogFileName is the per-profile JSONL file recording authenticated actions.
+const AuditLogFileName = "web_audit.jsonl"
+
+// AuditEntry is one line in the web audit log.
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	Identity string    `json:"identity"`
+	Action   string    `json:"action"`
+	Method   string    `json:"method"`
+	Path     string    `json:"path"`
+	Remote   string    `json:"remote,omitempty"`
+	Session  string    `json:"session,omitempty"`
+}
+
+type auditLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+func newAuditLog(path string) *auditLog {
+	if path == "" {
+		return nil
+	}
+	return &auditLog{path: path}
+}
+
+func (a *auditLog) write(entry AuditEntry) {
+	if a == nil {
+		return
+	}
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o700); err != nil {
+		logging.ForComponent(logging.CompWeb).Warn("audit_log_mkdir_failed", slog.String("error", err.Error()))
+		return
+	}
+	f, err := os.OpenFile(a.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		logging.ForComponent(logging.CompWeb).Warn("audit_log_open_failed", slog.String("error", err.Error()))
+		return
+	}
+	defer f.Close()
+	_, _ = f.Write(append(raw, '\n'))
+}
+
+// audit records an authenticated action. It is a no-op when no audit log is
+// configured or while auth is disabled; auth is checked per request because
+// the token store can enable it at runtime.
+func (s *Server) audit(r *http.Request, id *Identity, action, sessionID string) {
+	if s.auditLog == nil || !s.authEnabled() {
+		return
+	}
+	name := ""
+	if id != nil {
+		name = id.Name
+	}
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	s.auditLog.write(AuditEntry{
+		Time:     time.Now().UTC(),
+		Identity: name,
+		Action:   action,
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		Remote:   remote,
+		Session:  sessionID,
+	})
+}
@@ -2,28 +2,340 @@ package web
 
 import (
 	"crypto/subtle"
+	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 )
 
-func (s *Server) authorizeRequest(r *http.Request) bool {
-	if s.cfg.Token == "" {
-		return true
+// authCookieName holds the credential after a cookie login so the token does
+// not need to live in query strings.
+const authCookieName = "agentdeck_token"
+
+// Identity is the authenticated principal behind a request.
+type Identity struct {
+	Name     string
+	Scopes   []Scope
+	Profiles []string
+	Groups   []string
+
+	// share is set for visitors holding a share link; they are confined to
+	// the shared session and never get terminal input.
+	share *ShareLink
+}
+
+// Allows reports whether the identity holds scope. Admin implies every scope.
+func (id *Identity) Allows(scope Scope) bool {
+	if id == nil {
+		return false
 	}
+	for _, s := range id.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
 
-	queryToken := strings.TrimSpace(r.URL.Query().Get("token"))
-	if queryToken != "" && secureEqual(queryToken, s.cfg.Token) {
+// AllowsProfile reports whether the identity may act on profile.
+// An empty profile restriction allows every profile.
+func (id *Identity) AllowsProfile(profile string) bool {
+	if id == nil {
+		return false
+	}
+	if len(id.Profiles) == 0 {
 		return true
 	}
+	for _, p := range id.Profiles {
+		if p == profile {
+			return true
+		}
+	}
+	return false
+}
 
-	headerToken := bearerToken(r.Header.Get("Authorization"))
-	if headerToken != "" && secureEqual(headerToken, s.cfg.Token) {
+// AllowsGroup reports whether the identity may see sessions in groupPath.
+// A restriction to "work" also covers subgroups such as "work/api".
+func (id *Identity) AllowsGroup(groupPath string) bool {
+	if id == nil {
+		return false
+	}
+	if len(id.Groups) == 0 {
 		return true
 	}
+	for _, g := range id.Groups {
+		if groupPath == g || strings.HasPrefix(groupPath, g+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// authEnabled reports whether requests must carry a credential. Revoking
+// every token locks the server rather than opening it.
+func (s *Server) authEnabled() bool {
+	return s.cfg.Token != "" || len(s.cfg.Tokens) > 0 || len(s.apiTokens()) > 0
+}
+
+// apiTokens returns the API tokens currently accepted.
+func (s *Server) apiTokens() []APIToken {
+	if s.tokens != nil {
+		return s.tokens.current()
+	}
+	return s.cfg.Tokens
+}
+
+// authenticate resolves the identity for a request. A verified client
+// certificate wins over other credentials. When auth is disabled every
+// request gets an anonymous identity with all scopes. Identities restricted
+// to other profiles than the request's are rejected.
+func (s *Server) authenticate(r *http.Request) (*Identity, bool) {
+	return s.authenticateForProfile(r, s.requestProfile(r))
+}
+
+// authenticateForProfile is authenticate with an explicit profile check;
+// an empty profile accepts identities for any profile (used by the
+// cross-profile endpoints, which filter per profile themselves).
+func (s *Server) authenticateForProfile(r *http.Request, profile string) (*Identity, bool) {
+	allowed := func(id *Identity) bool { return profile == "" || id.AllowsProfile(profile) }
+	if id, ok := s.identityForClientCert(r); ok && allowed(id) {
+		return id, true
+	}
+	if shareToken := strings.TrimSpace(r.URL.Query().Get("share")); shareToken != "" {
+		return s.identityForShare(s.requestProfile(r), shareToken)
+	}
+	if !s.authEnabled() {
+		return &Identity{Name: "anonymous", Scopes: AllScopes}, true
+	}
+
+	for _, candidate := range requestCredentials(r) {
+		if id, ok := s.identityForSecret(candidate); ok && allowed(id) {
+			return id, true
+		}
+	}
+	return nil, false
+}
+
+func (s *Server) identityForSecret(secret string) (*Identity, bool) {
+	if s.cfg.Token != "" && secureEqual(secret, s.cfg.Token) {
+		return &Identity{Name: "default", Scopes: AllScopes}, true
+	}
+
+	hash := hashTokenSecret(secret)
+	for _, tok := range s.apiTokens() {
+		if !secureEqual(hash, tok.Hash) {
+			continue
+		}
+		return &Identity{
+			Name:     tok.Name,
+			Scopes:   tok.Scopes,
+			Profiles: tok.Profiles,
+			Groups:   tok.Groups,
+		}, true
+	}
+	return nil, false
+}
+
+// identityForClientCert maps a verified mTLS client certificate to an
+// identity. A token whose name equals the certificate's common name supplies
+// scopes and restrictions; without one the certificate is only trusted when
+// no token auth is configured.
+func (s *Server) identityForClientCert(r *http.Request) (*Identity, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+	cn := strings.TrimSpace(r.TLS.VerifiedChains[0][0].Subject.CommonName)
+	if cn == "" {
+		return nil, false
+	}
+
+	for _, tok := range s.apiTokens() {
+		if tok.Name != cn {
+			continue
+		}
+		return &Identity{
+			Name:     "cert:" + cn,
+			Scopes:   tok.Scopes,
+			Profiles: tok.Profiles,
+			Groups:   tok.Groups,
+		}, true
+	}
+	if !s.authEnabled() {
+		return &Identity{Name: "cert:" + cn, Scopes: AllScopes}, true
+	}
+	return nil, false
+}
+
+// identityForShare resolves a share-link token. Share identities can view
+// the terminal (unless transcript-only) and read the transcript.
+func (s *Server) identityForShare(profile, token string) (*Identity, bool) {
+	link, err := verifyShareToken(profile, token, time.Now())
+	if err != nil {
+		return nil, false
+	}
+	scopes := []Scope{ScopeMenuRead, ScopeTranscriptRead}
+	if link.TranscriptOnly {
+		scopes = []Scope{ScopeTranscriptRead}
+	}
+	return &Identity{Name: "share:" + link.ID, Scopes: scopes, share: link}, true
+}
 
+// sharePathAllowed confines share identities to their session's terminal
+// and transcript routes plus the share info endpoint.
+func sharePathAllowed(link *ShareLink, path string) bool {
+	switch path {
+	case "/api/share",
+		"/ws/session/" + link.SessionID,
+		"/api/messages/" + link.SessionID,
+		"/api/messages/" + link.SessionID + "/html":
+		return true
+	}
 	return false
 }
 
+// requestCredentials returns the credentials presented by a request in
+// priority order: Authorization header, login cookie, then ?token=.
+func requestCredentials(r *http.Request) []string {
+	var out []string
+	if headerToken := bearerToken(r.Header.Get("Authorization")); headerToken != "" {
+		out = append(out, headerToken)
+	}
+	if cookie, err := r.Cookie(authCookieName); err == nil {
+		if v := strings.TrimSpace(cookie.Value); v != "" {
+			out = append(out, v)
+		}
+	}
+	if queryToken := strings.TrimSpace(r.URL.Query().Get("token")); queryToken != "" {
+		out = append(out, queryToken)
+	}
+	return out
+}
+
+// requiredScope maps a request to the scope it needs. Reads of the menu and
+// hub state need menu:read, transcript and message queue reads need
+// transcripts:read (queued messages are user-written prompts), sending
+// library prompts and changing message queues need terminal:input, and hub
+// mutations need tasks:create (tasks) or admin (projects, templates,
+// workspaces). Terminal input is checked per message in handleSessionWS.
+func requiredScope(r *http.Request) Scope {
+	path := r.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/api/messages/"), path == "/api/share",
+		strings.HasPrefix(path, "/api/session/") && strings.Contains(path, "/queue") && r.Method == http.MethodGet:
+		return ScopeTranscriptRead
+	case strings.HasPrefix(path, "/ws/upload/"),
+		strings.HasPrefix(path, "/api/prompts/") && r.Method != http.MethodGet,
+		strings.HasPrefix(path, "/api/session/") && strings.Contains(path, "/queue") && r.Method != http.MethodGet:
+		return ScopeTerminalInput
+	case path == "/api/route",
+		path == "/api/tasks" && r.Method != http.MethodGet,
+		strings.HasPrefix(path, "/api/tasks/") && r.Method != http.MethodGet:
+		return ScopeTaskCreate
+	case strings.HasPrefix(path, "/api/projects"),
+		strings.HasPrefix(path, "/api/templates"),
+		strings.HasPrefix(path, "/api/workspaces"):
+		if r.Method != http.MethodGet {
+			return ScopeAdmin
+		}
+	}
+	return ScopeMenuRead
+}
+
+// authorizeRequest reports whether the request is authenticated and holds
+// the scope its route requires.
+func (s *Server) authorizeRequest(r *http.Request) bool {
+	id, ok := s.authenticate(r)
+	if !ok {
+		return false
+	}
+	if id.share != nil && !sharePathAllowed(id.share, r.URL.Path) {
+		return false
+	}
+	scope := requiredScope(r)
+	if !id.Allows(scope) {
+		return false
+	}
+	if scope != ScopeMenuRead {
+		s.audit(r, id, string(scope), "")
+	}
+	return true
+}
+
+// requestIdentity returns the identity for an already-authorized request.
+func (s *Server) requestIdentity(r *http.Request) *Identity {
+	id, _ := s.authenticate(r)
+	return id
+}
+
+type loginRequest struct {
+	Token string `json:"token"`
+}
+
+// handleLogin serves POST /api/login. It exchanges a token for an HttpOnly
+// cookie so the dashboard can drop ?token= from its URLs.
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	var req loginRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid json body")
+		return
+	}
+	secret := strings.TrimSpace(req.Token)
+	if secret == "" {
+		secret = bearerToken(r.Header.Get("Authorization"))
+	}
+
+	id, ok := s.identityForSecret(secret)
+	if !s.authEnabled() {
+		id, ok = &Identity{Name: "anonymous", Scopes: AllScopes}, true
+	}
+	if !ok {
+		s.audit(r, nil, "login_failed", "")
+		writeAPIError(w, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized")
+		return
+	}
+
+	if secret != "" {
+		http.SetCookie(w, &http.Cookie{
+			Name:     authCookieName,
+			Value:    secret,
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteStrictMode,
+			Expires:  time.Now().Add(30 * 24 * time.Hour),
+		})
+	}
+	s.audit(r, id, "login", "")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":     true,
+		"name":   id.Name,
+		"scopes": id.Scopes,
+	})
+}
+
+// handleLogout serves POST /api/logout and clears the login cookie.
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     authCookieName,
+		Value:    "",
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   -1,
+	})
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+}
+
 func bearerToken(authHeader string) string {
 	authHeader = strings.TrimSpace(authHeader)
 	if authHeader == "" {
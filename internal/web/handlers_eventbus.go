@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/asheshgoplani/agent-deck/internal/eventbus"
@@ -26,6 +27,8 @@ func (s *Server) handleEventBusWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	identity := s.requestIdentity(r)
+
 	conn, err := wsUpgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
@@ -38,7 +41,7 @@ func (s *Server) handleEventBusWS(w http.ResponseWriter, r *http.Request) {
 
 	webLog := logging.ForComponent(logging.CompWeb)
 
-	clientID := s.eventHub.RegisterClient(writer)
+	clientID := s.eventHub.RegisterFilteredClient(writer, s.eventFilterFor(r, identity))
 	webLog.Info("eventbus_client_connected", slog.String("client_id", clientID))
 	defer func() {
 		s.eventHub.UnregisterClient(clientID)
@@ -97,3 +100,96 @@ func (s *Server) handleEventBusWS(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 }
+
+// eventFilterFor returns the hub filter for a client connected as identity.
+// Events tied to a session are dropped when the identity cannot see the
+// session's group; broad notifications carry no session data and pass.
+func (s *Server) eventFilterFor(r *http.Request, identity *Identity) func(eventbus.Event) bool {
+	if identity != nil && len(identity.Groups) == 0 && identity.share == nil {
+		return nil
+	}
+	groups := &sessionGroupCache{menuData: s.menuDataFor(r)}
+	return func(e eventbus.Event) bool {
+		switch e.Type {
+		case eventbus.EventSessionCreated, eventbus.EventSessionUpdated, eventbus.EventSessionRemoved:
+			groups.invalidate()
+		}
+		sessionID := eventSessionID(e)
+		if sessionID == "" {
+			return true
+		}
+		groupPath, found := groups.lookup(sessionID)
+		return found && identity.AllowsGroup(groupPath)
+	}
+}
+
+// sessionGroupCacheMinAge limits snapshot reloads triggered by unknown
+// session IDs, so events for a deleted session can't force a rebuild each.
+const sessionGroupCacheMinAge = 2 * time.Second
+
+// sessionGroupCache maps session IDs to group paths for one event
+// connection. It loads the menu snapshot lazily, after session change
+// events, and when an unknown session shows up.
+type sessionGroupCache struct {
+	menuData MenuDataLoader
+
+	mu       sync.Mutex
+	groups   map[string]string
+	stale    bool
+	loadedAt time.Time
+}
+
+func (c *sessionGroupCache) invalidate() {
+	c.mu.Lock()
+	c.stale = true
+	c.mu.Unlock()
+}
+
+func (c *sessionGroupCache) lookup(sessionID string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.groups == nil || c.stale {
+		c.reload()
+	}
+	groupPath, ok := c.groups[sessionID]
+	if !ok && time.Since(c.loadedAt) >= sessionGroupCacheMinAge {
+		c.reload()
+		groupPath, ok = c.groups[sessionID]
+	}
+	return groupPath, ok
+}
+
+// reload rebuilds the map from a fresh snapshot; c.mu must be held.
+func (c *sessionGroupCache) reload() {
+	c.loadedAt = time.Now()
+	c.stale = false
+	snapshot, err := c.menuData.LoadMenuSnapshot()
+	if err != nil || snapshot == nil {
+		c.groups = map[string]string{}
+		return
+	}
+	c.groups = make(map[string]string, len(snapshot.Items))
+	for _, item := range snapshot.Items {
+		if item.Type == MenuItemTypeSession && item.Session != nil {
+			c.groups[item.Session.ID] = item.Session.GroupPath
+		}
+	}
+}
+
+// eventSessionID returns the session an event belongs to, or "" for broad
+// channel notifications. Per-session events use the session ID as channel.
+func eventSessionID(e eventbus.Event) string {
+	switch e.Channel {
+	case "", "sessions", "tasks", "push", "uploads", "system":
+	default:
+		return e.Channel
+	}
+	switch data := e.Data.(type) {
+	case map[string]string:
+		return data["sessionId"]
+	case map[string]any:
+		id, _ := data["sessionId"].(string)
+		return id
+	}
+	return ""
+}
@@ -1068,7 +1068,7 @@ func (s *Server) handleTaskAnalytics(w http.ResponseWriter, _ *http.Request, tas
 			data.CacheReadTokens = analytics.CacheReadTokens
 			data.CacheWriteTokens = analytics.CacheWriteTokens
 			data.CurrentContextTokens = analytics.CurrentContextTokens
-			data.ContextPercent = analytics.ContextPercent(200000)
+			data.ContextPercent = analytics.ContextPercent(0)
 			data.TotalTurns = analytics.TotalTurns
 			data.DurationSeconds = analytics.Duration.Seconds()
 			data.EstimatedCost = analytics.EstimatedCost
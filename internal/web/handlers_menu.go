@@ -33,16 +33,21 @@ func (s *Server) handleMenu(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	snapshot, err := s.menuData.LoadMenuSnapshot()
+	snapshot, err := s.menuDataFor(r).LoadMenuSnapshot()
 	if err != nil {
 		writeAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to load menu data")
 		return
 	}
 
-	writeJSON(w, http.StatusOK, snapshot)
+	writeJSON(w, http.StatusOK, filterSnapshotForIdentity(snapshot, s.requestIdentity(r)))
 }
 
 func (s *Server) handleSessionByID(w http.ResponseWriter, r *http.Request) {
+	if sessionID, rest, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/session/"), "/"); ok &&
+		(rest == "queue" || strings.HasPrefix(rest, "queue/")) {
+		s.handleSessionQueue(w, r, sessionID, strings.TrimPrefix(strings.TrimPrefix(rest, "queue"), "/"))
+		return
+	}
 	if r.Method != http.MethodGet {
 		writeAPIError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
 		return
@@ -63,7 +68,7 @@ func (s *Server) handleSessionByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	snapshot, err := s.menuData.LoadMenuSnapshot()
+	snapshot, err := s.menuDataFor(r).LoadMenuSnapshot()
 	if err != nil {
 		writeAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to load session data")
 		return
@@ -76,6 +81,9 @@ func (s *Server) handleSessionByID(w http.ResponseWriter, r *http.Request) {
 		if item.Session.ID != sessionID {
 			continue
 		}
+		if !s.requestIdentity(r).AllowsGroup(item.Session.GroupPath) {
+			break
+		}
 
 		writeJSON(w, http.StatusOK, sessionDetailsResponse{
 			Profile: snapshot.Profile,
@@ -104,3 +112,33 @@ func writeAPIError(w http.ResponseWriter, status int, code, message string) {
 		},
 	})
 }
+
+// filterSnapshotForIdentity drops groups and sessions outside the identity's
+// group restriction. Unrestricted identities get the snapshot unchanged.
+func filterSnapshotForIdentity(snapshot *MenuSnapshot, id *Identity) *MenuSnapshot {
+	if snapshot == nil || id == nil || len(id.Groups) == 0 {
+		return snapshot
+	}
+
+	filtered := *snapshot
+	filtered.Items = make([]MenuItem, 0, len(snapshot.Items))
+	filtered.TotalGroups = 0
+	filtered.TotalSessions = 0
+	for _, item := range snapshot.Items {
+		switch {
+		case item.Group != nil:
+			if !id.AllowsGroup(item.Group.Path) {
+				continue
+			}
+			filtered.TotalGroups++
+		case item.Session != nil:
+			if !id.AllowsGroup(item.Session.GroupPath) {
+				continue
+			}
+			filtered.TotalSessions++
+		}
+		item.Index = len(filtered.Items)
+		filtered.Items = append(filtered.Items, item)
+	}
+	return &filtered
+}
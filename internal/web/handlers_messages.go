@@ -213,7 +213,7 @@ func (s *Server) handleSessionMessagesHTML(w http.ResponseWriter, r *http.Reques
 // session ID. Returns empty string if no conversation data exists, or error
 // if the session is not found at all.
 func (s *Server) resolveSessionDir(r *http.Request, sessionID string) (string, error) {
-	snapshot, err := s.menuData.LoadMenuSnapshot()
+	snapshot, err := s.menuDataFor(r).LoadMenuSnapshot()
 	if err != nil {
 		return "", fmt.Errorf("failed to load session data")
 	}
@@ -227,6 +227,9 @@ func (s *Server) resolveSessionDir(r *http.Request, sessionID string) (string, e
 		if item.Session.ID != sessionID {
 			continue
 		}
+		if !s.requestIdentity(r).AllowsGroup(item.Session.GroupPath) {
+			break
+		}
 		projectPath = item.Session.ProjectPath
 		tmuxSession = item.Session.TmuxSession
 		found = true
@@ -0,0 +1,200 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// ── Prompt library API handlers ───────────────────────────────────────
+
+type promptSummary struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Body        string            `json:"body"`
+	Variables   []string          `json:"variables"`
+	Defaults    map[string]string `json:"defaults,omitempty"`
+}
+
+type promptsListResponse struct {
+	Prompts []promptSummary `json:"prompts"`
+}
+
+type promptSendRequest struct {
+	SessionIDs []string          `json:"sessionIds"`
+	Vars       map[string]string `json:"vars,omitempty"`
+}
+
+type promptSendResult struct {
+	SessionID string `json:"sessionId"`
+	Title     string `json:"title,omitempty"`
+	Status    string `json:"status"` // "sent" or "failed"
+	Error     string `json:"error,omitempty"`
+}
+
+type promptSendResponse struct {
+	Prompt  string             `json:"prompt"`
+	Results []promptSendResult `json:"results"`
+}
+
+// handlePrompts serves GET /api/prompts: the request profile's prompt library.
+func (s *Server) handlePrompts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+	if !s.authorizeRequest(r) {
+		writeAPIError(w, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized")
+		return
+	}
+
+	prompts, err := session.ListPrompts(s.requestProfile(r))
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to load prompts")
+		return
+	}
+	resp := promptsListResponse{Prompts: make([]promptSummary, 0, len(prompts))}
+	for _, p := range prompts {
+		resp.Prompts = append(resp.Prompts, newPromptSummary(&p))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func newPromptSummary(p *session.Prompt) promptSummary {
+	variables := p.Variables()
+	if variables == nil {
+		variables = []string{}
+	}
+	return promptSummary{
+		Name:        p.Name,
+		Description: p.Description,
+		Body:        p.Body,
+		Variables:   variables,
+		Defaults:    p.Vars,
+	}
+}
+
+// handlePromptByName dispatches GET /api/prompts/{name} and
+// POST /api/prompts/{name}/send.
+func (s *Server) handlePromptByName(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeRequest(r) {
+		writeAPIError(w, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized")
+		return
+	}
+
+	const prefix = "/api/prompts/"
+	name, subPath, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	if name == "" {
+		writeAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "prompt name is required")
+		return
+	}
+
+	switch subPath {
+	case "":
+		if r.Method != http.MethodGet {
+			writeAPIError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+			return
+		}
+		p, err := session.GetPrompt(s.requestProfile(r), name)
+		if err != nil {
+			writeAPIError(w, http.StatusNotFound, "NOT_FOUND", "prompt not found: "+name)
+			return
+		}
+		writeJSON(w, http.StatusOK, newPromptSummary(p))
+	case "send":
+		if r.Method != http.MethodPost {
+			writeAPIError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+			return
+		}
+		s.handlePromptSend(w, r, name)
+	default:
+		writeAPIError(w, http.StatusNotFound, "NOT_FOUND", "route not found")
+	}
+}
+
+// handlePromptSend serves POST /api/prompts/{name}/send. The prompt is
+// filled in for each session and sent like `agent-deck session send`;
+// sessions are sent to in parallel and reported individually.
+func (s *Server) handlePromptSend(w http.ResponseWriter, r *http.Request, name string) {
+	if s.cfg.ReadOnly {
+		writeAPIError(w, http.StatusForbidden, "READ_ONLY", "server is in read-only mode")
+		return
+	}
+
+	var req promptSendRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid JSON body")
+		return
+	}
+	if len(req.SessionIDs) == 0 {
+		writeAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "sessionIds is required")
+		return
+	}
+
+	profile := s.requestProfile(r)
+	p, err := session.GetPrompt(profile, name)
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, "NOT_FOUND", "prompt not found: "+name)
+		return
+	}
+	instances, err := s.loadPromptTargets(profile)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to load sessions")
+		return
+	}
+	byID := make(map[string]*session.Instance, len(instances))
+	for _, inst := range instances {
+		byID[inst.ID] = inst
+	}
+
+	id := s.requestIdentity(r)
+	results := make([]promptSendResult, len(req.SessionIDs))
+	var wg sync.WaitGroup
+	for k, sessionID := range req.SessionIDs {
+		results[k] = promptSendResult{SessionID: sessionID, Status: "failed"}
+		inst := byID[sessionID]
+		if inst == nil || !id.AllowsGroup(inst.GroupPath) {
+			results[k].Error = "session not found"
+			continue
+		}
+		results[k].Title = inst.Title
+		s.audit(r, id, "prompt_send", sessionID)
+
+		wg.Add(1)
+		go func(res *promptSendResult, inst *session.Instance) {
+			defer wg.Done()
+			err := s.sendPrompt(profile, p, inst, req.Vars)
+			if err != nil {
+				res.Error = err.Error()
+				return
+			}
+			res.Status = "sent"
+		}(&results[k], inst)
+	}
+	wg.Wait()
+
+	writeJSON(w, http.StatusOK, promptSendResponse{Prompt: p.Name, Results: results})
+}
+
+// sendPrompt renders p for inst and sends it; the send itself reports
+// sessions that aren't running
+func (s *Server) sendPrompt(profile string, p *session.Prompt, inst *session.Instance, vars map[string]string) error {
+	text, err := p.Render(inst, vars)
+	if err != nil {
+		return err
+	}
+	return s.sendMessage(profile, inst.ID, text)
+}
+
+func (s *Server) loadPromptTargets(profile string) ([]*session.Instance, error) {
+	storage, err := s.openStorage(profile)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = storage.Close() }()
+	instances, _, err := storage.LoadWithGroups()
+	return instances, err
+}
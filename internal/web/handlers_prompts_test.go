@@ -0,0 +1,126 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+func newPromptTestServer(t *testing.T, readOnly bool) (*Server, map[string]string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	dir, err := session.PromptsDir("test-profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\ndescription: Review for security\nfocus: auth\n---\nReview {{title}} for {{focus}}."
+	if err := os.WriteFile(filepath.Join(dir, "security.md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := newSessionTestServer(t, readOnly, nil)
+	var mu sync.Mutex
+	sent := map[string]string{}
+	srv.sendMessage = func(profile, sessionRef, message string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent[sessionRef] = message
+		return nil
+	}
+	return srv, sent
+}
+
+func TestPromptsEndpointList(t *testing.T) {
+	srv, _ := newPromptTestServer(t, false)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/prompts", nil)
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var resp promptsListResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Prompts) != 1 || resp.Prompts[0].Name != "security" || resp.Prompts[0].Defaults["focus"] != "auth" {
+		t.Fatalf("unexpected prompts: %+v", resp.Prompts)
+	}
+	if got := strings.Join(resp.Prompts[0].Variables, ","); got != "title,focus" {
+		t.Fatalf("expected variables title,focus, got %s", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/prompts/missing", nil)
+	rr = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d for unknown prompt, got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestPromptsEndpointSend(t *testing.T) {
+	srv, sent := newPromptTestServer(t, false)
+
+	body := `{"sessionIds":["sess-api","sess-missing"],"vars":{"focus":"injection"}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/prompts/security/send", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var resp promptSendResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", resp.Results)
+	}
+	if resp.Results[0].Status != "sent" || resp.Results[0].Title != "api" {
+		t.Fatalf("expected sess-api sent, got %+v", resp.Results[0])
+	}
+	if resp.Results[1].Status != "failed" || resp.Results[1].Error != "session not found" {
+		t.Fatalf("expected sess-missing not found, got %+v", resp.Results[1])
+	}
+	if got := sent["sess-api"]; got != "Review api for injection." {
+		t.Fatalf("unexpected message sent: %q", got)
+	}
+
+	srv.sendMessage = func(profile, sessionRef, message string) error {
+		return errors.New("session is not running")
+	}
+	req = httptest.NewRequest(http.MethodPost, "/api/prompts/security/send", strings.NewReader(`{"sessionIds":["sess-api"]}`))
+	rr = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if !strings.Contains(rr.Body.String(), `"error":"session is not running"`) {
+		t.Fatalf("expected send error in response, got: %s", rr.Body.String())
+	}
+}
+
+func TestPromptsEndpointSendReadOnly(t *testing.T) {
+	srv, sent := newPromptTestServer(t, true)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/prompts/security/send", strings.NewReader(`{"sessionIds":["sess-api"]}`))
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d", http.StatusForbidden, rr.Code)
+	}
+	if len(sent) != 0 {
+		t.Fatalf("expected nothing sent in read-only mode, got %v", sent)
+	}
+}
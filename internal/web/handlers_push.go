@@ -36,18 +36,19 @@ func (s *Server) handlePushConfig(w http.ResponseWriter, r *http.Request) {
 		writeAPIError(w, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized")
 		return
 	}
+	push := s.pushFor(r)
 
 	resp := pushConfigResponse{
-		Enabled: s.push != nil && s.push.Enabled(),
+		Enabled: push != nil && push.Enabled(),
 	}
 	if !resp.Enabled {
 		writeJSON(w, http.StatusOK, resp)
 		return
 	}
 
-	resp.VAPIDPublicKey = s.push.PublicKey()
-	resp.Subject = s.push.Subject()
-	if count, err := s.push.SubscriptionCount(r.Context()); err == nil {
+	resp.VAPIDPublicKey = push.PublicKey()
+	resp.Subject = push.Subject()
+	if count, err := push.SubscriptionCount(r.Context()); err == nil {
 		resp.SubscriptionCount = count
 	}
 
@@ -63,7 +64,8 @@ func (s *Server) handlePushSubscribe(w http.ResponseWriter, r *http.Request) {
 		writeAPIError(w, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized")
 		return
 	}
-	if s.push == nil || !s.push.Enabled() {
+	push := s.pushFor(r)
+	if push == nil || !push.Enabled() {
 		writeAPIError(w, http.StatusServiceUnavailable, "PUSH_NOT_CONFIGURED", "push notifications are not configured")
 		return
 	}
@@ -79,7 +81,7 @@ func (s *Server) handlePushSubscribe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.push.UpsertSubscription(r.Context(), sub); err != nil {
+	if err := push.UpsertSubscription(r.Context(), sub); err != nil {
 		writeAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to save push subscription")
 		return
 	}
@@ -99,7 +101,8 @@ func (s *Server) handlePushUnsubscribe(w http.ResponseWriter, r *http.Request) {
 		writeAPIError(w, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized")
 		return
 	}
-	if s.push == nil || !s.push.Enabled() {
+	push := s.pushFor(r)
+	if push == nil || !push.Enabled() {
 		writeAPIError(w, http.StatusServiceUnavailable, "PUSH_NOT_CONFIGURED", "push notifications are not configured")
 		return
 	}
@@ -112,7 +115,7 @@ func (s *Server) handlePushUnsubscribe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.push.RemoveSubscriptionByEndpoint(r.Context(), req.Endpoint); err != nil {
+	if err := push.RemoveSubscriptionByEndpoint(r.Context(), req.Endpoint); err != nil {
 		writeAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to remove push subscription")
 		return
 	}
@@ -132,7 +135,8 @@ func (s *Server) handlePushPresence(w http.ResponseWriter, r *http.Request) {
 		writeAPIError(w, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized")
 		return
 	}
-	if s.push == nil || !s.push.Enabled() {
+	push := s.pushFor(r)
+	if push == nil || !push.Enabled() {
 		writeAPIError(w, http.StatusServiceUnavailable, "PUSH_NOT_CONFIGURED", "push notifications are not configured")
 		return
 	}
@@ -148,7 +152,7 @@ func (s *Server) handlePushPresence(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.push.UpdateSubscriptionFocus(r.Context(), req.Endpoint, *req.Focused); err != nil {
+	if err := push.UpdateSubscriptionFocus(r.Context(), req.Endpoint, *req.Focused); err != nil {
 		writeAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to update push presence")
 		return
 	}
@@ -0,0 +1,180 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	"github.com/asheshgoplani/agent-deck/internal/statedb"
+)
+
+// ── Message queue API handlers ────────────────────────────────────────
+
+type queuedMessage struct {
+	ID        int64     `json:"id"`
+	Position  int       `json:"position"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type sessionQueueResponse struct {
+	SessionID string          `json:"sessionId"`
+	Messages  []queuedMessage `json:"messages"`
+}
+
+type queueSendRequest struct {
+	Message string `json:"message"`
+}
+
+type queueMoveRequest struct {
+	Position int `json:"position"` // 1 = next to deliver
+}
+
+// queueStorage is storage that exposes the state database holding the
+// message queue (session.Storage does).
+type queueStorage interface {
+	GetDB() *statedb.StateDB
+}
+
+// handleSessionQueue serves a session's message queue:
+//
+//	GET    /api/session/{id}/queue             list queued messages
+//	POST   /api/session/{id}/queue             send, queueing while the agent is busy
+//	DELETE /api/session/{id}/queue             cancel all queued messages
+//	DELETE /api/session/{id}/queue/{msg}       cancel one message
+//	POST   /api/session/{id}/queue/{msg}/move  move a message to a position
+func (s *Server) handleSessionQueue(w http.ResponseWriter, r *http.Request, sessionID, subPath string) {
+	if !s.authorizeRequest(r) {
+		writeAPIError(w, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized")
+		return
+	}
+	if sessionID == "" {
+		writeAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "session id is required")
+		return
+	}
+	if r.Method != http.MethodGet && s.cfg.ReadOnly {
+		writeAPIError(w, http.StatusForbidden, "READ_ONLY", "server is in read-only mode")
+		return
+	}
+
+	profile := s.requestProfile(r)
+	storage, err := s.openStorage(profile)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to load sessions")
+		return
+	}
+	defer func() { _ = storage.Close() }()
+
+	instances, _, err := storage.LoadWithGroups()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to load sessions")
+		return
+	}
+	var inst *session.Instance
+	for _, candidate := range instances {
+		if candidate.ID == sessionID {
+			inst = candidate
+			break
+		}
+	}
+	id := s.requestIdentity(r)
+	if inst == nil || !id.AllowsGroup(inst.GroupPath) {
+		writeAPIError(w, http.StatusNotFound, "NOT_FOUND", "session not found")
+		return
+	}
+	qs, ok := storage.(queueStorage)
+	if !ok || qs.GetDB() == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "message queue is not available")
+		return
+	}
+	db := qs.GetDB()
+
+	msgPart, action, _ := strings.Cut(subPath, "/")
+	switch {
+	case msgPart == "" && r.Method == http.MethodGet:
+		writeSessionQueue(w, db, sessionID)
+
+	case msgPart == "" && r.Method == http.MethodPost:
+		var req queueSendRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid JSON body")
+			return
+		}
+		if strings.TrimSpace(req.Message) == "" {
+			writeAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "message is required")
+			return
+		}
+		s.audit(r, id, "queue_send", sessionID)
+		// session send queues the message itself while the agent is busy
+		if err := s.queueMessage(profile, sessionID, req.Message); err != nil {
+			writeAPIError(w, http.StatusConflict, "SEND_FAILED", err.Error())
+			return
+		}
+		writeSessionQueue(w, db, sessionID)
+
+	case msgPart == "" && r.Method == http.MethodDelete:
+		s.audit(r, id, "queue_clear", sessionID)
+		if _, err := db.ClearQueuedMessages(sessionID); err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to clear queue")
+			return
+		}
+		writeSessionQueue(w, db, sessionID)
+
+	case msgPart != "":
+		msgID, err := strconv.ParseInt(msgPart, 10, 64)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid message id")
+			return
+		}
+		var found bool
+		switch {
+		case action == "" && r.Method == http.MethodDelete:
+			s.audit(r, id, "queue_cancel", sessionID)
+			found, err = db.DeleteQueuedMessage(sessionID, msgID)
+		case action == "move" && r.Method == http.MethodPost:
+			var req queueMoveRequest
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4*1024)).Decode(&req); err != nil || req.Position < 1 {
+				writeAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "position must be 1 or more")
+				return
+			}
+			s.audit(r, id, "queue_move", sessionID)
+			found, err = db.MoveQueuedMessage(sessionID, msgID, req.Position-1)
+		default:
+			writeAPIError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+			return
+		}
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to update queue")
+			return
+		}
+		if !found {
+			writeAPIError(w, http.StatusNotFound, "NOT_FOUND", "queued message not found")
+			return
+		}
+		writeSessionQueue(w, db, sessionID)
+
+	default:
+		writeAPIError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func writeSessionQueue(w http.ResponseWriter, db *statedb.StateDB, sessionID string) {
+	rows, err := db.LoadQueuedMessages(sessionID)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to load queue")
+		return
+	}
+	resp := sessionQueueResponse{SessionID: sessionID, Messages: make([]queuedMessage, 0, len(rows))}
+	for k, m := range rows {
+		resp.Messages = append(resp.Messages, queuedMessage{
+			ID:        m.ID,
+			Position:  k + 1,
+			Message:   m.Message,
+			CreatedAt: m.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
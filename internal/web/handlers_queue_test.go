@@ -0,0 +1,118 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/statedb"
+)
+
+type fakeQueueStorage struct {
+	*fakeStorage
+	db *statedb.StateDB
+}
+
+func (f *fakeQueueStorage) GetDB() *statedb.StateDB { return f.db }
+
+func newQueueTestServer(t *testing.T, readOnly bool) (*Server, *statedb.StateDB) {
+	t.Helper()
+	db, err := statedb.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	srv := newSessionTestServer(t, readOnly, func(f *fakeStorage) storageLoader {
+		return &fakeQueueStorage{fakeStorage: f, db: db}
+	})
+	// Stand in for `session send` finding the agent busy
+	srv.queueMessage = func(profile, sessionRef, message string) error {
+		_, err := db.EnqueueMessage(sessionRef, message)
+		return err
+	}
+	return srv, db
+}
+
+func doQueueRequest(t *testing.T, srv *Server, method, path, body string) (*httptest.ResponseRecorder, sessionQueueResponse) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	var resp sessionQueueResponse
+	if rr.Code == http.StatusOK {
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+	return rr, resp
+}
+
+func TestSessionQueueEndpoint(t *testing.T) {
+	srv, _ := newQueueTestServer(t, false)
+
+	rr, resp := doQueueRequest(t, srv, http.MethodGet, "/api/session/sess-api/queue", "")
+	if rr.Code != http.StatusOK || len(resp.Messages) != 0 {
+		t.Fatalf("expected empty queue, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	for _, msg := range []string{"first", "second", "third"} {
+		rr, _ = doQueueRequest(t, srv, http.MethodPost, "/api/session/sess-api/queue", fmt.Sprintf(`{"message":%q}`, msg))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+	}
+	_, resp = doQueueRequest(t, srv, http.MethodGet, "/api/session/sess-api/queue", "")
+	if len(resp.Messages) != 3 || resp.Messages[2].Message != "third" || resp.Messages[2].Position != 3 {
+		t.Fatalf("unexpected queue: %+v", resp.Messages)
+	}
+
+	thirdID := resp.Messages[2].ID
+	_, resp = doQueueRequest(t, srv, http.MethodPost, fmt.Sprintf("/api/session/sess-api/queue/%d/move", thirdID), `{"position":1}`)
+	if len(resp.Messages) != 3 || resp.Messages[0].ID != thirdID {
+		t.Fatalf("expected third message first, got %+v", resp.Messages)
+	}
+
+	_, resp = doQueueRequest(t, srv, http.MethodDelete, fmt.Sprintf("/api/session/sess-api/queue/%d", thirdID), "")
+	if len(resp.Messages) != 2 || resp.Messages[0].Message != "first" {
+		t.Fatalf("expected third message cancelled, got %+v", resp.Messages)
+	}
+
+	rr, _ = doQueueRequest(t, srv, http.MethodDelete, fmt.Sprintf("/api/session/sess-api/queue/%d", thirdID), "")
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d for cancelled message, got %d", http.StatusNotFound, rr.Code)
+	}
+
+	_, resp = doQueueRequest(t, srv, http.MethodDelete, "/api/session/sess-api/queue", "")
+	if len(resp.Messages) != 0 {
+		t.Fatalf("expected cleared queue, got %+v", resp.Messages)
+	}
+
+	rr, _ = doQueueRequest(t, srv, http.MethodGet, "/api/session/sess-missing/queue", "")
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d for unknown session, got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestSessionQueueEndpointReadOnly(t *testing.T) {
+	srv, db := newQueueTestServer(t, true)
+	if _, err := db.EnqueueMessage("sess-api", "queued"); err != nil {
+		t.Fatalf("EnqueueMessage: %v", err)
+	}
+
+	rr, resp := doQueueRequest(t, srv, http.MethodGet, "/api/session/sess-api/queue", "")
+	if rr.Code != http.StatusOK || len(resp.Messages) != 1 {
+		t.Fatalf("expected queue to be readable, got %d: %s", rr.Code, rr.Body.String())
+	}
+	rr, _ = doQueueRequest(t, srv, http.MethodDelete, "/api/session/sess-api/queue", "")
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d", http.StatusForbidden, rr.Code)
+	}
+}
@@ -0,0 +1,58 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+type shareInfoResponse struct {
+	SessionID      string    `json:"sessionId"`
+	SessionTitle   string    `json:"sessionTitle,omitempty"`
+	TranscriptOnly bool      `json:"transcriptOnly"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+}
+
+// handleShareInfo serves GET /api/share?share=<token> and describes the
+// shared session so the viewer page knows what to connect to.
+func (s *Server) handleShareInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+	if strings.TrimSpace(r.URL.Query().Get("share")) == "" {
+		writeAPIError(w, http.StatusBadRequest, "INVALID_REQUEST", "share token is required")
+		return
+	}
+	if !s.authorizeRequest(r) {
+		writeAPIError(w, http.StatusUnauthorized, "UNAUTHORIZED", "share link is invalid, expired or revoked")
+		return
+	}
+
+	link := s.requestIdentity(r).share
+	writeJSON(w, http.StatusOK, shareInfoResponse{
+		SessionID:      link.SessionID,
+		SessionTitle:   link.SessionTitle,
+		TranscriptOnly: link.TranscriptOnly,
+		ExpiresAt:      link.ExpiresAt,
+	})
+}
+
+// handleSharePage serves the read-only share viewer.
+func (s *Server) handleSharePage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeAPIError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	page, err := embeddedStaticFiles.ReadFile("static/share.html")
+	if err != nil {
+		http.Error(w, "share viewer unavailable", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(page)
+}
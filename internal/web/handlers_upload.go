@@ -64,6 +64,17 @@ func (s *Server) handleUploadWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	snapshot, err := s.menuDataFor(r).LoadMenuSnapshot()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to load session data")
+		return
+	}
+	menuSession, found := snapshotSessionByID(snapshot, sessionID)
+	if !found || !s.requestIdentity(r).AllowsGroup(menuSession.GroupPath) {
+		writeAPIError(w, http.StatusNotFound, "NOT_FOUND", "session not found")
+		return
+	}
+
 	conn, err := wsUpgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
@@ -166,7 +177,7 @@ func (s *Server) handleUploadWS(w http.ResponseWriter, r *http.Request) {
 				completed = false
 
 				// Resolve upload directory.
-				profileDir, dirErr := session.GetProfileDir(session.GetEffectiveProfile(s.cfg.Profile))
+				profileDir, dirErr := session.GetProfileDir(session.GetEffectiveProfile(s.requestProfile(r)))
 				if dirErr != nil {
 					_ = writeWSJSON(conn, map[string]string{
 						"type":    "error",
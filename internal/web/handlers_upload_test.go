@@ -1,8 +1,13 @@
 package web
 
 import (
+	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	"github.com/gorilla/websocket"
 )
 
 func TestSanitizeFilename(t *testing.T) {
@@ -32,3 +37,41 @@ func TestSanitizeFilename(t *testing.T) {
 		})
 	}
 }
+
+func TestUploadUnderProfilePrefixUsesThatProfileDir(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	srv := newMultiProfileTestServer(t, Config{})
+	testServer := httptest.NewServer(srv.Handler())
+	defer testServer.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL(testServer.URL, "/p/work/ws/upload/w1"), nil)
+	if err != nil {
+		t.Fatalf("dial upload websocket: %v", err)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteJSON(uploadStartMsg{Type: "start", Filename: "notes.txt", Size: 5}); err != nil {
+		t.Fatalf("write start: %v", err)
+	}
+	if err := conn.WriteMessage(websocket.BinaryMessage, []byte("hello")); err != nil {
+		t.Fatalf("write chunk: %v", err)
+	}
+	if err := conn.WriteJSON(map[string]string{"type": "end"}); err != nil {
+		t.Fatalf("write end: %v", err)
+	}
+
+	var complete uploadCompleteMsg
+	for complete.Type != "complete" {
+		if err := conn.ReadJSON(&complete); err != nil {
+			t.Fatalf("read upload reply: %v", err)
+		}
+	}
+
+	workDir, err := session.GetProfileDir("work")
+	if err != nil {
+		t.Fatalf("profile dir: %v", err)
+	}
+	if !strings.HasPrefix(complete.Path, filepath.Join(workDir, "uploads", "w1")+string(filepath.Separator)) {
+		t.Fatalf("expected upload under the work profile, got %s", complete.Path)
+	}
+}
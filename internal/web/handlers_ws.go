@@ -69,14 +69,15 @@ func (s *Server) handleSessionWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	snapshot, err := s.menuData.LoadMenuSnapshot()
+	snapshot, err := s.menuDataFor(r).LoadMenuSnapshot()
 	if err != nil {
 		writeAPIError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to load session data")
 		return
 	}
 
+	identity := s.requestIdentity(r)
 	menuSession, found := snapshotSessionByID(snapshot, sessionID)
-	if !found {
+	if !found || !identity.AllowsGroup(menuSession.GroupPath) {
 		writeAPIError(w, http.StatusNotFound, "NOT_FOUND", "session not found")
 		return
 	}
@@ -88,13 +89,21 @@ func (s *Server) handleSessionWS(w http.ResponseWriter, r *http.Request) {
 	defer conn.Close()
 
 	writer := newWSConnWriter(conn)
+	if identity.share != nil {
+		s.viewers.add(sessionID)
+		defer s.viewers.remove(sessionID)
+		s.audit(r, identity, "share_view", sessionID)
+		go s.watchShareValidity(r, conn, strings.TrimSpace(r.URL.Query().Get("share")))
+	}
+	inputAllowed := !s.cfg.ReadOnly && identity.Allows(ScopeTerminalInput)
+	inputAudited := false
 
 	_ = writer.WriteJSON(wsServerMessage{
 		Type:      "status",
 		Event:     "connected",
 		SessionID: sessionID,
 		Profile:   snapshot.Profile,
-		ReadOnly:  s.cfg.ReadOnly,
+		ReadOnly:  !inputAllowed,
 		Time:      time.Now().UTC(),
 	})
 	_ = writer.WriteJSON(wsServerMessage{
@@ -173,7 +182,7 @@ func (s *Server) handleSessionWS(w http.ResponseWriter, r *http.Request) {
 				Time:      time.Now().UTC(),
 			})
 		case "input":
-			if s.cfg.ReadOnly {
+			if !inputAllowed {
 				_ = writer.WriteJSON(wsServerMessage{
 					Type:      "error",
 					Code:      "READ_ONLY",
@@ -183,6 +192,10 @@ func (s *Server) handleSessionWS(w http.ResponseWriter, r *http.Request) {
 				})
 				continue
 			}
+			if !inputAudited {
+				s.audit(r, identity, "terminal_input", sessionID)
+				inputAudited = true
+			}
 			if bridge == nil {
 				_ = writer.WriteJSON(wsServerMessage{
 					Type:      "error",
@@ -239,6 +252,30 @@ func (s *Server) handleSessionWS(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// shareRecheckInterval is how often live share viewers are re-validated so
+// revocation and expiry cut off connected viewers.
+var shareRecheckInterval = 15 * time.Second
+
+// watchShareValidity closes conn once its share token is revoked or expires.
+func (s *Server) watchShareValidity(r *http.Request, conn *websocket.Conn, token string) {
+	ticker := time.NewTicker(shareRecheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err := verifyShareToken(s.requestProfile(r), token, time.Now()); err != nil {
+				_ = conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error()),
+					time.Now().Add(time.Second))
+				_ = conn.Close()
+				return
+			}
+		}
+	}
+}
+
 func snapshotSessionByID(snapshot *MenuSnapshot, sessionID string) (*MenuSession, bool) {
 	if snapshot == nil {
 		return nil, false
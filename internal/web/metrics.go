@@ -0,0 +1,350 @@
+package web
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/hub"
+	"github.com/asheshgoplani/agent-deck/internal/logging"
+	"github.com/asheshgoplani/agent-deck/internal/mcppool"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// metricsContentType is the Prometheus text exposition format, which
+// OpenMetrics-aware scrapers accept as well.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// MetricsCollector renders deck state (sessions, analytics, MCP pool, hub
+// tasks, notifier deliveries) in the Prometheus text format. It is shared by
+// the web server's /metrics route and `agent-deck metrics serve`.
+type MetricsCollector struct {
+	profiles        []string
+	openStorage     storageOpener
+	refreshStatuses func([]*session.Instance)
+	listTasks       func(profile string) ([]*hub.Task, error)
+	pool            func() *mcppool.Pool
+	deliveryStats   func() (map[string]map[string]int, error)
+	now             func() time.Time
+
+	mu sync.Mutex
+	// waitingSince records when each profile/session was first seen waiting,
+	// for sessions whose tmux state doesn't track it.
+	waitingSince   map[string]time.Time
+	analyticsCache map[string]cachedAnalytics
+}
+
+// analyticsTTL is how long a session's parsed analytics are reused across
+// scrapes before its transcript is parsed again.
+const analyticsTTL = 30 * time.Second
+
+type cachedAnalytics struct {
+	loadedAt  time.Time
+	analytics *session.AgentAnalytics
+}
+
+// NewMetricsCollector creates a collector for profiles. pool returns the MCP
+// pool to report on; it may be nil or return nil when pooling is off.
+func NewMetricsCollector(profiles []string, pool func() *mcppool.Pool) *MetricsCollector {
+	live := &SessionDataService{loadHookStatuses: defaultLoadHookStatuses}
+	return &MetricsCollector{
+		profiles:        profiles,
+		openStorage:     defaultStorageOpener,
+		refreshStatuses: live.refreshStatuses,
+		listTasks:       listHubTasks,
+		pool:            pool,
+		deliveryStats:   session.TransitionDeliveryStats,
+		now:             time.Now,
+		waitingSince:    make(map[string]time.Time),
+		analyticsCache:  make(map[string]cachedAnalytics),
+	}
+}
+
+func listHubTasks(profile string) ([]*hub.Task, error) {
+	hubDir, err := hub.GetHubDir(profile)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(hubDir); os.IsNotExist(err) {
+		return nil, nil
+	}
+	store, err := hub.NewTaskStore(hubDir)
+	if err != nil {
+		return nil, err
+	}
+	return store.List()
+}
+
+// ServeHTTP writes the current metrics.
+func (c *MetricsCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.serveFor(w, r, nil)
+}
+
+// serveFor writes the metrics visible to id.
+func (c *MetricsCollector) serveFor(w http.ResponseWriter, r *http.Request, id *Identity) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeAPIError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+	w.Header().Set("Content-Type", metricsContentType)
+	if err := c.writeMetrics(w, id); err != nil {
+		logging.ForComponent(logging.CompWeb).Warn("metrics_write_failed", slog.String("error", err.Error()))
+	}
+}
+
+// WriteMetrics gathers all sources and writes them to out. Failing sources
+// are logged and skipped so one broken profile does not blank the scrape.
+func (c *MetricsCollector) WriteMetrics(out io.Writer) error {
+	return c.writeMetrics(out, nil)
+}
+
+// writeMetrics writes the metrics of the profiles and groups id may see. A
+// nil id sees everything. Deck-wide sources (MCP pool, hub tasks) are only
+// shown to identities without profile or group restrictions.
+func (c *MetricsCollector) writeMetrics(out io.Writer, id *Identity) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	webLog := logging.ForComponent(logging.CompWeb)
+	now := c.now()
+	m := newMetricSet()
+
+	sessions := m.family("agentdeck_sessions", "gauge", "Sessions by profile, status, tool and group.")
+	waiting := m.family("agentdeck_session_waiting_seconds", "gauge", "Seconds each waiting session has been waiting for input, by profile.")
+	tokens := m.family("agentdeck_tokens", "gauge", "Tokens used by current sessions, by profile, tool and token type.")
+	cost := m.family("agentdeck_cost_usd", "gauge", "Estimated cost of current sessions in USD, by profile and tool.")
+	tasks := m.family("agentdeck_hub_tasks", "gauge", "Hub tasks by profile and status.")
+
+	unrestricted := id == nil || (len(id.Profiles) == 0 && len(id.Groups) == 0)
+	allowsProfile := func(profile string) bool { return id == nil || id.AllowsProfile(profile) }
+
+	// Waiting times are tracked for every session in a scanned profile, so
+	// a restricted scrape does not reset them for the others
+	seenWaiting := make(map[string]bool)
+	seenSessions := make(map[string]bool)
+	scanned := make(map[string]bool)
+	for _, profile := range c.profiles {
+		if !allowsProfile(profile) {
+			continue
+		}
+		instances, err := c.loadInstances(profile)
+		if err != nil {
+			webLog.Warn("metrics_sessions_failed", slog.String("profile", profile), slog.String("error", err.Error()))
+			continue
+		}
+		scanned[profile] = true
+		for _, inst := range instances {
+			status := inst.GetStatusThreadSafe()
+			visible := id == nil || id.AllowsGroup(inst.GroupPath)
+			key := profile + "/" + inst.ID
+			seenSessions[key] = true
+
+			if status == session.StatusWaiting {
+				seenWaiting[key] = true
+				since, ok := c.waitingSince[key]
+				if !ok {
+					since = now
+					c.waitingSince[key] = since
+				}
+				// GetWaitingSince falls back to CreatedAt when tmux hasn't
+				// tracked the transition; first-seen is closer in that case
+				if tracked := inst.GetWaitingSince(); !tracked.IsZero() && !tracked.Equal(inst.CreatedAt) {
+					since = tracked
+				}
+				if visible {
+					waiting.set(now.Sub(since).Seconds(), "profile", profile, "session_id", inst.ID)
+				}
+			}
+			if !visible {
+				continue
+			}
+
+			tool := inst.GetToolThreadSafe()
+			sessions.add(1, "profile", profile, "status", string(status), "tool", tool, "group", inst.GroupPath)
+			c.addAnalytics(inst, key, profile, tool, now, tokens, cost)
+		}
+
+		if !unrestricted {
+			continue
+		}
+		taskList, err := c.listTasks(profile)
+		if err != nil {
+			webLog.Warn("metrics_tasks_failed", slog.String("profile", profile), slog.String("error", err.Error()))
+		}
+		for _, task := range taskList {
+			tasks.add(1, "profile", profile, "status", string(task.Status))
+		}
+	}
+	for key := range c.waitingSince {
+		profile, _, _ := strings.Cut(key, "/")
+		if scanned[profile] && !seenWaiting[key] {
+			delete(c.waitingSince, key)
+		}
+	}
+	for key := range c.analyticsCache {
+		profile, _, _ := strings.Cut(key, "/")
+		if scanned[profile] && !seenSessions[key] {
+			delete(c.analyticsCache, key)
+		}
+	}
+
+	if c.pool != nil && unrestricted {
+		if pool := c.pool(); pool != nil {
+			servers := m.family("agentdeck_mcp_pool_servers", "gauge", "Pooled MCP servers by name and status (1 per server).")
+			clients := m.family("agentdeck_mcp_pool_clients", "gauge", "Clients connected to each pooled MCP server.")
+			for _, info := range pool.ListServers() {
+				servers.set(1, "name", info.Name, "status", info.Status)
+				clients.set(float64(info.Clients), "name", info.Name)
+			}
+		}
+	}
+
+	if c.deliveryStats != nil {
+		stats, err := c.deliveryStats()
+		if err != nil {
+			webLog.Warn("metrics_notify_stats_failed", slog.String("error", err.Error()))
+		}
+		deliveries := m.family("agentdeck_notify_deliveries_total", "counter", "Notify-daemon transition deliveries by profile and result.")
+		for profile, results := range stats {
+			if !allowsProfile(profile) {
+				continue
+			}
+			for result, count := range results {
+				deliveries.set(float64(count), "profile", profile, "result", result)
+			}
+		}
+	}
+
+	return m.write(out)
+}
+
+func (c *MetricsCollector) loadInstances(profile string) ([]*session.Instance, error) {
+	storage, err := c.openStorage(profile)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = storage.Close() }()
+
+	instances, _, err := storage.LoadWithGroups()
+	if err != nil {
+		return nil, err
+	}
+	if c.refreshStatuses != nil {
+		c.refreshStatuses(instances)
+	}
+	return instances, nil
+}
+
+// addAnalytics adds a session's token and cost totals from its tool
+// adapter. Parsed analytics are reused for analyticsTTL so frequent scrapes
+// don't re-read every transcript.
+func (c *MetricsCollector) addAnalytics(inst *session.Instance, key, profile, tool string, now time.Time, tokens, cost *metricFamily) {
+	cached, ok := c.analyticsCache[key]
+	if !ok || now.Sub(cached.loadedAt) >= analyticsTTL {
+		analytics, err := inst.LoadAnalytics()
+		if err != nil {
+			return
+		}
+		cached = cachedAnalytics{loadedAt: now, analytics: analytics}
+		c.analyticsCache[key] = cached
+	}
+	a := cached.analytics
+	if a == nil {
+		return
+	}
+	tokens.add(float64(a.InputTokens), "profile", profile, "tool", tool, "type", "input")
+	tokens.add(float64(a.OutputTokens), "profile", profile, "tool", tool, "type", "output")
+	tokens.add(float64(a.CacheReadTokens), "profile", profile, "tool", tool, "type", "cache_read")
+	tokens.add(float64(a.CacheWriteTokens), "profile", profile, "tool", tool, "type", "cache_write")
+	cost.add(a.EstimatedCost, "profile", profile, "tool", tool)
+}
+
+// metricSet accumulates metric families in registration order.
+type metricSet struct {
+	families []*metricFamily
+}
+
+type metricFamily struct {
+	name    string
+	typ     string
+	help    string
+	samples map[string]float64
+}
+
+func newMetricSet() *metricSet {
+	return &metricSet{}
+}
+
+func (m *metricSet) family(name, typ, help string) *metricFamily {
+	f := &metricFamily{name: name, typ: typ, help: help, samples: make(map[string]float64)}
+	m.families = append(m.families, f)
+	return f
+}
+
+// add increments the sample identified by the label name/value pairs.
+func (f *metricFamily) add(v float64, labels ...string) {
+	f.samples[formatMetricLabels(labels)] += v
+}
+
+// set overwrites the sample identified by the label name/value pairs.
+func (f *metricFamily) set(v float64, labels ...string) {
+	f.samples[formatMetricLabels(labels)] = v
+}
+
+func (m *metricSet) write(out io.Writer) error {
+	w := bufio.NewWriter(out)
+	for _, f := range m.families {
+		fmt.Fprintf(w, "# HELP %s %s\n", f.name, f.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.typ)
+		keys := make([]string, 0, len(f.samples))
+		for k := range f.samples {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s%s %s\n", f.name, k, strconv.FormatFloat(f.samples[k], 'g', -1, 64))
+		}
+	}
+	return w.Flush()
+}
+
+var metricLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatMetricLabels(pairs []string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(metricLabelEscaper.Replace(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// handleMetrics serves GET /metrics for Prometheus scrapers. With auth
+// enabled it requires a credential with the menu:read scope, and only the
+// profiles and groups of that credential are reported.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	id := s.requestIdentity(r)
+	if !s.authorizeRequest(r) || id == nil {
+		writeAPIError(w, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized")
+		return
+	}
+	s.metrics.serveFor(w, r, id)
+}
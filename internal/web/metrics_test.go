@@ -0,0 +1,147 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/hub"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+func TestMetricsCollectorWritesDeckState(t *testing.T) {
+	running := session.NewInstanceWithGroupAndTool("api", "/tmp/api", "work", "shell")
+	running.ID = "s-run"
+	running.Status = session.StatusRunning
+	waiting := session.NewInstanceWithGroupAndTool("docs \"v2\"", "/tmp/docs", "work", "shell")
+	waiting.ID = "s-wait"
+	waiting.Status = session.StatusWaiting
+
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	c := NewMetricsCollector([]string{"main"}, nil)
+	c.openStorage = func(profile string) (storageLoader, error) {
+		return &fakeStorage{instances: []*session.Instance{running, waiting}}, nil
+	}
+	c.refreshStatuses = nil
+	c.listTasks = func(profile string) ([]*hub.Task, error) {
+		return []*hub.Task{{Status: hub.TaskStatusRunning}, {Status: hub.TaskStatusRunning}, {Status: hub.TaskStatusDone}}, nil
+	}
+	c.deliveryStats = func() (map[string]map[string]int, error) {
+		return map[string]map[string]int{"main": {"sent": 4, "failed": 1}}, nil
+	}
+	c.now = func() time.Time { return now }
+
+	var first strings.Builder
+	if err := c.WriteMetrics(&first); err != nil {
+		t.Fatalf("WriteMetrics: %v", err)
+	}
+	now = now.Add(90 * time.Second)
+	var out strings.Builder
+	if err := c.WriteMetrics(&out); err != nil {
+		t.Fatalf("WriteMetrics: %v", err)
+	}
+	body := out.String()
+
+	for _, want := range []string{
+		"# TYPE agentdeck_sessions gauge",
+		`agentdeck_sessions{profile="main",status="running",tool="shell",group="work"} 1`,
+		`agentdeck_sessions{profile="main",status="waiting",tool="shell",group="work"} 1`,
+		`agentdeck_session_waiting_seconds{profile="main",session_id="s-wait"} 90`,
+		`agentdeck_hub_tasks{profile="main",status="running"} 2`,
+		`agentdeck_hub_tasks{profile="main",status="done"} 1`,
+		`agentdeck_notify_deliveries_total{profile="main",result="sent"} 4`,
+		`agentdeck_notify_deliveries_total{profile="main",result="failed"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "agentdeck_mcp_pool_servers") {
+		t.Errorf("expected no MCP pool metrics without a pool")
+	}
+}
+
+func TestMetricsEndpointRequiresAuth(t *testing.T) {
+	srv := NewServer(Config{ListenAddr: "127.0.0.1:0", Token: "secret"})
+	srv.metrics.openStorage = func(profile string) (storageLoader, error) {
+		return &fakeStorage{}, nil
+	}
+	srv.metrics.refreshStatuses = nil
+	srv.metrics.listTasks = func(string) ([]*hub.Task, error) { return nil, nil }
+	srv.metrics.deliveryStats = nil
+
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", rr.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+}
+
+func TestMetricsEndpointFiltersByIdentity(t *testing.T) {
+	work := session.NewInstanceWithGroupAndTool("api", "/tmp/api", "work/api", "shell")
+	work.ID = "s-work"
+	work.Status = session.StatusWaiting
+	personal := session.NewInstanceWithGroupAndTool("notes", "/tmp/notes", "personal", "shell")
+	personal.ID = "s-personal"
+	personal.Status = session.StatusWaiting
+
+	srv := newScopedTestServer(t,
+		APIToken{Name: "work", Hash: hashTokenSecret("work-secret"), Scopes: []Scope{ScopeMenuRead}, Groups: []string{"work"}},
+		APIToken{Name: "test-only", Hash: hashTokenSecret("test-secret"), Scopes: []Scope{ScopeMenuRead}, Profiles: []string{"test"}},
+	)
+	srv.metrics = NewMetricsCollector([]string{"test", "other"}, nil)
+	srv.metrics.openStorage = func(profile string) (storageLoader, error) {
+		if profile != "test" {
+			return &fakeStorage{}, nil
+		}
+		return &fakeStorage{instances: []*session.Instance{work, personal}}, nil
+	}
+	srv.metrics.refreshStatuses = nil
+	srv.metrics.listTasks = func(string) ([]*hub.Task, error) {
+		return []*hub.Task{{Status: hub.TaskStatusRunning}}, nil
+	}
+	srv.metrics.deliveryStats = func() (map[string]map[string]int, error) {
+		return map[string]map[string]int{"test": {"sent": 2}, "other": {"sent": 3}}, nil
+	}
+
+	scrape := func(secret string) string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("Authorization", "Bearer "+secret)
+		rr := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		return rr.Body.String()
+	}
+
+	body := scrape("work-secret")
+	if !strings.Contains(body, `session_id="s-work"`) || strings.Contains(body, "s-personal") || strings.Contains(body, `group="personal"`) {
+		t.Errorf("group-restricted token should only see its group:\n%s", body)
+	}
+	if strings.Contains(body, "agentdeck_hub_tasks{") {
+		t.Errorf("group-restricted token should not see deck-wide hub tasks:\n%s", body)
+	}
+
+	body = scrape("test-secret")
+	if strings.Contains(body, `profile="other"`) || !strings.Contains(body, "s-personal") {
+		t.Errorf("profile-restricted token should see only its profile:\n%s", body)
+	}
+	if !strings.Contains(body, `agentdeck_notify_deliveries_total{profile="test",result="sent"} 2`) {
+		t.Errorf("expected the token's own profile deliveries:\n%s", body)
+	}
+}
@@ -0,0 +1,220 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/logging"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// profileRoutePrefix scopes API, WebSocket and page routes to a profile:
+// /p/<profile>/api/menu, /p/<profile>/ws/session/<id>, /p/<profile>/s/<id>.
+const profileRoutePrefix = "/p/"
+
+type profileContextKey struct{}
+
+// profileBackend bundles the per-profile data sources served by one server.
+type profileBackend struct {
+	name     string
+	menuData MenuDataLoader
+	push     pushServiceAPI
+}
+
+// initExtraProfiles sets up backends for every additional profile in
+// cfg.Profiles. The primary profile keeps using s.menuData and s.push.
+func (s *Server) initExtraProfiles() {
+	webLog := logging.ForComponent(logging.CompWeb)
+	primary := session.GetEffectiveProfile(s.cfg.Profile)
+	s.extraProfiles = make(map[string]*profileBackend)
+	for _, name := range s.cfg.Profiles {
+		name = strings.TrimSpace(name)
+		if name == "" || name == primary || s.extraProfiles[name] != nil {
+			continue
+		}
+		backend := &profileBackend{
+			name:     name,
+			menuData: NewSessionDataService(name),
+		}
+		profileCfg := s.cfg
+		profileCfg.Profile = name
+		if pushSvc, err := newPushService(profileCfg, backend.menuData, s.eventBus, s.eventHub); err != nil {
+			webLog.Warn("push_disabled", slog.String("profile", name), slog.String("error", err.Error()))
+		} else if pushSvc != nil {
+			if ps, ok := pushSvc.(*pushService); ok {
+				ps.pathPrefix = profileRoutePrefix + name
+			}
+			backend.push = pushSvc
+		}
+		s.extraProfiles[name] = backend
+	}
+}
+
+// servedProfiles returns the primary profile followed by the extra ones.
+func (s *Server) servedProfiles() []string {
+	names := make([]string, 0, len(s.extraProfiles))
+	for name := range s.extraProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{session.GetEffectiveProfile(s.cfg.Profile)}, names...)
+}
+
+// withProfileRoutes strips a /p/<profile> prefix, records the profile on the
+// request context and forwards to next. Unknown profiles get 404.
+func (s *Server) withProfileRoutes(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, profileRoutePrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rest := strings.TrimPrefix(r.URL.Path, profileRoutePrefix)
+		name, tail, _ := strings.Cut(rest, "/")
+		if !s.servesProfile(name) {
+			writeAPIError(w, http.StatusNotFound, "PROFILE_NOT_FOUND", "profile is not served by this server")
+			return
+		}
+
+		r2 := r.Clone(context.WithValue(r.Context(), profileContextKey{}, name))
+		r2.URL.Path = "/" + tail
+		r2.URL.RawPath = ""
+		next.ServeHTTP(w, r2)
+	})
+}
+
+func (s *Server) servesProfile(name string) bool {
+	if name == "" {
+		return false
+	}
+	if name == session.GetEffectiveProfile(s.cfg.Profile) {
+		return true
+	}
+	_, ok := s.extraProfiles[name]
+	return ok
+}
+
+// requestProfile returns the profile a request targets.
+func (s *Server) requestProfile(r *http.Request) string {
+	if name, ok := r.Context().Value(profileContextKey{}).(string); ok && name != "" {
+		return name
+	}
+	return session.GetEffectiveProfile(s.cfg.Profile)
+}
+
+// menuDataFor returns the menu loader for the request's profile.
+func (s *Server) menuDataFor(r *http.Request) MenuDataLoader {
+	return s.menuDataForProfile(s.requestProfile(r))
+}
+
+func (s *Server) menuDataForProfile(name string) MenuDataLoader {
+	if backend := s.extraProfiles[name]; backend != nil {
+		return backend.menuData
+	}
+	return s.menuData
+}
+
+// pushFor returns the push service for the request's profile (may be nil).
+func (s *Server) pushFor(r *http.Request) pushServiceAPI {
+	if backend := s.extraProfiles[s.requestProfile(r)]; backend != nil {
+		return backend.push
+	}
+	return s.push
+}
+
+type profileSummary struct {
+	Name          string `json:"name"`
+	Primary       bool   `json:"primary,omitempty"`
+	TotalSessions int    `json:"totalSessions"`
+	Waiting       int    `json:"waiting"`
+}
+
+type profilesResponse struct {
+	Profiles []profileSummary `json:"profiles"`
+}
+
+// handleProfiles serves GET /api/profiles for the dashboard's profile switcher.
+func (s *Server) handleProfiles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+	id, ok := s.authenticateForProfile(r, "")
+	if !ok || !id.Allows(ScopeMenuRead) || id.share != nil {
+		writeAPIError(w, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized")
+		return
+	}
+
+	resp := profilesResponse{Profiles: []profileSummary{}}
+	for i, name := range s.servedProfiles() {
+		if !id.AllowsProfile(name) {
+			continue
+		}
+		summary := profileSummary{Name: name, Primary: i == 0}
+		if snapshot, err := s.menuDataForProfile(name).LoadMenuSnapshot(); err == nil && snapshot != nil {
+			for _, sess := range filterSnapshotForIdentity(snapshot, id).Items {
+				if sess.Session == nil {
+					continue
+				}
+				summary.TotalSessions++
+				if sess.Session.Status == session.StatusWaiting {
+					summary.Waiting++
+				}
+			}
+		}
+		resp.Profiles = append(resp.Profiles, summary)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type waitingSession struct {
+	Profile string       `json:"profile"`
+	Path    string       `json:"path"`
+	Session *MenuSession `json:"session"`
+}
+
+type waitingResponse struct {
+	Sessions []waitingSession `json:"sessions"`
+}
+
+// handleWaiting serves GET /api/waiting: every waiting session across all
+// served profiles the caller may see, oldest wait first.
+func (s *Server) handleWaiting(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+	id, ok := s.authenticateForProfile(r, "")
+	if !ok || !id.Allows(ScopeMenuRead) || id.share != nil {
+		writeAPIError(w, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized")
+		return
+	}
+
+	resp := waitingResponse{Sessions: []waitingSession{}}
+	for _, name := range s.servedProfiles() {
+		if !id.AllowsProfile(name) {
+			continue
+		}
+		snapshot, err := s.menuDataForProfile(name).LoadMenuSnapshot()
+		if err != nil || snapshot == nil {
+			continue
+		}
+		for _, item := range filterSnapshotForIdentity(snapshot, id).Items {
+			if item.Session == nil || item.Session.Status != session.StatusWaiting {
+				continue
+			}
+			resp.Sessions = append(resp.Sessions, waitingSession{
+				Profile: name,
+				Path:    profileRoutePrefix + name + "/s/" + item.Session.ID,
+				Session: item.Session,
+			})
+		}
+	}
+	sort.SliceStable(resp.Sessions, func(i, j int) bool {
+		return resp.Sessions[i].Session.LastAccessedAt.Before(resp.Sessions[j].Session.LastAccessedAt)
+	})
+	writeJSON(w, http.StatusOK, resp)
+}
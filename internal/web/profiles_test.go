@@ -0,0 +1,150 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+func newMultiProfileTestServer(t *testing.T, cfg Config) *Server {
+	t.Helper()
+	cfg.ListenAddr = "127.0.0.1:0"
+	cfg.Profile = "home"
+	cfg.Profiles = []string{"work", "home"}
+	srv := NewServer(cfg)
+
+	now := time.Now()
+	srv.menuData = &fakeMenuDataLoader{snapshot: &MenuSnapshot{
+		Profile: "home",
+		Items: []MenuItem{
+			{Type: MenuItemTypeSession, Session: &MenuSession{ID: "h1", Title: "home-waiting", Status: session.StatusWaiting, LastAccessedAt: now}},
+			{Type: MenuItemTypeSession, Session: &MenuSession{ID: "h2", Title: "home-idle", Status: session.StatusIdle}},
+		},
+	}}
+	if srv.extraProfiles["work"] == nil {
+		t.Fatalf("expected work profile backend, got %v", srv.extraProfiles)
+	}
+	srv.extraProfiles["work"].menuData = &fakeMenuDataLoader{snapshot: &MenuSnapshot{
+		Profile: "work",
+		Items: []MenuItem{
+			{Type: MenuItemTypeSession, Session: &MenuSession{ID: "w1", Title: "work-waiting", Status: session.StatusWaiting, LastAccessedAt: now.Add(-time.Hour)}},
+		},
+	}}
+	return srv
+}
+
+func TestProfileRoutesServeProfileMenu(t *testing.T) {
+	srv := newMultiProfileTestServer(t, Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/p/work/api/menu", nil)
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var snapshot MenuSnapshot
+	if err := json.Unmarshal(rr.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("decode menu: %v", err)
+	}
+	if snapshot.Profile != "work" || len(snapshot.Items) != 1 {
+		t.Fatalf("expected work snapshot, got %+v", snapshot)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/menu", nil)
+	rr = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if err := json.Unmarshal(rr.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("decode menu: %v", err)
+	}
+	if snapshot.Profile != "home" {
+		t.Fatalf("expected root paths to serve the primary profile, got %q", snapshot.Profile)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/p/unknown/api/menu", nil)
+	rr = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unserved profile, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/p/work/s/w1", nil)
+	rr = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected dashboard page under profile prefix, got %d", rr.Code)
+	}
+}
+
+func TestProfilesAndWaitingEndpoints(t *testing.T) {
+	srv := newMultiProfileTestServer(t, Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/profiles", nil)
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var profiles profilesResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &profiles); err != nil {
+		t.Fatalf("decode profiles: %v", err)
+	}
+	if len(profiles.Profiles) != 2 || profiles.Profiles[0].Name != "home" || !profiles.Profiles[0].Primary {
+		t.Fatalf("unexpected profiles: %+v", profiles.Profiles)
+	}
+	if profiles.Profiles[0].TotalSessions != 2 || profiles.Profiles[0].Waiting != 1 {
+		t.Fatalf("unexpected home counts: %+v", profiles.Profiles[0])
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/waiting", nil)
+	rr = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	var waiting waitingResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &waiting); err != nil {
+		t.Fatalf("decode waiting: %v", err)
+	}
+	if len(waiting.Sessions) != 2 {
+		t.Fatalf("expected 2 waiting sessions, got %+v", waiting.Sessions)
+	}
+	if waiting.Sessions[0].Profile != "work" || waiting.Sessions[0].Path != "/p/work/s/w1" {
+		t.Fatalf("expected oldest wait (work) first, got %+v", waiting.Sessions[0])
+	}
+}
+
+func TestProfileRestrictedTokenOnlySeesItsProfile(t *testing.T) {
+	srv := newMultiProfileTestServer(t, Config{
+		Tokens: []APIToken{{
+			Name:     "work-only",
+			Hash:     hashTokenSecret("work-secret"),
+			Scopes:   []Scope{ScopeMenuRead},
+			Profiles: []string{"work"},
+		}},
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer work-secret")
+		rr := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := get("/p/work/api/menu"); rr.Code != http.StatusOK {
+		t.Fatalf("expected work menu to be readable, got %d", rr.Code)
+	}
+	if rr := get("/api/menu"); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected primary profile to be rejected, got %d", rr.Code)
+	}
+
+	rr := get("/api/waiting")
+	var waiting waitingResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &waiting); err != nil {
+		t.Fatalf("decode waiting: %v", err)
+	}
+	if len(waiting.Sessions) != 1 || waiting.Sessions[0].Profile != "work" {
+		t.Fatalf("expected only work sessions, got %+v", waiting.Sessions)
+	}
+}
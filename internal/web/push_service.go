@@ -342,6 +342,9 @@ type pushService struct {
 	subject    string
 	profile    string
 	token      string
+	// pathPrefix routes notification clicks to a non-primary profile
+	// (e.g. "/p/work"). Empty for the server's primary profile.
+	pathPrefix string
 
 	menuData MenuDataLoader
 	store    pushSubscriptionStore
@@ -877,7 +880,11 @@ func (p *pushService) routePath(basePath string) string {
 	if basePath == "" {
 		basePath = "/"
 	}
-	if p == nil || strings.TrimSpace(p.token) == "" {
+	if p == nil {
+		return basePath
+	}
+	basePath = p.pathPrefix + basePath
+	if strings.TrimSpace(p.token) == "" {
 		return basePath
 	}
 
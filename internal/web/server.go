@@ -19,15 +19,35 @@ import (
 
 // Config defines runtime options for the web server.
 type Config struct {
-	ListenAddr          string
-	Profile             string
-	ReadOnly            bool
-	Token               string
-	MenuData            MenuDataLoader
-	PushVAPIDPublicKey  string
-	PushVAPIDPrivateKey string
-	PushVAPIDSubject    string
-	PushTestInterval    time.Duration
+	ListenAddr string
+	Profile    string
+	// Profiles are additional profiles served under /p/<name>/ alongside
+	// Profile, which stays the default at the root paths.
+	Profiles []string
+	ReadOnly bool
+	Token    string
+	// Tokens are named, scoped API tokens (see LoadAPITokens). When set,
+	// requests must present one of them or Token.
+	Tokens []APIToken
+	// TokenStoreProfile, when set, keeps Tokens in sync with that profile's
+	// token store so `agent-deck web token revoke` takes effect immediately.
+	TokenStoreProfile string
+	// AuditLogPath is the JSONL file receiving authenticated actions.
+	// Empty disables auditing.
+	AuditLogPath string
+	// TLSCertFile and TLSKeyFile enable HTTPS when both are set.
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile enables client-certificate (mTLS) authentication.
+	// Verified certificates map to identities by common name.
+	TLSClientCAFile string
+	// TLSRequireClientCert rejects TLS handshakes without a valid client cert.
+	TLSRequireClientCert bool
+	MenuData             MenuDataLoader
+	PushVAPIDPublicKey   string
+	PushVAPIDPrivateKey  string
+	PushVAPIDSubject     string
+	PushTestInterval     time.Duration
 }
 
 // MenuDataLoader provides menu snapshots for web APIs and push notifications.
@@ -37,13 +57,21 @@ type MenuDataLoader interface {
 
 // Server wraps an HTTP server for Agent Deck web mode.
 type Server struct {
-	cfg         Config
-	httpServer  *http.Server
-	menuData    MenuDataLoader
-	push        pushServiceAPI
-	baseCtx     context.Context
-	cancelBase  context.CancelFunc
-	hookWatcher *session.StatusFileWatcher
+	cfg        Config
+	httpServer *http.Server
+	menuData   MenuDataLoader
+	push       pushServiceAPI
+	// extraProfiles holds backends for Config.Profiles, keyed by name.
+	extraProfiles map[string]*profileBackend
+	metrics       *MetricsCollector
+	baseCtx       context.Context
+	cancelBase    context.CancelFunc
+	hookWatcher   *session.StatusFileWatcher
+	auditLog      *auditLog
+	tls           *certReloader
+	tokens        *tokenStore
+	tlsErr        error
+	viewers       *ShareViewers
 
 	// Hub dashboard state.
 	hubTasks         *hub.TaskStore
@@ -57,6 +85,13 @@ type Server struct {
 	eventBus *eventbus.EventBus
 	eventHub *eventbus.Hub
 
+	// openStorage, sendMessage and queueMessage load sessions and deliver
+	// messages for the prompt library and queue APIs; tests replace them.
+	// sendMessage waits for the agent, queueMessage queues while it is busy.
+	openStorage  storageOpener
+	sendMessage  func(profile, sessionRef, message string) error
+	queueMessage func(profile, sessionRef, message string) error
+
 	// claudeProjectsDir overrides the default ~/.claude/projects base
 	// directory for locating Claude Code conversation JSONL files.
 	// Empty means use the default.
@@ -75,8 +110,16 @@ func NewServer(cfg Config) *Server {
 	}
 
 	s := &Server{
-		cfg:      cfg,
-		menuData: menuData,
+		cfg:          cfg,
+		menuData:     menuData,
+		auditLog:     newAuditLog(cfg.AuditLogPath),
+		viewers:      NewShareViewers(),
+		openStorage:  defaultStorageOpener,
+		sendMessage:  session.SendSessionMessageReliable,
+		queueMessage: session.SendOrQueueSessionMessage,
+	}
+	if cfg.TokenStoreProfile != "" {
+		s.tokens = newTokenStore(cfg.TokenStoreProfile, cfg.Tokens)
 	}
 	s.baseCtx, s.cancelBase = context.WithCancel(context.Background())
 	s.eventBus = eventbus.New()
@@ -123,11 +166,14 @@ func NewServer(cfg Config) *Server {
 	} else {
 		s.push = pushSvc
 	}
+	s.initExtraProfiles()
+	s.metrics = NewMetricsCollector(s.servedProfiles(), session.GetGlobalPool)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", s.handleIndex)
 	mux.HandleFunc("/s/", s.handleIndex)
 	mux.HandleFunc("/terminal", s.handleTerminal)
+	mux.HandleFunc("/share", s.handleSharePage)
 	mux.HandleFunc("/manifest.webmanifest", s.handleManifest)
 	mux.HandleFunc("/sw.js", s.handleServiceWorker)
 	mux.Handle("/static/", http.StripPrefix("/static/", s.staticFileServer()))
@@ -146,7 +192,13 @@ func NewServer(cfg Config) *Server {
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(resp)
 	})
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/api/login", s.handleLogin)
+	mux.HandleFunc("/api/logout", s.handleLogout)
+	mux.HandleFunc("/api/share", s.handleShareInfo)
 	mux.HandleFunc("/api/menu", s.handleMenu)
+	mux.HandleFunc("/api/profiles", s.handleProfiles)
+	mux.HandleFunc("/api/waiting", s.handleWaiting)
 	mux.HandleFunc("/api/session/", s.handleSessionByID)
 	mux.HandleFunc("/api/messages/", s.handleSessionMessages)
 	mux.HandleFunc("/api/tasks", s.handleTasks)
@@ -155,6 +207,8 @@ func NewServer(cfg Config) *Server {
 	mux.HandleFunc("/api/projects/", s.handleProjectByName)
 	mux.HandleFunc("/api/templates", s.handleTemplates)
 	mux.HandleFunc("/api/templates/", s.handleTemplateByName)
+	mux.HandleFunc("/api/prompts", s.handlePrompts)
+	mux.HandleFunc("/api/prompts/", s.handlePromptByName)
 	mux.HandleFunc("/api/workspaces", s.handleWorkspaces)
 	mux.HandleFunc("/api/workspaces/", s.handleWorkspaceByName)
 	mux.HandleFunc("/api/route", s.handleRoute)
@@ -167,7 +221,17 @@ func NewServer(cfg Config) *Server {
 	mux.HandleFunc("/ws/upload/", s.handleUploadWS)
 	mux.HandleFunc("/ws/events", s.handleEventBusWS)
 
-	handler := withRecover(mux)
+	if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" {
+		if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+			s.tlsErr = fmt.Errorf("both TLS certificate and key are required")
+		} else if reloader, err := newCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCAFile); err != nil {
+			s.tlsErr = err
+		} else {
+			s.tls = reloader
+		}
+	}
+
+	handler := withRecover(s.withProfileRoutes(mux))
 
 	s.httpServer = &http.Server{
 		Addr:              cfg.ListenAddr,
@@ -176,6 +240,9 @@ func NewServer(cfg Config) *Server {
 		ReadHeaderTimeout: 5 * time.Second,
 		IdleTimeout:       60 * time.Second,
 	}
+	if s.tls != nil {
+		s.httpServer.TLSConfig = s.tls.tlsConfig(cfg.TLSRequireClientCert)
+	}
 
 	return s
 }
@@ -185,6 +252,11 @@ func (s *Server) Addr() string {
 	return s.httpServer.Addr
 }
 
+// ShareViewers returns the live share-link viewer tracker.
+func (s *Server) ShareViewers() *ShareViewers {
+	return s.viewers
+}
+
 // Handler returns the configured HTTP handler (used by tests).
 func (s *Server) Handler() http.Handler {
 	return s.httpServer.Handler
@@ -193,12 +265,20 @@ func (s *Server) Handler() http.Handler {
 // Start starts the HTTP server and blocks until shutdown or error.
 // Returns nil on graceful shutdown.
 func (s *Server) Start() error {
+	if s.tlsErr != nil {
+		return fmt.Errorf("tls setup: %w", s.tlsErr)
+	}
 	webLog := logging.ForComponent(logging.CompWeb)
 	if watcher, err := session.NewStatusFileWatcher(func() {
 		s.notifyMenuChanged()
 		if s.push != nil {
 			s.push.TriggerSync()
 		}
+		for _, backend := range s.extraProfiles {
+			if backend.push != nil {
+				backend.push.TriggerSync()
+			}
+		}
 	}); err != nil {
 		webLog.Warn("hooks_watcher_disabled", slog.String("error", err.Error()))
 	} else {
@@ -209,7 +289,18 @@ func (s *Server) Start() error {
 	if s.push != nil {
 		s.push.Start(s.baseCtx)
 	}
-	err := s.httpServer.ListenAndServe()
+	for _, backend := range s.extraProfiles {
+		if backend.push != nil {
+			backend.push.Start(s.baseCtx)
+		}
+	}
+	var err error
+	if s.tls != nil {
+		// Certificates come from TLSConfig.GetCertificate.
+		err = s.httpServer.ListenAndServeTLS("", "")
+	} else {
+		err = s.httpServer.ListenAndServe()
+	}
 	if s.hookWatcher != nil {
 		s.hookWatcher.Stop()
 		s.hookWatcher = nil
@@ -267,7 +358,7 @@ func withRecover(next http.Handler) http.Handler {
 }
 
 func (s *Server) String() string {
-	return fmt.Sprintf("web-server(addr=%s, profile=%s, readOnly=%t)", s.cfg.ListenAddr, s.cfg.Profile, s.cfg.ReadOnly)
+	return fmt.Sprintf("web-server(addr=%s, profile=%s, readOnly=%t, tls=%t)", s.cfg.ListenAddr, s.cfg.Profile, s.cfg.ReadOnly, s.TLSEnabled())
 }
 
 func (s *Server) notifyMenuChanged() {
@@ -27,6 +27,29 @@ func (f *fakeStorage) Close() error {
 	return nil
 }
 
+// newSessionTestServer returns a server for "test-profile" whose storage
+// holds one claude session, "sess-api" titled "api" in group "work". wrap,
+// when set, decorates the storage, e.g. to expose a state database.
+func newSessionTestServer(t *testing.T, readOnly bool, wrap func(*fakeStorage) storageLoader) *Server {
+	t.Helper()
+	inst := session.NewInstanceWithGroupAndTool("api", "/tmp/api", "work", "claude")
+	inst.ID = "sess-api"
+
+	srv := NewServer(Config{
+		ListenAddr: "127.0.0.1:0",
+		Profile:    "test-profile",
+		ReadOnly:   readOnly,
+	})
+	srv.openStorage = func(profile string) (storageLoader, error) {
+		storage := &fakeStorage{instances: []*session.Instance{inst}}
+		if wrap != nil {
+			return wrap(storage), nil
+		}
+		return storage, nil
+	}
+	return srv
+}
+
 func TestSessionDataService_LoadMenuSnapshot(t *testing.T) {
 	instWork := session.NewInstanceWithGroupAndTool("work-main", "/tmp/work", "work", "claude")
 	instWork.ID = "sess-work"
@@ -0,0 +1,306 @@
+package web
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+const webSharesFileName = "web_shares.json"
+
+// ShareLink is a revocable, expiring read-only grant for one session.
+type ShareLink struct {
+	ID             string    `json:"id"`
+	SessionID      string    `json:"sessionId"`
+	SessionTitle   string    `json:"sessionTitle,omitempty"`
+	TranscriptOnly bool      `json:"transcriptOnly,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+}
+
+// Expired reports whether the link is past its expiry at now.
+func (l ShareLink) Expired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+type webSharesFile struct {
+	// Key signs share tokens. Rotating it invalidates every outstanding link.
+	Key    string      `json:"key"`
+	Shares []ShareLink `json:"shares"`
+}
+
+// sharesMu serializes read-modify-write cycles on share stores within this
+// process (TUI and web server may both touch the file).
+var sharesMu sync.Mutex
+
+func webSharesPath(profile string) (string, error) {
+	profileDir, err := session.GetProfileDir(session.GetEffectiveProfile(profile))
+	if err != nil {
+		return "", fmt.Errorf("resolve profile dir: %w", err)
+	}
+	return filepath.Join(profileDir, webSharesFileName), nil
+}
+
+// CreateShareLink mints a share for sessionID valid for ttl and returns the
+// record plus the signed token to embed in the link.
+func CreateShareLink(profile, sessionID, sessionTitle string, ttl time.Duration, transcriptOnly bool) (ShareLink, string, error) {
+	if strings.TrimSpace(sessionID) == "" {
+		return ShareLink{}, "", fmt.Errorf("session id is required")
+	}
+	if ttl <= 0 {
+		return ShareLink{}, "", fmt.Errorf("ttl must be positive")
+	}
+
+	sharesMu.Lock()
+	defer sharesMu.Unlock()
+
+	path, err := webSharesPath(profile)
+	if err != nil {
+		return ShareLink{}, "", err
+	}
+	file, err := loadWebSharesFile(path)
+	if err != nil {
+		return ShareLink{}, "", err
+	}
+	if file.Key == "" {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return ShareLink{}, "", fmt.Errorf("generate share key: %w", err)
+		}
+		file.Key = hex.EncodeToString(key)
+	}
+
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return ShareLink{}, "", fmt.Errorf("generate share id: %w", err)
+	}
+
+	now := time.Now().UTC()
+	link := ShareLink{
+		ID:             hex.EncodeToString(idBytes),
+		SessionID:      sessionID,
+		SessionTitle:   sessionTitle,
+		TranscriptOnly: transcriptOnly,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(ttl),
+	}
+	file.Shares = append(pruneExpiredShares(file.Shares, now), link)
+	if err := writeWebSharesFile(path, file); err != nil {
+		return ShareLink{}, "", err
+	}
+	return link, signShareToken(file.Key, link), nil
+}
+
+// ListShareLinks returns the profile's unexpired shares, newest first.
+func ListShareLinks(profile string) ([]ShareLink, error) {
+	path, err := webSharesPath(profile)
+	if err != nil {
+		return nil, err
+	}
+	file, err := loadWebSharesFile(path)
+	if err != nil {
+		return nil, err
+	}
+	active := pruneExpiredShares(file.Shares, time.Now())
+	sort.Slice(active, func(i, j int) bool { return active[i].CreatedAt.After(active[j].CreatedAt) })
+	return active, nil
+}
+
+// RevokeShareLink deletes a share by ID (or unique ID prefix). Passing a
+// session ID revokes every share of that session. It returns the number of
+// shares removed.
+func RevokeShareLink(profile, idOrSession string) (int, error) {
+	idOrSession = strings.TrimSpace(idOrSession)
+	if idOrSession == "" {
+		return 0, fmt.Errorf("share id is required")
+	}
+
+	sharesMu.Lock()
+	defer sharesMu.Unlock()
+
+	path, err := webSharesPath(profile)
+	if err != nil {
+		return 0, err
+	}
+	file, err := loadWebSharesFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var prefixMatches []string
+	for _, sh := range file.Shares {
+		if strings.HasPrefix(sh.ID, idOrSession) {
+			prefixMatches = append(prefixMatches, sh.ID)
+		}
+	}
+	if len(prefixMatches) > 1 {
+		return 0, fmt.Errorf("share id %q is ambiguous", idOrSession)
+	}
+
+	kept := make([]ShareLink, 0, len(file.Shares))
+	removed := 0
+	for _, sh := range file.Shares {
+		if (len(prefixMatches) == 1 && sh.ID == prefixMatches[0]) || sh.SessionID == idOrSession {
+			removed++
+			continue
+		}
+		kept = append(kept, sh)
+	}
+	if removed == 0 {
+		return 0, fmt.Errorf("share %q not found", idOrSession)
+	}
+	file.Shares = kept
+	return removed, writeWebSharesFile(path, file)
+}
+
+// verifyShareToken checks a token's signature, expiry and revocation status
+// against the profile's share store.
+func verifyShareToken(profile, token string, now time.Time) (*ShareLink, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed share token")
+	}
+	expiryUnix, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, errors.New("malformed share token")
+	}
+	if !now.Before(time.Unix(expiryUnix, 0)) {
+		return nil, errors.New("share link expired")
+	}
+
+	path, err := webSharesPath(profile)
+	if err != nil {
+		return nil, err
+	}
+	file, err := loadWebSharesFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if file.Key == "" {
+		return nil, errors.New("share link revoked")
+	}
+	for _, sh := range file.Shares {
+		if sh.ID != parts[0] {
+			continue
+		}
+		if !hmac.Equal([]byte(signShareToken(file.Key, sh)), []byte(token)) {
+			return nil, errors.New("invalid share signature")
+		}
+		if sh.Expired(now) {
+			return nil, errors.New("share link expired")
+		}
+		link := sh
+		return &link, nil
+	}
+	return nil, errors.New("share link revoked")
+}
+
+// signShareToken returns "<id>.<expiry>.<hmac>" where the HMAC covers the
+// share ID, session, expiry and transcript-only flag.
+func signShareToken(key string, link ShareLink) string {
+	expiry := strconv.FormatInt(link.ExpiresAt.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(key))
+	fmt.Fprintf(mac, "%s|%s|%s|%t", link.ID, link.SessionID, expiry, link.TranscriptOnly)
+	return link.ID + "." + expiry + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+func pruneExpiredShares(shares []ShareLink, now time.Time) []ShareLink {
+	out := make([]ShareLink, 0, len(shares))
+	for _, sh := range shares {
+		if !sh.Expired(now) {
+			out = append(out, sh)
+		}
+	}
+	return out
+}
+
+func loadWebSharesFile(path string) (*webSharesFile, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &webSharesFile{}, nil
+		}
+		return nil, fmt.Errorf("read web shares file: %w", err)
+	}
+	var file webSharesFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("parse web shares file: %w", err)
+	}
+	return &file, nil
+}
+
+func writeWebSharesFile(path string, file *webSharesFile) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("mkdir web shares dir: %w", err)
+	}
+	raw, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal web shares: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("write temp web shares: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename web shares file: %w", err)
+	}
+	return nil
+}
+
+// ShareViewers counts live share-link viewers per session. The TUI reads it
+// to show who is watching when it runs alongside the web server.
+type ShareViewers struct {
+	mu     sync.RWMutex
+	counts map[string]int
+}
+
+// NewShareViewers creates an empty viewer tracker.
+func NewShareViewers() *ShareViewers {
+	return &ShareViewers{counts: make(map[string]int)}
+}
+
+func (v *ShareViewers) add(sessionID string) {
+	if v == nil {
+		return
+	}
+	v.mu.Lock()
+	v.counts[sessionID]++
+	v.mu.Unlock()
+}
+
+func (v *ShareViewers) remove(sessionID string) {
+	if v == nil {
+		return
+	}
+	v.mu.Lock()
+	if v.counts[sessionID] <= 1 {
+		delete(v.counts, sessionID)
+	} else {
+		v.counts[sessionID]--
+	}
+	v.mu.Unlock()
+}
+
+// Count returns the number of live viewers watching sessionID via share links.
+func (v *ShareViewers) Count(sessionID string) int {
+	if v == nil {
+		return 0
+	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.counts[sessionID]
+}
@@ -0,0 +1,130 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShareLinkCreateVerifyRevoke(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	link, token, err := CreateShareLink("share-profile", "sess-1", "Demo", time.Hour, false)
+	if err != nil {
+		t.Fatalf("CreateShareLink: %v", err)
+	}
+
+	got, err := verifyShareToken("share-profile", token, time.Now())
+	if err != nil {
+		t.Fatalf("verifyShareToken: %v", err)
+	}
+	if got.SessionID != "sess-1" || got.ID != link.ID {
+		t.Fatalf("unexpected share: %+v", got)
+	}
+
+	if _, err := verifyShareToken("share-profile", token, time.Now().Add(2*time.Hour)); err == nil {
+		t.Fatalf("expected expired token to fail")
+	}
+
+	tampered := strings.Replace(token, link.ID, link.ID[:len(link.ID)-1]+"0", 1)
+	if tampered != token {
+		if _, err := verifyShareToken("share-profile", tampered, time.Now()); err == nil {
+			t.Fatalf("expected tampered token to fail")
+		}
+	}
+
+	links, err := ListShareLinks("share-profile")
+	if err != nil || len(links) != 1 {
+		t.Fatalf("expected one active share, got %v (err=%v)", links, err)
+	}
+
+	removed, err := RevokeShareLink("share-profile", link.ID[:6])
+	if err != nil || removed != 1 {
+		t.Fatalf("RevokeShareLink: removed=%d err=%v", removed, err)
+	}
+	if _, err := verifyShareToken("share-profile", token, time.Now()); err == nil {
+		t.Fatalf("expected revoked token to fail")
+	}
+}
+
+func TestShareTokenConfinedToSharedSession(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	srv := NewServer(Config{
+		ListenAddr: "127.0.0.1:0",
+		Profile:    "share-profile",
+		Token:      "owner-secret",
+	})
+	srv.menuData = &fakeMenuDataLoader{snapshot: &MenuSnapshot{
+		Profile: "share-profile",
+		Items: []MenuItem{
+			{Type: MenuItemTypeSession, Session: &MenuSession{ID: "sess-1", Title: "Demo"}},
+			{Type: MenuItemTypeSession, Session: &MenuSession{ID: "sess-2", Title: "Other"}},
+		},
+	}}
+
+	_, token, err := CreateShareLink("share-profile", "sess-1", "Demo", time.Hour, true)
+	if err != nil {
+		t.Fatalf("CreateShareLink: %v", err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path+"?share="+token, nil)
+		rr := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("/api/share")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected share info, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var info shareInfoResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &info); err != nil {
+		t.Fatalf("decode share info: %v", err)
+	}
+	if info.SessionID != "sess-1" || !info.TranscriptOnly {
+		t.Fatalf("unexpected share info: %+v", info)
+	}
+
+	if rr := get("/api/messages/sess-2"); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected other session transcript to be rejected, got %d", rr.Code)
+	}
+	if rr := get("/api/menu"); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected menu to be rejected for share, got %d", rr.Code)
+	}
+	if rr := get("/ws/session/sess-1"); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected terminal to be rejected for transcript-only share, got %d", rr.Code)
+	}
+	if rr := get("/api/messages/sess-1"); rr.Code == http.StatusUnauthorized {
+		t.Fatalf("expected shared transcript to be readable")
+	}
+
+	if _, err := RevokeShareLink("share-profile", "sess-1"); err != nil {
+		t.Fatalf("RevokeShareLink: %v", err)
+	}
+	if rr := get("/api/share"); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected revoked share to be rejected, got %d", rr.Code)
+	}
+}
+
+func TestShareViewersCount(t *testing.T) {
+	v := NewShareViewers()
+	v.add("s")
+	v.add("s")
+	if v.Count("s") != 2 {
+		t.Fatalf("expected 2 viewers, got %d", v.Count("s"))
+	}
+	v.remove("s")
+	v.remove("s")
+	if v.Count("s") != 0 {
+		t.Fatalf("expected 0 viewers, got %d", v.Count("s"))
+	}
+	var nilTracker *ShareViewers
+	if nilTracker.Count("s") != 0 {
+		t.Fatalf("expected nil tracker to report 0")
+	}
+}
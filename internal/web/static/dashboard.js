@@ -12,6 +12,7 @@
     statusFilters: [],
     viewMode: "tier",
     authToken: readAuthTokenFromURL(),
+    profileBase: readProfileBaseFromURL(),  // "/p/<profile>" or "" for the primary profile
     terminal: null,
     terminalWs: null,
     previewStream: null,
@@ -110,7 +111,14 @@
     return String(params.get("token") || "").trim()
   }
 
+  // Pages under /p/<profile>/ talk to that profile's API and WebSockets.
+  function readProfileBaseFromURL() {
+    var m = /^\/p\/([^/]+)/.exec(window.location.pathname || "")
+    return m ? "/p/" + m[1] : ""
+  }
+
   function apiPathWithToken(path) {
+    path = state.profileBase + path
     if (!state.authToken) return path
     var url = new URL(path, window.location.origin)
     url.searchParams.set("token", state.authToken)
@@ -123,6 +131,24 @@
     return h
   }
 
+  // Exchange a ?token= credential for an HttpOnly login cookie, then drop
+  // the token from the address bar and from subsequent request URLs.
+  function loginWithURLToken() {
+    if (!state.authToken) return
+    fetch("/api/login", {
+      method: "POST",
+      headers: { "Content-Type": "application/json", Accept: "application/json" },
+      credentials: "same-origin",
+      body: JSON.stringify({ token: state.authToken }),
+    }).then(function (res) {
+      if (!res.ok) return
+      state.authToken = ""
+      var url = new URL(window.location.href)
+      url.searchParams.delete("token")
+      window.history.replaceState(null, "", url.pathname + url.search + url.hash)
+    }).catch(function () {})
+  }
+
   // ── Helpers: safe DOM construction ────────────────────────────────
   function el(tag, className, textContent) {
     var node = document.createElement(tag)
@@ -239,6 +265,68 @@
     if (countEl) countEl.textContent = active + " agent" + (active !== 1 ? "s" : "")
   }
 
+  // ── Profiles ─────────────────────────────────────────────────────
+  // The switcher only appears when the server serves more than one profile.
+  function loadProfiles() {
+    fetch(withToken("/api/profiles"), { headers: authHeaders() })
+      .then(function (r) { return r.ok ? r.json() : null })
+      .then(function (data) {
+        if (!data || !data.profiles || data.profiles.length < 2) return
+        renderProfileSwitcher(data.profiles)
+        loadAllWaiting()
+      })
+      .catch(function () {})
+  }
+
+  // withToken adds the auth token to server-wide paths that are not scoped
+  // to the current profile.
+  function withToken(path) {
+    if (!state.authToken) return path
+    return path + (path.indexOf("?") === -1 ? "?" : "&") + "token=" + encodeURIComponent(state.authToken)
+  }
+
+  function renderProfileSwitcher(profiles) {
+    var select = document.getElementById("profile-switcher")
+    if (!select) return
+    var current = state.profileBase ? decodeURIComponent(state.profileBase.slice(3)) : ""
+    select.innerHTML = ""
+    profiles.forEach(function (p) {
+      var opt = document.createElement("option")
+      opt.value = p.name
+      opt.textContent = p.name + (p.waiting ? " (" + p.waiting + ")" : "")
+      if (p.name === current || (!current && p.primary)) opt.selected = true
+      select.appendChild(opt)
+    })
+    select.hidden = false
+    select.onchange = function () {
+      window.location.href = "/p/" + encodeURIComponent(select.value) + "/"
+    }
+  }
+
+  // loadAllWaiting fills the cross-profile "waiting" list in the sidebar.
+  function loadAllWaiting() {
+    var btn = document.getElementById("all-waiting-btn")
+    var list = document.getElementById("all-waiting-list")
+    if (!btn || !list) return
+    fetch(withToken("/api/waiting"), { headers: authHeaders() })
+      .then(function (r) { return r.ok ? r.json() : null })
+      .then(function (data) {
+        var sessions = (data && data.sessions) || []
+        btn.textContent = sessions.length + " waiting"
+        btn.hidden = false
+        list.innerHTML = ""
+        sessions.forEach(function (w) {
+          var a = document.createElement("a")
+          a.className = "all-waiting-item"
+          a.href = w.path
+          a.textContent = w.profile + " / " + (w.session.title || w.session.id)
+          list.appendChild(a)
+        })
+        btn.onclick = function () { list.hidden = !list.hidden }
+      })
+      .catch(function () {})
+  }
+
   // ── ConnectionManager (WebSocket with auto-reconnect) ────────────
   function ConnectionManager(url) {
     this.url = url
@@ -2590,7 +2678,7 @@
   // Connect terminal to WebSocket for local sessions with live PTY streaming.
   function connectWebSocket(sessionId, term) {
     var protocol = window.location.protocol === "https:" ? "wss:" : "ws:"
-    var wsUrl = protocol + "//" + window.location.host + "/ws/session/" + encodeURIComponent(sessionId)
+    var wsUrl = protocol + "//" + window.location.host + state.profileBase + "/ws/session/" + encodeURIComponent(sessionId)
     if (state.authToken) wsUrl += "?token=" + encodeURIComponent(state.authToken)
     var ws = new WebSocket(wsUrl)
     state.terminalWs = ws
@@ -3125,7 +3213,7 @@
     var sessionId = state.selectedTaskId
 
     var protocol = location.protocol === "https:" ? "wss:" : "ws:"
-    var url = protocol + "//" + location.host + "/ws/upload/" + encodeURIComponent(sessionId)
+    var url = protocol + "//" + location.host + state.profileBase + "/ws/upload/" + encodeURIComponent(sessionId)
     var ws = new WebSocket(url)
 
     var progressBar = document.getElementById("upload-progress")
@@ -4194,11 +4282,13 @@
   fetchTasks()
   fetchProjects()
   fetchMenuData()
+  loginWithURLToken()
+  loadProfiles()
 
   // ── ConnectionManager (WebSocket-based event bus) ───────────────
   ;(function initConnectionManager() {
     var wsProto = (location.protocol === "https:") ? "wss:" : "ws:"
-    var wsUrl = wsProto + "//" + location.host + "/ws/events"
+    var wsUrl = wsProto + "//" + location.host + state.profileBase + "/ws/events"
     var token = state.authToken
     if (token) wsUrl += "?token=" + encodeURIComponent(token)
 
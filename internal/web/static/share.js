@@ -0,0 +1,109 @@
+(function () {
+  const root = document.getElementById("share-root")
+  const titleEl = document.getElementById("share-title")
+  const metaEl = document.getElementById("share-meta")
+  const shareToken = String(
+    new URLSearchParams(window.location.search || "").get("share") || "",
+  ).trim()
+
+  function withShare(path) {
+    const url = new URL(path, window.location.origin)
+    url.searchParams.set("share", shareToken)
+    return `${url.pathname}${url.search}`
+  }
+
+  function showMessage(text) {
+    root.textContent = text
+  }
+
+  async function loadTranscript(info) {
+    const res = await fetch(withShare(`/api/messages/${encodeURIComponent(info.sessionId)}/html`))
+    if (!res.ok) {
+      showMessage("Transcript unavailable.")
+      return
+    }
+    root.classList.add("share-transcript")
+    // Server-rendered, sanitized transcript fragment.
+    root.innerHTML = await res.text()
+    metaEl.textContent = `read-only transcript, expires ${new Date(info.expiresAt).toLocaleString()}`
+  }
+
+  function connectTerminal(info) {
+    if (!window.Terminal || !window.FitAddon) {
+      showMessage("Terminal emulator not available. Check xterm.js assets.")
+      return
+    }
+    root.textContent = ""
+    const terminal = new window.Terminal({
+      disableStdin: true,
+      cursorBlink: false,
+      fontFamily: "IBM Plex Mono, Menlo, Consolas, monospace",
+      fontSize: 13,
+      scrollback: 10000,
+      theme: { background: "#0a1220", foreground: "#d9e2ec", cursor: "#9ecbff" },
+    })
+    const fitAddon = new window.FitAddon.FitAddon()
+    terminal.loadAddon(fitAddon)
+    terminal.open(root)
+    fitAddon.fit()
+
+    const wsProto = window.location.protocol === "https:" ? "wss:" : "ws:"
+    const ws = new WebSocket(
+      `${wsProto}//${window.location.host}${withShare(`/ws/session/${encodeURIComponent(info.sessionId)}`)}`,
+    )
+    ws.binaryType = "arraybuffer"
+    const decoder = new TextDecoder()
+
+    ws.addEventListener("open", () => {
+      metaEl.textContent = `read-only, expires ${new Date(info.expiresAt).toLocaleString()}`
+    })
+    ws.addEventListener("message", (event) => {
+      if (event.data instanceof ArrayBuffer) {
+        terminal.write(decoder.decode(new Uint8Array(event.data), { stream: true }))
+        return
+      }
+      try {
+        const msg = JSON.parse(event.data)
+        if (msg.type === "error" && msg.code !== "READ_ONLY") {
+          metaEl.textContent = msg.message || "error"
+        }
+      } catch (_) {
+        // ignore non-JSON frames
+      }
+    })
+    ws.addEventListener("close", () => {
+      metaEl.textContent = "disconnected"
+    })
+  }
+
+  async function init() {
+    if (!shareToken) {
+      showMessage("This share link is missing its token.")
+      return
+    }
+    let info
+    try {
+      const res = await fetch(withShare("/api/share"))
+      if (!res.ok) {
+        showMessage("This share link is invalid, expired or revoked.")
+        metaEl.textContent = "unavailable"
+        return
+      }
+      info = await res.json()
+    } catch (error) {
+      showMessage(`Failed to load share: ${error.message}`)
+      return
+    }
+
+    const title = info.sessionTitle || info.sessionId
+    titleEl.textContent = `Agent Deck Share: ${title}`
+    document.title = `${title} - Agent Deck Share`
+    if (info.transcriptOnly) {
+      await loadTranscript(info)
+    } else {
+      connectTerminal(info)
+    }
+  }
+
+  init()
+})()
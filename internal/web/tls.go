@@ -0,0 +1,249 @@
+package web
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/logging"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+const (
+	selfSignedCertFileName = "web_tls_cert.pem"
+	selfSignedKeyFileName  = "web_tls_key.pem"
+	selfSignedValidity     = 365 * 24 * time.Hour
+)
+
+// EnsureSelfSignedCert returns the per-profile self-signed certificate and key
+// paths, generating a new pair when none exists, the stored one expired or it
+// does not cover every host. hosts are added as SANs (IPs and DNS names);
+// localhost is always included.
+func EnsureSelfSignedCert(profile string, hosts []string) (certPath, keyPath string, generated bool, err error) {
+	profileDir, err := session.GetProfileDir(session.GetEffectiveProfile(profile))
+	if err != nil {
+		return "", "", false, fmt.Errorf("resolve profile dir: %w", err)
+	}
+	certPath = filepath.Join(profileDir, selfSignedCertFileName)
+	keyPath = filepath.Join(profileDir, selfSignedKeyFileName)
+
+	if pair, loadErr := tls.LoadX509KeyPair(certPath, keyPath); loadErr == nil {
+		if leaf, parseErr := x509.ParseCertificate(pair.Certificate[0]); parseErr == nil && time.Now().Before(leaf.NotAfter) && certCoversHosts(leaf, hosts) {
+			return certPath, keyPath, false, nil
+		}
+	} else if !errors.Is(loadErr, os.ErrNotExist) {
+		logging.ForComponent(logging.CompWeb).Warn("tls_self_signed_regenerate",
+			slog.String("error", loadErr.Error()))
+	}
+
+	certPEM, keyPEM, err := generateSelfSignedCert(hosts, time.Now())
+	if err != nil {
+		return "", "", false, err
+	}
+	if err := os.MkdirAll(profileDir, 0o700); err != nil {
+		return "", "", false, fmt.Errorf("mkdir tls dir: %w", err)
+	}
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		return "", "", false, fmt.Errorf("write tls key: %w", err)
+	}
+	if err := os.WriteFile(certPath, certPEM, 0o644); err != nil {
+		return "", "", false, fmt.Errorf("write tls cert: %w", err)
+	}
+	return certPath, keyPath, true, nil
+}
+
+// certCoversHosts reports whether cert is valid for every host.
+func certCoversHosts(cert *x509.Certificate, hosts []string) bool {
+	for _, h := range hosts {
+		if h != "" && cert.VerifyHostname(h) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func generateSelfSignedCert(hosts []string, now time.Time) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate tls key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate serial: %w", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Agent Deck"}, CommonName: "agent-deck web"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, h := range append([]string{"localhost", "127.0.0.1", "::1"}, hosts...) {
+		if h == "" {
+			continue
+		}
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal tls key: %w", err)
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// certReloader serves the current certificate and client CA pool, and
+// re-reads them from disk on Reload.
+type certReloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+}
+
+func newCertReloader(certFile, keyFile, clientCAFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, clientCAFile: clientCAFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload re-reads the certificate, key and client CA bundle. On error the
+// previously loaded material stays active.
+func (r *certReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load tls keypair: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.clientCAFile != "" {
+		raw, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("read client ca: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(raw) {
+			return fmt.Errorf("client ca file %s contains no certificates", r.clientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCA = pool
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// tlsConfig builds a config whose certificate and client CA pool follow
+// Reload. Client certificates are verified when presented and required only
+// when requireClientCert is set.
+func (r *certReloader) tlsConfig(requireClientCert bool) *tls.Config {
+	base := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.getCertificate,
+	}
+	if r.clientCAFile == "" {
+		return base
+	}
+
+	clientAuth := tls.VerifyClientCertIfGiven
+	if requireClientCert {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mu.RLock()
+		pool := r.clientCA
+		r.mu.RUnlock()
+		cfg := base.Clone()
+		cfg.GetConfigForClient = nil
+		cfg.ClientAuth = clientAuth
+		cfg.ClientCAs = pool
+		return cfg, nil
+	}
+	return base
+}
+
+// TLSEnabled reports whether the server terminates TLS itself.
+func (s *Server) TLSEnabled() bool {
+	return s.tls != nil
+}
+
+// URL returns the base URL the server listens on.
+func (s *Server) URL() string {
+	if s.TLSEnabled() {
+		return "https://" + s.Addr()
+	}
+	return "http://" + s.Addr()
+}
+
+// ReloadTLS re-reads the certificate, key and client CA from disk. It also
+// surfaces the initial load error when TLS was configured but failed.
+func (s *Server) ReloadTLS() error {
+	if s.tls == nil {
+		return s.tlsErr
+	}
+	return s.tls.Reload()
+}
+
+// ReloadTLSOnSIGHUP reloads certificates on SIGHUP until ctx is cancelled.
+// It captures SIGHUP for the whole process, so only the standalone web
+// server installs it.
+func (s *Server) ReloadTLSOnSIGHUP(ctx context.Context) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	defer signal.Stop(sigCh)
+
+	webLog := logging.ForComponent(logging.CompWeb)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigCh:
+			if err := s.ReloadTLS(); err != nil {
+				webLog.Warn("tls_reload_failed", slog.String("error", err.Error()))
+			} else {
+				webLog.Info("tls_reloaded")
+			}
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package web
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEnsureSelfSignedCertCreatesAndReuses(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	cert1, key1, generated, err := EnsureSelfSignedCert("tls-profile", []string{"deck.vpn", "10.0.0.5"})
+	if err != nil {
+		t.Fatalf("EnsureSelfSignedCert: %v", err)
+	}
+	if !generated {
+		t.Fatalf("expected first call to generate a certificate")
+	}
+	if filepath.Dir(cert1) != filepath.Join(home, ".agent-deck", "profiles", "tls-profile") {
+		t.Fatalf("expected cert under profile dir, got %s", cert1)
+	}
+
+	pair, err := tls.LoadX509KeyPair(cert1, key1)
+	if err != nil {
+		t.Fatalf("load generated pair: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		t.Fatalf("parse leaf: %v", err)
+	}
+	if err := leaf.VerifyHostname("deck.vpn"); err != nil {
+		t.Fatalf("expected SAN for deck.vpn: %v", err)
+	}
+	if err := leaf.VerifyHostname("10.0.0.5"); err != nil {
+		t.Fatalf("expected SAN for 10.0.0.5: %v", err)
+	}
+
+	cert2, _, generated, err := EnsureSelfSignedCert("tls-profile", nil)
+	if err != nil {
+		t.Fatalf("EnsureSelfSignedCert second call: %v", err)
+	}
+	if generated || cert2 != cert1 {
+		t.Fatalf("expected existing certificate to be reused")
+	}
+	if _, _, generated, _ = EnsureSelfSignedCert("tls-profile", []string{"10.0.0.5"}); generated {
+		t.Fatalf("expected a certificate covering the hosts to be reused")
+	}
+
+	// A host the stored certificate lacks forces a new one
+	_, _, generated, err = EnsureSelfSignedCert("tls-profile", []string{"deck.vpn", "deck.lan"})
+	if err != nil || !generated {
+		t.Fatalf("expected regeneration for a new host, generated=%v err=%v", generated, err)
+	}
+	pair, _ = tls.LoadX509KeyPair(cert1, key1)
+	leaf, _ = x509.ParseCertificate(pair.Certificate[0])
+	if err := leaf.VerifyHostname("deck.lan"); err != nil {
+		t.Fatalf("expected SAN for deck.lan: %v", err)
+	}
+}
+
+func TestServerRejectsIncompleteTLSConfig(t *testing.T) {
+	srv := NewServer(Config{ListenAddr: "127.0.0.1:0", TLSCertFile: "/nonexistent.pem"})
+	if srv.TLSEnabled() {
+		t.Fatalf("expected TLS to stay disabled without a key")
+	}
+	if err := srv.ReloadTLS(); err == nil {
+		t.Fatalf("expected setup error to surface")
+	}
+}
+
+// testCA issues client certificates for mTLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (ca *testCA) clientCert(t *testing.T, cn string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestServerMutualTLSMapsClientCertToIdentity(t *testing.T) {
+	dir := t.TempDir()
+	certPEM, keyPEM, err := generateSelfSignedCert(nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	caFile := filepath.Join(dir, "ca.pem")
+	ca := newTestCA(t)
+	for path, data := range map[string][]byte{certFile: certPEM, keyFile: keyPEM, caFile: ca.pem} {
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := NewServer(Config{
+		ListenAddr:           "127.0.0.1:0",
+		Profile:              "test",
+		Tokens:               []APIToken{{Name: "alice", Hash: hashTokenSecret("unused"), Scopes: []Scope{ScopeMenuRead}}},
+		TLSCertFile:          certFile,
+		TLSKeyFile:           keyFile,
+		TLSClientCAFile:      caFile,
+		TLSRequireClientCert: true,
+	})
+	if !srv.TLSEnabled() {
+		t.Fatalf("expected TLS to be enabled: %v", srv.ReloadTLS())
+	}
+	srv.menuData = &fakeMenuDataLoader{snapshot: &MenuSnapshot{Profile: "test"}}
+
+	ts := httptest.NewUnstartedServer(srv.Handler())
+	ts.TLS = srv.httpServer.TLSConfig
+	ts.StartTLS()
+	defer ts.Close()
+
+	rootPool := x509.NewCertPool()
+	rootPool.AppendCertsFromPEM(certPEM)
+	clientFor := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      rootPool,
+			ServerName:   "127.0.0.1",
+			Certificates: certs,
+		}}}
+	}
+
+	if _, err := clientFor().Get(ts.URL + "/api/menu"); err == nil {
+		t.Fatalf("expected handshake without client cert to fail")
+	}
+
+	resp, err := clientFor(ca.clientCert(t, "alice")).Get(ts.URL + "/api/menu")
+	if err != nil {
+		t.Fatalf("mTLS request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected mapped cert to be authorized, got %d: %s", resp.StatusCode, body)
+	}
+
+	resp, err = clientFor(ca.clientCert(t, "mallory")).Get(ts.URL + "/api/menu")
+	if err != nil {
+		t.Fatalf("mTLS request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected unmapped cert to be rejected when tokens are configured, got %d", resp.StatusCode)
+	}
+
+	// Reload picks up a rotated certificate.
+	newCertPEM, newKeyPEM, err := generateSelfSignedCert(nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(certFile, newCertPEM, 0o600)
+	_ = os.WriteFile(keyFile, newKeyPEM, 0o600)
+	if err := srv.ReloadTLS(); err != nil {
+		t.Fatalf("ReloadTLS: %v", err)
+	}
+	current, _ := srv.tls.getCertificate(nil)
+	if current == nil {
+		t.Fatalf("expected reloaded certificate")
+	}
+	leaf, _ := x509.ParseCertificate(current.Certificate[0])
+	newBlock, _ := pem.Decode(newCertPEM)
+	if string(leaf.Raw) != string(newBlock.Bytes) {
+		t.Fatalf("expected reload to swap in the rotated certificate")
+	}
+}
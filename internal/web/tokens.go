@@ -0,0 +1,268 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+const webTokensFileName = "web_tokens.json"
+
+// tokenSecretPrefix marks agent-deck API tokens so they are easy to spot in
+// shell history or secret scanners.
+const tokenSecretPrefix = "adt_"
+
+// Scope is a permission granted to an API token.
+type Scope string
+
+const (
+	// ScopeMenuRead allows reading the session menu, session details and events.
+	ScopeMenuRead Scope = "menu:read"
+	// ScopeTranscriptRead allows reading conversation transcripts.
+	ScopeTranscriptRead Scope = "transcripts:read"
+	// ScopeTerminalInput allows typing into terminals and uploading files.
+	ScopeTerminalInput Scope = "terminal:input"
+	// ScopeTaskCreate allows creating and driving hub tasks.
+	ScopeTaskCreate Scope = "tasks:create"
+	// ScopeAdmin grants every other scope plus hub administration.
+	ScopeAdmin Scope = "admin"
+)
+
+// AllScopes lists every known scope in display order.
+var AllScopes = []Scope{ScopeMenuRead, ScopeTranscriptRead, ScopeTerminalInput, ScopeTaskCreate, ScopeAdmin}
+
+// ParseScopes parses a comma-separated scope list. Unknown scopes are an error.
+func ParseScopes(raw string) ([]Scope, error) {
+	var scopes []Scope
+	seen := make(map[Scope]bool)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		scope := Scope(part)
+		known := false
+		for _, s := range AllScopes {
+			if s == scope {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown scope %q", part)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	return scopes, nil
+}
+
+// APIToken is a named, scoped credential for the web server. Only the SHA-256
+// hash of the secret is persisted.
+type APIToken struct {
+	Name      string    `json:"name"`
+	Hash      string    `json:"hash"`
+	Scopes    []Scope   `json:"scopes"`
+	Profiles  []string  `json:"profiles,omitempty"`
+	Groups    []string  `json:"groups,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type webTokensFile struct {
+	Tokens []APIToken `json:"tokens"`
+}
+
+// webTokensPath returns the token store location for a profile.
+func webTokensPath(profile string) (string, error) {
+	profileDir, err := session.GetProfileDir(session.GetEffectiveProfile(profile))
+	if err != nil {
+		return "", fmt.Errorf("resolve profile dir: %w", err)
+	}
+	return filepath.Join(profileDir, webTokensFileName), nil
+}
+
+// LoadAPITokens returns the persisted API tokens for a profile, sorted by name.
+// A missing store yields an empty list.
+func LoadAPITokens(profile string) ([]APIToken, error) {
+	path, err := webTokensPath(profile)
+	if err != nil {
+		return nil, err
+	}
+	file, err := loadWebTokensFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return file.Tokens, nil
+}
+
+// CreateAPIToken mints a new token for the profile and returns its plaintext
+// secret. The secret is only shown once; the store keeps its hash.
+func CreateAPIToken(profile string, tok APIToken) (string, error) {
+	tok.Name = strings.TrimSpace(tok.Name)
+	if tok.Name == "" {
+		return "", fmt.Errorf("token name is required")
+	}
+	if len(tok.Scopes) == 0 {
+		return "", fmt.Errorf("at least one scope is required")
+	}
+
+	path, err := webTokensPath(profile)
+	if err != nil {
+		return "", err
+	}
+	file, err := loadWebTokensFile(path)
+	if err != nil {
+		return "", err
+	}
+	for _, existing := range file.Tokens {
+		if existing.Name == tok.Name {
+			return "", fmt.Errorf("token %q already exists", tok.Name)
+		}
+	}
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	secret := tokenSecretPrefix + hex.EncodeToString(buf)
+
+	tok.Hash = hashTokenSecret(secret)
+	tok.CreatedAt = time.Now().UTC()
+	file.Tokens = append(file.Tokens, tok)
+	if err := writeWebTokensFile(path, file); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// RevokeAPIToken removes the named token from the profile's store.
+func RevokeAPIToken(profile, name string) error {
+	path, err := webTokensPath(profile)
+	if err != nil {
+		return err
+	}
+	file, err := loadWebTokensFile(path)
+	if err != nil {
+		return err
+	}
+	kept := file.Tokens[:0]
+	found := false
+	for _, tok := range file.Tokens {
+		if tok.Name == name {
+			found = true
+			continue
+		}
+		kept = append(kept, tok)
+	}
+	if !found {
+		return fmt.Errorf("token %q not found", name)
+	}
+	file.Tokens = kept
+	return writeWebTokensFile(path, file)
+}
+
+// tokenStore serves a profile's API tokens, re-reading the store whenever
+// it changes on disk so created and revoked tokens apply without a restart.
+type tokenStore struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	tokens  []APIToken
+}
+
+func newTokenStore(profile string, initial []APIToken) *tokenStore {
+	path, err := webTokensPath(profile)
+	if err != nil {
+		return nil
+	}
+	store := &tokenStore{path: path, tokens: initial}
+	if info, err := os.Stat(path); err == nil {
+		store.modTime, store.size = info.ModTime(), info.Size()
+	}
+	return store
+}
+
+// current returns the latest tokens. If the store can't be read the last
+// good tokens stay in effect.
+func (t *tokenStore) current() []APIToken {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	info, err := os.Stat(t.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			t.modTime, t.size, t.tokens = time.Time{}, 0, nil
+		}
+		return t.tokens
+	}
+	if info.ModTime().Equal(t.modTime) && info.Size() == t.size {
+		return t.tokens
+	}
+	file, err := loadWebTokensFile(t.path)
+	if err != nil {
+		return t.tokens
+	}
+	t.modTime, t.size, t.tokens = info.ModTime(), info.Size(), file.Tokens
+	return t.tokens
+}
+
+func hashTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func loadWebTokensFile(path string) (*webTokensFile, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &webTokensFile{}, nil
+		}
+		return nil, fmt.Errorf("read web tokens file: %w", err)
+	}
+
+	var file webTokensFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("parse web tokens file: %w", err)
+	}
+	sort.Slice(file.Tokens, func(i, j int) bool { return file.Tokens[i].Name < file.Tokens[j].Name })
+	return &file, nil
+}
+
+func writeWebTokensFile(path string, file *webTokensFile) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("mkdir web tokens dir: %w", err)
+	}
+
+	raw, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal web tokens: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("write temp web tokens: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename web tokens file: %w", err)
+	}
+	return nil
+}
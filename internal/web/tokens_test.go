@@ -0,0 +1,371 @@
+package web
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/eventbus"
+)
+
+func TestCreateListRevokeAPIToken(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	secret, err := CreateAPIToken("tok-profile", APIToken{
+		Name:   "viewer",
+		Scopes: []Scope{ScopeMenuRead},
+	})
+	if err != nil {
+		t.Fatalf("CreateAPIToken: %v", err)
+	}
+	if !strings.HasPrefix(secret, tokenSecretPrefix) {
+		t.Fatalf("expected secret prefix %q, got %q", tokenSecretPrefix, secret)
+	}
+
+	if _, err := CreateAPIToken("tok-profile", APIToken{Name: "viewer", Scopes: []Scope{ScopeAdmin}}); err == nil {
+		t.Fatalf("expected duplicate name to fail")
+	}
+
+	tokens, err := LoadAPITokens("tok-profile")
+	if err != nil {
+		t.Fatalf("LoadAPITokens: %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].Name != "viewer" {
+		t.Fatalf("unexpected tokens: %+v", tokens)
+	}
+	if tokens[0].Hash == secret || tokens[0].Hash != hashTokenSecret(secret) {
+		t.Fatalf("expected only the secret hash to be stored")
+	}
+
+	if err := RevokeAPIToken("tok-profile", "viewer"); err != nil {
+		t.Fatalf("RevokeAPIToken: %v", err)
+	}
+	if err := RevokeAPIToken("tok-profile", "viewer"); err == nil {
+		t.Fatalf("expected revoking a missing token to fail")
+	}
+	tokens, _ = LoadAPITokens("tok-profile")
+	if len(tokens) != 0 {
+		t.Fatalf("expected no tokens after revoke, got %+v", tokens)
+	}
+}
+
+func TestRevokedTokenRejectedWithoutRestart(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	viewer, err := CreateAPIToken("tok-profile", APIToken{Name: "viewer", Scopes: []Scope{ScopeMenuRead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, _ := LoadAPITokens("tok-profile")
+	srv := newScopedTestServer(t, tokens...)
+	srv.tokens = newTokenStore("tok-profile", tokens)
+
+	menuStatus := func(secret string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/menu", nil)
+		req.Header.Set("Authorization", "Bearer "+secret)
+		rr := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rr, req)
+		return rr.Code
+	}
+	if code := menuStatus(viewer); code != http.StatusOK {
+		t.Fatalf("expected token accepted, got %d", code)
+	}
+
+	later, err := CreateAPIToken("tok-profile", APIToken{Name: "later", Scopes: []Scope{ScopeMenuRead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := menuStatus(later); code != http.StatusOK {
+		t.Fatalf("expected token created after start accepted, got %d", code)
+	}
+
+	for _, name := range []string{"viewer", "later"} {
+		if err := RevokeAPIToken("tok-profile", name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if code := menuStatus(viewer); code != http.StatusUnauthorized {
+		t.Fatalf("expected revoked token rejected, got %d", code)
+	}
+	// Revoking every token must not turn auth off
+	if code := menuStatus(""); code != http.StatusUnauthorized {
+		t.Fatalf("expected anonymous request rejected, got %d", code)
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := ParseScopes("menu:read, transcripts:read,menu:read")
+	if err != nil {
+		t.Fatalf("ParseScopes: %v", err)
+	}
+	if len(scopes) != 2 {
+		t.Fatalf("expected deduplicated scopes, got %v", scopes)
+	}
+	if _, err := ParseScopes("menu:write"); err == nil {
+		t.Fatalf("expected unknown scope to fail")
+	}
+	if _, err := ParseScopes(""); err == nil {
+		t.Fatalf("expected empty scope list to fail")
+	}
+}
+
+func newScopedTestServer(t *testing.T, tokens ...APIToken) *Server {
+	t.Helper()
+	srv := NewServer(Config{
+		ListenAddr:   "127.0.0.1:0",
+		Profile:      "test",
+		Tokens:       tokens,
+		AuditLogPath: filepath.Join(t.TempDir(), AuditLogFileName),
+	})
+	srv.menuData = &fakeMenuDataLoader{
+		snapshot: &MenuSnapshot{
+			Profile:       "test",
+			TotalGroups:   2,
+			TotalSessions: 2,
+			Items: []MenuItem{
+				{Index: 0, Type: MenuItemTypeGroup, Group: &MenuGroup{Name: "work", Path: "work"}},
+				{Index: 1, Type: MenuItemTypeSession, Session: &MenuSession{ID: "sess-work", GroupPath: "work/api"}},
+				{Index: 2, Type: MenuItemTypeGroup, Group: &MenuGroup{Name: "personal", Path: "personal"}},
+				{Index: 3, Type: MenuItemTypeSession, Session: &MenuSession{ID: "sess-personal", GroupPath: "personal"}},
+			},
+		},
+	}
+	return srv
+}
+
+func TestScopedTokenEnforcesRouteScopes(t *testing.T) {
+	srv := newScopedTestServer(t, APIToken{
+		Name:   "viewer",
+		Hash:   hashTokenSecret("viewer-secret"),
+		Scopes: []Scope{ScopeMenuRead},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/menu", nil)
+	req.Header.Set("Authorization", "Bearer viewer-secret")
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected menu read to succeed, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/messages/sess-work", nil)
+	req.Header.Set("Authorization", "Bearer viewer-secret")
+	rr = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected transcript read without scope to be rejected, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/tasks", strings.NewReader(`{}`))
+	req.Header.Set("Authorization", "Bearer viewer-secret")
+	rr = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected task create without scope to be rejected, got %d", rr.Code)
+	}
+}
+
+func TestScopedTokenProfileRestriction(t *testing.T) {
+	srv := newScopedTestServer(t, APIToken{
+		Name:     "other",
+		Hash:     hashTokenSecret("other-secret"),
+		Scopes:   []Scope{ScopeAdmin},
+		Profiles: []string{"elsewhere"},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/menu", nil)
+	req.Header.Set("Authorization", "Bearer other-secret")
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected token for another profile to be rejected, got %d", rr.Code)
+	}
+}
+
+func TestScopedTokenGroupRestrictionFiltersMenu(t *testing.T) {
+	srv := newScopedTestServer(t, APIToken{
+		Name:   "work-only",
+		Hash:   hashTokenSecret("work-secret"),
+		Scopes: []Scope{ScopeMenuRead},
+		Groups: []string{"work"},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/menu", nil)
+	req.Header.Set("Authorization", "Bearer work-secret")
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	body := rr.Body.String()
+	if !strings.Contains(body, "sess-work") {
+		t.Fatalf("expected work session in menu, got: %s", body)
+	}
+	if strings.Contains(body, "sess-personal") {
+		t.Fatalf("expected personal session to be filtered, got: %s", body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/session/sess-personal", nil)
+	req.Header.Set("Authorization", "Bearer work-secret")
+	rr = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected out-of-scope session to be hidden, got %d", rr.Code)
+	}
+}
+
+func TestLoginSetsCookieAndAudits(t *testing.T) {
+	srv := newScopedTestServer(t, APIToken{
+		Name:   "reader",
+		Hash:   hashTokenSecret("reader-secret"),
+		Scopes: []Scope{ScopeMenuRead, ScopeTranscriptRead},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/login", bytes.NewBufferString(`{"token":"wrong"}`))
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected bad login to fail, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/login", bytes.NewBufferString(`{"token":"reader-secret"}`))
+	rr = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected login to succeed, got %d: %s", rr.Code, rr.Body.String())
+	}
+	cookies := rr.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != authCookieName || !cookies[0].HttpOnly {
+		t.Fatalf("expected HttpOnly login cookie, got %+v", cookies)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/menu", nil)
+	req.AddCookie(cookies[0])
+	rr = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected cookie auth to succeed, got %d", rr.Code)
+	}
+
+	raw, err := os.ReadFile(srv.cfg.AuditLogPath)
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	if !strings.Contains(string(raw), `"action":"login_failed"`) || !strings.Contains(string(raw), `"identity":"reader"`) {
+		t.Fatalf("expected login events in audit log, got: %s", raw)
+	}
+}
+
+func TestScopedTokenGroupRestrictionHidesUploadTarget(t *testing.T) {
+	srv := newScopedTestServer(t, APIToken{
+		Name:   "work-admin",
+		Hash:   hashTokenSecret("work-secret"),
+		Scopes: []Scope{ScopeAdmin},
+		Groups: []string{"work"},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ws/upload/sess-personal", nil)
+	req.Header.Set("Authorization", "Bearer work-secret")
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected upload to out-of-scope session to be rejected, got %d", rr.Code)
+	}
+}
+
+func TestScopedTokenGroupRestrictionFiltersEvents(t *testing.T) {
+	srv := newScopedTestServer(t)
+	req := httptest.NewRequest(http.MethodGet, "/ws/events", nil)
+	allow := srv.eventFilterFor(req, &Identity{Name: "work-only", Scopes: []Scope{ScopeMenuRead}, Groups: []string{"work"}})
+	if allow == nil {
+		t.Fatal("expected a filter for a group-restricted identity")
+	}
+	if !allow(eventbus.Event{Type: eventbus.EventSessionStatusChanged, Channel: "sess-work"}) {
+		t.Fatal("expected event for in-scope session to pass")
+	}
+	if allow(eventbus.Event{Type: eventbus.EventSessionStatusChanged, Channel: "sess-personal"}) {
+		t.Fatal("expected event for out-of-scope session to be dropped")
+	}
+	if !allow(eventbus.Event{Type: eventbus.EventSessionUpdated, Channel: "sessions"}) {
+		t.Fatal("expected broad session notification to pass")
+	}
+
+	if srv.eventFilterFor(req, &Identity{Name: "admin", Scopes: AllScopes}) != nil {
+		t.Fatal("expected no filter for an unrestricted identity")
+	}
+}
+
+type countingMenuDataLoader struct {
+	MenuDataLoader
+	loads int
+}
+
+func (c *countingMenuDataLoader) LoadMenuSnapshot() (*MenuSnapshot, error) {
+	c.loads++
+	return c.MenuDataLoader.LoadMenuSnapshot()
+}
+
+func TestEventFilterCachesSessionGroups(t *testing.T) {
+	srv := newScopedTestServer(t)
+	loader := &countingMenuDataLoader{MenuDataLoader: srv.menuData}
+	srv.menuData = loader
+	req := httptest.NewRequest(http.MethodGet, "/ws/events", nil)
+	allow := srv.eventFilterFor(req, &Identity{Name: "work-only", Scopes: []Scope{ScopeMenuRead}, Groups: []string{"work"}})
+
+	for i := 0; i < 5; i++ {
+		allow(eventbus.Event{Type: eventbus.EventSessionStatusChanged, Channel: "sess-work"})
+		allow(eventbus.Event{Type: eventbus.EventSessionStatusChanged, Channel: "sess-personal"})
+	}
+	if loader.loads != 1 {
+		t.Fatalf("expected one snapshot load for repeated events, got %d", loader.loads)
+	}
+
+	allow(eventbus.Event{Type: eventbus.EventSessionUpdated, Channel: "sessions"})
+	allow(eventbus.Event{Type: eventbus.EventSessionStatusChanged, Channel: "sess-work"})
+	if loader.loads != 2 {
+		t.Fatalf("expected a reload after a session update, got %d loads", loader.loads)
+	}
+}
+
+func TestAuditStartsWhenAuthIsEnabledAtRuntime(t *testing.T) {
+	srv := newScopedTestServer(t)
+	req := httptest.NewRequest(http.MethodGet, "/api/menu", nil)
+
+	srv.audit(req, &Identity{Name: "anonymous", Scopes: AllScopes}, "menu", "")
+	if _, err := os.Stat(srv.cfg.AuditLogPath); !os.IsNotExist(err) {
+		t.Fatalf("expected no audit log while auth is disabled, got err=%v", err)
+	}
+
+	srv.cfg.Token = "late-secret"
+	srv.audit(req, &Identity{Name: "default", Scopes: AllScopes}, "menu", "")
+	raw, err := os.ReadFile(srv.cfg.AuditLogPath)
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	if !strings.Contains(string(raw), `"identity":"default"`) {
+		t.Fatalf("expected audit entry once auth is enabled, got: %s", raw)
+	}
+}
+
+func TestRequiredScopeProtectsQueuedMessageText(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   Scope
+	}{
+		{http.MethodGet, "/api/session/sess-work/queue", ScopeTranscriptRead},
+		{http.MethodPost, "/api/session/sess-work/queue", ScopeTerminalInput},
+		{http.MethodDelete, "/api/session/sess-work/queue/3", ScopeTerminalInput},
+		{http.MethodGet, "/api/session/sess-work", ScopeMenuRead},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if got := requiredScope(req); got != tc.want {
+			t.Errorf("requiredScope(%s %s) = %s, want %s", tc.method, tc.path, got, tc.want)
+		}
+	}
+}